@@ -41,7 +41,8 @@ The process vtbackup follows to take a new backup is as follows:
    for catching up on replication before taking the backup, so the goalposts
    don't move.
 5. Wait until replication is caught up to the goal position or beyond.
-6. Stop mysqld and take a new backup.
+6. Stop mysqld and take a new backup. With -incremental, the new backup only
+   stores what changed since the backup it was restored from.
 
 Aside from additional replication load while vtbackup's mysqld catches up on
 new transactions, the shard should be otherwise unaffected. Existing tablets
@@ -54,6 +55,13 @@ The command-line parameters to vtbackup specify a policy for when a new backup
 is needed, and when old backups should be removed. If the existing backups
 already satisfy the policy, then vtbackup will do nothing and return success
 immediately.
+
+With -verify_backup, vtbackup verifies an existing backup instead: it restores
+the backup into its mysqld, runs CHECK TABLE on every table and counts their
+rows, and stores the result next to the backups in the backup storage. It
+returns an error if the backup can't be restored or a table is corrupted.
+The VerifyBackup vtctl command does the same, with a mysqld it starts on the
+host it runs on.
 */
 package main
 
@@ -110,10 +118,13 @@ var (
 	minBackupInterval = flag.Duration("min_backup_interval", 0, "Only take a new backup if it's been at least this long since the most recent backup.")
 	minRetentionTime  = flag.Duration("min_retention_time", 0, "Keep each old backup for at least this long before removing it. Set to 0 to disable pruning of old backups.")
 	minRetentionCount = flag.Int("min_retention_count", 1, "Always keep at least this many of the most recent backups in this backup storage location, even if some are older than the min_retention_time. This must be at least 1 since a backup must always exist to allow new backups to be made")
+	incremental       = flag.Bool("incremental", false, "Take an incremental backup on top of the most recent backup, that only stores what changed since then. Backups that more recent incremental backups depend on are never pruned.")
 
 	initialBackup    = flag.Bool("initial_backup", false, "Instead of restoring from backup, initialize an empty database with the provided init_db_sql_file and upload a backup of that for the shard, if the shard has no backups yet. This can be used to seed a brand new shard with an initial, empty backup. If any backups already exist for the shard, this will be considered a successful no-op. This can only be done before the shard exists in topology (i.e. before any tablets are deployed).")
 	allowFirstBackup = flag.Bool("allow_first_backup", false, "Allow this job to take the first backup of an existing shard.")
 
+	verifyBackupName = flag.String("verify_backup", "", "Instead of taking a backup, restore the backup with this name (or the most recent complete backup, with 'latest'), check its tables, and store the result of the verification next to the backups.")
+
 	restartBeforeBackup = flag.Bool("restart_before_backup", false, "Perform a mysqld clean/full restart after applying binlogs, but before taking the backup. Only makes sense to work around xtrabackup bugs.")
 
 	// vttablet-like flags
@@ -170,6 +181,14 @@ func main() {
 	topoServer := topo.Open()
 	defer topoServer.Close()
 
+	if *verifyBackupName != "" {
+		if err := verifyBackup(ctx, backupStorage); err != nil {
+			log.Errorf("Backup verification failed: %v", err)
+			exit.Return(1)
+		}
+		return
+	}
+
 	// Try to take a backup, if it's been long enough since the last one.
 	// Skip pruning if backup wasn't fully successful. We don't want to be
 	// deleting things if the backup process is not healthy.
@@ -187,58 +206,18 @@ func main() {
 	}
 
 	// Prune old backups.
-	if err := pruneBackups(ctx, backupStorage, backupDir); err != nil {
+	if err := mysqlctl.PruneBackups(ctx, backupStorage, *initKeyspace, *initShard, *minRetentionTime, *minRetentionCount); err != nil {
 		log.Errorf("Couldn't prune old backups: %v", err)
 		exit.Return(1)
 	}
 }
 
 func takeBackup(ctx context.Context, topoServer *topo.Server, backupStorage backupstorage.BackupStorage) error {
-	// This is an imaginary tablet alias. The value doesn't matter for anything,
-	// except that we generate a random UID to ensure the target backup
-	// directory is unique if multiple vtbackup instances are launched for the
-	// same shard, at exactly the same second, pointed at the same backup
-	// storage location.
-	bigN, err := rand.Int(rand.Reader, big.NewInt(math.MaxUint32))
-	if err != nil {
-		return fmt.Errorf("can't generate random tablet UID: %v", err)
-	}
-	tabletAlias := &topodatapb.TabletAlias{
-		Cell: "vtbackup",
-		Uid:  uint32(bigN.Uint64()),
-	}
-
-	// Clean up our temporary data dir if we exit for any reason, to make sure
-	// every invocation of vtbackup starts with a clean slate, and it does not
-	// accumulate garbage (and run out of disk space) if it's restarted.
-	tabletDir := mysqlctl.TabletDir(tabletAlias.Uid)
-	defer func() {
-		log.Infof("Removing temporary tablet directory: %v", tabletDir)
-		if err := os.RemoveAll(tabletDir); err != nil {
-			log.Warningf("Failed to remove temporary tablet directory: %v", err)
-		}
-	}()
-
-	// Start up mysqld as if we are mysqlctld provisioning a fresh tablet.
-	mysqld, mycnf, err := mysqlctl.CreateMysqldAndMycnf(tabletAlias.Uid, *mysqlSocket, int32(*mysqlPort))
+	tabletAlias, mysqld, mycnf, cleanup, err := startMysqld(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to initialize mysql config: %v", err)
-	}
-	initCtx, initCancel := context.WithTimeout(ctx, *mysqlTimeout)
-	defer initCancel()
-	if err := mysqld.Init(initCtx, mycnf, *initDBSQLFile); err != nil {
-		return fmt.Errorf("failed to initialize mysql data dir and start mysqld: %v", err)
+		return err
 	}
-	// Shut down mysqld when we're done.
-	defer func() {
-		// Be careful not to use the original context, because we don't want to
-		// skip shutdown just because we timed out waiting for other things.
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		if err := mysqld.Shutdown(ctx, mycnf, false); err != nil {
-			log.Errorf("failed to shutdown mysqld: %v", err)
-		}
-	}()
+	defer cleanup()
 
 	extraEnv := map[string]string{
 		"TABLET_ALIAS": topoproto.TabletAliasString(tabletAlias),
@@ -257,7 +236,9 @@ func takeBackup(ctx context.Context, topoServer *topo.Server, backupStorage back
 		TopoServer:   topoServer,
 		Keyspace:     *initKeyspace,
 		Shard:        *initShard,
+		DbName:       dbName,
 		TabletAlias:  topoproto.TabletAliasString(tabletAlias),
+		Incremental:  *incremental,
 	}
 	// In initial_backup mode, just take a backup of this empty database.
 	if *initialBackup {
@@ -426,6 +407,96 @@ func takeBackup(ctx context.Context, topoServer *topo.Server, backupStorage back
 	return nil
 }
 
+// startMysqld initializes and starts a mysqld with an empty data directory,
+// as if we were mysqlctld provisioning a fresh tablet. The returned function
+// shuts it down and removes its data directory.
+func startMysqld(ctx context.Context) (*topodatapb.TabletAlias, *mysqlctl.Mysqld, *mysqlctl.Mycnf, func(), error) {
+	// This is an imaginary tablet alias. The value doesn't matter for anything,
+	// except that we generate a random UID to ensure the target backup
+	// directory is unique if multiple vtbackup instances are launched for the
+	// same shard, at exactly the same second, pointed at the same backup
+	// storage location.
+	bigN, err := rand.Int(rand.Reader, big.NewInt(math.MaxUint32))
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("can't generate random tablet UID: %v", err)
+	}
+	tabletAlias := &topodatapb.TabletAlias{
+		Cell: "vtbackup",
+		Uid:  uint32(bigN.Uint64()),
+	}
+
+	// Clean up our temporary data dir if we exit for any reason, to make sure
+	// every invocation of vtbackup starts with a clean slate, and it does not
+	// accumulate garbage (and run out of disk space) if it's restarted.
+	tabletDir := mysqlctl.TabletDir(tabletAlias.Uid)
+	removeTabletDir := func() {
+		log.Infof("Removing temporary tablet directory: %v", tabletDir)
+		if err := os.RemoveAll(tabletDir); err != nil {
+			log.Warningf("Failed to remove temporary tablet directory: %v", err)
+		}
+	}
+
+	mysqld, mycnf, err := mysqlctl.CreateMysqldAndMycnf(tabletAlias.Uid, *mysqlSocket, int32(*mysqlPort))
+	if err != nil {
+		removeTabletDir()
+		return nil, nil, nil, nil, fmt.Errorf("failed to initialize mysql config: %v", err)
+	}
+	initCtx, initCancel := context.WithTimeout(ctx, *mysqlTimeout)
+	defer initCancel()
+	if err := mysqld.Init(initCtx, mycnf, *initDBSQLFile); err != nil {
+		removeTabletDir()
+		return nil, nil, nil, nil, fmt.Errorf("failed to initialize mysql data dir and start mysqld: %v", err)
+	}
+
+	cleanup := func() {
+		// Shut down mysqld when we're done.
+		// Be careful not to use the original context, because we don't want to
+		// skip shutdown just because we timed out waiting for other things.
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := mysqld.Shutdown(ctx, mycnf, false); err != nil {
+			log.Errorf("failed to shutdown mysqld: %v", err)
+		}
+		removeTabletDir()
+	}
+	return tabletAlias, mysqld, mycnf, cleanup, nil
+}
+
+// verifyBackup restores the backup to verify, checks its tables, and stores
+// the result of the verification.
+func verifyBackup(ctx context.Context, backupStorage backupstorage.BackupStorage) error {
+	tabletAlias, mysqld, mycnf, cleanup, err := startMysqld(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	dbName := *initDbNameOverride
+	if dbName == "" {
+		dbName = fmt.Sprintf("vt_%s", *initKeyspace)
+	}
+	backupName := *verifyBackupName
+	if backupName == "latest" {
+		backupName = ""
+	}
+	_, err = mysqlctl.VerifyBackup(ctx, backupStorage, mysqlctl.RestoreParams{
+		Cnf:         mycnf,
+		Mysqld:      mysqld,
+		Logger:      logutil.NewConsoleLogger(),
+		Concurrency: *concurrency,
+		HookExtraEnv: map[string]string{
+			"TABLET_ALIAS": topoproto.TabletAliasString(tabletAlias),
+		},
+		LocalMetadata:       map[string]string{},
+		DeleteBeforeRestore: true,
+		DbName:              dbName,
+		Keyspace:            *initKeyspace,
+		Shard:               *initShard,
+		BackupName:          backupName,
+	})
+	return err
+}
+
 func resetReplication(ctx context.Context, pos mysql.Position, mysqld mysqlctl.MysqlDaemon) error {
 	cmds := []string{
 		"STOP SLAVE",
@@ -524,48 +595,6 @@ func retryOnError(ctx context.Context, fn func() error) error {
 	}
 }
 
-func pruneBackups(ctx context.Context, backupStorage backupstorage.BackupStorage, backupDir string) error {
-	if *minRetentionTime == 0 {
-		log.Info("Pruning of old backups is disabled.")
-		return nil
-	}
-	backups, err := backupStorage.ListBackups(ctx, backupDir)
-	if err != nil {
-		return fmt.Errorf("can't list backups: %v", err)
-	}
-	numBackups := len(backups)
-	if numBackups <= *minRetentionCount {
-		log.Infof("Found %v backups. Not pruning any since this is within the min_retention_count of %v.", numBackups, *minRetentionCount)
-		return nil
-	}
-	// We have more than the minimum retention count, so we could afford to
-	// prune some. See if any are beyond the minimum retention time.
-	// ListBackups returns them sorted by oldest first.
-	for _, backup := range backups {
-		backupTime, err := parseBackupTime(backup.Name())
-		if err != nil {
-			return err
-		}
-		if time.Since(backupTime) < *minRetentionTime {
-			// The oldest remaining backup is not old enough to prune.
-			log.Infof("Oldest backup taken at %v has not reached min_retention_time of %v. Nothing left to prune.", backupTime, *minRetentionTime)
-			break
-		}
-		// Remove the backup.
-		log.Infof("Removing old backup %v from %v, since it's older than min_retention_time of %v", backup.Name(), backupDir, *minRetentionTime)
-		if err := backupStorage.RemoveBackup(ctx, backupDir, backup.Name()); err != nil {
-			return fmt.Errorf("couldn't remove backup %v from %v: %v", backup.Name(), backupDir, err)
-		}
-		// We successfully removed one backup. Can we afford to prune any more?
-		numBackups--
-		if numBackups == *minRetentionCount {
-			log.Infof("Successfully pruned backup count to min_retention_count of %v.", *minRetentionCount)
-			break
-		}
-	}
-	return nil
-}
-
 func parseBackupTime(name string) (time.Time, error) {
 	// Backup names are formatted as "date.time.tablet-alias".
 	parts := strings.Split(name, ".")
@@ -24,6 +24,7 @@ import (
 
 	"vitess.io/vitess/go/cmd/vtctldclient/cli"
 	"vitess.io/vitess/go/json2"
+	"vitess.io/vitess/go/vt/vtctl/schematools"
 
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
@@ -44,6 +45,17 @@ var (
 		RunE:                  commandApplyVSchema,
 		Short:                 "Applies the VTGate routing schema to the provided keyspace. Shows the result after application.",
 	}
+	// ValidateVSchemaChange makes a ValidateVSchemaChange gRPC call to a vtctld.
+	ValidateVSchemaChange = &cobra.Command{
+		Use:                   "ValidateVSchemaChange {-vschema=<vschema> || -vschema-file=<vschema file> || -sql=<sql> || -sql-file=<sql file>} [-query-log=<query log>] [-sample=<n>] <keyspace>",
+		Args:                  cobra.ExactArgs(1),
+		DisableFlagsInUseLine: true,
+		RunE:                  commandValidateVSchemaChange,
+		Short:                 "Validates a proposed VTGate routing schema for the provided keyspace, without applying it.",
+		Long: `Validates a proposed VTGate routing schema for the provided keyspace against the schemas of its primary tablets.
+The queries of a vtgate query log, in the text or json format, are planned with the current and the proposed
+routing schemas, and the queries whose plans change are reported.`,
+	}
 )
 
 var applyVSchemaOptions = struct {
@@ -119,6 +131,104 @@ func commandApplyVSchema(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var validateVSchemaChangeOptions = struct {
+	VSchema     string
+	VSchemaFile string
+	SQL         string
+	SQLFile     string
+	QueryLog    string
+	Sample      int
+}{}
+
+func commandValidateVSchemaChange(cmd *cobra.Command, args []string) error {
+	sqlMode := (validateVSchemaChangeOptions.SQL != "") != (validateVSchemaChangeOptions.SQLFile != "")
+	jsonMode := (validateVSchemaChangeOptions.VSchema != "") != (validateVSchemaChangeOptions.VSchemaFile != "")
+
+	if sqlMode && jsonMode {
+		return fmt.Errorf("only one of the sql, sql-file, vschema, or vschema-file flags may be specified when calling the ValidateVSchemaChange command")
+	}
+
+	if !sqlMode && !jsonMode {
+		return fmt.Errorf("one of the sql, sql-file, vschema, or vschema-file flags must be specified when calling the ValidateVSchemaChange command")
+	}
+
+	req := &vtctldatapb.ValidateVSchemaChangeRequest{
+		Keyspace: cmd.Flags().Arg(0),
+	}
+
+	if sqlMode {
+		if validateVSchemaChangeOptions.SQLFile != "" {
+			sqlBytes, err := os.ReadFile(validateVSchemaChangeOptions.SQLFile)
+			if err != nil {
+				return err
+			}
+			req.Sql = string(sqlBytes)
+		} else {
+			req.Sql = validateVSchemaChangeOptions.SQL
+		}
+	} else { // jsonMode
+		schema := []byte(validateVSchemaChangeOptions.VSchema)
+		if validateVSchemaChangeOptions.VSchemaFile != "" {
+			var err error
+			schema, err = os.ReadFile(validateVSchemaChangeOptions.VSchemaFile)
+			if err != nil {
+				return err
+			}
+		}
+
+		vs := &vschemapb.Keyspace{}
+		if err := json2.Unmarshal(schema, vs); err != nil {
+			return err
+		}
+		req.VSchema = vs
+	}
+
+	// The query log is read here, so that it doesn't have to be on the
+	// vtctld host.
+	if validateVSchemaChangeOptions.QueryLog != "" {
+		f, err := os.Open(validateVSchemaChangeOptions.QueryLog)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		queries, err := schematools.ReadQueryLog(f, validateVSchemaChangeOptions.Sample)
+		if err != nil {
+			return err
+		}
+		for _, query := range queries {
+			req.Queries = append(req.Queries, &vtctldatapb.ValidateVSchemaChangeRequest_Query{
+				Sql:      query.SQL,
+				Keyspace: query.Keyspace,
+			})
+		}
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ValidateVSchemaChange(commandCtx, req)
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	failing := len(resp.Errors) != 0
+	for _, change := range resp.PlanChanges {
+		failing = failing || change.Failing
+	}
+	if failing {
+		return fmt.Errorf("the VSchema of keyspace %s is not valid", req.Keyspace)
+	}
+
+	return nil
+}
+
 func commandGetVSchema(cmd *cobra.Command, args []string) error {
 	cli.FinishedParsing(cmd)
 
@@ -152,4 +262,12 @@ func init() {
 	Root.AddCommand(ApplyVSchema)
 
 	Root.AddCommand(GetVSchema)
+
+	ValidateVSchemaChange.Flags().StringVar(&validateVSchemaChangeOptions.VSchema, "vschema", "", "The proposed VSchema")
+	ValidateVSchemaChange.Flags().StringVar(&validateVSchemaChangeOptions.VSchemaFile, "vschema-file", "", "A file containing the proposed VSchema")
+	ValidateVSchemaChange.Flags().StringVar(&validateVSchemaChangeOptions.SQL, "sql", "", "A VSchema DDL SQL statement applied to the current VSchema, e.g. `alter table t add vindex hash(id)`")
+	ValidateVSchemaChange.Flags().StringVar(&validateVSchemaChangeOptions.SQLFile, "sql-file", "", "A file containing VSchema DDL SQL")
+	ValidateVSchemaChange.Flags().StringVar(&validateVSchemaChangeOptions.QueryLog, "query-log", "", "A vtgate query log, in the text or json format, whose queries are planned with the current and the proposed VSchemas")
+	ValidateVSchemaChange.Flags().IntVar(&validateVSchemaChangeOptions.Sample, "sample", 1000, "The number of distinct queries to plan, starting from the most recent ones of the query log")
+	Root.AddCommand(ValidateVSchemaChange)
 }
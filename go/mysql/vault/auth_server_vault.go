@@ -87,39 +87,81 @@ func registerAuthServerVault(addr string, timeout time.Duration, caCertPath stri
 
 func newAuthServerVault(addr string, timeout time.Duration, caCertPath string, path string, ttl time.Duration, tokenFilePath string, roleID string, secretIDPath string, roleMountPoint string) (*AuthServerVault, error) {
 	// Validate more parameters
-	token, err := readFromFile(tokenFilePath)
+	token, err := ReadFromFile(tokenFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("No Vault token in provided filename for -mysql_auth_vault_tokenfile")
 	}
-	secretID, err := readFromFile(secretIDPath)
+	secretID, err := ReadFromFile(secretIDPath)
 	if err != nil {
 		return nil, fmt.Errorf("No Vault secret_id in provided filename for -mysql_auth_vault_role_secretidfile")
 	}
 
+	client, err := NewClient(ClientConfig{
+		Addr:           addr,
+		Timeout:        timeout,
+		CACert:         caCertPath,
+		Token:          token,
+		RoleID:         roleID,
+		SecretID:       secretID,
+		RoleMountPoint: roleMountPoint,
+	})
+	if err != nil || client == nil {
+		log.Errorf("Error in vault client initialization, will retry: %v", err)
+	}
+
+	a := &AuthServerVault{
+		vaultClient: client,
+		vaultPath:   path,
+		vaultTTL:    ttl,
+		entries:     make(map[string][]*mysql.AuthServerStaticEntry),
+	}
+
+	authMethodNative := mysql.NewMysqlNativeAuthMethod(a, a)
+	a.methods = []mysql.AuthMethod{authMethodNative}
+
+	a.reloadVault()
+	a.installSignalHandlers()
+	return a, nil
+}
+
+// ClientConfig holds the settings of a Vault client.
+type ClientConfig struct {
+	Addr           string
+	Timeout        time.Duration
+	CACert         string
+	Token          string
+	RoleID         string
+	SecretID       string
+	RoleMountPoint string
+}
+
+// NewClient returns a Vault client. The VAULT_* environment variables
+// take precedence over the settings in cc.
+func NewClient(cc ClientConfig) (*vaultapi.Client, error) {
 	config := vaultapi.NewConfig()
 
 	// All these can be overriden by environment
 	//   so we need to check if they have been set by NewConfig
 	if config.Address == "" {
-		config.Address = addr
+		config.Address = cc.Addr
 	}
 	if config.Timeout == (0 * time.Second) {
-		config.Timeout = timeout
+		config.Timeout = cc.Timeout
 	}
 	if config.CACert == "" {
-		config.CACert = caCertPath
+		config.CACert = cc.CACert
 	}
 	if config.Token == "" {
-		config.Token = token
+		config.Token = cc.Token
 	}
 	if config.AppRoleCredentials.RoleID == "" {
-		config.AppRoleCredentials.RoleID = roleID
+		config.AppRoleCredentials.RoleID = cc.RoleID
 	}
 	if config.AppRoleCredentials.SecretID == "" {
-		config.AppRoleCredentials.SecretID = secretID
+		config.AppRoleCredentials.SecretID = cc.SecretID
 	}
 	if config.AppRoleCredentials.MountPoint == "" {
-		config.AppRoleCredentials.MountPoint = roleMountPoint
+		config.AppRoleCredentials.MountPoint = cc.RoleMountPoint
 	}
 
 	if config.CACert != "" {
@@ -127,24 +169,7 @@ func newAuthServerVault(addr string, timeout time.Duration, caCertPath string, p
 		config.InsecureSSL = false
 	}
 
-	client, err := vaultapi.NewClient(config)
-	if err != nil || client == nil {
-		log.Errorf("Error in vault client initialization, will retry: %v", err)
-	}
-
-	a := &AuthServerVault{
-		vaultClient: client,
-		vaultPath:   path,
-		vaultTTL:    ttl,
-		entries:     make(map[string][]*mysql.AuthServerStaticEntry),
-	}
-
-	authMethodNative := mysql.NewMysqlNativeAuthMethod(a, a)
-	a.methods = []mysql.AuthMethod{authMethodNative}
-
-	a.reloadVault()
-	a.installSignalHandlers()
-	return a, nil
+	return vaultapi.NewClient(config)
 }
 
 // AuthMethods returns the list of registered auth methods
@@ -271,9 +296,11 @@ func (a *AuthServerVault) close() {
 	}
 }
 
+// ReadFromFile returns the trimmed content of a file holding a Vault
+// token or secret_id. It returns an empty string if filePath is empty.
 // We ignore most errors here, to allow us to retry cleanly
 //   or ignore the cases where the input is not passed by file, but via env
-func readFromFile(filePath string) (string, error) {
+func ReadFromFile(filePath string) (string, error) {
 	if filePath == "" {
 		return "", nil
 	}
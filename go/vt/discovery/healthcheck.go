@@ -498,7 +498,23 @@ func (hc *HealthCheckImpl) recomputeHealthy(key keyspaceShardTabletType) {
 			allArray = append(allArray, s)
 		}
 	}
-	hc.healthy[key] = FilterStatsByReplicationLag(allArray)
+	hc.healthy[key] = filterStatsByOverload(FilterStatsByReplicationLag(allArray))
+}
+
+// filterStatsByOverload removes the tablets that shed load because their
+// MySQL is overloaded, unless all the tablets are overloaded: those still
+// serve the high priority queries, so they are better than no tablet at all.
+func filterStatsByOverload(tabletHealthList []*TabletHealth) []*TabletHealth {
+	res := make([]*TabletHealth, 0, len(tabletHealthList))
+	for _, th := range tabletHealthList {
+		if th.Stats == nil || !th.Stats.Overloaded {
+			res = append(res, th)
+		}
+	}
+	if len(res) == 0 {
+		return tabletHealthList
+	}
+	return res
 }
 
 // Subscribe adds a listener. Used by vtgate buffer to learn about primary changes.
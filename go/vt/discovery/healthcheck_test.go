@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"html/template"
 	"io"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
@@ -894,6 +895,63 @@ func TestGetHealthyTablets(t *testing.T) {
 	mustMatch(t, want, a, "unexpected result")
 }
 
+func TestGetHealthyTabletsOverloaded(t *testing.T) {
+	ts := memorytopo.NewServer("cell")
+	hc := createTestHc(ts)
+	defer hc.Close()
+	resultChan := hc.Subscribe()
+	target := &querypb.Target{Keyspace: "k", Shard: "s", TabletType: topodatapb.TabletType_REPLICA}
+
+	tablet1 := createTestTablet(0, "cell", "a")
+	tablet1.Type = topodatapb.TabletType_REPLICA
+	input1 := make(chan *querypb.StreamHealthResponse)
+	createFakeConn(tablet1, input1)
+	hc.AddTablet(tablet1)
+	<-resultChan
+
+	tablet2 := createTestTablet(1, "cell", "b")
+	tablet2.Type = topodatapb.TabletType_REPLICA
+	input2 := make(chan *querypb.StreamHealthResponse)
+	createFakeConn(tablet2, input2)
+	hc.AddTablet(tablet2)
+	<-resultChan
+
+	send := func(tablet *topodatapb.Tablet, input chan *querypb.StreamHealthResponse, overloaded bool) {
+		input <- &querypb.StreamHealthResponse{
+			TabletAlias:   tablet.Alias,
+			Target:        target,
+			Serving:       true,
+			RealtimeStats: &querypb.RealtimeStats{ReplicationLagSeconds: 1, Overloaded: overloaded},
+		}
+		<-resultChan
+	}
+	healthyUids := func() []uint32 {
+		var uids []uint32
+		for _, th := range hc.GetHealthyTabletStats(target) {
+			uids = append(uids, th.Tablet.Alias.Uid)
+		}
+		sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+		return uids
+	}
+
+	send(tablet1, input1, false)
+	send(tablet2, input2, false)
+	assert.Equal(t, []uint32{0, 1}, healthyUids())
+
+	// Overloaded tablets are skipped.
+	send(tablet1, input1, true)
+	assert.Equal(t, []uint32{1}, healthyUids())
+
+	// Unless all the tablets are overloaded.
+	send(tablet2, input2, true)
+	assert.Equal(t, []uint32{0, 1}, healthyUids())
+
+	send(tablet2, input2, false)
+	assert.Equal(t, []uint32{1}, healthyUids())
+	send(tablet1, input1, false)
+	assert.Equal(t, []uint32{0, 1}, healthyUids())
+}
+
 func TestPrimaryInOtherCell(t *testing.T) {
 	ts := memorytopo.NewServer("cell1", "cell2")
 	hc := NewHealthCheck(context.Background(), 1*time.Millisecond, time.Hour, ts, "cell1", "cell1, cell2")
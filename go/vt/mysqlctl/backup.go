@@ -75,10 +75,11 @@ var (
 	// and used as the transform hook name again.
 	backupStorageHook = flag.String("backup_storage_hook", "", "if set, we send the contents of the backup files through this hook.")
 
-	// backupStorageCompress can be set to false to not use gzip
-	// on the backups. Usually would be set if a hook is used, and
-	// the hook compresses the data.
-	backupStorageCompress = flag.Bool("backup_storage_compress", true, "if set, the backup files will be compressed (default is true). Set to false for instance if a backup_storage_hook is specified and it compresses the data.")
+	// backupStorageCompress can be set to false to not compress
+	// the backups. Usually would be set if a hook is used, and
+	// the hook compresses the data. compressionEngineName picks
+	// the compression otherwise.
+	backupStorageCompress = flag.Bool("backup_storage_compress", true, "if set, the backup files will be compressed with the compression_engine_name engine (default is true). Set to false for instance if a backup_storage_hook is specified and it compresses the data.")
 
 	// backupCompressBlockSize is the splitting size for each
 	// compressed block
@@ -88,6 +89,11 @@ var (
 	// once before the writer blocks
 	backupCompressBlocks = flag.Int("backup_storage_number_blocks", 2, "if backup_storage_compress is true, backup_storage_number_blocks sets the number of blocks that can be processed, at once, before the writer blocks, during compression (default is 2). It should be equal to the number of CPUs available for compression")
 
+	// incrementalBackupMaxChainLength caps the number of incremental
+	// backups between two full backups, so restores don't have to go
+	// through an ever growing chain.
+	incrementalBackupMaxChainLength = flag.Int("incremental_backup_max_chain_length", 10, "maximum number of incremental backups taken on top of a full backup, before an incremental backup request takes a full backup instead")
+
 	backupDuration  = stats.NewGauge("backup_duration_seconds", "How long it took to complete the last backup operation (in seconds)")
 	restoreDuration = stats.NewGauge("restore_duration_seconds", "How long it took to complete the last restore operation (in seconds)")
 )
@@ -100,18 +106,39 @@ func Backup(ctx context.Context, params BackupParams) error {
 	startTs := time.Now()
 	backupDir := GetBackupDir(params.Keyspace, params.Shard)
 	name := fmt.Sprintf("%v.%v", params.BackupTime.UTC().Format(BackupTimestampFormat), params.TabletAlias)
+	if *backupStorageCompress {
+		if err := validateCompressionEngine(*compressionEngineName); err != nil {
+			return err
+		}
+	}
+	if *backupEncryptionKeyProvider != "" {
+		if _, err := getBackupKeyProvider(*backupEncryptionKeyProvider); err != nil {
+			return err
+		}
+	}
 	// Start the backup with the BackupStorage.
 	bs, err := backupstorage.GetBackupStorage()
 	if err != nil {
 		return vterrors.Wrap(err, "unable to get backup storage")
 	}
 	defer bs.Close()
+
+	// Find the backup to take an incremental backup on top of, before
+	// starting the new one.
+	if params.Incremental {
+		parent, err := findParentBackup(ctx, params, bs, backupDir)
+		if err != nil {
+			return err
+		}
+		params.ParentBackup = parent
+	}
+
 	bh, err := bs.StartBackup(ctx, backupDir, name)
 	if err != nil {
 		return vterrors.Wrap(err, "StartBackup failed")
 	}
 
-	be, err := GetBackupEngine()
+	be, err := GetBackupEngineByName(params.BackupEngine)
 	if err != nil {
 		return vterrors.Wrap(err, "failed to find backup engine")
 	}
@@ -141,6 +168,46 @@ func Backup(ctx context.Context, params BackupParams) error {
 	return finishErr
 }
 
+// findParentBackup returns the most recent complete backup, to take an
+// incremental backup on top of. It returns nil if a full backup should be
+// taken instead.
+func findParentBackup(ctx context.Context, params BackupParams, bs backupstorage.BackupStorage, backupDir string) (backupstorage.BackupHandle, error) {
+	bhs, err := bs.ListBackups(ctx, backupDir)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "ListBackups failed")
+	}
+	if len(bhs) == 0 {
+		params.Logger.Infof("no backup to take an incremental backup on top of, taking a full backup")
+		return nil, nil
+	}
+	parent, err := FindBackupToRestore(ctx, RestoreParams{
+		Logger:   params.Logger,
+		Keyspace: params.Keyspace,
+		Shard:    params.Shard,
+	}, bhs)
+	if err == ErrNoCompleteBackup {
+		params.Logger.Infof("no complete backup to take an incremental backup on top of, taking a full backup")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	bm, err := GetBackupManifest(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	parents, err := findParentBackups(ctx, bhs, bm)
+	if err != nil {
+		return nil, err
+	}
+	if len(parents) >= *incrementalBackupMaxChainLength {
+		params.Logger.Infof("backup %v is already %v incremental backup(s) away from a full backup, taking a full backup", parent.Name(), len(parents))
+		return nil, nil
+	}
+	params.Logger.Infof("taking an incremental backup on top of %v", parent.Name())
+	return parent, nil
+}
+
 // ParseBackupName parses the backup name for a given dir/name, according to
 // the format generated by mysqlctl.Backup. An error is returned only if the
 // backup name does not have the expected number of parts; errors parsing the
@@ -309,6 +376,17 @@ func Restore(ctx context.Context, params RestoreParams) (*BackupManifest, error)
 	if err != nil {
 		return nil, err
 	}
+	bm, err := GetBackupManifest(ctx, bh)
+	if err != nil {
+		return nil, err
+	}
+	params.ParentBackups, err = findParentBackups(ctx, bhs, bm)
+	if err != nil {
+		return nil, err
+	}
+	if (len(params.Tables) > 0 || params.KeyRange != nil) && bm.BackupMethod != logicalBackupEngineName {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "backup %v was taken with the %v engine, only %v backups can be restored partially", bh.Name(), bm.BackupMethod, logicalBackupEngineName)
+	}
 
 	re, err := GetRestoreEngine(ctx, bh)
 	if err != nil {
@@ -320,23 +398,29 @@ func Restore(ctx context.Context, params RestoreParams) (*BackupManifest, error)
 		return nil, err
 	}
 
-	// mysqld needs to be running in order for mysql_upgrade to work.
-	// If we've just restored from a backup from previous MySQL version then mysqld
-	// may fail to start due to a different structure of mysql.* tables. The flag
-	// --skip-grant-tables ensures that these tables are not read until mysql_upgrade
-	// is executed. And since with --skip-grant-tables anyone can connect to MySQL
-	// without password, we are passing --skip-networking to greatly reduce the set
-	// of those who can connect.
-	params.Logger.Infof("Restore: starting mysqld for mysql_upgrade")
-	// Note Start will use dba user for waiting, this is fine, it will be allowed.
-	err = params.Mysqld.Start(context.Background(), params.Cnf, "--skip-grant-tables", "--skip-networking")
-	if err != nil {
-		return nil, err
-	}
+	// A logical backup is restored into the running mysqld, which keeps
+	// its own system tables, so there is nothing to upgrade.
+	physical := manifest.BackupMethod != logicalBackupEngineName
+
+	if physical {
+		// mysqld needs to be running in order for mysql_upgrade to work.
+		// If we've just restored from a backup from previous MySQL version then mysqld
+		// may fail to start due to a different structure of mysql.* tables. The flag
+		// --skip-grant-tables ensures that these tables are not read until mysql_upgrade
+		// is executed. And since with --skip-grant-tables anyone can connect to MySQL
+		// without password, we are passing --skip-networking to greatly reduce the set
+		// of those who can connect.
+		params.Logger.Infof("Restore: starting mysqld for mysql_upgrade")
+		// Note Start will use dba user for waiting, this is fine, it will be allowed.
+		err = params.Mysqld.Start(context.Background(), params.Cnf, "--skip-grant-tables", "--skip-networking")
+		if err != nil {
+			return nil, err
+		}
 
-	params.Logger.Infof("Restore: running mysql_upgrade")
-	if err := params.Mysqld.RunMysqlUpgrade(); err != nil {
-		return nil, vterrors.Wrap(err, "mysql_upgrade failed")
+		params.Logger.Infof("Restore: running mysql_upgrade")
+		if err := params.Mysqld.RunMysqlUpgrade(); err != nil {
+			return nil, vterrors.Wrap(err, "mysql_upgrade failed")
+		}
 	}
 
 	// Add backupTime and restorePosition to LocalMetadata
@@ -351,16 +435,28 @@ func Restore(ctx context.Context, params RestoreParams) (*BackupManifest, error)
 		return nil, err
 	}
 
-	// The MySQL manual recommends restarting mysqld after running mysql_upgrade,
-	// so that any changes made to system tables take effect.
-	params.Logger.Infof("Restore: restarting mysqld after mysql_upgrade")
-	err = params.Mysqld.Shutdown(context.Background(), params.Cnf, true)
-	if err != nil {
-		return nil, err
+	if physical {
+		// The MySQL manual recommends restarting mysqld after running mysql_upgrade,
+		// so that any changes made to system tables take effect.
+		params.Logger.Infof("Restore: restarting mysqld after mysql_upgrade")
+		err = params.Mysqld.Shutdown(context.Background(), params.Cnf, true)
+		if err != nil {
+			return nil, err
+		}
+		err = params.Mysqld.Start(context.Background(), params.Cnf)
+		if err != nil {
+			return nil, err
+		}
 	}
-	err = params.Mysqld.Start(context.Background(), params.Cnf)
-	if err != nil {
-		return nil, err
+
+	// Point-in-time recovery: bring the backup to the exact restore point.
+	if !params.RestoreToPos.IsZero() || !params.RestoreToTime.IsZero() {
+		params.Logger.Infof("Restore: replaying archived binary logs from %v", manifest.Position)
+		pos, err := restoreFromBinlogArchive(ctx, bs, params, manifest.Position)
+		if err != nil {
+			return nil, vterrors.Wrap(err, "point-in-time recovery failed")
+		}
+		manifest.Position = pos
 	}
 
 	if err = removeStateFile(params.Cnf); err != nil {
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+)
+
+// PruneBackups removes the backups of a shard that are older than
+// minRetentionTime, except for the minRetentionCount most recent ones, and
+// the ones that the remaining incremental backups depend on. It also
+// removes the verifications of the removed backups, and the archived binary
+// logs that are too old to be replayed on top of the remaining backups.
+// Nothing is removed if minRetentionTime is 0.
+func PruneBackups(ctx context.Context, bs backupstorage.BackupStorage, keyspace, shard string, minRetentionTime time.Duration, minRetentionCount int) error {
+	if minRetentionTime == 0 {
+		log.Info("Pruning of old backups is disabled.")
+		return nil
+	}
+	backupDir := GetBackupDir(keyspace, shard)
+	backups, err := bs.ListBackups(ctx, backupDir)
+	if err != nil {
+		return fmt.Errorf("can't list backups: %v", err)
+	}
+	numBackups := len(backups)
+	if numBackups <= minRetentionCount {
+		log.Infof("Found %v backups. Not pruning any since this is within the min_retention_count of %v.", numBackups, minRetentionCount)
+		return pruneBinlogArchives(ctx, bs, keyspace, shard, backups)
+	}
+	// We have more than the minimum retention count, so we could afford to
+	// prune some. See if any are beyond the minimum retention time.
+	// ListBackups returns them sorted by oldest first.
+	var oldBackups []backupstorage.BackupHandle
+	for _, backup := range backups[:numBackups-minRetentionCount] {
+		backupTime, _, err := ParseBackupName(backupDir, backup.Name())
+		if err != nil {
+			return err
+		}
+		if backupTime == nil {
+			return fmt.Errorf("can't parse timestamp from backup %q", backup.Name())
+		}
+		if time.Since(*backupTime) < minRetentionTime {
+			// The oldest remaining backup is not old enough to prune.
+			log.Infof("Oldest backup taken at %v has not reached min_retention_time of %v. Nothing left to prune.", *backupTime, minRetentionTime)
+			break
+		}
+		oldBackups = append(oldBackups, backup)
+	}
+	if len(oldBackups) == numBackups-minRetentionCount {
+		log.Infof("Pruning backup count to min_retention_count of %v.", minRetentionCount)
+	}
+
+	// Incremental backups can only be restored with their parents, so keep
+	// the backups that the remaining ones depend on, directly or not.
+	parents := make(map[string]string)
+	for _, backup := range backups {
+		manifest, err := GetBackupManifest(ctx, backup)
+		if err != nil {
+			// Incomplete backups don't depend on any other backup.
+			continue
+		}
+		parents[backup.Name()] = manifest.Parent
+	}
+	needed := make(map[string]bool)
+	for _, backup := range backups[len(oldBackups):] {
+		for name := parents[backup.Name()]; name != "" && !needed[name]; name = parents[name] {
+			needed[name] = true
+		}
+	}
+
+	// Remove the most recent backups first, so that no incremental backup
+	// is left without its parent if we fail halfway.
+	removed := make(map[string]bool)
+	for i := len(oldBackups) - 1; i >= 0; i-- {
+		backup := oldBackups[i]
+		if needed[backup.Name()] {
+			log.Infof("Keeping old backup %v in %v, since more recent incremental backups depend on it", backup.Name(), backupDir)
+			continue
+		}
+		log.Infof("Removing old backup %v from %v, since it's older than min_retention_time of %v", backup.Name(), backupDir, minRetentionTime)
+		if err := bs.RemoveBackup(ctx, backupDir, backup.Name()); err != nil {
+			return fmt.Errorf("couldn't remove backup %v from %v: %v", backup.Name(), backupDir, err)
+		}
+		removed[backup.Name()] = true
+		// Its verification, if any, is useless now.
+		verificationDir := GetBackupVerificationDir(keyspace, shard)
+		if err := bs.RemoveBackup(ctx, verificationDir, backup.Name()); err != nil {
+			log.Warningf("Couldn't remove verification of backup %v from %v: %v", backup.Name(), verificationDir, err)
+		}
+	}
+
+	var remaining []backupstorage.BackupHandle
+	for _, backup := range backups {
+		if !removed[backup.Name()] {
+			remaining = append(remaining, backup)
+		}
+	}
+	return pruneBinlogArchives(ctx, bs, keyspace, shard, remaining)
+}
+
+// pruneBinlogArchives removes the archived binary logs of a shard whose
+// transactions are all in the oldest complete backup of backups, since a
+// restore never replays them anymore.
+func pruneBinlogArchives(ctx context.Context, bs backupstorage.BackupStorage, keyspace, shard string, backups []backupstorage.BackupHandle) error {
+	var oldest *BackupManifest
+	for _, backup := range backups {
+		if manifest, err := GetBackupManifest(ctx, backup); err == nil {
+			oldest = manifest
+			break
+		}
+	}
+	if oldest == nil || oldest.Position.IsZero() {
+		return nil
+	}
+
+	dir := GetBinlogArchiveDir(keyspace, shard)
+	archives, err := listBinlogArchives(ctx, bs, dir, logutil.NewConsoleLogger())
+	if err != nil {
+		return fmt.Errorf("can't list archived binary logs: %v", err)
+	}
+	for _, archive := range archives {
+		if !oldest.Position.AtLeast(archive.Manifest.Position) {
+			continue
+		}
+		log.Infof("Removing archived binary log %v from %v, since the oldest backup is at %v", archive.Handle.Name(), dir, oldest.Position)
+		if err := bs.RemoveBackup(ctx, dir, archive.Handle.Name()); err != nil {
+			return fmt.Errorf("couldn't remove archived binary log %v from %v: %v", archive.Handle.Name(), dir, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl_test
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
+)
+
+// addTestManifest stores a directory of the backup storage with only a
+// MANIFEST.
+func addTestManifest(t *testing.T, bs backupstorage.BackupStorage, dir, name string, manifest interface{}) {
+	ctx := context.Background()
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	bh, err := bs.StartBackup(ctx, dir, name)
+	require.NoError(t, err)
+	wc, err := bh.AddFile(ctx, "MANIFEST", int64(len(data)))
+	require.NoError(t, err)
+	_, err = wc.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, wc.Close())
+	require.NoError(t, bh.EndBackup(ctx))
+}
+
+func listNames(t *testing.T, bs backupstorage.BackupStorage, dir string) []string {
+	bhs, err := bs.ListBackups(context.Background(), dir)
+	require.NoError(t, err)
+	var names []string
+	for _, bh := range bhs {
+		names = append(names, bh.Name())
+	}
+	return names
+}
+
+func TestPruneBackups(t *testing.T) {
+	oldRoot := *filebackupstorage.FileBackupStorageRoot
+	oldImplementation := *backupstorage.BackupStorageImplementation
+	defer func() {
+		*filebackupstorage.FileBackupStorageRoot = oldRoot
+		*backupstorage.BackupStorageImplementation = oldImplementation
+	}()
+	*filebackupstorage.FileBackupStorageRoot = path.Join(t.TempDir(), "backups")
+	*backupstorage.BackupStorageImplementation = "file"
+	ctx := context.Background()
+	bs, err := backupstorage.GetBackupStorage()
+	require.NoError(t, err)
+	defer bs.Close()
+
+	position := func(gtids string) mysql.Position {
+		if gtids == "" {
+			return mysql.Position{}
+		}
+		return mysql.MustParsePosition(mysql.Mysql56FlavorID, testServerUUID+":"+gtids)
+	}
+	backupDir := mysqlctl.GetBackupDir("ks", "0")
+	old := time.Now().Add(-48 * time.Hour).UTC().Format(mysqlctl.BackupTimestampFormat)
+	recent := time.Now().Add(-time.Hour).UTC().Format(mysqlctl.BackupTimestampFormat)
+	addTestManifest(t, bs, backupDir, old+".cell1-0000000101", &mysqlctl.BackupManifest{BackupMethod: "builtin", Position: position("1-20")})
+	addTestManifest(t, bs, backupDir, recent+".cell1-0000000101", &mysqlctl.BackupManifest{BackupMethod: "builtin", Position: position("1-30")})
+
+	binlogDir := mysqlctl.GetBinlogArchiveDir("ks", "0")
+	for _, archive := range []struct {
+		name, previous, position string
+	}{
+		{"2021-06-01.000000.cell1-0000000101.vt-bin.000001", "", "1-10"},
+		{"2021-06-01.010000.cell1-0000000101.vt-bin.000002", "1-10", "1-25"},
+		{"2021-06-01.020000.cell1-0000000101.vt-bin.000003", "1-25", "1-35"},
+	} {
+		addTestManifest(t, bs, binlogDir, archive.name, &mysqlctl.BinlogArchiveManifest{
+			PreviousPosition: position(archive.previous),
+			Position:         position(archive.position),
+		})
+	}
+
+	// Nothing is pruned if pruning is disabled.
+	require.NoError(t, mysqlctl.PruneBackups(ctx, bs, "ks", "0", 0, 1))
+	assert.Len(t, listNames(t, bs, backupDir), 2)
+	assert.Len(t, listNames(t, bs, binlogDir), 3)
+
+	// The binary logs that are all in the oldest backup are pruned, even
+	// if no backup is.
+	require.NoError(t, mysqlctl.PruneBackups(ctx, bs, "ks", "0", 24*time.Hour, 2))
+	assert.Len(t, listNames(t, bs, backupDir), 2)
+	assert.Equal(t, []string{
+		"2021-06-01.010000.cell1-0000000101.vt-bin.000002",
+		"2021-06-01.020000.cell1-0000000101.vt-bin.000003",
+	}, listNames(t, bs, binlogDir))
+
+	// Once the old backup is pruned, so are the binary logs it needed.
+	require.NoError(t, mysqlctl.PruneBackups(ctx, bs, "ks", "0", 24*time.Hour, 1))
+	assert.Equal(t, []string{recent + ".cell1-0000000101"}, listNames(t, bs, backupDir))
+	assert.Equal(t, []string{"2021-06-01.020000.cell1-0000000101.vt-bin.000003"}, listNames(t, bs, binlogDir))
+}
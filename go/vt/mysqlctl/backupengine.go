@@ -33,11 +33,14 @@ import (
 	"vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 )
 
 var (
 	// BackupEngineImplementation is the implementation to use for BackupEngine
-	backupEngineImplementation = flag.String("backup_engine_implementation", builtinBackupEngineName, "Specifies which implementation to use for creating new backups (builtin or xtrabackup). Restores will always be done with whichever engine created a given backup.")
+	backupEngineImplementation = flag.String("backup_engine_implementation", builtinBackupEngineName, "Specifies which implementation to use for creating new backups (builtin, xtrabackup or logical). Restores will always be done with whichever engine created a given backup.")
 )
 
 // BackupEngine is the interface to take a backup with a given engine.
@@ -61,10 +64,23 @@ type BackupParams struct {
 	// Keyspace and Shard are used to infer the directory where backups should be stored
 	Keyspace string
 	Shard    string
+	// DbName is the name of the managed database / schema, for engines
+	// that back up its content rather than the files of mysqld
+	DbName string
 	// TabletAlias is used along with backupTime to construct the backup name
 	TabletAlias string
 	// BackupTime is the time at which the backup is being started
 	BackupTime time.Time
+	// Incremental asks for a backup that only stores what changed since
+	// the most recent complete backup. See ParentBackup.
+	Incremental bool
+	// ParentBackup is the backup an incremental backup is taken on top of.
+	// It is set by Backup if Incremental is true and a suitable backup
+	// exists. Engines that don't support incremental backups ignore it.
+	ParentBackup backupstorage.BackupHandle
+	// BackupEngine is the name of the engine to take the backup with.
+	// The -backup_engine_implementation flag is used if empty.
+	BackupEngine string
 }
 
 // RestoreParams is the struct that holds all params passed to ExecuteRestore
@@ -91,6 +107,24 @@ type RestoreParams struct {
 	// StartTime: if non-zero, look for a backup that was taken at or before this time
 	// Otherwise, find the most recent backup
 	StartTime time.Time
+	// BackupName: if set, restore this backup rather than the most recent one
+	BackupName string
+	// RestoreToPos and RestoreToTime: if one is non-zero, restore the most
+	// recent backup before it, and replay the archived binary logs on top
+	// of the backup up to that exact point.
+	RestoreToPos  mysql.Position
+	RestoreToTime time.Time
+	// ParentBackups are the backups an incremental backup depends on,
+	// starting with the full backup. They are set by Restore.
+	ParentBackups []backupstorage.BackupHandle
+	// Tables: if set, only restore these tables, and leave the other
+	// tables of the database alone. Only logical backups support it.
+	Tables []string
+	// KeyRange: if set, only restore the rows in this key range, according
+	// to the primary vindexes of VSchema. It is used to restore a backup
+	// of another shard. Only logical backups support it.
+	KeyRange *topodatapb.KeyRange
+	VSchema  *vschemapb.Keyspace
 }
 
 // RestoreEngine is the interface to restore a backup with a given engine.
@@ -117,7 +151,16 @@ var BackupRestoreEngineMap = make(map[string]BackupRestoreEngine)
 //
 // This must only be called after flags have been parsed.
 func GetBackupEngine() (BackupEngine, error) {
-	name := *backupEngineImplementation
+	return GetBackupEngineByName(*backupEngineImplementation)
+}
+
+// GetBackupEngineByName returns the BackupEngine implementation registered
+// with the given name, or the one of -backup_engine_implementation if name
+// is empty.
+func GetBackupEngineByName(name string) (BackupEngine, error) {
+	if name == "" {
+		name = *backupEngineImplementation
+	}
 	be, ok := BackupRestoreEngineMap[name]
 	if !ok {
 		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "unknown BackupEngine implementation %q", name)
@@ -187,6 +230,13 @@ type BackupManifest struct {
 	// FinishedTime is the time (in RFC 3339 format, UTC) at which the backup finished, if known.
 	// Some backups may not set this field if they were created before the field was added.
 	FinishedTime string
+
+	// Parent is the name of the backup, in the same directory, that this
+	// incremental backup was taken on top of. It is empty for full backups.
+	Parent string `json:",omitempty"`
+
+	// Encryption is set if the backup files are encrypted.
+	Encryption *BackupEncryption `json:",omitempty"`
 }
 
 // FindBackupToRestore returns a selected candidate backup to be restored.
@@ -196,17 +246,27 @@ func FindBackupToRestore(ctx context.Context, params RestoreParams, bhs []backup
 	var bh backupstorage.BackupHandle
 	var index int
 	// if a StartTime is provided in params, then find a backup that was taken at or before that time
+	if params.StartTime.IsZero() {
+		params.StartTime = params.RestoreToTime
+	}
 	checkBackupTime := !params.StartTime.IsZero()
 	backupDir := GetBackupDir(params.Keyspace, params.Shard)
 
 	for index = len(bhs) - 1; index >= 0; index-- {
 		bh = bhs[index]
+		if params.BackupName != "" && bh.Name() != params.BackupName {
+			continue
+		}
 		// Check that the backup MANIFEST exists and can be successfully decoded.
 		bm, err := GetBackupManifest(ctx, bh)
 		if err != nil {
 			params.Logger.Warningf("Possibly incomplete backup %v in directory %v on BackupStorage: can't read MANIFEST: %v)", bh.Name(), backupDir, err)
 			continue
 		}
+		if _, err := findParentBackups(ctx, bhs, bm); err != nil {
+			params.Logger.Warningf("Restore: skipping incremental backup %v/%v: %v", backupDir, bh.Name(), err)
+			continue
+		}
 
 		var backupTime time.Time
 		if checkBackupTime {
@@ -216,6 +276,10 @@ func FindBackupToRestore(ctx context.Context, params RestoreParams, bhs []backup
 				continue
 			}
 		}
+		if !params.RestoreToPos.IsZero() && !params.RestoreToPos.AtLeast(bm.Position) {
+			params.Logger.Infof("Restore: skipping backup %v/%v at position %v, which is after the restore position", backupDir, bh.Name(), bm.Position)
+			continue
+		}
 		if !checkBackupTime || backupTime.Equal(params.StartTime) || backupTime.Before(params.StartTime) {
 			if !checkBackupTime {
 				params.Logger.Infof("Restore: found latest backup %v %v to restore", bh.Directory(), bh.Name())
@@ -226,9 +290,15 @@ func FindBackupToRestore(ctx context.Context, params RestoreParams, bhs []backup
 		}
 	}
 	if index < 0 {
+		if params.BackupName != "" {
+			params.Logger.Errorf("No valid backup named %v found", params.BackupName)
+		}
 		if checkBackupTime {
 			params.Logger.Errorf("No valid backup found before time %v", params.StartTime.Format(BackupTimestampFormat))
 		}
+		if !params.RestoreToPos.IsZero() {
+			params.Logger.Errorf("No valid backup found before position %v", params.RestoreToPos)
+		}
 		// There is at least one attempted backup, but none could be read.
 		// This implies there is data we ought to have, so it's not safe to start
 		// up empty.
@@ -238,16 +308,49 @@ func FindBackupToRestore(ctx context.Context, params RestoreParams, bhs []backup
 	return bh, nil
 }
 
-func prepareToRestore(ctx context.Context, cnf *Mycnf, mysqld MysqlDaemon, logger logutil.Logger) error {
+// findParentBackups returns the backups an incremental backup depends on,
+// starting with the full backup. It returns an error if one of them is
+// missing or incomplete.
+func findParentBackups(ctx context.Context, bhs []backupstorage.BackupHandle, bm *BackupManifest) ([]backupstorage.BackupHandle, error) {
+	byName := make(map[string]backupstorage.BackupHandle, len(bhs))
+	for _, bh := range bhs {
+		byName[bh.Name()] = bh
+	}
+	var parents []backupstorage.BackupHandle
+	for name := bm.Parent; name != ""; {
+		// A chain can't be longer than the list of backups, unless
+		// it has a cycle.
+		if len(parents) == len(bhs) {
+			return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "backup %v is part of a cycle of parent backups", name)
+		}
+		parent, ok := byName[name]
+		if !ok {
+			return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "parent backup %v is missing", name)
+		}
+		pm, err := GetBackupManifest(ctx, parent)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "parent backup %v is incomplete", name)
+		}
+		parents = append([]backupstorage.BackupHandle{parent}, parents...)
+		name = pm.Parent
+	}
+	return parents, nil
+}
+
+// prepareToRestore shuts down mysqld, and deletes its files, unless
+// keepFiles is set to resume an interrupted restore.
+func prepareToRestore(ctx context.Context, cnf *Mycnf, mysqld MysqlDaemon, logger logutil.Logger, keepFiles bool) error {
 	// shutdown mysqld if it is running
 	logger.Infof("Restore: shutdown mysqld")
 	if err := mysqld.Shutdown(ctx, cnf, true); err != nil {
 		return err
 	}
 
-	logger.Infof("Restore: deleting existing files")
-	if err := removeExistingFiles(cnf); err != nil {
-		return err
+	if !keepFiles {
+		logger.Infof("Restore: deleting existing files")
+		if err := removeExistingFiles(cnf); err != nil {
+			return err
+		}
 	}
 
 	logger.Infof("Restore: reinit config file")
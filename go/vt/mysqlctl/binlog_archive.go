@@ -0,0 +1,593 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	vtenv "vitess.io/vitess/go/vt/env"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// This file handles the archiving of the binary logs of the primary to the
+// BackupStorage, and their replay on top of a backup for point-in-time
+// recovery. Every archived binary log is stored like a backup, with the
+// binary log itself and a MANIFEST that records its GTID range.
+
+const (
+	// binlogArchiveFileName is the name of the binary log within its archive.
+	binlogArchiveFileName = "binlog"
+
+	// binlogEventHeaderLength is the length of the header of the events
+	// of binary logs in the v4 format, which is the only one in use.
+	binlogEventHeaderLength = 19
+)
+
+// binlogMagic is at the start of every binary log file.
+var binlogMagic = []byte{0xfe, 'b', 'i', 'n'}
+
+// BinlogArchiveManifest is the MANIFEST of an archived binary log.
+type BinlogArchiveManifest struct {
+	// BinlogFile is the name of the binary log on the tablet that archived it.
+	BinlogFile string
+
+	// TabletAlias is the alias of the tablet that archived the binary log.
+	TabletAlias string
+
+	// PreviousPosition is the GTID set executed before the binary log.
+	PreviousPosition mysql.Position
+
+	// Position is the GTID set executed at the end of the binary log.
+	Position mysql.Position
+
+	// FirstTimestamp and LastTimestamp are the times (in RFC 3339 format, UTC)
+	// of the first and last transactions of the binary log.
+	FirstTimestamp string
+	LastTimestamp  string
+
+	// SkipCompress is true if the binary log was NOT compressed.
+	SkipCompress bool
+
+	// CompressionEngine and ExternalDecompressor tell how the binary log
+	// was compressed. See the fields of the same name in builtinBackupManifest.
+	CompressionEngine    string `json:",omitempty"`
+	ExternalDecompressor string `json:",omitempty"`
+
+	// Encryption is set if the binary log was encrypted. See the field of
+	// the same name in BackupManifest.
+	Encryption *BackupEncryption `json:",omitempty"`
+}
+
+// BinlogArchive is an archived binary log, with its MANIFEST.
+type BinlogArchive struct {
+	Handle   backupstorage.BackupHandle
+	Manifest *BinlogArchiveManifest
+}
+
+// ArchiveBinlogsParams is the struct that holds all params passed to ArchiveBinlogs
+type ArchiveBinlogsParams struct {
+	Cnf    *Mycnf
+	Mysqld MysqlDaemon
+	Logger logutil.Logger
+	// Keyspace and Shard are used to infer the directory where binary logs are archived
+	Keyspace string
+	Shard    string
+	// TabletAlias is recorded in the MANIFEST of the archived binary logs
+	TabletAlias string
+}
+
+// GetBinlogArchiveDir returns the directory where the binary logs of the
+// given keyspace/shard are (or will be) archived. It is next to the backup
+// directory rather than in it, so archived binary logs are not listed as
+// backups.
+func GetBinlogArchiveDir(keyspace, shard string) string {
+	return fmt.Sprintf("%v/%v.binlogs", keyspace, shard)
+}
+
+// ArchiveBinlogs uploads the closed binary logs of mysqld that contain
+// transactions which are not archived yet. The binary log mysqld is
+// currently writing to is left for a later run, once it's rotated.
+// The binary logs archived by this tablet are skipped by name, without
+// reading them. It returns the number of archived binary logs.
+func ArchiveBinlogs(ctx context.Context, params ArchiveBinlogsParams) (int, error) {
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return 0, vterrors.Wrap(err, "unable to get backup storage")
+	}
+	defer bs.Close()
+
+	dir := GetBinlogArchiveDir(params.Keyspace, params.Shard)
+	archives, err := listBinlogArchives(ctx, bs, dir, params.Logger)
+	if err != nil {
+		return 0, err
+	}
+	var archived mysql.Position
+	archivedFiles := make(map[string]bool, len(archives))
+	for _, archive := range archives {
+		archived = unionPositions(archived, archive.Manifest.Position)
+		archivedFiles[archivedBinlogKey(archive.Manifest.TabletAlias, archive.Manifest.BinlogFile)] = true
+	}
+
+	qr, err := params.Mysqld.FetchSuperQuery(ctx, "SHOW BINARY LOGS")
+	if err != nil {
+		return 0, vterrors.Wrap(err, "can't list binary logs")
+	}
+	if len(qr.Rows) == 0 {
+		return 0, nil
+	}
+	binlogDir := path.Dir(params.Cnf.BinLogPath)
+	count := 0
+	for _, row := range qr.Rows[:len(qr.Rows)-1] {
+		file := row[0].ToString()
+		if archivedFiles[archivedBinlogKey(params.TabletAlias, file)] {
+			// Don't parse the binary logs this tablet archived already.
+			continue
+		}
+		bm, err := readBinlogManifest(path.Join(binlogDir, file))
+		if err != nil {
+			return count, vterrors.Wrapf(err, "can't read binary log %v", file)
+		}
+		if bm.FirstTimestamp == "" || archived.AtLeast(bm.Position) {
+			// No transactions, or all of them were archived already.
+			continue
+		}
+		bm.BinlogFile = file
+		bm.TabletAlias = params.TabletAlias
+		bm.SkipCompress = !*backupStorageCompress
+		bm.CompressionEngine, bm.ExternalDecompressor = manifestCompression()
+		if err := archiveBinlog(ctx, bs, dir, path.Join(binlogDir, file), bm, params.Logger); err != nil {
+			return count, vterrors.Wrapf(err, "can't archive binary log %v", file)
+		}
+		params.Logger.Infof("Archived binary log %v up to %v", file, bm.Position)
+		archived = unionPositions(archived, bm.Position)
+		count++
+	}
+	return count, nil
+}
+
+// archivedBinlogKey identifies a binary log of a tablet. The names of the
+// binary logs are only unique within a tablet.
+func archivedBinlogKey(tabletAlias, file string) string {
+	return tabletAlias + "/" + file
+}
+
+// archiveBinlog uploads a binary log and its MANIFEST. The name of the
+// archive starts with the time of the first transaction, so that archives
+// are listed in order.
+func archiveBinlog(ctx context.Context, bs backupstorage.BackupStorage, dir, file string, bm *BinlogArchiveManifest, logger logutil.Logger) (finalErr error) {
+	firstTime, err := time.Parse(time.RFC3339, bm.FirstTimestamp)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%v.%v.%v", firstTime.UTC().Format(BackupTimestampFormat), bm.TabletAlias, bm.BinlogFile)
+	bh, err := bs.StartBackup(ctx, dir, name)
+	if err != nil {
+		return vterrors.Wrap(err, "StartBackup failed")
+	}
+	defer func() {
+		if finalErr != nil {
+			if err := bh.AbortBackup(ctx); err != nil {
+				log.Errorf("failed to abort binary log archive %v: %v", name, err)
+			}
+			return
+		}
+		finalErr = bh.EndBackup(ctx)
+	}()
+
+	if err := uploadBinlog(ctx, bh, file, bm, logger); err != nil {
+		return err
+	}
+
+	// The MANIFEST goes last: archives without one are incomplete.
+	data, err := json.MarshalIndent(bm, "", "  ")
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot JSON encode %v", backupManifestFileName)
+	}
+	wc, err := bh.AddFile(ctx, backupManifestFileName, int64(len(data)))
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot add %v to binary log archive", backupManifestFileName)
+	}
+	if _, err := wc.Write(data); err != nil {
+		wc.Close()
+		return vterrors.Wrapf(err, "cannot write %v", backupManifestFileName)
+	}
+	return wc.Close()
+}
+
+func uploadBinlog(ctx context.Context, bh backupstorage.BackupHandle, file string, bm *BinlogArchiveManifest, logger logutil.Logger) (finalErr error) {
+	source, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	fi, err := source.Stat()
+	if err != nil {
+		return err
+	}
+
+	wc, err := bh.AddFile(ctx, binlogArchiveFileName, fi.Size())
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot add %v to binary log archive", binlogArchiveFileName)
+	}
+	defer func() {
+		if cerr := wc.Close(); finalErr == nil {
+			finalErr = cerr
+		}
+	}()
+	dst := bufio.NewWriterSize(wc, writerBufferSize)
+
+	var writer io.Writer = dst
+	encryption, dataKey, err := newBackupEncryption(ctx)
+	if err != nil {
+		return err
+	}
+	bm.Encryption = encryption
+	var encryptor io.WriteCloser
+	if dataKey != nil {
+		encryptor, err = newEncryptingWriter(writer, dataKey)
+		if err != nil {
+			return vterrors.Wrap(err, "cannot create encryptor")
+		}
+		writer = encryptor
+	}
+	var compressor io.WriteCloser
+	if !bm.SkipCompress {
+		compressor, err = newCompressor(ctx, bm.CompressionEngine, writer, logger)
+		if err != nil {
+			return vterrors.Wrap(err, "cannot create compressor")
+		}
+		writer = compressor
+	}
+	if _, err := io.Copy(writer, source); err != nil {
+		return vterrors.Wrap(err, "cannot copy data")
+	}
+	if compressor != nil {
+		if err := compressor.Close(); err != nil {
+			return vterrors.Wrap(err, "cannot close compressor")
+		}
+	}
+	if encryptor != nil {
+		if err := encryptor.Close(); err != nil {
+			return vterrors.Wrap(err, "cannot close encryptor")
+		}
+	}
+	if err := dst.Flush(); err != nil {
+		return vterrors.Wrapf(err, "cannot flush destination: %v", binlogArchiveFileName)
+	}
+	return nil
+}
+
+// readBinlogManifest reads a binary log file, and returns its GTID range
+// and the times of its first and last transactions. FirstTimestamp is
+// left empty if the binary log has no transactions.
+func readBinlogManifest(file string) (*BinlogArchiveManifest, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	reader := bufio.NewReader(f)
+
+	magic := make([]byte, len(binlogMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(magic, binlogMagic) {
+		return nil, fmt.Errorf("not a binary log: %v", file)
+	}
+
+	bm := &BinlogArchiveManifest{}
+	var format mysql.BinlogFormat
+	var first, last uint32
+	header := make([]byte, binlogEventHeaderLength)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		length := binary.LittleEndian.Uint32(header[9:13])
+		if length < binlogEventHeaderLength {
+			return nil, fmt.Errorf("invalid event length %v in binary log %v", length, file)
+		}
+		buf := make([]byte, length)
+		copy(buf, header)
+		if _, err := io.ReadFull(reader, buf[binlogEventHeaderLength:]); err != nil {
+			return nil, err
+		}
+
+		ev := mysql.NewMysql56BinlogEvent(buf)
+		if !ev.IsValid() {
+			return nil, fmt.Errorf("invalid event in binary log %v", file)
+		}
+		if ev.IsFormatDescription() {
+			if format, err = ev.Format(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if format.IsZero() {
+			return nil, fmt.Errorf("binary log %v has no format description event", file)
+		}
+		if ev, _, err = ev.StripChecksum(format); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case ev.IsPreviousGTIDs():
+			pos, err := ev.PreviousGTIDs(format)
+			if err != nil {
+				return nil, err
+			}
+			bm.PreviousPosition = pos
+			bm.Position = pos
+		case ev.IsGTID():
+			gtid, _, err := ev.GTID(format)
+			if err != nil {
+				return nil, err
+			}
+			bm.Position = mysql.AppendGTID(bm.Position, gtid)
+			if first == 0 {
+				first = ev.Timestamp()
+			}
+		case ev.IsRotate():
+			// Rotate events don't belong to transactions.
+			continue
+		}
+		if first != 0 && ev.Timestamp() > last {
+			last = ev.Timestamp()
+		}
+	}
+
+	if first != 0 {
+		bm.FirstTimestamp = time.Unix(int64(first), 0).UTC().Format(time.RFC3339)
+		bm.LastTimestamp = time.Unix(int64(last), 0).UTC().Format(time.RFC3339)
+	}
+	return bm, nil
+}
+
+// listBinlogArchives returns the complete binary log archives of a
+// directory, in order.
+func listBinlogArchives(ctx context.Context, bs backupstorage.BackupStorage, dir string, logger logutil.Logger) ([]*BinlogArchive, error) {
+	bhs, err := bs.ListBackups(ctx, dir)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "ListBackups failed")
+	}
+	var archives []*BinlogArchive
+	for _, bh := range bhs {
+		bm := &BinlogArchiveManifest{}
+		if err := getBackupManifestInto(ctx, bh, bm); err != nil {
+			logger.Warningf("Possibly incomplete binary log archive %v in directory %v on BackupStorage: %v", bh.Name(), dir, err)
+			continue
+		}
+		archives = append(archives, &BinlogArchive{Handle: bh, Manifest: bm})
+	}
+	return archives, nil
+}
+
+// FindBinlogsToRestore returns the archived binary logs to replay on top
+// of a backup taken at backupPos, to restore up to params.RestoreToPos or
+// params.RestoreToTime. It fails if the archived binary logs have a gap,
+// or don't go as far as the restore point.
+func FindBinlogsToRestore(ctx context.Context, bs backupstorage.BackupStorage, params RestoreParams, backupPos mysql.Position) ([]*BinlogArchive, error) {
+	dir := GetBinlogArchiveDir(params.Keyspace, params.Shard)
+	archives, err := listBinlogArchives(ctx, bs, dir, params.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	reached := func(pos mysql.Position) bool {
+		return !params.RestoreToPos.IsZero() && pos.AtLeast(params.RestoreToPos)
+	}
+	if reached(backupPos) {
+		return nil, nil
+	}
+
+	var result []*BinlogArchive
+	pos := backupPos
+	for _, archive := range archives {
+		bm := archive.Manifest
+		if pos.AtLeast(bm.Position) {
+			// All the transactions are in the backup, or in a previous archive.
+			continue
+		}
+		if !pos.AtLeast(bm.PreviousPosition) {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "binary log archive %v/%v starts at %v, which is after %v: some binary logs are missing", dir, archive.Handle.Name(), bm.PreviousPosition, pos)
+		}
+		result = append(result, archive)
+		pos = unionPositions(pos, bm.Position)
+
+		if reached(pos) {
+			return result, nil
+		}
+		if !params.RestoreToTime.IsZero() {
+			lastTime, err := time.Parse(time.RFC3339, bm.LastTimestamp)
+			if err != nil {
+				return nil, vterrors.Wrapf(err, "binary log archive %v/%v has an invalid LastTimestamp", dir, archive.Handle.Name())
+			}
+			if !lastTime.Before(params.RestoreToTime) {
+				return result, nil
+			}
+		}
+	}
+	if params.RestoreToTime.IsZero() {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the binary logs archived in %v only go up to %v, which doesn't reach the restore position %v", dir, pos, params.RestoreToPos)
+	}
+	return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the binary logs archived in %v only go up to %v, which doesn't reach the restore time %v", dir, pos, params.RestoreToTime.UTC().Format(time.RFC3339))
+}
+
+// restoreFromBinlogArchive replays the archived binary logs on top of the
+// backup that was just restored, up to the restore point. It returns the
+// position of mysqld once they're applied.
+func restoreFromBinlogArchive(ctx context.Context, bs backupstorage.BackupStorage, params RestoreParams, backupPos mysql.Position) (mysql.Position, error) {
+	archives, err := FindBinlogsToRestore(ctx, bs, params, backupPos)
+	if err != nil {
+		return mysql.Position{}, err
+	}
+	if len(archives) == 0 {
+		params.Logger.Infof("Restore: the backup is already at the restore point")
+		return backupPos, nil
+	}
+
+	tmpDir, err := os.MkdirTemp(params.Cnf.TmpDir, "restore_binlogs")
+	if err != nil {
+		return mysql.Position{}, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var files []string
+	for _, archive := range archives {
+		file := path.Join(tmpDir, archive.Manifest.BinlogFile)
+		params.Logger.Infof("Restore: downloading binary log archive %v", archive.Handle.Name())
+		if err := downloadBinlog(ctx, archive, file, params.Logger); err != nil {
+			return mysql.Position{}, vterrors.Wrapf(err, "can't download binary log archive %v", archive.Handle.Name())
+		}
+		files = append(files, file)
+	}
+
+	params.Logger.Infof("Restore: applying %v binary logs", len(files))
+	if err := params.Mysqld.ApplyBinlogFiles(ctx, files, params.RestoreToPos, params.RestoreToTime); err != nil {
+		return mysql.Position{}, vterrors.Wrap(err, "can't apply binary logs")
+	}
+	return params.Mysqld.PrimaryPosition()
+}
+
+func downloadBinlog(ctx context.Context, archive *BinlogArchive, file string, logger logutil.Logger) (finalErr error) {
+	source, err := archive.Handle.ReadFile(ctx, binlogArchiveFileName)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	dst, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := dst.Close(); finalErr == nil {
+			finalErr = cerr
+		}
+	}()
+
+	var reader io.Reader = source
+	dataKey, err := archive.Manifest.Encryption.dataKey(ctx)
+	if err != nil {
+		return err
+	}
+	if dataKey != nil {
+		reader, err = newDecryptingReader(reader, dataKey)
+		if err != nil {
+			return err
+		}
+	}
+	if !archive.Manifest.SkipCompress {
+		decompressor, err := newDecompressor(ctx, archive.Manifest.CompressionEngine, archive.Manifest.ExternalDecompressor, reader, logger)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := decompressor.Close(); finalErr == nil {
+				finalErr = cerr
+			}
+		}()
+		reader = decompressor
+	}
+	_, err = io.Copy(dst, reader)
+	return err
+}
+
+// ApplyBinlogFiles replays binary logs with mysqlbinlog, stopping at
+// restoreToPos or restoreToTime if they're set. Transactions that were
+// executed already are skipped by mysqld, thanks to their GTID.
+func (mysqld *Mysqld) ApplyBinlogFiles(ctx context.Context, binlogFiles []string, restoreToPos mysql.Position, restoreToTime time.Time) error {
+	dir, err := vtenv.VtMysqlRoot()
+	if err != nil {
+		return err
+	}
+	name, err := binaryPath(dir, "mysqlbinlog")
+	if err != nil {
+		return err
+	}
+	var args []string
+	if !restoreToPos.IsZero() {
+		args = append(args, "--include-gtids="+restoreToPos.GTIDSet.String())
+	}
+	if !restoreToTime.IsZero() {
+		args = append(args, "--stop-datetime="+restoreToTime.UTC().Format("2006-01-02 15:04:05"))
+	}
+	args = append(args, binlogFiles...)
+	env, err := buildLdPaths()
+	if err != nil {
+		return err
+	}
+	// mysqlbinlog reads --stop-datetime in the local time zone.
+	env = append(env, "TZ=UTC")
+
+	params, err := mysqld.dbcfgs.DbaConnector().MysqlParams()
+	if err != nil {
+		return err
+	}
+
+	log.Infof("ApplyBinlogFiles: %v %v", name, args)
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = env
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	scriptErr := mysqld.executeMysqlScript(params, stdout)
+	if scriptErr != nil {
+		// Don't leave mysqlbinlog blocked on a pipe nobody reads.
+		cmd.Process.Kill()
+	}
+	if err := cmd.Wait(); err != nil && scriptErr == nil {
+		return fmt.Errorf("mysqlbinlog: %v, output: %v", err, stderr.String())
+	}
+	return scriptErr
+}
+
+func unionPositions(a, b mysql.Position) mysql.Position {
+	if a.IsZero() {
+		return b
+	}
+	if b.IsZero() {
+		return a
+	}
+	return mysql.Position{GTIDSet: a.GTIDSet.Union(b.GTIDSet)}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl_test
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/fakemysqldaemon"
+	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
+)
+
+const testServerUUID = "00010203-0405-0607-0809-0a0b0c0d0e0f"
+
+// writeBinlog writes a binary log file that starts after the previous GTID
+// set, with one transaction per timestamp.
+func writeBinlog(t *testing.T, file, previous string, firstSequence int64, timestamps ...uint32) {
+	f := mysql.NewMySQL56BinlogFormat()
+	s := mysql.NewFakeBinlogStream()
+	content := []byte{0xfe, 'b', 'i', 'n'}
+	bytes := func(ev mysql.BinlogEvent) []byte {
+		return ev.(interface{ Bytes() []byte }).Bytes()
+	}
+	content = append(content, bytes(mysql.NewFormatDescriptionEvent(f, s))...)
+
+	pos, err := mysql.ParsePosition(mysql.Mysql56FlavorID, previous)
+	require.NoError(t, err)
+	content = append(content, s.Packetize(f, 35 /* PREVIOUS_GTIDS_EVENT */, 0, pos.GTIDSet.(mysql.Mysql56GTIDSet).SIDBlock())...)
+
+	sid, err := mysql.ParseSID(testServerUUID)
+	require.NoError(t, err)
+	for i, timestamp := range timestamps {
+		s.Timestamp = timestamp
+		data := make([]byte, 1+16+8)
+		copy(data[1:], sid[:])
+		binary.LittleEndian.PutUint64(data[17:], uint64(firstSequence+int64(i)))
+		content = append(content, s.Packetize(f, 33 /* GTID_EVENT */, 0, data)...)
+		content = append(content, bytes(mysql.NewXIDEvent(f, s))...)
+	}
+	s.Timestamp = 0
+	content = append(content, bytes(mysql.NewRotateEvent(f, s, 4, "next"))...)
+	require.NoError(t, os.WriteFile(file, content, 0644))
+}
+
+func TestBinlogArchive(t *testing.T) {
+	root := t.TempDir()
+	oldRoot := *filebackupstorage.FileBackupStorageRoot
+	oldImplementation := *backupstorage.BackupStorageImplementation
+	defer func() {
+		*filebackupstorage.FileBackupStorageRoot = oldRoot
+		*backupstorage.BackupStorageImplementation = oldImplementation
+	}()
+	*filebackupstorage.FileBackupStorageRoot = path.Join(root, "backups")
+	*backupstorage.BackupStorageImplementation = "file"
+
+	binlogDir := path.Join(root, "binlogs")
+	require.NoError(t, os.MkdirAll(binlogDir, 0755))
+	writeBinlog(t, path.Join(binlogDir, "vt-bin.000001"), testServerUUID+":1-2", 3, 1000, 1010)
+	writeBinlog(t, path.Join(binlogDir, "vt-bin.000002"), testServerUUID+":1-4", 5, 1020)
+	// The binary log mysqld is writing to.
+	writeBinlog(t, path.Join(binlogDir, "vt-bin.000003"), testServerUUID+":1-5", 6, 1030)
+
+	mysqld := fakemysqldaemon.NewFakeMysqlDaemon(fakesqldb.New(t))
+	defer mysqld.Close()
+	mysqld.FetchSuperQueryMap = map[string]*sqltypes.Result{
+		"SHOW BINARY LOGS": sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields("Log_name|File_size", "varchar|int64"),
+			"vt-bin.000001|1",
+			"vt-bin.000002|1",
+			"vt-bin.000003|1",
+		),
+	}
+
+	ctx := context.Background()
+	logger := logutil.NewMemoryLogger()
+	archiveParams := mysqlctl.ArchiveBinlogsParams{
+		Cnf:         &mysqlctl.Mycnf{BinLogPath: path.Join(binlogDir, "vt-bin")},
+		Mysqld:      mysqld,
+		Logger:      logger,
+		Keyspace:    "ks",
+		Shard:       "-80",
+		TabletAlias: "cell1-0000000100",
+	}
+	count, err := mysqlctl.ArchiveBinlogs(ctx, archiveParams)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Binary logs are archived only once, and aren't parsed again.
+	require.NoError(t, os.WriteFile(path.Join(binlogDir, "vt-bin.000001"), []byte("purged"), 0644))
+	count, err = mysqlctl.ArchiveBinlogs(ctx, archiveParams)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	bs, err := backupstorage.GetBackupStorage()
+	require.NoError(t, err)
+	defer bs.Close()
+
+	position := func(gtids string) mysql.Position {
+		return mysql.MustParsePosition(mysql.Mysql56FlavorID, testServerUUID+":"+gtids)
+	}
+	testcases := []struct {
+		name          string
+		backupPos     string
+		restoreToPos  string
+		restoreToTime int64
+		want          []string
+		wantErr       string
+	}{{
+		name:         "position in the first binary log",
+		backupPos:    "1-2",
+		restoreToPos: "1-3",
+		want:         []string{"vt-bin.000001"},
+	}, {
+		name:         "position in the last binary log",
+		backupPos:    "1-2",
+		restoreToPos: "1-5",
+		want:         []string{"vt-bin.000001", "vt-bin.000002"},
+	}, {
+		name:         "backup in the last binary log",
+		backupPos:    "1-4",
+		restoreToPos: "1-5",
+		want:         []string{"vt-bin.000002"},
+	}, {
+		name:         "backup at the position",
+		backupPos:    "1-5",
+		restoreToPos: "1-5",
+	}, {
+		name:          "time in the first binary log",
+		backupPos:     "1-2",
+		restoreToTime: 1005,
+		want:          []string{"vt-bin.000001"},
+	}, {
+		name:          "time between binary logs",
+		backupPos:     "1-2",
+		restoreToTime: 1015,
+		want:          []string{"vt-bin.000001", "vt-bin.000002"},
+	}, {
+		name:         "gap in the archive",
+		backupPos:    "1",
+		restoreToPos: "1-5",
+		wantErr:      "some binary logs are missing",
+	}, {
+		name:         "position not archived yet",
+		backupPos:    "1-2",
+		restoreToPos: "1-6",
+		wantErr:      "doesn't reach the restore position",
+	}, {
+		name:          "time not archived yet",
+		backupPos:     "1-2",
+		restoreToTime: 1030,
+		wantErr:       "doesn't reach the restore time",
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			params := mysqlctl.RestoreParams{
+				Logger:   logger,
+				Keyspace: "ks",
+				Shard:    "-80",
+			}
+			if tc.restoreToPos != "" {
+				params.RestoreToPos = position(tc.restoreToPos)
+			}
+			if tc.restoreToTime != 0 {
+				params.RestoreToTime = time.Unix(tc.restoreToTime, 0)
+			}
+			archives, err := mysqlctl.FindBinlogsToRestore(ctx, bs, params, position(tc.backupPos))
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			var got []string
+			for _, archive := range archives {
+				got = append(got, archive.Manifest.BinlogFile)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
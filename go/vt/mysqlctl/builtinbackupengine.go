@@ -28,9 +28,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/klauspost/pgzip"
-	"github.com/planetscale/pargzip"
-
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/vt/concurrency"
@@ -55,6 +52,10 @@ var (
 	// It can later be extended for other calls to mysqld during backup functions.
 	// Exported for testing.
 	BuiltinBackupMysqldTimeout = flag.Duration("builtinbackup_mysqld_timeout", 10*time.Minute, "how long to wait for mysqld to shutdown at the start of the backup")
+
+	// builtinBackupBlockSize is the size of the blocks incremental backups
+	// compare to their parent backup.
+	builtinBackupBlockSize = flag.Int("builtinbackup_incremental_block_size", 256*1024, "size of the blocks whose checksums are stored with every builtin backup, so that incremental backups taken on top of it only store the blocks that changed. If 0, no checksums are stored, and no incremental backup can be taken on top of new backups")
 )
 
 // BuiltinBackupEngine encapsulates the logic of the builtin engine
@@ -82,6 +83,20 @@ type builtinBackupManifest struct {
 	// false for backups that were created before the field existed, and those
 	// backups all had compression enabled.
 	SkipCompress bool
+
+	// CompressionEngine is the engine the files were compressed with. It is
+	// empty for backups taken before the field existed, which used gzip.
+	CompressionEngine string `json:",omitempty"`
+
+	// ExternalDecompressor is the command that decompresses the files, if
+	// they were compressed with the external engine. It is only run with
+	// the -external_decompressor_use_manifest flag.
+	ExternalDecompressor string `json:",omitempty"`
+
+	// BlockSize is the size of the blocks whose checksums are stored with
+	// each file. It is 0 if the backup has no checksums, in which case no
+	// incremental backup can be taken on top of it.
+	BlockSize int `json:",omitempty"`
 }
 
 // FileEntry is one file to backup
@@ -98,9 +113,21 @@ type FileEntry struct {
 	// Hash is the hash of the final data (transformed and
 	// compressed if specified) stored in the BackupStorage.
 	Hash string
+
+	// Incremental is true if the stored data is a patch with the blocks
+	// that changed since the parent backup, rather than the whole file.
+	Incremental bool `json:",omitempty"`
+
+	// Size is the size of the file, for incremental entries.
+	Size int64 `json:",omitempty"`
+
+	// StoredSize is the size of the data stored in the BackupStorage. It
+	// is used to report the progress of restores.
+	StoredSize int64 `json:",omitempty"`
 }
 
-func (fe *FileEntry) open(cnf *Mycnf, readOnly bool) (*os.File, error) {
+// path returns the local path of the file.
+func (fe *FileEntry) path(cnf *Mycnf) (string, error) {
 	// find the root to use
 	var root string
 	switch fe.Base {
@@ -111,13 +138,19 @@ func (fe *FileEntry) open(cnf *Mycnf, readOnly bool) (*os.File, error) {
 	case backupData:
 		root = cnf.DataDir
 	default:
-		return nil, vterrors.Errorf(vtrpc.Code_UNKNOWN, "unknown base: %v", fe.Base)
+		return "", vterrors.Errorf(vtrpc.Code_UNKNOWN, "unknown base: %v", fe.Base)
+	}
+	return path.Join(root, fe.Name), nil
+}
+
+func (fe *FileEntry) open(cnf *Mycnf, readOnly bool) (*os.File, error) {
+	name, err := fe.path(cnf)
+	if err != nil {
+		return nil, err
 	}
 
 	// and open the file
-	name := path.Join(root, fe.Name)
 	var fd *os.File
-	var err error
 	if readOnly {
 		if fd, err = os.Open(name); err != nil {
 			return nil, vterrors.Wrapf(err, "cannot open source file %v", name)
@@ -134,11 +167,30 @@ func (fe *FileEntry) open(cnf *Mycnf, readOnly bool) (*os.File, error) {
 	return fd, nil
 }
 
+// openForPatch opens the file to apply an incremental entry on top of the
+// content restored from the parent backups. Unlike open, it keeps the
+// existing content.
+func (fe *FileEntry) openForPatch(cnf *Mycnf) (*os.File, error) {
+	name, err := fe.path(cnf)
+	if err != nil {
+		return nil, err
+	}
+	dir := path.Dir(name)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, vterrors.Wrapf(err, "cannot create destination directory %v", dir)
+	}
+	fd, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "cannot open destination file %v", name)
+	}
+	return fd, nil
+}
+
 // ExecuteBackup returns a boolean that indicates if the backup is usable,
 // and an overall error.
 func (be *BuiltinBackupEngine) ExecuteBackup(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle) (bool, error) {
 
-	params.Logger.Infof("Hook: %v, Compress: %v", *backupStorageHook, *backupStorageCompress)
+	params.Logger.Infof("Hook: %v, Compress: %v, Compression engine: %v", *backupStorageHook, *backupStorageCompress, *compressionEngineName)
 
 	// Save initial state so we can restore.
 	replicaStartRequired := false
@@ -286,6 +338,25 @@ func (be *BuiltinBackupEngine) backupFiles(ctx context.Context, params BackupPar
 	}
 	params.Logger.Infof("found %v files to backup", len(fes))
 
+	// Generate the data key, if backups are encrypted.
+	encryption, dataKey, err := newBackupEncryption(ctx)
+	if err != nil {
+		return err
+	}
+
+	// For an incremental backup, find the files of the parent backup.
+	parent, err := be.readParentManifest(ctx, params)
+	if err != nil {
+		return err
+	}
+	var parentFiles map[string]int
+	if parent != nil {
+		parentFiles = make(map[string]int, len(parent.FileEntries))
+		for i, fe := range parent.FileEntries {
+			parentFiles[path.Join(fe.Base, fe.Name)] = i
+		}
+	}
+
 	// Backup with the provided concurrency.
 	sema := sync2.NewSemaphore(params.Concurrency, 0)
 	wg := sync.WaitGroup{}
@@ -302,9 +373,21 @@ func (be *BuiltinBackupEngine) backupFiles(ctx context.Context, params BackupPar
 				return
 			}
 
+			// Get the block checksums of the file in the parent
+			// backup, if any.
+			var parentChecksums []uint64
+			if j, ok := parentFiles[path.Join(fes[i].Base, fes[i].Name)]; ok {
+				checksums, err := readChecksums(ctx, params.ParentBackup, fmt.Sprintf("%v%v", j, checksumsFileSuffix))
+				if err != nil {
+					bh.RecordError(err)
+					return
+				}
+				parentChecksums = checksums
+			}
+
 			// Backup the individual file.
 			name := fmt.Sprintf("%v", i)
-			bh.RecordError(be.backupFile(ctx, params, bh, &fes[i], name))
+			bh.RecordError(be.backupFile(ctx, params, bh, &fes[i], name, parentChecksums, dataKey))
 		}(i)
 	}
 
@@ -341,12 +424,18 @@ func (be *BuiltinBackupEngine) backupFiles(ctx context.Context, params BackupPar
 			Position:     replicationPosition,
 			BackupTime:   params.BackupTime.UTC().Format(time.RFC3339),
 			FinishedTime: time.Now().UTC().Format(time.RFC3339),
+			Encryption:   encryption,
 		},
 
 		// Builtin-specific fields
 		FileEntries:   fes,
 		TransformHook: *backupStorageHook,
 		SkipCompress:  !*backupStorageCompress,
+		BlockSize:     *builtinBackupBlockSize,
+	}
+	bm.CompressionEngine, bm.ExternalDecompressor = manifestCompression()
+	if parent != nil {
+		bm.Parent = params.ParentBackup.Name()
 	}
 	data, err := json.MarshalIndent(bm, "", "  ")
 	if err != nil {
@@ -359,8 +448,32 @@ func (be *BuiltinBackupEngine) backupFiles(ctx context.Context, params BackupPar
 	return nil
 }
 
-// backupFile backs up an individual file.
-func (be *BuiltinBackupEngine) backupFile(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle, fe *FileEntry, name string) (finalErr error) {
+// readParentManifest returns the manifest of the backup to take an
+// incremental backup on top of. It returns nil if a full backup should
+// be taken.
+func (be *BuiltinBackupEngine) readParentManifest(ctx context.Context, params BackupParams) (*builtinBackupManifest, error) {
+	if params.ParentBackup == nil {
+		return nil, nil
+	}
+	if *builtinBackupBlockSize <= 0 {
+		params.Logger.Warningf("builtinbackup_incremental_block_size is not set, taking a full backup")
+		return nil, nil
+	}
+	var parent builtinBackupManifest
+	if err := getBackupManifestInto(ctx, params.ParentBackup, &parent); err != nil {
+		return nil, vterrors.Wrapf(err, "can't read parent backup %v", params.ParentBackup.Name())
+	}
+	if parent.BackupMethod != builtinBackupEngineName || parent.BlockSize != *builtinBackupBlockSize {
+		params.Logger.Warningf("parent backup %v was taken with engine %v and block size %v, taking a full backup", params.ParentBackup.Name(), parent.BackupMethod, parent.BlockSize)
+		return nil, nil
+	}
+	return &parent, nil
+}
+
+// backupFile backs up an individual file. If the checksums of the file in
+// the parent backup are set, only the blocks that changed are stored. If
+// dataKey is set, the file is encrypted with it.
+func (be *BuiltinBackupEngine) backupFile(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle, fe *FileEntry, name string, parentChecksums []uint64, dataKey []byte) (finalErr error) {
 	// Open the source file for reading.
 	source, err := fe.open(params.Cnf, true)
 	if err != nil {
@@ -393,7 +506,8 @@ func (be *BuiltinBackupEngine) backupFile(ctx context.Context, params BackupPara
 
 	// Create the hasher and the tee on top.
 	hasher := newHasher()
-	writer := io.MultiWriter(dst, hasher)
+	var stored byteCounter
+	writer := io.MultiWriter(dst, hasher, &stored)
 
 	// Create the external write pipe, if any.
 	var pipe io.WriteCloser
@@ -408,27 +522,51 @@ func (be *BuiltinBackupEngine) backupFile(ctx context.Context, params BackupPara
 		writer = pipe
 	}
 
-	// Create the gzip compression pipe, if necessary.
-	var gzip *pargzip.Writer
+	// Create the encryption pipe, if necessary.
+	var encryptor io.WriteCloser
+	if dataKey != nil {
+		encryptor, err = newEncryptingWriter(writer, dataKey)
+		if err != nil {
+			return vterrors.Wrap(err, "cannot create encryptor")
+		}
+		writer = encryptor
+	}
+
+	// Create the compression pipe, if necessary.
+	var compressor io.WriteCloser
 	if *backupStorageCompress {
-		gzip = pargzip.NewWriter(writer)
-		gzip.ChunkSize = *backupCompressBlockSize
-		gzip.Parallel = *backupCompressBlocks
-		gzip.CompressionLevel = pargzip.BestSpeed
-		writer = gzip
+		compressor, err = newCompressor(ctx, *compressionEngineName, writer, params.Logger)
+		if err != nil {
+			return vterrors.Wrap(err, "cannot create compressor")
+		}
+		writer = compressor
 	}
 
-	// Copy from the source file to writer (optional gzip,
-	// optional pipe, tee, output file and hasher).
-	_, err = io.Copy(writer, source)
+	// Copy from the source file to writer (optional compressor,
+	// optional pipe, tee, output file and hasher), computing the
+	// block checksums on the way.
+	var reader io.Reader = source
+	var blocks *blockReader
+	if *builtinBackupBlockSize > 0 {
+		blocks = newBlockReader(source, *builtinBackupBlockSize, parentChecksums)
+		reader = blocks
+	}
+	_, err = io.Copy(writer, reader)
 	if err != nil {
 		return vterrors.Wrap(err, "cannot copy data")
 	}
 
-	// Close gzip to flush it, after that all data is sent to writer.
-	if gzip != nil {
-		if err = gzip.Close(); err != nil {
-			return vterrors.Wrap(err, "cannot close gzip")
+	// Close the compressor to flush it, after that all data is sent to writer.
+	if compressor != nil {
+		if err = compressor.Close(); err != nil {
+			return vterrors.Wrap(err, "cannot close compressor")
+		}
+	}
+
+	// Close the encryptor to write the last chunk.
+	if encryptor != nil {
+		if err = encryptor.Close(); err != nil {
+			return vterrors.Wrap(err, "cannot close encryptor")
 		}
 	}
 
@@ -451,8 +589,21 @@ func (be *BuiltinBackupEngine) backupFile(ctx context.Context, params BackupPara
 		return vterrors.Wrapf(err, "cannot flush destination: %v", name)
 	}
 
-	// Save the hash.
+	// Save the block checksums, for incremental backups taken on top of
+	// this one.
+	if blocks != nil {
+		if err := writeChecksums(ctx, bh, name+checksumsFileSuffix, blocks.checksums); err != nil {
+			return err
+		}
+		if parentChecksums != nil {
+			fe.Incremental = true
+			fe.Size = blocks.size
+		}
+	}
+
+	// Save the hash and the size.
 	fe.Hash = hasher.HashString()
+	fe.StoredSize = int64(stored)
 	return nil
 }
 
@@ -467,29 +618,90 @@ func (be *BuiltinBackupEngine) ExecuteRestore(ctx context.Context, params Restor
 		return nil, err
 	}
 
-	// mark restore as in progress
-	if err := createStateFile(params.Cnf); err != nil {
+	// Mark restore as in progress. If a restore of the same backup was
+	// interrupted, keep the files it already restored.
+	rt, err := newRestoreTracker(params.Cnf, params.Logger, bh.Name())
+	if err != nil {
 		return nil, err
 	}
+	if rt.resumed() {
+		params.Logger.Infof("Restore: resuming the interrupted restore of %v", bh.Name())
+	}
 
-	if err := prepareToRestore(ctx, params.Cnf, params.Mysqld, params.Logger); err != nil {
+	if err := prepareToRestore(ctx, params.Cnf, params.Mysqld, params.Logger, rt.resumed()); err != nil {
 		return nil, err
 	}
 
+	// An incremental backup is restored by restoring the full backup, and
+	// applying each incremental backup on top of it in order.
+	pms := make([]builtinBackupManifest, len(params.ParentBackups))
+	for i, parent := range params.ParentBackups {
+		if err := getBackupManifestInto(ctx, parent, &pms[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	// Compute the size of the restore, to report its progress.
+	total, known := storedSize(bm.FileEntries)
+	skipped := rt.completedSize(bh.Name(), bm.FileEntries)
+	for i, pm := range pms {
+		size, ok := storedSize(pm.FileEntries)
+		total += size
+		known = known && ok
+		skipped += rt.completedSize(params.ParentBackups[i].Name(), pm.FileEntries)
+	}
+	if !known {
+		total = 0
+	}
+	rt.startProgress(total, skipped)
+	defer rt.stopProgress()
+
+	restored := make(map[string]FileEntry)
+	for i, parent := range params.ParentBackups {
+		pm := pms[i]
+		params.Logger.Infof("Restore: copying %v files from parent backup %v", len(pm.FileEntries), parent.Name())
+		if err := be.restoreFiles(context.Background(), params, parent, pm, rt); err != nil {
+			// don't delete the file here because that is how we detect an interrupted restore
+			return nil, vterrors.Wrapf(err, "failed to restore files from parent backup %v", parent.Name())
+		}
+		for _, fe := range pm.FileEntries {
+			restored[path.Join(fe.Base, fe.Name)] = fe
+		}
+	}
+
 	params.Logger.Infof("Restore: copying %v files", len(bm.FileEntries))
 
-	if err := be.restoreFiles(context.Background(), params, bh, bm); err != nil {
+	if err := be.restoreFiles(context.Background(), params, bh, bm, rt); err != nil {
 		// don't delete the file here because that is how we detect an interrupted restore
 		return nil, vterrors.Wrap(err, "failed to restore files")
 	}
 
+	// Remove the files that were deleted since the parent backups.
+	for _, fe := range bm.FileEntries {
+		delete(restored, path.Join(fe.Base, fe.Name))
+	}
+	for _, fe := range restored {
+		name, err := fe.path(params.Cnf)
+		if err != nil {
+			return nil, err
+		}
+		params.Logger.Infof("Restore: removing %v, deleted since the parent backups", name)
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			return nil, vterrors.Wrapf(err, "can't remove %v", name)
+		}
+	}
+
 	params.Logger.Infof("Restore: returning replication position %v", bm.Position)
 	return &bm.BackupManifest, nil
 }
 
 // restoreFiles will copy all the files from the BackupStorage to the
 // right place.
-func (be *BuiltinBackupEngine) restoreFiles(ctx context.Context, params RestoreParams, bh backupstorage.BackupHandle, bm builtinBackupManifest) error {
+func (be *BuiltinBackupEngine) restoreFiles(ctx context.Context, params RestoreParams, bh backupstorage.BackupHandle, bm builtinBackupManifest, rt *restoreTracker) error {
+	dataKey, err := bm.Encryption.dataKey(ctx)
+	if err != nil {
+		return err
+	}
 	fes := bm.FileEntries
 	sema := sync2.NewSemaphore(params.Concurrency, 0)
 	rec := concurrency.AllErrorRecorder{}
@@ -507,12 +719,23 @@ func (be *BuiltinBackupEngine) restoreFiles(ctx context.Context, params RestoreP
 				return
 			}
 
-			// And restore the file.
+			// Skip the file if it was restored before the restore
+			// was interrupted.
 			name := fmt.Sprintf("%v", i)
+			if rt.isCompleted(bh.Name(), name) {
+				params.Logger.Infof("Skipping file %v: %v, already restored", name, fes[i].Name)
+				return
+			}
+
+			// And restore the file.
 			params.Logger.Infof("Copying file %v: %v", name, fes[i].Name)
-			err := be.restoreFile(ctx, params, bh, &fes[i], bm.TransformHook, !bm.SkipCompress, name)
+			err := be.restoreFile(ctx, params, bh, &fes[i], bm, dataKey, name, rt)
 			if err != nil {
 				rec.RecordError(vterrors.Wrapf(err, "can't restore file %v to %v", name, fes[i].Name))
+				return
+			}
+			if err := rt.markCompleted(bh.Name(), name); err != nil {
+				rec.RecordError(vterrors.Wrapf(err, "can't record restored file %v", name))
 			}
 		}(i)
 	}
@@ -521,7 +744,10 @@ func (be *BuiltinBackupEngine) restoreFiles(ctx context.Context, params RestoreP
 }
 
 // restoreFile restores an individual file.
-func (be *BuiltinBackupEngine) restoreFile(ctx context.Context, params RestoreParams, bh backupstorage.BackupHandle, fe *FileEntry, transformHook string, compress bool, name string) (finalErr error) {
+func (be *BuiltinBackupEngine) restoreFile(ctx context.Context, params RestoreParams, bh backupstorage.BackupHandle, fe *FileEntry, bm builtinBackupManifest, dataKey []byte, name string, rt *restoreTracker) (finalErr error) {
+	transformHook := bm.TransformHook
+	compress := !bm.SkipCompress
+
 	// Open the source file for reading.
 	source, err := bh.ReadFile(ctx, name)
 	if err != nil {
@@ -529,8 +755,14 @@ func (be *BuiltinBackupEngine) restoreFile(ctx context.Context, params RestorePa
 	}
 	defer source.Close()
 
-	// Open the destination file for writing.
-	dstFile, err := fe.open(params.Cnf, false)
+	// Open the destination file for writing. Incremental entries are
+	// applied on top of the file restored from the parent backups.
+	var dstFile *os.File
+	if fe.Incremental {
+		dstFile, err = fe.openForPatch(params.Cnf)
+	} else {
+		dstFile, err = fe.open(params.Cnf, false)
+	}
 	if err != nil {
 		return vterrors.Wrap(err, "can't open destination file for writing")
 	}
@@ -545,15 +777,15 @@ func (be *BuiltinBackupEngine) restoreFile(ctx context.Context, params RestorePa
 		}
 	}()
 
-	// Create a buffering output.
-	dst := bufio.NewWriterSize(dstFile, 2*1024*1024)
+	// Create a buffering output, throttled if needed.
+	dst := bufio.NewWriterSize(rt.newWriter(ctx, dstFile), 2*1024*1024)
 
 	// Create hash to write the compressed data to.
 	hasher := newHasher()
 
-	// Create a Tee: we split the input into the hasher
-	// and into the gunziper.
-	reader := io.TeeReader(source, hasher)
+	// Create a Tee: we split the input, throttled and counted in the
+	// progress, into the hasher and into the gunziper.
+	reader := io.TeeReader(rt.newReader(ctx, source), hasher)
 
 	// Create the external read pipe, if any.
 	var wait hook.WaitFunc
@@ -566,27 +798,42 @@ func (be *BuiltinBackupEngine) restoreFile(ctx context.Context, params RestorePa
 		}
 	}
 
+	// Create the decrypter if needed.
+	if dataKey != nil {
+		reader, err = newDecryptingReader(reader, dataKey)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create the uncompresser if needed.
 	if compress {
-		gz, err := pgzip.NewReader(reader)
+		decompressor, err := newDecompressor(ctx, bm.CompressionEngine, bm.ExternalDecompressor, reader, params.Logger)
 		if err != nil {
-			return vterrors.Wrap(err, "can't open gzip decompressor")
+			return err
 		}
 		defer func() {
-			if cerr := gz.Close(); cerr != nil {
+			if cerr := decompressor.Close(); cerr != nil {
 				if finalErr != nil {
 					// We already have an error, just log this one.
-					log.Errorf("failed to close gzip decompressor %v: %v", name, cerr)
+					log.Errorf("failed to close decompressor %v: %v", name, cerr)
 				} else {
-					finalErr = vterrors.Wrap(err, "failed to close gzip decompressor")
+					finalErr = vterrors.Wrap(cerr, "failed to close decompressor")
 				}
 			}
 		}()
-		reader = gz
+		reader = decompressor
 	}
 
 	// Copy the data. Will also write to the hasher.
-	if _, err = io.Copy(dst, reader); err != nil {
+	if fe.Incremental {
+		if err := applyPatch(dstFile, reader, bm.BlockSize); err != nil {
+			return vterrors.Wrap(err, "failed to apply incremental changes")
+		}
+		if err := dstFile.Truncate(fe.Size); err != nil {
+			return vterrors.Wrap(err, "failed to truncate destination file")
+		}
+	} else if _, err = io.Copy(dst, reader); err != nil {
 		return vterrors.Wrap(err, "failed to copy file contents")
 	}
 
@@ -612,6 +859,12 @@ func (be *BuiltinBackupEngine) restoreFile(ctx context.Context, params RestorePa
 		return vterrors.Wrap(err, "failed to flush destination buffer")
 	}
 
+	// Sync the file, as it is recorded as restored once we return, and
+	// won't be restored again if the restore is interrupted.
+	if err := dstFile.Sync(); err != nil {
+		return vterrors.Wrap(err, "failed to sync destination file")
+	}
+
 	return nil
 }
 
@@ -6,6 +6,10 @@ package mysqlctl_test
 
 import (
 	"context"
+	"encoding/json"
+	"expvar"
+	"flag"
+	"fmt"
 	"os"
 	"path"
 	"testing"
@@ -14,9 +18,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/mysql/fakesqldb"
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
 	"vitess.io/vitess/go/vt/mysqlctl/fakemysqldaemon"
 	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
 	"vitess.io/vitess/go/vt/proto/topodata"
@@ -134,3 +140,235 @@ func TestExecuteBackup(t *testing.T) {
 	assert.Error(t, err)
 	assert.False(t, ok)
 }
+
+func TestExecuteIncrementalBackup(t *testing.T) {
+	root := t.TempDir()
+	oldRoot := *filebackupstorage.FileBackupStorageRoot
+	oldImplementation := *backupstorage.BackupStorageImplementation
+	defer func() {
+		*filebackupstorage.FileBackupStorageRoot = oldRoot
+		*backupstorage.BackupStorageImplementation = oldImplementation
+	}()
+	*filebackupstorage.FileBackupStorageRoot = path.Join(root, "backups")
+	*backupstorage.BackupStorageImplementation = "file"
+	require.NoError(t, flag.Set("builtinbackup_incremental_block_size", "4"))
+	defer flag.Set("builtinbackup_incremental_block_size", "262144")
+
+	newCnf := func(dir string) *mysqlctl.Mycnf {
+		return &mysqlctl.Mycnf{
+			InnodbDataHomeDir:     path.Join(root, dir, "innodb"),
+			InnodbLogGroupHomeDir: path.Join(root, dir, "log"),
+			DataDir:               path.Join(root, dir, "data"),
+			BinLogPath:            path.Join(root, dir, "bin-logs", "vt-bin"),
+			RelayLogPath:          path.Join(root, dir, "relay-logs", "vt-relay"),
+			RelayLogIndexPath:     path.Join(root, dir, "relay-logs", "vt-relay.index"),
+			RelayLogInfoPath:      path.Join(root, dir, "relay-logs", "relay-log.info"),
+		}
+	}
+	cnf := newCnf("source")
+	require.NoError(t, createBackupDir(root, "source/innodb", "source/log", "source/data/vt_db"))
+	writeFile := func(name, content string) {
+		require.NoError(t, os.WriteFile(path.Join(root, "source", name), []byte(content), 0644))
+	}
+	writeFile("innodb/ibdata1", "aaaabbbbccccdd")
+	writeFile("log/ib_logfile0", "log")
+	writeFile("data/vt_db/t1.ibd", "t1t1t1t1")
+	writeFile("data/vt_db/t2.ibd", "t2t2t2t2")
+
+	ctx := context.Background()
+	bs, err := backupstorage.GetBackupStorage()
+	require.NoError(t, err)
+	defer bs.Close()
+	be := &mysqlctl.BuiltinBackupEngine{}
+	mysqld := fakemysqldaemon.NewFakeMysqlDaemon(fakesqldb.New(t))
+	defer mysqld.Close()
+	mysqld.ReplicationStatusError = mysql.ErrNotReplica
+	backup := func(name string, parent backupstorage.BackupHandle) backupstorage.BackupHandle {
+		bh, err := bs.StartBackup(ctx, "ks/0", name)
+		require.NoError(t, err)
+		ok, err := be.ExecuteBackup(ctx, mysqlctl.BackupParams{
+			Cnf:          cnf,
+			Mysqld:       mysqld,
+			Logger:       logutil.NewConsoleLogger(),
+			Concurrency:  2,
+			HookExtraEnv: map[string]string{},
+			Keyspace:     "ks",
+			Shard:        "0",
+			ParentBackup: parent,
+		}, bh)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.NoError(t, bh.EndBackup(ctx))
+
+		// Return a read-only handle, as ListBackups does.
+		bhs, err := bs.ListBackups(ctx, "ks/0")
+		require.NoError(t, err)
+		for _, bh := range bhs {
+			if bh.Name() == name {
+				return bh
+			}
+		}
+		require.FailNow(t, "backup not found", name)
+		return nil
+	}
+
+	full := backup("full", nil)
+
+	// Change a block, grow, shrink and remove files.
+	writeFile("innodb/ibdata1", "aaaaBBBBccccddeeee")
+	writeFile("log/ib_logfile0", "lo")
+	require.NoError(t, os.Remove(path.Join(root, "source", "data/vt_db/t2.ibd")))
+	writeFile("data/vt_db/t3.ibd", "t3")
+	incremental := backup("incremental", full)
+
+	manifest, err := mysqlctl.GetBackupManifest(ctx, incremental)
+	require.NoError(t, err)
+	assert.Equal(t, "full", manifest.Parent)
+
+	cnf = newCnf("restored")
+	require.NoError(t, createBackupDir(root, "restored"))
+	_, err = be.ExecuteRestore(ctx, mysqlctl.RestoreParams{
+		Cnf:           cnf,
+		Mysqld:        mysqld,
+		Logger:        logutil.NewConsoleLogger(),
+		Concurrency:   2,
+		HookExtraEnv:  map[string]string{},
+		ParentBackups: []backupstorage.BackupHandle{full},
+	}, incremental)
+	require.NoError(t, err)
+
+	for name, want := range map[string]string{
+		"innodb/ibdata1":    "aaaaBBBBccccddeeee",
+		"log/ib_logfile0":   "lo",
+		"data/vt_db/t1.ibd": "t1t1t1t1",
+		"data/vt_db/t3.ibd": "t3",
+	} {
+		got, err := os.ReadFile(path.Join(root, "restored", name))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(got), name)
+	}
+	_, err = os.Stat(path.Join(root, "restored", "data/vt_db/t2.ibd"))
+	assert.True(t, os.IsNotExist(err), "t2.ibd should have been removed")
+}
+
+func TestExecuteRestoreResume(t *testing.T) {
+	root := t.TempDir()
+	oldRoot := *filebackupstorage.FileBackupStorageRoot
+	oldImplementation := *backupstorage.BackupStorageImplementation
+	defer func() {
+		*filebackupstorage.FileBackupStorageRoot = oldRoot
+		*backupstorage.BackupStorageImplementation = oldImplementation
+	}()
+	*filebackupstorage.FileBackupStorageRoot = path.Join(root, "backups")
+	*backupstorage.BackupStorageImplementation = "file"
+	require.NoError(t, flag.Set("restore_max_read_bytes_per_second", "1048576"))
+	defer flag.Set("restore_max_read_bytes_per_second", "0")
+	require.NoError(t, flag.Set("restore_max_write_bytes_per_second", "1048576"))
+	defer flag.Set("restore_max_write_bytes_per_second", "0")
+
+	newCnf := func(dir string) *mysqlctl.Mycnf {
+		return &mysqlctl.Mycnf{
+			InnodbDataHomeDir:     path.Join(root, dir, "innodb"),
+			InnodbLogGroupHomeDir: path.Join(root, dir, "log"),
+			DataDir:               path.Join(root, dir, "data"),
+			BinLogPath:            path.Join(root, dir, "bin-logs", "vt-bin"),
+			RelayLogPath:          path.Join(root, dir, "relay-logs", "vt-relay"),
+			RelayLogIndexPath:     path.Join(root, dir, "relay-logs", "vt-relay.index"),
+			RelayLogInfoPath:      path.Join(root, dir, "relay-logs", "relay-log.info"),
+		}
+	}
+	require.NoError(t, createBackupDir(root, "source/innodb", "source/log", "source/data/vt_db"))
+	for name, content := range map[string]string{
+		"innodb/ibdata1":    "ibdata1",
+		"log/ib_logfile0":   "log",
+		"data/vt_db/t1.ibd": "t1t1t1t1",
+		"data/vt_db/t2.ibd": "t2t2t2t2",
+	} {
+		require.NoError(t, os.WriteFile(path.Join(root, "source", name), []byte(content), 0644))
+	}
+
+	ctx := context.Background()
+	bs, err := backupstorage.GetBackupStorage()
+	require.NoError(t, err)
+	defer bs.Close()
+	be := &mysqlctl.BuiltinBackupEngine{}
+	mysqld := fakemysqldaemon.NewFakeMysqlDaemon(fakesqldb.New(t))
+	defer mysqld.Close()
+	mysqld.ReplicationStatusError = mysql.ErrNotReplica
+
+	bh, err := bs.StartBackup(ctx, "ks/0", "backup1")
+	require.NoError(t, err)
+	ok, err := be.ExecuteBackup(ctx, mysqlctl.BackupParams{
+		Cnf:          newCnf("source"),
+		Mysqld:       mysqld,
+		Logger:       logutil.NewConsoleLogger(),
+		Concurrency:  2,
+		HookExtraEnv: map[string]string{},
+		Keyspace:     "ks",
+		Shard:        "0",
+	}, bh)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, bh.EndBackup(ctx))
+	bhs, err := bs.ListBackups(ctx, "ks/0")
+	require.NoError(t, err)
+	require.Len(t, bhs, 1)
+	bh = bhs[0]
+
+	// Find the file t1.ibd is stored in.
+	rc, err := bh.ReadFile(ctx, "MANIFEST")
+	require.NoError(t, err)
+	var manifest struct {
+		FileEntries []mysqlctl.FileEntry
+	}
+	require.NoError(t, json.NewDecoder(rc).Decode(&manifest))
+	rc.Close()
+	t1 := -1
+	for i, fe := range manifest.FileEntries {
+		assert.NotZero(t, fe.StoredSize, fe.Name)
+		if fe.Name == "vt_db/t1.ibd" {
+			t1 = i
+		}
+	}
+	require.NotEqual(t, -1, t1)
+
+	// Pretend a restore of the backup was interrupted after restoring
+	// t1.ibd. It must not be restored again, nor deleted.
+	require.NoError(t, createBackupDir(root, "restored/data/vt_db"))
+	require.NoError(t, os.WriteFile(path.Join(root, "restored/data/vt_db/t1.ibd"), []byte("restored before"), 0644))
+	state := fmt.Sprintf(`{"Backup":"backup1","Completed":["backup1/%v"]}`, t1)
+	require.NoError(t, os.WriteFile(path.Join(root, "restored", mysqlctl.RestoreState), []byte(state), 0644))
+
+	logger := logutil.NewMemoryLogger()
+	_, err = be.ExecuteRestore(ctx, mysqlctl.RestoreParams{
+		Cnf:          newCnf("restored"),
+		Mysqld:       mysqld,
+		Logger:       logger,
+		Concurrency:  2,
+		HookExtraEnv: map[string]string{},
+	}, bh)
+	require.NoError(t, err)
+	assert.Contains(t, logger.String(), "resuming the interrupted restore of backup1")
+
+	for name, want := range map[string]string{
+		"innodb/ibdata1":    "ibdata1",
+		"log/ib_logfile0":   "log",
+		"data/vt_db/t1.ibd": "restored before",
+		"data/vt_db/t2.ibd": "t2t2t2t2",
+	} {
+		got, err := os.ReadFile(path.Join(root, "restored", name))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(got), name)
+	}
+
+	// All the files are recorded as restored, and in the progress.
+	data, err := os.ReadFile(path.Join(root, "restored", mysqlctl.RestoreState))
+	require.NoError(t, err)
+	for i := range manifest.FileEntries {
+		assert.Contains(t, string(data), fmt.Sprintf(`"backup1/%v"`, i))
+	}
+	total := expvar.Get("restore_bytes_total").String()
+	assert.NotEqual(t, "0", total)
+	assert.Equal(t, total, expvar.Get("restore_bytes_done").String())
+	assert.Equal(t, "-1", expvar.Get("restore_eta_seconds").String())
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/klauspost/pgzip"
+	"github.com/pierrec/lz4/v4"
+	"github.com/planetscale/pargzip"
+
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// The compression engines that can be used for backups. The name of the
+// engine is stored in the MANIFEST, so restores use the matching
+// decompressor whatever the current flags are.
+const (
+	// PargzipCompressor compresses with gzip, splitting the data into
+	// blocks compressed in parallel. It is the default.
+	PargzipCompressor = "pargzip"
+	// PgzipCompressor also compresses with gzip in parallel.
+	PgzipCompressor = "pgzip"
+	// ZstdCompressor compresses with zstd.
+	ZstdCompressor = "zstd"
+	// Lz4Compressor compresses with lz4.
+	Lz4Compressor = "lz4"
+	// ExternalCompressor runs the -external_compressor command, and the
+	// -external_decompressor command on restore.
+	ExternalCompressor = "external"
+)
+
+var (
+	// compressionEngineName is the compression engine used for new backups,
+	// if backup_storage_compress is set.
+	compressionEngineName = flag.String("compression_engine_name", PargzipCompressor, "compression engine used for new backups if backup_storage_compress is true: pargzip, pgzip, zstd, lz4 or external. Restores always use the engine the backup was taken with.")
+
+	// compressionLevel is the level passed to the gzip and zstd compressors.
+	compressionLevel = flag.Int("compression_level", 1, "compression level used by the pargzip, pgzip and zstd compression engines")
+
+	// externalCompressorCmd is the command run to compress backups with
+	// the external engine. It reads from stdin and writes to stdout.
+	externalCompressorCmd = flag.String("external_compressor", "", "command with arguments used to compress backups, if compression_engine_name is external. It must read the data from stdin and write the compressed data to stdout")
+
+	// externalCompressorExt is appended to the name of files compressed
+	// with the external engine, when the name shows the compression.
+	externalCompressorExt = flag.String("external_compressor_extension", "", "extension of the files compressed with the external compressor, e.g. .bz2")
+
+	// externalDecompressorCmd is the command run to decompress backups
+	// taken with the external engine. It is recorded in the MANIFEST.
+	externalDecompressorCmd = flag.String("external_decompressor", "", "command with arguments used to decompress backups taken with the external compression engine")
+
+	// externalDecompressorUseManifest allows running the decompressor
+	// command recorded in the MANIFEST. It is off by default, because
+	// whoever can write to the backup storage could then run any command
+	// on the tablets that restore.
+	externalDecompressorUseManifest = flag.Bool("external_decompressor_use_manifest", false, "if external_decompressor is not set, decompress the backups taken with the external compression engine with the command recorded in their MANIFEST. Only enable it if the backup storage is trusted, as it runs a command read from there")
+)
+
+// validateCompressionEngine returns an error if new backups can't be
+// compressed with the given engine.
+func validateCompressionEngine(engine string) error {
+	switch engine {
+	case PargzipCompressor, PgzipCompressor, ZstdCompressor, Lz4Compressor:
+		return nil
+	case ExternalCompressor:
+		if *externalCompressorCmd == "" {
+			return vterrors.New(vtrpc.Code_INVALID_ARGUMENT, "external_compressor must be set to use the external compression engine")
+		}
+		return nil
+	default:
+		return vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "unknown compression engine %q", engine)
+	}
+}
+
+// manifestCompression returns the compression engine and the external
+// decompressor to record in the MANIFEST of a new backup. They are empty
+// if backups are not compressed.
+func manifestCompression() (engine, externalDecompressor string) {
+	if !*backupStorageCompress {
+		return "", ""
+	}
+	if *compressionEngineName == ExternalCompressor {
+		return ExternalCompressor, *externalDecompressorCmd
+	}
+	return *compressionEngineName, ""
+}
+
+// compressionExtension returns the file extension of data compressed with
+// the given engine.
+func compressionExtension(engine string) string {
+	switch engine {
+	case ZstdCompressor:
+		return ".zst"
+	case Lz4Compressor:
+		return ".lz4"
+	case ExternalCompressor:
+		return *externalCompressorExt
+	default:
+		return ".gz"
+	}
+}
+
+// newCompressor returns a writer that compresses data into writer with the
+// given engine. Closing it flushes the compressed data, but doesn't close
+// writer.
+func newCompressor(ctx context.Context, engine string, writer io.Writer, logger logutil.Logger) (io.WriteCloser, error) {
+	switch engine {
+	case PargzipCompressor:
+		gzip := pargzip.NewWriter(writer)
+		gzip.ChunkSize = *backupCompressBlockSize
+		gzip.Parallel = *backupCompressBlocks
+		gzip.CompressionLevel = *compressionLevel
+		return gzip, nil
+	case PgzipCompressor:
+		gzip, err := pgzip.NewWriterLevel(writer, *compressionLevel)
+		if err != nil {
+			return nil, vterrors.Wrap(err, "can't create pgzip compressor")
+		}
+		if err := gzip.SetConcurrency(*backupCompressBlockSize, *backupCompressBlocks); err != nil {
+			return nil, vterrors.Wrap(err, "can't set pgzip concurrency")
+		}
+		return gzip, nil
+	case ZstdCompressor:
+		zst, err := zstd.NewWriter(writer, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(*compressionLevel)))
+		if err != nil {
+			return nil, vterrors.Wrap(err, "can't create zstd compressor")
+		}
+		return zst, nil
+	case Lz4Compressor:
+		return lz4.NewWriter(writer), nil
+	case ExternalCompressor:
+		return newExternalCompressor(ctx, *externalCompressorCmd, writer, logger)
+	default:
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "unknown compression engine %q", engine)
+	}
+}
+
+// newDecompressor returns a reader that decompresses the data read from
+// reader. engine and externalDecompressor are the fields recorded in the
+// MANIFEST of the backup. externalDecompressor is only used with the
+// -external_decompressor_use_manifest flag. Backups taken before the
+// engine was recorded were compressed with gzip.
+func newDecompressor(ctx context.Context, engine, externalDecompressor string, reader io.Reader, logger logutil.Logger) (io.ReadCloser, error) {
+	switch engine {
+	case "", PargzipCompressor, PgzipCompressor:
+		gz, err := pgzip.NewReader(reader)
+		if err != nil {
+			return nil, vterrors.Wrap(err, "can't open gzip decompressor")
+		}
+		return gz, nil
+	case ZstdCompressor:
+		zst, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, vterrors.Wrap(err, "can't open zstd decompressor")
+		}
+		return zst.IOReadCloser(), nil
+	case Lz4Compressor:
+		return io.NopCloser(lz4.NewReader(reader)), nil
+	case ExternalCompressor:
+		cmd := *externalDecompressorCmd
+		if cmd == "" && *externalDecompressorUseManifest {
+			cmd = externalDecompressor
+		}
+		if cmd == "" {
+			return nil, vterrors.New(vtrpc.Code_FAILED_PRECONDITION, "external_decompressor must be set to decompress a backup taken with the external compression engine")
+		}
+		return newExternalDecompressor(ctx, cmd, reader, logger)
+	default:
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "can't decompress backup compressed with unknown engine %q", engine)
+	}
+}
+
+// externalCompressor pipes the data through an external command.
+type externalCompressor struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stderr bytes.Buffer
+	logger logutil.Logger
+}
+
+func newExternalCompressor(ctx context.Context, cmdStr string, writer io.Writer, logger logutil.Logger) (io.WriteCloser, error) {
+	args := strings.Fields(cmdStr)
+	if len(args) == 0 {
+		return nil, vterrors.New(vtrpc.Code_INVALID_ARGUMENT, "empty external compressor command")
+	}
+	ec := &externalCompressor{
+		cmd:    exec.CommandContext(ctx, args[0], args[1:]...),
+		logger: logger,
+	}
+	ec.cmd.Stdout = writer
+	ec.cmd.Stderr = &ec.stderr
+	stdin, err := ec.cmd.StdinPipe()
+	if err != nil {
+		return nil, vterrors.Wrap(err, "can't create stdin pipe")
+	}
+	ec.stdin = stdin
+	logger.Infof("Running external compressor %v", args)
+	if err := ec.cmd.Start(); err != nil {
+		return nil, vterrors.Wrapf(err, "can't start external compressor %v", args)
+	}
+	return ec, nil
+}
+
+// Write is part of the io.Writer interface.
+func (ec *externalCompressor) Write(p []byte) (int, error) {
+	return ec.stdin.Write(p)
+}
+
+// Close is part of the io.Closer interface. It waits for the command to
+// write all the compressed data.
+func (ec *externalCompressor) Close() error {
+	if err := ec.stdin.Close(); err != nil {
+		return vterrors.Wrap(err, "can't close external compressor stdin")
+	}
+	if err := ec.cmd.Wait(); err != nil {
+		return vterrors.Wrapf(err, "external compressor failed: %v", ec.stderr.String())
+	}
+	if ec.stderr.Len() > 0 {
+		ec.logger.Infof("external compressor returned stderr: %v", ec.stderr.String())
+	}
+	return nil
+}
+
+// externalDecompressor reads the data from an external command.
+type externalDecompressor struct {
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	stderr bytes.Buffer
+	logger logutil.Logger
+}
+
+func newExternalDecompressor(ctx context.Context, cmdStr string, reader io.Reader, logger logutil.Logger) (io.ReadCloser, error) {
+	args := strings.Fields(cmdStr)
+	if len(args) == 0 {
+		return nil, vterrors.New(vtrpc.Code_INVALID_ARGUMENT, "empty external decompressor command")
+	}
+	ed := &externalDecompressor{
+		cmd:    exec.CommandContext(ctx, args[0], args[1:]...),
+		logger: logger,
+	}
+	ed.cmd.Stdin = reader
+	ed.cmd.Stderr = &ed.stderr
+	stdout, err := ed.cmd.StdoutPipe()
+	if err != nil {
+		return nil, vterrors.Wrap(err, "can't create stdout pipe")
+	}
+	ed.stdout = stdout
+	logger.Infof("Running external decompressor %v", args)
+	if err := ed.cmd.Start(); err != nil {
+		return nil, vterrors.Wrapf(err, "can't start external decompressor %v", args)
+	}
+	return ed, nil
+}
+
+// Read is part of the io.Reader interface.
+func (ed *externalDecompressor) Read(p []byte) (int, error) {
+	return ed.stdout.Read(p)
+}
+
+// Close is part of the io.Closer interface. It must be called once all
+// the data has been read.
+func (ed *externalDecompressor) Close() error {
+	// Closing stdout stops a command that still has data to write, if we
+	// stopped reading early.
+	ed.stdout.Close()
+	if err := ed.cmd.Wait(); err != nil {
+		return vterrors.Wrapf(err, "external decompressor failed: %v", ed.stderr.String())
+	}
+	if ed.stderr.Len() > 0 {
+		ed.logger.Infof("external decompressor returned stderr: %v", ed.stderr.String())
+	}
+	return nil
+}
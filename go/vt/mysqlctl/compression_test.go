@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/logutil"
+)
+
+func TestCompressionEngines(t *testing.T) {
+	defer func(saved string) { *externalCompressorCmd = saved }(*externalCompressorCmd)
+	defer func(saved string) { *externalDecompressorCmd = saved }(*externalDecompressorCmd)
+	*externalCompressorCmd = "gzip -c"
+	*externalDecompressorCmd = "gzip -d -c"
+
+	data := []byte(strings.Repeat("a backup file with some data to compress\n", 10000))
+	ctx := context.Background()
+	logger := logutil.NewMemoryLogger()
+	for _, engine := range []string{PargzipCompressor, PgzipCompressor, ZstdCompressor, Lz4Compressor, ExternalCompressor} {
+		t.Run(engine, func(t *testing.T) {
+			if engine == ExternalCompressor {
+				if _, err := exec.LookPath("gzip"); err != nil {
+					t.Skip("gzip is not installed")
+				}
+			}
+			require.NoError(t, validateCompressionEngine(engine))
+
+			var compressed bytes.Buffer
+			compressor, err := newCompressor(ctx, engine, &compressed, logger)
+			require.NoError(t, err)
+			_, err = compressor.Write(data)
+			require.NoError(t, err)
+			require.NoError(t, compressor.Close())
+			assert.Less(t, compressed.Len(), len(data))
+
+			decompressor, err := newDecompressor(ctx, engine, "", &compressed, logger)
+			require.NoError(t, err)
+			got, err := io.ReadAll(decompressor)
+			require.NoError(t, err)
+			require.NoError(t, decompressor.Close())
+			assert.Equal(t, data, got)
+		})
+	}
+
+	// Backups taken before the engine was recorded are gzip.
+	var compressed bytes.Buffer
+	compressor, err := newCompressor(ctx, PargzipCompressor, &compressed, logger)
+	require.NoError(t, err)
+	_, err = compressor.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, compressor.Close())
+	decompressor, err := newDecompressor(ctx, "", "", &compressed, logger)
+	require.NoError(t, err)
+	got, err := io.ReadAll(decompressor)
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+
+	assert.EqualError(t, validateCompressionEngine("bzip2"), `unknown compression engine "bzip2"`)
+	*externalCompressorCmd = ""
+	assert.Error(t, validateCompressionEngine(ExternalCompressor))
+
+	// The decompressor of the MANIFEST is only run when explicitly allowed.
+	*externalDecompressorCmd = ""
+	_, err = newDecompressor(ctx, ExternalCompressor, "gzip -d -c", &compressed, logger)
+	assert.EqualError(t, err, "external_decompressor must be set to decompress a backup taken with the external compression engine")
+	if _, err := exec.LookPath("gzip"); err == nil {
+		defer func(saved bool) { *externalDecompressorUseManifest = saved }(*externalDecompressorUseManifest)
+		*externalDecompressorUseManifest = true
+		compressed.Reset()
+		compressor, err = newCompressor(ctx, PgzipCompressor, &compressed, logger)
+		require.NoError(t, err)
+		_, err = compressor.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, compressor.Close())
+		decompressor, err = newDecompressor(ctx, ExternalCompressor, "gzip -d -c", &compressed, logger)
+		require.NoError(t, err)
+		got, err = io.ReadAll(decompressor)
+		require.NoError(t, err)
+		require.NoError(t, decompressor.Close())
+		assert.Equal(t, data, got)
+	}
+}
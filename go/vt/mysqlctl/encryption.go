@@ -0,0 +1,403 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// Backups can be encrypted with AES-GCM. Each backup has its own random
+// data key, that encrypts all its files. The data key is wrapped with a
+// key encryption key of a BackupKeyProvider, and stored in the MANIFEST
+// with the id of that key. Key encryption keys can be rotated: new backups
+// use the current key, while old backups keep referencing their own.
+//
+// The data is encrypted in chunks, as it's streamed:
+//   - 1 byte: version of the format
+//   - 7 bytes: random nonce prefix
+//   - chunks of encryptionChunkSize bytes of plaintext, sealed with a
+//     nonce made of the prefix, the index of the chunk, and whether
+//     it's the last chunk. The last chunk may be shorter, or empty.
+// The nonce makes reordered, truncated or extended data fail to decrypt.
+
+const (
+	encryptionVersion    = 1
+	encryptionPrefixSize = 7
+	encryptionChunkSize  = 64 * 1024
+	dataKeySize          = 32
+)
+
+var (
+	// backupEncryptionKeyProvider is the BackupKeyProvider new backups are
+	// encrypted with.
+	backupEncryptionKeyProvider = flag.String("backup_encryption_key_provider", "", "if set, backup files are encrypted with AES-GCM, with a data key wrapped by this key provider: file or vault. Restores always use the key provider recorded in the backup MANIFEST.")
+
+	// backupEncryptionKeyFile is the BackupKeySet of the file key provider.
+	backupEncryptionKeyFile = flag.String("backup_encryption_key_file", "", `path to the JSON file with the keys of the file backup key provider: {"current": "<key id>", "keys": {"<key id>": "<base64 AES key>"}}. It's read every time a key is needed, so keys can be rotated without a restart`)
+)
+
+// BackupKeyProvider wraps the data keys backups are encrypted with, using
+// its key encryption keys.
+type BackupKeyProvider interface {
+	// WrapKey encrypts dataKey with the current key encryption key,
+	// and returns the id of that key.
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrappedKey []byte, err error)
+
+	// UnwrapKey decrypts a data key wrapped by WrapKey with the key keyID.
+	UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error)
+}
+
+// BackupKeyProviderMap contains the registered implementations of
+// BackupKeyProvider.
+var BackupKeyProviderMap = make(map[string]BackupKeyProvider)
+
+func getBackupKeyProvider(name string) (BackupKeyProvider, error) {
+	kp, ok := BackupKeyProviderMap[name]
+	if !ok {
+		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "unknown backup key provider %q", name)
+	}
+	return kp, nil
+}
+
+// BackupEncryption describes how a backup is encrypted, in its MANIFEST.
+type BackupEncryption struct {
+	// KeyProvider is the name of the BackupKeyProvider that wrapped the
+	// data key.
+	KeyProvider string
+
+	// KeyID is the id of the key encryption key that wrapped the data key.
+	KeyID string
+
+	// WrappedKey is the data key the files are encrypted with, wrapped
+	// with the key encryption key.
+	WrappedKey []byte
+}
+
+// newBackupEncryption generates the data key of a new backup. It returns
+// nil if backups are not encrypted.
+func newBackupEncryption(ctx context.Context) (*BackupEncryption, []byte, error) {
+	if *backupEncryptionKeyProvider == "" {
+		return nil, nil, nil
+	}
+	kp, err := getBackupKeyProvider(*backupEncryptionKeyProvider)
+	if err != nil {
+		return nil, nil, err
+	}
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, vterrors.Wrap(err, "can't generate data key")
+	}
+	keyID, wrappedKey, err := kp.WrapKey(ctx, dataKey)
+	if err != nil {
+		return nil, nil, vterrors.Wrapf(err, "can't wrap data key with key provider %v", *backupEncryptionKeyProvider)
+	}
+	return &BackupEncryption{
+		KeyProvider: *backupEncryptionKeyProvider,
+		KeyID:       keyID,
+		WrappedKey:  wrappedKey,
+	}, dataKey, nil
+}
+
+// dataKey returns the unwrapped data key. It returns nil if e is nil,
+// i.e. the backup is not encrypted.
+func (e *BackupEncryption) dataKey(ctx context.Context) ([]byte, error) {
+	if e == nil {
+		return nil, nil
+	}
+	kp, err := getBackupKeyProvider(e.KeyProvider)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := kp.UnwrapKey(ctx, e.KeyID, e.WrappedKey)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "can't unwrap data key with key %v of key provider %v", e.KeyID, e.KeyProvider)
+	}
+	return dataKey, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "invalid encryption key")
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns the nonce of a chunk.
+func chunkNonce(nonce, prefix []byte, index uint32, last bool) []byte {
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[encryptionPrefixSize:], index)
+	nonce[encryptionPrefixSize+4] = 0
+	if last {
+		nonce[encryptionPrefixSize+4] = 1
+	}
+	return nonce
+}
+
+// encryptingWriter encrypts the data written to it into w. It must be
+// closed to write the last chunk.
+type encryptingWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	prefix []byte
+	nonce  []byte
+	index  uint32
+	buf    []byte
+	out    []byte
+}
+
+// newEncryptingWriter returns a writer that encrypts the data written to
+// it with dataKey, into w. Closing it doesn't close w.
+func newEncryptingWriter(w io.Writer, dataKey []byte) (io.WriteCloser, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, 1+encryptionPrefixSize)
+	header[0] = encryptionVersion
+	if _, err := rand.Read(header[1:]); err != nil {
+		return nil, vterrors.Wrap(err, "can't generate nonce")
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &encryptingWriter{
+		w:      w,
+		aead:   aead,
+		prefix: header[1:],
+		nonce:  make([]byte, aead.NonceSize()),
+		buf:    make([]byte, 0, encryptionChunkSize),
+	}, nil
+}
+
+// Write is part of the io.Writer interface.
+func (ew *encryptingWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// Only seal a full chunk once we know it's not the last one.
+		if len(ew.buf) == encryptionChunkSize {
+			if err := ew.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(ew.buf[len(ew.buf):encryptionChunkSize], p)
+		ew.buf = ew.buf[:len(ew.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close is part of the io.Closer interface.
+func (ew *encryptingWriter) Close() error {
+	return ew.seal(true)
+}
+
+func (ew *encryptingWriter) seal(last bool) error {
+	if ew.index == ^uint32(0) {
+		return vterrors.New(vtrpc.Code_OUT_OF_RANGE, "too much data to encrypt in a single stream")
+	}
+	ew.out = ew.aead.Seal(ew.out[:0], chunkNonce(ew.nonce, ew.prefix, ew.index, last), ew.buf, nil)
+	if _, err := ew.w.Write(ew.out); err != nil {
+		return err
+	}
+	ew.index++
+	ew.buf = ew.buf[:0]
+	return nil
+}
+
+// decryptingReader decrypts the data written by an encryptingWriter.
+type decryptingReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	prefix  []byte
+	nonce   []byte
+	index   uint32
+	buf     []byte
+	plain   []byte
+	pending []byte
+	done    bool
+}
+
+// newDecryptingReader returns a reader that decrypts the data read from r
+// with dataKey.
+func newDecryptingReader(r io.Reader, dataKey []byte) (io.Reader, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, 1+encryptionPrefixSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, vterrors.Wrap(err, "can't read encryption header")
+	}
+	if header[0] != encryptionVersion {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "unsupported encryption format version %v", header[0])
+	}
+	return &decryptingReader{
+		r:      bufio.NewReaderSize(r, encryptionChunkSize+aead.Overhead()),
+		aead:   aead,
+		prefix: header[1:],
+		nonce:  make([]byte, aead.NonceSize()),
+		buf:    make([]byte, encryptionChunkSize+aead.Overhead()),
+	}, nil
+}
+
+// Read is part of the io.Reader interface.
+func (dr *decryptingReader) Read(p []byte) (int, error) {
+	for len(dr.pending) == 0 {
+		if dr.done {
+			return 0, io.EOF
+		}
+		if err := dr.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, dr.pending)
+	dr.pending = dr.pending[n:]
+	return n, nil
+}
+
+func (dr *decryptingReader) open() error {
+	n, err := io.ReadFull(dr.r, dr.buf)
+	last := false
+	switch err {
+	case nil:
+		// A full chunk is the last one if nothing follows it.
+		if _, err := dr.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	case io.EOF, io.ErrUnexpectedEOF:
+		last = true
+	default:
+		return err
+	}
+	dr.plain, err = dr.aead.Open(dr.plain[:0], chunkNonce(dr.nonce, dr.prefix, dr.index, last), dr.buf[:n], nil)
+	if err != nil {
+		return vterrors.Errorf(vtrpc.Code_DATA_LOSS, "can't decrypt chunk %v: the data is corrupted, or the key is wrong", dr.index)
+	}
+	dr.pending = dr.plain
+	dr.index++
+	dr.done = last
+	return nil
+}
+
+// BackupKeySet is a set of key encryption keys. Its JSON format is the one
+// used by the file and vault key providers:
+//   {"current": "key2", "keys": {"key1": "<base64 AES key>", "key2": "<base64 AES key>"}}
+// New data keys are wrapped with the current key. Old keys must be kept as
+// long as backups reference them.
+type BackupKeySet struct {
+	Current string            `json:"current"`
+	Keys    map[string][]byte `json:"keys"`
+}
+
+// ParseBackupKeySet parses a JSON BackupKeySet.
+func ParseBackupKeySet(data []byte) (*BackupKeySet, error) {
+	ks := &BackupKeySet{}
+	if err := json.Unmarshal(data, ks); err != nil {
+		return nil, vterrors.Wrap(err, "can't parse backup key set")
+	}
+	if _, ok := ks.Keys[ks.Current]; !ok {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "current key %q is not in the backup key set", ks.Current)
+	}
+	return ks, nil
+}
+
+// WrapKey wraps dataKey with the current key.
+func (ks *BackupKeySet) WrapKey(dataKey []byte) (string, []byte, error) {
+	aead, err := newAEAD(ks.Keys[ks.Current])
+	if err != nil {
+		return "", nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, vterrors.Wrap(err, "can't generate nonce")
+	}
+	return ks.Current, aead.Seal(nonce, nonce, dataKey, []byte(ks.Current)), nil
+}
+
+// UnwrapKey unwraps a data key wrapped with the key keyID.
+func (ks *BackupKeySet) UnwrapKey(keyID string, wrappedKey []byte) ([]byte, error) {
+	key, ok := ks.Keys[keyID]
+	if !ok {
+		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "key %q is not in the backup key set", keyID)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrappedKey) < aead.NonceSize() {
+		return nil, vterrors.New(vtrpc.Code_DATA_LOSS, "wrapped key is too short")
+	}
+	nonce, sealed := wrappedKey[:aead.NonceSize()], wrappedKey[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, sealed, []byte(keyID))
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpc.Code_DATA_LOSS, "can't unwrap data key with key %q", keyID)
+	}
+	return dataKey, nil
+}
+
+// fileBackupKeyProvider is a BackupKeyProvider that reads its BackupKeySet
+// from the -backup_encryption_key_file file.
+type fileBackupKeyProvider struct{}
+
+func (fileBackupKeyProvider) keySet() (*BackupKeySet, error) {
+	if *backupEncryptionKeyFile == "" {
+		return nil, vterrors.New(vtrpc.Code_FAILED_PRECONDITION, "backup_encryption_key_file must be set to use the file backup key provider")
+	}
+	data, err := os.ReadFile(*backupEncryptionKeyFile)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "can't read backup key file")
+	}
+	return ParseBackupKeySet(data)
+}
+
+// WrapKey is part of the BackupKeyProvider interface.
+func (kp fileBackupKeyProvider) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	ks, err := kp.keySet()
+	if err != nil {
+		return "", nil, err
+	}
+	return ks.WrapKey(dataKey)
+}
+
+// UnwrapKey is part of the BackupKeyProvider interface.
+func (kp fileBackupKeyProvider) UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error) {
+	ks, err := kp.keySet()
+	if err != nil {
+		return nil, err
+	}
+	return ks.UnwrapKey(keyID, wrappedKey)
+}
+
+func init() {
+	BackupKeyProviderMap["file"] = fileBackupKeyProvider{}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encrypt(t *testing.T, data, dataKey []byte) []byte {
+	var encrypted bytes.Buffer
+	encryptor, err := newEncryptingWriter(&encrypted, dataKey)
+	require.NoError(t, err)
+	_, err = encryptor.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, encryptor.Close())
+	return encrypted.Bytes()
+}
+
+func decrypt(data, dataKey []byte) ([]byte, error) {
+	decryptor, err := newDecryptingReader(bytes.NewReader(data), dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(decryptor)
+}
+
+func TestEncryptionStream(t *testing.T) {
+	dataKey := make([]byte, dataKeySize)
+	_, err := rand.Read(dataKey)
+	require.NoError(t, err)
+
+	for _, size := range []int{0, 10, encryptionChunkSize, 3*encryptionChunkSize + 123} {
+		t.Run(fmt.Sprintf("%v bytes", size), func(t *testing.T) {
+			data := make([]byte, size)
+			_, err := rand.Read(data)
+			require.NoError(t, err)
+
+			encrypted := encrypt(t, data, dataKey)
+			got, err := decrypt(encrypted, dataKey)
+			require.NoError(t, err)
+			assert.Equal(t, len(data), len(got))
+			assert.True(t, bytes.Equal(data, got))
+
+			// Tampered data fails to decrypt.
+			tampered := append([]byte(nil), encrypted...)
+			tampered[len(tampered)-1] ^= 1
+			_, err = decrypt(tampered, dataKey)
+			assert.Error(t, err)
+
+			// So does truncated data, even at a chunk boundary.
+			if size > encryptionChunkSize {
+				truncated := encrypted[:1+encryptionPrefixSize+encryptionChunkSize+16]
+				_, err = decrypt(truncated, dataKey)
+				assert.Error(t, err)
+			}
+
+			// And the wrong key.
+			otherKey := make([]byte, dataKeySize)
+			_, err = decrypt(encrypted, otherKey)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFileBackupKeyProvider(t *testing.T) {
+	defer func(saved string) { *backupEncryptionKeyFile = saved }(*backupEncryptionKeyFile)
+	defer func(saved string) { *backupEncryptionKeyProvider = saved }(*backupEncryptionKeyProvider)
+	ctx := context.Background()
+
+	// Backups are not encrypted by default.
+	encryption, dataKey, err := newBackupEncryption(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, encryption)
+	assert.Nil(t, dataKey)
+
+	writeKeySet := func(ks *BackupKeySet) {
+		data, err := json.Marshal(ks)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(*backupEncryptionKeyFile, data, 0600))
+	}
+	newKey := func() []byte {
+		key := make([]byte, 32)
+		_, err := rand.Read(key)
+		require.NoError(t, err)
+		return key
+	}
+	*backupEncryptionKeyProvider = "file"
+	*backupEncryptionKeyFile = path.Join(t.TempDir(), "keys.json")
+	ks := &BackupKeySet{
+		Current: "key1",
+		Keys:    map[string][]byte{"key1": newKey()},
+	}
+	writeKeySet(ks)
+
+	encryption, dataKey, err = newBackupEncryption(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "file", encryption.KeyProvider)
+	assert.Equal(t, "key1", encryption.KeyID)
+	got, err := encryption.dataKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, got)
+
+	// After a rotation, new backups use the new key, and old ones can
+	// still be restored.
+	ks.Current = "key2"
+	ks.Keys["key2"] = newKey()
+	writeKeySet(ks)
+	newEncryption, _, err := newBackupEncryption(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "key2", newEncryption.KeyID)
+	got, err = encryption.dataKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, got)
+
+	// The key id is authenticated.
+	encryption.KeyID = "key2"
+	_, err = encryption.dataKey(ctx)
+	assert.Error(t, err)
+
+	// Removed keys can't be used anymore.
+	delete(ks.Keys, "key1")
+	writeKeySet(ks)
+	encryption.KeyID = "key1"
+	_, err = encryption.dataKey(ctx)
+	assert.Error(t, err)
+
+	_, err = ParseBackupKeySet([]byte(`{"current": "key3", "keys": {}}`))
+	assert.Error(t, err)
+}
@@ -160,6 +160,10 @@ type FakeMysqlDaemon struct {
 	// BinlogPlayerEnabled is used by {Enable,Disable}BinlogPlayer
 	BinlogPlayerEnabled sync2.AtomicBool
 
+	// AppliedBinlogFiles is the list of binary log files ApplyBinlogFiles
+	// was called with.
+	AppliedBinlogFiles []string
+
 	// SemiSyncMasterEnabled represents the state of rpl_semi_sync_master_enabled.
 	SemiSyncMasterEnabled bool
 	// SemiSyncReplicaEnabled represents the state of rpl_semi_sync_slave_enabled.
@@ -489,6 +493,12 @@ func (fmd *FakeMysqlDaemon) DisableBinlogPlayback() error {
 	return nil
 }
 
+// ApplyBinlogFiles is part of the MysqlDaemon interface
+func (fmd *FakeMysqlDaemon) ApplyBinlogFiles(ctx context.Context, binlogFiles []string, restoreToPos mysql.Position, restoreToTime time.Time) error {
+	fmd.AppliedBinlogFiles = append(fmd.AppliedBinlogFiles, binlogFiles...)
+	return nil
+}
+
 // Close is part of the MysqlDaemon interface
 func (fmd *FakeMysqlDaemon) Close() {
 	if fmd.appPool != nil {
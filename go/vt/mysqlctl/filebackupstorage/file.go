@@ -16,6 +16,12 @@ limitations under the License.
 
 // Package filebackupstorage implements the BackupStorage interface
 // for a local filesystem (which can be an NFS mount).
+//
+// With -file_backup_storage_atomic, backups are written to a temporary
+// directory, which is renamed to the name of the backup by EndBackup, after
+// all files are synced to disk. So ListBackups never returns a backup that
+// is being written, or that was left behind by a crash, even on NFS where
+// other clients may see the files of a backup in a different order.
 package filebackupstorage
 
 import (
@@ -24,6 +30,7 @@ import (
 	"io"
 	"os"
 	"path"
+	"strings"
 
 	"context"
 
@@ -35,8 +42,16 @@ var (
 	// FileBackupStorageRoot is where the backups will go.
 	// Exported for test purposes.
 	FileBackupStorageRoot = flag.String("file_backup_storage_root", "", "root directory for the file backup storage")
+
+	// FileBackupStorageAtomic makes backups appear atomically.
+	// Exported for test purposes.
+	FileBackupStorageAtomic = flag.Bool("file_backup_storage_atomic", false, "write backups to a temporary directory, with all files synced to disk, and rename it when the backup is complete, so incomplete backups are never listed. Recommended when the file backup storage root is on NFS")
 )
 
+// tmpPrefix is prepended to the name of a backup being written, with
+// -file_backup_storage_atomic. ListBackups ignores such directories.
+const tmpPrefix = ".tmp-"
+
 // FileBackupHandle implements BackupHandle for local file system.
 type FileBackupHandle struct {
 	fbs      *FileBackupStorage
@@ -44,6 +59,10 @@ type FileBackupHandle struct {
 	name     string
 	readOnly bool
 	errors   concurrency.AllErrorRecorder
+
+	// tmpName is the name of the directory the backup is written to,
+	// with -file_backup_storage_atomic.
+	tmpName string
 }
 
 // RecordError is part of the concurrency.ErrorRecorder interface.
@@ -76,8 +95,30 @@ func (fbh *FileBackupHandle) AddFile(ctx context.Context, filename string, files
 	if fbh.readOnly {
 		return nil, fmt.Errorf("AddFile cannot be called on read-only backup")
 	}
-	p := path.Join(*FileBackupStorageRoot, fbh.dir, fbh.name, filename)
-	return os.Create(p)
+	if fbh.tmpName == "" {
+		p := path.Join(*FileBackupStorageRoot, fbh.dir, fbh.name, filename)
+		return os.Create(p)
+	}
+	p := path.Join(*FileBackupStorageRoot, fbh.dir, fbh.tmpName, filename)
+	f, err := os.Create(p)
+	if err != nil {
+		return nil, err
+	}
+	return &syncedFile{f}, nil
+}
+
+// syncedFile is a file that is synced to disk when it's closed.
+type syncedFile struct {
+	*os.File
+}
+
+// Close is part of the io.Closer interface.
+func (f *syncedFile) Close() error {
+	if err := f.File.Sync(); err != nil {
+		f.File.Close()
+		return err
+	}
+	return f.File.Close()
 }
 
 // EndBackup is part of the BackupHandle interface
@@ -85,7 +126,33 @@ func (fbh *FileBackupHandle) EndBackup(ctx context.Context) error {
 	if fbh.readOnly {
 		return fmt.Errorf("EndBackup cannot be called on read-only backup")
 	}
-	return nil
+	if fbh.tmpName == "" {
+		return nil
+	}
+
+	// Make the backup appear atomically, once all its files are on disk.
+	dir := path.Join(*FileBackupStorageRoot, fbh.dir)
+	tmpPath := path.Join(dir, fbh.tmpName)
+	if err := syncDir(tmpPath); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path.Join(dir, fbh.name)); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir syncs the entries of a directory to disk.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		d.Close()
+		return err
+	}
+	return d.Close()
 }
 
 // AbortBackup is part of the BackupHandle interface
@@ -128,6 +195,10 @@ func (fbs *FileBackupStorage) ListBackups(ctx context.Context, dir string) ([]ba
 		if info.Name() == "." || info.Name() == ".." {
 			continue
 		}
+		if strings.HasPrefix(info.Name(), tmpPrefix) {
+			// A backup being written, or an abandoned one.
+			continue
+		}
 		result = append(result, &FileBackupHandle{
 			fbs:      fbs,
 			dir:      dir,
@@ -146,6 +217,26 @@ func (fbs *FileBackupStorage) StartBackup(ctx context.Context, dir, name string)
 		return nil, err
 	}
 
+	if *FileBackupStorageAtomic {
+		// Create a temporary subdirectory, if the backup doesn't exist yet.
+		if _, err := os.Stat(path.Join(p, name)); err == nil {
+			return nil, fmt.Errorf("backup %v already exists in %v", name, dir)
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+		tmpName := tmpPrefix + name
+		if err := os.Mkdir(path.Join(p, tmpName), os.ModePerm); err != nil {
+			return nil, err
+		}
+		return &FileBackupHandle{
+			fbs:      fbs,
+			dir:      dir,
+			name:     name,
+			readOnly: false,
+			tmpName:  tmpName,
+		}, nil
+	}
+
 	// Create the subdirectory for this named backup.
 	p = path.Join(p, name)
 	if err := os.Mkdir(p, os.ModePerm); err != nil {
@@ -162,6 +253,11 @@ func (fbs *FileBackupStorage) StartBackup(ctx context.Context, dir, name string)
 
 // RemoveBackup is part of the BackupStorage interface
 func (fbs *FileBackupStorage) RemoveBackup(ctx context.Context, dir, name string) error {
+	// Also remove the temporary directory of the backup, if it was written
+	// with -file_backup_storage_atomic and not completed.
+	if err := os.RemoveAll(path.Join(*FileBackupStorageRoot, dir, tmpPrefix+name)); err != nil {
+		return err
+	}
 	p := path.Join(*FileBackupStorageRoot, dir, name)
 	return os.RemoveAll(p)
 }
@@ -19,6 +19,7 @@ package filebackupstorage
 import (
 	"io"
 	"os"
+	"path"
 	"testing"
 
 	"context"
@@ -203,3 +204,73 @@ func TestFileContents(t *testing.T) {
 		t.Fatalf("rc.Close failed: %v", err)
 	}
 }
+
+func TestAtomicBackups(t *testing.T) {
+	fbs := setupFileBackupStorage(t)
+	defer cleanupFileBackupStorage(fbs)
+	defer func(saved bool) { *FileBackupStorageAtomic = saved }(*FileBackupStorageAtomic)
+	*FileBackupStorageAtomic = true
+	ctx := context.Background()
+
+	dir := "keyspace/shard"
+	name := "cell-0001-2015-01-14-10-00-00"
+	contents := "contents of the file"
+	bh, err := fbs.StartBackup(ctx, dir, name)
+	if err != nil {
+		t.Fatalf("fbs.StartBackup failed: %v", err)
+	}
+	wc, err := bh.AddFile(ctx, "file1", 0)
+	if err != nil {
+		t.Fatalf("bh.AddFile failed: %v", err)
+	}
+	if _, err := wc.Write([]byte(contents)); err != nil {
+		t.Fatalf("wc.Write failed: %v", err)
+	}
+	if err := wc.Close(); err != nil {
+		t.Fatalf("wc.Close failed: %v", err)
+	}
+
+	// the backup is not listed until it's complete
+	bhs, err := fbs.ListBackups(ctx, dir)
+	if err != nil || len(bhs) != 0 {
+		t.Fatalf("ListBackups of incomplete backup returned wrong results: %v %v", err, bhs)
+	}
+	if err := bh.EndBackup(ctx); err != nil {
+		t.Fatalf("bh.EndBackup failed: %v", err)
+	}
+	bhs, err = fbs.ListBackups(ctx, dir)
+	if err != nil || len(bhs) != 1 || bhs[0].Name() != name {
+		t.Fatalf("ListBackups of complete backup returned wrong results: %v %v", err, bhs)
+	}
+	rc, err := bhs[0].ReadFile(ctx, "file1")
+	if err != nil {
+		t.Fatalf("bhs[0].ReadFile failed: %v", err)
+	}
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil || string(data) != contents {
+		t.Fatalf("ReadFile returned wrong result: %v %q", err, data)
+	}
+
+	// a complete backup can't be written again
+	if _, err := fbs.StartBackup(ctx, dir, name); err == nil {
+		t.Fatalf("was able to StartBackup an existing backup")
+	}
+
+	// abandoned backups are not listed, and can be removed
+	secondBackup := "cell-0001-2015-01-15-10-00-00"
+	if _, err := fbs.StartBackup(ctx, dir, secondBackup); err != nil {
+		t.Fatalf("fbs.StartBackup failed: %v", err)
+	}
+	bhs, err = fbs.ListBackups(ctx, dir)
+	if err != nil || len(bhs) != 1 || bhs[0].Name() != name {
+		t.Fatalf("ListBackups with abandoned backup returned wrong results: %v %v", err, bhs)
+	}
+	if err := fbs.RemoveBackup(ctx, dir, secondBackup); err != nil {
+		t.Fatalf("RemoveBackup failed: %v", err)
+	}
+	entries, err := os.ReadDir(path.Join(*FileBackupStorageRoot, dir))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("RemoveBackup of abandoned backup left wrong entries: %v %v", err, entries)
+	}
+}
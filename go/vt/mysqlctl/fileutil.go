@@ -36,3 +36,11 @@ func newHasher() *hasher {
 func (h *hasher) HashString() string {
 	return hex.EncodeToString(h.Sum(nil))
 }
+
+// byteCounter is an io.Writer that counts the bytes written to it.
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/crc64"
+	"io"
+	"os"
+
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// Incremental backups of the builtin engine work at the block level. Every
+// builtin backup stores, next to each file, the checksums of the blocks of
+// that file. An incremental backup compares the blocks of each file to the
+// checksums stored in its parent backup, and only stores the blocks that
+// changed, as a patch:
+//   - 8 bytes: index of the block in the file (little endian)
+//   - 4 bytes: length of the block (little endian)
+//   - the content of the block
+// The patch goes through the same transform hook and compression as a whole
+// file would.
+
+const (
+	// checksumsFileSuffix is appended to the name of a file in the backup
+	// to get the name of the file with its block checksums.
+	checksumsFileSuffix = "-checksums"
+
+	patchHeaderLength = 8 + 4
+)
+
+var checksumTable = crc64.MakeTable(crc64.ECMA)
+
+// blockReader reads a file block by block, and computes the checksums of
+// its blocks. If the checksums of the parent backup are set, it only returns
+// the blocks that changed, encoded as a patch. Otherwise, it returns the
+// content of the file as is.
+type blockReader struct {
+	source    io.Reader
+	blockSize int
+	parent    []uint64
+
+	// checksums and size are the checksums of the blocks and the size of
+	// the file, once it's been read entirely.
+	checksums []uint64
+	size      int64
+
+	block   []byte
+	out     []byte
+	pending []byte
+	err     error
+}
+
+func newBlockReader(source io.Reader, blockSize int, parent []uint64) *blockReader {
+	return &blockReader{
+		source:    source,
+		blockSize: blockSize,
+		parent:    parent,
+		block:     make([]byte, blockSize),
+	}
+}
+
+// Read is part of the io.Reader interface.
+func (br *blockReader) Read(p []byte) (int, error) {
+	for len(br.pending) == 0 {
+		if br.err != nil {
+			return 0, br.err
+		}
+		br.nextBlock()
+	}
+	n := copy(p, br.pending)
+	br.pending = br.pending[n:]
+	return n, nil
+}
+
+func (br *blockReader) nextBlock() {
+	n, err := io.ReadFull(br.source, br.block)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	br.err = err
+	if n == 0 {
+		return
+	}
+
+	data := br.block[:n]
+	index := len(br.checksums)
+	checksum := crc64.Checksum(data, checksumTable)
+	br.checksums = append(br.checksums, checksum)
+	br.size += int64(n)
+
+	switch {
+	case br.parent == nil:
+		br.pending = data
+	case index < len(br.parent) && br.parent[index] == checksum:
+		// Unchanged since the parent backup.
+	default:
+		var header [patchHeaderLength]byte
+		binary.LittleEndian.PutUint64(header[0:8], uint64(index))
+		binary.LittleEndian.PutUint32(header[8:12], uint32(n))
+		br.out = append(append(br.out[:0], header[:]...), data...)
+		br.pending = br.out
+	}
+}
+
+// applyPatch writes the blocks of a patch read from reader into dst.
+func applyPatch(dst *os.File, reader io.Reader, blockSize int) error {
+	header := make([]byte, patchHeaderLength)
+	block := make([]byte, blockSize)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return vterrors.Wrap(err, "can't read patch header")
+		}
+		index := binary.LittleEndian.Uint64(header[0:8])
+		length := binary.LittleEndian.Uint32(header[8:12])
+		if int(length) > blockSize {
+			return vterrors.Errorf(vtrpc.Code_INTERNAL, "invalid patch: block %v has %v bytes, more than the block size %v", index, length, blockSize)
+		}
+		if _, err := io.ReadFull(reader, block[:length]); err != nil {
+			return vterrors.Wrapf(err, "can't read block %v of patch", index)
+		}
+		if _, err := dst.WriteAt(block[:length], int64(index)*int64(blockSize)); err != nil {
+			return vterrors.Wrapf(err, "can't write block %v", index)
+		}
+	}
+}
+
+// writeChecksums stores the block checksums of a file in the backup.
+func writeChecksums(ctx context.Context, bh backupstorage.BackupHandle, name string, checksums []uint64) (finalErr error) {
+	data := make([]byte, 8*len(checksums))
+	for i, checksum := range checksums {
+		binary.LittleEndian.PutUint64(data[8*i:], checksum)
+	}
+	wc, err := bh.AddFile(ctx, name, int64(len(data)))
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot add file: %v", name)
+	}
+	defer func() {
+		if closeErr := wc.Close(); finalErr == nil {
+			finalErr = closeErr
+		}
+	}()
+	if _, err := wc.Write(data); err != nil {
+		return vterrors.Wrapf(err, "cannot write %v", name)
+	}
+	return nil
+}
+
+// readChecksums reads the block checksums of a file from the backup.
+func readChecksums(ctx context.Context, bh backupstorage.BackupHandle, name string) ([]uint64, error) {
+	rc, err := bh.ReadFile(ctx, name)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "can't read %v", name)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "can't read %v", name)
+	}
+	if len(data)%8 != 0 {
+		return nil, vterrors.Errorf(vtrpc.Code_INTERNAL, "invalid checksums file %v of %v bytes", name, len(data))
+	}
+	checksums := make([]uint64, len(data)/8)
+	for i := range checksums {
+		checksums[i] = binary.LittleEndian.Uint64(data[8*i:])
+	}
+	return checksums, nil
+}
@@ -0,0 +1,881 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/dbconnpool"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+const (
+	logicalBackupEngineName = "logical"
+)
+
+var (
+	// logicalBackupChunkSize is the size of the chunks tables are split
+	// into, so they can be restored in parallel.
+	logicalBackupChunkSize = flag.Int("logicalbackup_chunk_size", 64*1024*1024, "approximate size in bytes of the uncompressed chunks the tables of logical backups are split into")
+
+	// logicalBackupRestoreBatchSize caps the size of the INSERT statements
+	// of restores. It must be lower than max_allowed_packet.
+	logicalBackupRestoreBatchSize = flag.Int("logicalbackup_restore_batch_size", 1024*1024, "maximum size in bytes of the INSERT statements used to restore logical backups. It must be lower than max_allowed_packet")
+)
+
+// LogicalBackupEngine encapsulates the logic of the logical engine. It
+// dumps the rows of each table of the database, from a consistent snapshot
+// taken while mysqld keeps running, along with the schema of its views,
+// stored routines, triggers and events. Unlike the physical backups of the
+// other engines, the backups can be restored into another version of
+// MySQL, partially, or filtered by key range.
+type LogicalBackupEngine struct {
+}
+
+// logicalBackupManifest represents a logical backup.
+type logicalBackupManifest struct {
+	// BackupManifest is an anonymous embedding of the base manifest struct.
+	BackupManifest
+
+	// DatabaseSchema is the CREATE DATABASE statement of the database,
+	// with {{.DatabaseName}} in place of its name.
+	DatabaseSchema string
+
+	// Tables are the tables and views of the database.
+	Tables []logicalBackupTable
+
+	// Objects are the stored routines, triggers and events of the
+	// database, in the order they are recreated.
+	Objects []logicalBackupObject `json:",omitempty"`
+
+	// SkipCompress is true if the chunks were NOT compressed.
+	SkipCompress bool
+
+	// CompressionEngine is the engine the chunks were compressed with.
+	CompressionEngine string `json:",omitempty"`
+
+	// ExternalDecompressor is the command that decompresses the chunks, if
+	// they were compressed with the external engine. It is only run with
+	// the -external_decompressor_use_manifest flag.
+	ExternalDecompressor string `json:",omitempty"`
+}
+
+// logicalBackupTable is a table of a logical backup.
+type logicalBackupTable struct {
+	Name string
+
+	// Type is tmutils.TableBaseTable or tmutils.TableView.
+	Type string
+
+	// Schema is the CREATE statement of the table, with
+	// {{.DatabaseName}} in place of the database name.
+	Schema string
+
+	// Columns are the dumped columns. Generated columns aren't dumped.
+	Columns []string `json:",omitempty"`
+
+	// Chunks are the files the rows are stored in.
+	Chunks []logicalBackupChunk `json:",omitempty"`
+}
+
+// logicalBackupObject is a stored routine, trigger or event of a logical
+// backup.
+type logicalBackupObject struct {
+	Name string
+
+	// Type is PROCEDURE, FUNCTION, TRIGGER or EVENT.
+	Type string
+
+	// Table is the table of a trigger.
+	Table string `json:",omitempty"`
+
+	// SQLMode is the sql_mode the object was created with. Its body is
+	// parsed with it, so it has to be recreated with it too.
+	SQLMode string
+
+	// Schema is the CREATE statement of the object.
+	Schema string
+}
+
+// logicalBackupChunk is a file of a logical backup. It stores a
+// query.QueryResult proto with some rows of a table.
+type logicalBackupChunk struct {
+	// Name is the name of the file in the backup.
+	Name string
+
+	// Rows is the number of rows.
+	Rows int
+
+	// Hash is the hash of the data stored in the BackupStorage.
+	Hash string
+}
+
+// ExecuteBackup dumps the database. All the tables are read from
+// consistent snapshots started while holding a global read lock, so they
+// all match the same replication position.
+func (be *LogicalBackupEngine) ExecuteBackup(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle) (bool, error) {
+	params.Logger.Infof("Compress: %v, Compression engine: %v", *backupStorageCompress, *compressionEngineName)
+
+	// Take the global read lock, to start the snapshots and read the
+	// position and schema they match.
+	lockConn, err := params.Mysqld.GetDbaConnection(ctx)
+	if err != nil {
+		return false, vterrors.Wrap(err, "can't get connection to lock tables")
+	}
+	defer lockConn.Close()
+	params.Logger.Infof("locking tables to start the snapshot")
+	if _, err := lockConn.ExecuteFetch("FLUSH TABLES WITH READ LOCK", 0, false); err != nil {
+		return false, vterrors.Wrap(err, "can't lock tables")
+	}
+	locked := true
+	defer func() {
+		if locked {
+			if _, err := lockConn.ExecuteFetch("UNLOCK TABLES", 0, false); err != nil {
+				log.Warningf("Unlock tables failed: %v", err)
+			}
+		}
+	}()
+
+	replicationPosition, err := params.Mysqld.PrimaryPosition()
+	if err != nil {
+		return false, vterrors.Wrap(err, "can't get position")
+	}
+	params.Logger.Infof("using replication position: %v", replicationPosition)
+	sd, err := params.Mysqld.GetSchema(ctx, params.DbName, nil, nil, true)
+	if err != nil {
+		return false, vterrors.Wrapf(err, "can't get schema of %v", params.DbName)
+	}
+	generated, err := be.generatedColumns(ctx, params)
+	if err != nil {
+		return false, err
+	}
+	objects, err := be.databaseObjects(ctx, params)
+	if err != nil {
+		return false, err
+	}
+
+	concurrency := params.Concurrency
+	if concurrency > len(sd.TableDefinitions) {
+		concurrency = len(sd.TableDefinitions)
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	conns := make(chan *dbconnpool.DBConnection, concurrency)
+	defer func() {
+		close(conns)
+		for conn := range conns {
+			if _, err := conn.ExecuteFetch("ROLLBACK", 0, false); err != nil {
+				log.Warningf("Rollback of the snapshot failed: %v", err)
+			}
+			conn.Close()
+		}
+	}()
+	for i := 0; i < concurrency; i++ {
+		conn, err := startSnapshot(ctx, params.Mysqld)
+		if err != nil {
+			return false, err
+		}
+		conns <- conn
+	}
+
+	if _, err := lockConn.ExecuteFetch("UNLOCK TABLES", 0, false); err != nil {
+		return false, vterrors.Wrap(err, "can't unlock tables")
+	}
+	locked = false
+	params.Logger.Infof("tables unlocked, dumping %v tables", len(sd.TableDefinitions))
+
+	// Generate the data key, if backups are encrypted.
+	encryption, dataKey, err := newBackupEncryption(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	// Dump the tables in parallel, each with one of the snapshots.
+	tables := make([]logicalBackupTable, len(sd.TableDefinitions))
+	wg := sync.WaitGroup{}
+	for i, td := range sd.TableDefinitions {
+		tables[i] = logicalBackupTable{
+			Name:   td.Name,
+			Type:   td.Type,
+			Schema: td.Schema,
+		}
+		if td.Type != tmutils.TableBaseTable {
+			continue
+		}
+		for _, column := range td.Columns {
+			if !generated[td.Name][column] {
+				tables[i].Columns = append(tables[i].Columns, column)
+			}
+		}
+		wg.Add(1)
+		go func(i int, pkColumns []string) {
+			defer wg.Done()
+
+			conn := <-conns
+			defer func() { conns <- conn }()
+			if bh.HasErrors() {
+				return
+			}
+			bh.RecordError(be.dumpTable(ctx, params, bh, conn, i, &tables[i], pkColumns, dataKey))
+		}(i, td.PrimaryKeyColumns)
+	}
+	wg.Wait()
+	if bh.HasErrors() {
+		return false, bh.Error()
+	}
+
+	// Write the MANIFEST.
+	bm := &logicalBackupManifest{
+		BackupManifest: BackupManifest{
+			BackupMethod: logicalBackupEngineName,
+			Position:     replicationPosition,
+			BackupTime:   params.BackupTime.UTC().Format(time.RFC3339),
+			FinishedTime: time.Now().UTC().Format(time.RFC3339),
+			Encryption:   encryption,
+		},
+		DatabaseSchema: sd.DatabaseSchema,
+		Tables:         tables,
+		Objects:        objects,
+		SkipCompress:   !*backupStorageCompress,
+	}
+	bm.CompressionEngine, bm.ExternalDecompressor = manifestCompression()
+	data, err := json.MarshalIndent(bm, "", "  ")
+	if err != nil {
+		return false, vterrors.Wrapf(err, "cannot JSON encode %v", backupManifestFileName)
+	}
+	wc, err := bh.AddFile(ctx, backupManifestFileName, int64(len(data)))
+	if err != nil {
+		return false, vterrors.Wrapf(err, "cannot add %v to backup", backupManifestFileName)
+	}
+	if _, err := wc.Write(data); err != nil {
+		wc.Close()
+		return false, vterrors.Wrapf(err, "cannot write %v", backupManifestFileName)
+	}
+	if err := wc.Close(); err != nil {
+		return false, vterrors.Wrapf(err, "cannot close %v", backupManifestFileName)
+	}
+	return true, nil
+}
+
+// generatedColumns returns the generated columns of each table. Their
+// values are computed by mysqld, so they can't be restored.
+func (be *LogicalBackupEngine) generatedColumns(ctx context.Context, params BackupParams) (map[string]map[string]bool, error) {
+	qr, err := params.Mysqld.FetchSuperQuery(ctx, fmt.Sprintf("SELECT table_name, column_name FROM information_schema.columns WHERE table_schema = %s AND generation_expression != ''", sqltypes.EncodeStringSQL(params.DbName)))
+	if err != nil {
+		return nil, vterrors.Wrap(err, "can't list generated columns")
+	}
+	generated := make(map[string]map[string]bool)
+	for _, row := range qr.Rows {
+		table, column := row[0].ToString(), row[1].ToString()
+		if generated[table] == nil {
+			generated[table] = make(map[string]bool)
+		}
+		generated[table][column] = true
+	}
+	return generated, nil
+}
+
+// databaseObjects returns the stored routines, triggers and events of the
+// database. Routines go first, as triggers and events may call them, and
+// the triggers of a table are listed in the order they fire.
+func (be *LogicalBackupEngine) databaseObjects(ctx context.Context, params BackupParams) ([]logicalBackupObject, error) {
+	dbName := sqltypes.EncodeStringSQL(params.DbName)
+	var objects []logicalBackupObject
+	for _, query := range []string{
+		"SELECT routine_type, routine_name, '' FROM information_schema.routines WHERE routine_schema = %s ORDER BY routine_name",
+		"SELECT 'TRIGGER', trigger_name, event_object_table FROM information_schema.triggers WHERE trigger_schema = %s ORDER BY event_object_table, action_timing, event_manipulation, action_order",
+		"SELECT 'EVENT', event_name, '' FROM information_schema.events WHERE event_schema = %s ORDER BY event_name",
+	} {
+		qr, err := params.Mysqld.FetchSuperQuery(ctx, fmt.Sprintf(query, dbName))
+		if err != nil {
+			return nil, vterrors.Wrap(err, "can't list the stored routines, triggers and events")
+		}
+		for _, row := range qr.Rows {
+			object := logicalBackupObject{
+				Type:  row[0].ToString(),
+				Name:  row[1].ToString(),
+				Table: row[2].ToString(),
+			}
+			show, err := params.Mysqld.FetchSuperQuery(ctx, fmt.Sprintf("SHOW CREATE %v %v.%v", object.Type, sqlescape.EscapeID(params.DbName), sqlescape.EscapeID(object.Name)))
+			if err != nil {
+				return nil, vterrors.Wrapf(err, "can't get the schema of %v %v", strings.ToLower(object.Type), object.Name)
+			}
+			// The statement comes after the time zone for events.
+			createColumn := 2
+			if object.Type == "EVENT" {
+				createColumn = 3
+			}
+			if len(show.Rows) != 1 || len(show.Rows[0]) <= createColumn || show.Rows[0][createColumn].IsNull() {
+				return nil, vterrors.Errorf(vtrpc.Code_INTERNAL, "can't get the schema of %v %v", strings.ToLower(object.Type), object.Name)
+			}
+			object.SQLMode = show.Rows[0][1].ToString()
+			object.Schema = show.Rows[0][createColumn].ToString()
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+// startSnapshot returns a connection with a consistent snapshot
+// transaction. Like vstreamer's snapshot connections, it must be called
+// while the tables are locked, for the snapshot to match the position.
+func startSnapshot(ctx context.Context, mysqld MysqlDaemon) (*dbconnpool.DBConnection, error) {
+	conn, err := mysqld.GetDbaConnection(ctx)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "can't get connection to start a snapshot")
+	}
+	for _, query := range []string{
+		"SET TRANSACTION ISOLATION LEVEL REPEATABLE READ",
+		"START TRANSACTION WITH CONSISTENT SNAPSHOT",
+		"SET time_zone = '+00:00'",
+	} {
+		if _, err := conn.ExecuteFetch(query, 0, false); err != nil {
+			conn.Close()
+			return nil, vterrors.Wrap(err, "can't start snapshot")
+		}
+	}
+	return conn, nil
+}
+
+// dumpTable reads all the rows of a table from the snapshot of conn, and
+// stores them in chunks of about logicalbackup_chunk_size bytes.
+func (be *LogicalBackupEngine) dumpTable(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle, conn *dbconnpool.DBConnection, index int, table *logicalBackupTable, pkColumns []string, dataKey []byte) error {
+	if len(table.Columns) == 0 {
+		return nil
+	}
+	query := fmt.Sprintf("SELECT %v FROM %v.%v", escapeIDs(table.Columns), sqlescape.EscapeID(params.DbName), sqlescape.EscapeID(table.Name))
+	if len(pkColumns) > 0 {
+		query += " ORDER BY " + escapeIDs(pkColumns)
+	}
+
+	var fields []*querypb.Field
+	rows := 0
+	err := conn.ExecuteStreamFetch(query, func(qr *sqltypes.Result) error {
+		if qr.Fields != nil {
+			fields = qr.Fields
+			return nil
+		}
+		chunk := logicalBackupChunk{
+			Name: fmt.Sprintf("%v.%v", index, len(table.Chunks)),
+			Rows: len(qr.Rows),
+		}
+		qr.Fields = fields
+		hash, err := be.writeChunk(ctx, params, bh, chunk.Name, qr, dataKey)
+		if err != nil {
+			return vterrors.Wrapf(err, "can't write chunk %v of table %v", chunk.Name, table.Name)
+		}
+		chunk.Hash = hash
+		table.Chunks = append(table.Chunks, chunk)
+		rows += chunk.Rows
+		return nil
+	}, func() *sqltypes.Result { return &sqltypes.Result{} }, *logicalBackupChunkSize)
+	if err != nil {
+		return vterrors.Wrapf(err, "can't dump table %v", table.Name)
+	}
+	params.Logger.Infof("Dumped table %v: %v rows in %v chunks", table.Name, rows, len(table.Chunks))
+	return nil
+}
+
+// writeChunk stores rows in a file of the backup, and returns its hash.
+func (be *LogicalBackupEngine) writeChunk(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle, name string, qr *sqltypes.Result, dataKey []byte) (hash string, finalErr error) {
+	data, err := sqltypes.ResultToProto3(qr).MarshalVT()
+	if err != nil {
+		return "", err
+	}
+	wc, err := bh.AddFile(ctx, name, int64(len(data)))
+	if err != nil {
+		return "", vterrors.Wrapf(err, "cannot add file: %v", name)
+	}
+	defer func() {
+		if cerr := wc.Close(); cerr != nil && finalErr == nil {
+			finalErr = cerr
+		}
+	}()
+	dst := bufio.NewWriterSize(wc, writerBufferSize)
+	hasher := newHasher()
+	var writer io.Writer = io.MultiWriter(dst, hasher)
+
+	var encryptor io.WriteCloser
+	if dataKey != nil {
+		if encryptor, err = newEncryptingWriter(writer, dataKey); err != nil {
+			return "", vterrors.Wrap(err, "cannot create encryptor")
+		}
+		writer = encryptor
+	}
+	var compressor io.WriteCloser
+	if *backupStorageCompress {
+		if compressor, err = newCompressor(ctx, *compressionEngineName, writer, params.Logger); err != nil {
+			return "", vterrors.Wrap(err, "cannot create compressor")
+		}
+		writer = compressor
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		return "", vterrors.Wrap(err, "cannot write data")
+	}
+	if compressor != nil {
+		if err := compressor.Close(); err != nil {
+			return "", vterrors.Wrap(err, "cannot close compressor")
+		}
+	}
+	if encryptor != nil {
+		if err := encryptor.Close(); err != nil {
+			return "", vterrors.Wrap(err, "cannot close encryptor")
+		}
+	}
+	if err := dst.Flush(); err != nil {
+		return "", vterrors.Wrapf(err, "cannot flush destination: %v", name)
+	}
+	return hasher.HashString(), nil
+}
+
+// ExecuteRestore restores a logical backup into the running mysqld. If
+// params.Tables is set, only these tables are restored, and the other
+// tables of the database are left alone. Otherwise, the database is
+// replaced. If params.KeyRange is set, only the rows in the key range,
+// according to params.VSchema, are restored.
+func (be *LogicalBackupEngine) ExecuteRestore(ctx context.Context, params RestoreParams, bh backupstorage.BackupHandle) (*BackupManifest, error) {
+	var bm logicalBackupManifest
+	if err := getBackupManifestInto(ctx, bh, &bm); err != nil {
+		return nil, err
+	}
+
+	var ks *vindexes.KeyspaceSchema
+	if params.KeyRange != nil {
+		if params.VSchema == nil {
+			return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "a VSchema is required to restore key range %v", key.KeyRangeString(params.KeyRange))
+		}
+		var err error
+		if ks, err = vindexes.BuildKeyspaceSchema(params.VSchema, params.Keyspace); err != nil {
+			return nil, vterrors.Wrap(err, "invalid VSchema")
+		}
+	}
+
+	// Find the tables to restore.
+	tables := bm.Tables
+	if len(params.Tables) > 0 {
+		tables = nil
+		for _, name := range params.Tables {
+			found := false
+			for _, table := range bm.Tables {
+				if table.Name == name {
+					tables = append(tables, table)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "table %v is not in backup %v", name, bh.Name())
+			}
+		}
+	}
+
+	// mark restore as in progress
+	if err := createStateFile(params.Cnf); err != nil {
+		return nil, err
+	}
+
+	// Wait for mysqld to be ready, in case it was launched in parallel
+	// with us.
+	if err := params.Mysqld.Wait(ctx, params.Cnf); err != nil {
+		return nil, err
+	}
+
+	// Create the database and the tables. Views go last, as they may
+	// depend on the tables and on each other.
+	conn, err := restoreConnection(ctx, params.Mysqld)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	dbName := sqlescape.EscapeID(params.DbName)
+	var queries []string
+	if len(params.Tables) == 0 {
+		params.Logger.Infof("Restore: recreating database %v", params.DbName)
+		queries = append(queries,
+			"DROP DATABASE IF EXISTS "+dbName,
+			strings.Replace(bm.DatabaseSchema, "{{.DatabaseName}}", dbName, -1),
+		)
+	} else {
+		params.Logger.Infof("Restore: recreating tables %v", strings.Join(params.Tables, ", "))
+		queries = append(queries, "CREATE DATABASE IF NOT EXISTS "+dbName)
+	}
+	queries = append(queries, "USE "+dbName)
+	var views []string
+	for _, table := range tables {
+		schema := strings.Replace(table.Schema, "{{.DatabaseName}}", dbName, -1)
+		if table.Type != tmutils.TableBaseTable {
+			if len(params.Tables) > 0 {
+				queries = append(queries, "DROP VIEW IF EXISTS "+sqlescape.EscapeID(table.Name))
+			}
+			views = append(views, schema)
+			continue
+		}
+		if len(params.Tables) > 0 {
+			queries = append(queries, "DROP TABLE IF EXISTS "+sqlescape.EscapeID(table.Name))
+		}
+		queries = append(queries, schema)
+	}
+	for _, query := range queries {
+		if _, err := conn.ExecuteFetch(query, 0, false); err != nil {
+			return nil, vterrors.Wrapf(err, "can't create schema: %v", query)
+		}
+	}
+	if err := createViews(conn, views); err != nil {
+		return nil, err
+	}
+
+	// Restore the chunks in parallel.
+	dataKey, err := bm.Encryption.dataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	type job struct {
+		table *logicalBackupTable
+		chunk logicalBackupChunk
+	}
+	jobs := make(chan job)
+	rec := concurrency.AllErrorRecorder{}
+	wg := sync.WaitGroup{}
+	workers := params.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var conn *dbconnpool.DBConnection
+			defer func() {
+				if conn != nil {
+					conn.Close()
+				}
+			}()
+			for job := range jobs {
+				if rec.HasErrors() {
+					continue
+				}
+				if conn == nil {
+					c, err := restoreConnection(ctx, params.Mysqld)
+					if err == nil {
+						_, err = c.ExecuteFetch("USE "+dbName, 0, false)
+					}
+					if err != nil {
+						rec.RecordError(err)
+						continue
+					}
+					conn = c
+				}
+				params.Logger.Infof("Restoring chunk %v of table %v: %v rows", job.chunk.Name, job.table.Name, job.chunk.Rows)
+				if err := be.restoreChunk(ctx, params, bh, bm, conn, ks, job.table, job.chunk, dataKey); err != nil {
+					rec.RecordError(vterrors.Wrapf(err, "can't restore chunk %v of table %v", job.chunk.Name, job.table.Name))
+				}
+			}
+		}()
+	}
+	for i := range tables {
+		for _, chunk := range tables[i].Chunks {
+			jobs <- job{table: &tables[i], chunk: chunk}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if rec.HasErrors() {
+		// don't delete the state file here because that is how we detect an interrupted restore
+		return nil, vterrors.Wrap(rec.Error(), "failed to restore tables")
+	}
+
+	// Recreate the triggers once the rows are restored, so they don't
+	// fire for them.
+	if err := be.restoreObjects(params, conn, bm.Objects); err != nil {
+		return nil, err
+	}
+
+	params.Logger.Infof("Restore: returning replication position %v", bm.Position)
+	return &bm.BackupManifest, nil
+}
+
+// createViews creates views that may depend on each other. The ones that
+// fail are retried, until all of them are created or no more can be.
+func createViews(conn *dbconnpool.DBConnection, views []string) error {
+	for len(views) > 0 {
+		var failed []string
+		var lastErr error
+		for _, view := range views {
+			if _, err := conn.ExecuteFetch(view, 0, false); err != nil {
+				failed = append(failed, view)
+				lastErr = vterrors.Wrapf(err, "can't create view: %v", view)
+			}
+		}
+		if len(failed) == len(views) {
+			return lastErr
+		}
+		views = failed
+	}
+	return nil
+}
+
+// restoreObjects recreates the stored routines, triggers and events of the
+// database. If only some tables are restored, only their triggers are
+// recreated, as the other objects belong to the whole database.
+func (be *LogicalBackupEngine) restoreObjects(params RestoreParams, conn *dbconnpool.DBConnection, objects []logicalBackupObject) error {
+	restored := make(map[string]bool)
+	for _, table := range params.Tables {
+		restored[table] = true
+	}
+	for _, object := range objects {
+		var queries []string
+		if len(params.Tables) > 0 {
+			if object.Type != "TRIGGER" || !restored[object.Table] {
+				continue
+			}
+			queries = append(queries, "DROP TRIGGER IF EXISTS "+sqlescape.EscapeID(object.Name))
+		}
+		params.Logger.Infof("Restore: recreating %v %v", strings.ToLower(object.Type), object.Name)
+		queries = append(queries,
+			"SET SESSION sql_mode = "+sqltypes.EncodeStringSQL(object.SQLMode),
+			object.Schema,
+		)
+		for _, query := range queries {
+			if _, err := conn.ExecuteFetch(query, 0, false); err != nil {
+				return vterrors.Wrapf(err, "can't recreate %v %v: %v", strings.ToLower(object.Type), object.Name, query)
+			}
+		}
+	}
+	return nil
+}
+
+// restoreConnection returns a connection to restore data with. Its
+// changes aren't written to the binary logs, so they don't make GTIDs
+// the rest of the shard doesn't have.
+func restoreConnection(ctx context.Context, mysqld MysqlDaemon) (*dbconnpool.DBConnection, error) {
+	conn, err := mysqld.GetDbaConnection(ctx)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "can't get connection to restore data")
+	}
+	for _, query := range []string{
+		"SET sql_log_bin = 0",
+		"SET foreign_key_checks = 0",
+		"SET time_zone = '+00:00'",
+		"SET sql_mode = 'NO_AUTO_VALUE_ON_ZERO'",
+	} {
+		if _, err := conn.ExecuteFetch(query, 0, false); err != nil {
+			conn.Close()
+			return nil, vterrors.Wrapf(err, "can't set up connection to restore data: %v", query)
+		}
+	}
+	return conn, nil
+}
+
+// restoreChunk inserts the rows of a chunk, in statements of at most
+// logicalbackup_restore_batch_size bytes.
+func (be *LogicalBackupEngine) restoreChunk(ctx context.Context, params RestoreParams, bh backupstorage.BackupHandle, bm logicalBackupManifest, conn *dbconnpool.DBConnection, ks *vindexes.KeyspaceSchema, table *logicalBackupTable, chunk logicalBackupChunk, dataKey []byte) error {
+	source, err := bh.ReadFile(ctx, chunk.Name)
+	if err != nil {
+		return vterrors.Wrap(err, "can't open source file for reading")
+	}
+	defer source.Close()
+
+	hasher := newHasher()
+	tee := io.TeeReader(source, hasher)
+	reader := tee
+	if dataKey != nil {
+		if reader, err = newDecryptingReader(reader, dataKey); err != nil {
+			return err
+		}
+	}
+	if !bm.SkipCompress {
+		decompressor, err := newDecompressor(ctx, bm.CompressionEngine, bm.ExternalDecompressor, reader, params.Logger)
+		if err != nil {
+			return err
+		}
+		defer decompressor.Close()
+		reader = decompressor
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return vterrors.Wrap(err, "failed to read chunk")
+	}
+	// Read what the decompressor may have left, before checking the hash.
+	if _, err := io.Copy(io.Discard, tee); err != nil {
+		return vterrors.Wrap(err, "failed to read chunk")
+	}
+	if hash := hasher.HashString(); hash != chunk.Hash {
+		return vterrors.Errorf(vtrpc.Code_DATA_LOSS, "hash mismatch for chunk %v, got %v expected %v", chunk.Name, hash, chunk.Hash)
+	}
+
+	pqr := &querypb.QueryResult{}
+	if err := pqr.UnmarshalVT(data); err != nil {
+		return vterrors.Wrap(err, "can't decode chunk")
+	}
+	qr := sqltypes.Proto3ToResult(pqr)
+
+	var filter func([]sqltypes.Value) (bool, error)
+	if ks != nil {
+		if filter, err = newKeyRangeFilter(ks, params.KeyRange, table.Name, qr.Fields); err != nil {
+			return err
+		}
+	}
+
+	prefix := fmt.Sprintf("INSERT INTO %v (%v) VALUES ", sqlescape.EscapeID(table.Name), escapeIDs(table.Columns))
+	buf := &bytes.Buffer{}
+	flush := func() error {
+		if buf.Len() == 0 {
+			return nil
+		}
+		_, err := conn.ExecuteFetch(buf.String(), 0, false)
+		buf.Reset()
+		return err
+	}
+	for _, row := range qr.Rows {
+		if filter != nil {
+			keep, err := filter(row)
+			if err != nil {
+				return err
+			}
+			if !keep {
+				continue
+			}
+		}
+		if buf.Len() == 0 {
+			buf.WriteString(prefix)
+		} else {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('(')
+		for i, value := range row {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			value.EncodeSQL(buf)
+		}
+		buf.WriteByte(')')
+		if buf.Len() >= *logicalBackupRestoreBatchSize {
+			if err := flush(); err != nil {
+				return vterrors.Wrap(err, "failed to insert rows")
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return vterrors.Wrap(err, "failed to insert rows")
+	}
+	return nil
+}
+
+// newKeyRangeFilter returns a function that tells whether a row of the
+// table is in the key range, according to the primary vindex of the table.
+// It returns nil if all the rows are kept, for unsharded keyspaces and
+// reference tables.
+func newKeyRangeFilter(ks *vindexes.KeyspaceSchema, kr *topodatapb.KeyRange, tableName string, fields []*querypb.Field) (func([]sqltypes.Value) (bool, error), error) {
+	if !ks.Keyspace.Sharded {
+		return nil, nil
+	}
+	table, ok := ks.Tables[tableName]
+	if !ok || table.Type == vindexes.TypeReference {
+		return nil, nil
+	}
+	if table.Pinned != nil {
+		keep := key.KeyRangeContains(kr, table.Pinned)
+		return func([]sqltypes.Value) (bool, error) { return keep, nil }, nil
+	}
+	if len(table.ColumnVindexes) == 0 {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "table %v has no primary vindex", tableName)
+	}
+	cv := table.ColumnVindexes[0]
+	if cv.Vindex.NeedsVCursor() {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "the primary vindex %v of table %v needs to run queries, and can't be used to filter rows", cv.Name, tableName)
+	}
+	indexes := make([]int, len(cv.Columns))
+	for i, column := range cv.Columns {
+		indexes[i] = -1
+		for j, field := range fields {
+			if column.EqualString(field.Name) {
+				indexes[i] = j
+				break
+			}
+		}
+		if indexes[i] == -1 {
+			return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "column %v of the primary vindex of table %v is not in the backup", column, tableName)
+		}
+	}
+	return func(row []sqltypes.Value) (bool, error) {
+		values := make([]sqltypes.Value, len(indexes))
+		for i, index := range indexes {
+			values[i] = row[index]
+		}
+		// The vindex of a JSON path column maps the value found at the
+		// path, not the document.
+		if cv.JSONPath != nil {
+			value, err := cv.JSONPath.Extract(values[0])
+			if err != nil {
+				return false, err
+			}
+			values[0] = value
+		}
+		destinations, err := vindexes.Map(cv.Vindex, nil, [][]sqltypes.Value{values})
+		if err != nil {
+			return false, err
+		}
+		ksid, ok := destinations[0].(key.DestinationKeyspaceID)
+		if !ok {
+			return false, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "the primary vindex %v of table %v didn't map %v to a keyspace id", cv.Name, tableName, values)
+		}
+		return key.KeyRangeContains(kr, ksid), nil
+	}, nil
+}
+
+// escapeIDs returns the escaped, comma separated identifiers.
+func escapeIDs(ids []string) string {
+	escaped := make([]string, len(ids))
+	for i, id := range ids {
+		escaped[i] = sqlescape.EscapeID(id)
+	}
+	return strings.Join(escaped, ", ")
+}
+
+// ShouldDrainForBackup satisfies the BackupEngine interface.
+// The logical engine only locks the tables briefly, so mysqld keeps
+// serving during the backup.
+func (be *LogicalBackupEngine) ShouldDrainForBackup() bool {
+	return false
+}
+
+func init() {
+	BackupRestoreEngineMap[logicalBackupEngineName] = &LogicalBackupEngine{}
+}
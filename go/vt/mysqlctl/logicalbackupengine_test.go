@@ -0,0 +1,270 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/fakemysqldaemon"
+	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func TestLogicalBackupEngine(t *testing.T) {
+	root := t.TempDir()
+	oldRoot := *filebackupstorage.FileBackupStorageRoot
+	oldImplementation := *backupstorage.BackupStorageImplementation
+	defer func() {
+		*filebackupstorage.FileBackupStorageRoot = oldRoot
+		*backupstorage.BackupStorageImplementation = oldImplementation
+	}()
+	*filebackupstorage.FileBackupStorageRoot = path.Join(root, "backups")
+	*backupstorage.BackupStorageImplementation = "file"
+	// Store each row in its own chunk.
+	require.NoError(t, flag.Set("logicalbackup_chunk_size", "1"))
+	defer flag.Set("logicalbackup_chunk_size", "67108864")
+
+	db := fakesqldb.New(t)
+	defer db.Close()
+	mysqld := fakemysqldaemon.NewFakeMysqlDaemon(db)
+	defer mysqld.Close()
+	position, err := mysql.ParsePosition(mysql.Mysql56FlavorID, "00010203-0405-0607-0809-0a0b0c0d0e0f:1-10")
+	require.NoError(t, err)
+	mysqld.CurrentPrimaryPosition = position
+	mysqld.Schema = &tabletmanagerdatapb.SchemaDefinition{
+		DatabaseSchema: "CREATE DATABASE {{.DatabaseName}}",
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
+			Name:              "t1",
+			Schema:            "CREATE TABLE `t1` (`id` bigint, `name` varchar(10), `name_len` int AS (length(`name`)), PRIMARY KEY (`id`))",
+			Columns:           []string{"id", "name", "name_len"},
+			PrimaryKeyColumns: []string{"id"},
+			Type:              tmutils.TableBaseTable,
+		}, {
+			// v0 depends on v1, which is created after it.
+			Name:   "v0",
+			Schema: "CREATE VIEW {{.DatabaseName}}.`v0` AS SELECT `id` FROM {{.DatabaseName}}.`v1`",
+			Type:   tmutils.TableView,
+		}, {
+			Name:   "v1",
+			Schema: "CREATE VIEW {{.DatabaseName}}.`v1` AS SELECT `id` FROM {{.DatabaseName}}.`t1`",
+			Type:   tmutils.TableView,
+		}},
+	}
+	mysqld.FetchSuperQueryMap = map[string]*sqltypes.Result{
+		"SELECT table_name, column_name FROM information_schema.columns WHERE table_schema = 'vt_db' AND generation_expression != ''": sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields("table_name|column_name", "varchar|varchar"),
+			"t1|name_len",
+		),
+		"SELECT routine_type, routine_name, '' FROM information_schema.routines WHERE routine_schema = 'vt_db' ORDER BY routine_name": sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields("routine_type|routine_name|table", "varchar|varchar|varchar"),
+			"PROCEDURE|p1|",
+		),
+		"SHOW CREATE PROCEDURE `vt_db`.`p1`": sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields("Procedure|sql_mode|Create Procedure", "varchar|varchar|varchar"),
+			"p1|ANSI_QUOTES|CREATE PROCEDURE `p1`() SELECT 1",
+		),
+		"SELECT 'TRIGGER', trigger_name, event_object_table FROM information_schema.triggers WHERE trigger_schema = 'vt_db' ORDER BY event_object_table, action_timing, event_manipulation, action_order": sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields("TRIGGER|trigger_name|event_object_table", "varchar|varchar|varchar"),
+			"TRIGGER|tr1|t1",
+		),
+		"SHOW CREATE TRIGGER `vt_db`.`tr1`": sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields("Trigger|sql_mode|SQL Original Statement", "varchar|varchar|varchar"),
+			"tr1|STRICT_TRANS_TABLES|CREATE TRIGGER `tr1` BEFORE INSERT ON `t1` FOR EACH ROW SET NEW.`name` = UPPER(NEW.`name`)",
+		),
+		"SELECT 'EVENT', event_name, '' FROM information_schema.events WHERE event_schema = 'vt_db' ORDER BY event_name": sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields("EVENT|event_name|table", "varchar|varchar|varchar"),
+			"EVENT|e1|",
+		),
+		"SHOW CREATE EVENT `vt_db`.`e1`": sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields("Event|sql_mode|time_zone|Create Event", "varchar|varchar|varchar|varchar"),
+			"e1||SYSTEM|CREATE EVENT `e1` ON SCHEDULE EVERY 1 DAY DISABLE ON SLAVE DO CALL `p1`()",
+		),
+	}
+	db.AddQuery("SELECT `id`, `name` FROM `vt_db`.`t1` ORDER BY `id`", sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("id|name", "int64|varchar"),
+		"1|a",
+		"2|b",
+		"3|c",
+		"4|d",
+	))
+	db.AddQueryPattern(".*", &sqltypes.Result{})
+
+	ctx := context.Background()
+	bs, err := backupstorage.GetBackupStorage()
+	require.NoError(t, err)
+	defer bs.Close()
+	be := &mysqlctl.LogicalBackupEngine{}
+	assert.False(t, be.ShouldDrainForBackup())
+
+	bh, err := bs.StartBackup(ctx, "ks/0", "backup1")
+	require.NoError(t, err)
+	ok, err := be.ExecuteBackup(ctx, mysqlctl.BackupParams{
+		Mysqld:      mysqld,
+		Logger:      logutil.NewConsoleLogger(),
+		Concurrency: 2,
+		Keyspace:    "ks",
+		Shard:       "0",
+		DbName:      "vt_db",
+		BackupTime:  time.Now(),
+	}, bh)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, bh.EndBackup(ctx))
+	queries := db.QueryLog()
+	assert.Contains(t, queries, "flush tables with read lock;")
+	assert.Contains(t, queries, "start transaction with consistent snapshot;")
+	assert.Less(t, strings.Index(queries, "start transaction with consistent snapshot"), strings.Index(queries, "unlock tables"), "the snapshots must start while the tables are locked")
+
+	bhs, err := bs.ListBackups(ctx, "ks/0")
+	require.NoError(t, err)
+	require.Len(t, bhs, 1)
+	bh = bhs[0]
+	manifest, err := mysqlctl.GetBackupManifest(ctx, bh)
+	require.NoError(t, err)
+	assert.Equal(t, "logical", manifest.BackupMethod)
+	assert.Equal(t, position, manifest.Position)
+
+	restore := func(params mysqlctl.RestoreParams) string {
+		params.Cnf = &mysqlctl.Mycnf{DataDir: path.Join(root, "restored", "data")}
+		params.Mysqld = mysqld
+		params.Logger = logutil.NewConsoleLogger()
+		params.Concurrency = 2
+		params.Keyspace = "ks"
+		params.DbName = "vt_restored"
+		require.NoError(t, createBackupDir(root, "restored/data"))
+		db.ResetQueryLog()
+		manifest, err := be.ExecuteRestore(ctx, params, bh)
+		require.NoError(t, err)
+		assert.Equal(t, position, manifest.Position)
+		return db.QueryLog()
+	}
+
+	t.Run("full", func(t *testing.T) {
+		queries := restore(mysqlctl.RestoreParams{})
+		for _, query := range []string{
+			"set sql_log_bin = 0",
+			"drop database if exists `vt_restored`",
+			"create database `vt_restored`",
+			"create view `vt_restored`.`v1` as select `id` from `vt_restored`.`t1`",
+			"insert into `t1` (`id`, `name`) values (1,'a')",
+			"insert into `t1` (`id`, `name`) values (4,'d')",
+		} {
+			assert.Contains(t, queries, query)
+		}
+		// Views go after the tables they depend on.
+		assert.Less(t, strings.Index(queries, "create table `t1`"), strings.Index(queries, "create view"))
+		// The triggers are recreated once the rows are restored, and
+		// the routines and events after the tables.
+		for _, query := range []string{
+			"set session sql_mode = 'ansi_quotes'",
+			"create procedure `p1`() select 1",
+			"set session sql_mode = 'strict_trans_tables'",
+			"create trigger `tr1` before insert on `t1` for each row set new.`name` = upper(new.`name`)",
+			"set session sql_mode = ''",
+			"create event `e1` on schedule every 1 day disable on slave do call `p1`()",
+		} {
+			assert.Contains(t, queries, query)
+		}
+		assert.Less(t, strings.Index(queries, "values (4,'d')"), strings.Index(queries, "create trigger"))
+		assert.Less(t, strings.Index(queries, "create procedure"), strings.Index(queries, "create trigger"))
+		assert.Less(t, strings.Index(queries, "create trigger"), strings.Index(queries, "create event"))
+	})
+
+	t.Run("view dependencies", func(t *testing.T) {
+		// v0 is retried once v1 is created, until no more views can be
+		// created.
+		createV0 := "create view `vt_restored`.`v0` as select `id` from `vt_restored`.`v1`"
+		db.AddRejectedQuery(createV0, errors.New("table vt_restored.v1 doesn't exist"))
+		defer db.DeleteRejectedQuery(createV0)
+		called := db.GetQueryCalledNum(createV0)
+		_, err := be.ExecuteRestore(ctx, mysqlctl.RestoreParams{
+			Cnf:    &mysqlctl.Mycnf{DataDir: path.Join(root, "restored", "data")},
+			Mysqld: mysqld,
+			Logger: logutil.NewConsoleLogger(),
+			DbName: "vt_restored",
+		}, bh)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't create view")
+		assert.Equal(t, called+2, db.GetQueryCalledNum(createV0))
+	})
+
+	t.Run("key range", func(t *testing.T) {
+		kr, err := key.ParseShardingSpec("80-")
+		require.NoError(t, err)
+		queries := restore(mysqlctl.RestoreParams{
+			KeyRange: kr[0],
+			VSchema: &vschemapb.Keyspace{
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"hash": {Type: "hash"},
+				},
+				Tables: map[string]*vschemapb.Table{
+					"t1": {ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "id", Name: "hash"}}},
+				},
+			},
+		})
+		// Only the keyspace id of 4 is in 80-.
+		assert.Contains(t, queries, "insert into `t1` (`id`, `name`) values (4,'d')")
+		assert.NotContains(t, queries, "values (1,'a')")
+		assert.NotContains(t, queries, "values (2,'b')")
+		assert.NotContains(t, queries, "values (3,'c')")
+	})
+
+	t.Run("single table", func(t *testing.T) {
+		queries := restore(mysqlctl.RestoreParams{Tables: []string{"t1"}})
+		assert.NotContains(t, queries, "drop database")
+		assert.Contains(t, queries, "drop table if exists `t1`")
+		assert.NotContains(t, queries, "create view")
+		assert.Contains(t, queries, "insert into `t1` (`id`, `name`) values (2,'b')")
+		// Only the triggers of the table are recreated.
+		assert.Contains(t, queries, "drop trigger if exists `tr1`")
+		assert.Contains(t, queries, "create trigger `tr1`")
+		assert.NotContains(t, queries, "create procedure")
+		assert.NotContains(t, queries, "create event")
+	})
+
+	t.Run("unknown table", func(t *testing.T) {
+		_, err := be.ExecuteRestore(ctx, mysqlctl.RestoreParams{
+			Cnf:    &mysqlctl.Mycnf{DataDir: path.Join(root, "restored", "data")},
+			Mysqld: mysqld,
+			Logger: logutil.NewConsoleLogger(),
+			DbName: "vt_restored",
+			Tables: []string{"t2"},
+		}, bh)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "table t2 is not in backup backup1")
+	})
+}
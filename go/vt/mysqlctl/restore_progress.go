@@ -0,0 +1,323 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/sync2"
+	"vitess.io/vitess/go/vt/logutil"
+)
+
+// This file handles the bookkeeping of the builtin restores: their
+// progress, their bandwidth limits, and the files they already restored,
+// so an interrupted restore can be resumed.
+
+var (
+	restoreProgressInterval = flag.Duration("restore_progress_interval", 30*time.Second, "how often the progress of builtin restores is logged")
+
+	// restoreMaxReadBytesPerSecond caps the network bandwidth of restores.
+	restoreMaxReadBytesPerSecond = flag.Int64("restore_max_read_bytes_per_second", 0, "maximum number of bytes per second builtin restores read from the backup storage, across all files. 0 means unlimited")
+
+	// restoreMaxWriteBytesPerSecond caps the disk bandwidth of restores.
+	restoreMaxWriteBytesPerSecond = flag.Int64("restore_max_write_bytes_per_second", 0, "maximum number of bytes per second builtin restores write to disk, across all files. 0 means unlimited")
+
+	restoreBytesTotal = stats.NewGauge("restore_bytes_total", "Number of bytes to read from the backup storage in the current or last restore, 0 if unknown")
+	restoreBytesDone  = stats.NewGauge("restore_bytes_done", "Number of bytes read from the backup storage in the current or last restore, including the files restored before it was interrupted")
+	restoreETA        = stats.NewGauge("restore_eta_seconds", "Estimated number of seconds left in the current restore, -1 if unknown")
+)
+
+// restoreStateFile is the content of the RestoreState file of builtin
+// restores.
+type restoreStateFile struct {
+	// Backup is the name of the backup being restored.
+	Backup string
+
+	// Completed are the files already restored, as <backup>/<file>.
+	Completed []string
+}
+
+// restoreTracker is shared by the files of a builtin restore. It records
+// the files already restored in the RestoreState file, reports the
+// progress, and throttles the reads and writes.
+type restoreTracker struct {
+	cnf    *Mycnf
+	logger logutil.Logger
+
+	readLimiter  *rate.Limiter
+	writeLimiter *rate.Limiter
+
+	// total is the number of bytes to read, 0 if unknown.
+	total int64
+	// skipped is the number of bytes restored before the restore was
+	// interrupted.
+	skipped int64
+	done    sync2.AtomicInt64
+	start   time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+
+	// mu protects the fields below.
+	mu        sync.Mutex
+	state     restoreStateFile
+	completed map[string]bool
+}
+
+// newRestoreTracker reads the RestoreState file. If it was left by an
+// interrupted restore of the same backup, the files it lists will be
+// skipped. Otherwise, it is reset for a new restore.
+func newRestoreTracker(cnf *Mycnf, logger logutil.Logger, backup string) (*restoreTracker, error) {
+	rt := &restoreTracker{
+		cnf:          cnf,
+		logger:       logger,
+		readLimiter:  newRestoreLimiter(*restoreMaxReadBytesPerSecond),
+		writeLimiter: newRestoreLimiter(*restoreMaxWriteBytesPerSecond),
+		stop:         make(chan struct{}),
+		completed:    make(map[string]bool),
+	}
+	data, err := os.ReadFile(filepath.Join(cnf.TabletDir(), RestoreState))
+	if err == nil {
+		// The file is empty if it was created by another engine.
+		var state restoreStateFile
+		if json.Unmarshal(data, &state) == nil && state.Backup == backup {
+			rt.state = state
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to read file: %v", err)
+	}
+	rt.state.Backup = backup
+	for _, name := range rt.state.Completed {
+		rt.completed[name] = true
+	}
+	return rt, rt.writeState()
+}
+
+// resumed returns true if some files were restored before the restore was
+// interrupted.
+func (rt *restoreTracker) resumed() bool {
+	return len(rt.completed) > 0
+}
+
+// isCompleted returns true if the given file was already restored.
+func (rt *restoreTracker) isCompleted(backup, name string) bool {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.completed[backup+"/"+name]
+}
+
+// markCompleted records that the given file is restored.
+func (rt *restoreTracker) markCompleted(backup, name string) error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.completed[backup+"/"+name] = true
+	rt.state.Completed = append(rt.state.Completed, backup+"/"+name)
+	return rt.writeState()
+}
+
+// completedSize returns the stored size of the files of the given backup
+// that were already restored.
+func (rt *restoreTracker) completedSize(backup string, fes []FileEntry) int64 {
+	var size int64
+	for i, fe := range fes {
+		if rt.isCompleted(backup, fmt.Sprintf("%v", i)) {
+			size += fe.StoredSize
+		}
+	}
+	return size
+}
+
+// writeState replaces the RestoreState file. It is written to a temporary
+// file first, so it can't be left half written.
+func (rt *restoreTracker) writeState() error {
+	data, err := json.Marshal(rt.state)
+	if err != nil {
+		return err
+	}
+	fname := filepath.Join(rt.cnf.TabletDir(), RestoreState)
+	if err := os.WriteFile(fname+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("unable to write file: %v", err)
+	}
+	if err := os.Rename(fname+".tmp", fname); err != nil {
+		return fmt.Errorf("unable to rename file: %v", err)
+	}
+	return nil
+}
+
+// startProgress starts reporting the progress, until stopProgress is
+// called. total is the number of bytes to read, 0 if unknown, and skipped
+// the number of bytes of the files already restored.
+func (rt *restoreTracker) startProgress(total, skipped int64) {
+	rt.total = total
+	rt.skipped = skipped
+	rt.done.Set(skipped)
+	rt.start = time.Now()
+	restoreBytesTotal.Set(total)
+	restoreBytesDone.Set(skipped)
+	restoreETA.Set(-1)
+
+	rt.wg.Add(1)
+	go func() {
+		defer rt.wg.Done()
+		ticker := time.NewTicker(*restoreProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-rt.stop:
+				return
+			case <-ticker.C:
+				rt.reportProgress()
+			}
+		}
+	}()
+}
+
+// stopProgress stops reporting the progress, and reports it one last time.
+func (rt *restoreTracker) stopProgress() {
+	close(rt.stop)
+	rt.wg.Wait()
+	rt.reportProgress()
+	restoreETA.Set(-1)
+}
+
+// eta returns the estimated time left, or -1 if it is unknown.
+func (rt *restoreTracker) eta() time.Duration {
+	done := rt.done.Get()
+	elapsed := time.Since(rt.start)
+	if rt.total == 0 || done <= rt.skipped || done >= rt.total {
+		return -1
+	}
+	perByte := float64(elapsed) / float64(done-rt.skipped)
+	return time.Duration(perByte * float64(rt.total-done)).Round(time.Second)
+}
+
+func (rt *restoreTracker) reportProgress() {
+	done := rt.done.Get()
+	eta := rt.eta()
+	if eta < 0 {
+		restoreETA.Set(-1)
+	} else {
+		restoreETA.Set(int64(eta.Seconds()))
+	}
+	switch {
+	case rt.total == 0:
+		rt.logger.Infof("Restore: %v bytes done", done)
+	case eta < 0:
+		rt.logger.Infof("Restore: %v of %v bytes done (%.1f%%)", done, rt.total, 100*float64(done)/float64(rt.total))
+	default:
+		rt.logger.Infof("Restore: %v of %v bytes done (%.1f%%), ETA %v", done, rt.total, 100*float64(done)/float64(rt.total), eta)
+	}
+}
+
+// newReader returns a reader that throttles the reads from r, and counts
+// them in the progress.
+func (rt *restoreTracker) newReader(ctx context.Context, r io.Reader) io.Reader {
+	return &restoreReader{ctx: ctx, r: r, rt: rt}
+}
+
+// newWriter returns a writer that throttles the writes to w.
+func (rt *restoreTracker) newWriter(ctx context.Context, w io.Writer) io.Writer {
+	if rt.writeLimiter == nil {
+		return w
+	}
+	return &throttledWriter{ctx: ctx, w: w, limiter: rt.writeLimiter}
+}
+
+// restoreReader is the reader returned by restoreTracker.newReader.
+type restoreReader struct {
+	ctx context.Context
+	r   io.Reader
+	rt  *restoreTracker
+}
+
+func (r *restoreReader) Read(p []byte) (int, error) {
+	if r.rt.readLimiter != nil && len(p) > r.rt.readLimiter.Burst() {
+		p = p[:r.rt.readLimiter.Burst()]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		restoreBytesDone.Set(r.rt.done.Add(int64(n)))
+		if r.rt.readLimiter != nil {
+			if werr := r.rt.readLimiter.WaitN(r.ctx, n); werr != nil {
+				return n, werr
+			}
+		}
+	}
+	return n, err
+}
+
+// throttledWriter writes to w no faster than its limiter allows.
+type throttledWriter struct {
+	ctx     context.Context
+	w       io.Writer
+	limiter *rate.Limiter
+}
+
+func (w *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > w.limiter.Burst() {
+			chunk = chunk[:w.limiter.Burst()]
+		}
+		if err := w.limiter.WaitN(w.ctx, len(chunk)); err != nil {
+			return written, err
+		}
+		n, err := w.w.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// newRestoreLimiter returns a limiter of bytesPerSecond, or nil if it is
+// 0, which means unlimited.
+func newRestoreLimiter(bytesPerSecond int64) *rate.Limiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	burst := int64(writerBufferSize)
+	if bytesPerSecond < burst {
+		burst = bytesPerSecond
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), int(burst))
+}
+
+// storedSize returns the number of bytes of the given files in the
+// BackupStorage. It returns false if it is unknown, for backups taken
+// before the size was recorded.
+func storedSize(fes []FileEntry) (int64, bool) {
+	var size int64
+	for _, fe := range fes {
+		size += fe.StoredSize
+	}
+	return size, size > 0 || len(fes) == 0
+}
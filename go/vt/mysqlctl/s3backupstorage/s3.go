@@ -26,6 +26,7 @@ package s3backupstorage
 import (
 	"crypto/md5"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"flag"
 	"fmt"
@@ -74,6 +75,14 @@ var (
 
 	tlsSkipVerifyCert = flag.Bool("s3_backup_tls_skip_verify_cert", false, "skip the 'certificate is valid' check for SSL connections")
 
+	// tlsCA is used to validate the certificate of S3-compatible appliances
+	// signed by a private CA.
+	tlsCA = flag.String("s3_backup_tls_ca", "", "path to a PEM file with the CA certificates used to validate the certificate of the S3 endpoint, instead of the system ones")
+
+	// uploadPartSize and uploadConcurrency tune the multipart uploads.
+	uploadPartSize    = flag.Int64("s3_backup_upload_part_size", s3manager.DefaultUploadPartSize, "size in bytes of the parts of multipart uploads. It is increased for files too large to fit in the maximum number of parts. The minimum is 5MiB")
+	uploadConcurrency = flag.Int("s3_backup_upload_concurrency", s3manager.DefaultUploadConcurrency, "number of parts of a file uploaded in parallel")
+
 	// verboseLogging provides more verbose logging of AWS actions
 	requiredLogLevel = flag.String("s3_backup_log_level", "LogOff", "determine the S3 loglevel to use from LogOff, LogDebug, LogDebugWithSigning, LogDebugWithHTTPBody, LogDebugWithRequestRetries, LogDebugWithRequestErrors")
 
@@ -133,7 +142,10 @@ func (bh *S3BackupHandle) AddFile(ctx context.Context, filename string, filesize
 	}
 
 	// Calculate s3 upload part size using the source filesize
-	partSizeBytes := s3manager.DefaultUploadPartSize
+	partSizeBytes := *uploadPartSize
+	if partSizeBytes < s3manager.MinUploadPartSize {
+		partSizeBytes = s3manager.MinUploadPartSize
+	}
 	if filesize > 0 {
 		minimumPartSize := float64(filesize) / float64(s3manager.MaxUploadParts)
 		// Round up to ensure large enough partsize
@@ -150,6 +162,7 @@ func (bh *S3BackupHandle) AddFile(ctx context.Context, filename string, filesize
 		defer bh.waitGroup.Done()
 		uploader := s3manager.NewUploaderWithClient(bh.client, func(u *s3manager.Uploader) {
 			u.PartSize = partSizeBytes
+			u.Concurrency = *uploadConcurrency
 		})
 		object := objName(bh.dir, bh.name, filename)
 
@@ -411,6 +424,16 @@ func (bs *S3BackupStorage) client() (*s3.S3, error) {
 		logLevel := getLogLevel()
 
 		tlsClientConf := &tls.Config{InsecureSkipVerify: *tlsSkipVerifyCert}
+		if *tlsCA != "" {
+			pem, err := os.ReadFile(*tlsCA)
+			if err != nil {
+				return nil, fmt.Errorf("can't read -s3_backup_tls_ca: %v", err)
+			}
+			tlsClientConf.RootCAs = x509.NewCertPool()
+			if !tlsClientConf.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in -s3_backup_tls_ca %v", *tlsCA)
+			}
+		}
 		httpTransport := &http.Transport{TLSClientConfig: tlsClientConf}
 		httpClient := &http.Client{Transport: httpTransport}
 
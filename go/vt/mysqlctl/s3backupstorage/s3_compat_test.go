@@ -0,0 +1,266 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3backupstorage
+
+import (
+	"bytes"
+	"context"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is a minimal S3-compatible server, standing in for MinIO. It only
+// supports path style addressing, and the calls used by S3BackupStorage.
+type fakeS3 struct {
+	bucket string
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	uploads map[string]map[int][]byte
+	// parts is the number of parts of the multipart uploads.
+	parts map[string]int
+}
+
+func newFakeS3(bucket string) *fakeS3 {
+	return &fakeS3{
+		bucket:  bucket,
+		objects: make(map[string][]byte),
+		uploads: make(map[string]map[int][]byte),
+		parts:   make(map[string]int),
+	}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if parts[0] != f.bucket {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	query := r.URL.Query()
+	if len(parts) == 1 || parts[1] == "" {
+		switch {
+		case r.Method == http.MethodHead:
+		case r.Method == http.MethodGet && query.Get("list-type") == "2":
+			f.list(w, query.Get("prefix"), query.Get("delimiter"))
+		case r.Method == http.MethodPost && query.Has("delete"):
+			f.delete(w, r)
+		default:
+			http.Error(w, "unsupported bucket request", http.StatusNotImplemented)
+		}
+		return
+	}
+
+	key := parts[1]
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		uploadID := fmt.Sprintf("upload-%v", len(f.parts))
+		f.uploads[uploadID] = make(map[int][]byte)
+		f.parts[key] = 0
+		writeXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadID string `xml:"UploadId"`
+		}{Bucket: f.bucket, Key: key, UploadID: uploadID})
+	case r.Method == http.MethodPut && query.Get("uploadId") != "":
+		partNumber, _ := strconv.Atoi(query.Get("partNumber"))
+		f.uploads[query.Get("uploadId")][partNumber] = body
+		w.Header().Set("ETag", fmt.Sprintf(`"%v"`, partNumber))
+	case r.Method == http.MethodPost && query.Get("uploadId") != "":
+		upload := f.uploads[query.Get("uploadId")]
+		numbers := make([]int, 0, len(upload))
+		for number := range upload {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+		var data []byte
+		for _, number := range numbers {
+			data = append(data, upload[number]...)
+		}
+		f.objects[key] = data
+		f.parts[key] = len(numbers)
+		delete(f.uploads, query.Get("uploadId"))
+		writeXML(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket  string
+			Key     string
+			ETag    string
+		}{Bucket: f.bucket, Key: key, ETag: `"etag"`})
+	case r.Method == http.MethodPut:
+		f.objects[key] = body
+	case r.Method == http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	default:
+		http.Error(w, "unsupported object request", http.StatusNotImplemented)
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, prefix, delimiter string) {
+	type content struct {
+		Key  string
+		Size int
+	}
+	type commonPrefix struct {
+		Prefix string
+	}
+	result := struct {
+		XMLName        xml.Name `xml:"ListBucketResult"`
+		Name           string
+		Prefix         string
+		IsTruncated    bool
+		Contents       []content
+		CommonPrefixes []commonPrefix
+	}{Name: f.bucket, Prefix: prefix}
+	seen := make(map[string]bool)
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				p := key[:len(prefix)+i+len(delimiter)]
+				if !seen[p] {
+					seen[p] = true
+					result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: p})
+				}
+				continue
+			}
+		}
+		result.Contents = append(result.Contents, content{Key: key, Size: len(f.objects[key])})
+	}
+	writeXML(w, result)
+}
+
+func (f *fakeS3) delete(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Objects []struct {
+			Key string
+		} `xml:"Object"`
+	}
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, obj := range req.Objects {
+		delete(f.objects, obj.Key)
+	}
+	writeXML(w, struct {
+		XMLName xml.Name `xml:"DeleteResult"`
+	}{})
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	data, _ := xml.Marshal(v)
+	w.Write(data)
+}
+
+func TestS3Compatible(t *testing.T) {
+	fake := newFakeS3("backups")
+	server := httptest.NewTLSServer(fake)
+	defer server.Close()
+
+	// Trust the certificate of the server through -s3_backup_tls_ca.
+	caFile := path.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "minio")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "minio123")
+	defer func(endpointSaved, bucketSaved, tlsCASaved string, forcePathSaved bool, partSizeSaved int64) {
+		*endpoint, *bucket, *tlsCA, *forcePath, *uploadPartSize = endpointSaved, bucketSaved, tlsCASaved, forcePathSaved, partSizeSaved
+	}(*endpoint, *bucket, *tlsCA, *forcePath, *uploadPartSize)
+	*endpoint = server.URL
+	*bucket = "backups"
+	*tlsCA = caFile
+	*forcePath = true
+	*uploadPartSize = s3manager.MinUploadPartSize
+
+	ctx := context.Background()
+	bs := &S3BackupStorage{}
+	defer bs.Close()
+	bh, err := bs.StartBackup(ctx, "ks/0", "backup1")
+	require.NoError(t, err)
+
+	small := []byte("small file")
+	wc, err := bh.AddFile(ctx, "small", int64(len(small)))
+	require.NoError(t, err)
+	_, err = wc.Write(small)
+	require.NoError(t, err)
+	require.NoError(t, wc.Close())
+
+	// Large files are uploaded in parts of -s3_backup_upload_part_size.
+	large := bytes.Repeat([]byte("0123456789abcdef"), int(2*s3manager.MinUploadPartSize+1024)/16)
+	wc, err = bh.AddFile(ctx, "large", int64(len(large)))
+	require.NoError(t, err)
+	_, err = wc.Write(large)
+	require.NoError(t, err)
+	require.NoError(t, wc.Close())
+	require.NoError(t, bh.EndBackup(ctx))
+	assert.Equal(t, 3, fake.parts["ks/0/backup1/large"])
+
+	bhs, err := bs.ListBackups(ctx, "ks/0")
+	require.NoError(t, err)
+	require.Len(t, bhs, 1)
+	assert.Equal(t, "backup1", bhs[0].Name())
+	for name, want := range map[string][]byte{"small": small, "large": large} {
+		rc, err := bhs[0].ReadFile(ctx, name)
+		require.NoError(t, err)
+		got, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(want, got), name)
+	}
+
+	require.NoError(t, bs.RemoveBackup(ctx, "ks/0", "backup1"))
+	bhs, err = bs.ListBackups(ctx, "ks/0")
+	require.NoError(t, err)
+	assert.Empty(t, bhs)
+	assert.Empty(t, fake.objects)
+}
@@ -0,0 +1,328 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package swiftbackupstorage implements the BackupStorage interface for
+// OpenStack Swift.
+//
+// Credentials can be passed with the flags, or the standard OpenStack
+// environment variables (OS_AUTH_URL, OS_USERNAME, OS_PASSWORD, ...), which
+// the flags override.
+//
+// Files are stored as Static Large Objects, so they can be larger than the
+// maximum size of a Swift object. Their segments go to a separate container.
+package swiftbackupstorage
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ncw/swift/v2"
+
+	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+)
+
+var (
+	authURL      = flag.String("swift_backup_auth_url", "", "Swift authentication URL, e.g. https://keystone.example.com/v3. Defaults to OS_AUTH_URL")
+	authVersion  = flag.Int("swift_backup_auth_version", 0, "Swift authentication version (1, 2 or 3), 0 to detect it from the authentication URL")
+	user         = flag.String("swift_backup_user", "", "Swift user name. Defaults to OS_USERNAME")
+	passwordFile = flag.String("swift_backup_password_file", "", "path to a file with the password or API key of the Swift user. Defaults to OS_PASSWORD")
+	domain       = flag.String("swift_backup_domain", "", "Keystone v3 domain of the Swift user. Defaults to OS_USER_DOMAIN_NAME")
+	tenant       = flag.String("swift_backup_tenant", "", "Swift tenant (project) name. Defaults to OS_PROJECT_NAME or OS_TENANT_NAME")
+	region       = flag.String("swift_backup_region", "", "Swift region. Defaults to OS_REGION_NAME, or the first region")
+	timeout      = flag.Duration("swift_backup_timeout", 60*time.Second, "timeout of Swift data operations")
+
+	// container is where the backups will go.
+	container = flag.String("swift_backup_storage_container", "", "Swift container to use for backups")
+
+	// segmentContainer is where the segments of the files go.
+	segmentContainer = flag.String("swift_backup_storage_segment_container", "", "Swift container to use for the segments of backup files. Defaults to the backup container with a _segments suffix")
+
+	// segmentSize is the size of the segments of the files.
+	segmentSize = flag.Int64("swift_backup_storage_segment_size", 1024*1024*1024, "size in bytes of the segments of backup files. It must be less than the maximum object size of the Swift cluster")
+
+	// root is a prefix added to all object names.
+	root = flag.String("swift_backup_storage_root", "", "root prefix for all backup-related object names")
+)
+
+// SwiftBackupHandle implements BackupHandle for OpenStack Swift.
+type SwiftBackupHandle struct {
+	conn     *swift.Connection
+	bs       *SwiftBackupStorage
+	dir      string
+	name     string
+	readOnly bool
+	errors   concurrency.AllErrorRecorder
+}
+
+// RecordError is part of the concurrency.ErrorRecorder interface.
+func (bh *SwiftBackupHandle) RecordError(err error) {
+	bh.errors.RecordError(err)
+}
+
+// HasErrors is part of the concurrency.ErrorRecorder interface.
+func (bh *SwiftBackupHandle) HasErrors() bool {
+	return bh.errors.HasErrors()
+}
+
+// Error is part of the concurrency.ErrorRecorder interface.
+func (bh *SwiftBackupHandle) Error() error {
+	return bh.errors.Error()
+}
+
+// Directory implements BackupHandle.
+func (bh *SwiftBackupHandle) Directory() string {
+	return bh.dir
+}
+
+// Name implements BackupHandle.
+func (bh *SwiftBackupHandle) Name() string {
+	return bh.name
+}
+
+// AddFile implements BackupHandle.
+func (bh *SwiftBackupHandle) AddFile(ctx context.Context, filename string, filesize int64) (io.WriteCloser, error) {
+	if bh.readOnly {
+		return nil, fmt.Errorf("AddFile cannot be called on read-only backup")
+	}
+	object := objName(bh.dir, bh.name, filename)
+	return bh.conn.StaticLargeObjectCreate(ctx, &swift.LargeObjectOpts{
+		Container:        *container,
+		ObjectName:       object,
+		ChunkSize:        *segmentSize,
+		SegmentContainer: segmentContainerName(),
+		SegmentPrefix:    object,
+	})
+}
+
+// EndBackup implements BackupHandle.
+func (bh *SwiftBackupHandle) EndBackup(ctx context.Context) error {
+	if bh.readOnly {
+		return fmt.Errorf("EndBackup cannot be called on read-only backup")
+	}
+	return nil
+}
+
+// AbortBackup implements BackupHandle.
+func (bh *SwiftBackupHandle) AbortBackup(ctx context.Context) error {
+	if bh.readOnly {
+		return fmt.Errorf("AbortBackup cannot be called on read-only backup")
+	}
+	return bh.bs.RemoveBackup(ctx, bh.dir, bh.name)
+}
+
+// ReadFile implements BackupHandle.
+func (bh *SwiftBackupHandle) ReadFile(ctx context.Context, filename string) (io.ReadCloser, error) {
+	if !bh.readOnly {
+		return nil, fmt.Errorf("ReadFile cannot be called on read-write backup")
+	}
+	object := objName(bh.dir, bh.name, filename)
+	file, _, err := bh.conn.ObjectOpen(ctx, *container, object, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// SwiftBackupStorage implements BackupStorage for OpenStack Swift.
+type SwiftBackupStorage struct {
+	// _conn is the authenticated Swift connection.
+	_conn *swift.Connection
+	// mu guards all fields.
+	mu sync.Mutex
+}
+
+// ListBackups implements BackupStorage.
+func (bs *SwiftBackupStorage) ListBackups(ctx context.Context, dir string) ([]backupstorage.BackupHandle, error) {
+	conn, err := bs.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var searchPrefix string
+	if dir == "/" {
+		searchPrefix = objName("")
+	} else {
+		searchPrefix = objName(dir, "" /* include trailing slash */)
+	}
+	objects, err := conn.ObjectsAll(ctx, *container, &swift.ObjectsOpts{
+		Prefix:    searchPrefix,
+		Delimiter: '/',
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Each pseudo directory is a backup.
+	var subdirs []string
+	for _, obj := range objects {
+		if !obj.PseudoDirectory {
+			continue
+		}
+		subdir := strings.TrimPrefix(obj.Name, searchPrefix)
+		subdir = strings.TrimSuffix(subdir, "/")
+		subdirs = append(subdirs, subdir)
+	}
+
+	// Backups must be returned in order, oldest first.
+	sort.Strings(subdirs)
+
+	result := make([]backupstorage.BackupHandle, 0, len(subdirs))
+	for _, subdir := range subdirs {
+		result = append(result, &SwiftBackupHandle{
+			conn:     conn,
+			bs:       bs,
+			dir:      dir,
+			name:     subdir,
+			readOnly: true,
+		})
+	}
+	return result, nil
+}
+
+// StartBackup implements BackupStorage.
+func (bs *SwiftBackupStorage) StartBackup(ctx context.Context, dir, name string) (backupstorage.BackupHandle, error) {
+	conn, err := bs.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SwiftBackupHandle{
+		conn:     conn,
+		bs:       bs,
+		dir:      dir,
+		name:     name,
+		readOnly: false,
+	}, nil
+}
+
+// RemoveBackup implements BackupStorage.
+func (bs *SwiftBackupStorage) RemoveBackup(ctx context.Context, dir, name string) error {
+	conn, err := bs.conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	names, err := conn.ObjectNamesAll(ctx, *container, &swift.ObjectsOpts{
+		Prefix: objName(dir, name, "" /* include trailing slash */),
+	})
+	if err != nil {
+		return err
+	}
+	// Delete the objects, and their segments.
+	for _, name := range names {
+		if err := conn.LargeObjectDelete(ctx, *container, name); err != nil && err != swift.ObjectNotFound {
+			return fmt.Errorf("unable to delete %q from container %q: %v", name, *container, err)
+		}
+	}
+	return nil
+}
+
+// Close implements BackupStorage.
+func (bs *SwiftBackupStorage) Close() error {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	bs._conn = nil
+	return nil
+}
+
+// conn returns the authenticated Swift connection.
+// If there isn't one yet, it tries to create one.
+func (bs *SwiftBackupStorage) conn(ctx context.Context) (*swift.Connection, error) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if bs._conn == nil {
+		if *container == "" {
+			return nil, fmt.Errorf("-swift_backup_storage_container required")
+		}
+
+		conn := &swift.Connection{}
+		if err := conn.ApplyEnvironment(); err != nil {
+			return nil, fmt.Errorf("invalid OpenStack environment variables: %v", err)
+		}
+		if *authURL != "" {
+			conn.AuthUrl = *authURL
+		}
+		if *authVersion != 0 {
+			conn.AuthVersion = *authVersion
+		}
+		if *user != "" {
+			conn.UserName = *user
+		}
+		if *passwordFile != "" {
+			data, err := os.ReadFile(*passwordFile)
+			if err != nil {
+				return nil, fmt.Errorf("can't read -swift_backup_password_file: %v", err)
+			}
+			conn.ApiKey = strings.TrimSpace(string(data))
+		}
+		if *domain != "" {
+			conn.Domain = *domain
+		}
+		if *tenant != "" {
+			conn.Tenant = *tenant
+		}
+		if *region != "" {
+			conn.Region = *region
+		}
+		conn.Timeout = *timeout
+
+		if err := conn.Authenticate(ctx); err != nil {
+			return nil, fmt.Errorf("can't authenticate to Swift: %v", err)
+		}
+		// Make sure the containers exist. Creating a container that
+		// exists is a no-op.
+		for _, c := range []string{*container, segmentContainerName()} {
+			if err := conn.ContainerCreate(ctx, c, nil); err != nil {
+				return nil, fmt.Errorf("can't create Swift container %v: %v", c, err)
+			}
+		}
+		log.Infof("Connected to Swift storage %v", conn.StorageUrl)
+		bs._conn = conn
+	}
+	return bs._conn, nil
+}
+
+// segmentContainerName returns the container of the segments of the files.
+func segmentContainerName() string {
+	if *segmentContainer != "" {
+		return *segmentContainer
+	}
+	return *container + "_segments"
+}
+
+// objName joins path parts into an object name.
+// Unlike path.Join, it doesn't collapse ".." or strip trailing slashes.
+// It also adds the value of the -swift_backup_storage_root flag if set.
+func objName(parts ...string) string {
+	if *root != "" {
+		return *root + "/" + strings.Join(parts, "/")
+	}
+	return strings.Join(parts, "/")
+}
+
+func init() {
+	backupstorage.BackupStorageMap["swift"] = &SwiftBackupStorage{}
+}
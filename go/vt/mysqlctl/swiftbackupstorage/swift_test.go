@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package swiftbackupstorage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ncw/swift/v2/swifttest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwiftBackupStorage(t *testing.T) {
+	server, err := swifttest.NewSwiftServer("localhost")
+	require.NoError(t, err)
+	defer server.Close()
+
+	passwordPath := path.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(passwordPath, []byte(swifttest.TEST_ACCOUNT+"\n"), 0600))
+	defer func(authURLSaved, userSaved, passwordFileSaved, containerSaved string, segmentSizeSaved int64) {
+		*authURL, *user, *passwordFile, *container, *segmentSize = authURLSaved, userSaved, passwordFileSaved, containerSaved, segmentSizeSaved
+	}(*authURL, *user, *passwordFile, *container, *segmentSize)
+	*authURL = server.AuthURL
+	*user = swifttest.TEST_ACCOUNT
+	*passwordFile = passwordPath
+	*container = "backups"
+	*segmentSize = 1024
+
+	ctx := context.Background()
+	bs := &SwiftBackupStorage{}
+	defer bs.Close()
+
+	dir := "ks/0"
+	bh, err := bs.StartBackup(ctx, dir, "backup2")
+	require.NoError(t, err)
+	// Files larger than the segment size are split in segments.
+	contents := bytes.Repeat([]byte("some data "), 500)
+	wc, err := bh.AddFile(ctx, "file1", int64(len(contents)))
+	require.NoError(t, err)
+	_, err = wc.Write(contents)
+	require.NoError(t, err)
+	require.NoError(t, wc.Close())
+	require.NoError(t, bh.EndBackup(ctx))
+
+	bh, err = bs.StartBackup(ctx, dir, "backup1")
+	require.NoError(t, err)
+	wc, err = bh.AddFile(ctx, "file1", 5)
+	require.NoError(t, err)
+	_, err = wc.Write([]byte("small"))
+	require.NoError(t, err)
+	require.NoError(t, wc.Close())
+	require.NoError(t, bh.EndBackup(ctx))
+
+	bhs, err := bs.ListBackups(ctx, dir)
+	require.NoError(t, err)
+	require.Len(t, bhs, 2)
+	assert.Equal(t, "backup1", bhs[0].Name())
+	assert.Equal(t, "backup2", bhs[1].Name())
+	assert.Equal(t, dir, bhs[1].Directory())
+
+	rc, err := bhs[1].ReadFile(ctx, "file1")
+	require.NoError(t, err)
+	got, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	assert.True(t, bytes.Equal(contents, got))
+	_, err = bhs[1].AddFile(ctx, "file2", 0)
+	assert.Error(t, err)
+
+	// Removing a backup also removes the segments of its files.
+	require.NoError(t, bs.RemoveBackup(ctx, dir, "backup2"))
+	bhs, err = bs.ListBackups(ctx, dir)
+	require.NoError(t, err)
+	require.Len(t, bhs, 1)
+	assert.Equal(t, "backup1", bhs[0].Name())
+	conn, err := bs.conn(ctx)
+	require.NoError(t, err)
+	segments, err := conn.ObjectNamesAll(ctx, "backups_segments", nil)
+	require.NoError(t, err)
+	for _, segment := range segments {
+		assert.NotContains(t, segment, "backup2")
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vaultbackupkeyprovider implements a BackupKeyProvider that reads
+// the key encryption keys of backups from Vault.
+package vaultbackupkeyprovider
+
+import (
+	"context"
+	"flag"
+	"sync"
+	"time"
+
+	vaultapi "github.com/aquarapid/vaultlib"
+
+	"vitess.io/vitess/go/mysql/vault"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+var (
+	vaultAddr             = flag.String("backup_encryption_vault_addr", "", "URL to Vault server")
+	vaultTimeout          = flag.Duration("backup_encryption_vault_timeout", 10*time.Second, "Timeout for vault API operations")
+	vaultCACert           = flag.String("backup_encryption_vault_tls_ca", "", "Path to CA PEM for validating Vault server certificate")
+	vaultPath             = flag.String("backup_encryption_vault_path", "", `Vault path to the backup encryption keys JSON blob, e.g.: secret/data/prod/backupkeys. Its format is {"current": "<key id>", "keys": {"<key id>": "<base64 AES key>"}}`)
+	vaultTokenFile        = flag.String("backup_encryption_vault_tokenfile", "", "Path to file containing Vault auth token; token can also be passed using VAULT_TOKEN environment variable")
+	vaultRoleID           = flag.String("backup_encryption_vault_roleid", "", "Vault AppRole id; can also be passed using VAULT_ROLEID environment variable")
+	vaultRoleSecretIDFile = flag.String("backup_encryption_vault_role_secretidfile", "", "Path to file containing Vault AppRole secret_id; can also be passed using VAULT_SECRETID environment variable")
+	vaultRoleMountPoint   = flag.String("backup_encryption_vault_role_mountpoint", "approle", "Vault AppRole mountpoint; can also be passed using VAULT_MOUNTPOINT environment variable")
+)
+
+// KeyProvider implements mysqlctl.BackupKeyProvider. The key set is read
+// from Vault every time a key is needed, so keys can be rotated in Vault.
+type KeyProvider struct {
+	mu     sync.Mutex
+	client *vaultapi.Client
+}
+
+func (kp *KeyProvider) keySet() (*mysqlctl.BackupKeySet, error) {
+	if *vaultAddr == "" || *vaultPath == "" {
+		return nil, vterrors.New(vtrpc.Code_FAILED_PRECONDITION, "backup_encryption_vault_addr and backup_encryption_vault_path must be set to use the vault backup key provider")
+	}
+
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	if kp.client == nil {
+		token, err := vault.ReadFromFile(*vaultTokenFile)
+		if err != nil {
+			return nil, vterrors.Wrap(err, "can't read -backup_encryption_vault_tokenfile")
+		}
+		secretID, err := vault.ReadFromFile(*vaultRoleSecretIDFile)
+		if err != nil {
+			return nil, vterrors.Wrap(err, "can't read -backup_encryption_vault_role_secretidfile")
+		}
+		client, err := vault.NewClient(vault.ClientConfig{
+			Addr:           *vaultAddr,
+			Timeout:        *vaultTimeout,
+			CACert:         *vaultCACert,
+			Token:          token,
+			RoleID:         *vaultRoleID,
+			SecretID:       secretID,
+			RoleMountPoint: *vaultRoleMountPoint,
+		})
+		if err != nil || client == nil {
+			return nil, vterrors.Errorf(vtrpc.Code_UNAVAILABLE, "can't create vault client: %v", err)
+		}
+		kp.client = client
+	}
+
+	secret, err := kp.client.GetSecret(*vaultPath)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "can't read backup keys from vault path %v", *vaultPath)
+	}
+	if secret.JSONSecret == nil {
+		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "no backup keys at vault path %v", *vaultPath)
+	}
+	return mysqlctl.ParseBackupKeySet(secret.JSONSecret)
+}
+
+// WrapKey is part of the mysqlctl.BackupKeyProvider interface.
+func (kp *KeyProvider) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	ks, err := kp.keySet()
+	if err != nil {
+		return "", nil, err
+	}
+	return ks.WrapKey(dataKey)
+}
+
+// UnwrapKey is part of the mysqlctl.BackupKeyProvider interface.
+func (kp *KeyProvider) UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error) {
+	ks, err := kp.keySet()
+	if err != nil {
+		return nil, err
+	}
+	return ks.UnwrapKey(keyID, wrappedKey)
+}
+
+func init() {
+	mysqlctl.BackupKeyProviderMap["vault"] = &KeyProvider{}
+}
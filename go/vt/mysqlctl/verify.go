@@ -0,0 +1,366 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// This file handles the verification of backups: a backup is restored into
+// a scratch mysqld, its tables are checked, and the result is stored in the
+// BackupStorage, next to the backups, like binary log archives are.
+
+const (
+	// backupVerificationFileName is the name of the verification result
+	// within its directory.
+	backupVerificationFileName = "VERIFICATION"
+)
+
+// BackupVerification is the result of the verification of a backup.
+type BackupVerification struct {
+	// BackupName is the name of the verified backup.
+	BackupName string
+
+	// VerifiedTime is the time (in RFC 3339 format, UTC) of the
+	// verification.
+	VerifiedTime string
+
+	// Success is true if the backup was restored, and its tables checked
+	// without errors.
+	Success bool
+
+	// Errors are the reasons why the verification failed.
+	Errors []string `json:",omitempty"`
+
+	// Warnings are suspicious results that don't fail the verification,
+	// like tables that lost all their rows since the previous verification.
+	Warnings []string `json:",omitempty"`
+
+	// Tables are the results of the checks of the tables.
+	Tables []TableVerification `json:",omitempty"`
+}
+
+// TableVerification is the result of the checks of a table.
+type TableVerification struct {
+	Name string
+
+	// Check is the status returned by CHECK TABLE.
+	Check string
+
+	// Rows is the number of rows of the table.
+	Rows int64
+}
+
+// GetBackupVerificationDir returns the directory where the results of the
+// verification of the backups of the given keyspace/shard are stored.
+func GetBackupVerificationDir(keyspace, shard string) string {
+	return fmt.Sprintf("%v/%v.verifications", keyspace, shard)
+}
+
+// VerifyRestoredBackup checks the tables of dbName, which a backup was just
+// restored into, and returns the result of the verification. previous is
+// the last successful verification of an older backup of the shard, if
+// any. Tables that disappeared, or lost all their rows since then, are
+// reported as warnings.
+func VerifyRestoredBackup(ctx context.Context, mysqld MysqlDaemon, dbName string, previous *BackupVerification, logger logutil.Logger) (*BackupVerification, error) {
+	qr, err := mysqld.FetchSuperQuery(ctx, fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_schema = %s AND table_type = 'BASE TABLE' ORDER BY table_name", sqltypes.EncodeStringSQL(dbName)))
+	if err != nil {
+		return nil, vterrors.Wrap(err, "can't list tables")
+	}
+
+	bv := &BackupVerification{}
+	rows := make(map[string]int64)
+	for _, row := range qr.Rows {
+		name := row[0].ToString()
+		table := sqlescape.EscapeID(dbName) + "." + sqlescape.EscapeID(name)
+		tv := TableVerification{Name: name}
+
+		check, err := mysqld.FetchSuperQuery(ctx, "CHECK TABLE "+table)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "can't check table %v", name)
+		}
+		// The rows of CHECK TABLE are: Table, Op, Msg_type, Msg_text.
+		// The last one has the status.
+		for _, checkRow := range check.Rows {
+			msgType, msgText := checkRow[2].ToString(), checkRow[3].ToString()
+			if strings.EqualFold(msgType, "error") {
+				bv.Errors = append(bv.Errors, fmt.Sprintf("CHECK TABLE %v: %v", name, msgText))
+			}
+			tv.Check = msgText
+		}
+		if !strings.EqualFold(tv.Check, "OK") && !strings.EqualFold(tv.Check, "Table is already up to date") {
+			bv.Errors = append(bv.Errors, fmt.Sprintf("CHECK TABLE %v returned %q", name, tv.Check))
+		}
+
+		count, err := mysqld.FetchSuperQuery(ctx, "SELECT COUNT(*) FROM "+table)
+		if err != nil {
+			bv.Errors = append(bv.Errors, fmt.Sprintf("can't count rows of %v: %v", name, err))
+		} else {
+			tv.Rows, err = count.Rows[0][0].ToInt64()
+			if err != nil {
+				return nil, vterrors.Wrapf(err, "invalid row count for %v", name)
+			}
+		}
+		rows[name] = tv.Rows
+		logger.Infof("Verified table %v: %v, %v rows", name, tv.Check, tv.Rows)
+		bv.Tables = append(bv.Tables, tv)
+	}
+	if len(bv.Tables) == 0 {
+		bv.Errors = append(bv.Errors, fmt.Sprintf("no tables in database %v", dbName))
+	}
+
+	if previous != nil {
+		for _, tv := range previous.Tables {
+			n, ok := rows[tv.Name]
+			switch {
+			case !ok:
+				bv.Warnings = append(bv.Warnings, fmt.Sprintf("table %v was in backup %v, but is missing", tv.Name, previous.BackupName))
+			case n == 0 && tv.Rows > 0:
+				bv.Warnings = append(bv.Warnings, fmt.Sprintf("table %v had %v rows in backup %v, but is empty", tv.Name, tv.Rows, previous.BackupName))
+			}
+		}
+	}
+	for _, warning := range bv.Warnings {
+		logger.Warningf("Backup verification: %v", warning)
+	}
+
+	bv.Success = len(bv.Errors) == 0
+	return bv, nil
+}
+
+// StartScratchMysqld initializes a mysqld with an empty data dir, using the
+// built-in init_db.sql, and starts it, for tools that don't run next to a
+// mysqld to restore backups into. Like vttest, it connects to mysqld as the
+// vt_dba user created by init_db.sql. The returned function shuts mysqld
+// down and removes its directory.
+func StartScratchMysqld(ctx context.Context, tabletUID uint32, mysqlPort int32) (*Mysqld, *Mycnf, func(), error) {
+	mycnf := NewMycnf(tabletUID, mysqlPort)
+	if err := mycnf.RandomizeMysqlServerID(); err != nil {
+		return nil, nil, nil, vterrors.Wrap(err, "can't generate random MySQL server_id")
+	}
+	cp := mysql.ConnParams{
+		Uname:      "vt_dba",
+		Charset:    "utf8",
+		UnixSocket: mycnf.SocketFile,
+	}
+	mysqld := NewMysqld(dbconfigs.NewTestDBConfigs(cp, cp, ""))
+	tabletDir := TabletDir(tabletUID)
+	removeTabletDir := func() {
+		mysqld.Close()
+		if err := os.RemoveAll(tabletDir); err != nil {
+			log.Warningf("Failed to remove scratch tablet directory %v: %v", tabletDir, err)
+		}
+	}
+	if err := mysqld.Init(ctx, mycnf, ""); err != nil {
+		removeTabletDir()
+		return nil, nil, nil, vterrors.Wrap(err, "can't initialize scratch mysqld")
+	}
+	cleanup := func() {
+		// Shut mysqld down even if ctx is done.
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := mysqld.Shutdown(ctx, mycnf, false); err != nil {
+			log.Errorf("Failed to shut scratch mysqld down: %v", err)
+		}
+		removeTabletDir()
+	}
+	return mysqld, mycnf, cleanup, nil
+}
+
+// VerifyBackup restores a backup of params.Keyspace/params.Shard into the
+// scratch mysqld of params, checks its tables, and stores the result of the
+// verification next to the backups. It verifies params.BackupName, or the
+// most recent complete backup if it's empty. The tables are compared with
+// the last successful verification of an older backup. A backup that can't
+// be restored is recorded as invalid, and an error is returned along with
+// the result if the backup is invalid.
+func VerifyBackup(ctx context.Context, bs backupstorage.BackupStorage, params RestoreParams) (*BackupVerification, error) {
+	backupName, err := backupToVerify(ctx, bs, GetBackupDir(params.Keyspace, params.Shard), params.BackupName)
+	if err != nil {
+		return nil, err
+	}
+	verifications, err := ListBackupVerifications(ctx, bs, params.Keyspace, params.Shard, params.Logger)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "can't list backup verifications")
+	}
+	// Backup names start with their time, so they sort chronologically.
+	var previous *BackupVerification
+	for _, bv := range verifications {
+		if bv.Success && bv.BackupName < backupName {
+			previous = bv
+		}
+	}
+
+	params.BackupName = backupName
+	params.Logger.Infof("Restoring backup %v of %v/%v to verify it", backupName, params.Keyspace, params.Shard)
+	restore := func() error {
+		_, err := Restore(ctx, params)
+		return err
+	}
+	verify := func() (*BackupVerification, error) {
+		return VerifyRestoredBackup(ctx, params.Mysqld, params.DbName, previous, params.Logger)
+	}
+	return recordBackupVerification(ctx, bs, params.Keyspace, params.Shard, backupName, restore, verify, params.Logger)
+}
+
+// backupToVerify returns the name of the backup to verify. If name is
+// empty, it is the most recent complete backup of backupDir.
+func backupToVerify(ctx context.Context, bs backupstorage.BackupStorage, backupDir, name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+	bhs, err := bs.ListBackups(ctx, backupDir)
+	if err != nil {
+		return "", vterrors.Wrap(err, "ListBackups failed")
+	}
+	// Backups are sorted in ascending order by start time. Only complete
+	// ones have a MANIFEST.
+	for i := len(bhs) - 1; i >= 0; i-- {
+		if _, err := GetBackupManifest(ctx, bhs[i]); err == nil {
+			return bhs[i].Name(), nil
+		}
+	}
+	return "", vterrors.Errorf(vtrpc.Code_NOT_FOUND, "no complete backup to verify in %v", backupDir)
+}
+
+// recordBackupVerification restores the backup and verifies the restored
+// tables, and stores the result of the verification next to the backup.
+// A backup that can't be restored is recorded as invalid.
+func recordBackupVerification(ctx context.Context, bs backupstorage.BackupStorage, keyspace, shard, backupName string, restore func() error, verify func() (*BackupVerification, error), logger logutil.Logger) (*BackupVerification, error) {
+	bv := &BackupVerification{}
+	if err := restore(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		bv.Errors = append(bv.Errors, fmt.Sprintf("can't restore backup: %v", err))
+	} else {
+		var err error
+		bv, err = verify()
+		if err != nil {
+			return nil, vterrors.Wrap(err, "can't verify restored backup")
+		}
+	}
+	bv.BackupName = backupName
+	bv.VerifiedTime = time.Now().UTC().Format(time.RFC3339)
+	if err := WriteBackupVerification(ctx, bs, keyspace, shard, bv); err != nil {
+		return nil, vterrors.Wrap(err, "can't store result of the verification")
+	}
+
+	if !bv.Success {
+		return bv, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "backup %v is invalid: %v", backupName, strings.Join(bv.Errors, "; "))
+	}
+	logger.Infof("Backup %v verified: %v tables checked.", backupName, len(bv.Tables))
+	return bv, nil
+}
+
+// WriteBackupVerification stores the result of the verification of a
+// backup of the given keyspace/shard, replacing the previous one, if any.
+func WriteBackupVerification(ctx context.Context, bs backupstorage.BackupStorage, keyspace, shard string, bv *BackupVerification) (finalErr error) {
+	dir := GetBackupVerificationDir(keyspace, shard)
+	bhs, err := bs.ListBackups(ctx, dir)
+	if err != nil {
+		return vterrors.Wrap(err, "ListBackups failed")
+	}
+	for _, bh := range bhs {
+		if bh.Name() == bv.BackupName {
+			if err := bs.RemoveBackup(ctx, dir, bv.BackupName); err != nil {
+				return vterrors.Wrapf(err, "can't remove previous verification of %v", bv.BackupName)
+			}
+		}
+	}
+
+	bh, err := bs.StartBackup(ctx, dir, bv.BackupName)
+	if err != nil {
+		return vterrors.Wrap(err, "StartBackup failed")
+	}
+	defer func() {
+		if finalErr != nil {
+			if err := bh.AbortBackup(ctx); err != nil {
+				log.Errorf("failed to abort verification of %v: %v", bv.BackupName, err)
+			}
+			return
+		}
+		finalErr = bh.EndBackup(ctx)
+	}()
+
+	data, err := json.MarshalIndent(bv, "", "  ")
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot JSON encode %v", backupVerificationFileName)
+	}
+	wc, err := bh.AddFile(ctx, backupVerificationFileName, int64(len(data)))
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot add %v", backupVerificationFileName)
+	}
+	if _, err := wc.Write(data); err != nil {
+		wc.Close()
+		return vterrors.Wrapf(err, "cannot write %v", backupVerificationFileName)
+	}
+	return wc.Close()
+}
+
+// ListBackupVerifications returns the results of the verifications of the
+// backups of the given keyspace/shard, sorted like the backups.
+func ListBackupVerifications(ctx context.Context, bs backupstorage.BackupStorage, keyspace, shard string, logger logutil.Logger) ([]*BackupVerification, error) {
+	dir := GetBackupVerificationDir(keyspace, shard)
+	bhs, err := bs.ListBackups(ctx, dir)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "ListBackups failed")
+	}
+	var result []*BackupVerification
+	for _, bh := range bhs {
+		bv, err := readBackupVerification(ctx, bh)
+		if err != nil {
+			logger.Warningf("Can't read verification %v in directory %v on BackupStorage: %v", bh.Name(), dir, err)
+			continue
+		}
+		result = append(result, bv)
+	}
+	return result, nil
+}
+
+func readBackupVerification(ctx context.Context, bh backupstorage.BackupHandle) (*BackupVerification, error) {
+	rc, err := bh.ReadFile(ctx, backupVerificationFileName)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "can't read %v", backupVerificationFileName)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "can't read %v", backupVerificationFileName)
+	}
+	bv := &BackupVerification{}
+	if err := json.Unmarshal(data, bv); err != nil {
+		return nil, vterrors.Wrapf(err, "can't decode %v", backupVerificationFileName)
+	}
+	return bv, nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
+)
+
+// newTestBackupStorage returns a file backup storage in a temporary directory.
+func newTestBackupStorage(t *testing.T) backupstorage.BackupStorage {
+	oldRoot := *filebackupstorage.FileBackupStorageRoot
+	oldImplementation := *backupstorage.BackupStorageImplementation
+	t.Cleanup(func() {
+		*filebackupstorage.FileBackupStorageRoot = oldRoot
+		*backupstorage.BackupStorageImplementation = oldImplementation
+	})
+	*filebackupstorage.FileBackupStorageRoot = path.Join(t.TempDir(), "backups")
+	*backupstorage.BackupStorageImplementation = "file"
+
+	bs, err := backupstorage.GetBackupStorage()
+	require.NoError(t, err)
+	t.Cleanup(func() { bs.Close() })
+	return bs
+}
+
+// addBackup creates a backup. Only complete backups have a MANIFEST.
+func addBackup(t *testing.T, bs backupstorage.BackupStorage, name string, complete bool) {
+	ctx := context.Background()
+	bh, err := bs.StartBackup(ctx, GetBackupDir("ks", "0"), name)
+	require.NoError(t, err)
+	if complete {
+		w, err := bh.AddFile(ctx, "MANIFEST", 0)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(`{"BackupMethod": "builtin"}`))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	}
+	require.NoError(t, bh.EndBackup(ctx))
+}
+
+func TestBackupToVerify(t *testing.T) {
+	ctx := context.Background()
+	bs := newTestBackupStorage(t)
+	backupDir := GetBackupDir("ks", "0")
+
+	_, err := backupToVerify(ctx, bs, backupDir, "")
+	assert.EqualError(t, err, "no complete backup to verify in ks/0")
+
+	addBackup(t, bs, "2021-06-01.030000.cell1-0000000101", true)
+	addBackup(t, bs, "2021-06-02.030000.cell1-0000000101", true)
+	// Incomplete backups are skipped.
+	addBackup(t, bs, "2021-06-03.030000.cell1-0000000101", false)
+
+	name, err := backupToVerify(ctx, bs, backupDir, "")
+	require.NoError(t, err)
+	assert.Equal(t, "2021-06-02.030000.cell1-0000000101", name)
+
+	name, err = backupToVerify(ctx, bs, backupDir, "2021-06-01.030000.cell1-0000000101")
+	require.NoError(t, err)
+	assert.Equal(t, "2021-06-01.030000.cell1-0000000101", name)
+}
+
+func TestRecordBackupVerification(t *testing.T) {
+	ctx := context.Background()
+	bs := newTestBackupStorage(t)
+	logger := logutil.NewMemoryLogger()
+
+	verified := false
+	verify := func() (*BackupVerification, error) {
+		verified = true
+		return &BackupVerification{
+			Success: true,
+			Tables:  []TableVerification{{Name: "t1", Check: "OK", Rows: 10}},
+		}, nil
+	}
+
+	// A successful verification is recorded.
+	bv, err := recordBackupVerification(ctx, bs, "ks", "0", "2021-06-01.030000.cell1-0000000101", func() error { return nil }, verify, logger)
+	require.NoError(t, err)
+	assert.True(t, bv.Success)
+	assert.True(t, verified)
+
+	// A backup that can't be restored is recorded as invalid, without
+	// verifying the tables.
+	verified = false
+	restoreFailed := func() error { return errors.New("corrupted file") }
+	bv, err = recordBackupVerification(ctx, bs, "ks", "0", "2021-06-02.030000.cell1-0000000101", restoreFailed, verify, logger)
+	assert.False(t, bv.Success)
+	assert.EqualError(t, err, "backup 2021-06-02.030000.cell1-0000000101 is invalid: can't restore backup: corrupted file")
+	assert.False(t, verified)
+
+	verifications, err := ListBackupVerifications(ctx, bs, "ks", "0", logger)
+	require.NoError(t, err)
+	require.Len(t, verifications, 2)
+	assert.Equal(t, "2021-06-01.030000.cell1-0000000101", verifications[0].BackupName)
+	assert.True(t, verifications[0].Success)
+	assert.Len(t, verifications[0].Tables, 1)
+	assert.Equal(t, "2021-06-02.030000.cell1-0000000101", verifications[1].BackupName)
+	assert.False(t, verifications[1].Success)
+	assert.Equal(t, []string{"can't restore backup: corrupted file"}, verifications[1].Errors)
+	assert.NotEmpty(t, verifications[1].VerifiedTime)
+
+	// A restore interrupted by the context is not recorded.
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = recordBackupVerification(canceledCtx, bs, "ks", "0", "2021-06-03.030000.cell1-0000000101", restoreFailed, verify, logger)
+	assert.Equal(t, context.Canceled, err)
+	verifications, err = ListBackupVerifications(ctx, bs, "ks", "0", logger)
+	require.NoError(t, err)
+	assert.Len(t, verifications, 2)
+}
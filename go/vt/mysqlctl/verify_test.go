@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl_test
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/fakemysqldaemon"
+	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
+)
+
+func TestVerifyRestoredBackup(t *testing.T) {
+	oldRoot := *filebackupstorage.FileBackupStorageRoot
+	oldImplementation := *backupstorage.BackupStorageImplementation
+	defer func() {
+		*filebackupstorage.FileBackupStorageRoot = oldRoot
+		*backupstorage.BackupStorageImplementation = oldImplementation
+	}()
+	*filebackupstorage.FileBackupStorageRoot = path.Join(t.TempDir(), "backups")
+	*backupstorage.BackupStorageImplementation = "file"
+
+	checkFields := sqltypes.MakeTestFields("Table|Op|Msg_type|Msg_text", "varchar|varchar|varchar|varchar")
+	countFields := sqltypes.MakeTestFields("count(*)", "int64")
+	mysqld := fakemysqldaemon.NewFakeMysqlDaemon(fakesqldb.New(t))
+	defer mysqld.Close()
+	mysqld.FetchSuperQueryMap = map[string]*sqltypes.Result{
+		"SELECT table_name FROM information_schema.tables WHERE table_schema = 'vt_ks' AND table_type = 'BASE TABLE' ORDER BY table_name": sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields("table_name", "varchar"),
+			"t1",
+			"t2",
+		),
+		"CHECK TABLE `vt_ks`.`t1`":          sqltypes.MakeTestResult(checkFields, "vt_ks.t1|check|status|OK"),
+		"SELECT COUNT(*) FROM `vt_ks`.`t1`": sqltypes.MakeTestResult(countFields, "10"),
+		"CHECK TABLE `vt_ks`.`t2`": sqltypes.MakeTestResult(checkFields,
+			"vt_ks.t2|check|error|Corrupt",
+			"vt_ks.t2|check|status|Corrupt",
+		),
+		"SELECT COUNT(*) FROM `vt_ks`.`t2`": sqltypes.MakeTestResult(countFields, "0"),
+	}
+
+	ctx := context.Background()
+	logger := logutil.NewMemoryLogger()
+	previous := &mysqlctl.BackupVerification{
+		BackupName: "2021-01-01.000000.cell1-0000000100",
+		Success:    true,
+		Tables: []mysqlctl.TableVerification{
+			{Name: "t1", Check: "OK", Rows: 5},
+			{Name: "t2", Check: "OK", Rows: 5},
+			{Name: "t3", Check: "OK", Rows: 5},
+		},
+	}
+	bv, err := mysqlctl.VerifyRestoredBackup(ctx, mysqld, "vt_ks", previous, logger)
+	require.NoError(t, err)
+	assert.False(t, bv.Success)
+	assert.Equal(t, []mysqlctl.TableVerification{
+		{Name: "t1", Check: "OK", Rows: 10},
+		{Name: "t2", Check: "Corrupt", Rows: 0},
+	}, bv.Tables)
+	assert.Equal(t, []string{
+		"CHECK TABLE t2: Corrupt",
+		`CHECK TABLE t2 returned "Corrupt"`,
+	}, bv.Errors)
+	assert.Equal(t, []string{
+		"table t2 had 5 rows in backup 2021-01-01.000000.cell1-0000000100, but is empty",
+		"table t3 was in backup 2021-01-01.000000.cell1-0000000100, but is missing",
+	}, bv.Warnings)
+
+	// Verifications are stored next to the backups, and can be replaced.
+	bs, err := backupstorage.GetBackupStorage()
+	require.NoError(t, err)
+	defer bs.Close()
+	bv.BackupName = "2021-01-02.000000.cell1-0000000100"
+	bv.VerifiedTime = "2021-01-03T00:00:00Z"
+	require.NoError(t, mysqlctl.WriteBackupVerification(ctx, bs, "ks", "-80", previous))
+	require.NoError(t, mysqlctl.WriteBackupVerification(ctx, bs, "ks", "-80", bv))
+	bv.Success = true
+	bv.Errors = nil
+	require.NoError(t, mysqlctl.WriteBackupVerification(ctx, bs, "ks", "-80", bv))
+
+	bvs, err := mysqlctl.ListBackupVerifications(ctx, bs, "ks", "-80", logger)
+	require.NoError(t, err)
+	assert.Equal(t, []*mysqlctl.BackupVerification{previous, bv}, bvs)
+
+	backups, err := bs.ListBackups(ctx, mysqlctl.GetBackupDir("ks", "-80"))
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+}
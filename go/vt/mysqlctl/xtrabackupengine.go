@@ -31,9 +31,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/klauspost/pgzip"
-	"github.com/planetscale/pargzip"
-
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
@@ -99,6 +96,16 @@ type xtraBackupManifest struct {
 	// false for backups that were created before the field existed, and those
 	// backups all had compression enabled.
 	SkipCompress bool
+
+	// CompressionEngine and ExternalDecompressor tell how the backup files
+	// were compressed. See the fields of the same name in builtinBackupManifest.
+	CompressionEngine    string `json:",omitempty"`
+	ExternalDecompressor string `json:",omitempty"`
+
+	// StripeHashes are the hashes of the stripe files, as stored in the
+	// BackupStorage. They are checked on restore. Backups taken before the
+	// field existed are not checked.
+	StripeHashes []string `json:",omitempty"`
 }
 
 func (be *XtrabackupEngine) backupFileName() string {
@@ -108,7 +115,7 @@ func (be *XtrabackupEngine) backupFileName() string {
 		fileName += *xtrabackupStreamMode
 	}
 	if *backupStorageCompress {
-		fileName += ".gz"
+		fileName += compressionExtension(*compressionEngineName)
 	}
 	return fileName
 }
@@ -130,6 +137,9 @@ func (be *XtrabackupEngine) ExecuteBackup(ctx context.Context, params BackupPara
 	if *xtrabackupUser == "" {
 		return false, vterrors.New(vtrpc.Code_INVALID_ARGUMENT, "xtrabackupUser must be specified.")
 	}
+	if params.ParentBackup != nil {
+		params.Logger.Warningf("xtrabackup engine doesn't support incremental backups, taking a full backup")
+	}
 	// use a mysql connection to detect flavor at runtime
 	conn, err := params.Mysqld.GetDbaConnection(ctx)
 	if conn != nil && err == nil {
@@ -149,16 +159,30 @@ func (be *XtrabackupEngine) ExecuteBackup(ctx context.Context, params BackupPara
 	backupFileName := be.backupFileName()
 	numStripes := int(*xtrabackupStripes)
 
+	// Generate the data key, if backups are encrypted.
+	encryption, dataKey, err := newBackupEncryption(ctx)
+	if err != nil {
+		return false, err
+	}
+
 	// Perform backups in a separate function, so deferred calls to Close() are
 	// all done before we continue to write the MANIFEST. This ensures that we
 	// do not write the MANIFEST unless all files were closed successfully,
 	// maintaining the contract that a MANIFEST file should only exist if the
 	// backup was created successfully.
 	params.Logger.Infof("Starting backup with %v stripe(s)", numStripes)
-	replicationPosition, err := be.backupFiles(ctx, params, bh, backupFileName, numStripes, flavor)
+	hashers := make([]*hasher, numStripes)
+	for i := range hashers {
+		hashers[i] = newHasher()
+	}
+	replicationPosition, err := be.backupFiles(ctx, params, bh, backupFileName, numStripes, flavor, dataKey, hashers)
 	if err != nil {
 		return false, err
 	}
+	stripeHashes := make([]string, numStripes)
+	for i, hasher := range hashers {
+		stripeHashes[i] = hasher.HashString()
+	}
 
 	// open the MANIFEST
 	params.Logger.Infof("Writing backup MANIFEST")
@@ -176,6 +200,7 @@ func (be *XtrabackupEngine) ExecuteBackup(ctx context.Context, params BackupPara
 			Position:     replicationPosition,
 			BackupTime:   params.BackupTime.UTC().Format(time.RFC3339),
 			FinishedTime: time.Now().UTC().Format(time.RFC3339),
+			Encryption:   encryption,
 		},
 
 		// XtraBackup-specific fields
@@ -185,7 +210,9 @@ func (be *XtrabackupEngine) ExecuteBackup(ctx context.Context, params BackupPara
 		Params:          *xtrabackupBackupFlags,
 		NumStripes:      int32(numStripes),
 		StripeBlockSize: int32(*xtrabackupStripeBlockSize),
+		StripeHashes:    stripeHashes,
 	}
+	bm.CompressionEngine, bm.ExternalDecompressor = manifestCompression()
 
 	data, err := json.MarshalIndent(bm, "", "  ")
 	if err != nil {
@@ -199,7 +226,7 @@ func (be *XtrabackupEngine) ExecuteBackup(ctx context.Context, params BackupPara
 	return true, nil
 }
 
-func (be *XtrabackupEngine) backupFiles(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle, backupFileName string, numStripes int, flavor string) (replicationPosition mysql.Position, finalErr error) {
+func (be *XtrabackupEngine) backupFiles(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle, backupFileName string, numStripes int, flavor string, dataKey []byte, hashers []*hasher) (replicationPosition mysql.Position, finalErr error) {
 
 	backupProgram := path.Join(*xtrabackupEnginePath, xtrabackupBinaryName)
 	flagsToExec := []string{"--defaults-file=" + params.Cnf.path,
@@ -272,17 +299,29 @@ func (be *XtrabackupEngine) backupFiles(ctx context.Context, params BackupParams
 	destWriters := []io.Writer{}
 	destBuffers := []*bufio.Writer{}
 	destCompressors := []io.WriteCloser{}
-	for _, file := range destFiles {
-		buffer := bufio.NewWriterSize(file, writerBufferSize)
+	destEncryptors := []io.WriteCloser{}
+	for i, file := range destFiles {
+		// Hash the data as it's stored.
+		buffer := bufio.NewWriterSize(io.MultiWriter(file, hashers[i]), writerBufferSize)
 		destBuffers = append(destBuffers, buffer)
 		writer := io.Writer(buffer)
 
-		// Create the gzip compression pipe, if necessary.
+		// Create the encryption pipe, if necessary.
+		if dataKey != nil {
+			encryptor, err := newEncryptingWriter(writer, dataKey)
+			if err != nil {
+				return replicationPosition, vterrors.Wrap(err, "cannot create encryptor")
+			}
+			writer = encryptor
+			destEncryptors = append(destEncryptors, encryptor)
+		}
+
+		// Create the compression pipe, if necessary.
 		if *backupStorageCompress {
-			compressor := pargzip.NewWriter(writer)
-			compressor.ChunkSize = *backupCompressBlockSize
-			compressor.Parallel = *backupCompressBlocks
-			compressor.CompressionLevel = pargzip.BestSpeed
+			compressor, err := newCompressor(ctx, *compressionEngineName, writer, params.Logger)
+			if err != nil {
+				return replicationPosition, vterrors.Wrap(err, "cannot create compressor")
+			}
 			writer = compressor
 			destCompressors = append(destCompressors, compressor)
 		}
@@ -343,7 +382,14 @@ func (be *XtrabackupEngine) backupFiles(ctx context.Context, params BackupParams
 	// Close compressor to flush it. After that all data is sent to the buffer.
 	for _, compressor := range destCompressors {
 		if err := compressor.Close(); err != nil {
-			return replicationPosition, vterrors.Wrap(err, "cannot close gzip compressor")
+			return replicationPosition, vterrors.Wrap(err, "cannot close compressor")
+		}
+	}
+
+	// Close encryptor to write the last chunk.
+	for _, encryptor := range destEncryptors {
+		if err := encryptor.Close(); err != nil {
+			return replicationPosition, vterrors.Wrap(err, "cannot close encryptor")
 		}
 	}
 
@@ -386,7 +432,7 @@ func (be *XtrabackupEngine) ExecuteRestore(ctx context.Context, params RestorePa
 		return nil, err
 	}
 
-	if err := prepareToRestore(ctx, params.Cnf, params.Mysqld, params.Logger); err != nil {
+	if err := prepareToRestore(ctx, params.Cnf, params.Mysqld, params.Logger, false); err != nil {
 		return nil, err
 	}
 
@@ -521,16 +567,40 @@ func (be *XtrabackupEngine) extractFiles(ctx context.Context, logger logutil.Log
 		}
 	}()
 
+	dataKey, err := bm.Encryption.dataKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	checkHashes := len(bm.StripeHashes) == len(srcFiles)
+	srcHashers := []*hasher{}
+	srcTees := []io.Reader{}
 	srcReaders := []io.Reader{}
 	srcDecompressors := []io.ReadCloser{}
 	for _, file := range srcFiles {
 		reader := io.Reader(file)
 
+		// Hash the data as it's read.
+		if checkHashes {
+			hasher := newHasher()
+			reader = io.TeeReader(reader, hasher)
+			srcHashers = append(srcHashers, hasher)
+			srcTees = append(srcTees, reader)
+		}
+
+		// Create the decrypter if needed.
+		if dataKey != nil {
+			reader, err = newDecryptingReader(reader, dataKey)
+			if err != nil {
+				return err
+			}
+		}
+
 		// Create the decompressor if needed.
 		if compressed {
-			decompressor, err := pgzip.NewReader(reader)
+			decompressor, err := newDecompressor(ctx, bm.CompressionEngine, bm.ExternalDecompressor, reader, logger)
 			if err != nil {
-				return vterrors.Wrap(err, "can't create gzip decompressor")
+				return err
 			}
 			srcDecompressors = append(srcDecompressors, decompressor)
 			reader = decompressor
@@ -541,7 +611,7 @@ func (be *XtrabackupEngine) extractFiles(ctx context.Context, logger logutil.Log
 	defer func() {
 		for _, decompressor := range srcDecompressors {
 			if cerr := decompressor.Close(); cerr != nil {
-				logger.Errorf("failed to close gzip decompressor: %v", cerr)
+				logger.Errorf("failed to close decompressor: %v", cerr)
 			}
 		}
 	}()
@@ -616,6 +686,18 @@ func (be *XtrabackupEngine) extractFiles(ctx context.Context, logger logutil.Log
 	default:
 		return vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "%v is not a valid value for xtrabackup_stream_mode, supported modes are tar and xbstream", streamMode)
 	}
+
+	// Check the hashes of the stripes. Read what the extraction left
+	// unread, like padding at the end of the compressed data, so the
+	// whole stripes are hashed.
+	for i, tee := range srcTees {
+		if _, err := io.Copy(io.Discard, tee); err != nil {
+			return vterrors.Wrapf(err, "cannot read stripe %v", i)
+		}
+		if hash := srcHashers[i].HashString(); hash != bm.StripeHashes[i] {
+			return vterrors.Errorf(vtrpc.Code_DATA_LOSS, "hash mismatch for stripe %v of %v, got %v expected %v", i, baseFileName, hash, bm.StripeHashes[i])
+		}
+	}
 	return nil
 }
 
@@ -736,8 +818,8 @@ func copyToStripes(writers []io.Writer, reader io.Reader, blockSize int64) (writ
 	}
 
 	// Read blocks from source and round-robin them to destination writers.
-	// Since we put a buffer in front of the destination file, and pargzip has its
-	// own buffer as well, we are writing into a buffer either way (whether a
+	// Since we put a buffer in front of the destination file, and compressors have
+	// their own buffer as well, we are writing into a buffer either way (whether a
 	// compressor is in the chain or not). That means these writes should not
 	// block often, so we shouldn't need separate goroutines here.
 	destIndex := 0
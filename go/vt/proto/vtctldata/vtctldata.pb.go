@@ -7308,6 +7308,147 @@ func (x *ValidateShardResponse) GetResults() []string {
 	return nil
 }
 
+type ValidateVSchemaChangeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Keyspace string `protobuf:"bytes,1,opt,name=keyspace,proto3" json:"keyspace,omitempty"`
+	// VSchema is the proposed vschema of the keyspace. It is mutually
+	// exclusive with Sql.
+	VSchema *vschema.Keyspace `protobuf:"bytes,2,opt,name=v_schema,json=vSchema,proto3" json:"v_schema,omitempty"`
+	// Sql is a vschema ddl statement applied to the current vschema of the
+	// keyspace to get the proposed one.
+	Sql string `protobuf:"bytes,3,opt,name=sql,proto3" json:"sql,omitempty"`
+	// Queries are planned with the current and the proposed vschemas.
+	Queries []*ValidateVSchemaChangeRequest_Query `protobuf:"bytes,4,rep,name=queries,proto3" json:"queries,omitempty"`
+}
+
+func (x *ValidateVSchemaChangeRequest) Reset() {
+	*x = ValidateVSchemaChangeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vtctldata_proto_msgTypes[131]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateVSchemaChangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateVSchemaChangeRequest) ProtoMessage() {}
+
+func (x *ValidateVSchemaChangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vtctldata_proto_msgTypes[131]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateVSchemaChangeRequest.ProtoReflect.Descriptor instead.
+func (*ValidateVSchemaChangeRequest) Descriptor() ([]byte, []int) {
+	return file_vtctldata_proto_rawDescGZIP(), []int{131}
+}
+
+func (x *ValidateVSchemaChangeRequest) GetKeyspace() string {
+	if x != nil {
+		return x.Keyspace
+	}
+	return ""
+}
+
+func (x *ValidateVSchemaChangeRequest) GetVSchema() *vschema.Keyspace {
+	if x != nil {
+		return x.VSchema
+	}
+	return nil
+}
+
+func (x *ValidateVSchemaChangeRequest) GetSql() string {
+	if x != nil {
+		return x.Sql
+	}
+	return ""
+}
+
+func (x *ValidateVSchemaChangeRequest) GetQueries() []*ValidateVSchemaChangeRequest_Query {
+	if x != nil {
+		return x.Queries
+	}
+	return nil
+}
+
+type ValidateVSchemaChangeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Errors are the problems found in the proposed vschema.
+	Errors []string `protobuf:"bytes,1,rep,name=errors,proto3" json:"errors,omitempty"`
+	// Replayed is the number of queries that were planned.
+	Replayed    int32                                       `protobuf:"varint,2,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	PlanChanges []*ValidateVSchemaChangeResponse_PlanChange `protobuf:"bytes,3,rep,name=plan_changes,json=planChanges,proto3" json:"plan_changes,omitempty"`
+}
+
+func (x *ValidateVSchemaChangeResponse) Reset() {
+	*x = ValidateVSchemaChangeResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vtctldata_proto_msgTypes[132]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateVSchemaChangeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateVSchemaChangeResponse) ProtoMessage() {}
+
+func (x *ValidateVSchemaChangeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vtctldata_proto_msgTypes[132]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateVSchemaChangeResponse.ProtoReflect.Descriptor instead.
+func (*ValidateVSchemaChangeResponse) Descriptor() ([]byte, []int) {
+	return file_vtctldata_proto_rawDescGZIP(), []int{132}
+}
+
+func (x *ValidateVSchemaChangeResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *ValidateVSchemaChangeResponse) GetReplayed() int32 {
+	if x != nil {
+		return x.Replayed
+	}
+	return 0
+}
+
+func (x *ValidateVSchemaChangeResponse) GetPlanChanges() []*ValidateVSchemaChangeResponse_PlanChange {
+	if x != nil {
+		return x.PlanChanges
+	}
+	return nil
+}
+
 type Workflow_ReplicationLocation struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -7320,7 +7461,7 @@ type Workflow_ReplicationLocation struct {
 func (x *Workflow_ReplicationLocation) Reset() {
 	*x = Workflow_ReplicationLocation{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vtctldata_proto_msgTypes[132]
+		mi := &file_vtctldata_proto_msgTypes[134]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -7333,7 +7474,7 @@ func (x *Workflow_ReplicationLocation) String() string {
 func (*Workflow_ReplicationLocation) ProtoMessage() {}
 
 func (x *Workflow_ReplicationLocation) ProtoReflect() protoreflect.Message {
-	mi := &file_vtctldata_proto_msgTypes[132]
+	mi := &file_vtctldata_proto_msgTypes[134]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -7376,7 +7517,7 @@ type Workflow_ShardStream struct {
 func (x *Workflow_ShardStream) Reset() {
 	*x = Workflow_ShardStream{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vtctldata_proto_msgTypes[133]
+		mi := &file_vtctldata_proto_msgTypes[135]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -7389,7 +7530,7 @@ func (x *Workflow_ShardStream) String() string {
 func (*Workflow_ShardStream) ProtoMessage() {}
 
 func (x *Workflow_ShardStream) ProtoReflect() protoreflect.Message {
-	mi := &file_vtctldata_proto_msgTypes[133]
+	mi := &file_vtctldata_proto_msgTypes[135]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -7459,7 +7600,7 @@ type Workflow_Stream struct {
 func (x *Workflow_Stream) Reset() {
 	*x = Workflow_Stream{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vtctldata_proto_msgTypes[134]
+		mi := &file_vtctldata_proto_msgTypes[136]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -7472,7 +7613,7 @@ func (x *Workflow_Stream) String() string {
 func (*Workflow_Stream) ProtoMessage() {}
 
 func (x *Workflow_Stream) ProtoReflect() protoreflect.Message {
-	mi := &file_vtctldata_proto_msgTypes[134]
+	mi := &file_vtctldata_proto_msgTypes[136]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -7605,7 +7746,7 @@ type Workflow_Stream_CopyState struct {
 func (x *Workflow_Stream_CopyState) Reset() {
 	*x = Workflow_Stream_CopyState{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vtctldata_proto_msgTypes[135]
+		mi := &file_vtctldata_proto_msgTypes[137]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -7618,7 +7759,7 @@ func (x *Workflow_Stream_CopyState) String() string {
 func (*Workflow_Stream_CopyState) ProtoMessage() {}
 
 func (x *Workflow_Stream_CopyState) ProtoReflect() protoreflect.Message {
-	mi := &file_vtctldata_proto_msgTypes[135]
+	mi := &file_vtctldata_proto_msgTypes[137]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -7666,7 +7807,7 @@ type Workflow_Stream_Log struct {
 func (x *Workflow_Stream_Log) Reset() {
 	*x = Workflow_Stream_Log{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vtctldata_proto_msgTypes[136]
+		mi := &file_vtctldata_proto_msgTypes[138]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -7679,7 +7820,7 @@ func (x *Workflow_Stream_Log) String() string {
 func (*Workflow_Stream_Log) ProtoMessage() {}
 
 func (x *Workflow_Stream_Log) ProtoReflect() protoreflect.Message {
-	mi := &file_vtctldata_proto_msgTypes[136]
+	mi := &file_vtctldata_proto_msgTypes[138]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -7762,7 +7903,7 @@ type GetSrvKeyspaceNamesResponse_NameList struct {
 func (x *GetSrvKeyspaceNamesResponse_NameList) Reset() {
 	*x = GetSrvKeyspaceNamesResponse_NameList{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vtctldata_proto_msgTypes[140]
+		mi := &file_vtctldata_proto_msgTypes[142]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -7775,7 +7916,7 @@ func (x *GetSrvKeyspaceNamesResponse_NameList) String() string {
 func (*GetSrvKeyspaceNamesResponse_NameList) ProtoMessage() {}
 
 func (x *GetSrvKeyspaceNamesResponse_NameList) ProtoReflect() protoreflect.Message {
-	mi := &file_vtctldata_proto_msgTypes[140]
+	mi := &file_vtctldata_proto_msgTypes[142]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -7798,6 +7939,144 @@ func (x *GetSrvKeyspaceNamesResponse_NameList) GetNames() []string {
 	return nil
 }
 
+type ValidateVSchemaChangeRequest_Query struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sql string `protobuf:"bytes,1,opt,name=sql,proto3" json:"sql,omitempty"`
+	// Keyspace is the target the query was sent to, if the session had one.
+	Keyspace string `protobuf:"bytes,2,opt,name=keyspace,proto3" json:"keyspace,omitempty"`
+}
+
+func (x *ValidateVSchemaChangeRequest_Query) Reset() {
+	*x = ValidateVSchemaChangeRequest_Query{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vtctldata_proto_msgTypes[149]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateVSchemaChangeRequest_Query) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateVSchemaChangeRequest_Query) ProtoMessage() {}
+
+func (x *ValidateVSchemaChangeRequest_Query) ProtoReflect() protoreflect.Message {
+	mi := &file_vtctldata_proto_msgTypes[149]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateVSchemaChangeRequest_Query.ProtoReflect.Descriptor instead.
+func (*ValidateVSchemaChangeRequest_Query) Descriptor() ([]byte, []int) {
+	return file_vtctldata_proto_rawDescGZIP(), []int{131, 0}
+}
+
+func (x *ValidateVSchemaChangeRequest_Query) GetSql() string {
+	if x != nil {
+		return x.Sql
+	}
+	return ""
+}
+
+func (x *ValidateVSchemaChangeRequest_Query) GetKeyspace() string {
+	if x != nil {
+		return x.Keyspace
+	}
+	return ""
+}
+
+type ValidateVSchemaChangeResponse_PlanChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Query    string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Keyspace string `protobuf:"bytes,2,opt,name=keyspace,proto3" json:"keyspace,omitempty"`
+	// Before and After list the routes of the plans, or the planning
+	// error, with the current and the proposed vschema.
+	Before string `protobuf:"bytes,3,opt,name=before,proto3" json:"before,omitempty"`
+	After  string `protobuf:"bytes,4,opt,name=after,proto3" json:"after,omitempty"`
+	// Failing is true if the query can't be planned anymore.
+	Failing bool `protobuf:"varint,5,opt,name=failing,proto3" json:"failing,omitempty"`
+}
+
+func (x *ValidateVSchemaChangeResponse_PlanChange) Reset() {
+	*x = ValidateVSchemaChangeResponse_PlanChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vtctldata_proto_msgTypes[150]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateVSchemaChangeResponse_PlanChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateVSchemaChangeResponse_PlanChange) ProtoMessage() {}
+
+func (x *ValidateVSchemaChangeResponse_PlanChange) ProtoReflect() protoreflect.Message {
+	mi := &file_vtctldata_proto_msgTypes[150]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateVSchemaChangeResponse_PlanChange.ProtoReflect.Descriptor instead.
+func (*ValidateVSchemaChangeResponse_PlanChange) Descriptor() ([]byte, []int) {
+	return file_vtctldata_proto_rawDescGZIP(), []int{132, 0}
+}
+
+func (x *ValidateVSchemaChangeResponse_PlanChange) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *ValidateVSchemaChangeResponse_PlanChange) GetKeyspace() string {
+	if x != nil {
+		return x.Keyspace
+	}
+	return ""
+}
+
+func (x *ValidateVSchemaChangeResponse_PlanChange) GetBefore() string {
+	if x != nil {
+		return x.Before
+	}
+	return ""
+}
+
+func (x *ValidateVSchemaChangeResponse_PlanChange) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+func (x *ValidateVSchemaChangeResponse_PlanChange) GetFailing() bool {
+	if x != nil {
+		return x.Failing
+	}
+	return false
+}
+
 var File_vtctldata_proto protoreflect.FileDescriptor
 
 var file_vtctldata_proto_rawDesc = []byte{
@@ -8766,15 +9045,50 @@ var file_vtctldata_proto_rawDesc = []byte{
 	0x69, 0x6e, 0x67, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x74, 0x73, 0x22, 0x31, 0x0a, 0x15, 0x56, 0x61,
 	0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x53, 0x68, 0x61, 0x72, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f,
 	0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01,
-	0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x2a, 0x4a, 0x0a,
-	0x15, 0x4d, 0x61, 0x74, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x49, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x0a, 0x0a, 0x06, 0x43, 0x55, 0x53, 0x54, 0x4f, 0x4d,
-	0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x4d, 0x4f, 0x56, 0x45, 0x54, 0x41, 0x42, 0x4c, 0x45, 0x53,
-	0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x4c, 0x4f, 0x4f, 0x4b,
-	0x55, 0x50, 0x49, 0x4e, 0x44, 0x45, 0x58, 0x10, 0x02, 0x42, 0x28, 0x5a, 0x26, 0x76, 0x69, 0x74,
-	0x65, 0x73, 0x73, 0x2e, 0x69, 0x6f, 0x2f, 0x76, 0x69, 0x74, 0x65, 0x73, 0x73, 0x2f, 0x67, 0x6f,
-	0x2f, 0x76, 0x74, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x74, 0x63, 0x74, 0x6c, 0x64,
-	0x61, 0x74, 0x61, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x22, 0xfa, 0x01,
+	0x0a, 0x1c, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x56, 0x53, 0x63, 0x68, 0x65, 0x6d,
+	0x61, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a,
+	0x0a, 0x08, 0x6b, 0x65, 0x79, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x6b, 0x65, 0x79, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x2c, 0x0a, 0x08, 0x76, 0x5f,
+	0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x76,
+	0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x2e, 0x4b, 0x65, 0x79, 0x73, 0x70, 0x61, 0x63, 0x65, 0x52,
+	0x07, 0x76, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x71, 0x6c, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x71, 0x6c, 0x12, 0x47, 0x0a, 0x07, 0x71, 0x75,
+	0x65, 0x72, 0x69, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x76, 0x74,
+	0x63, 0x74, 0x6c, 0x64, 0x61, 0x74, 0x61, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65,
+	0x56, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52, 0x07, 0x71, 0x75, 0x65, 0x72,
+	0x69, 0x65, 0x73, 0x1a, 0x35, 0x0a, 0x05, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x73, 0x71, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x71, 0x6c, 0x12, 0x1a,
+	0x0a, 0x08, 0x6b, 0x65, 0x79, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x6b, 0x65, 0x79, 0x73, 0x70, 0x61, 0x63, 0x65, 0x22, 0xb4, 0x02, 0x0a, 0x1d, 0x56,
+	0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x56, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x64,
+	0x12, 0x56, 0x0a, 0x0c, 0x70, 0x6c, 0x61, 0x6e, 0x5f, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x33, 0x2e, 0x76, 0x74, 0x63, 0x74, 0x6c, 0x64, 0x61,
+	0x74, 0x61, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x56, 0x53, 0x63, 0x68, 0x65,
+	0x6d, 0x61, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x2e, 0x50, 0x6c, 0x61, 0x6e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x0b, 0x70, 0x6c, 0x61,
+	0x6e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x1a, 0x86, 0x01, 0x0a, 0x0a, 0x50, 0x6c, 0x61,
+	0x6e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x1a, 0x0a,
+	0x08, 0x6b, 0x65, 0x79, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x6b, 0x65, 0x79, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x65, 0x66,
+	0x6f, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x62, 0x65, 0x66, 0x6f, 0x72,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x66, 0x61, 0x69, 0x6c, 0x69,
+	0x6e, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x66, 0x61, 0x69, 0x6c, 0x69, 0x6e,
+	0x67, 0x2a, 0x4a, 0x0a, 0x15, 0x4d, 0x61, 0x74, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x69, 0x7a, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x0a, 0x0a, 0x06, 0x43, 0x55,
+	0x53, 0x54, 0x4f, 0x4d, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x4d, 0x4f, 0x56, 0x45, 0x54, 0x41,
+	0x42, 0x4c, 0x45, 0x53, 0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45,
+	0x4c, 0x4f, 0x4f, 0x4b, 0x55, 0x50, 0x49, 0x4e, 0x44, 0x45, 0x58, 0x10, 0x02, 0x42, 0x28, 0x5a,
+	0x26, 0x76, 0x69, 0x74, 0x65, 0x73, 0x73, 0x2e, 0x69, 0x6f, 0x2f, 0x76, 0x69, 0x74, 0x65, 0x73,
+	0x73, 0x2f, 0x67, 0x6f, 0x2f, 0x76, 0x74, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x74,
+	0x63, 0x74, 0x6c, 0x64, 0x61, 0x74, 0x61, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -8790,7 +9104,7 @@ func file_vtctldata_proto_rawDescGZIP() []byte {
 }
 
 var file_vtctldata_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_vtctldata_proto_msgTypes = make([]protoimpl.MessageInfo, 147)
+var file_vtctldata_proto_msgTypes = make([]protoimpl.MessageInfo, 151)
 var file_vtctldata_proto_goTypes = []interface{}{
 	(MaterializationIntent)(0),                   // 0: vtctldata.MaterializationIntent
 	(*ExecuteVtctlCommandRequest)(nil),           // 1: vtctldata.ExecuteVtctlCommandRequest
@@ -8924,165 +9238,172 @@ var file_vtctldata_proto_goTypes = []interface{}{
 	(*ValidateKeyspaceResponse)(nil),             // 129: vtctldata.ValidateKeyspaceResponse
 	(*ValidateShardRequest)(nil),                 // 130: vtctldata.ValidateShardRequest
 	(*ValidateShardResponse)(nil),                // 131: vtctldata.ValidateShardResponse
-	nil,                                          // 132: vtctldata.Workflow.ShardStreamsEntry
-	(*Workflow_ReplicationLocation)(nil),         // 133: vtctldata.Workflow.ReplicationLocation
-	(*Workflow_ShardStream)(nil),                 // 134: vtctldata.Workflow.ShardStream
-	(*Workflow_Stream)(nil),                      // 135: vtctldata.Workflow.Stream
-	(*Workflow_Stream_CopyState)(nil),            // 136: vtctldata.Workflow.Stream.CopyState
-	(*Workflow_Stream_Log)(nil),                  // 137: vtctldata.Workflow.Stream.Log
-	nil,                                          // 138: vtctldata.FindAllShardsInKeyspaceResponse.ShardsEntry
-	nil,                                          // 139: vtctldata.GetCellsAliasesResponse.AliasesEntry
-	nil,                                          // 140: vtctldata.GetSrvKeyspaceNamesResponse.NamesEntry
-	(*GetSrvKeyspaceNamesResponse_NameList)(nil), // 141: vtctldata.GetSrvKeyspaceNamesResponse.NameList
-	nil,                                  // 142: vtctldata.GetSrvKeyspacesResponse.SrvKeyspacesEntry
-	nil,                                  // 143: vtctldata.GetSrvVSchemasResponse.SrvVSchemasEntry
-	nil,                                  // 144: vtctldata.ShardReplicationPositionsResponse.ReplicationStatusesEntry
-	nil,                                  // 145: vtctldata.ShardReplicationPositionsResponse.TabletMapEntry
-	nil,                                  // 146: vtctldata.ValidateResponse.ResultsByKeyspaceEntry
-	nil,                                  // 147: vtctldata.ValidateKeyspaceResponse.ResultsByShardEntry
-	(*logutil.Event)(nil),                // 148: logutil.Event
-	(*topodata.Keyspace)(nil),            // 149: topodata.Keyspace
-	(*topodata.Shard)(nil),               // 150: topodata.Shard
-	(*topodata.CellInfo)(nil),            // 151: topodata.CellInfo
-	(*vschema.RoutingRules)(nil),         // 152: vschema.RoutingRules
-	(*vschema.Keyspace)(nil),             // 153: vschema.Keyspace
-	(*topodata.TabletAlias)(nil),         // 154: topodata.TabletAlias
-	(topodata.TabletType)(0),             // 155: topodata.TabletType
-	(*topodata.Tablet)(nil),              // 156: topodata.Tablet
-	(topodata.KeyspaceIdType)(0),         // 157: topodata.KeyspaceIdType
-	(*topodata.Keyspace_ServedFrom)(nil), // 158: topodata.Keyspace.ServedFrom
-	(topodata.KeyspaceType)(0),           // 159: topodata.KeyspaceType
-	(*vttime.Time)(nil),                  // 160: vttime.Time
-	(*vttime.Duration)(nil),              // 161: vttime.Duration
-	(*tabletmanagerdata.ExecuteHookRequest)(nil),  // 162: tabletmanagerdata.ExecuteHookRequest
-	(*tabletmanagerdata.ExecuteHookResponse)(nil), // 163: tabletmanagerdata.ExecuteHookResponse
-	(*mysqlctl.BackupInfo)(nil),                   // 164: mysqlctl.BackupInfo
-	(*tabletmanagerdata.SchemaDefinition)(nil),    // 165: tabletmanagerdata.SchemaDefinition
-	(*vschema.SrvVSchema)(nil),                    // 166: vschema.SrvVSchema
-	(*topodata.CellsAlias)(nil),                   // 167: topodata.CellsAlias
-	(*topodata.Shard_TabletControl)(nil),          // 168: topodata.Shard.TabletControl
-	(*binlogdata.BinlogSource)(nil),               // 169: binlogdata.BinlogSource
-	(*topodata.SrvKeyspace)(nil),                  // 170: topodata.SrvKeyspace
-	(*replicationdata.Status)(nil),                // 171: replicationdata.Status
+	(*ValidateVSchemaChangeRequest)(nil),         // 132: vtctldata.ValidateVSchemaChangeRequest
+	(*ValidateVSchemaChangeResponse)(nil),        // 133: vtctldata.ValidateVSchemaChangeResponse
+	nil,                                          // 134: vtctldata.Workflow.ShardStreamsEntry
+	(*Workflow_ReplicationLocation)(nil),         // 135: vtctldata.Workflow.ReplicationLocation
+	(*Workflow_ShardStream)(nil),                 // 136: vtctldata.Workflow.ShardStream
+	(*Workflow_Stream)(nil),                      // 137: vtctldata.Workflow.Stream
+	(*Workflow_Stream_CopyState)(nil),            // 138: vtctldata.Workflow.Stream.CopyState
+	(*Workflow_Stream_Log)(nil),                  // 139: vtctldata.Workflow.Stream.Log
+	nil,                                          // 140: vtctldata.FindAllShardsInKeyspaceResponse.ShardsEntry
+	nil,                                          // 141: vtctldata.GetCellsAliasesResponse.AliasesEntry
+	nil,                                          // 142: vtctldata.GetSrvKeyspaceNamesResponse.NamesEntry
+	(*GetSrvKeyspaceNamesResponse_NameList)(nil), // 143: vtctldata.GetSrvKeyspaceNamesResponse.NameList
+	nil, // 144: vtctldata.GetSrvKeyspacesResponse.SrvKeyspacesEntry
+	nil, // 145: vtctldata.GetSrvVSchemasResponse.SrvVSchemasEntry
+	nil, // 146: vtctldata.ShardReplicationPositionsResponse.ReplicationStatusesEntry
+	nil, // 147: vtctldata.ShardReplicationPositionsResponse.TabletMapEntry
+	nil, // 148: vtctldata.ValidateResponse.ResultsByKeyspaceEntry
+	nil, // 149: vtctldata.ValidateKeyspaceResponse.ResultsByShardEntry
+	(*ValidateVSchemaChangeRequest_Query)(nil),       // 150: vtctldata.ValidateVSchemaChangeRequest.Query
+	(*ValidateVSchemaChangeResponse_PlanChange)(nil), // 151: vtctldata.ValidateVSchemaChangeResponse.PlanChange
+	(*logutil.Event)(nil),                            // 152: logutil.Event
+	(*topodata.Keyspace)(nil),                        // 153: topodata.Keyspace
+	(*topodata.Shard)(nil),                           // 154: topodata.Shard
+	(*topodata.CellInfo)(nil),                        // 155: topodata.CellInfo
+	(*vschema.RoutingRules)(nil),                     // 156: vschema.RoutingRules
+	(*vschema.Keyspace)(nil),                         // 157: vschema.Keyspace
+	(*topodata.TabletAlias)(nil),                     // 158: topodata.TabletAlias
+	(topodata.TabletType)(0),                         // 159: topodata.TabletType
+	(*topodata.Tablet)(nil),                          // 160: topodata.Tablet
+	(topodata.KeyspaceIdType)(0),                     // 161: topodata.KeyspaceIdType
+	(*topodata.Keyspace_ServedFrom)(nil),             // 162: topodata.Keyspace.ServedFrom
+	(topodata.KeyspaceType)(0),                       // 163: topodata.KeyspaceType
+	(*vttime.Time)(nil),                              // 164: vttime.Time
+	(*vttime.Duration)(nil),                          // 165: vttime.Duration
+	(*tabletmanagerdata.ExecuteHookRequest)(nil),     // 166: tabletmanagerdata.ExecuteHookRequest
+	(*tabletmanagerdata.ExecuteHookResponse)(nil),    // 167: tabletmanagerdata.ExecuteHookResponse
+	(*mysqlctl.BackupInfo)(nil),                      // 168: mysqlctl.BackupInfo
+	(*tabletmanagerdata.SchemaDefinition)(nil),       // 169: tabletmanagerdata.SchemaDefinition
+	(*vschema.SrvVSchema)(nil),                       // 170: vschema.SrvVSchema
+	(*topodata.CellsAlias)(nil),                      // 171: topodata.CellsAlias
+	(*topodata.Shard_TabletControl)(nil),             // 172: topodata.Shard.TabletControl
+	(*binlogdata.BinlogSource)(nil),                  // 173: binlogdata.BinlogSource
+	(*topodata.SrvKeyspace)(nil),                     // 174: topodata.SrvKeyspace
+	(*replicationdata.Status)(nil),                   // 175: replicationdata.Status
 }
 var file_vtctldata_proto_depIdxs = []int32{
-	148, // 0: vtctldata.ExecuteVtctlCommandResponse.event:type_name -> logutil.Event
+	152, // 0: vtctldata.ExecuteVtctlCommandResponse.event:type_name -> logutil.Event
 	3,   // 1: vtctldata.MaterializeSettings.table_settings:type_name -> vtctldata.TableMaterializeSettings
 	0,   // 2: vtctldata.MaterializeSettings.materialization_intent:type_name -> vtctldata.MaterializationIntent
-	149, // 3: vtctldata.Keyspace.keyspace:type_name -> topodata.Keyspace
-	150, // 4: vtctldata.Shard.shard:type_name -> topodata.Shard
-	133, // 5: vtctldata.Workflow.source:type_name -> vtctldata.Workflow.ReplicationLocation
-	133, // 6: vtctldata.Workflow.target:type_name -> vtctldata.Workflow.ReplicationLocation
-	132, // 7: vtctldata.Workflow.shard_streams:type_name -> vtctldata.Workflow.ShardStreamsEntry
-	151, // 8: vtctldata.AddCellInfoRequest.cell_info:type_name -> topodata.CellInfo
-	152, // 9: vtctldata.ApplyRoutingRulesRequest.routing_rules:type_name -> vschema.RoutingRules
-	153, // 10: vtctldata.ApplyVSchemaRequest.v_schema:type_name -> vschema.Keyspace
-	153, // 11: vtctldata.ApplyVSchemaResponse.v_schema:type_name -> vschema.Keyspace
-	154, // 12: vtctldata.ChangeTabletTypeRequest.tablet_alias:type_name -> topodata.TabletAlias
-	155, // 13: vtctldata.ChangeTabletTypeRequest.db_type:type_name -> topodata.TabletType
-	156, // 14: vtctldata.ChangeTabletTypeResponse.before_tablet:type_name -> topodata.Tablet
-	156, // 15: vtctldata.ChangeTabletTypeResponse.after_tablet:type_name -> topodata.Tablet
-	157, // 16: vtctldata.CreateKeyspaceRequest.sharding_column_type:type_name -> topodata.KeyspaceIdType
-	158, // 17: vtctldata.CreateKeyspaceRequest.served_froms:type_name -> topodata.Keyspace.ServedFrom
-	159, // 18: vtctldata.CreateKeyspaceRequest.type:type_name -> topodata.KeyspaceType
-	160, // 19: vtctldata.CreateKeyspaceRequest.snapshot_time:type_name -> vttime.Time
+	153, // 3: vtctldata.Keyspace.keyspace:type_name -> topodata.Keyspace
+	154, // 4: vtctldata.Shard.shard:type_name -> topodata.Shard
+	135, // 5: vtctldata.Workflow.source:type_name -> vtctldata.Workflow.ReplicationLocation
+	135, // 6: vtctldata.Workflow.target:type_name -> vtctldata.Workflow.ReplicationLocation
+	134, // 7: vtctldata.Workflow.shard_streams:type_name -> vtctldata.Workflow.ShardStreamsEntry
+	155, // 8: vtctldata.AddCellInfoRequest.cell_info:type_name -> topodata.CellInfo
+	156, // 9: vtctldata.ApplyRoutingRulesRequest.routing_rules:type_name -> vschema.RoutingRules
+	157, // 10: vtctldata.ApplyVSchemaRequest.v_schema:type_name -> vschema.Keyspace
+	157, // 11: vtctldata.ApplyVSchemaResponse.v_schema:type_name -> vschema.Keyspace
+	158, // 12: vtctldata.ChangeTabletTypeRequest.tablet_alias:type_name -> topodata.TabletAlias
+	159, // 13: vtctldata.ChangeTabletTypeRequest.db_type:type_name -> topodata.TabletType
+	160, // 14: vtctldata.ChangeTabletTypeResponse.before_tablet:type_name -> topodata.Tablet
+	160, // 15: vtctldata.ChangeTabletTypeResponse.after_tablet:type_name -> topodata.Tablet
+	161, // 16: vtctldata.CreateKeyspaceRequest.sharding_column_type:type_name -> topodata.KeyspaceIdType
+	162, // 17: vtctldata.CreateKeyspaceRequest.served_froms:type_name -> topodata.Keyspace.ServedFrom
+	163, // 18: vtctldata.CreateKeyspaceRequest.type:type_name -> topodata.KeyspaceType
+	164, // 19: vtctldata.CreateKeyspaceRequest.snapshot_time:type_name -> vttime.Time
 	5,   // 20: vtctldata.CreateKeyspaceResponse.keyspace:type_name -> vtctldata.Keyspace
 	5,   // 21: vtctldata.CreateShardResponse.keyspace:type_name -> vtctldata.Keyspace
 	6,   // 22: vtctldata.CreateShardResponse.shard:type_name -> vtctldata.Shard
 	6,   // 23: vtctldata.DeleteShardsRequest.shards:type_name -> vtctldata.Shard
-	154, // 24: vtctldata.DeleteTabletsRequest.tablet_aliases:type_name -> topodata.TabletAlias
-	154, // 25: vtctldata.EmergencyReparentShardRequest.new_primary:type_name -> topodata.TabletAlias
-	154, // 26: vtctldata.EmergencyReparentShardRequest.ignore_replicas:type_name -> topodata.TabletAlias
-	161, // 27: vtctldata.EmergencyReparentShardRequest.wait_replicas_timeout:type_name -> vttime.Duration
-	154, // 28: vtctldata.EmergencyReparentShardResponse.promoted_primary:type_name -> topodata.TabletAlias
-	148, // 29: vtctldata.EmergencyReparentShardResponse.events:type_name -> logutil.Event
-	154, // 30: vtctldata.ExecuteHookRequest.tablet_alias:type_name -> topodata.TabletAlias
-	162, // 31: vtctldata.ExecuteHookRequest.tablet_hook_request:type_name -> tabletmanagerdata.ExecuteHookRequest
-	163, // 32: vtctldata.ExecuteHookResponse.hook_result:type_name -> tabletmanagerdata.ExecuteHookResponse
-	138, // 33: vtctldata.FindAllShardsInKeyspaceResponse.shards:type_name -> vtctldata.FindAllShardsInKeyspaceResponse.ShardsEntry
-	164, // 34: vtctldata.GetBackupsResponse.backups:type_name -> mysqlctl.BackupInfo
-	151, // 35: vtctldata.GetCellInfoResponse.cell_info:type_name -> topodata.CellInfo
-	139, // 36: vtctldata.GetCellsAliasesResponse.aliases:type_name -> vtctldata.GetCellsAliasesResponse.AliasesEntry
+	158, // 24: vtctldata.DeleteTabletsRequest.tablet_aliases:type_name -> topodata.TabletAlias
+	158, // 25: vtctldata.EmergencyReparentShardRequest.new_primary:type_name -> topodata.TabletAlias
+	158, // 26: vtctldata.EmergencyReparentShardRequest.ignore_replicas:type_name -> topodata.TabletAlias
+	165, // 27: vtctldata.EmergencyReparentShardRequest.wait_replicas_timeout:type_name -> vttime.Duration
+	158, // 28: vtctldata.EmergencyReparentShardResponse.promoted_primary:type_name -> topodata.TabletAlias
+	152, // 29: vtctldata.EmergencyReparentShardResponse.events:type_name -> logutil.Event
+	158, // 30: vtctldata.ExecuteHookRequest.tablet_alias:type_name -> topodata.TabletAlias
+	166, // 31: vtctldata.ExecuteHookRequest.tablet_hook_request:type_name -> tabletmanagerdata.ExecuteHookRequest
+	167, // 32: vtctldata.ExecuteHookResponse.hook_result:type_name -> tabletmanagerdata.ExecuteHookResponse
+	140, // 33: vtctldata.FindAllShardsInKeyspaceResponse.shards:type_name -> vtctldata.FindAllShardsInKeyspaceResponse.ShardsEntry
+	168, // 34: vtctldata.GetBackupsResponse.backups:type_name -> mysqlctl.BackupInfo
+	155, // 35: vtctldata.GetCellInfoResponse.cell_info:type_name -> topodata.CellInfo
+	141, // 36: vtctldata.GetCellsAliasesResponse.aliases:type_name -> vtctldata.GetCellsAliasesResponse.AliasesEntry
 	5,   // 37: vtctldata.GetKeyspacesResponse.keyspaces:type_name -> vtctldata.Keyspace
 	5,   // 38: vtctldata.GetKeyspaceResponse.keyspace:type_name -> vtctldata.Keyspace
-	152, // 39: vtctldata.GetRoutingRulesResponse.routing_rules:type_name -> vschema.RoutingRules
-	154, // 40: vtctldata.GetSchemaRequest.tablet_alias:type_name -> topodata.TabletAlias
-	165, // 41: vtctldata.GetSchemaResponse.schema:type_name -> tabletmanagerdata.SchemaDefinition
+	156, // 39: vtctldata.GetRoutingRulesResponse.routing_rules:type_name -> vschema.RoutingRules
+	158, // 40: vtctldata.GetSchemaRequest.tablet_alias:type_name -> topodata.TabletAlias
+	169, // 41: vtctldata.GetSchemaResponse.schema:type_name -> tabletmanagerdata.SchemaDefinition
 	6,   // 42: vtctldata.GetShardResponse.shard:type_name -> vtctldata.Shard
-	140, // 43: vtctldata.GetSrvKeyspaceNamesResponse.names:type_name -> vtctldata.GetSrvKeyspaceNamesResponse.NamesEntry
-	142, // 44: vtctldata.GetSrvKeyspacesResponse.srv_keyspaces:type_name -> vtctldata.GetSrvKeyspacesResponse.SrvKeyspacesEntry
-	166, // 45: vtctldata.GetSrvVSchemaResponse.srv_v_schema:type_name -> vschema.SrvVSchema
-	143, // 46: vtctldata.GetSrvVSchemasResponse.srv_v_schemas:type_name -> vtctldata.GetSrvVSchemasResponse.SrvVSchemasEntry
-	154, // 47: vtctldata.GetTabletRequest.tablet_alias:type_name -> topodata.TabletAlias
-	156, // 48: vtctldata.GetTabletResponse.tablet:type_name -> topodata.Tablet
-	154, // 49: vtctldata.GetTabletsRequest.tablet_aliases:type_name -> topodata.TabletAlias
-	156, // 50: vtctldata.GetTabletsResponse.tablets:type_name -> topodata.Tablet
-	153, // 51: vtctldata.GetVSchemaResponse.v_schema:type_name -> vschema.Keyspace
+	142, // 43: vtctldata.GetSrvKeyspaceNamesResponse.names:type_name -> vtctldata.GetSrvKeyspaceNamesResponse.NamesEntry
+	144, // 44: vtctldata.GetSrvKeyspacesResponse.srv_keyspaces:type_name -> vtctldata.GetSrvKeyspacesResponse.SrvKeyspacesEntry
+	170, // 45: vtctldata.GetSrvVSchemaResponse.srv_v_schema:type_name -> vschema.SrvVSchema
+	145, // 46: vtctldata.GetSrvVSchemasResponse.srv_v_schemas:type_name -> vtctldata.GetSrvVSchemasResponse.SrvVSchemasEntry
+	158, // 47: vtctldata.GetTabletRequest.tablet_alias:type_name -> topodata.TabletAlias
+	160, // 48: vtctldata.GetTabletResponse.tablet:type_name -> topodata.Tablet
+	158, // 49: vtctldata.GetTabletsRequest.tablet_aliases:type_name -> topodata.TabletAlias
+	160, // 50: vtctldata.GetTabletsResponse.tablets:type_name -> topodata.Tablet
+	157, // 51: vtctldata.GetVSchemaResponse.v_schema:type_name -> vschema.Keyspace
 	7,   // 52: vtctldata.GetWorkflowsResponse.workflows:type_name -> vtctldata.Workflow
-	154, // 53: vtctldata.InitShardPrimaryRequest.primary_elect_tablet_alias:type_name -> topodata.TabletAlias
-	161, // 54: vtctldata.InitShardPrimaryRequest.wait_replicas_timeout:type_name -> vttime.Duration
-	148, // 55: vtctldata.InitShardPrimaryResponse.events:type_name -> logutil.Event
-	154, // 56: vtctldata.PingTabletRequest.tablet_alias:type_name -> topodata.TabletAlias
-	154, // 57: vtctldata.PlannedReparentShardRequest.new_primary:type_name -> topodata.TabletAlias
-	154, // 58: vtctldata.PlannedReparentShardRequest.avoid_primary:type_name -> topodata.TabletAlias
-	161, // 59: vtctldata.PlannedReparentShardRequest.wait_replicas_timeout:type_name -> vttime.Duration
-	154, // 60: vtctldata.PlannedReparentShardResponse.promoted_primary:type_name -> topodata.TabletAlias
-	148, // 61: vtctldata.PlannedReparentShardResponse.events:type_name -> logutil.Event
-	154, // 62: vtctldata.RefreshStateRequest.tablet_alias:type_name -> topodata.TabletAlias
-	154, // 63: vtctldata.ReloadSchemaRequest.tablet_alias:type_name -> topodata.TabletAlias
-	148, // 64: vtctldata.ReloadSchemaKeyspaceResponse.events:type_name -> logutil.Event
-	148, // 65: vtctldata.ReloadSchemaShardResponse.events:type_name -> logutil.Event
-	154, // 66: vtctldata.ReparentTabletRequest.tablet:type_name -> topodata.TabletAlias
-	154, // 67: vtctldata.ReparentTabletResponse.primary:type_name -> topodata.TabletAlias
-	154, // 68: vtctldata.RunHealthCheckRequest.tablet_alias:type_name -> topodata.TabletAlias
-	155, // 69: vtctldata.SetKeyspaceServedFromRequest.tablet_type:type_name -> topodata.TabletType
-	149, // 70: vtctldata.SetKeyspaceServedFromResponse.keyspace:type_name -> topodata.Keyspace
-	157, // 71: vtctldata.SetKeyspaceShardingInfoRequest.column_type:type_name -> topodata.KeyspaceIdType
-	149, // 72: vtctldata.SetKeyspaceShardingInfoResponse.keyspace:type_name -> topodata.Keyspace
-	150, // 73: vtctldata.SetShardIsPrimaryServingResponse.shard:type_name -> topodata.Shard
-	155, // 74: vtctldata.SetShardTabletControlRequest.tablet_type:type_name -> topodata.TabletType
-	150, // 75: vtctldata.SetShardTabletControlResponse.shard:type_name -> topodata.Shard
-	154, // 76: vtctldata.SetWritableRequest.tablet_alias:type_name -> topodata.TabletAlias
-	144, // 77: vtctldata.ShardReplicationPositionsResponse.replication_statuses:type_name -> vtctldata.ShardReplicationPositionsResponse.ReplicationStatusesEntry
-	145, // 78: vtctldata.ShardReplicationPositionsResponse.tablet_map:type_name -> vtctldata.ShardReplicationPositionsResponse.TabletMapEntry
-	154, // 79: vtctldata.SleepTabletRequest.tablet_alias:type_name -> topodata.TabletAlias
-	161, // 80: vtctldata.SleepTabletRequest.duration:type_name -> vttime.Duration
-	154, // 81: vtctldata.StartReplicationRequest.tablet_alias:type_name -> topodata.TabletAlias
-	154, // 82: vtctldata.StopReplicationRequest.tablet_alias:type_name -> topodata.TabletAlias
-	154, // 83: vtctldata.TabletExternallyReparentedRequest.tablet:type_name -> topodata.TabletAlias
-	154, // 84: vtctldata.TabletExternallyReparentedResponse.new_primary:type_name -> topodata.TabletAlias
-	154, // 85: vtctldata.TabletExternallyReparentedResponse.old_primary:type_name -> topodata.TabletAlias
-	151, // 86: vtctldata.UpdateCellInfoRequest.cell_info:type_name -> topodata.CellInfo
-	151, // 87: vtctldata.UpdateCellInfoResponse.cell_info:type_name -> topodata.CellInfo
-	167, // 88: vtctldata.UpdateCellsAliasRequest.cells_alias:type_name -> topodata.CellsAlias
-	167, // 89: vtctldata.UpdateCellsAliasResponse.cells_alias:type_name -> topodata.CellsAlias
-	146, // 90: vtctldata.ValidateResponse.results_by_keyspace:type_name -> vtctldata.ValidateResponse.ResultsByKeyspaceEntry
-	147, // 91: vtctldata.ValidateKeyspaceResponse.results_by_shard:type_name -> vtctldata.ValidateKeyspaceResponse.ResultsByShardEntry
-	134, // 92: vtctldata.Workflow.ShardStreamsEntry.value:type_name -> vtctldata.Workflow.ShardStream
-	135, // 93: vtctldata.Workflow.ShardStream.streams:type_name -> vtctldata.Workflow.Stream
-	168, // 94: vtctldata.Workflow.ShardStream.tablet_controls:type_name -> topodata.Shard.TabletControl
-	154, // 95: vtctldata.Workflow.Stream.tablet:type_name -> topodata.TabletAlias
-	169, // 96: vtctldata.Workflow.Stream.binlog_source:type_name -> binlogdata.BinlogSource
-	160, // 97: vtctldata.Workflow.Stream.transaction_timestamp:type_name -> vttime.Time
-	160, // 98: vtctldata.Workflow.Stream.time_updated:type_name -> vttime.Time
-	136, // 99: vtctldata.Workflow.Stream.copy_states:type_name -> vtctldata.Workflow.Stream.CopyState
-	137, // 100: vtctldata.Workflow.Stream.logs:type_name -> vtctldata.Workflow.Stream.Log
-	160, // 101: vtctldata.Workflow.Stream.Log.created_at:type_name -> vttime.Time
-	160, // 102: vtctldata.Workflow.Stream.Log.updated_at:type_name -> vttime.Time
-	6,   // 103: vtctldata.FindAllShardsInKeyspaceResponse.ShardsEntry.value:type_name -> vtctldata.Shard
-	167, // 104: vtctldata.GetCellsAliasesResponse.AliasesEntry.value:type_name -> topodata.CellsAlias
-	141, // 105: vtctldata.GetSrvKeyspaceNamesResponse.NamesEntry.value:type_name -> vtctldata.GetSrvKeyspaceNamesResponse.NameList
-	170, // 106: vtctldata.GetSrvKeyspacesResponse.SrvKeyspacesEntry.value:type_name -> topodata.SrvKeyspace
-	166, // 107: vtctldata.GetSrvVSchemasResponse.SrvVSchemasEntry.value:type_name -> vschema.SrvVSchema
-	171, // 108: vtctldata.ShardReplicationPositionsResponse.ReplicationStatusesEntry.value:type_name -> replicationdata.Status
-	156, // 109: vtctldata.ShardReplicationPositionsResponse.TabletMapEntry.value:type_name -> topodata.Tablet
-	129, // 110: vtctldata.ValidateResponse.ResultsByKeyspaceEntry.value:type_name -> vtctldata.ValidateKeyspaceResponse
-	131, // 111: vtctldata.ValidateKeyspaceResponse.ResultsByShardEntry.value:type_name -> vtctldata.ValidateShardResponse
-	112, // [112:112] is the sub-list for method output_type
-	112, // [112:112] is the sub-list for method input_type
-	112, // [112:112] is the sub-list for extension type_name
-	112, // [112:112] is the sub-list for extension extendee
-	0,   // [0:112] is the sub-list for field type_name
+	158, // 53: vtctldata.InitShardPrimaryRequest.primary_elect_tablet_alias:type_name -> topodata.TabletAlias
+	165, // 54: vtctldata.InitShardPrimaryRequest.wait_replicas_timeout:type_name -> vttime.Duration
+	152, // 55: vtctldata.InitShardPrimaryResponse.events:type_name -> logutil.Event
+	158, // 56: vtctldata.PingTabletRequest.tablet_alias:type_name -> topodata.TabletAlias
+	158, // 57: vtctldata.PlannedReparentShardRequest.new_primary:type_name -> topodata.TabletAlias
+	158, // 58: vtctldata.PlannedReparentShardRequest.avoid_primary:type_name -> topodata.TabletAlias
+	165, // 59: vtctldata.PlannedReparentShardRequest.wait_replicas_timeout:type_name -> vttime.Duration
+	158, // 60: vtctldata.PlannedReparentShardResponse.promoted_primary:type_name -> topodata.TabletAlias
+	152, // 61: vtctldata.PlannedReparentShardResponse.events:type_name -> logutil.Event
+	158, // 62: vtctldata.RefreshStateRequest.tablet_alias:type_name -> topodata.TabletAlias
+	158, // 63: vtctldata.ReloadSchemaRequest.tablet_alias:type_name -> topodata.TabletAlias
+	152, // 64: vtctldata.ReloadSchemaKeyspaceResponse.events:type_name -> logutil.Event
+	152, // 65: vtctldata.ReloadSchemaShardResponse.events:type_name -> logutil.Event
+	158, // 66: vtctldata.ReparentTabletRequest.tablet:type_name -> topodata.TabletAlias
+	158, // 67: vtctldata.ReparentTabletResponse.primary:type_name -> topodata.TabletAlias
+	158, // 68: vtctldata.RunHealthCheckRequest.tablet_alias:type_name -> topodata.TabletAlias
+	159, // 69: vtctldata.SetKeyspaceServedFromRequest.tablet_type:type_name -> topodata.TabletType
+	153, // 70: vtctldata.SetKeyspaceServedFromResponse.keyspace:type_name -> topodata.Keyspace
+	161, // 71: vtctldata.SetKeyspaceShardingInfoRequest.column_type:type_name -> topodata.KeyspaceIdType
+	153, // 72: vtctldata.SetKeyspaceShardingInfoResponse.keyspace:type_name -> topodata.Keyspace
+	154, // 73: vtctldata.SetShardIsPrimaryServingResponse.shard:type_name -> topodata.Shard
+	159, // 74: vtctldata.SetShardTabletControlRequest.tablet_type:type_name -> topodata.TabletType
+	154, // 75: vtctldata.SetShardTabletControlResponse.shard:type_name -> topodata.Shard
+	158, // 76: vtctldata.SetWritableRequest.tablet_alias:type_name -> topodata.TabletAlias
+	146, // 77: vtctldata.ShardReplicationPositionsResponse.replication_statuses:type_name -> vtctldata.ShardReplicationPositionsResponse.ReplicationStatusesEntry
+	147, // 78: vtctldata.ShardReplicationPositionsResponse.tablet_map:type_name -> vtctldata.ShardReplicationPositionsResponse.TabletMapEntry
+	158, // 79: vtctldata.SleepTabletRequest.tablet_alias:type_name -> topodata.TabletAlias
+	165, // 80: vtctldata.SleepTabletRequest.duration:type_name -> vttime.Duration
+	158, // 81: vtctldata.StartReplicationRequest.tablet_alias:type_name -> topodata.TabletAlias
+	158, // 82: vtctldata.StopReplicationRequest.tablet_alias:type_name -> topodata.TabletAlias
+	158, // 83: vtctldata.TabletExternallyReparentedRequest.tablet:type_name -> topodata.TabletAlias
+	158, // 84: vtctldata.TabletExternallyReparentedResponse.new_primary:type_name -> topodata.TabletAlias
+	158, // 85: vtctldata.TabletExternallyReparentedResponse.old_primary:type_name -> topodata.TabletAlias
+	155, // 86: vtctldata.UpdateCellInfoRequest.cell_info:type_name -> topodata.CellInfo
+	155, // 87: vtctldata.UpdateCellInfoResponse.cell_info:type_name -> topodata.CellInfo
+	171, // 88: vtctldata.UpdateCellsAliasRequest.cells_alias:type_name -> topodata.CellsAlias
+	171, // 89: vtctldata.UpdateCellsAliasResponse.cells_alias:type_name -> topodata.CellsAlias
+	148, // 90: vtctldata.ValidateResponse.results_by_keyspace:type_name -> vtctldata.ValidateResponse.ResultsByKeyspaceEntry
+	149, // 91: vtctldata.ValidateKeyspaceResponse.results_by_shard:type_name -> vtctldata.ValidateKeyspaceResponse.ResultsByShardEntry
+	157, // 92: vtctldata.ValidateVSchemaChangeRequest.v_schema:type_name -> vschema.Keyspace
+	150, // 93: vtctldata.ValidateVSchemaChangeRequest.queries:type_name -> vtctldata.ValidateVSchemaChangeRequest.Query
+	151, // 94: vtctldata.ValidateVSchemaChangeResponse.plan_changes:type_name -> vtctldata.ValidateVSchemaChangeResponse.PlanChange
+	136, // 95: vtctldata.Workflow.ShardStreamsEntry.value:type_name -> vtctldata.Workflow.ShardStream
+	137, // 96: vtctldata.Workflow.ShardStream.streams:type_name -> vtctldata.Workflow.Stream
+	172, // 97: vtctldata.Workflow.ShardStream.tablet_controls:type_name -> topodata.Shard.TabletControl
+	158, // 98: vtctldata.Workflow.Stream.tablet:type_name -> topodata.TabletAlias
+	173, // 99: vtctldata.Workflow.Stream.binlog_source:type_name -> binlogdata.BinlogSource
+	164, // 100: vtctldata.Workflow.Stream.transaction_timestamp:type_name -> vttime.Time
+	164, // 101: vtctldata.Workflow.Stream.time_updated:type_name -> vttime.Time
+	138, // 102: vtctldata.Workflow.Stream.copy_states:type_name -> vtctldata.Workflow.Stream.CopyState
+	139, // 103: vtctldata.Workflow.Stream.logs:type_name -> vtctldata.Workflow.Stream.Log
+	164, // 104: vtctldata.Workflow.Stream.Log.created_at:type_name -> vttime.Time
+	164, // 105: vtctldata.Workflow.Stream.Log.updated_at:type_name -> vttime.Time
+	6,   // 106: vtctldata.FindAllShardsInKeyspaceResponse.ShardsEntry.value:type_name -> vtctldata.Shard
+	171, // 107: vtctldata.GetCellsAliasesResponse.AliasesEntry.value:type_name -> topodata.CellsAlias
+	143, // 108: vtctldata.GetSrvKeyspaceNamesResponse.NamesEntry.value:type_name -> vtctldata.GetSrvKeyspaceNamesResponse.NameList
+	174, // 109: vtctldata.GetSrvKeyspacesResponse.SrvKeyspacesEntry.value:type_name -> topodata.SrvKeyspace
+	170, // 110: vtctldata.GetSrvVSchemasResponse.SrvVSchemasEntry.value:type_name -> vschema.SrvVSchema
+	175, // 111: vtctldata.ShardReplicationPositionsResponse.ReplicationStatusesEntry.value:type_name -> replicationdata.Status
+	160, // 112: vtctldata.ShardReplicationPositionsResponse.TabletMapEntry.value:type_name -> topodata.Tablet
+	129, // 113: vtctldata.ValidateResponse.ResultsByKeyspaceEntry.value:type_name -> vtctldata.ValidateKeyspaceResponse
+	131, // 114: vtctldata.ValidateKeyspaceResponse.ResultsByShardEntry.value:type_name -> vtctldata.ValidateShardResponse
+	115, // [115:115] is the sub-list for method output_type
+	115, // [115:115] is the sub-list for method input_type
+	115, // [115:115] is the sub-list for extension type_name
+	115, // [115:115] is the sub-list for extension extendee
+	0,   // [0:115] is the sub-list for field type_name
 }
 
 func init() { file_vtctldata_proto_init() }
@@ -10663,7 +10984,31 @@ func file_vtctldata_proto_init() {
 				return nil
 			}
 		}
+		file_vtctldata_proto_msgTypes[131].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateVSchemaChangeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 		file_vtctldata_proto_msgTypes[132].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateVSchemaChangeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vtctldata_proto_msgTypes[134].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Workflow_ReplicationLocation); i {
 			case 0:
 				return &v.state
@@ -10675,7 +11020,7 @@ func file_vtctldata_proto_init() {
 				return nil
 			}
 		}
-		file_vtctldata_proto_msgTypes[133].Exporter = func(v interface{}, i int) interface{} {
+		file_vtctldata_proto_msgTypes[135].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Workflow_ShardStream); i {
 			case 0:
 				return &v.state
@@ -10687,7 +11032,7 @@ func file_vtctldata_proto_init() {
 				return nil
 			}
 		}
-		file_vtctldata_proto_msgTypes[134].Exporter = func(v interface{}, i int) interface{} {
+		file_vtctldata_proto_msgTypes[136].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Workflow_Stream); i {
 			case 0:
 				return &v.state
@@ -10699,7 +11044,7 @@ func file_vtctldata_proto_init() {
 				return nil
 			}
 		}
-		file_vtctldata_proto_msgTypes[135].Exporter = func(v interface{}, i int) interface{} {
+		file_vtctldata_proto_msgTypes[137].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Workflow_Stream_CopyState); i {
 			case 0:
 				return &v.state
@@ -10711,7 +11056,7 @@ func file_vtctldata_proto_init() {
 				return nil
 			}
 		}
-		file_vtctldata_proto_msgTypes[136].Exporter = func(v interface{}, i int) interface{} {
+		file_vtctldata_proto_msgTypes[138].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Workflow_Stream_Log); i {
 			case 0:
 				return &v.state
@@ -10723,7 +11068,7 @@ func file_vtctldata_proto_init() {
 				return nil
 			}
 		}
-		file_vtctldata_proto_msgTypes[140].Exporter = func(v interface{}, i int) interface{} {
+		file_vtctldata_proto_msgTypes[142].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetSrvKeyspaceNamesResponse_NameList); i {
 			case 0:
 				return &v.state
@@ -10735,6 +11080,30 @@ func file_vtctldata_proto_init() {
 				return nil
 			}
 		}
+		file_vtctldata_proto_msgTypes[149].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateVSchemaChangeRequest_Query); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vtctldata_proto_msgTypes[150].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateVSchemaChangeResponse_PlanChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -10742,7 +11111,7 @@ func file_vtctldata_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_vtctldata_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   147,
+			NumMessages:   151,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
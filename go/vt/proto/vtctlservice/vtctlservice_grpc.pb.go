@@ -339,6 +339,10 @@ type VtctldClient interface {
 	// ValidateShard validates that all nodes reachable from the specified shard
 	// are consistent.
 	ValidateShard(ctx context.Context, in *vtctldata.ValidateShardRequest, opts ...grpc.CallOption) (*vtctldata.ValidateShardResponse, error)
+	// ValidateVSchemaChange validates a proposed vschema for a keyspace against
+	// the schemas of the primary tablets, and reports the queries whose plans
+	// change with it.
+	ValidateVSchemaChange(ctx context.Context, in *vtctldata.ValidateVSchemaChangeRequest, opts ...grpc.CallOption) (*vtctldata.ValidateVSchemaChangeResponse, error)
 }
 
 type vtctldClient struct {
@@ -907,6 +911,15 @@ func (c *vtctldClient) ValidateShard(ctx context.Context, in *vtctldata.Validate
 	return out, nil
 }
 
+func (c *vtctldClient) ValidateVSchemaChange(ctx context.Context, in *vtctldata.ValidateVSchemaChangeRequest, opts ...grpc.CallOption) (*vtctldata.ValidateVSchemaChangeResponse, error) {
+	out := new(vtctldata.ValidateVSchemaChangeResponse)
+	err := c.cc.Invoke(ctx, "/vtctlservice.Vtctld/ValidateVSchemaChange", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // VtctldServer is the server API for Vtctld service.
 // All implementations must embed UnimplementedVtctldServer
 // for forward compatibility
@@ -1118,6 +1131,10 @@ type VtctldServer interface {
 	// ValidateShard validates that all nodes reachable from the specified shard
 	// are consistent.
 	ValidateShard(context.Context, *vtctldata.ValidateShardRequest) (*vtctldata.ValidateShardResponse, error)
+	// ValidateVSchemaChange validates a proposed vschema for a keyspace against
+	// the schemas of the primary tablets, and reports the queries whose plans
+	// change with it.
+	ValidateVSchemaChange(context.Context, *vtctldata.ValidateVSchemaChangeRequest) (*vtctldata.ValidateVSchemaChangeResponse, error)
 	mustEmbedUnimplementedVtctldServer()
 }
 
@@ -1311,6 +1328,9 @@ func (UnimplementedVtctldServer) ValidateKeyspace(context.Context, *vtctldata.Va
 func (UnimplementedVtctldServer) ValidateShard(context.Context, *vtctldata.ValidateShardRequest) (*vtctldata.ValidateShardResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ValidateShard not implemented")
 }
+func (UnimplementedVtctldServer) ValidateVSchemaChange(context.Context, *vtctldata.ValidateVSchemaChangeRequest) (*vtctldata.ValidateVSchemaChangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateVSchemaChange not implemented")
+}
 func (UnimplementedVtctldServer) mustEmbedUnimplementedVtctldServer() {}
 
 // UnsafeVtctldServer may be embedded to opt out of forward compatibility for this service.
@@ -2440,6 +2460,24 @@ func _Vtctld_ValidateShard_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Vtctld_ValidateVSchemaChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(vtctldata.ValidateVSchemaChangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VtctldServer).ValidateVSchemaChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/vtctlservice.Vtctld/ValidateVSchemaChange",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VtctldServer).ValidateVSchemaChange(ctx, req.(*vtctldata.ValidateVSchemaChangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Vtctld_ServiceDesc is the grpc.ServiceDesc for Vtctld service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -2695,6 +2733,10 @@ var Vtctld_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ValidateShard",
 			Handler:    _Vtctld_ValidateShard_Handler,
 		},
+		{
+			MethodName: "ValidateVSchemaChange",
+			Handler:    _Vtctld_ValidateVSchemaChange_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "vtctlservice.proto",
@@ -39,6 +39,8 @@ const (
 	DirectiveIgnoreMaxMemoryRows = "IGNORE_MAX_MEMORY_ROWS"
 	// DirectiveAllowScatter lets scatter plans pass through even when they are turned off by `no-scatter`.
 	DirectiveAllowScatter = "ALLOW_SCATTER"
+	// DirectiveWorkloadClass sets the workload class of the query in vttablet.
+	DirectiveWorkloadClass = "WORKLOAD_CLASS"
 )
 
 func isNonSpace(r rune) bool {
@@ -405,3 +407,21 @@ func AllowScatterDirective(stmt Statement) bool {
 	}
 	return directives.IsSet(DirectiveAllowScatter)
 }
+
+// WorkloadClassDirective returns the workload class set by the query, if any.
+func WorkloadClassDirective(stmt Statement) string {
+	var directives CommentDirectives
+	switch stmt := stmt.(type) {
+	case *Select:
+		directives = ExtractCommentDirectives(stmt.Comments)
+	case *Insert:
+		directives = ExtractCommentDirectives(stmt.Comments)
+	case *Update:
+		directives = ExtractCommentDirectives(stmt.Comments)
+	case *Delete:
+		directives = ExtractCommentDirectives(stmt.Comments)
+	default:
+		return ""
+	}
+	return directives.GetString(DirectiveWorkloadClass, "")
+}
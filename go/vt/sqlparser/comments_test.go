@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSplitComments(t *testing.T) {
@@ -469,3 +470,25 @@ func TestIgnoreMaxMaxMemoryRowsDirective(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkloadClassDirective(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected string
+	}{
+		{"select /*vt+ WORKLOAD_CLASS=batch */ * from users", "batch"},
+		{"insert /*vt+ WORKLOAD_CLASS=\"reporting\" */ into users(id) values (1)", "reporting"},
+		{"update /*vt+ WORKLOAD_CLASS=batch */ users set name=1", "batch"},
+		{"delete /*vt+ WORKLOAD_CLASS=batch */ from users", "batch"},
+		{"select * from users", ""},
+		{"show /*vt+ WORKLOAD_CLASS=batch */ create table users", ""},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.query, func(t *testing.T) {
+			stmt, err := Parse(test.query)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, WorkloadClassDirective(stmt))
+		})
+	}
+}
@@ -75,6 +75,11 @@ func (nz *normalizer) WalkStatement(cursor *Cursor) bool {
 		return false
 	case *ConvertType: // we should not rewrite the type description
 		return false
+	case *BinaryExpr:
+		// the path of a JSON extraction has to stay a literal
+		if isJSONExtract(node) {
+			return false
+		}
 	}
 	return nz.err == nil // only continue if we haven't found any errors
 }
@@ -96,10 +101,19 @@ func (nz *normalizer) WalkSelect(cursor *Cursor) bool {
 	case *ConvertType:
 		// we should not rewrite the type description
 		return false
+	case *BinaryExpr:
+		// the path of a JSON extraction has to stay a literal
+		if isJSONExtract(node) {
+			return false
+		}
 	}
 	return nz.err == nil // only continue if we haven't found any errors
 }
 
+func isJSONExtract(node *BinaryExpr) bool {
+	return node.Operator == JSONExtractOp || node.Operator == JSONUnquoteExtractOp
+}
+
 func (nz *normalizer) convertLiteralDedup(node *Literal, cursor *Cursor) {
 	// If value is too long, don't dedup.
 	// Such values are most likely not for vindexes.
@@ -227,6 +227,13 @@ func TestNormalize(t *testing.T) {
 			"bv2": sqltypes.StringBindVariable("2"),
 			"bv3": sqltypes.Int64BindVariable(3),
 		},
+	}, {
+		// JSON paths are not normalized
+		in:      "select doc->>'$.name' from t where doc->'$.id' = 1",
+		outstmt: "select doc ->> '$.name' from t where doc -> '$.id' = :bv1",
+		outbv: map[string]*querypb.BindVariable{
+			"bv1": sqltypes.Int64BindVariable(1),
+		},
 	}}
 	for _, tc := range testcases {
 		stmt, err := Parse(tc.in)
@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// This file provides the utility methods to lease numeric instance ids
+// in the topology global cell. An instance id is unique within its pool
+// for as long as its owner keeps renewing the lease.
+
+const (
+	instanceIDsPath = "instance_ids"
+)
+
+func pathForInstanceID(pool string, id int) string {
+	return path.Join(instanceIDsPath, pool, strconv.Itoa(id))
+}
+
+// InstanceIDLeaseInfo is a meta struct that contains the pool, id and
+// version of an InstanceIDLease.
+type InstanceIDLeaseInfo struct {
+	pool    string
+	id      int
+	version Version
+	*topodatapb.InstanceIDLease
+}
+
+// Pool returns the pool the instance id belongs to.
+func (li *InstanceIDLeaseInfo) Pool() string {
+	return li.pool
+}
+
+// ID returns the leased instance id.
+func (li *InstanceIDLeaseInfo) ID() int {
+	return li.id
+}
+
+// LeaseInstanceID reserves the lowest instance id in [0, maxID] of the
+// given pool that is either free or whose lease has expired. The lease
+// is valid for ttl, and must be renewed with RenewInstanceID.
+func (ts *Server) LeaseInstanceID(ctx context.Context, pool string, maxID int, owner string, ttl time.Duration) (*InstanceIDLeaseInfo, error) {
+	for id := 0; id <= maxID; id++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		filePath := pathForInstanceID(pool, id)
+		lease := &topodatapb.InstanceIDLease{
+			Owner:      owner,
+			ExpireTime: logutil.TimeToProto(time.Now().Add(ttl)),
+		}
+		contents, err := proto.Marshal(lease)
+		if err != nil {
+			return nil, err
+		}
+
+		version, err := ts.globalCell.Create(ctx, filePath, contents)
+		if err == nil {
+			return &InstanceIDLeaseInfo{pool: pool, id: id, version: version, InstanceIDLease: lease}, nil
+		}
+		if !IsErrType(err, NodeExists) {
+			return nil, err
+		}
+
+		// The id was leased before, take it over if the lease expired.
+		data, version, err := ts.globalCell.Get(ctx, filePath)
+		switch {
+		case IsErrType(err, NoNode):
+			// Released in the meantime, let the next call pick it up.
+			continue
+		case err != nil:
+			return nil, err
+		}
+		existing := &topodatapb.InstanceIDLease{}
+		if err := proto.Unmarshal(data, existing); err != nil {
+			return nil, vterrors.Wrapf(err, "bad instance id lease data for %v", filePath)
+		}
+		if time.Now().Before(logutil.ProtoToTime(existing.ExpireTime)) {
+			continue
+		}
+		version, err = ts.globalCell.Update(ctx, filePath, contents, version)
+		switch {
+		case IsErrType(err, BadVersion):
+			// Someone else took it over first.
+			continue
+		case err != nil:
+			return nil, err
+		}
+		return &InstanceIDLeaseInfo{pool: pool, id: id, version: version, InstanceIDLease: lease}, nil
+	}
+	return nil, vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "no free instance id in pool %v", pool)
+}
+
+// RenewInstanceID extends the lease by ttl from now. It returns an
+// error of type BadVersion if the lease was lost to another owner.
+func (ts *Server) RenewInstanceID(ctx context.Context, li *InstanceIDLeaseInfo, ttl time.Duration) error {
+	lease := proto.Clone(li.InstanceIDLease).(*topodatapb.InstanceIDLease)
+	lease.ExpireTime = logutil.TimeToProto(time.Now().Add(ttl))
+	contents, err := proto.Marshal(lease)
+	if err != nil {
+		return err
+	}
+	version, err := ts.globalCell.Update(ctx, pathForInstanceID(li.pool, li.id), contents, li.version)
+	if err != nil {
+		return err
+	}
+	li.version = version
+	li.InstanceIDLease = lease
+	return nil
+}
+
+// ReleaseInstanceID gives the instance id back to the pool. The lease
+// is only removed if it is still owned by the caller.
+func (ts *Server) ReleaseInstanceID(ctx context.Context, li *InstanceIDLeaseInfo) error {
+	return ts.globalCell.Delete(ctx, pathForInstanceID(li.pool, li.id), li.version)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestLeaseInstanceID(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+
+	l0, err := ts.LeaseInstanceID(ctx, "pool", 1, "owner0", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 0, l0.ID())
+	assert.Equal(t, "owner0", l0.Owner)
+
+	l1, err := ts.LeaseInstanceID(ctx, "pool", 1, "owner1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, l1.ID())
+
+	// Other pools are independent.
+	other, err := ts.LeaseInstanceID(ctx, "other", 1, "owner2", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 0, other.ID())
+
+	// The pool is full.
+	_, err = ts.LeaseInstanceID(ctx, "pool", 1, "owner2", time.Minute)
+	assert.EqualError(t, err, "no free instance id in pool pool")
+
+	// An expired lease can be taken over, after which the previous
+	// owner can neither renew nor release it.
+	require.NoError(t, ts.RenewInstanceID(ctx, l0, -time.Second))
+	l2, err := ts.LeaseInstanceID(ctx, "pool", 1, "owner2", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 0, l2.ID())
+	assert.True(t, topo.IsErrType(ts.RenewInstanceID(ctx, l0, time.Minute), topo.BadVersion))
+	assert.True(t, topo.IsErrType(ts.ReleaseInstanceID(ctx, l0), topo.BadVersion))
+
+	// A released id is free again.
+	require.NoError(t, ts.ReleaseInstanceID(ctx, l1))
+	l3, err := ts.LeaseInstanceID(ctx, "pool", 1, "owner3", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, l3.ID())
+}
@@ -18,10 +18,14 @@ package vtctl
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"math"
+	"math/big"
+	"net"
 	"time"
 
 	"vitess.io/vitess/go/vt/logutil"
@@ -31,6 +35,7 @@ import (
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/wrangler"
 
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
@@ -45,9 +50,15 @@ func init() {
 	addCommand("Shards", command{
 		name:   "BackupShard",
 		method: commandBackupShard,
-		params: "[-allow_primary=false] <keyspace/shard>",
+		params: "[-concurrency=4] [-allow_primary=false] [-backup_engine=<engine>] <keyspace/shard>",
 		help:   "Chooses a tablet and creates a backup for a shard.",
 	})
+	addCommand("Shards", command{
+		name:   "VerifyBackup",
+		method: commandVerifyBackup,
+		params: "[-concurrency=4] [-mysql_port=<port>] [-db_name=<name>] <keyspace/shard> [<backup name>]",
+		help:   "Restores a backup of a shard, the most recent complete one by default, into a scratch mysqld started on the host this command runs on, runs CHECK TABLE on its tables and counts their rows, and stores the result of the verification next to the backups, like vtbackup -verify_backup. The mysqld binaries must be installed on the host, and VTDATAROOT must have room for the restored backup. Returns an error if the backup is invalid.",
+	})
 	addCommand("Shards", command{
 		name:   "RemoveBackup",
 		method: commandRemoveBackup,
@@ -58,14 +69,14 @@ func init() {
 	addCommand("Tablets", command{
 		name:   "Backup",
 		method: commandBackup,
-		params: "[-concurrency=4] [-allow_primary=false] <tablet alias>",
+		params: "[-concurrency=4] [-allow_primary=false] [-backup_engine=<engine>] <tablet alias>",
 		help:   "Stops mysqld and uses the BackupStorage service to store a new backup. This function also remembers if the tablet was replicating so that it can restore the same state after the backup completes.",
 	})
 	addCommand("Tablets", command{
 		name:   "RestoreFromBackup",
 		method: commandRestoreFromBackup,
-		params: "[-backup_timestamp=yyyy-MM-dd.HHmmss] <tablet alias>",
-		help:   "Stops mysqld and restores the data from the latest backup or if a timestamp is specified then the most recent backup at or before that time.",
+		params: "[-backup_timestamp=yyyy-MM-dd.HHmmss] [-restore_to_pos=<position> | -restore_to_timestamp=yyyy-MM-dd.HHmmss] <tablet alias>",
+		help:   "Stops mysqld and restores the data from the latest backup or if a timestamp is specified then the most recent backup at or before that time. If a restore position or timestamp is specified, the most recent backup before it is restored, and the archived binary logs are replayed on top of it up to that exact point. Replication is then left stopped.",
 	})
 }
 
@@ -73,6 +84,8 @@ func commandBackup(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.Fl
 	concurrency := subFlags.Int("concurrency", 4, "Specifies the number of compression/checksum jobs to run simultaneously")
 	allowPrimary := subFlags.Bool("allow_primary", false, "Allows backups to be taken on primary. Warning!! If you are using the builtin backup engine, this will shutdown your primary mysql for as long as it takes to create a backup.")
 
+	backupEngine := subFlags.String("backup_engine", "", "The backup engine to use, builtin, xtrabackup or logical. The -backup_engine_implementation of the tablet is used if empty.")
+
 	// handle deprecated flags
 	// should be deleted in a future release
 	deprecatedAllowMaster := subFlags.Bool("allow_master", false, "DEPRECATED. Use -allow_primary instead")
@@ -96,13 +109,15 @@ func commandBackup(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.Fl
 		*allowPrimary = *deprecatedAllowMaster
 	}
 
-	return execBackup(ctx, wr, tabletInfo.Tablet, *concurrency, *allowPrimary)
+	return execBackup(ctx, wr, tabletInfo.Tablet, *concurrency, *allowPrimary, *backupEngine)
 }
 
 func commandBackupShard(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
 	concurrency := subFlags.Int("concurrency", 4, "Specifies the number of compression/checksum jobs to run simultaneously")
 	allowPrimary := subFlags.Bool("allow_primary", false, "Whether to use primary tablet for backup. Warning!! If you are using the builtin backup engine, this will shutdown your primary mysql for as long as it takes to create a backup.")
 
+	backupEngine := subFlags.String("backup_engine", "", "The backup engine to use, builtin, xtrabackup or logical. The -backup_engine_implementation of the tablet is used if empty.")
+
 	// handle deprecated flags
 	// should be deleted in a future release
 	deprecatedAllowMaster := subFlags.Bool("allow_master", false, "DEPRECATED. Use -allow_primary instead")
@@ -171,12 +186,16 @@ func commandBackupShard(ctx context.Context, wr *wrangler.Wrangler, subFlags *fl
 		*allowPrimary = *deprecatedAllowMaster
 	}
 
-	return execBackup(ctx, wr, tabletForBackup, *concurrency, *allowPrimary)
+	return execBackup(ctx, wr, tabletForBackup, *concurrency, *allowPrimary, *backupEngine)
 }
 
 // execBackup is shared by Backup and BackupShard
-func execBackup(ctx context.Context, wr *wrangler.Wrangler, tablet *topodatapb.Tablet, concurrency int, allowPrimary bool) error {
-	stream, err := wr.TabletManagerClient().Backup(ctx, tablet, concurrency, allowPrimary)
+func execBackup(ctx context.Context, wr *wrangler.Wrangler, tablet *topodatapb.Tablet, concurrency int, allowPrimary bool, backupEngine string) error {
+	stream, err := wr.TabletManagerClient().Backup(ctx, tablet, &tabletmanagerdatapb.BackupRequest{
+		Concurrency:  int64(concurrency),
+		AllowPrimary: allowPrimary,
+		BackupEngine: backupEngine,
+	})
 	if err != nil {
 		return err
 	}
@@ -222,6 +241,81 @@ func commandListBackups(ctx context.Context, wr *wrangler.Wrangler, subFlags *fl
 	return nil
 }
 
+func commandVerifyBackup(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	concurrency := subFlags.Int("concurrency", 4, "Specifies the number of files to restore concurrently")
+	mysqlPort := subFlags.Int("mysql_port", 0, "TCP port of the scratch mysqld, a free port by default")
+	dbName := subFlags.String("db_name", "", "Name of the database of the backup, vt_<keyspace> by default")
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 && subFlags.NArg() != 2 {
+		return fmt.Errorf("action VerifyBackup requires <keyspace/shard> [<backup name>]")
+	}
+
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *dbName == "" {
+		*dbName = "vt_" + keyspace
+	}
+	if *mysqlPort == 0 {
+		if *mysqlPort, err = freePort(); err != nil {
+			return err
+		}
+	}
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return err
+	}
+	defer bs.Close()
+
+	// The alias of the scratch mysqld only names its directory, which
+	// must not be the one of another mysqld of the host.
+	uid, err := rand.Int(rand.Reader, big.NewInt(math.MaxUint32))
+	if err != nil {
+		return fmt.Errorf("can't generate random tablet UID: %v", err)
+	}
+	tabletAlias := &topodatapb.TabletAlias{Cell: "verifybackup", Uid: uint32(uid.Uint64())}
+	mysqld, mycnf, cleanup, err := mysqlctl.StartScratchMysqld(ctx, tabletAlias.Uid, int32(*mysqlPort))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	bv, err := mysqlctl.VerifyBackup(ctx, bs, mysqlctl.RestoreParams{
+		Cnf:         mycnf,
+		Mysqld:      mysqld,
+		Logger:      wr.Logger(),
+		Concurrency: *concurrency,
+		HookExtraEnv: map[string]string{
+			"TABLET_ALIAS": topoproto.TabletAliasString(tabletAlias),
+		},
+		LocalMetadata:       map[string]string{},
+		DeleteBeforeRestore: true,
+		DbName:              *dbName,
+		Keyspace:            keyspace,
+		Shard:               shard,
+		BackupName:          subFlags.Arg(1),
+	})
+	if bv != nil {
+		if perr := printJSON(wr.Logger(), bv); perr != nil && err == nil {
+			err = perr
+		}
+	}
+	return err
+}
+
+// freePort returns a TCP port that is free for the scratch mysqld.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, fmt.Errorf("can't find a free port: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
 func commandRemoveBackup(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
 	if err := subFlags.Parse(args); err != nil {
 		return err
@@ -247,6 +341,8 @@ func commandRemoveBackup(ctx context.Context, wr *wrangler.Wrangler, subFlags *f
 
 func commandRestoreFromBackup(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
 	backupTimestampStr := subFlags.String("backup_timestamp", "", "Use the backup taken at or before this timestamp rather than using the latest backup.")
+	restoreToPos := subFlags.String("restore_to_pos", "", "Restore up to this replication position, using the archived binary logs.")
+	restoreToTimestampStr := subFlags.String("restore_to_timestamp", "", "Restore up to this timestamp (UTC), using the archived binary logs.")
 	if err := subFlags.Parse(args); err != nil {
 		return err
 	}
@@ -274,7 +370,18 @@ func commandRestoreFromBackup(ctx context.Context, wr *wrangler.Wrangler, subFla
 	if err != nil {
 		return err
 	}
-	stream, err := wr.TabletManagerClient().RestoreFromBackup(ctx, tabletInfo.Tablet, backupTime)
+	req := &tabletmanagerdatapb.RestoreFromBackupRequest{
+		BackupTime:   logutil.TimeToProto(backupTime),
+		RestoreToPos: *restoreToPos,
+	}
+	if *restoreToTimestampStr != "" {
+		restoreToTime, err := time.Parse(mysqlctl.BackupTimestampFormat, *restoreToTimestampStr)
+		if err != nil {
+			return vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, fmt.Sprintf("unable to parse the restore timestamp value provided of '%s'", *restoreToTimestampStr))
+		}
+		req.RestoreToTimestamp = logutil.TimeToProto(restoreToTime)
+	}
+	stream, err := wr.TabletManagerClient().RestoreFromBackup(ctx, tabletInfo.Tablet, req)
 	if err != nil {
 		return err
 	}
@@ -585,3 +585,12 @@ func (client *gRPCVtctldClient) ValidateShard(ctx context.Context, in *vtctldata
 
 	return client.c.ValidateShard(ctx, in, opts...)
 }
+
+// ValidateVSchemaChange is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ValidateVSchemaChange(ctx context.Context, in *vtctldatapb.ValidateVSchemaChangeRequest, opts ...grpc.CallOption) (*vtctldatapb.ValidateVSchemaChangeResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ValidateVSchemaChange(ctx, in, opts...)
+}
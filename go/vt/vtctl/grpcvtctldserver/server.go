@@ -2885,6 +2885,75 @@ func (s *VtctldServer) ValidateShard(ctx context.Context, req *vtctldatapb.Valid
 	return &resp, nil
 }
 
+// ValidateVSchemaChange is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ValidateVSchemaChange(ctx context.Context, req *vtctldatapb.ValidateVSchemaChangeRequest) (*vtctldatapb.ValidateVSchemaChangeResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ValidateVSchemaChange")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("queries", len(req.Queries))
+
+	if _, err := s.ts.GetKeyspace(ctx, req.Keyspace); err != nil {
+		return nil, vterrors.Wrapf(err, "GetKeyspace(%s)", req.Keyspace)
+	}
+
+	if (req.Sql != "" && req.VSchema != nil) || (req.Sql == "" && req.VSchema == nil) {
+		return nil, vterrors.New(vtrpc.Code_INVALID_ARGUMENT, "must pass exactly one of req.VSchema and req.Sql")
+	}
+
+	vs := req.VSchema
+	if req.Sql != "" {
+		span.Annotate("sql_mode", true)
+
+		stmt, err := sqlparser.Parse(req.Sql)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "Parse(%s)", req.Sql)
+		}
+		ddl, ok := stmt.(*sqlparser.AlterVschema)
+		if !ok {
+			return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "error parsing VSchema DDL statement `%s`", req.Sql)
+		}
+
+		vs, err = s.ts.GetVSchema(ctx, req.Keyspace)
+		if err != nil && !topo.IsErrType(err, topo.NoNode) {
+			return nil, vterrors.Wrapf(err, "GetVSchema(%s)", req.Keyspace)
+		}
+
+		vs, err = topotools.ApplyVSchemaDDL(req.Keyspace, vs, ddl)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "ApplyVSchemaDDL(%s,%v,%v)", req.Keyspace, vs, ddl)
+		}
+	} else {
+		span.Annotate("sql_mode", false)
+	}
+
+	queries := make([]*schematools.LoggedQuery, 0, len(req.Queries))
+	for _, query := range req.Queries {
+		queries = append(queries, &schematools.LoggedQuery{SQL: query.Sql, Keyspace: query.Keyspace})
+	}
+
+	validation, err := schematools.ValidateVSchemaChange(ctx, s.ts, s.tmc, req.Keyspace, vs, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &vtctldatapb.ValidateVSchemaChangeResponse{
+		Errors:   validation.Errors,
+		Replayed: int32(validation.Replayed),
+	}
+	for _, change := range validation.PlanChanges {
+		resp.PlanChanges = append(resp.PlanChanges, &vtctldatapb.ValidateVSchemaChangeResponse_PlanChange{
+			Query:    change.Query,
+			Keyspace: change.Keyspace,
+			Before:   change.Before,
+			After:    change.After,
+			Failing:  change.Failing,
+		})
+	}
+
+	return resp, nil
+}
+
 // StartServer registers a VtctldServer for RPCs on the given gRPC server.
 func StartServer(s *grpc.Server, ts *topo.Server) {
 	vtctlservicepb.RegisterVtctldServer(s, NewVtctldServer(ts))
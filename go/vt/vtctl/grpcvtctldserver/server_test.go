@@ -9698,3 +9698,131 @@ func TestValidateShard(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateVSchemaChange(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		req       *vtctldatapb.ValidateVSchemaChangeRequest
+		expected  *vtctldatapb.ValidateVSchemaChangeResponse
+		shouldErr bool
+	}{
+		{
+			name: "vschema",
+			req: &vtctldatapb.ValidateVSchemaChangeRequest{
+				Keyspace: "testkeyspace",
+				VSchema: &vschemapb.Keyspace{
+					Sharded:  true,
+					Vindexes: map[string]*vschemapb.Vindex{"hash": {Type: "hash"}},
+					Tables: map[string]*vschemapb.Table{
+						"t1": {ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "hash", Column: "c"}}},
+					},
+				},
+				Queries: []*vtctldatapb.ValidateVSchemaChangeRequest_Query{
+					{Sql: "select * from t1 where id = 1", Keyspace: "testkeyspace"},
+					{Sql: "select * from t1", Keyspace: "testkeyspace"},
+				},
+			},
+			expected: &vtctldatapb.ValidateVSchemaChangeResponse{
+				Replayed: 2,
+				PlanChanges: []*vtctldatapb.ValidateVSchemaChangeResponse_PlanChange{{
+					Query:    "select * from t1 where id = 1",
+					Keyspace: "testkeyspace",
+					Before:   "Route(testkeyspace.SelectEqualUnique)",
+					After:    "Route(testkeyspace.SelectScatter)",
+				}},
+			},
+		},
+		{
+			name: "sql",
+			req: &vtctldatapb.ValidateVSchemaChangeRequest{
+				Keyspace: "testkeyspace",
+				Sql:      "alter vschema on t1 add vindex missing_hash(missing) using hash",
+			},
+			expected: &vtctldatapb.ValidateVSchemaChangeResponse{
+				Errors: []string{"column missing of vindex missing_hash does not exist in table t1"},
+			},
+		},
+		{
+			name: "both",
+			req: &vtctldatapb.ValidateVSchemaChangeRequest{
+				Keyspace: "testkeyspace",
+				VSchema:  &vschemapb.Keyspace{},
+				Sql:      "alter vschema on t1 add vindex hash(c)",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "neither",
+			req: &vtctldatapb.ValidateVSchemaChangeRequest{
+				Keyspace: "testkeyspace",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "keyspace not found",
+			req: &vtctldatapb.ValidateVSchemaChangeRequest{
+				Keyspace: "otherkeyspace",
+				VSchema:  &vschemapb.Keyspace{},
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ts := memorytopo.NewServer("zone1")
+			tmc := &testutil.TabletManagerClient{
+				GetSchemaResults: map[string]struct {
+					Schema *tabletmanagerdatapb.SchemaDefinition
+					Error  error
+				}{
+					"zone1-0000000100": {
+						Schema: &tabletmanagerdatapb.SchemaDefinition{
+							TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
+								Name: "t1",
+								Fields: []*querypb.Field{
+									{Name: "id", Type: querypb.Type_INT64},
+									{Name: "c", Type: querypb.Type_INT64},
+								},
+							}},
+						},
+					},
+				},
+			}
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+
+			testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{AlsoSetShardPrimary: true}, &topodatapb.Tablet{
+				Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+				Keyspace: "testkeyspace",
+				Shard:    "-",
+				Type:     topodatapb.TabletType_PRIMARY,
+			})
+			err := ts.SaveVSchema(ctx, "testkeyspace", &vschemapb.Keyspace{
+				Sharded:  true,
+				Vindexes: map[string]*vschemapb.Vindex{"hash": {Type: "hash"}},
+				Tables: map[string]*vschemapb.Table{
+					"t1": {ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "hash", Column: "id"}}},
+				},
+			})
+			require.NoError(t, err)
+
+			resp, err := vtctld.ValidateVSchemaChange(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
@@ -335,3 +335,8 @@ func (client *localVtctldClient) ValidateKeyspace(ctx context.Context, in *vtctl
 func (client *localVtctldClient) ValidateShard(ctx context.Context, in *vtctldatapb.ValidateShardRequest, opts ...grpc.CallOption) (*vtctldatapb.ValidateShardResponse, error) {
 	return client.s.ValidateShard(ctx, in)
 }
+
+// ValidateVSchemaChange is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ValidateVSchemaChange(ctx context.Context, in *vtctldatapb.ValidateVSchemaChangeRequest, opts ...grpc.CallOption) (*vtctldatapb.ValidateVSchemaChangeResponse, error) {
+	return client.s.ValidateVSchemaChange(ctx, in)
+}
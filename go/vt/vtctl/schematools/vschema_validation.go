@@ -0,0 +1,637 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schematools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// LoggedQuery is a query read from a vtgate query log.
+type LoggedQuery struct {
+	SQL string
+	// Keyspace is the keyspace the query was sent to,
+	// if the session had one.
+	Keyspace string
+}
+
+// VSchemaPlanChange describes how the plan of a query changes
+// with a proposed vschema.
+type VSchemaPlanChange struct {
+	Query    string
+	Keyspace string
+	// Before and After list the routes of the plans, or the planning
+	// error, with the current and the proposed vschema.
+	Before string
+	After  string
+	// Failing is true if the query can't be planned anymore.
+	Failing bool
+}
+
+// VSchemaValidation is the result of ValidateVSchemaChange.
+type VSchemaValidation struct {
+	// Errors are the problems found in the proposed vschema.
+	Errors []string
+	// Replayed is the number of queries that were planned.
+	Replayed int
+	// PlanChanges are the queries whose plan changes.
+	PlanChanges []*VSchemaPlanChange
+}
+
+// HasFailures returns true if the proposed vschema has errors,
+// or makes some queries fail.
+func (v *VSchemaValidation) HasFailures() bool {
+	if len(v.Errors) != 0 {
+		return true
+	}
+	for _, change := range v.PlanChanges {
+		if change.Failing {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateVSchemaChange validates a proposed vschema for a keyspace
+// against the schemas of the primary tablets, and replays the queries
+// through the planner with the current and the proposed vschemas to
+// report the plans that change.
+func ValidateVSchemaChange(ctx context.Context, ts *topo.Server, tmc tmclient.TabletManagerClient, keyspace string, proposed *vschemapb.Keyspace, queries []*LoggedQuery) (*VSchemaValidation, error) {
+	current, err := srvVSchema(ctx, ts)
+	if err != nil {
+		return nil, err
+	}
+	next := proto.Clone(current).(*vschemapb.SrvVSchema)
+	next.Keyspaces[keyspace] = proposed
+	before := vindexes.BuildVSchema(current)
+	after := vindexes.BuildVSchema(next)
+
+	validation := &VSchemaValidation{}
+	if err := after.Keyspaces[keyspace].Error; err != nil {
+		validation.Errors = append(validation.Errors, err.Error())
+		return validation, nil
+	}
+	v := &vschemaValidator{
+		ts:      ts,
+		tmc:     tmc,
+		vschema: after,
+		schemas: make(map[string]map[string]*tabletmanagerdatapb.TableDefinition),
+	}
+	if err := v.validate(ctx, keyspace, proposed); err != nil {
+		return nil, err
+	}
+	validation.Errors = v.errors
+
+	for _, query := range queries {
+		stmt, err := sqlparser.Parse(query.SQL)
+		if err != nil {
+			continue
+		}
+		switch stmt.(type) {
+		case sqlparser.SelectStatement, *sqlparser.Insert, *sqlparser.Update, *sqlparser.Delete:
+		default:
+			continue
+		}
+		validation.Replayed++
+		routesBefore, errBefore := planRoutes(before, query)
+		routesAfter, errAfter := planRoutes(after, query)
+		if errBefore != nil {
+			routesBefore = errBefore.Error()
+		}
+		if errAfter != nil {
+			routesAfter = errAfter.Error()
+		}
+		if routesBefore == routesAfter {
+			continue
+		}
+		validation.PlanChanges = append(validation.PlanChanges, &VSchemaPlanChange{
+			Query:    query.SQL,
+			Keyspace: query.Keyspace,
+			Before:   routesBefore,
+			After:    routesAfter,
+			Failing:  errBefore == nil && errAfter != nil,
+		})
+	}
+	return validation, nil
+}
+
+// srvVSchema builds the SrvVSchema from the vschemas of all the keyspaces.
+func srvVSchema(ctx context.Context, ts *topo.Server) (*vschemapb.SrvVSchema, error) {
+	keyspaces, err := ts.GetKeyspaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	srvVSchema := &vschemapb.SrvVSchema{
+		Keyspaces: make(map[string]*vschemapb.Keyspace, len(keyspaces)),
+	}
+	for _, keyspace := range keyspaces {
+		vschema, err := ts.GetVSchema(ctx, keyspace)
+		if err != nil {
+			if topo.IsErrType(err, topo.NoNode) {
+				continue
+			}
+			return nil, err
+		}
+		srvVSchema.Keyspaces[keyspace] = vschema
+	}
+	if srvVSchema.RoutingRules, err = ts.GetRoutingRules(ctx); err != nil {
+		return nil, err
+	}
+	return srvVSchema, nil
+}
+
+// vschemaValidator validates a vschema against the schemas of the
+// primary tablets of its keyspaces.
+type vschemaValidator struct {
+	ts      *topo.Server
+	tmc     tmclient.TabletManagerClient
+	vschema *vindexes.VSchema
+	// schemas are the tables of each keyspace, loaded on demand.
+	schemas map[string]map[string]*tabletmanagerdatapb.TableDefinition
+	errors  []string
+}
+
+func (v *vschemaValidator) errorf(format string, args ...interface{}) {
+	v.errors = append(v.errors, fmt.Sprintf(format, args...))
+}
+
+func (v *vschemaValidator) validate(ctx context.Context, keyspace string, proposed *vschemapb.Keyspace) error {
+	tables, err := v.tables(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	if tables == nil {
+		v.errorf("keyspace %s has no primary tablet to validate against", keyspace)
+		return nil
+	}
+	ksSchema := v.vschema.Keyspaces[keyspace]
+
+	names := make([]string, 0, len(proposed.Tables))
+	for name := range proposed.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		table := ksSchema.Tables[name]
+		def, ok := tables[name]
+		if !ok {
+			v.errorf("table %s does not exist in keyspace %s", name, keyspace)
+			continue
+		}
+		fields := tableFields(def)
+		for _, col := range table.Columns {
+			if _, ok := fields[col.Name.Lowered()]; !ok {
+				v.errorf("column %s of table %s does not exist", col.Name, name)
+			}
+		}
+		for _, cv := range table.ColumnVindexes {
+			for _, col := range cv.Columns {
+				field, ok := fields[col.Lowered()]
+				if !ok {
+					v.errorf("column %s of vindex %s does not exist in table %s", col, cv.Name, name)
+					continue
+				}
+				if want := vindexColumnType(cv); want != "" && !compatibleType(want, field.Type) {
+					v.errorf("column %s of table %s has type %s, vindex %s needs %s values", col, name, strings.ToLower(field.Type.String()), cv.Name, want)
+				}
+			}
+		}
+		if ai := table.AutoIncrement; ai != nil {
+			if _, ok := fields[ai.Column.Lowered()]; !ok {
+				v.errorf("auto_increment column %s of table %s does not exist", ai.Column, name)
+			}
+			if ai.Sequence != nil {
+				if err := v.checkTable(ctx, ai.Sequence.Keyspace.Name, ai.Sequence.Name.String(), nil); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	vindexNames := make([]string, 0, len(proposed.Vindexes))
+	for name := range proposed.Vindexes {
+		vindexNames = append(vindexNames, name)
+	}
+	sort.Strings(vindexNames)
+	for _, name := range vindexNames {
+		vindex := proposed.Vindexes[name]
+		if _, ok := ksSchema.Vindexes[name].(vindexes.Lookup); ok && vindex.Params["table"] != "" {
+			if err := v.checkLookupTable(ctx, keyspace, name, vindex.Params); err != nil {
+				return err
+			}
+		}
+		if vindex.Owner == "" {
+			continue
+		}
+		owner, ok := proposed.Tables[vindex.Owner]
+		if !ok {
+			v.errorf("owner table %s of vindex %s does not exist in the vschema", vindex.Owner, name)
+			continue
+		}
+		used := false
+		for _, cv := range owner.ColumnVindexes {
+			used = used || cv.Name == name
+		}
+		if !used {
+			v.errorf("owner table %s of vindex %s does not use it", vindex.Owner, name)
+		}
+	}
+	return nil
+}
+
+// checkLookupTable checks that the table of a lookup vindex has its
+// from and to columns.
+func (v *vschemaValidator) checkLookupTable(ctx context.Context, keyspace, vindex string, params map[string]string) error {
+	lookupKeyspace, lookupTable, err := sqlparser.ParseTable(params["table"])
+	if err != nil {
+		v.errorf("invalid table %s of vindex %s: %v", params["table"], vindex, err)
+		return nil
+	}
+	if lookupKeyspace == "" {
+		table, err := v.vschema.FindTable("", lookupTable)
+		if err != nil {
+			v.errorf("lookup table %s of vindex %s is not in the vschema", lookupTable, vindex)
+			return nil
+		}
+		lookupKeyspace = table.Keyspace.Name
+	}
+	var columns []string
+	for _, col := range strings.Split(params["from"], ",") {
+		columns = append(columns, strings.TrimSpace(col))
+	}
+	columns = append(columns, params["to"])
+	return v.checkTable(ctx, lookupKeyspace, lookupTable, columns)
+}
+
+// checkTable checks that a table and its columns exist.
+func (v *vschemaValidator) checkTable(ctx context.Context, keyspace, table string, columns []string) error {
+	tables, err := v.tables(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	def, ok := tables[table]
+	if !ok {
+		v.errorf("table %s does not exist in keyspace %s", table, keyspace)
+		return nil
+	}
+	fields := tableFields(def)
+	for _, col := range columns {
+		if _, ok := fields[strings.ToLower(col)]; !ok {
+			v.errorf("column %s does not exist in table %s.%s", col, keyspace, table)
+		}
+	}
+	return nil
+}
+
+// tables returns the tables of the keyspace, as seen by the primary of
+// its first shard. It returns nil if there's no such primary.
+func (v *vschemaValidator) tables(ctx context.Context, keyspace string) (map[string]*tabletmanagerdatapb.TableDefinition, error) {
+	if tables, ok := v.schemas[keyspace]; ok {
+		return tables, nil
+	}
+	shards, err := v.ts.GetShardNames(ctx, keyspace)
+	if err != nil && !topo.IsErrType(err, topo.NoNode) {
+		return nil, err
+	}
+	sort.Strings(shards)
+	var tables map[string]*tabletmanagerdatapb.TableDefinition
+	for _, shard := range shards {
+		si, err := v.ts.GetShard(ctx, keyspace, shard)
+		if err != nil {
+			return nil, err
+		}
+		if si.PrimaryAlias == nil {
+			continue
+		}
+		ti, err := v.ts.GetTablet(ctx, si.PrimaryAlias)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := v.tmc.GetSchema(ctx, ti.Tablet, []string{"/.*/"}, nil, true)
+		if err != nil {
+			return nil, err
+		}
+		tables = make(map[string]*tabletmanagerdatapb.TableDefinition, len(schema.TableDefinitions))
+		for _, td := range schema.TableDefinitions {
+			tables[td.Name] = td
+		}
+		break
+	}
+	v.schemas[keyspace] = tables
+	return tables, nil
+}
+
+// tableFields returns the fields of a table by lower case name. The
+// type of the fields is unknown if the tablet only returned names.
+func tableFields(td *tabletmanagerdatapb.TableDefinition) map[string]*querypb.Field {
+	fields := make(map[string]*querypb.Field, len(td.Columns))
+	for _, col := range td.Columns {
+		fields[strings.ToLower(col)] = &querypb.Field{Name: col}
+	}
+	for _, field := range td.Fields {
+		fields[strings.ToLower(field.Name)] = field
+	}
+	return fields
+}
+
+// vindexColumnType returns the kind of values a column vindex maps,
+// or an empty string if it can map any value.
+func vindexColumnType(cv *vindexes.ColumnVindex) string {
+	if cv.JSONPath != nil {
+		return "json"
+	}
+	switch cv.Vindex.(type) {
+	case *vindexes.Hash, *vindexes.Numeric, *vindexes.NumericStaticMap, *vindexes.ReverseBits, *vindexes.Tenant:
+		return "integral"
+	case *vindexes.Geo:
+		return "numeric"
+	}
+	return ""
+}
+
+func compatibleType(want string, typ querypb.Type) bool {
+	switch {
+	case typ == sqltypes.Null:
+		// The tablet didn't return the type.
+		return true
+	case want == "json":
+		return typ == sqltypes.TypeJSON
+	case want == "integral":
+		return sqltypes.IsIntegral(typ)
+	case want == "numeric":
+		return sqltypes.IsNumber(typ)
+	}
+	return true
+}
+
+// planRoutes plans the query, and returns the routes of the plan.
+func planRoutes(vschema *vindexes.VSchema, query *LoggedQuery) (string, error) {
+	stmt, reserved, err := sqlparser.Parse2(query.SQL)
+	if err != nil {
+		return "", err
+	}
+	keyspace, _, _, err := topoproto.ParseDestination(query.Keyspace, topodatapb.TabletType_PRIMARY)
+	if err != nil {
+		return "", err
+	}
+	result, err := sqlparser.RewriteAST(stmt, keyspace, sqlparser.SQLSelectLimitUnset)
+	if err != nil {
+		return "", err
+	}
+	cursor := &replayVSchema{vschema: vschema, keyspace: keyspace, version: planbuilder.V3}
+	plan, err := planbuilder.BuildFromStmt(query.SQL, result.AST, sqlparser.NewReservedVars("vtg", reserved), cursor, result.BindVarNeeds, true, true)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(describeRoutes(engine.PrimitiveToPlanDescription(plan.Instructions), nil), ", "), nil
+}
+
+// describeRoutes lists the primitives of a plan that are sent to a
+// keyspace, with their variant, which gives their shard fan-out.
+func describeRoutes(pd engine.PrimitiveDescription, routes []string) []string {
+	if pd.Keyspace != nil {
+		routes = append(routes, fmt.Sprintf("%s(%s.%s)", pd.OperatorType, pd.Keyspace.Name, pd.Variant))
+	}
+	for _, input := range pd.Inputs {
+		routes = describeRoutes(input, routes)
+	}
+	return routes
+}
+
+// ReadQueryLog reads a vtgate query log, in the text or the json
+// format, and returns the last sample distinct queries, oldest first.
+// Lines that can't be read are skipped.
+func ReadQueryLog(r io.Reader, sample int) ([]*LoggedQuery, error) {
+	var queries []*LoggedQuery
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		var query *LoggedQuery
+		if strings.HasPrefix(line, "{") {
+			query = &LoggedQuery{}
+			if err := json.Unmarshal([]byte(line), query); err != nil {
+				continue
+			}
+		} else {
+			// The SQL is the 13th field, and the keyspace the 4th
+			// before the end, because the bind variables of the
+			// text format can contain tabs.
+			fields := strings.Split(line, "\t")
+			if len(fields) < 20 {
+				continue
+			}
+			sql, err := strconv.Unquote(fields[12])
+			if err != nil {
+				continue
+			}
+			keyspace, err := strconv.Unquote(fields[len(fields)-4])
+			if err != nil {
+				continue
+			}
+			query = &LoggedQuery{SQL: sql, Keyspace: keyspace}
+		}
+		if query.SQL != "" {
+			queries = append(queries, query)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var sampled []*LoggedQuery
+	seen := make(map[LoggedQuery]bool)
+	for i := len(queries) - 1; i >= 0 && len(sampled) < sample; i-- {
+		if seen[*queries[i]] {
+			continue
+		}
+		seen[*queries[i]] = true
+		sampled = append(sampled, queries[i])
+	}
+	for i, j := 0, len(sampled)-1; i < j; i, j = i+1, j-1 {
+		sampled[i], sampled[j] = sampled[j], sampled[i]
+	}
+	return sampled, nil
+}
+
+// replayVSchema is the planbuilder.ContextVSchema used to plan
+// queries outside of vtgate.
+type replayVSchema struct {
+	vschema  *vindexes.VSchema
+	keyspace string
+	version  planbuilder.PlannerVersion
+}
+
+var _ planbuilder.ContextVSchema = (*replayVSchema)(nil)
+
+func (rv *replayVSchema) FindTable(name sqlparser.TableName) (*vindexes.Table, string, topodatapb.TabletType, key.Destination, error) {
+	destKeyspace, destTabletType, dest, err := topoproto.ParseDestination(name.Qualifier.String(), topodatapb.TabletType_PRIMARY)
+	if err != nil {
+		return nil, "", destTabletType, nil, err
+	}
+	if destKeyspace == "" {
+		destKeyspace = rv.keyspace
+	}
+	table, err := rv.vschema.FindTable(destKeyspace, name.Name.String())
+	if err != nil {
+		return nil, "", destTabletType, nil, err
+	}
+	return table, destKeyspace, destTabletType, dest, nil
+}
+
+func (rv *replayVSchema) FindTableOrVindex(name sqlparser.TableName) (*vindexes.Table, vindexes.Vindex, string, topodatapb.TabletType, key.Destination, error) {
+	destKeyspace, destTabletType, dest, err := topoproto.ParseDestination(name.Qualifier.String(), topodatapb.TabletType_PRIMARY)
+	if err != nil {
+		return nil, nil, "", destTabletType, nil, err
+	}
+	if destKeyspace == "" {
+		destKeyspace = rv.keyspace
+	}
+	table, vindex, err := rv.vschema.FindTableOrVindex(destKeyspace, name.Name.String(), destTabletType)
+	if err != nil {
+		return nil, nil, "", destTabletType, nil, err
+	}
+	return table, vindex, destKeyspace, destTabletType, dest, nil
+}
+
+func (rv *replayVSchema) DefaultKeyspace() (*vindexes.Keyspace, error) {
+	if rv.keyspace == "" {
+		return nil, vterrors.NewErrorf(vtrpcpb.Code_FAILED_PRECONDITION, vterrors.NoDB, "No database selected: use keyspace<:shard><@type> or keyspace<[range]><@type> (<> are optional)")
+	}
+	ks, ok := rv.vschema.Keyspaces[rv.keyspace]
+	if !ok {
+		return nil, vterrors.NewErrorf(vtrpcpb.Code_NOT_FOUND, vterrors.BadDb, "Unknown database '%s' in vschema", rv.keyspace)
+	}
+	return ks.Keyspace, nil
+}
+
+func (rv *replayVSchema) TargetString() string {
+	return rv.keyspace
+}
+
+func (rv *replayVSchema) Destination() key.Destination {
+	return nil
+}
+
+func (rv *replayVSchema) TabletType() topodatapb.TabletType {
+	return topodatapb.TabletType_PRIMARY
+}
+
+func (rv *replayVSchema) TargetDestination(qualifier string) (key.Destination, *vindexes.Keyspace, topodatapb.TabletType, error) {
+	keyspaceName := rv.keyspace
+	if qualifier != "" {
+		keyspaceName = qualifier
+	}
+	if keyspaceName == "" {
+		return nil, nil, 0, vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "keyspace not specified")
+	}
+	ks := rv.vschema.Keyspaces[keyspaceName]
+	if ks == nil {
+		return nil, nil, 0, vterrors.NewErrorf(vtrpcpb.Code_NOT_FOUND, vterrors.BadDb, "Unknown database '%s' in vschema", keyspaceName)
+	}
+	return nil, ks.Keyspace, topodatapb.TabletType_PRIMARY, nil
+}
+
+func (rv *replayVSchema) AnyKeyspace() (*vindexes.Keyspace, error) {
+	if ks, err := rv.DefaultKeyspace(); err == nil {
+		return ks, nil
+	}
+	return rv.FirstSortedKeyspace()
+}
+
+func (rv *replayVSchema) FirstSortedKeyspace() (*vindexes.Keyspace, error) {
+	if len(rv.vschema.Keyspaces) == 0 {
+		return nil, vterrors.NewErrorf(vtrpcpb.Code_FAILED_PRECONDITION, vterrors.NoDB, "no database available")
+	}
+	names := make([]string, 0, len(rv.vschema.Keyspaces))
+	for name := range rv.vschema.Keyspaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return rv.vschema.Keyspaces[names[0]].Keyspace, nil
+}
+
+func (rv *replayVSchema) SysVarSetEnabled() bool {
+	return true
+}
+
+func (rv *replayVSchema) KeyspaceExists(keyspace string) bool {
+	return rv.vschema.Keyspaces[keyspace] != nil
+}
+
+func (rv *replayVSchema) AllKeyspace() ([]*vindexes.Keyspace, error) {
+	var kss []*vindexes.Keyspace
+	for _, ks := range rv.vschema.Keyspaces {
+		kss = append(kss, ks.Keyspace)
+	}
+	return kss, nil
+}
+
+func (rv *replayVSchema) GetSemTable() *semantics.SemTable {
+	return nil
+}
+
+func (rv *replayVSchema) Planner() planbuilder.PlannerVersion {
+	return rv.version
+}
+
+func (rv *replayVSchema) SetPlannerVersion(version planbuilder.PlannerVersion) {
+	rv.version = version
+}
+
+func (rv *replayVSchema) ErrorIfShardedF(keyspace *vindexes.Keyspace, _, errFmt string, params ...interface{}) error {
+	if keyspace.Sharded {
+		return fmt.Errorf(errFmt, params...)
+	}
+	return nil
+}
+
+func (rv *replayVSchema) WarnUnshardedOnly(string, ...interface{}) {
+}
+
+func (rv *replayVSchema) PlannerWarning(string) {
+}
+
+func (rv *replayVSchema) ForeignKeyMode() string {
+	return "allow"
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schematools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver/testutil"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+// newTestVSchemaEnv creates a sharded keyspace ks with the users and orders
+// tables, and an unsharded keyspace main with a sequence and a lookup table.
+func newTestVSchemaEnv(t *testing.T) (*topo.Server, *testutil.TabletManagerClient) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{AlsoSetShardPrimary: true}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "ks",
+		Shard:    "-80",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+		Keyspace: "ks",
+		Shard:    "80-",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 300},
+		Keyspace: "main",
+		Shard:    "0",
+		Type:     topodatapb.TabletType_PRIMARY,
+	})
+
+	table := func(name string, fields ...*querypb.Field) *tabletmanagerdatapb.TableDefinition {
+		return &tabletmanagerdatapb.TableDefinition{Name: name, Fields: fields}
+	}
+	ksSchema := &tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+			table("users", &querypb.Field{Name: "id", Type: sqltypes.Int64}, &querypb.Field{Name: "name", Type: sqltypes.VarChar}),
+			table("orders", &querypb.Field{Name: "id", Type: sqltypes.Int64}, &querypb.Field{Name: "user_id", Type: sqltypes.Int64}),
+		},
+	}
+	mainSchema := &tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+			table("users_seq", &querypb.Field{Name: "id", Type: sqltypes.Int64}, &querypb.Field{Name: "next_id", Type: sqltypes.Int64}),
+			table("name_idx", &querypb.Field{Name: "name", Type: sqltypes.VarChar}, &querypb.Field{Name: "keyspace_id", Type: sqltypes.VarBinary}),
+		},
+	}
+	tmc := &testutil.TabletManagerClient{
+		GetSchemaResults: map[string]struct {
+			Schema *tabletmanagerdatapb.SchemaDefinition
+			Error  error
+		}{
+			"zone1-0000000100": {Schema: ksSchema},
+			"zone1-0000000200": {Schema: ksSchema},
+			"zone1-0000000300": {Schema: mainSchema},
+		},
+	}
+
+	err := ts.SaveVSchema(ctx, "main", &vschemapb.Keyspace{
+		Tables: map[string]*vschemapb.Table{
+			"users_seq": {Type: "sequence"},
+			"name_idx":  {},
+		},
+	})
+	require.NoError(t, err)
+	err = ts.SaveVSchema(ctx, "ks", &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {Type: "hash"},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"users": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "hash", Column: "id"}},
+			},
+			"orders": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "hash", Column: "user_id"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	return ts, tmc
+}
+
+func TestValidateVSchemaChange(t *testing.T) {
+	ts, tmc := newTestVSchemaEnv(t)
+
+	proposed := &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {Type: "hash"},
+			"name_lookup": {
+				Type:   "lookup_unique",
+				Params: map[string]string{"table": "main.name_idx", "from": "name", "to": "keyspace_id"},
+				Owner:  "users",
+			},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"users": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{
+					{Name: "hash", Column: "id"},
+					{Name: "name_lookup", Column: "name"},
+				},
+				AutoIncrement: &vschemapb.AutoIncrement{Column: "id", Sequence: "main.users_seq"},
+			},
+			"orders": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "hash", Column: "id"}},
+			},
+		},
+	}
+	queries := []*LoggedQuery{
+		{SQL: "select * from users where id = 1", Keyspace: "ks"},
+		{SQL: "select * from orders where user_id = 1", Keyspace: "ks"},
+		{SQL: "select * from orders where id = 1", Keyspace: "ks@replica"},
+		{SQL: "set @x = 1", Keyspace: "ks"},
+	}
+	validation, err := ValidateVSchemaChange(context.Background(), ts, tmc, "ks", proposed, queries)
+	require.NoError(t, err)
+	assert.Empty(t, validation.Errors)
+	assert.False(t, validation.HasFailures())
+	assert.Equal(t, 3, validation.Replayed)
+	assert.Equal(t, []*VSchemaPlanChange{{
+		Query:    "select * from orders where user_id = 1",
+		Keyspace: "ks",
+		Before:   "Route(ks.SelectEqualUnique)",
+		After:    "Route(ks.SelectScatter)",
+	}, {
+		Query:    "select * from orders where id = 1",
+		Keyspace: "ks@replica",
+		Before:   "Route(ks.SelectScatter)",
+		After:    "Route(ks.SelectEqualUnique)",
+	}}, validation.PlanChanges)
+
+	// Dropping a table makes its queries fail.
+	delete(proposed.Tables, "orders")
+	validation, err = ValidateVSchemaChange(context.Background(), ts, tmc, "ks", proposed, queries[1:2])
+	require.NoError(t, err)
+	assert.True(t, validation.HasFailures())
+	require.Len(t, validation.PlanChanges, 1)
+	assert.True(t, validation.PlanChanges[0].Failing)
+}
+
+func TestValidateVSchemaChangeErrors(t *testing.T) {
+	ts, tmc := newTestVSchemaEnv(t)
+
+	proposed := &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {Type: "hash"},
+			"name_lookup": {
+				Type:   "lookup_unique",
+				Params: map[string]string{"table": "main.name_idx", "from": "name", "to": "ksid"},
+				Owner:  "orders",
+			},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"users": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{
+					{Name: "hash", Column: "name"},
+					{Name: "name_lookup", Column: "name"},
+				},
+				AutoIncrement: &vschemapb.AutoIncrement{Column: "id", Sequence: "main.user_seq"},
+				Columns:       []*vschemapb.Column{{Name: "email"}},
+			},
+			"orders": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "hash", Column: "customer_id"}},
+			},
+			"customers": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "hash", Column: "id"}},
+			},
+		},
+	}
+	validation, err := ValidateVSchemaChange(context.Background(), ts, tmc, "ks", proposed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"table customers does not exist in keyspace ks",
+		"column customer_id of vindex hash does not exist in table orders",
+		"column email of table users does not exist",
+		"column name of table users has type varchar, vindex hash needs integral values",
+		"table user_seq does not exist in keyspace main",
+		"column ksid does not exist in table main.name_idx",
+		"owner table orders of vindex name_lookup does not use it",
+	}, validation.Errors)
+
+	// A vschema that can't be built is reported as is.
+	proposed.Vindexes["hash"].Type = "nohash"
+	validation, err = ValidateVSchemaChange(context.Background(), ts, tmc, "ks", proposed, nil)
+	require.NoError(t, err)
+	require.Len(t, validation.Errors, 1)
+	assert.Contains(t, validation.Errors[0], "vindexType \"nohash\" not found")
+}
+
+func TestReadQueryLog(t *testing.T) {
+	textLine := func(sql, bindVars, keyspace string) string {
+		fields := []string{
+			"Execute", "", "", "''", "''",
+			"2021-11-09 10:00:00.000000", "2021-11-09 10:00:00.001000",
+			"0.001", "0.000", "0.000", "0.001", "select", `"` + sql + `"`, bindVars,
+			"1", "1", `""`, `"` + keyspace + `"`, `""`, `"PRIMARY"`, "",
+		}
+		return strings.Join(fields, "\t")
+	}
+	log := strings.Join([]string{
+		textLine("select 1 from t1", "map[]", "ks"),
+		textLine("select 2 from t1", "map[a:type:INT64\tvalue:\"1\"]", "ks@replica"),
+		`{"Method": "Execute", "SQL": "select 3 from t1", "Keyspace": "ks"}`,
+		"not a query log line",
+		textLine("select 1 from t1", "map[]", "ks"),
+		`{"Method": "Execute", "SQL": "select 4 from t1", "Keyspace": ""}`,
+	}, "\n")
+
+	queries, err := ReadQueryLog(strings.NewReader(log), 3)
+	require.NoError(t, err)
+	assert.Equal(t, []*LoggedQuery{
+		{SQL: "select 3 from t1", Keyspace: "ks"},
+		{SQL: "select 1 from t1", Keyspace: "ks"},
+		{SQL: "select 4 from t1"},
+	}, queries)
+
+	queries, err = ReadQueryLog(strings.NewReader(log), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []*LoggedQuery{
+		{SQL: "select 2 from t1", Keyspace: "ks@replica"},
+		{SQL: "select 3 from t1", Keyspace: "ks"},
+		{SQL: "select 1 from t1", Keyspace: "ks"},
+		{SQL: "select 4 from t1"},
+	}, queries)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctl
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"vitess.io/vitess/go/vt/wrangler"
+)
+
+// This file contains the distributed transaction commands for vtctl.
+
+func init() {
+	addCommand("Keyspaces", command{
+		name:   "ListDistributedTransactions",
+		method: commandListDistributedTransactions,
+		params: "<keyspace>",
+		help:   "Lists the unresolved distributed transactions of all the shards of a keyspace, and the transactions prepared on them, as JSON.",
+	})
+	addCommand("Keyspaces", command{
+		name:   "GetDistributedTransaction",
+		method: commandGetDistributedTransaction,
+		params: "<dtid>",
+		help:   "Outputs a JSON structure that contains the state and the participants of a distributed transaction.",
+	})
+	addCommand("Keyspaces", command{
+		name:   "ResolveDistributedTransaction",
+		method: commandResolveDistributedTransaction,
+		params: "-server <vtgate> [-abandon_age <duration>] [-force] <dtid>",
+		help:   "Asks the vtgate coordinator to resolve an abandoned distributed transaction. It is committed on all participants if the commit decision was recorded, and rolled back otherwise. Transactions younger than -abandon_age are refused unless -force is set.",
+	})
+}
+
+func commandListDistributedTransactions(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace> argument is required for the ListDistributedTransactions command")
+	}
+
+	transactions, err := wr.ListDistributedTransactions(ctx, subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return printJSON(wr.Logger(), transactions)
+}
+
+func commandGetDistributedTransaction(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <dtid> argument is required for the GetDistributedTransaction command")
+	}
+
+	transaction, err := wr.GetDistributedTransaction(ctx, subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return printJSON(wr.Logger(), transaction)
+}
+
+func commandResolveDistributedTransaction(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	server := subFlags.String("server", "", "VtGate server to connect to")
+	abandonAge := subFlags.Duration("abandon_age", 5*time.Minute, "Age after which a transaction is abandoned. It should not be lower than the -twopc_abandon_age of the tablets.")
+	force := subFlags.Bool("force", false, "Resolve the transaction even if it is not abandoned yet")
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <dtid> argument is required for the ResolveDistributedTransaction command")
+	}
+	if *server == "" {
+		return fmt.Errorf("the -server flag is required for the ResolveDistributedTransaction command")
+	}
+
+	return wr.ResolveDistributedTransaction(ctx, *server, subFlags.Arg(0), *abandonAge, *force)
+}
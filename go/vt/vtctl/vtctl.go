@@ -113,6 +113,7 @@ import (
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/topotools"
+	"vitess.io/vitess/go/vt/vtctl/schematools"
 	"vitess.io/vitess/go/vt/vtctl/workflow"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/wrangler"
@@ -441,6 +442,12 @@ var commands = []commandGroup{
 				params: "[-source=<source keyspace name>] [-remove] [-cells=c1,c2,...] <keyspace name> <tablet type>",
 				help:   "Changes the ServedFromMap manually. This command is intended for emergency fixes. This field is automatically set when you call the *MigrateServedFrom* command. This command does not rebuild the serving graph.",
 			},
+			{
+				name:   "SetKeyspaceBackupSchedule",
+				method: commandSetKeyspaceBackupSchedule,
+				params: "[-tablet_types=<tablet types>] [-backup_engine=<engine>] [-min_retention_time=<duration>] [-min_retention_count=1] [-remove] <keyspace name> [<cron expression>]",
+				help:   "Sets the schedule of the backups that vtctld takes of the shards of a keyspace, when it runs with -enable_backup_scheduler. The cron expression is a standard one with 5 fields, in UTC, e.g. '0 3 * * *' to back up every shard every day at 3am. With -remove, the schedule is removed instead.",
+			},
 			{
 				name:   "RebuildKeyspaceGraph",
 				method: commandRebuildKeyspaceGraph,
@@ -495,6 +502,33 @@ var commands = []commandGroup{
 				params: `[-cells=<cells>] [-tablet_types=<source_tablet_types>] <json_spec>, example : '{"workflow": "aaa", "source_keyspace": "source", "target_keyspace": "target", "table_settings": [{"target_table": "customer", "source_expression": "select * from customer", "create_ddl": "copy"}]}'`,
 				help:   "Performs materialization based on the json spec. Is used directly to form VReplication rules, with an optional step to copy table structure/DDL.",
 			},
+			{
+				name:   "RestoreTable",
+				method: commandRestoreTable,
+				params: "[-cells=<cells>] [-tablet_types=<source_tablet_types>] [-workflow=<workflow>] [-base_keyspace=<keyspace> -snapshot_time=<time>] [-wait_for_restore=<duration>] [-target_table=<table>] [-filter=<where_clause>] <snapshot_keyspace>.<table>",
+				help: `Restores a table from a backup into a temporary SNAPSHOT keyspace, and copies it back into its base keyspace using a Materialize workflow that stops after the copy.
+With -base_keyspace and -snapshot_time (RFC3339), the SNAPSHOT keyspace is created if it doesn't exist, like 'CreateKeyspace -keyspace_type=SNAPSHOT' does.
+This command doesn't start the tablets of the SNAPSHOT keyspace: start them with -init_keyspace=<snapshot_keyspace> -restore_from_backup -restore_from_backup_ts=<time>, and with -restore_tables=<table> to only restore the table of a logical backup. The command waits up to -wait_for_restore for one of them to restore the table, and logs these flags while it waits.
+-target_table is the name of the table in the base keyspace, the original name by default. It is created if it doesn't exist, and should be empty otherwise.
+-filter is a WHERE clause that selects the rows to copy, all of them by default. Example: 'created_at < "2021-06-01"'.
+Once the copy is done, delete the workflow, which is restore_<target_table> by default, the tablets of the SNAPSHOT keyspace and the keyspace.`,
+			},
+			{
+				name:   "MoveTenant",
+				method: commandMoveTenant,
+				params: "[-cells=<cells>] [-tablet_types=<source_tablet_types>] -keyrange=<keyrange> <action> <keyspace>.<vindex> <tenant_id>",
+				help: `Moves the rows of a tenant of a tenant vindex to the shard that owns keyrange, and pins the tenant to keyrange.
+Action must be one of the following: Create, Complete, Cancel.
+Create: starts copying the rows of the tenant to the target shard.
+Complete: pins the tenant to keyrange in the mapping table, and deletes its rows from the other shards. Writes to the tenant should be paused first.
+Cancel: stops the copy, and deletes the copied rows from the target shard.`,
+			},
+			{
+				name:   "RedriveMessages",
+				method: commandRedriveMessages,
+				params: "[-ids=<id1,id2,...>] <keyspace>.<message_table>",
+				help:   "Moves the messages of a message table back from its dead letter table on all the shards, so that they're sent again with a fresh number of attempts. If -ids is set, only those messages are moved.",
+			},
 			{
 				name:       "SplitClone",
 				method:     commandSplitClone,
@@ -713,6 +747,12 @@ var commands = []commandGroup{
 				params: "{-vschema=<vschema> || -vschema_file=<vschema file> || -sql=<sql> || -sql_file=<sql file>} [-cells=c1,c2,...] [-skip_rebuild] [-dry-run] <keyspace>",
 				help:   "Applies the VTGate routing schema to the provided keyspace. Shows the result after application.",
 			},
+			{
+				name:   "ValidateVSchemaChange",
+				method: commandValidateVSchemaChange,
+				params: "{-vschema=<vschema> || -vschema_file=<vschema file> || -sql=<sql> || -sql_file=<sql file>} [-query_log=<query log file>] [-sample=<count>] <keyspace>",
+				help:   "Validates a VTGate routing schema for the provided keyspace against the schemas of its tablets, without applying it. If a vtgate query log is provided, its most recent queries are planned with the current and the proposed routing schemas, and the queries whose routing changes or that start failing are reported.",
+			},
 			{
 				name:   "GetRoutingRules",
 				method: commandGetRoutingRules,
@@ -2150,6 +2190,43 @@ func commandSetKeyspaceServedFrom(ctx context.Context, wr *wrangler.Wrangler, su
 	return err
 }
 
+func commandSetKeyspaceBackupSchedule(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	tabletTypes := subFlags.String("tablet_types", "", "Comma-separated list of the tablet types to take backups from, in order of preference. REPLICA then RDONLY if empty.")
+	backupEngine := subFlags.String("backup_engine", "", "The backup engine to use, builtin, xtrabackup or logical. The -backup_engine_implementation of the tablets is used if empty.")
+	minRetentionTime := subFlags.Duration("min_retention_time", 0, "Remove the backups older than this after each scheduled backup. No backup is removed if 0.")
+	minRetentionCount := subFlags.Int("min_retention_count", 1, "Always keep at least this many of the most recent backups, even if some are older than -min_retention_time.")
+	remove := subFlags.Bool("remove", false, "Removes the backup schedule of the keyspace.")
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if *remove {
+		if subFlags.NArg() != 1 {
+			return fmt.Errorf("the <keyspace name> argument is required for the SetKeyspaceBackupSchedule command with -remove")
+		}
+		return wr.SetKeyspaceBackupSchedule(ctx, subFlags.Arg(0), nil)
+	}
+	if subFlags.NArg() != 2 {
+		return fmt.Errorf("the <keyspace name> and <cron expression> arguments are required for the SetKeyspaceBackupSchedule command")
+	}
+
+	schedule := &topodatapb.BackupSchedule{
+		Cron:              subFlags.Arg(1),
+		BackupEngine:      *backupEngine,
+		MinRetentionCount: int32(*minRetentionCount),
+	}
+	if *tabletTypes != "" {
+		tts, err := topoproto.ParseTabletTypes(*tabletTypes)
+		if err != nil {
+			return err
+		}
+		schedule.TabletTypes = tts
+	}
+	if *minRetentionTime != 0 {
+		schedule.MinRetentionTime = protoutil.DurationToProto(*minRetentionTime)
+	}
+	return wr.SetKeyspaceBackupSchedule(ctx, subFlags.Arg(0), schedule)
+}
+
 func commandRebuildKeyspaceGraph(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
 	cells := subFlags.String("cells", "", "Specifies a comma-separated list of cells to update")
 	allowPartial := subFlags.Bool("allow_partial", false, "Specifies whether a SNAPSHOT keyspace is allowed to serve with an incomplete set of shards. Ignored for all other types of keyspaces")
@@ -2672,6 +2749,63 @@ func commandExternalizeVindex(ctx context.Context, wr *wrangler.Wrangler, subFla
 	return wr.ExternalizeVindex(ctx, subFlags.Arg(0))
 }
 
+func commandMoveTenant(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	cells := subFlags.String("cells", "", "Source cells to replicate from.")
+	tabletTypes := subFlags.String("tablet_types", "", "Source tablet types to replicate from.")
+	keyRange := subFlags.String("keyrange", "", "Key range to pin the tenant to, written like a shard name, e.g. 80-c0.")
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 3 {
+		return fmt.Errorf("three arguments are required: <action> <keyspace>.<vindex> <tenant_id>")
+	}
+	if *keyRange == "" {
+		return fmt.Errorf("-keyrange is required")
+	}
+	action := subFlags.Arg(0)
+	splits := strings.Split(subFlags.Arg(1), ".")
+	if len(splits) != 2 {
+		return fmt.Errorf("vindex name should be of the form keyspace.vindex: %s", subFlags.Arg(1))
+	}
+	tenantID, err := strconv.ParseUint(subFlags.Arg(2), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid tenant id %s: %v", subFlags.Arg(2), err)
+	}
+	switch strings.ToLower(action) {
+	case "create":
+		return wr.MoveTenant(ctx, splits[0], splits[1], tenantID, *keyRange, *cells, *tabletTypes)
+	case "complete":
+		return wr.CompleteMoveTenant(ctx, splits[0], splits[1], tenantID, *keyRange)
+	case "cancel":
+		return wr.CancelMoveTenant(ctx, splits[0], splits[1], tenantID, *keyRange)
+	default:
+		return fmt.Errorf("unknown action %s, must be one of Create, Complete, Cancel", action)
+	}
+}
+
+func commandRedriveMessages(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	ids := subFlags.String("ids", "", "Comma-separated list of the ids of the messages to redrive. All the dead lettered messages are redriven if empty.")
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace>.<message_table> argument is required for the RedriveMessages command")
+	}
+	splits := strings.Split(subFlags.Arg(0), ".")
+	if len(splits) != 2 {
+		return fmt.Errorf("message table should be of the form keyspace.table: %s", subFlags.Arg(0))
+	}
+	var idList []string
+	if *ids != "" {
+		idList = strings.Split(*ids, ",")
+	}
+	counts, err := wr.RedriveMessages(ctx, splits[0], splits[1], idList)
+	if err != nil {
+		return err
+	}
+	return printJSON(wr.Logger(), counts)
+}
+
 func commandMaterialize(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
 	cells := subFlags.String("cells", "", "Source cells to replicate from.")
 	tabletTypes := subFlags.String("tablet_types", "", "Source tablet types to replicate from.")
@@ -2690,6 +2824,35 @@ func commandMaterialize(ctx context.Context, wr *wrangler.Wrangler, subFlags *fl
 	return wr.Materialize(ctx, ms)
 }
 
+func commandRestoreTable(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	cells := subFlags.String("cells", "", "Source cells to replicate from.")
+	tabletTypes := subFlags.String("tablet_types", "", "Source tablet types to replicate from.")
+	workflow := subFlags.String("workflow", "", "Name of the workflow, restore_<target_table> by default.")
+	targetTable := subFlags.String("target_table", "", "Name of the table in the base keyspace, the original name by default.")
+	filter := subFlags.String("filter", "", "WHERE clause selecting the rows to copy, all of them by default.")
+	baseKeyspace := subFlags.String("base_keyspace", "", "Keyspace of the backup, to create the SNAPSHOT keyspace if it doesn't exist.")
+	timestampStr := subFlags.String("snapshot_time", "", "Time to restore the backup to (RFC3339), to create the SNAPSHOT keyspace if it doesn't exist.")
+	waitForRestore := subFlags.Duration("wait_for_restore", 0, "How long to wait for the tablets of the SNAPSHOT keyspace to restore the table.")
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <snapshot_keyspace>.<table> argument is required for the RestoreTable command")
+	}
+	splits := strings.Split(subFlags.Arg(0), ".")
+	if len(splits) != 2 {
+		return fmt.Errorf("table should be of the form keyspace.table: %s", subFlags.Arg(0))
+	}
+	var snapshotTime time.Time
+	if *timestampStr != "" {
+		var err error
+		if snapshotTime, err = time.Parse(time.RFC3339, *timestampStr); err != nil {
+			return err
+		}
+	}
+	return wr.RestoreTable(ctx, *workflow, splits[0], *baseKeyspace, snapshotTime, splits[1], *targetTable, *filter, *cells, *tabletTypes, *waitForRestore)
+}
+
 func commandSplitClone(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
 	if err := subFlags.Parse(args); err != nil {
 		return err
@@ -3568,36 +3731,74 @@ func commandApplyVSchema(ctx context.Context, wr *wrangler.Wrangler, subFlags *f
 	}
 	keyspace := subFlags.Arg(0)
 
+	vs, err := readVSchema(ctx, wr, keyspace, "ApplyVSchema", *vschema, *vschemaFile, *sql, *sqlFile)
+	if err != nil {
+		return err
+	}
+
+	b, err := json2.MarshalIndentPB(vs, "  ")
+	if err != nil {
+		wr.Logger().Errorf2(err, "Failed to marshal VSchema for display")
+	} else {
+		wr.Logger().Printf("New VSchema object:\n%s\nIf this is not what you expected, check the input data (as JSON parsing will skip unexpected fields).\n", b)
+	}
+
+	if *dryRun {
+		wr.Logger().Printf("Dry run: Skipping update of VSchema\n")
+		return nil
+	}
+
+	if _, err := wr.TopoServer().GetKeyspace(ctx, keyspace); err != nil {
+		if strings.Contains(err.Error(), "node doesn't exist") {
+			return fmt.Errorf("keyspace(%s) doesn't exist, check if the keyspace is initialized", keyspace)
+		}
+		return err
+	}
+
+	if err := wr.TopoServer().SaveVSchema(ctx, keyspace, vs); err != nil {
+		return err
+	}
+
+	if *skipRebuild {
+		wr.Logger().Warningf("Skipping rebuild of SrvVSchema, will need to run RebuildVSchemaGraph for changes to take effect")
+		return nil
+	}
+	return wr.TopoServer().RebuildSrvVSchema(ctx, cells)
+}
+
+// readVSchema reads the vschema of a keyspace from the vschema, vschema_file,
+// sql or sql_file flags. The vschema ddl statements are applied to the
+// current vschema of the keyspace.
+func readVSchema(ctx context.Context, wr *wrangler.Wrangler, keyspace, command, vschema, vschemaFile, sql, sqlFile string) (*vschemapb.Keyspace, error) {
 	var vs *vschemapb.Keyspace
-	var err error
 
-	sqlMode := (*sql != "") != (*sqlFile != "")
-	jsonMode := (*vschema != "") != (*vschemaFile != "")
+	sqlMode := (sql != "") != (sqlFile != "")
+	jsonMode := (vschema != "") != (vschemaFile != "")
 
 	if sqlMode && jsonMode {
-		return fmt.Errorf("only one of the sql, sql_file, vschema, or vschema_file flags may be specified when calling the ApplyVSchema command")
+		return nil, fmt.Errorf("only one of the sql, sql_file, vschema, or vschema_file flags may be specified when calling the %s command", command)
 	}
 
 	if !sqlMode && !jsonMode {
-		return fmt.Errorf("one of the sql, sql_file, vschema, or vschema_file flags must be specified when calling the ApplyVSchema command")
+		return nil, fmt.Errorf("one of the sql, sql_file, vschema, or vschema_file flags must be specified when calling the %s command", command)
 	}
 
 	if sqlMode {
-		if *sqlFile != "" {
-			sqlBytes, err := os.ReadFile(*sqlFile)
+		if sqlFile != "" {
+			sqlBytes, err := os.ReadFile(sqlFile)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			*sql = string(sqlBytes)
+			sql = string(sqlBytes)
 		}
 
-		stmt, err := sqlparser.Parse(*sql)
+		stmt, err := sqlparser.Parse(sql)
 		if err != nil {
-			return fmt.Errorf("error parsing vschema statement `%s`: %v", *sql, err)
+			return nil, fmt.Errorf("error parsing vschema statement `%s`: %v", sql, err)
 		}
 		ddl, ok := stmt.(*sqlparser.AlterVschema)
 		if !ok {
-			return fmt.Errorf("error parsing vschema statement `%s`: not a ddl statement", *sql)
+			return nil, fmt.Errorf("error parsing vschema statement `%s`: not a ddl statement", sql)
 		}
 
 		vs, err = wr.TopoServer().GetVSchema(ctx, keyspace)
@@ -3605,63 +3806,89 @@ func commandApplyVSchema(ctx context.Context, wr *wrangler.Wrangler, subFlags *f
 			if topo.IsErrType(err, topo.NoNode) {
 				vs = &vschemapb.Keyspace{}
 			} else {
-				return err
+				return nil, err
 			}
 		}
 
 		vs, err = topotools.ApplyVSchemaDDL(keyspace, vs, ddl)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 	} else {
 		// json mode
 		var schema []byte
-		if *vschemaFile != "" {
+		if vschemaFile != "" {
 			var err error
-			schema, err = os.ReadFile(*vschemaFile)
+			schema, err = os.ReadFile(vschemaFile)
 			if err != nil {
-				return err
+				return nil, err
 			}
 		} else {
-			schema = []byte(*vschema)
+			schema = []byte(vschema)
 		}
 
 		vs = &vschemapb.Keyspace{}
 		err := json2.Unmarshal(schema, vs)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	b, err := json2.MarshalIndentPB(vs, "  ")
-	if err != nil {
-		wr.Logger().Errorf2(err, "Failed to marshal VSchema for display")
-	} else {
-		wr.Logger().Printf("New VSchema object:\n%s\nIf this is not what you expected, check the input data (as JSON parsing will skip unexpected fields).\n", b)
-	}
+	return vs, nil
+}
 
-	if *dryRun {
-		wr.Logger().Printf("Dry run: Skipping update of VSchema\n")
-		return nil
-	}
+func commandValidateVSchemaChange(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
+	vschema := subFlags.String("vschema", "", "Identifies the VTGate routing schema")
+	vschemaFile := subFlags.String("vschema_file", "", "Identifies the VTGate routing schema file")
+	sql := subFlags.String("sql", "", "A vschema ddl SQL statement (e.g. `add vindex`, `alter table t add vindex hash(id)`, etc)")
+	sqlFile := subFlags.String("sql_file", "", "A vschema ddl SQL statement (e.g. `add vindex`, `alter table t add vindex hash(id)`, etc)")
+	queryLog := subFlags.String("query_log", "", "A vtgate query log, in the text or json format, whose queries are planned with the current and the proposed routing schemas")
+	sample := subFlags.Int("sample", 1000, "The number of distinct queries to plan, starting from the most recent ones of the query log")
 
-	if _, err := wr.TopoServer().GetKeyspace(ctx, keyspace); err != nil {
-		if strings.Contains(err.Error(), "node doesn't exist") {
-			return fmt.Errorf("keyspace(%s) doesn't exist, check if the keyspace is initialized", keyspace)
-		}
+	if err := subFlags.Parse(args); err != nil {
 		return err
 	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("the <keyspace> argument is required for the ValidateVSchemaChange command")
+	}
+	keyspace := subFlags.Arg(0)
 
-	if err := wr.TopoServer().SaveVSchema(ctx, keyspace, vs); err != nil {
+	vs, err := readVSchema(ctx, wr, keyspace, "ValidateVSchemaChange", *vschema, *vschemaFile, *sql, *sqlFile)
+	if err != nil {
 		return err
 	}
+	var queries []*schematools.LoggedQuery
+	if *queryLog != "" {
+		f, err := os.Open(*queryLog)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if queries, err = schematools.ReadQueryLog(f, *sample); err != nil {
+			return err
+		}
+	}
 
-	if *skipRebuild {
-		wr.Logger().Warningf("Skipping rebuild of SrvVSchema, will need to run RebuildVSchemaGraph for changes to take effect")
-		return nil
+	validation, err := schematools.ValidateVSchemaChange(ctx, wr.TopoServer(), wr.TabletManagerClient(), keyspace, vs, queries)
+	if err != nil {
+		return err
 	}
-	return wr.TopoServer().RebuildSrvVSchema(ctx, cells)
+	for _, verr := range validation.Errors {
+		wr.Logger().Printf("Error: %s\n", verr)
+	}
+	for _, change := range validation.PlanChanges {
+		status := "Changed"
+		if change.Failing {
+			status = "Failing"
+		}
+		wr.Logger().Printf("%s: %s\n  before: %s\n  after:  %s\n", status, change.Query, change.Before, change.After)
+	}
+	wr.Logger().Printf("%d errors, %d of %d planned queries changed\n", len(validation.Errors), len(validation.PlanChanges), validation.Replayed)
+	if validation.HasFailures() {
+		return fmt.Errorf("the VSchema of keyspace %s is not valid", keyspace)
+	}
+	return nil
 }
 
 func commandApplyRoutingRules(ctx context.Context, wr *wrangler.Wrangler, subFlags *flag.FlagSet, args []string) error {
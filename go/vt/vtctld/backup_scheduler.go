@@ -0,0 +1,338 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+	"vitess.io/vitess/go/vt/wrangler"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// This file runs the backups scheduled with SetKeyspaceBackupSchedule.
+
+var (
+	enableBackupScheduler        = flag.Bool("enable_backup_scheduler", false, "If set, vtctld backs up the shards of the keyspaces that have a backup schedule, see SetKeyspaceBackupSchedule")
+	backupSchedulerCheckInterval = flag.Duration("backup_scheduler_check_interval", time.Minute, "How often vtctld checks whether a scheduled backup is due")
+	backupSchedulerConcurrency   = flag.Int("backup_scheduler_concurrency", 4, "The number of compression/checksum jobs scheduled backups run simultaneously")
+
+	scheduledBackups      = stats.NewCountersWithSingleLabel("ScheduledBackups", "Number of backups started by the backup scheduler", "KeyspaceShard")
+	scheduledBackupErrors = stats.NewCountersWithSingleLabel("ScheduledBackupErrors", "Number of scheduled backups that failed", "KeyspaceShard")
+)
+
+// backupSchedulerLockPath is the directory of the global topo that is locked
+// while a shard is being backed up, as <path>/<keyspace>/<shard>, so two
+// vtctlds don't back up the same shard at the same time. The shard itself
+// isn't locked, since that would block reparents during the backup.
+const backupSchedulerLockPath = "backup_scheduler"
+
+func initBackupScheduler(ts *topo.Server) {
+	if !*enableBackupScheduler {
+		return
+	}
+	bs := newBackupScheduler(ts, tmclient.NewTabletManagerClient())
+	checkTicks := timer.NewTimer(*backupSchedulerCheckInterval)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	checkTicks.Start(func() { bs.check(ctx) })
+
+	servenv.OnTermSync(func() {
+		checkTicks.Stop()
+		cancel()
+		bs.wg.Wait()
+	})
+}
+
+// backupScheduler backs up the shards of the keyspaces that have a
+// BackupSchedule, when it is due.
+type backupScheduler struct {
+	ts  *topo.Server
+	tmc tmclient.TabletManagerClient
+
+	// now is time.Now, except in tests. The times are compared in UTC,
+	// like the schedules and the names of the backups.
+	now func() time.Time
+
+	wg sync.WaitGroup
+
+	// mu protects the fields below.
+	mu sync.Mutex
+	// running are the shards this vtctld is backing up, as keyspace/shard.
+	running map[string]bool
+	// lastAttempts are the start times of the last backups of the shards
+	// by this vtctld, so failed ones are only retried at the next
+	// scheduled time.
+	lastAttempts map[string]time.Time
+}
+
+func newBackupScheduler(ts *topo.Server, tmc tmclient.TabletManagerClient) *backupScheduler {
+	return &backupScheduler{
+		ts:           ts,
+		tmc:          tmc,
+		now:          time.Now,
+		running:      make(map[string]bool),
+		lastAttempts: make(map[string]time.Time),
+	}
+}
+
+// check starts the backups that are due, in the background.
+func (bs *backupScheduler) check(ctx context.Context) {
+	keyspaces, err := bs.ts.GetKeyspaces(ctx)
+	if err != nil {
+		log.Errorf("backupScheduler: can't get keyspaces: %v", err)
+		return
+	}
+	for _, keyspace := range keyspaces {
+		ki, err := bs.ts.GetKeyspace(ctx, keyspace)
+		if err != nil {
+			log.Errorf("backupScheduler: can't get keyspace %v: %v", keyspace, err)
+			continue
+		}
+		if ki.BackupSchedule == nil {
+			continue
+		}
+		schedule, err := wrangler.ParseBackupSchedule(ki.BackupSchedule.Cron)
+		if err != nil {
+			log.Errorf("backupScheduler: invalid cron expression %q for keyspace %v: %v", ki.BackupSchedule.Cron, keyspace, err)
+			continue
+		}
+		shards, err := bs.ts.GetShardNames(ctx, keyspace)
+		if err != nil {
+			log.Errorf("backupScheduler: can't get shards of keyspace %v: %v", keyspace, err)
+			continue
+		}
+		for _, shard := range shards {
+			bs.checkShard(ctx, keyspace, shard, ki.BackupSchedule, schedule)
+		}
+	}
+}
+
+func (bs *backupScheduler) checkShard(ctx context.Context, keyspace, shard string, bsc *topodatapb.BackupSchedule, schedule cron.Schedule) {
+	keyspaceShard := topoproto.KeyspaceShardString(keyspace, shard)
+	bs.mu.Lock()
+	running := bs.running[keyspaceShard]
+	bs.mu.Unlock()
+	if running {
+		return
+	}
+	// The backup storage is read without holding mu, since it can be slow.
+	due, err := bs.isDue(ctx, keyspace, shard, schedule)
+	if err != nil {
+		log.Errorf("backupScheduler: can't check the backups of %v: %v", keyspaceShard, err)
+		return
+	}
+	if !due {
+		return
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.running[keyspaceShard] {
+		return
+	}
+	bs.running[keyspaceShard] = true
+	bs.lastAttempts[keyspaceShard] = bs.now().UTC()
+	bs.wg.Add(1)
+	go func() {
+		defer func() {
+			bs.mu.Lock()
+			delete(bs.running, keyspaceShard)
+			bs.mu.Unlock()
+			bs.wg.Done()
+		}()
+		if err := bs.backupShard(ctx, keyspace, shard, bsc, schedule); err != nil {
+			log.Errorf("backupScheduler: scheduled backup of %v failed: %v", keyspaceShard, err)
+			scheduledBackupErrors.Add(keyspaceShard, 1)
+		}
+	}()
+}
+
+// isDue returns true if the scheduled time following the last complete
+// backup of the shard, or the last attempt of this vtctld, has passed. A
+// shard that was never backed up is due right away.
+func (bs *backupScheduler) isDue(ctx context.Context, keyspace, shard string, schedule cron.Schedule) (bool, error) {
+	last, err := lastCompleteBackupTime(ctx, keyspace, shard)
+	if err != nil {
+		return false, err
+	}
+	bs.mu.Lock()
+	attempt := bs.lastAttempts[topoproto.KeyspaceShardString(keyspace, shard)]
+	bs.mu.Unlock()
+	if attempt.After(last) {
+		last = attempt
+	}
+	if last.IsZero() {
+		return true, nil
+	}
+	return !schedule.Next(last).After(bs.now()), nil
+}
+
+// backupShard takes a backup of the shard, unless another vtctld just did,
+// and prunes the old backups.
+func (bs *backupScheduler) backupShard(ctx context.Context, keyspace, shard string, bsc *topodatapb.BackupSchedule, schedule cron.Schedule) error {
+	conn, err := bs.ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return err
+	}
+	lockPath := path.Join(backupSchedulerLockPath, keyspace, shard)
+	// Locks can only be taken on directories that exist.
+	if _, err := conn.Create(ctx, path.Join(lockPath, "sentry"), []byte{}); err != nil && !topo.IsErrType(err, topo.NodeExists) {
+		return err
+	}
+	lockDescriptor, err := conn.Lock(ctx, lockPath, "vtctld backup scheduler")
+	if err != nil {
+		return err
+	}
+	defer lockDescriptor.Unlock(context.Background())
+
+	// Now that we hold the lock, check that another vtctld didn't take the
+	// backup in the meantime.
+	last, err := lastCompleteBackupTime(ctx, keyspace, shard)
+	if err != nil {
+		return err
+	}
+	if !last.IsZero() && schedule.Next(last).After(bs.now()) {
+		log.Infof("backupScheduler: %v/%v was backed up at %v, skipping", keyspace, shard, last)
+		return nil
+	}
+
+	tablet, err := bs.pickTablet(ctx, keyspace, shard, bsc.TabletTypes)
+	if err != nil {
+		return err
+	}
+	log.Infof("backupScheduler: backing up %v/%v on tablet %v", keyspace, shard, topoproto.TabletAliasString(tablet.Alias))
+	scheduledBackups.Add(topoproto.KeyspaceShardString(keyspace, shard), 1)
+	stream, err := bs.tmc.Backup(ctx, tablet, &tabletmanagerdatapb.BackupRequest{
+		Concurrency:  int64(*backupSchedulerConcurrency),
+		BackupEngine: bsc.BackupEngine,
+	})
+	if err != nil {
+		return err
+	}
+	logger := logutil.NewConsoleLogger()
+	for {
+		e, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		logutil.LogEvent(logger, e)
+	}
+
+	minRetentionTime, _, err := protoutil.DurationFromProto(bsc.MinRetentionTime)
+	if err != nil {
+		return err
+	}
+	storage, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+	return mysqlctl.PruneBackups(ctx, storage, keyspace, shard, minRetentionTime, int(bsc.MinRetentionCount))
+}
+
+// pickTablet returns the replicating tablet of the shard with the least
+// replication lag, among the ones of the first of tabletTypes that has
+// one. tabletTypes are REPLICA then RDONLY if empty.
+func (bs *backupScheduler) pickTablet(ctx context.Context, keyspace, shard string, tabletTypes []topodatapb.TabletType) (*topodatapb.Tablet, error) {
+	if len(tabletTypes) == 0 {
+		tabletTypes = []topodatapb.TabletType{topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY}
+	}
+	tablets, err := bs.ts.GetTabletMapForShard(ctx, keyspace, shard)
+	if err != nil {
+		return nil, err
+	}
+	for _, tabletType := range tabletTypes {
+		var best *topodatapb.Tablet
+		var bestLag uint32
+		for _, ti := range tablets {
+			if ti.Type != tabletType {
+				continue
+			}
+			status, err := bs.tmc.ReplicationStatus(ctx, ti.Tablet)
+			if err != nil {
+				log.Warningf("backupScheduler: skipping tablet %v: %v", ti.AliasString(), err)
+				continue
+			}
+			if !status.IoThreadRunning || !status.SqlThreadRunning {
+				log.Warningf("backupScheduler: skipping tablet %v, it isn't replicating", ti.AliasString())
+				continue
+			}
+			if best == nil || status.ReplicationLagSeconds < bestLag {
+				best = ti.Tablet
+				bestLag = status.ReplicationLagSeconds
+			}
+		}
+		if best != nil {
+			return best, nil
+		}
+	}
+	return nil, fmt.Errorf("no healthy tablet of type %v to back up %v/%v", tabletTypes, keyspace, shard)
+}
+
+// lastCompleteBackupTime returns the time of the most recent complete
+// backup of the shard, or zero if there is none.
+func lastCompleteBackupTime(ctx context.Context, keyspace, shard string) (time.Time, error) {
+	storage, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer storage.Close()
+	backupDir := mysqlctl.GetBackupDir(keyspace, shard)
+	backups, err := storage.ListBackups(ctx, backupDir)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// Backups are sorted by start time, the MANIFEST is written once
+	// they are complete.
+	for i := len(backups) - 1; i >= 0; i-- {
+		if _, err := mysqlctl.GetBackupManifest(ctx, backups[i]); err != nil {
+			continue
+		}
+		backupTime, _, err := mysqlctl.ParseBackupName(backupDir, backups[i].Name())
+		if err != nil {
+			return time.Time{}, err
+		}
+		if backupTime != nil {
+			return *backupTime, nil
+		}
+	}
+	return time.Time{}, nil
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
+	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// backupSchedulerTMClient takes fake backups, named after the time of the
+// scheduler.
+type backupSchedulerTMClient struct {
+	tmclient.TabletManagerClient
+	t   *testing.T
+	now func() time.Time
+
+	// statuses are the replication statuses of the tablets, by uid. The
+	// tablets that aren't in it fail to return one.
+	statuses map[uint32]*replicationdatapb.Status
+
+	mu      sync.Mutex
+	backups []string
+	engines []string
+}
+
+func (tmc *backupSchedulerTMClient) ReplicationStatus(ctx context.Context, tablet *topodatapb.Tablet) (*replicationdatapb.Status, error) {
+	status, ok := tmc.statuses[tablet.Alias.Uid]
+	if !ok {
+		return nil, fmt.Errorf("tablet %v is down", topoproto.TabletAliasString(tablet.Alias))
+	}
+	return status, nil
+}
+
+func (tmc *backupSchedulerTMClient) Backup(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.BackupRequest) (logutil.EventStream, error) {
+	name := fmt.Sprintf("%v.%v", tmc.now().UTC().Format(mysqlctl.BackupTimestampFormat), topoproto.TabletAliasString(tablet.Alias))
+	addBackup(tmc.t, tablet.Keyspace, tablet.Shard, name, true)
+	tmc.mu.Lock()
+	defer tmc.mu.Unlock()
+	tmc.backups = append(tmc.backups, name)
+	tmc.engines = append(tmc.engines, req.BackupEngine)
+	return &eofEventStream{}, nil
+}
+
+type eofEventStream struct{}
+
+func (s *eofEventStream) Recv() (*logutilpb.Event, error) {
+	return nil, io.EOF
+}
+
+// addBackup creates a backup in the backup storage. Only complete
+// backups have a MANIFEST.
+func addBackup(t *testing.T, keyspace, shard, name string, complete bool) {
+	ctx := context.Background()
+	bs, err := backupstorage.GetBackupStorage()
+	require.NoError(t, err)
+	defer bs.Close()
+	bh, err := bs.StartBackup(ctx, mysqlctl.GetBackupDir(keyspace, shard), name)
+	require.NoError(t, err)
+	if complete {
+		w, err := bh.AddFile(ctx, "MANIFEST", 0)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(`{"BackupMethod": "builtin"}`))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	}
+	require.NoError(t, bh.EndBackup(ctx))
+}
+
+func listBackups(t *testing.T, keyspace, shard string) []string {
+	bs, err := backupstorage.GetBackupStorage()
+	require.NoError(t, err)
+	defer bs.Close()
+	bhs, err := bs.ListBackups(context.Background(), mysqlctl.GetBackupDir(keyspace, shard))
+	require.NoError(t, err)
+	var names []string
+	for _, bh := range bhs {
+		names = append(names, bh.Name())
+	}
+	return names
+}
+
+func TestBackupScheduler(t *testing.T) {
+	oldRoot := *filebackupstorage.FileBackupStorageRoot
+	oldImplementation := *backupstorage.BackupStorageImplementation
+	defer func() {
+		*filebackupstorage.FileBackupStorageRoot = oldRoot
+		*backupstorage.BackupStorageImplementation = oldImplementation
+	}()
+	*filebackupstorage.FileBackupStorageRoot = path.Join(t.TempDir(), "backups")
+	*backupstorage.BackupStorageImplementation = "file"
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{
+		BackupSchedule: &topodatapb.BackupSchedule{
+			Cron:              "0 3 * * *",
+			TabletTypes:       []topodatapb.TabletType{topodatapb.TabletType_RDONLY, topodatapb.TabletType_REPLICA},
+			BackupEngine:      "logical",
+			MinRetentionTime:  protoutil.DurationToProto(24 * time.Hour),
+			MinRetentionCount: 1,
+		},
+	}))
+	require.NoError(t, ts.CreateKeyspace(ctx, "unscheduled", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, "ks", "0"))
+	require.NoError(t, ts.CreateShard(ctx, "unscheduled", "0"))
+	for uid, tabletType := range map[uint32]topodatapb.TabletType{
+		100: topodatapb.TabletType_PRIMARY,
+		101: topodatapb.TabletType_REPLICA,
+		102: topodatapb.TabletType_RDONLY,
+		103: topodatapb.TabletType_RDONLY,
+		104: topodatapb.TabletType_REPLICA,
+		200: topodatapb.TabletType_REPLICA,
+	} {
+		keyspace := "ks"
+		if uid >= 200 {
+			keyspace = "unscheduled"
+		}
+		require.NoError(t, ts.CreateTablet(ctx, &topodatapb.Tablet{
+			Alias:    &topodatapb.TabletAlias{Cell: "cell1", Uid: uid},
+			Keyspace: keyspace,
+			Shard:    "0",
+			Type:     tabletType,
+		}))
+	}
+
+	now := time.Date(2021, 6, 2, 3, 0, 30, 0, time.UTC)
+	clock := func() time.Time { return now }
+	tmc := &backupSchedulerTMClient{
+		t:   t,
+		now: clock,
+		statuses: map[uint32]*replicationdatapb.Status{
+			101: {IoThreadRunning: true, SqlThreadRunning: true, ReplicationLagSeconds: 10},
+			102: {IoThreadRunning: true, SqlThreadRunning: true, ReplicationLagSeconds: 30},
+			103: {IoThreadRunning: false, SqlThreadRunning: true},
+			104: {IoThreadRunning: true, SqlThreadRunning: true, ReplicationLagSeconds: 2},
+			200: {IoThreadRunning: true, SqlThreadRunning: true},
+		},
+	}
+	bs := newBackupScheduler(ts, tmc)
+	bs.now = clock
+
+	addBackup(t, "ks", "0", "2021-05-30.030000.cell1-0000000101", true)
+	addBackup(t, "ks", "0", "2021-06-01.030000.cell1-0000000101", true)
+	// Incomplete backups are ignored.
+	addBackup(t, "ks", "0", "2021-06-02.030000.cell1-0000000101", false)
+
+	// The last complete backup is from yesterday, so a backup is due. It is
+	// taken on the preferred RDONLY tablet that replicates, and the old
+	// backups are pruned.
+	bs.check(ctx)
+	bs.wg.Wait()
+	assert.Equal(t, []string{"2021-06-02.030030.cell1-0000000102"}, tmc.backups)
+	assert.Equal(t, []string{"logical"}, tmc.engines)
+	assert.Equal(t, []string{"2021-06-02.030030.cell1-0000000102"}, listBackups(t, "ks", "0"))
+	assert.Empty(t, listBackups(t, "unscheduled", "0"))
+
+	// Not due before 3am tomorrow.
+	now = now.Add(time.Hour)
+	bs.check(ctx)
+	bs.wg.Wait()
+	assert.Len(t, tmc.backups, 1)
+
+	// Without a healthy RDONLY tablet, the REPLICA with the least lag is
+	// used.
+	now = time.Date(2021, 6, 3, 3, 1, 0, 0, time.UTC)
+	delete(tmc.statuses, 102)
+	bs.check(ctx)
+	bs.wg.Wait()
+	assert.Equal(t, []string{"2021-06-02.030030.cell1-0000000102", "2021-06-03.030100.cell1-0000000104"}, tmc.backups)
+
+	// A failed backup is not retried before the next scheduled time.
+	now = time.Date(2021, 6, 4, 3, 0, 0, 0, time.UTC)
+	tmc.statuses = nil
+	bs.check(ctx)
+	bs.wg.Wait()
+	now = now.Add(time.Hour)
+	tmc.statuses = map[uint32]*replicationdatapb.Status{
+		101: {IoThreadRunning: true, SqlThreadRunning: true},
+	}
+	bs.check(ctx)
+	bs.wg.Wait()
+	assert.Len(t, tmc.backups, 2)
+	now = time.Date(2021, 6, 5, 3, 0, 0, 0, time.UTC)
+	bs.check(ctx)
+	bs.wg.Wait()
+	assert.Len(t, tmc.backups, 3)
+
+	// The schedule is in UTC, whatever the time zone of vtctld.
+	zone := time.FixedZone("UTC+5", 5*60*60)
+	now = time.Date(2021, 6, 6, 8, 0, 0, 0, zone)
+	tmc.statuses = nil
+	bs.check(ctx)
+	bs.wg.Wait()
+	// 11pm UTC.
+	now = time.Date(2021, 6, 7, 4, 0, 0, 0, zone)
+	tmc.statuses = map[uint32]*replicationdatapb.Status{
+		101: {IoThreadRunning: true, SqlThreadRunning: true},
+	}
+	bs.check(ctx)
+	bs.wg.Wait()
+	assert.Len(t, tmc.backups, 3)
+	now = time.Date(2021, 6, 7, 8, 0, 0, 0, zone)
+	bs.check(ctx)
+	bs.wg.Wait()
+	assert.Len(t, tmc.backups, 4)
+}
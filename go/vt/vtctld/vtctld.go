@@ -195,6 +195,9 @@ func InitVtctld(ts *topo.Server) error {
 	// Init online DDL schema manager
 	initSchemaManager(ts)
 
+	// Init the backup scheduler
+	initBackupScheduler(ts)
+
 	// Setup reverse proxy for all vttablets through /vttablet/.
 	initVTTabletRedirection(ts)
 
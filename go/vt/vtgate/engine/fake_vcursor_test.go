@@ -78,6 +78,10 @@ func (t *noopVCursor) KeyspaceAvailable(ks string) bool {
 	panic("implement me")
 }
 
+func (t *noopVCursor) GenerateSnowflakeIDs(count int) ([]int64, error) {
+	panic("implement me")
+}
+
 func (t *noopVCursor) SetDDLStrategy(strategy string) {
 	panic("implement me")
 }
@@ -149,6 +153,10 @@ func (t *noopVCursor) InReservedConn() bool {
 	panic("implement me")
 }
 
+func (t *noopVCursor) CanUseSettingsPool() bool {
+	return false
+}
+
 func (t *noopVCursor) ShardSession() []*srvtopo.ResolvedShard {
 	panic("implement me")
 }
@@ -293,6 +301,8 @@ type loggingVCursor struct {
 	tableRoutes tableRoutes
 	dbDDLPlugin string
 	ksAvailable bool
+
+	nextSnowflakeID int64
 }
 
 type tableRoutes struct {
@@ -421,6 +431,19 @@ func (f *loggingVCursor) ExecuteStandalone(query string, bindvars map[string]*qu
 	return f.nextResult()
 }
 
+func (f *loggingVCursor) GenerateSnowflakeIDs(count int) ([]int64, error) {
+	f.log = append(f.log, fmt.Sprintf("GenerateSnowflakeIDs %d", count))
+	if f.resultErr != nil {
+		return nil, f.resultErr
+	}
+	ids := make([]int64, count)
+	for i := range ids {
+		f.nextSnowflakeID++
+		ids[i] = f.nextSnowflakeID
+	}
+	return ids, nil
+}
+
 func (f *loggingVCursor) StreamExecuteMulti(query string, rss []*srvtopo.ResolvedShard, bindVars []map[string]*querypb.BindVariable, callback func(reply *sqltypes.Result) error) []error {
 	f.mu.Lock()
 	f.log = append(f.log, fmt.Sprintf("StreamExecuteMulti %s %s", query, printResolvedShardsBindVars(rss, bindVars)))
@@ -450,6 +473,20 @@ func (f *loggingVCursor) ResolveDestinations(keyspace string, ids []*querypb.Val
 			shards = f.shards
 		case key.DestinationKeyRange:
 			shards = f.shardForKsid
+		case key.DestinationKeyRanges:
+			// The shards that intersect one of the key ranges.
+			for _, shard := range f.shards {
+				shardKrs, err := key.ParseShardingSpec(shard)
+				if err != nil {
+					return nil, nil, err
+				}
+				for _, kr := range d {
+					if key.KeyRangesIntersect(kr, shardKrs[0]) {
+						shards = append(shards, shard)
+						break
+					}
+				}
+			}
 		case key.DestinationKeyspaceID:
 			if f.shardForKsid == nil || f.curShardForKsid >= len(f.shardForKsid) {
 				shards = []string{"-20"}
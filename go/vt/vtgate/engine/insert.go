@@ -124,9 +124,12 @@ func NewInsert(opcode InsertOpcode, keyspace *vindexes.Keyspace, vindexValues []
 
 // Generate represents the instruction to generate
 // a value from a sequence.
+// If Snowflake is set, the values are generated by vtgate
+// itself, and Keyspace and Query are unused.
 type Generate struct {
-	Keyspace *vindexes.Keyspace
-	Query    string
+	Keyspace  *vindexes.Keyspace
+	Query     string
+	Snowflake bool
 	// Values are the supplied values for the column, which
 	// will be stored as a list within the PlanValue. New
 	// values will be generated based on how many were not
@@ -310,6 +313,10 @@ func (ins *Insert) processGenerate(vcursor VCursor, bindVars map[string]*querypb
 		}
 	}
 
+	if ins.Generate.Snowflake {
+		return ins.processGenerateSnowflake(vcursor, bindVars, resolved, count)
+	}
+
 	// If generation is needed, generate the requested number of values (as one call).
 	if count != 0 {
 		rss, _, err := vcursor.ResolveDestinations(ins.Generate.Keyspace.Name, nil, []key.Destination{key.DestinationAnyShard{}})
@@ -345,6 +352,30 @@ func (ins *Insert) processGenerate(vcursor VCursor, bindVars map[string]*querypb
 	return insertID, nil
 }
 
+// processGenerateSnowflake fills the values that were not supplied with
+// snowflake ids. Unlike sequence values, snowflake ids are not contiguous,
+// so the returned insert id is the first generated id.
+func (ins *Insert) processGenerateSnowflake(vcursor VCursor, bindVars map[string]*querypb.BindVariable, resolved []sqltypes.Value, count int64) (insertID int64, err error) {
+	var ids []int64
+	if count != 0 {
+		ids, err = vcursor.GenerateSnowflakeIDs(int(count))
+		if err != nil {
+			return 0, err
+		}
+		insertID = ids[0]
+	}
+
+	for i, v := range resolved {
+		if shouldGenerate(v) {
+			bindVars[SeqVarName+strconv.Itoa(i)] = sqltypes.Int64BindVariable(ids[0])
+			ids = ids[1:]
+		} else {
+			bindVars[SeqVarName+strconv.Itoa(i)] = sqltypes.ValueBindVariable(v)
+		}
+	}
+	return insertID, nil
+}
+
 // getInsertShardedRoute performs all the vindex related work
 // and returns a map of shard to queries.
 // Using the primary vindex, it computes the target keyspace ids.
@@ -362,14 +393,24 @@ func (ins *Insert) getInsertShardedRoute(vcursor VCursor, bindVars map[string]*q
 	vindexRowsValues := make([][][]sqltypes.Value, len(ins.VindexValues))
 	rowCount := 0
 	for vIdx, vColValues := range ins.VindexValues {
-		if len(vColValues.Values) != len(ins.Table.ColumnVindexes[vIdx].Columns) {
-			return nil, nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] supplied vindex column values don't match vschema: %v %v", vColValues, ins.Table.ColumnVindexes[vIdx].Columns)
+		colVindex := ins.Table.ColumnVindexes[vIdx]
+		if len(vColValues.Values) != len(colVindex.Columns) {
+			return nil, nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] supplied vindex column values don't match vschema: %v %v", vColValues, colVindex.Columns)
 		}
 		for colIdx, colValues := range vColValues.Values {
 			rowsResolvedValues, err := colValues.ResolveList(bindVars)
 			if err != nil {
 				return nil, nil, err
 			}
+			// The vindex of a JSON path column maps the value found
+			// at the path, not the document.
+			if colVindex.JSONPath != nil {
+				for rowNum, doc := range rowsResolvedValues {
+					if rowsResolvedValues[rowNum], err = colVindex.JSONPath.Extract(doc); err != nil {
+						return nil, nil, err
+					}
+				}
+			}
 			// This is the first iteration: allocate for transpose.
 			if colIdx == 0 {
 				if len(rowsResolvedValues) == 0 {
@@ -418,6 +459,10 @@ func (ins *Insert) getInsertShardedRoute(vcursor VCursor, bindVars map[string]*q
 	// Build 3-d bindvars. Skip rows with nil keyspace ids in case
 	// we're executing an insert ignore.
 	for vIdx, colVindex := range ins.Table.ColumnVindexes {
+		if colVindex.JSONPath != nil {
+			// The document is sent as is.
+			continue
+		}
 		for rowNum, rowColumnKeys := range vindexRowsValues[vIdx] {
 			if keyspaceIDs[rowNum] == nil {
 				// InsertShardedIgnore: skip the row.
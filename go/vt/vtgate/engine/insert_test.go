@@ -174,6 +174,45 @@ func TestInsertUnshardedGenerate_Zeros(t *testing.T) {
 	expectResult(t, "Execute", result, &sqltypes.Result{InsertID: 4})
 }
 
+func TestInsertUnshardedGenerateSnowflake(t *testing.T) {
+	ins := NewQueryInsert(
+		InsertUnsharded,
+		&vindexes.Keyspace{
+			Name:    "ks",
+			Sharded: false,
+		},
+		"dummy_insert",
+	)
+	ins.Generate = &Generate{
+		Snowflake: true,
+		Values: sqltypes.PlanValue{
+			Values: []sqltypes.PlanValue{
+				{Value: sqltypes.NewInt64(1)},
+				{Value: sqltypes.NULL},
+				{Value: sqltypes.NewInt64(2)},
+				{Value: sqltypes.NewInt64(0)},
+			},
+		},
+	}
+
+	vc := newDMLTestVCursor("0")
+	vc.nextSnowflakeID = 1000
+	vc.results = []*sqltypes.Result{
+		{InsertID: 1},
+	}
+
+	result, err := ins.TryExecute(vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		// No round trip to generate the values.
+		`GenerateSnowflakeIDs 2`,
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks.0: dummy_insert {__seq0: type:INT64 value:"1" __seq1: type:INT64 value:"1001" __seq2: type:INT64 value:"2" __seq3: type:INT64 value:"1002"} true true`,
+	})
+
+	// The insert id is the first generated id.
+	expectResult(t, "Execute", result, &sqltypes.Result{InsertID: 1001})
+}
 func TestInsertShardedSimple(t *testing.T) {
 	invschema := &vschemapb.SrvVSchema{
 		Keyspaces: map[string]*vschemapb.Keyspace{
@@ -775,6 +814,78 @@ func TestInsertShardedGeo(t *testing.T) {
 	})
 }
 
+func TestInsertShardedJSONPath(t *testing.T) {
+	invschema := &vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"sharded": {
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"hash": {
+						Type: "hash",
+					},
+				},
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{
+							Name:   "hash",
+							Column: "doc->'$.id'",
+						}},
+					},
+				},
+			},
+		},
+	}
+	vs := vindexes.BuildVSchema(invschema)
+	ks := vs.Keyspaces["sharded"]
+
+	ins := NewInsert(
+		InsertSharded,
+		ks.Keyspace,
+		[]sqltypes.PlanValue{{
+			// colVindex columns: doc
+			Values: []sqltypes.PlanValue{{
+				// rows for doc
+				Values: []sqltypes.PlanValue{{
+					Key: "__doc",
+				}, {
+					Value: sqltypes.NewVarChar(`{"id": 3}`),
+				}},
+			}},
+		}},
+		ks.Tables["t1"],
+		"prefix",
+		[]string{" mid1", " mid2"},
+		" suffix",
+	)
+
+	vc := newDMLTestVCursor("-20", "20-")
+	vc.shardForKsid = []string{"20-", "-20"}
+
+	_, err := ins.TryExecute(vc, map[string]*querypb.BindVariable{
+		"__doc": sqltypes.StringBindVariable(`{"id": 1, "name": "a"}`),
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The documents are routed on the value of their id,
+	// and are inserted as is.
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations sharded [value:"0" value:"1"] Destinations:DestinationKeyspaceID(166b40b44aba4bd6),DestinationKeyspaceID(4eb190c9a2fa169c)`,
+		`ExecuteMultiShard sharded.20-: prefix mid1 suffix ` +
+			`{__doc: type:VARBINARY value:"{\"id\": 1, \"name\": \"a\"}"} ` +
+			`sharded.-20: prefix mid2 suffix ` +
+			`{__doc: type:VARBINARY value:"{\"id\": 1, \"name\": \"a\"}"} ` +
+			`true false`,
+	})
+
+	// A document that's not JSON can't be routed.
+	_, err = ins.TryExecute(vc, map[string]*querypb.BindVariable{
+		"__doc": sqltypes.StringBindVariable(`{"id": `),
+	}, false)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid JSON document for path $.id")
+}
+
 func TestInsertShardedIgnoreOwned(t *testing.T) {
 	invschema := &vschemapb.SrvVSchema{
 		Keyspaces: map[string]*vschemapb.Keyspace{
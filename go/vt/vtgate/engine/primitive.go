@@ -110,6 +110,9 @@ type (
 		MessageStream(rss []*srvtopo.ResolvedShard, tableName string, callback func(*sqltypes.Result) error) error
 
 		VStream(rss []*srvtopo.ResolvedShard, filter *binlogdatapb.Filter, gtid string, callback func(evs []*binlogdatapb.VEvent) error) error
+
+		// GenerateSnowflakeIDs returns count new snowflake ids, generated by this vtgate.
+		GenerateSnowflakeIDs(count int) ([]int64, error)
 	}
 
 	//SessionActions gives primitives ability to interact with the session state
@@ -129,6 +132,10 @@ type (
 		// InReservedConn provides whether this session is using reserved connection
 		InReservedConn() bool
 
+		// CanUseSettingsPool returns true if the system variables of this session can be
+		// applied on pooled connections, instead of a dedicated connection
+		CanUseSettingsPool() bool
+
 		// ShardSession returns shard info about open connections
 		ShardSession() []*srvtopo.ResolvedShard
 
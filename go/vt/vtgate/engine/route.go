@@ -78,6 +78,9 @@ type Route struct {
 	// Values specifies the vindex values to use for routing.
 	Values []sqltypes.PlanValue
 
+	// RangeVindex specifies the vindex to be used with SelectRange.
+	RangeVindex vindexes.Ranged
+
 	// OrderBy specifies the key order for merge sorting. This will be
 	// set only for scatter queries that need the results to be
 	// merge-sorted.
@@ -179,6 +182,12 @@ const (
 	// SelectIN, but the query sent to each shard is the
 	// same.
 	SelectMultiEqual
+	// SelectRange is for routing a query that has range
+	// predicates on all the columns of a Ranged vindex, to
+	// the shards of the key ranges that contain the matching
+	// rows. Requires: A RangeVindex, and two Values lists with
+	// the lower and the upper bounds of its columns.
+	SelectRange
 	// SelectScatter is for routing a scatter query
 	// to all shards of a keyspace.
 	SelectScatter
@@ -200,6 +209,7 @@ var routeName = map[RouteOpcode]string{
 	SelectEqual:       "SelectEqual",
 	SelectIN:          "SelectIN",
 	SelectMultiEqual:  "SelectMultiEqual",
+	SelectRange:       "SelectRange",
 	SelectScatter:     "SelectScatter",
 	SelectNext:        "SelectNext",
 	SelectDBA:         "SelectDBA",
@@ -272,6 +282,8 @@ func (route *Route) executeInternal(vcursor VCursor, bindVars map[string]*queryp
 		rss, bvs, err = route.paramsSelectIn(vcursor, bindVars)
 	case SelectMultiEqual:
 		rss, bvs, err = route.paramsSelectMultiEqual(vcursor, bindVars)
+	case SelectRange:
+		rss, bvs, err = route.paramsSelectRange(vcursor, bindVars)
 	case SelectNone:
 		rss, bvs, err = nil, nil, nil
 	default:
@@ -346,6 +358,8 @@ func (route *Route) TryStreamExecute(vcursor VCursor, bindVars map[string]*query
 		rss, bvs, err = route.paramsSelectIn(vcursor, bindVars)
 	case SelectMultiEqual:
 		rss, bvs, err = route.paramsSelectMultiEqual(vcursor, bindVars)
+	case SelectRange:
+		rss, bvs, err = route.paramsSelectRange(vcursor, bindVars)
 	case SelectNone:
 		rss, bvs, err = nil, nil, nil
 	default:
@@ -624,6 +638,30 @@ func (route *Route) paramsSelectMultiEqual(vcursor VCursor, bindVars map[string]
 	return rss, multiBindVars, nil
 }
 
+func (route *Route) paramsSelectRange(vcursor VCursor, bindVars map[string]*querypb.BindVariable) ([]*srvtopo.ResolvedShard, []map[string]*querypb.BindVariable, error) {
+	from, err := route.Values[0].ResolveList(bindVars)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := route.Values[1].ResolveList(bindVars)
+	if err != nil {
+		return nil, nil, err
+	}
+	destination, err := route.RangeVindex.MapRange(vcursor, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	rss, _, err := vcursor.ResolveDestinations(route.Keyspace.Name, nil, []key.Destination{destination})
+	if err != nil {
+		return nil, nil, err
+	}
+	multiBindVars := make([]map[string]*querypb.BindVariable, len(rss))
+	for i := range multiBindVars {
+		multiBindVars[i] = bindVars
+	}
+	return rss, multiBindVars, nil
+}
+
 func resolveShards(vcursor VCursor, vindex vindexes.SingleColumn, keyspace *vindexes.Keyspace, vindexKeys []sqltypes.Value) ([]*srvtopo.ResolvedShard, [][]*querypb.Value, error) {
 	// Convert vindexKeys to []*querypb.Value
 	ids := make([]*querypb.Value, len(vindexKeys))
@@ -788,6 +826,9 @@ func (route *Route) description() PrimitiveDescription {
 	if route.Vindex != nil {
 		other["Vindex"] = route.Vindex.String()
 	}
+	if route.RangeVindex != nil {
+		other["Vindex"] = route.RangeVindex.String()
+	}
 	if len(route.Values) > 0 {
 		other["Values"] = route.Values
 	}
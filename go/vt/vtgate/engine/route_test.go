@@ -580,6 +580,59 @@ func TestSelectMultiEqual(t *testing.T) {
 	expectResult(t, "sel.StreamExecute", result, defaultSelectResult)
 }
 
+func TestSelectRange(t *testing.T) {
+	vindex, _ := vindexes.NewGeo("", map[string]string{"encoding": "geohash", "precision": "1"})
+	sel := NewRoute(
+		SelectRange,
+		&vindexes.Keyspace{
+			Name:    "ks",
+			Sharded: true,
+		},
+		"dummy_select",
+		"dummy_select_field",
+	)
+	sel.RangeVindex = vindex.(vindexes.Ranged)
+	sel.Values = []sqltypes.PlanValue{
+		{Values: []sqltypes.PlanValue{{Key: "minlat"}, {Value: sqltypes.NewFloat64(2.2)}}},
+		{Values: []sqltypes.PlanValue{{Key: "maxlat"}, {Value: sqltypes.NewFloat64(2.4)}}},
+	}
+	bindVars := map[string]*querypb.BindVariable{
+		"minlat": sqltypes.Float64BindVariable(48.8),
+		"maxlat": sqltypes.Float64BindVariable(48.9),
+	}
+
+	vc := &loggingVCursor{
+		shards:  []string{"-40", "40-80", "80-c0", "c0-"},
+		results: []*sqltypes.Result{defaultSelectResult},
+	}
+	result, err := sel.TryExecute(vc, bindVars, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationKeyRanges(d000000000000000-d800000000000000)`,
+		`ExecuteMultiShard ks.c0-: dummy_select {maxlat: type:FLOAT64 value:"48.9" minlat: type:FLOAT64 value:"48.8"} false false`,
+	})
+	expectResult(t, "sel.Execute", result, defaultSelectResult)
+
+	vc.Rewind()
+	result, err = wrapStreamExecute(sel, vc, bindVars, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationKeyRanges(d000000000000000-d800000000000000)`,
+		`StreamExecuteMulti dummy_select ks.c0-: {maxlat: type:FLOAT64 value:"48.9" minlat: type:FLOAT64 value:"48.8"} `,
+	})
+	expectResult(t, "sel.StreamExecute", result, defaultSelectResult)
+
+	// An empty range doesn't go to any shard.
+	vc.Rewind()
+	bindVars["minlat"] = sqltypes.Float64BindVariable(49)
+	result, err = sel.TryExecute(vc, bindVars, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationNone()`,
+	})
+	expectResult(t, "sel.Execute", result, &sqltypes.Result{})
+}
+
 func TestSelectLike(t *testing.T) {
 	subshard, _ := vindexes.NewCFC("cfc", map[string]string{"hash": "md5", "offsets": "[1,2]"})
 	vindex := subshard.(*vindexes.CFC).PrefixVindex()
@@ -355,7 +355,9 @@ func (svs *SysVarReservedConn) checkAndUpdateSysVar(vcursor VCursor, res evaleng
 	buf := new(bytes.Buffer)
 	value.EncodeSQL(buf)
 	vcursor.Session().SetSysVar(svs.Name, buf.String())
-	vcursor.Session().NeedsReservedConn()
+	if !vcursor.Session().CanUseSettingsPool() {
+		vcursor.Session().NeedsReservedConn()
+	}
 	return true, nil
 }
 
@@ -51,6 +51,7 @@ import (
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder"
+	"vitess.io/vitess/go/vt/vtgate/snowflake"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 	"vitess.io/vitess/go/vt/vtgate/vschemaacl"
 
@@ -107,6 +108,14 @@ type Executor struct {
 
 	// allowScatter will fail planning if set to false and a plan contains any scatter queries
 	allowScatter bool
+
+	// snowflake generates ids for auto_increment columns of type snowflake.
+	// It is nil if snowflake ids are not enabled on this vtgate.
+	snowflake *snowflake.Keeper
+
+	// tenantWatcher keeps the mappings of the tenant vindexes up to date.
+	// It is nil until the vtgate is initialized.
+	tenantWatcher *tenantWatcher
 }
 
 var executorOnce sync.Once
@@ -388,7 +397,7 @@ func ifReadAfterWriteExist(session *SafeSession, f func(*vtgatepb.ReadAfterWrite
 }
 
 func (e *Executor) destinationExec(ctx context.Context, safeSession *SafeSession, sql string, bindVars map[string]*querypb.BindVariable, dest key.Destination, destKeyspace string, destTabletType topodatapb.TabletType, logStats *LogStats, ignoreMaxMemoryRows bool) (*sqltypes.Result, error) {
-	return e.resolver.Execute(ctx, sql, bindVars, destKeyspace, destTabletType, dest, safeSession, safeSession.Options, logStats, false /* canAutocommit */, ignoreMaxMemoryRows)
+	return e.resolver.Execute(ctx, sql, bindVars, destKeyspace, destTabletType, dest, safeSession, safeSession.ExecuteOptions(), logStats, false /* canAutocommit */, ignoreMaxMemoryRows)
 }
 
 func (e *Executor) handleBegin(ctx context.Context, safeSession *SafeSession, logStats *LogStats) (*sqltypes.Result, error) {
@@ -428,7 +437,7 @@ func (e *Executor) handleRollback(ctx context.Context, safeSession *SafeSession,
 	return &sqltypes.Result{}, err
 }
 
-func (e *Executor) handleSavepoint(ctx context.Context, safeSession *SafeSession, sql string, planType string, logStats *LogStats, nonTxResponse func(query string) (*sqltypes.Result, error), ignoreMaxMemoryRows bool) (*sqltypes.Result, error) {
+func (e *Executor) handleSavepoint(ctx context.Context, safeSession *SafeSession, sql string, planType string, logStats *LogStats, nonTxResponse func(query string) (*sqltypes.Result, error)) (*sqltypes.Result, error) {
 	execStart := time.Now()
 	logStats.PlanTime = execStart.Sub(logStats.StartTime)
 	logStats.ShardQueries = uint64(len(safeSession.ShardSessions))
@@ -437,32 +446,29 @@ func (e *Executor) handleSavepoint(ctx context.Context, safeSession *SafeSession
 		logStats.ExecuteTime = time.Since(execStart)
 	}()
 
-	if len(safeSession.ShardSessions) == 0 {
-		if safeSession.InTransaction() {
-			// Storing, as this needs to be executed just after starting transaction on the shard.
-			safeSession.StoreSavepoint(sql)
-			return &sqltypes.Result{}, nil
-		}
+	if !safeSession.InTransaction() {
 		return nonTxResponse(sql)
 	}
-	var rss []*srvtopo.ResolvedShard
-	for _, shardSession := range safeSession.ShardSessions {
-		rss = append(rss, &srvtopo.ResolvedShard{
-			Target:  shardSession.Target,
-			Gateway: e.resolver.resolver.GetGateway(),
-		})
+	stmt, err := sqlparser.Parse(sql)
+	if err != nil {
+		return nil, err
 	}
-	queries := make([]*querypb.BoundQuery, len(rss))
-	for i := range rss {
-		queries[i] = &querypb.BoundQuery{Sql: sql}
+	// The savepoints are recorded in the session, and set on the shards
+	// when they join the transaction.
+	switch stmt := stmt.(type) {
+	case *sqlparser.Savepoint:
+		err = e.txConn.Savepoint(ctx, safeSession, stmt.Name)
+	case *sqlparser.SRollback:
+		err = e.txConn.RollbackToSavepoint(ctx, safeSession, stmt.Name)
+	case *sqlparser.Release:
+		err = e.txConn.ReleaseSavepoint(ctx, safeSession, stmt.Name)
+	default:
+		err = vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] unexpected statement for %s: %s", planType, sql)
 	}
-	qr, errs := e.ExecuteMultiShard(ctx, rss, queries, safeSession, false /*autocommit*/, ignoreMaxMemoryRows)
-	err := vterrors.Aggregate(errs)
 	if err != nil {
 		return nil, err
 	}
-	safeSession.StoreSavepoint(sql)
-	return qr, nil
+	return &sqltypes.Result{}, nil
 }
 
 // CloseSession releases the current connection, which rollbacks open transactions and closes reserved connections.
@@ -1294,12 +1300,23 @@ func (e *Executor) VSchema() *vindexes.VSchema {
 	return e.vschema
 }
 
+// GenerateSnowflakeIDs returns count new snowflake ids.
+func (e *Executor) GenerateSnowflakeIDs(count int) ([]int64, error) {
+	if e.snowflake == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "snowflake ids are not enabled on this vtgate, use -enable_snowflake_ids")
+	}
+	return e.snowflake.Next(count)
+}
+
 // SaveVSchema updates the vschema and stats
 func (e *Executor) SaveVSchema(vschema *vindexes.VSchema, stats *VSchemaStats) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	if vschema != nil {
 		e.vschema = vschema
+		if e.tenantWatcher != nil {
+			e.tenantWatcher.update(vschema)
+		}
 	}
 	e.vschemaStats = stats
 	e.plans.Clear()
@@ -366,6 +366,39 @@ func TestExecutorSetOp(t *testing.T) {
 	}
 }
 
+func TestExecutorSetSettingsPool(t *testing.T) {
+	executor, _, _, sbclookup := createExecutorEnv()
+	*sysVarSetEnabled = true
+	*useSettingsPool = true
+	defer func() {
+		*useSettingsPool = false
+	}()
+
+	session := NewAutocommitSession(&vtgatepb.Session{TargetString: KsTestUnsharded, EnableSystemSettings: true})
+	sbclookup.SetResults([]*sqltypes.Result{sqltypes.MakeTestResult(sqltypes.MakeTestFields("sql_safe_updates", "int64"), "1")})
+	_, err := executor.Execute(context.Background(), "TestExecute", session, "set sql_safe_updates = 1", nil)
+	require.NoError(t, err)
+	assert.False(t, session.InReservedConn())
+
+	// The queries of the session carry its settings.
+	sbclookup.Options = nil
+	_, err = executor.Execute(context.Background(), "TestExecute", session, "select id from music_user_map", nil)
+	require.NoError(t, err)
+	require.Len(t, sbclookup.Options, 1)
+	assert.Equal(t, []string{"set @@sql_safe_updates = 1"}, sbclookup.Options[0].Settings)
+	assert.Nil(t, session.Options.GetSettings())
+
+	// A session that holds connections needs to reserve them.
+	_, err = executor.Execute(context.Background(), "TestExecute", session, "begin", nil)
+	require.NoError(t, err)
+	_, err = executor.Execute(context.Background(), "TestExecute", session, "select id from music_user_map", nil)
+	require.NoError(t, err)
+	sbclookup.SetResults([]*sqltypes.Result{sqltypes.MakeTestResult(sqltypes.MakeTestFields("orig|new", "varchar|varchar"), "|STRICT_ALL_TABLES")})
+	_, err = executor.Execute(context.Background(), "TestExecute", session, "set sql_mode = 'STRICT_ALL_TABLES'", nil)
+	require.NoError(t, err)
+	assert.True(t, session.InReservedConn())
+}
+
 func TestExecutorSetMetadata(t *testing.T) {
 	executor, _, _, _ := createLegacyExecutorEnv()
 	session := NewSafeSession(&vtgatepb.Session{TargetString: "@primary", Autocommit: true})
@@ -40,14 +40,14 @@ func getDMLRouting(where *sqlparser.Where, table *vindexes.Table) (engine.DMLOpc
 			continue
 		}
 		if ksidCol == "" {
-			ksidCol = sqlparser.String(index.Columns[0])
+			ksidCol = sqlparser.String(index.Expr())
 			ksidVindex = single
 		}
 		if where == nil {
 			return engine.Scatter, ksidVindex, ksidCol, nil, nil, nil
 		}
 
-		if pv, ok := getMatch(where.Expr, index.Columns[0]); ok {
+		if pv, ok := getMatch(where.Expr, index); ok {
 			opcode := engine.Equal
 			if pv.IsList() {
 				opcode = engine.In
@@ -68,14 +68,14 @@ func getDMLRouting(where *sqlparser.Where, table *vindexes.Table) (engine.DMLOpc
 // getMatch returns the matched value if there is an equality
 // constraint on the specified column that can be used to
 // decide on a route.
-func getMatch(node sqlparser.Expr, col sqlparser.ColIdent) (pv sqltypes.PlanValue, ok bool) {
+func getMatch(node sqlparser.Expr, index *vindexes.ColumnVindex) (pv sqltypes.PlanValue, ok bool) {
 	filters := sqlparser.SplitAndExpression(nil, node)
 	for _, filter := range filters {
 		comparison, ok := filter.(*sqlparser.ComparisonExpr)
 		if !ok {
 			continue
 		}
-		if !nameMatch(comparison.Left, col) {
+		if !vindexMatch(comparison.Left, index) {
 			continue
 		}
 		switch comparison.Operator {
@@ -99,9 +99,14 @@ func getMatch(node sqlparser.Expr, col sqlparser.ColIdent) (pv sqltypes.PlanValu
 	return sqltypes.PlanValue{}, false
 }
 
-func nameMatch(node sqlparser.Expr, col sqlparser.ColIdent) bool {
-	colname, ok := node.(*sqlparser.ColName)
-	return ok && colname.Name.Equal(col)
+// vindexMatch returns true if the expression is the column, or the
+// JSON path of the column, that the vindex is defined over.
+func vindexMatch(node sqlparser.Expr, index *vindexes.ColumnVindex) bool {
+	colname, path, ok := vindexes.JSONPathColumn(node)
+	if !ok {
+		colname, ok = node.(*sqlparser.ColName)
+	}
+	return ok && colname.Name.Equal(index.Columns[0]) && index.MatchesJSONPath(path)
 }
 
 func buildDMLPlan(vschema ContextVSchema, dmlType string, stmt sqlparser.Statement, reservedVars *sqlparser.ReservedVars, tableExprs sqlparser.TableExprs, where *sqlparser.Where, orderBy sqlparser.OrderBy, limit *sqlparser.Limit, comments sqlparser.Comments, nodes ...sqlparser.SQLNode) (*engine.DML, vindexes.SingleColumn, string, error) {
@@ -180,6 +180,11 @@ func buildInsertShardedPlan(ins *sqlparser.Insert, table *vindexes.Table) (engin
 		}
 	}
 	for _, colVindex := range eins.Table.ColumnVindexes {
+		if colVindex.JSONPath != nil {
+			// The document is inserted as is, the vindex value
+			// is extracted from it at execution time.
+			continue
+		}
 		for _, col := range colVindex.Columns {
 			colNum := findOrAddColumn(ins, col)
 			for rowNum, row := range rows {
@@ -239,6 +244,13 @@ func modifyForAutoinc(ins *sqlparser.Insert, eins *engine.Insert) error {
 		row[colNum] = sqlparser.NewArgument(engine.SeqVarName + strconv.Itoa(rowNum))
 	}
 
+	if eins.Table.AutoIncrement.Type == vindexes.AutoIncrementSnowflake {
+		eins.Generate = &engine.Generate{
+			Snowflake: true,
+			Values:    autoIncValues,
+		}
+		return nil
+	}
 	eins.Generate = &engine.Generate{
 		Keyspace: eins.Table.AutoIncrement.Sequence.Keyspace,
 		Query:    fmt.Sprintf("select next :n values from %s", sqlparser.String(eins.Table.AutoIncrement.Sequence.Name)),
@@ -402,9 +402,15 @@ func transformRoutePlan(ctx *planningContext, n *routeTree) (*route, error) {
 	}
 
 	var singleColumn vindexes.SingleColumn
+	var rangeVindex vindexes.Ranged
 	var values []sqltypes.PlanValue
 	if n.selectedVindex() != nil {
-		singleColumn = n.selected.foundVindex.(vindexes.SingleColumn)
+		switch vindex := n.selected.foundVindex.(type) {
+		case vindexes.SingleColumn:
+			singleColumn = vindex
+		case vindexes.Ranged:
+			rangeVindex = vindex
+		}
 		values = n.selected.values
 	}
 
@@ -443,6 +449,7 @@ func transformRoutePlan(ctx *planningContext, n *routeTree) (*route, error) {
 			Keyspace:            n.keyspace,
 			Vindex:              singleColumn,
 			Values:              values,
+			RangeVindex:         rangeVindex,
 			SysTableTableName:   n.SysTableTableName,
 			SysTableTableSchema: n.SysTableTableSchema,
 		},
@@ -403,7 +403,7 @@ func exprHasUniqueVindex(vschema ContextVSchema, semTable *semantics.SemTable, e
 		if len(vindex.Columns) > 1 || !vindex.Vindex.IsUnique() {
 			return false
 		}
-		if col.Name.Equal(vindex.Columns[0]) {
+		if col.Name.Equal(vindex.Columns[0]) && vindex.MatchesJSONPath("") {
 			return true
 		}
 	}
@@ -907,7 +907,7 @@ func findColumnVindex(ctx *planningContext, a *routeTree, exp sqlparser.Expr) vi
 			if leftDep.IsSolvedBy(rb.qtable.TableID) {
 				for _, vindex := range rb.vtable.ColumnVindexes {
 					sC, isSingle := vindex.Vindex.(vindexes.SingleColumn)
-					if isSingle && vindex.Columns[0].Equal(col.Name) {
+					if isSingle && vindex.Columns[0].Equal(col.Name) && vindex.MatchesJSONPath("") {
 						singCol = sC
 						return false, io.EOF
 					}
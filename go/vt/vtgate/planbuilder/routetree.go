@@ -86,6 +86,11 @@ type (
 		opcode      engine.RouteOpcode
 		foundVindex vindexes.Vindex
 		cost        cost
+
+		// from and to are the lower and upper bounds found so far for the
+		// columns of a Ranged vindex. The option is ready once all of the
+		// columns have both.
+		from, to []*sqltypes.PlanValue
 	}
 )
 
@@ -141,6 +146,8 @@ func (rp *routeTree) cost() int {
 		return 10
 	case engine.SelectMultiEqual:
 		return 10
+	case engine.SelectRange:
+		return 15
 	case engine.SelectScatter:
 		return 20
 	}
@@ -237,6 +244,12 @@ func (rp *routeTree) searchForNewVindexes(ctx *planningContext, predicates []sql
 				return false, err
 			}
 			newVindexFound = newVindexFound || found
+		case *sqlparser.RangeCond:
+			found, err := rp.planRangeCond(ctx, node)
+			if err != nil {
+				return false, err
+			}
+			newVindexFound = newVindexFound || found
 		}
 	}
 	return newVindexFound, nil
@@ -278,6 +291,12 @@ func (rp *routeTree) planComparison(ctx *planningContext, node *sqlparser.Compar
 			return false, false, err
 		}
 		return found, false, nil
+	case sqlparser.GreaterThanOp, sqlparser.GreaterEqualOp, sqlparser.LessThanOp, sqlparser.LessEqualOp:
+		found, err := rp.planRangeOp(ctx, node)
+		if err != nil {
+			return false, false, err
+		}
+		return found, false, nil
 	}
 	return false, false, nil
 }
@@ -309,11 +328,11 @@ func (rp *routeTree) isImpossibleNotIN(node *sqlparser.ComparisonExpr) bool {
 }
 
 func (rp *routeTree) planEqualOp(ctx *planningContext, node *sqlparser.ComparisonExpr) (bool, error) {
-	column, ok := node.Left.(*sqlparser.ColName)
+	column, path, ok := vindexColumn(node.Left)
 	other := node.Right
 	vdValue := other
 	if !ok {
-		column, ok = node.Right.(*sqlparser.ColName)
+		column, path, ok = vindexColumn(node.Right)
 		if !ok {
 			// either the LHS or RHS have to be a column to be useful for the vindex
 			return false, nil
@@ -325,10 +344,21 @@ func (rp *routeTree) planEqualOp(ctx *planningContext, node *sqlparser.Compariso
 		return false, err
 	}
 
-	return rp.haveMatchingVindex(ctx, node, vdValue, column, *val, equalOrEqualUnique, justTheVindex), err
+	return rp.haveMatchingVindex(ctx, node, vdValue, column, path, *val, equalOrEqualUnique, justTheVindex), err
 }
 
-func (rp *routeTree) planSimpleInOp(ctx *planningContext, node *sqlparser.ComparisonExpr, left *sqlparser.ColName) (bool, error) {
+// vindexColumn returns the column of an expression that can be routed
+// on: a plain column, or a JSON path of a column like doc->'$.id'.
+// The path is empty for plain columns.
+func vindexColumn(expr sqlparser.Expr) (*sqlparser.ColName, string, bool) {
+	if column, path, ok := vindexes.JSONPathColumn(expr); ok {
+		return column, path, true
+	}
+	column, ok := expr.(*sqlparser.ColName)
+	return column, "", ok
+}
+
+func (rp *routeTree) planSimpleInOp(ctx *planningContext, node *sqlparser.ComparisonExpr, left *sqlparser.ColName, path string) (bool, error) {
 	vdValue := node.Right
 	value, err := rp.makePlanValue(ctx, vdValue)
 	if err != nil || value == nil {
@@ -342,7 +372,7 @@ func (rp *routeTree) planSimpleInOp(ctx *planningContext, node *sqlparser.Compar
 		}
 	}
 	opcode := func(*vindexes.ColumnVindex) engine.RouteOpcode { return engine.SelectIN }
-	return rp.haveMatchingVindex(ctx, node, vdValue, left, *value, opcode, justTheVindex), err
+	return rp.haveMatchingVindex(ctx, node, vdValue, left, path, *value, opcode, justTheVindex), err
 }
 
 func (rp *routeTree) planCompositeInOp(ctx *planningContext, node *sqlparser.ComparisonExpr, left sqlparser.ValTuple) (bool, error) {
@@ -391,7 +421,7 @@ func (rp *routeTree) planCompositeInOpRecursive(ctx *planningContext, node *sqlp
 			}
 
 			opcode := func(*vindexes.ColumnVindex) engine.RouteOpcode { return engine.SelectMultiEqual }
-			newVindex := rp.haveMatchingVindex(ctx, node, rightVals, expr, *newPlanValues, opcode, justTheVindex)
+			newVindex := rp.haveMatchingVindex(ctx, node, rightVals, expr, "", *newPlanValues, opcode, justTheVindex)
 			foundVindex = newVindex || foundVindex
 		}
 	}
@@ -399,12 +429,12 @@ func (rp *routeTree) planCompositeInOpRecursive(ctx *planningContext, node *sqlp
 }
 
 func (rp *routeTree) planInOp(ctx *planningContext, node *sqlparser.ComparisonExpr) (bool, error) {
-	switch left := node.Left.(type) {
-	case *sqlparser.ColName:
-		return rp.planSimpleInOp(ctx, node, left)
-	case sqlparser.ValTuple:
+	if left, ok := node.Left.(sqlparser.ValTuple); ok {
 		return rp.planCompositeInOp(ctx, node, left)
 	}
+	if column, path, ok := vindexColumn(node.Left); ok {
+		return rp.planSimpleInOp(ctx, node, column, path)
+	}
 	return false, nil
 }
 
@@ -430,7 +460,62 @@ func (rp *routeTree) planLikeOp(ctx *planningContext, node *sqlparser.Comparison
 		return nil
 	}
 
-	return rp.haveMatchingVindex(ctx, node, vdValue, column, *val, selectEqual, vdx), err
+	return rp.haveMatchingVindex(ctx, node, vdValue, column, "", *val, selectEqual, vdx), err
+}
+
+// planRangeOp uses a comparison as a bound of a column of a Ranged vindex.
+// Strict comparisons are used as inclusive bounds, which can only send the
+// query to more shards than needed.
+func (rp *routeTree) planRangeOp(ctx *planningContext, node *sqlparser.ComparisonExpr) (bool, error) {
+	operator := node.Operator
+	column, ok := node.Left.(*sqlparser.ColName)
+	vdValue := node.Right
+	if !ok {
+		column, ok = node.Right.(*sqlparser.ColName)
+		if !ok {
+			return false, nil
+		}
+		vdValue = node.Left
+		// 1 < col is the same as col > 1
+		switch operator {
+		case sqlparser.GreaterThanOp:
+			operator = sqlparser.LessThanOp
+		case sqlparser.GreaterEqualOp:
+			operator = sqlparser.LessEqualOp
+		case sqlparser.LessThanOp:
+			operator = sqlparser.GreaterThanOp
+		case sqlparser.LessEqualOp:
+			operator = sqlparser.GreaterEqualOp
+		}
+	}
+	val, err := rp.makePlanValue(ctx, vdValue)
+	if err != nil || val == nil {
+		return false, err
+	}
+	isLower := operator == sqlparser.GreaterThanOp || operator == sqlparser.GreaterEqualOp
+	if isLower {
+		return rp.haveMatchingRangedVindex(ctx, node, column, val, nil), nil
+	}
+	return rp.haveMatchingRangedVindex(ctx, node, column, nil, val), nil
+}
+
+func (rp *routeTree) planRangeCond(ctx *planningContext, node *sqlparser.RangeCond) (bool, error) {
+	if node.Operator != sqlparser.BetweenOp {
+		return false, nil
+	}
+	column, ok := node.Left.(*sqlparser.ColName)
+	if !ok {
+		return false, nil
+	}
+	from, err := rp.makePlanValue(ctx, node.From)
+	if err != nil || from == nil {
+		return false, err
+	}
+	to, err := rp.makePlanValue(ctx, node.To)
+	if err != nil || to == nil {
+		return false, err
+	}
+	return rp.haveMatchingRangedVindex(ctx, node, column, from, to), nil
 }
 
 func (rp *routeTree) planIsExpr(ctx *planningContext, node *sqlparser.IsExpr) (bool, error) {
@@ -438,7 +523,7 @@ func (rp *routeTree) planIsExpr(ctx *planningContext, node *sqlparser.IsExpr) (b
 	if node.Right != sqlparser.IsNullOp {
 		return false, nil
 	}
-	column, ok := node.Left.(*sqlparser.ColName)
+	column, path, ok := vindexColumn(node.Left)
 	if !ok {
 		return false, nil
 	}
@@ -448,7 +533,7 @@ func (rp *routeTree) planIsExpr(ctx *planningContext, node *sqlparser.IsExpr) (b
 		return false, err
 	}
 
-	return rp.haveMatchingVindex(ctx, node, vdValue, column, *val, equalOrEqualUnique, justTheVindex), err
+	return rp.haveMatchingVindex(ctx, node, vdValue, column, path, *val, equalOrEqualUnique, justTheVindex), err
 }
 
 // makePlanValue transforms the given sqlparser.Expr into a sqltypes.PlanValue.
@@ -502,6 +587,7 @@ func (rp *routeTree) haveMatchingVindex(
 	node sqlparser.Expr,
 	valueExpr sqlparser.Expr,
 	column *sqlparser.ColName,
+	path string,
 	value sqltypes.PlanValue,
 	opcode func(*vindexes.ColumnVindex) engine.RouteOpcode,
 	vfunc func(*vindexes.ColumnVindex) vindexes.Vindex,
@@ -518,7 +604,7 @@ func (rp *routeTree) haveMatchingVindex(
 		}
 
 		col := v.colVindex.Columns[0]
-		if column.Name.Equal(col) {
+		if column.Name.Equal(col) && v.colVindex.MatchesJSONPath(path) {
 			// single column vindex - just add the option
 			routeOpcode := opcode(v.colVindex)
 			vindex := vfunc(v.colVindex)
@@ -537,6 +623,86 @@ func (rp *routeTree) haveMatchingVindex(
 	return newVindexFound
 }
 
+// haveMatchingRangedVindex adds the bounds of column to the options of the
+// Ranged vindexes that use it. Either bound can be nil. It returns true if
+// one of the options got all of its bounds.
+func (rp *routeTree) haveMatchingRangedVindex(ctx *planningContext, node sqlparser.Expr, column *sqlparser.ColName, from, to *sqltypes.PlanValue) bool {
+	newVindexFound := false
+	for _, v := range rp.vindexPreds {
+		if !ctx.semTable.DirectDeps(column).IsSolvedBy(v.tableID) {
+			continue
+		}
+		vindex, isRanged := v.colVindex.Vindex.(vindexes.Ranged)
+		if !isRanged {
+			continue
+		}
+		idx := -1
+		for i, col := range v.colVindex.Columns {
+			if column.Name.Equal(col) {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			continue
+		}
+
+		// The options are shared with the clones of this routeTree, so
+		// the range option is replaced rather than changed.
+		option := &vindexOption{
+			opcode:      engine.SelectRange,
+			foundVindex: vindex,
+			cost:        costFor(vindex, engine.SelectRange),
+			from:        make([]*sqltypes.PlanValue, len(v.colVindex.Columns)),
+			to:          make([]*sqltypes.PlanValue, len(v.colVindex.Columns)),
+		}
+		var options []*vindexOption
+		for _, other := range v.options {
+			if other.opcode != engine.SelectRange {
+				options = append(options, other)
+				continue
+			}
+			copy(option.from, other.from)
+			copy(option.to, other.to)
+			option.predicates = other.predicates
+		}
+		if option.ready = isReadyRange(option); option.ready {
+			// We already have all the bounds we need.
+			continue
+		}
+		// Any bound is good enough, since the predicates are ANDed.
+		if from != nil && option.from[idx] == nil {
+			option.from[idx] = from
+		}
+		if to != nil && option.to[idx] == nil {
+			option.to[idx] = to
+		}
+		option.predicates = append(option.predicates[:len(option.predicates):len(option.predicates)], node)
+		if option.ready = isReadyRange(option); option.ready {
+			fromValues := make([]sqltypes.PlanValue, len(option.from))
+			toValues := make([]sqltypes.PlanValue, len(option.to))
+			for i := range option.from {
+				fromValues[i] = *option.from[i]
+				toValues[i] = *option.to[i]
+			}
+			option.values = []sqltypes.PlanValue{{Values: fromValues}, {Values: toValues}}
+			newVindexFound = true
+		}
+		v.options = append(options, option)
+	}
+	return newVindexFound
+}
+
+// isReadyRange returns true if all the columns of a range option are bound.
+func isReadyRange(option *vindexOption) bool {
+	for i := range option.from {
+		if option.from[i] == nil || option.to[i] == nil {
+			return false
+		}
+	}
+	return true
+}
+
 // pickBestAvailableVindex goes over the available vindexes for this route and picks the best one available.
 func (rp *routeTree) pickBestAvailableVindex() {
 	for _, v := range rp.vindexPreds {
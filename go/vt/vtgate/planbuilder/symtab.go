@@ -122,10 +122,21 @@ func (st *symtab) AddVSchemaTable(alias sqlparser.TableName, vschemaTable *vinde
 			if err != nil {
 				return err
 			}
-			if i == 0 {
-				if col.vindex == nil || col.vindex.Cost() > single.Cost() {
-					col.vindex = single
+			if i != 0 {
+				continue
+			}
+			if cv.JSONPath != nil {
+				path := cv.JSONPath.String()
+				if col.jsonVindexes == nil {
+					col.jsonVindexes = make(map[string]vindexes.SingleColumn)
+				}
+				if vindex := col.jsonVindexes[path]; vindex == nil || vindex.Cost() > single.Cost() {
+					col.jsonVindexes[path] = single
 				}
+				continue
+			}
+			if col.vindex == nil || col.vindex.Cost() > single.Cost() {
+				col.vindex = single
 			}
 		}
 	}
@@ -395,10 +406,15 @@ func ResultFromNumber(rcs []*resultColumn, val *sqlparser.Literal, caller string
 
 // Vindex returns the vindex if the expression is a plain column reference
 // that is part of the specified route, and has an associated vindex.
+// The expression can also be a JSON path of a column, like doc->'$.id',
+// with a vindex defined over that path.
 func (st *symtab) Vindex(expr sqlparser.Expr, scope *route) vindexes.SingleColumn {
-	col, ok := expr.(*sqlparser.ColName)
+	col, path, ok := vindexes.JSONPathColumn(expr)
 	if !ok {
-		return nil
+		col, ok = expr.(*sqlparser.ColName)
+		if !ok {
+			return nil
+		}
 	}
 	if col.Metadata == nil {
 		// Find will set the Metadata.
@@ -410,6 +426,9 @@ func (st *symtab) Vindex(expr sqlparser.Expr, scope *route) vindexes.SingleColum
 	if c.Origin() != scope {
 		return nil
 	}
+	if path != "" {
+		return c.jsonVindexes[path]
+	}
 	return c.vindex
 }
 
@@ -519,11 +538,14 @@ func (t *table) Origin() logicalPlan {
 // For subquery and vindexFunc, the colNumber is also set because
 // the column order is known and unchangeable.
 type column struct {
-	origin    logicalPlan
-	st        *symtab
-	vindex    vindexes.SingleColumn
-	typ       querypb.Type
-	colNumber int
+	origin logicalPlan
+	st     *symtab
+	vindex vindexes.SingleColumn
+	// jsonVindexes are the vindexes defined over JSON paths
+	// of the column, by path.
+	jsonVindexes map[string]vindexes.SingleColumn
+	typ          querypb.Type
+	colNumber    int
 }
 
 // Origin returns the route that originates the column.
@@ -18,11 +18,14 @@ package vtgate
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"google.golang.org/protobuf/proto"
 
+	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -339,11 +342,53 @@ func (session *SafeSession) SetOptions(options *querypb.ExecuteOptions) {
 	session.Options = options
 }
 
-// StoreSavepoint stores the savepoint and release savepoint queries in the session
-func (session *SafeSession) StoreSavepoint(sql string) {
+// AddSavepoint records the savepoint in the session, so that it's set
+// on the shards that join the transaction later. Like in MySQL, it
+// replaces an older savepoint with the same name.
+func (session *SafeSession) AddSavepoint(name sqlparser.ColIdent) {
 	session.mu.Lock()
 	defer session.mu.Unlock()
-	session.Savepoints = append(session.Savepoints, sql)
+	if i := session.findSavepoint(name); i != -1 {
+		session.Savepoints = append(session.Savepoints[:i], session.Savepoints[i+1:]...)
+	}
+	session.Savepoints = append(session.Savepoints, sqlparser.String(&sqlparser.Savepoint{Name: name}))
+}
+
+// HasSavepoint returns true if the savepoint is recorded in the session.
+func (session *SafeSession) HasSavepoint(name sqlparser.ColIdent) bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return session.findSavepoint(name) != -1
+}
+
+// RollbackToSavepoint forgets the savepoints set after the named one.
+func (session *SafeSession) RollbackToSavepoint(name sqlparser.ColIdent) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if i := session.findSavepoint(name); i != -1 {
+		session.Savepoints = session.Savepoints[:i+1]
+	}
+}
+
+// ReleaseSavepoint forgets the named savepoint and the ones set after it.
+func (session *SafeSession) ReleaseSavepoint(name sqlparser.ColIdent) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if i := session.findSavepoint(name); i != -1 {
+		session.Savepoints = session.Savepoints[:i]
+	}
+}
+
+// findSavepoint returns the index of the savepoint in the session, or -1.
+// Savepoint names are case insensitive. The mutex must be held.
+func (session *SafeSession) findSavepoint(name sqlparser.ColIdent) int {
+	query := sqlparser.String(&sqlparser.Savepoint{Name: name})
+	for i, savepoint := range session.Savepoints {
+		if strings.EqualFold(savepoint, query) {
+			return i
+		}
+	}
+	return -1
 }
 
 // InReservedConn returns true if the session needs to execute on a dedicated connection
@@ -364,15 +409,50 @@ func (session *SafeSession) SetReservedConn(reservedConn bool) {
 func (session *SafeSession) SetPreQueries() []string {
 	session.mu.Lock()
 	defer session.mu.Unlock()
-	result := make([]string, len(session.SystemVariables))
-	idx := 0
-	for k, v := range session.SystemVariables {
-		result[idx] = fmt.Sprintf("set @@%s = %s", k, v)
-		idx++
+	return session.setPreQueries()
+}
+
+// setPreQueries returns the prequeries, sorted by variable name so that
+// equal settings look the same. The mutex must be held.
+func (session *SafeSession) setPreQueries() []string {
+	names := make([]string, 0, len(session.SystemVariables))
+	for k := range session.SystemVariables {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	result := make([]string, len(names))
+	for i, k := range names {
+		result[i] = fmt.Sprintf("set @@%s = %s", k, session.SystemVariables[k])
 	}
 	return result
 }
 
+// CanUseSettingsPool returns true if the system variables set in the
+// session can be applied on pooled connections, instead of reserving
+// connections. This is not possible once the session holds connections.
+func (session *SafeSession) CanUseSettingsPool() bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return *useSettingsPool && !session.Session.InReservedConn && len(session.ShardSessions) == 0
+}
+
+// ExecuteOptions returns the options to execute the queries of the session
+// with. They include the system settings of the session, unless it uses
+// reserved connections, which have the settings applied already.
+func (session *SafeSession) ExecuteOptions() *querypb.ExecuteOptions {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if !*useSettingsPool || session.Session.InReservedConn || len(session.SystemVariables) == 0 {
+		return session.Options
+	}
+	options := &querypb.ExecuteOptions{}
+	if session.Options != nil {
+		options = proto.Clone(session.Options).(*querypb.ExecuteOptions)
+	}
+	options.Settings = session.setPreQueries()
+	return options
+}
+
 // SetLockSession sets the lock session.
 func (session *SafeSession) SetLockSession(lockSession *vtgatepb.Session_ShardSession) {
 	session.mu.Lock()
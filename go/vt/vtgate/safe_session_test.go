@@ -20,8 +20,11 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/vt/sqlparser"
+
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
@@ -67,3 +70,23 @@ func TestPrequeries(t *testing.T) {
 		t.Errorf("got %v but wanted %v", preQueries, want)
 	}
 }
+
+func TestSavepoints(t *testing.T) {
+	session := NewSafeSession(&vtgatepb.Session{InTransaction: true})
+	a, b, c := sqlparser.NewColIdent("a"), sqlparser.NewColIdent("b"), sqlparser.NewColIdent("c")
+	session.AddSavepoint(a)
+	session.AddSavepoint(b)
+	session.AddSavepoint(c)
+	assert.Equal(t, []string{"savepoint a", "savepoint b", "savepoint c"}, session.Savepoints)
+
+	// Setting a savepoint again moves it to the end. Names are case insensitive.
+	session.AddSavepoint(sqlparser.NewColIdent("A"))
+	assert.Equal(t, []string{"savepoint b", "savepoint c", "savepoint A"}, session.Savepoints)
+	assert.True(t, session.HasSavepoint(a))
+
+	session.RollbackToSavepoint(c)
+	assert.Equal(t, []string{"savepoint b", "savepoint c"}, session.Savepoints)
+	session.ReleaseSavepoint(b)
+	assert.Empty(t, session.Savepoints)
+	assert.False(t, session.HasSavepoint(c))
+}
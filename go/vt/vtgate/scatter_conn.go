@@ -205,7 +205,7 @@ func (stc *ScatterConn) ExecuteMultiShard(
 			reservedID := info.reservedID
 
 			if session != nil && session.Session != nil {
-				opts = session.Session.Options
+				opts = session.ExecuteOptions()
 			}
 
 			if autocommit {
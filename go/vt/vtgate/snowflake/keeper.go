@@ -0,0 +1,166 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snowflake
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// InstanceIDPool is the topo pool the instance ids are leased from.
+const InstanceIDPool = "snowflake"
+
+// Keeper leases an instance id through the topology service, and keeps
+// renewing it in the background. Ids can only be generated while the
+// lease is held: if a renewal fails for longer than the lease ttl,
+// another process may have taken the instance id over.
+type Keeper struct {
+	ts    *topo.Server
+	owner string
+	ttl   time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu        sync.Mutex
+	lease     *topo.InstanceIDLeaseInfo
+	gen       *Generator
+	prev      *Generator
+	validTill time.Time
+}
+
+// NewKeeper leases an instance id for owner, and starts renewing it
+// every ttl/3.
+func NewKeeper(ctx context.Context, ts *topo.Server, owner string, ttl time.Duration) (*Keeper, error) {
+	lease, err := ts.LeaseInstanceID(ctx, InstanceIDPool, MaxInstanceID, owner, ttl)
+	if err != nil {
+		return nil, err
+	}
+	gen, err := NewGenerator(lease.ID())
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Leased snowflake instance id %d", lease.ID())
+
+	k := &Keeper{
+		ts:        ts,
+		owner:     owner,
+		ttl:       ttl,
+		lease:     lease,
+		gen:       gen,
+		validTill: logutil.ProtoToTime(lease.ExpireTime),
+	}
+	renewCtx, cancel := context.WithCancel(context.Background())
+	k.cancel = cancel
+	k.wg.Add(1)
+	go k.renewLoop(renewCtx)
+	return k, nil
+}
+
+// Next returns count new ids, see Generator.Next.
+func (k *Keeper) Next(count int) ([]int64, error) {
+	k.mu.Lock()
+	gen, validTill := k.gen, k.validTill
+	k.mu.Unlock()
+
+	if gen == nil || time.Now().After(validTill) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "snowflake instance id lease is not held")
+	}
+	return gen.Next(count)
+}
+
+func (k *Keeper) renewLoop(ctx context.Context) {
+	defer k.wg.Done()
+
+	ticker := time.NewTicker(k.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		k.renew(ctx)
+	}
+}
+
+func (k *Keeper) renew(ctx context.Context) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.lease != nil {
+		err := k.ts.RenewInstanceID(ctx, k.lease, k.ttl)
+		if err == nil {
+			k.validTill = logutil.ProtoToTime(k.lease.ExpireTime)
+			return
+		}
+		if !topo.IsErrType(err, topo.BadVersion) && !topo.IsErrType(err, topo.NoNode) {
+			log.Warningf("Failed to renew snowflake instance id %d: %v", k.lease.ID(), err)
+			return
+		}
+		log.Warningf("Lost snowflake instance id %d: %v", k.lease.ID(), err)
+		k.lease = nil
+		k.prev, k.gen = k.gen, nil
+	}
+
+	// The lease was lost, try to get a new one. The new generator
+	// cannot hand out ids that collide with the previous owner of the
+	// instance id, because that owner stopped generating ids when its
+	// own lease expired.
+	lease, err := k.ts.LeaseInstanceID(ctx, InstanceIDPool, MaxInstanceID, k.owner, k.ttl)
+	if err != nil {
+		log.Warningf("Failed to lease a snowflake instance id: %v", err)
+		return
+	}
+	gen, err := NewGenerator(lease.ID())
+	if err != nil {
+		log.Warningf("Failed to create a snowflake generator: %v", err)
+		return
+	}
+	if k.prev != nil && k.prev.InstanceID() == lease.ID() {
+		// Keep the clock-skew guard of the previous generator.
+		gen = k.prev
+	}
+	log.Infof("Leased snowflake instance id %d", lease.ID())
+	k.lease = lease
+	k.gen = gen
+	k.validTill = logutil.ProtoToTime(lease.ExpireTime)
+}
+
+// Close stops renewing the lease, and releases the instance id.
+func (k *Keeper) Close(ctx context.Context) {
+	k.cancel()
+	k.wg.Wait()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.lease != nil {
+		if err := k.ts.ReleaseInstanceID(ctx, k.lease); err != nil {
+			log.Warningf("Failed to release snowflake instance id %d: %v", k.lease.ID(), err)
+		}
+	}
+	k.lease = nil
+	k.gen = nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package snowflake generates time-ordered, unique 64-bit ids without
+// coordination at generation time.
+//
+// An id is laid out as follows, from the most significant bit:
+//
+//	1 bit   always 0, so that ids are positive
+//	41 bits milliseconds since Epoch
+//	10 bits instance id, unique per generating process
+//	12 bits counter within the millisecond
+//
+// Instance ids are leased through the topology service, see Keeper.
+package snowflake
+
+import (
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	timestampBits = 41
+	instanceBits  = 10
+	counterBits   = 12
+
+	// MaxInstanceID is the largest instance id that fits in an id.
+	MaxInstanceID = 1<<instanceBits - 1
+	maxCounter    = 1<<counterBits - 1
+	maxTimestamp  = 1<<timestampBits - 1
+)
+
+// Epoch is the origin of the timestamp part of the ids.
+var Epoch = time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Generator generates ids for a single instance id. It is safe for
+// concurrent use.
+type Generator struct {
+	instanceID int64
+
+	// now is the clock, it's overridden in tests.
+	now func() time.Time
+
+	mu         sync.Mutex
+	lastMillis int64
+	counter    int64
+}
+
+// NewGenerator creates a Generator for the given instance id.
+func NewGenerator(instanceID int) (*Generator, error) {
+	if instanceID < 0 || instanceID > MaxInstanceID {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "snowflake instance id %d out of range [0, %d]", instanceID, MaxInstanceID)
+	}
+	return &Generator{
+		instanceID: int64(instanceID),
+		now:        time.Now,
+	}, nil
+}
+
+// InstanceID returns the instance id of the generator.
+func (g *Generator) InstanceID() int {
+	return int(g.instanceID)
+}
+
+// Next returns count new ids, in increasing order. It refuses to generate
+// ids if the clock moved backwards since the last call, because the ids
+// could then collide with ids that were already handed out.
+func (g *Generator) Next(count int) ([]int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ids := make([]int64, 0, count)
+	for len(ids) < count {
+		millis := g.millis()
+		switch {
+		case millis < g.lastMillis:
+			return nil, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "clock moved backwards by %v, refusing to generate snowflake ids", time.Duration(g.lastMillis-millis)*time.Millisecond)
+		case millis > maxTimestamp:
+			return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "snowflake timestamp overflow")
+		case millis == g.lastMillis:
+			if g.counter == maxCounter {
+				// The counter is exhausted for this millisecond.
+				time.Sleep(100 * time.Microsecond)
+				continue
+			}
+			g.counter++
+		default:
+			g.lastMillis = millis
+			g.counter = 0
+		}
+		ids = append(ids, millis<<(instanceBits+counterBits)|g.instanceID<<counterBits|g.counter)
+	}
+	return ids, nil
+}
+
+func (g *Generator) millis() int64 {
+	return g.now().Sub(Epoch).Milliseconds()
+}
+
+// Time returns the time at which an id was generated, with millisecond
+// precision.
+func Time(id int64) time.Time {
+	return Epoch.Add(time.Duration(id>>(instanceBits+counterBits)) * time.Millisecond)
+}
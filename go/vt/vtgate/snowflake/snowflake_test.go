@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snowflake
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestGenerator(t *testing.T) {
+	now := Epoch.Add(time.Hour)
+	g, err := NewGenerator(5)
+	require.NoError(t, err)
+	g.now = func() time.Time { return now }
+
+	ids, err := g.Next(3)
+	require.NoError(t, err)
+	base := time.Hour.Milliseconds()<<22 | 5<<12
+	assert.Equal(t, []int64{base, base + 1, base + 2}, ids)
+	assert.Equal(t, now, Time(ids[0]))
+
+	// A new millisecond resets the counter.
+	now = now.Add(time.Millisecond)
+	ids, err = g.Next(1)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{(time.Hour.Milliseconds()+1)<<22 | 5<<12}, ids)
+
+	// The clock moving backwards is refused.
+	now = now.Add(-time.Second)
+	_, err = g.Next(1)
+	assert.EqualError(t, err, "clock moved backwards by 1s, refusing to generate snowflake ids")
+}
+
+func TestGeneratorCounterExhausted(t *testing.T) {
+	start := Epoch.Add(time.Hour)
+	calls := 0
+	g, err := NewGenerator(0)
+	require.NoError(t, err)
+	g.now = func() time.Time {
+		// Move to the next millisecond once the counter is exhausted.
+		calls++
+		if calls > maxCounter+1 {
+			return start.Add(time.Millisecond)
+		}
+		return start
+	}
+
+	ids, err := g.Next(maxCounter + 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(maxCounter), ids[maxCounter]&maxCounter)
+	assert.Equal(t, start.Add(time.Millisecond), Time(ids[maxCounter+1]))
+	for i := 1; i < len(ids); i++ {
+		assert.Less(t, ids[i-1], ids[i])
+	}
+}
+
+func TestNewGeneratorOutOfRange(t *testing.T) {
+	_, err := NewGenerator(MaxInstanceID + 1)
+	assert.EqualError(t, err, "snowflake instance id 1024 out of range [0, 1023]")
+}
+
+func TestKeeper(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+
+	k1, err := NewKeeper(ctx, ts, "vtgate1", time.Minute)
+	require.NoError(t, err)
+	k2, err := NewKeeper(ctx, ts, "vtgate2", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 0, k1.gen.InstanceID())
+	assert.Equal(t, 1, k2.gen.InstanceID())
+
+	ids, err := k1.Next(2)
+	require.NoError(t, err)
+	assert.Len(t, ids, 2)
+
+	// Once released, the instance id can be leased again.
+	k1.Close(ctx)
+	_, err = k1.Next(1)
+	assert.EqualError(t, err, "snowflake instance id lease is not held")
+	k3, err := NewKeeper(ctx, ts, "vtgate3", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 0, k3.gen.InstanceID())
+
+	k2.Close(ctx)
+	k3.Close(ctx)
+}
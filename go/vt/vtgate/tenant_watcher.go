@@ -0,0 +1,215 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/srvtopo"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// tenantWatcher keeps the mappings of the tenant vindexes up to date.
+// It runs one vstream per shard of each mapping table: the copy phase
+// loads the whole mapping, after which the changes to the table are
+// applied as they come.
+type tenantWatcher struct {
+	resolver   *srvtopo.Resolver
+	retryDelay time.Duration
+
+	mu      sync.Mutex
+	streams map[tenantMapping]context.CancelFunc
+}
+
+// tenantMapping identifies the mapping table of a tenant vindex, with its
+// tenant id and key range columns.
+type tenantMapping struct {
+	table, from, to string
+}
+
+func newTenantWatcher(resolver *srvtopo.Resolver) *tenantWatcher {
+	return &tenantWatcher{
+		resolver:   resolver,
+		retryDelay: 5 * time.Second,
+		streams:    make(map[tenantMapping]context.CancelFunc),
+	}
+}
+
+// update starts watching the mapping tables of the tenant vindexes of
+// vschema, and stops watching the ones that are not used any more. A
+// mapping table whose columns changed is watched again from scratch.
+func (tw *tenantWatcher) update(vschema *vindexes.VSchema) {
+	tenants := make(map[tenantMapping]*vindexes.Tenant)
+	for _, ks := range vschema.Keyspaces {
+		for _, vindex := range ks.Vindexes {
+			if tenant, ok := vindex.(*vindexes.Tenant); ok {
+				table, from, to := tenant.MappingTable()
+				tenants[tenantMapping{table: table, from: from, to: to}] = tenant
+			}
+		}
+	}
+
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	for mapping, cancel := range tw.streams {
+		if _, ok := tenants[mapping]; !ok {
+			log.Infof("Stopping to watch tenant mapping table %s (%s, %s)", mapping.table, mapping.from, mapping.to)
+			cancel()
+			delete(tw.streams, mapping)
+		}
+	}
+	for mapping, tenant := range tenants {
+		if _, ok := tw.streams[mapping]; ok {
+			continue
+		}
+		log.Infof("Starting to watch tenant mapping table %s (%s, %s)", mapping.table, mapping.from, mapping.to)
+		ctx, cancel := context.WithCancel(context.Background())
+		tw.streams[mapping] = cancel
+		go tw.watch(ctx, tenant)
+	}
+}
+
+// stop stops watching all the mapping tables.
+func (tw *tenantWatcher) stop() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	for mapping, cancel := range tw.streams {
+		cancel()
+		delete(tw.streams, mapping)
+	}
+}
+
+func (tw *tenantWatcher) watch(ctx context.Context, tenant *vindexes.Tenant) {
+	for {
+		err := tw.stream(ctx, tenant)
+		if ctx.Err() != nil {
+			return
+		}
+		table, _, _ := tenant.MappingTable()
+		log.Warningf("Watching tenant mapping table %s failed, retrying in %v: %v", table, tw.retryDelay, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(tw.retryDelay):
+		}
+	}
+}
+
+// stream copies the mapping table, and then follows its changes. While
+// the copy is in progress, the rows are staged, and the mapping of the
+// vindex is only replaced once all the shards of the mapping table are
+// copied. The shards are streamed from directly, because vtgate's own
+// vstream does not report the end of the copy phase.
+func (tw *tenantWatcher) stream(ctx context.Context, tenant *vindexes.Tenant) error {
+	table, from, to := tenant.MappingTable()
+	keyspace, tableName := splitTableName(table)
+	rss, err := tw.resolver.ResolveDestination(ctx, keyspace, topodatapb.TabletType_PRIMARY, key.DestinationAllShards{})
+	if err != nil {
+		return err
+	}
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match:  tableName,
+			Filter: fmt.Sprintf("select %s, %s from %s", sqlescape.EscapeID(from), sqlescape.EscapeID(to), sqlescape.EscapeID(tableName)),
+		}},
+	}
+
+	tm := tenant.TenantMap()
+	var mu sync.Mutex
+	staged := make(map[uint64]*topodatapb.KeyRange)
+	copying := len(rss)
+	apply := func(fields []*querypb.Field, row *querypb.Row, deleted bool) {
+		id, kr, err := tenant.ParseMapping(sqltypes.MakeRowTrusted(fields, row))
+		if err != nil {
+			log.Warningf("Skipping invalid row of tenant mapping table %s: %v", table, err)
+			return
+		}
+		switch {
+		case staged != nil && deleted:
+			delete(staged, id)
+		case staged != nil:
+			staged[id] = kr
+		case deleted:
+			tm.Delete(id)
+		default:
+			tm.Set(id, kr)
+		}
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	for _, rs := range rss {
+		rs := rs
+		g.Go(func() error {
+			var fields []*querypb.Field
+			// An empty position starts with a copy of the table.
+			err := rs.Gateway.VStream(ctx, rs.Target, "", nil, filter, func(events []*binlogdatapb.VEvent) error {
+				mu.Lock()
+				defer mu.Unlock()
+				for _, event := range events {
+					switch event.Type {
+					case binlogdatapb.VEventType_FIELD:
+						fields = event.FieldEvent.Fields
+					case binlogdatapb.VEventType_ROW:
+						for _, change := range event.RowEvent.RowChanges {
+							if change.Before != nil {
+								apply(fields, change.Before, true)
+							}
+							if change.After != nil {
+								apply(fields, change.After, false)
+							}
+						}
+					case binlogdatapb.VEventType_LASTPK:
+						if staged == nil || !event.LastPKEvent.Completed {
+							continue
+						}
+						copying--
+						if copying == 0 {
+							log.Infof("Loaded %d tenants from tenant mapping table %s", len(staged), table)
+							tm.Reset(staged)
+							staged = nil
+						}
+					}
+				}
+				return nil
+			})
+			if err == nil {
+				err = fmt.Errorf("vstream from %s/%s ended", rs.Target.Keyspace, rs.Target.Shard)
+			}
+			return err
+		})
+	}
+	return g.Wait()
+}
+
+func splitTableName(table string) (keyspace, name string) {
+	parts := strings.SplitN(table, ".", 2)
+	return parts[0], parts[1]
+}
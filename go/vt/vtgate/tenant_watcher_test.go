@@ -0,0 +1,146 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/discovery"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/srvtopo"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestTenantWatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cell := "aa"
+	ks := "TestTenantWatcher"
+	s := createSandbox(ks)
+	s.ShardSpec = "-80-"
+	hc := discovery.NewFakeHealthCheck()
+	st := getSandboxTopo(ctx, cell, ks, []string{"-80", "80-"})
+	sbc0 := hc.AddTestTablet(cell, "1.1.1.1", 1001, ks, "-80", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	addTabletToSandboxTopo(t, st, ks, "-80", sbc0.Tablet())
+	sbc1 := hc.AddTestTablet(cell, "1.1.1.1", 1002, ks, "80-", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	addTabletToSandboxTopo(t, st, ks, "80-", sbc1.Tablet())
+
+	vind, err := vindexes.CreateVindex("tenant", "tenant", map[string]string{"table": ks + ".tenant_map"})
+	require.NoError(t, err)
+	tenant := vind.(*vindexes.Tenant)
+
+	fields := []*querypb.Field{
+		{Name: "tenant_id", Type: sqltypes.Int64},
+		{Name: "keyrange", Type: sqltypes.VarChar},
+	}
+	row := func(id int64, kr string) *querypb.Row {
+		return sqltypes.RowToProto3([]sqltypes.Value{sqltypes.NewInt64(id), sqltypes.NewVarChar(kr)})
+	}
+	copyCompleted := &binlogdatapb.VEvent{
+		Type: binlogdatapb.VEventType_LASTPK,
+		LastPKEvent: &binlogdatapb.LastPKEvent{
+			TableLastPK: &binlogdatapb.TableLastPK{TableName: "tenant_map"},
+			Completed:   true,
+		},
+	}
+	sbc0.AddVStreamEvents([]*binlogdatapb.VEvent{
+		{Type: binlogdatapb.VEventType_FIELD, FieldEvent: &binlogdatapb.FieldEvent{TableName: "tenant_map", Fields: fields}},
+		{Type: binlogdatapb.VEventType_ROW, RowEvent: &binlogdatapb.RowEvent{TableName: "tenant_map", RowChanges: []*binlogdatapb.RowChange{
+			{After: row(1, "40-80")},
+			{After: row(2, "80-c0")},
+		}}},
+		copyCompleted,
+	}, nil)
+	sbc0.AddVStreamEvents([]*binlogdatapb.VEvent{
+		{Type: binlogdatapb.VEventType_FIELD, FieldEvent: &binlogdatapb.FieldEvent{TableName: "tenant_map", Fields: fields}},
+		{Type: binlogdatapb.VEventType_ROW, RowEvent: &binlogdatapb.RowEvent{TableName: "tenant_map", RowChanges: []*binlogdatapb.RowChange{
+			{Before: row(2, "80-c0")},
+			{After: row(3, "c0-")},
+		}}},
+	}, nil)
+	sbc1.AddVStreamEvents([]*binlogdatapb.VEvent{copyCompleted}, nil)
+
+	gw := NewTabletGateway(ctx, hc, st, cell)
+	tw := newTenantWatcher(srvtopo.NewResolver(st, gw, cell))
+	defer tw.stop()
+	tw.update(&vindexes.VSchema{
+		Keyspaces: map[string]*vindexes.KeyspaceSchema{
+			ks: {Vindexes: map[string]vindexes.Vindex{"tenant": tenant}},
+		},
+	})
+
+	tm := tenant.TenantMap()
+	require.Eventually(t, func() bool {
+		return tm.Loaded() && tm.KeyRange(3) != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "40-80", key.KeyRangeString(tm.KeyRange(1)))
+	assert.Nil(t, tm.KeyRange(2))
+	assert.Equal(t, "c0-", key.KeyRangeString(tm.KeyRange(3)))
+}
+
+func TestTenantWatcherUpdate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cell := "aa"
+	ks := "TestTenantWatcherUpdate"
+	createSandbox(ks)
+	hc := discovery.NewFakeHealthCheck()
+	st := getSandboxTopo(ctx, cell, ks, []string{"-80", "80-"})
+	gw := NewTabletGateway(ctx, hc, st, cell)
+	tw := newTenantWatcher(srvtopo.NewResolver(st, gw, cell))
+	defer tw.stop()
+
+	vschema := func(params map[string]string) *vindexes.VSchema {
+		vind, err := vindexes.CreateVindex("tenant", "tenant", params)
+		require.NoError(t, err)
+		return &vindexes.VSchema{
+			Keyspaces: map[string]*vindexes.KeyspaceSchema{
+				ks: {Vindexes: map[string]vindexes.Vindex{"tenant": vind}},
+			},
+		}
+	}
+	streams := func() []tenantMapping {
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		var mappings []tenantMapping
+		for mapping := range tw.streams {
+			mappings = append(mappings, mapping)
+		}
+		return mappings
+	}
+
+	table := ks + ".tenant_map"
+	tw.update(vschema(map[string]string{"table": table}))
+	assert.Equal(t, []tenantMapping{{table: table, from: "tenant_id", to: "keyrange"}}, streams())
+
+	// A change of the columns of the mapping table restarts its stream.
+	tw.update(vschema(map[string]string{"table": table, "from": "id", "to": "shard_range"}))
+	assert.Equal(t, []tenantMapping{{table: table, from: "id", to: "shard_range"}}, streams())
+
+	tw.update(&vindexes.VSchema{})
+	assert.Empty(t, streams())
+}
@@ -32,6 +32,7 @@ import (
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 )
 
@@ -222,6 +223,68 @@ func (txc *TxConn) Rollback(ctx context.Context, session *SafeSession) error {
 	return err
 }
 
+// Savepoint sets the savepoint on all the shards in the transaction, and
+// records it in the session so that the shards that join the transaction
+// later get it too.
+func (txc *TxConn) Savepoint(ctx context.Context, session *SafeSession, name sqlparser.ColIdent) error {
+	if err := txc.runSavepointQuery(ctx, session, &sqlparser.Savepoint{Name: name}); err != nil {
+		return err
+	}
+	session.AddSavepoint(name)
+	return nil
+}
+
+// RollbackToSavepoint rolls back all the shards in the transaction to the
+// savepoint. The shards that joined the transaction after the savepoint
+// was set got it when they joined, so they're rolled back too. If the
+// rollback fails on any shard, the shards can't be kept consistent with
+// each other, and the whole transaction is rolled back.
+func (txc *TxConn) RollbackToSavepoint(ctx context.Context, session *SafeSession, name sqlparser.ColIdent) error {
+	stmt := &sqlparser.SRollback{Name: name}
+	if !session.HasSavepoint(name) {
+		return vterrors.NewErrorf(vtrpcpb.Code_NOT_FOUND, vterrors.SPDoesNotExist, "SAVEPOINT does not exist: %s", sqlparser.String(stmt))
+	}
+	if err := txc.runSavepointQuery(ctx, session, stmt); err != nil {
+		_ = txc.Rollback(ctx, session)
+		return vterrors.Errorf(vtrpcpb.Code_ABORTED, "rollback to savepoint failed, the transaction was rolled back: %v", err)
+	}
+	session.RollbackToSavepoint(name)
+	return nil
+}
+
+// ReleaseSavepoint releases the savepoint, and the ones set after it,
+// on all the shards in the transaction.
+func (txc *TxConn) ReleaseSavepoint(ctx context.Context, session *SafeSession, name sqlparser.ColIdent) error {
+	stmt := &sqlparser.Release{Name: name}
+	if !session.HasSavepoint(name) {
+		return vterrors.NewErrorf(vtrpcpb.Code_NOT_FOUND, vterrors.SPDoesNotExist, "SAVEPOINT does not exist: %s", sqlparser.String(stmt))
+	}
+	if err := txc.runSavepointQuery(ctx, session, stmt); err != nil {
+		return err
+	}
+	session.ReleaseSavepoint(name)
+	return nil
+}
+
+func (txc *TxConn) runSavepointQuery(ctx context.Context, session *SafeSession, stmt sqlparser.Statement) error {
+	query := sqlparser.String(stmt)
+	allsessions := append(session.PreSessions, session.ShardSessions...)
+	allsessions = append(allsessions, session.PostSessions...)
+	return txc.runSessions(ctx, allsessions, func(ctx context.Context, s *vtgatepb.Session_ShardSession) error {
+		if s.TransactionId == 0 {
+			// A reserved connection that didn't begin the transaction
+			// yet. It will get the savepoints when it does.
+			return nil
+		}
+		qs, err := txc.queryService(s.TabletAlias)
+		if err != nil {
+			return err
+		}
+		_, err = qs.Execute(ctx, s.Target, query, nil, s.TransactionId, s.ReservedId, session.Options)
+		return err
+	})
+}
+
 //Release releases the reserved connection and/or rollbacks the transaction
 func (txc *TxConn) Release(ctx context.Context, session *SafeSession) error {
 	if !session.InTransaction() && !session.InReservedConn() {
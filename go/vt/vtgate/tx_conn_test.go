@@ -29,6 +29,7 @@ import (
 
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/srvtopo"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/sandboxconn"
@@ -916,6 +917,65 @@ func TestTxConnReservedRollbackFailure(t *testing.T) {
 	assert.EqualValues(t, 1, sbc1.ReleaseCount.Get(), "sbc1.ReleaseCount")
 }
 
+func TestTxConnSavepoints(t *testing.T) {
+	sc, sbc0, sbc1, rss0, rss1, rss01 := newLegacyTestTxConnEnv(t, "TestTxConn")
+	session := NewSafeSession(&vtgatepb.Session{InTransaction: true})
+	a := sqlparser.NewColIdent("a")
+	b := sqlparser.NewColIdent("b")
+	sqls := func(sbc *sandboxconn.SandboxConn) []string {
+		var sqls []string
+		for _, query := range sbc.Queries {
+			sqls = append(sqls, query.Sql)
+		}
+		return sqls
+	}
+
+	// Savepoint a is set before any shard joins the transaction.
+	require.NoError(t, sc.txConn.Savepoint(ctx, session, a))
+	_, errs := sc.ExecuteMultiShard(ctx, rss0, queries, session, false, false)
+	require.Empty(t, errs)
+	require.NoError(t, sc.txConn.Savepoint(ctx, session, b))
+	assert.Equal(t, []string{"savepoint a", "query1", "savepoint b"}, sqls(sbc0))
+
+	// Shard 1 joins late, and gets both savepoints.
+	_, errs = sc.ExecuteMultiShard(ctx, rss1, queries, session, false, false)
+	require.Empty(t, errs)
+	assert.Equal(t, []string{"savepoint a", "savepoint b", "query1"}, sqls(sbc1))
+
+	// Rolling back to a keeps a, and forgets b.
+	sbc0.Queries = nil
+	sbc1.Queries = nil
+	require.NoError(t, sc.txConn.RollbackToSavepoint(ctx, session, a))
+	assert.Equal(t, []string{"rollback to a"}, sqls(sbc0))
+	assert.Equal(t, []string{"rollback to a"}, sqls(sbc1))
+	assert.Equal(t, []string{"savepoint a"}, session.Savepoints)
+	err := sc.txConn.RollbackToSavepoint(ctx, session, b)
+	require.EqualError(t, err, "SAVEPOINT does not exist: rollback to b")
+	assert.Equal(t, vtrpcpb.Code_NOT_FOUND, vterrors.Code(err))
+
+	// Releasing a forgets it.
+	require.NoError(t, sc.txConn.ReleaseSavepoint(ctx, session, a))
+	assert.Empty(t, session.Savepoints)
+	require.EqualError(t, sc.txConn.ReleaseSavepoint(ctx, session, a), "SAVEPOINT does not exist: release savepoint a")
+
+	require.NoError(t, sc.txConn.Rollback(ctx, session))
+
+	// A failed rollback to savepoint rolls back the whole transaction.
+	session = NewSafeSession(&vtgatepb.Session{InTransaction: true})
+	_, errs = sc.ExecuteMultiShard(ctx, rss01, twoQueries, session, false, false)
+	require.Empty(t, errs)
+	require.NoError(t, sc.txConn.Savepoint(ctx, session, a))
+	sbc1.MustFailCodes[vtrpcpb.Code_INVALID_ARGUMENT] = 1
+	err = sc.txConn.RollbackToSavepoint(ctx, session, a)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rollback to savepoint failed, the transaction was rolled back")
+	assert.Equal(t, vtrpcpb.Code_ABORTED, vterrors.Code(err))
+	assert.False(t, session.InTransaction())
+	assert.Empty(t, session.Savepoints)
+	assert.EqualValues(t, 2, sbc0.RollbackCount.Get(), "sbc0.RollbackCount")
+	assert.EqualValues(t, 2, sbc1.RollbackCount.Get(), "sbc1.RollbackCount")
+}
+
 func TestTxConnResolveOnPrepare(t *testing.T) {
 	sc, sbc0, sbc1, _, _, _ := newLegacyTestTxConnEnv(t, "TestTxConn")
 
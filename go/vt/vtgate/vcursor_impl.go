@@ -68,6 +68,8 @@ type iExecute interface {
 	ExecuteMessageStream(ctx context.Context, rss []*srvtopo.ResolvedShard, name string, callback func(*sqltypes.Result) error) error
 	ExecuteVStream(ctx context.Context, rss []*srvtopo.ResolvedShard, filter *binlogdatapb.Filter, gtid string, callback func(evs []*binlogdatapb.VEvent) error) error
 
+	GenerateSnowflakeIDs(count int) ([]int64, error)
+
 	// TODO: remove when resolver is gone
 	ParseDestinationTarget(targetString string) (string, topodatapb.TabletType, key.Destination, error)
 	VSchema() *vindexes.VSchema
@@ -472,7 +474,7 @@ func (vc *vcursorImpl) ExecuteStandalone(query string, bindVars map[string]*quer
 // StreamExeculteMulti is the streaming version of ExecuteMultiShard.
 func (vc *vcursorImpl) StreamExecuteMulti(query string, rss []*srvtopo.ResolvedShard, bindVars []map[string]*querypb.BindVariable, callback func(reply *sqltypes.Result) error) []error {
 	atomic.AddUint64(&vc.logStats.ShardQueries, uint64(len(rss)))
-	return vc.executor.StreamExecuteMulti(vc.ctx, vc.marginComments.Leading+query+vc.marginComments.Trailing, rss, bindVars, vc.safeSession.Options, callback)
+	return vc.executor.StreamExecuteMulti(vc.ctx, vc.marginComments.Leading+query+vc.marginComments.Trailing, rss, bindVars, vc.safeSession.ExecuteOptions(), callback)
 }
 
 // ExecuteKeyspaceID is part of the engine.VCursor interface.
@@ -547,6 +549,11 @@ func (vc *vcursorImpl) InReservedConn() bool {
 	return vc.safeSession.InReservedConn()
 }
 
+// CanUseSettingsPool implements the SessionActions interface
+func (vc *vcursorImpl) CanUseSettingsPool() bool {
+	return vc.safeSession.CanUseSettingsPool()
+}
+
 func (vc *vcursorImpl) ShardSession() []*srvtopo.ResolvedShard {
 	ss := vc.safeSession.GetShardSessions()
 	if len(ss) == 0 {
@@ -727,6 +734,11 @@ func (vc *vcursorImpl) KeyspaceAvailable(ks string) bool {
 	return exists
 }
 
+// GenerateSnowflakeIDs implements the VCursor interface
+func (vc *vcursorImpl) GenerateSnowflakeIDs(count int) ([]int64, error) {
+	return vc.executor.GenerateSnowflakeIDs(count)
+}
+
 // ErrorIfShardedF implements the VCursor interface
 func (vc *vcursorImpl) ErrorIfShardedF(ks *vindexes.Keyspace, warn, errFormat string, params ...interface{}) error {
 	if ks.Sharded {
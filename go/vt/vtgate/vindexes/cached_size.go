@@ -35,10 +35,12 @@ func (cached *AutoIncrement) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(48)
+		size += int64(64)
 	}
 	// field Column vitess.io/vitess/go/vt/sqlparser.ColIdent
 	size += cached.Column.CachedSize(false)
+	// field Type string
+	size += hack.RuntimeAllocSize(int64(len(cached.Type)))
 	// field Sequence *vitess.io/vitess/go/vt/vtgate/vindexes.Table
 	size += cached.Sequence.CachedSize(true)
 	return size
@@ -105,7 +107,7 @@ func (cached *ColumnVindex) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(80)
+		size += int64(88)
 	}
 	// field Columns []vitess.io/vitess/go/vt/sqlparser.ColIdent
 	{
@@ -122,6 +124,8 @@ func (cached *ColumnVindex) CachedSize(alloc bool) int64 {
 	if cc, ok := cached.Vindex.(cachedObject); ok {
 		size += cc.CachedSize(true)
 	}
+	// field JSONPath *vitess.io/vitess/go/vt/vtgate/vindexes.JSONPath
+	size += cached.JSONPath.CachedSize(true)
 	return size
 }
 func (cached *ConsistentLookup) CachedSize(alloc bool) int64 {
@@ -148,6 +152,20 @@ func (cached *ConsistentLookupUnique) CachedSize(alloc bool) int64 {
 	size += cached.clCommon.CachedSize(true)
 	return size
 }
+func (cached *Geo) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(40)
+	}
+	// field name string
+	size += hack.RuntimeAllocSize(int64(len(cached.name)))
+	// field encoding string
+	size += hack.RuntimeAllocSize(int64(len(cached.encoding)))
+	return size
+}
 func (cached *Hash) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -160,6 +178,25 @@ func (cached *Hash) CachedSize(alloc bool) int64 {
 	size += hack.RuntimeAllocSize(int64(len(cached.name)))
 	return size
 }
+func (cached *JSONPath) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(40)
+	}
+	// field path string
+	size += hack.RuntimeAllocSize(int64(len(cached.path)))
+	// field legs []vitess.io/vitess/go/vt/vtgate/vindexes.jsonPathLeg
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.legs)) * int64(24))
+		for _, elem := range cached.legs {
+			size += elem.CachedSize(false)
+		}
+	}
+	return size
+}
 func (cached *Keyspace) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -405,6 +442,50 @@ func (cached *Table) CachedSize(alloc bool) int64 {
 	size += hack.RuntimeAllocSize(int64(cap(cached.Pinned)))
 	return size
 }
+func (cached *Tenant) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(72)
+	}
+	// field name string
+	size += hack.RuntimeAllocSize(int64(len(cached.name)))
+	// field table string
+	size += hack.RuntimeAllocSize(int64(len(cached.table)))
+	// field from string
+	size += hack.RuntimeAllocSize(int64(len(cached.from)))
+	// field to string
+	size += hack.RuntimeAllocSize(int64(len(cached.to)))
+	// field tenants *vitess.io/vitess/go/vt/vtgate/vindexes.TenantMap
+	size += cached.tenants.CachedSize(true)
+	return size
+}
+func (cached *TenantMap) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(40)
+	}
+	// field keyRanges map[uint64]*vitess.io/vitess/go/vt/proto/topodata.KeyRange
+	if cached.keyRanges != nil {
+		size += int64(48)
+		hmap := reflect.ValueOf(cached.keyRanges)
+		numBuckets := int(math.Pow(2, float64((*(*uint8)(unsafe.Pointer(hmap.Pointer() + uintptr(9)))))))
+		numOldBuckets := (*(*uint16)(unsafe.Pointer(hmap.Pointer() + uintptr(10))))
+		size += hack.RuntimeAllocSize(int64(numOldBuckets * 144))
+		if len(cached.keyRanges) > 0 || numBuckets > 1 {
+			size += hack.RuntimeAllocSize(int64(numBuckets * 144))
+		}
+		for _, v := range cached.keyRanges {
+			size += v.CachedSize(true)
+		}
+	}
+	return size
+}
 func (cached *UnicodeLooseMD5) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -474,6 +555,18 @@ func (cached *clCommon) CachedSize(alloc bool) int64 {
 	size += hack.RuntimeAllocSize(int64(len(cached.updateLookupQuery)))
 	return size
 }
+func (cached *jsonPathLeg) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(24)
+	}
+	// field member string
+	size += hack.RuntimeAllocSize(int64(len(cached.member)))
+	return size
+}
 func (cached *lookupInternal) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -0,0 +1,377 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/golang/geo/r1"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+var (
+	_ MultiColumn = (*Geo)(nil)
+	_ Ranged      = (*Geo)(nil)
+)
+
+const (
+	// GeoEncodingS2 maps points to the S2 cell that contains them.
+	GeoEncodingS2 = "s2"
+	// GeoEncodingGeohash maps points to their geohash.
+	GeoEncodingGeohash = "geohash"
+
+	defaultS2Level          = 10
+	maxS2Level              = 30
+	defaultGeohashPrecision = 5
+	maxGeohashPrecision     = 12
+
+	// geoMaxCoverCells is the maximum number of cells
+	// used to cover a bounding box.
+	geoMaxCoverCells = 16
+)
+
+func init() {
+	Register("geo", NewGeo)
+}
+
+// Geo is a multi-column unique vindex that maps a point, given as
+// a latitude and a longitude column, to a keyspace id that preserves
+// locality: points that are close to each other get keyspace ids
+// that are close to each other, and end up in the same shard.
+//
+// The keyspace id is either the id of the S2 cell that contains the
+// point, or its geohash, in 8 bytes. All the points of a cell or of
+// a geohash prefix get the same keyspace id.
+//
+// Queries with a lower and an upper bound on both columns, like
+// lat BETWEEN 48.8 AND 48.9 AND lng BETWEEN 2.2 AND 2.4, are only sent
+// to the shards that can contain matching points.
+//
+// The supported params are:
+//   encoding: s2 or geohash, s2 by default.
+//   level: the S2 cell level, from 0 to 30, 10 by default.
+//   precision: the number of geohash characters, from 1 to 12, 5 by default.
+type Geo struct {
+	name     string
+	encoding string
+	level    int
+}
+
+// NewGeo creates a Geo vindex.
+func NewGeo(name string, m map[string]string) (Vindex, error) {
+	g := &Geo{
+		name:     name,
+		encoding: GeoEncodingS2,
+	}
+	if encoding, ok := m["encoding"]; ok {
+		g.encoding = encoding
+	}
+	var err error
+	switch g.encoding {
+	case GeoEncodingS2:
+		g.level, err = geoParam(m, "level", defaultS2Level, 0, maxS2Level)
+	case GeoEncodingGeohash:
+		var precision int
+		precision, err = geoParam(m, "precision", defaultGeohashPrecision, 1, maxGeohashPrecision)
+		// A geohash character is made of 5 bits.
+		g.level = 5 * precision
+	default:
+		return nil, fmt.Errorf("geo: encoding must be %s or %s: %q", GeoEncodingS2, GeoEncodingGeohash, g.encoding)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func geoParam(m map[string]string, name string, def, min, max int) (int, error) {
+	s, ok := m[name]
+	if !ok {
+		return def, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("geo: %s must be a number from %d to %d: %q", name, min, max, s)
+	}
+	return v, nil
+}
+
+// String returns the name of the vindex.
+func (g *Geo) String() string {
+	return g.name
+}
+
+// Cost returns the cost of this index as 1.
+func (g *Geo) Cost() int {
+	return 1
+}
+
+// IsUnique returns true since the Vindex is unique.
+func (g *Geo) IsUnique() bool {
+	return true
+}
+
+// NeedsVCursor satisfies the Vindex interface.
+func (g *Geo) NeedsVCursor() bool {
+	return false
+}
+
+// Map satisfies MultiColumn. Each row must be made of a latitude and
+// a longitude, in degrees.
+func (g *Geo) Map(vcursor VCursor, rowsColValues [][]sqltypes.Value) ([]key.Destination, error) {
+	destinations := make([]key.Destination, 0, len(rowsColValues))
+	for _, row := range rowsColValues {
+		lat, lng, ok := geoPoint(row)
+		if !ok {
+			destinations = append(destinations, key.DestinationNone{})
+			continue
+		}
+		destinations = append(destinations, key.DestinationKeyspaceID(g.keyspaceID(lat, lng)))
+	}
+	return destinations, nil
+}
+
+// Verify satisfies MultiColumn.
+func (g *Geo) Verify(vcursor VCursor, rowsColValues [][]sqltypes.Value, ksids [][]byte) ([]bool, error) {
+	result := make([]bool, len(rowsColValues))
+	for i, row := range rowsColValues {
+		lat, lng, ok := geoPoint(row)
+		if !ok {
+			continue
+		}
+		result[i] = bytes.Equal(g.keyspaceID(lat, lng), ksids[i])
+	}
+	return result, nil
+}
+
+// BoundingBox returns the key ranges that contain all the points of
+// the bounding box going from (minLat, minLng) to (maxLat, maxLng).
+// A box with minLng greater than maxLng crosses the antimeridian.
+// The key ranges may contain points outside of the box, but never
+// more than a few, so that a query for the box only needs to be
+// sent to the shards that contain them.
+func (g *Geo) BoundingBox(minLat, minLng, maxLat, maxLng float64) (key.Destination, error) {
+	if !validLatLng(minLat, minLng) || !validLatLng(maxLat, maxLng) || minLat > maxLat {
+		return nil, fmt.Errorf("geo: invalid bounding box (%v, %v), (%v, %v)", minLat, minLng, maxLat, maxLng)
+	}
+	var krs []*topodatapb.KeyRange
+	switch g.encoding {
+	case GeoEncodingS2:
+		krs = g.s2Cover(minLat, minLng, maxLat, maxLng)
+	case GeoEncodingGeohash:
+		if minLng > maxLng {
+			krs = append(g.geohashCover(minLat, minLng, maxLat, 180), g.geohashCover(minLat, -180, maxLat, maxLng)...)
+		} else {
+			krs = g.geohashCover(minLat, minLng, maxLat, maxLng)
+		}
+	}
+	return key.DestinationKeyRanges(mergeKeyRanges(krs)), nil
+}
+
+// MapRange satisfies Ranged. from and to are the minimum and maximum
+// latitude and longitude of a bounding box. Unlike with BoundingBox, a
+// box with from[1] greater than to[1] is empty.
+func (g *Geo) MapRange(vcursor VCursor, from, to []sqltypes.Value) (key.Destination, error) {
+	if len(from) != 2 || len(to) != 2 {
+		return nil, fmt.Errorf("geo: a range needs a latitude and a longitude, got %d and %d values", len(from), len(to))
+	}
+	var bounds [4]float64
+	for i, v := range []sqltypes.Value{from[0], from[1], to[0], to[1]} {
+		f, err := evalengine.ToFloat64(v)
+		if err != nil {
+			// MySQL compares the columns with whatever these are.
+			return key.DestinationAllShards{}, nil
+		}
+		bounds[i] = f
+	}
+	minLat, minLng := math.Max(bounds[0], -90), math.Max(bounds[1], -180)
+	maxLat, maxLng := math.Min(bounds[2], 90), math.Min(bounds[3], 180)
+	if minLat > maxLat || minLng > maxLng {
+		return key.DestinationNone{}, nil
+	}
+	return g.BoundingBox(minLat, minLng, maxLat, maxLng)
+}
+
+func (g *Geo) keyspaceID(lat, lng float64) []byte {
+	var id uint64
+	switch g.encoding {
+	case GeoEncodingS2:
+		id = uint64(s2.CellIDFromLatLng(s2.LatLngFromDegrees(lat, lng)).Parent(g.level))
+	case GeoEncodingGeohash:
+		latBits, lngBits := geohashBits(g.level)
+		id = geohashInterleave(geohashIndex(lat, -90, 90, latBits), geohashIndex(lng, -180, 180, lngBits), g.level)
+	}
+	ksid := make([]byte, 8)
+	binary.BigEndian.PutUint64(ksid, id)
+	return ksid
+}
+
+// s2Cover covers the bounding box with S2 cells. All the keyspace ids
+// that belong to a covering cell are contiguous.
+func (g *Geo) s2Cover(minLat, minLng, maxLat, maxLng float64) []*topodatapb.KeyRange {
+	radians := func(degrees float64) float64 {
+		return (s1.Angle(degrees) * s1.Degree).Radians()
+	}
+	rect := s2.Rect{
+		Lat: r1.Interval{Lo: radians(minLat), Hi: radians(maxLat)},
+		Lng: s1.IntervalFromEndpoints(radians(minLng), radians(maxLng)),
+	}
+	rc := &s2.RegionCoverer{MinLevel: 0, MaxLevel: g.level, MaxCells: geoMaxCoverCells}
+	var krs []*topodatapb.KeyRange
+	for _, cell := range rc.Covering(rect) {
+		krs = append(krs, geoKeyRange(uint64(cell.ChildBeginAtLevel(g.level)), uint64(cell.ChildEndAtLevel(g.level))))
+	}
+	return krs
+}
+
+// geohashCover covers the bounding box with the geohash prefixes of
+// the longest length that doesn't need more than geoMaxCoverCells.
+func (g *Geo) geohashCover(minLat, minLng, maxLat, maxLng float64) []*topodatapb.KeyRange {
+	bits := 0
+	for bits < g.level {
+		latBits, lngBits := geohashBits(bits + 1)
+		lats := geohashIndex(maxLat, -90, 90, latBits) - geohashIndex(minLat, -90, 90, latBits) + 1
+		lngs := geohashIndex(maxLng, -180, 180, lngBits) - geohashIndex(minLng, -180, 180, lngBits) + 1
+		if lats*lngs > geoMaxCoverCells {
+			break
+		}
+		bits++
+	}
+	if bits == 0 {
+		return []*topodatapb.KeyRange{{}}
+	}
+
+	latBits, lngBits := geohashBits(bits)
+	var krs []*topodatapb.KeyRange
+	for lat := geohashIndex(minLat, -90, 90, latBits); lat <= geohashIndex(maxLat, -90, 90, latBits); lat++ {
+		for lng := geohashIndex(minLng, -180, 180, lngBits); lng <= geohashIndex(maxLng, -180, 180, lngBits); lng++ {
+			start := geohashInterleave(lat, lng, bits)
+			krs = append(krs, geoKeyRange(start, start+1<<(64-bits)))
+		}
+	}
+	return krs
+}
+
+// geohashBits returns how many of the first bits of a geohash
+// are latitude and longitude bits. Geohashes start with a
+// longitude bit.
+func geohashBits(bits int) (latBits, lngBits int) {
+	return bits / 2, (bits + 1) / 2
+}
+
+// geohashIndex returns the index of the interval that contains v when
+// [min, max] is split in 2^bits intervals.
+func geohashIndex(v, min, max float64, bits int) uint64 {
+	n := uint64(1) << bits
+	i := uint64((v - min) / (max - min) * float64(n))
+	if i >= n {
+		i = n - 1
+	}
+	return i
+}
+
+// geohashInterleave interleaves the bits of the latitude and longitude
+// indexes into a geohash of the given number of bits, aligned to the
+// left of the result.
+func geohashInterleave(lat, lng uint64, bits int) uint64 {
+	latBits, lngBits := geohashBits(bits)
+	var hash uint64
+	for i := 0; i < bits; i++ {
+		hash <<= 1
+		if i%2 == 0 {
+			lngBits--
+			hash |= (lng >> lngBits) & 1
+		} else {
+			latBits--
+			hash |= (lat >> latBits) & 1
+		}
+	}
+	if bits == 0 {
+		return 0
+	}
+	return hash << (64 - bits)
+}
+
+// geoKeyRange returns the key range [start, end). An end of 0
+// stands for the end of the keyspace.
+func geoKeyRange(start, end uint64) *topodatapb.KeyRange {
+	kr := &topodatapb.KeyRange{}
+	if start != 0 {
+		kr.Start = make([]byte, 8)
+		binary.BigEndian.PutUint64(kr.Start, start)
+	}
+	if end != 0 {
+		kr.End = make([]byte, 8)
+		binary.BigEndian.PutUint64(kr.End, end)
+	}
+	return kr
+}
+
+// mergeKeyRanges sorts the key ranges, and merges the ones that touch.
+func mergeKeyRanges(krs []*topodatapb.KeyRange) []*topodatapb.KeyRange {
+	sort.Slice(krs, func(i, j int) bool {
+		return bytes.Compare(krs[i].Start, krs[j].Start) < 0
+	})
+	var merged []*topodatapb.KeyRange
+	for _, kr := range krs {
+		if len(merged) != 0 {
+			last := merged[len(merged)-1]
+			if len(last.End) == 0 {
+				break
+			}
+			if bytes.Compare(kr.Start, last.End) <= 0 {
+				if len(kr.End) == 0 || bytes.Compare(kr.End, last.End) > 0 {
+					last.End = kr.End
+				}
+				continue
+			}
+		}
+		merged = append(merged, &topodatapb.KeyRange{Start: kr.Start, End: kr.End})
+	}
+	return merged
+}
+
+func geoPoint(row []sqltypes.Value) (lat, lng float64, ok bool) {
+	if len(row) != 2 {
+		return 0, 0, false
+	}
+	lat, err := evalengine.ToFloat64(row[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	lng, err = evalengine.ToFloat64(row[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return lat, lng, validLatLng(lat, lng)
+}
+
+func validLatLng(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func createGeo(t *testing.T, params map[string]string) *Geo {
+	t.Helper()
+	vind, err := CreateVindex("geo", "geo", params)
+	require.NoError(t, err)
+	return vind.(*Geo)
+}
+
+func geoRow(lat, lng float64) []sqltypes.Value {
+	return []sqltypes.Value{sqltypes.NewFloat64(lat), sqltypes.NewFloat64(lng)}
+}
+
+func TestGeoInfo(t *testing.T) {
+	geo := createGeo(t, nil)
+	assert.Equal(t, 1, geo.Cost())
+	assert.Equal(t, "geo", geo.String())
+	assert.True(t, geo.IsUnique())
+	assert.False(t, geo.NeedsVCursor())
+	assert.Equal(t, GeoEncodingS2, geo.encoding)
+	assert.Equal(t, 10, geo.level)
+
+	geo = createGeo(t, map[string]string{"encoding": "geohash", "precision": "4"})
+	assert.Equal(t, 20, geo.level)
+
+	_, err := CreateVindex("geo", "geo", map[string]string{"encoding": "h3"})
+	assert.EqualError(t, err, `geo: encoding must be s2 or geohash: "h3"`)
+	_, err = CreateVindex("geo", "geo", map[string]string{"level": "31"})
+	assert.EqualError(t, err, `geo: level must be a number from 0 to 30: "31"`)
+	_, err = CreateVindex("geo", "geo", map[string]string{"encoding": "geohash", "precision": "0"})
+	assert.EqualError(t, err, `geo: precision must be a number from 1 to 12: "0"`)
+}
+
+func TestGeoMapS2(t *testing.T) {
+	geo := createGeo(t, map[string]string{"level": "10"})
+	got, err := geo.Map(nil, [][]sqltypes.Value{
+		geoRow(48.8584, 2.2945),
+		geoRow(48.8606, 2.3376),
+		geoRow(40.6892, -74.0445),
+		{sqltypes.TestValue(sqltypes.Decimal, "48.8584"), sqltypes.NewVarChar("2.2945")},
+		geoRow(91, 0),
+		{sqltypes.NewFloat64(0)},
+		{sqltypes.NewVarChar("north"), sqltypes.NewFloat64(0)},
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 7)
+
+	// Two points a few kilometers apart share their level 10 cell,
+	// a point on another continent doesn't.
+	assert.Equal(t, got[0], got[1])
+	assert.NotEqual(t, got[0], got[2])
+	assert.Equal(t, got[0], got[3])
+	assert.Equal(t, key.DestinationNone{}, got[4])
+	assert.Equal(t, key.DestinationNone{}, got[5])
+	assert.Equal(t, key.DestinationNone{}, got[6])
+
+	ksid := got[0].(key.DestinationKeyspaceID)
+	verified, err := geo.Verify(nil, [][]sqltypes.Value{geoRow(48.8606, 2.3376), geoRow(40.6892, -74.0445), geoRow(91, 0)}, [][]byte{ksid, ksid, ksid})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, false}, verified)
+}
+
+// decodeGeohash returns the keyspace id of a geohash string.
+func decodeGeohash(t *testing.T, hash string) key.DestinationKeyspaceID {
+	t.Helper()
+	const alphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+	var id uint64
+	for _, c := range hash {
+		i := strings.IndexRune(alphabet, c)
+		require.NotEqual(t, -1, i)
+		id = id<<5 | uint64(i)
+	}
+	ksid := make([]byte, 8)
+	binary.BigEndian.PutUint64(ksid, id<<(64-5*len(hash)))
+	return ksid
+}
+
+func TestGeoMapGeohash(t *testing.T) {
+	geo := createGeo(t, map[string]string{"encoding": "geohash", "precision": "5"})
+	got, err := geo.Map(nil, [][]sqltypes.Value{
+		geoRow(57.64911, 10.40744),
+		geoRow(-25.382708, -49.265506),
+		geoRow(90, 180),
+		geoRow(-90, -180),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []key.Destination{
+		decodeGeohash(t, "u4pru"),
+		decodeGeohash(t, "6gkzw"),
+		decodeGeohash(t, "zzzzz"),
+		decodeGeohash(t, "00000"),
+	}, got)
+}
+
+func TestGeoBoundingBox(t *testing.T) {
+	boxes := []struct {
+		minLat, minLng, maxLat, maxLng float64
+	}{
+		{48.8, 2.2, 48.9, 2.4},
+		{-10, -10, 10, 10},
+		{30, 170, 40, -170},
+		{-90, -180, 90, 180},
+	}
+	vindexes := []*Geo{
+		createGeo(t, map[string]string{"level": "12"}),
+		createGeo(t, map[string]string{"encoding": "geohash", "precision": "6"}),
+	}
+	r := rand.New(rand.NewSource(1))
+	for _, geo := range vindexes {
+		for _, box := range boxes {
+			dest, err := geo.BoundingBox(box.minLat, box.minLng, box.maxLat, box.maxLng)
+			require.NoError(t, err)
+			krs := dest.(key.DestinationKeyRanges)
+			assert.LessOrEqual(t, len(krs), 2*geoMaxCoverCells)
+
+			width := box.maxLng - box.minLng
+			if width < 0 {
+				width += 360
+			}
+			for i := 0; i < 100; i++ {
+				lat := box.minLat + r.Float64()*(box.maxLat-box.minLat)
+				lng := box.minLng + r.Float64()*width
+				if lng > 180 {
+					lng -= 360
+				}
+				got, err := geo.Map(nil, [][]sqltypes.Value{geoRow(lat, lng)})
+				require.NoError(t, err)
+				ksid := got[0].(key.DestinationKeyspaceID)
+				assert.True(t, keyRangesContain(krs, ksid), "%s: (%v, %v) not in %v", geo.encoding, lat, lng, krs)
+			}
+		}
+	}
+
+	// A small box only covers a small part of the keyspace.
+	dest, err := vindexes[0].BoundingBox(48.8, 2.2, 48.9, 2.4)
+	require.NoError(t, err)
+	for _, kr := range dest.(key.DestinationKeyRanges) {
+		assert.True(t, key.KeyRangeIsPartial(kr))
+	}
+
+	_, err = vindexes[0].BoundingBox(10, 0, 0, 10)
+	assert.EqualError(t, err, "geo: invalid bounding box (10, 0), (0, 10)")
+}
+
+func TestGeoMapRange(t *testing.T) {
+	geo := createGeo(t, map[string]string{"encoding": "geohash", "precision": "6"})
+
+	dest, err := geo.MapRange(nil, geoRow(48.8, 2.2), geoRow(48.9, 2.4))
+	require.NoError(t, err)
+	want, err := geo.BoundingBox(48.8, 2.2, 48.9, 2.4)
+	require.NoError(t, err)
+	assert.Equal(t, want, dest)
+
+	// Bounds out of the valid range are clamped.
+	dest, err = geo.MapRange(nil, geoRow(-100, -200), geoRow(100, 200))
+	require.NoError(t, err)
+	want, err = geo.BoundingBox(-90, -180, 90, 180)
+	require.NoError(t, err)
+	assert.Equal(t, want, dest)
+
+	// Ranges that can't match anything.
+	dest, err = geo.MapRange(nil, geoRow(10, 0), geoRow(0, 10))
+	require.NoError(t, err)
+	assert.Equal(t, key.DestinationNone{}, dest)
+	dest, err = geo.MapRange(nil, geoRow(30, 170), geoRow(40, -170))
+	require.NoError(t, err)
+	assert.Equal(t, key.DestinationNone{}, dest)
+
+	// Bounds that aren't numbers.
+	dest, err = geo.MapRange(nil, []sqltypes.Value{sqltypes.NewVarChar("abc"), sqltypes.NewFloat64(0)}, geoRow(1, 1))
+	require.NoError(t, err)
+	assert.Equal(t, key.DestinationAllShards{}, dest)
+
+	_, err = geo.MapRange(nil, geoRow(1, 1)[:1], geoRow(1, 1))
+	assert.EqualError(t, err, "geo: a range needs a latitude and a longitude, got 1 and 2 values")
+}
+
+func keyRangesContain(krs []*topodatapb.KeyRange, ksid []byte) bool {
+	for _, kr := range krs {
+		if key.KeyRangeContains(kr, ksid) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMergeKeyRanges(t *testing.T) {
+	krs, err := key.ParseShardingSpec("-40-80-c0-")
+	require.NoError(t, err)
+	merged := mergeKeyRanges([]*topodatapb.KeyRange{krs[2], krs[0], krs[1]})
+	assert.Equal(t, []string{"-c0"}, keyRangeStrings(merged))
+
+	merged = mergeKeyRanges([]*topodatapb.KeyRange{krs[3], krs[0]})
+	assert.Equal(t, []string{"-40", "c0-"}, keyRangeStrings(merged))
+
+	merged = mergeKeyRanges([]*topodatapb.KeyRange{krs[3], {}, krs[1]})
+	assert.Equal(t, []string{"-"}, keyRangeStrings(merged))
+}
+
+func keyRangeStrings(krs []*topodatapb.KeyRange) []string {
+	var out []string
+	for _, kr := range krs {
+		out = append(out, key.KeyRangeString(kr))
+	}
+	return out
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spyzhov/ajson"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// JSONPath is the path of a value inside a JSON document. It is used
+// by column vindexes defined over an expression like doc->'$.tenant_id'
+// instead of a plain column: the vindex maps the value found at the
+// path, and equality predicates over the same expression are routed.
+// Only member and array cell legs are supported, like in $.a."b c"[0].
+type JSONPath struct {
+	path string
+	legs []jsonPathLeg
+}
+
+// jsonPathLeg is either a member, or an array cell if index is not -1.
+type jsonPathLeg struct {
+	member string
+	index  int
+}
+
+// ParseJSONPath parses a MySQL JSON path.
+func ParseJSONPath(path string) (*JSONPath, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSON path must start with $: %s", path)
+	}
+	jp := &JSONPath{path: path}
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			var member string
+			if strings.HasPrefix(rest, `"`) {
+				end := 1
+				for end < len(rest) && rest[end] != '"' {
+					if rest[end] == '\\' {
+						end++
+					}
+					end++
+				}
+				if end >= len(rest) {
+					return nil, fmt.Errorf("unterminated member in JSON path: %s", path)
+				}
+				var err error
+				if member, err = strconv.Unquote(rest[:end+1]); err != nil {
+					return nil, fmt.Errorf("invalid member in JSON path: %s", path)
+				}
+				rest = rest[end+1:]
+			} else {
+				end := strings.IndexAny(rest, ".[")
+				if end == -1 {
+					end = len(rest)
+				}
+				member, rest = rest[:end], rest[end:]
+				if member == "" || strings.ContainsAny(member, `*" `) {
+					return nil, fmt.Errorf("unsupported member in JSON path: %s", path)
+				}
+			}
+			jp.legs = append(jp.legs, jsonPathLeg{member: member, index: -1})
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated array cell in JSON path: %s", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("unsupported array cell in JSON path: %s", path)
+			}
+			jp.legs = append(jp.legs, jsonPathLeg{index: index})
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("unsupported JSON path: %s", path)
+		}
+	}
+	return jp, nil
+}
+
+// String returns the path as it was written in the vschema.
+func (jp *JSONPath) String() string {
+	return jp.path
+}
+
+// MarshalJSON marshals the path as a string.
+func (jp *JSONPath) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jp.path)
+}
+
+// Extract returns the scalar found at the path of the JSON document.
+// The result is NULL if the document is NULL, or if there's nothing
+// at the path. JSON strings are returned unquoted, so that the value
+// maps to the same keyspace id as the equivalent SQL literal.
+func (jp *JSONPath) Extract(doc sqltypes.Value) (sqltypes.Value, error) {
+	if doc.IsNull() {
+		return sqltypes.NULL, nil
+	}
+	node, err := ajson.Unmarshal(doc.Raw())
+	if err != nil {
+		return sqltypes.NULL, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid JSON document for path %s: %v", jp.path, err)
+	}
+	for _, leg := range jp.legs {
+		if leg.index == -1 {
+			if !node.IsObject() {
+				return sqltypes.NULL, nil
+			}
+			node, err = node.GetKey(leg.member)
+		} else {
+			if !node.IsArray() {
+				return sqltypes.NULL, nil
+			}
+			node, err = node.GetIndex(leg.index)
+		}
+		if err != nil {
+			return sqltypes.NULL, nil
+		}
+	}
+	switch node.Type() {
+	case ajson.Null:
+		return sqltypes.NULL, nil
+	case ajson.String:
+		s, err := node.GetString()
+		if err != nil {
+			return sqltypes.NULL, err
+		}
+		return sqltypes.NewVarChar(s), nil
+	case ajson.Numeric:
+		raw := string(node.Source())
+		if v, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return sqltypes.NewInt64(v), nil
+		}
+		if v, err := strconv.ParseUint(raw, 10, 64); err == nil {
+			return sqltypes.NewUint64(v), nil
+		}
+		v, err := node.GetNumeric()
+		if err != nil {
+			return sqltypes.NULL, err
+		}
+		return sqltypes.NewFloat64(v), nil
+	case ajson.Bool:
+		if v, _ := node.GetBool(); v {
+			return sqltypes.NewInt64(1), nil
+		}
+		return sqltypes.NewInt64(0), nil
+	}
+	return sqltypes.NULL, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "JSON path %s does not point to a scalar value", jp.path)
+}
+
+// JSONPathColumn returns the column and the path of a JSON extraction
+// expression like doc->'$.id' or doc->>'$.id'. Both forms are
+// equivalent for routing.
+func JSONPathColumn(expr sqlparser.Expr) (*sqlparser.ColName, string, bool) {
+	node, ok := expr.(*sqlparser.BinaryExpr)
+	if !ok || (node.Operator != sqlparser.JSONExtractOp && node.Operator != sqlparser.JSONUnquoteExtractOp) {
+		return nil, "", false
+	}
+	col, ok := node.Left.(*sqlparser.ColName)
+	if !ok {
+		return nil, "", false
+	}
+	path, ok := node.Right.(*sqlparser.Literal)
+	if !ok || path.Type != sqlparser.StrVal {
+		return nil, "", false
+	}
+	return col, path.Val, true
+}
+
+// parseJSONPathColumn parses a column vindex definition like
+// doc->'$.id' into its column and path.
+func parseJSONPathColumn(expr string) (sqlparser.ColIdent, *JSONPath, error) {
+	stmt, err := sqlparser.Parse("select " + expr)
+	if err != nil {
+		return sqlparser.ColIdent{}, nil, err
+	}
+	var col *sqlparser.ColName
+	var path string
+	ok := false
+	if sel, isSelect := stmt.(*sqlparser.Select); isSelect && len(sel.SelectExprs) == 1 {
+		if aliased, isAliased := sel.SelectExprs[0].(*sqlparser.AliasedExpr); isAliased && aliased.As.IsEmpty() {
+			col, path, ok = JSONPathColumn(aliased.Expr)
+		}
+	}
+	if !ok || !col.Qualifier.IsEmpty() {
+		return sqlparser.ColIdent{}, nil, fmt.Errorf("expected an expression like column->'$.path'")
+	}
+	jp, err := ParseJSONPath(path)
+	if err != nil {
+		return sqlparser.ColIdent{}, nil, err
+	}
+	return col.Name, jp, nil
+}
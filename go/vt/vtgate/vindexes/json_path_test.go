@@ -0,0 +1,124 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	jp, err := ParseJSONPath(`$.a."b c"[2].d`)
+	require.NoError(t, err)
+	assert.Equal(t, `$.a."b c"[2].d`, jp.String())
+	assert.Equal(t, []jsonPathLeg{
+		{member: "a", index: -1},
+		{member: "b c", index: -1},
+		{index: 2},
+		{member: "d", index: -1},
+	}, jp.legs)
+
+	for _, path := range []string{"a", "$.", "$.*", "$**.a", "$[*]", "$[1", `$."a`, "$a"} {
+		_, err := ParseJSONPath(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestJSONPathExtract(t *testing.T) {
+	jp, err := ParseJSONPath("$.tenant.id")
+	require.NoError(t, err)
+	testcases := []struct {
+		doc  sqltypes.Value
+		want sqltypes.Value
+	}{
+		{doc: sqltypes.NewVarChar(`{"tenant": {"id": 42}}`), want: sqltypes.NewInt64(42)},
+		{doc: sqltypes.NewVarChar(`{"tenant": {"id": 18446744073709551615}}`), want: sqltypes.NewUint64(18446744073709551615)},
+		{doc: sqltypes.NewVarChar(`{"tenant": {"id": 1.5}}`), want: sqltypes.NewFloat64(1.5)},
+		{doc: sqltypes.NewVarChar(`{"tenant": {"id": "a\"b"}}`), want: sqltypes.NewVarChar(`a"b`)},
+		{doc: sqltypes.NewVarChar(`{"tenant": {"id": true}}`), want: sqltypes.NewInt64(1)},
+		{doc: sqltypes.NewVarChar(`{"tenant": {"id": null}}`), want: sqltypes.NULL},
+		{doc: sqltypes.NewVarChar(`{"tenant": 42}`), want: sqltypes.NULL},
+		{doc: sqltypes.NewVarChar(`{}`), want: sqltypes.NULL},
+		{doc: sqltypes.NULL, want: sqltypes.NULL},
+	}
+	for _, tc := range testcases {
+		got, err := jp.Extract(tc.doc)
+		require.NoError(t, err, tc.doc.String())
+		assert.Equal(t, tc.want, got, tc.doc.String())
+	}
+
+	jp, err = ParseJSONPath("$[1]")
+	require.NoError(t, err)
+	got, err := jp.Extract(sqltypes.NewVarChar(`["a", "b"]`))
+	require.NoError(t, err)
+	assert.Equal(t, sqltypes.NewVarChar("b"), got)
+
+	_, err = jp.Extract(sqltypes.NewVarChar(`[1, {}]`))
+	assert.EqualError(t, err, "JSON path $[1] does not point to a scalar value")
+	_, err = jp.Extract(sqltypes.NewVarChar(`[1,`))
+	assert.Error(t, err)
+}
+
+func TestShardedVSchemaJSONPath(t *testing.T) {
+	build := func(column string, vindexType string, owner string) (*KeyspaceSchema, error) {
+		vschema := BuildVSchema(&vschemapb.SrvVSchema{
+			Keyspaces: map[string]*vschemapb.Keyspace{
+				"sharded": {
+					Sharded: true,
+					Vindexes: map[string]*vschemapb.Vindex{
+						"v":    {Type: vindexType, Owner: owner},
+						"stfu": {Type: "stfu"},
+					},
+					Tables: map[string]*vschemapb.Table{
+						"t1": {
+							ColumnVindexes: []*vschemapb.ColumnVindex{
+								{Column: "id", Name: "stfu"},
+								{Column: column, Name: "v"},
+							},
+						},
+					},
+				},
+			},
+		})
+		ks := vschema.Keyspaces["sharded"]
+		return ks, ks.Error
+	}
+
+	ks, err := build("doc->>'$.tenant_id'", "stfu", "")
+	require.NoError(t, err)
+	cv := ks.Tables["t1"].ColumnVindexes[1]
+	assert.Equal(t, []sqlparser.ColIdent{sqlparser.NewColIdent("doc")}, cv.Columns)
+	assert.Equal(t, "$.tenant_id", cv.JSONPath.String())
+	assert.True(t, cv.MatchesJSONPath("$.tenant_id"))
+	assert.False(t, cv.MatchesJSONPath(""))
+	assert.True(t, ks.Tables["t1"].ColumnVindexes[0].MatchesJSONPath(""))
+	assert.Equal(t, "doc ->> '$.tenant_id'", sqlparser.String(cv.Expr()))
+
+	_, err = build("t1.doc->'$.a'", "stfu", "")
+	assert.EqualError(t, err, "invalid JSON path column t1.doc->'$.a' for vindex (v) and table (t1): expected an expression like column->'$.path'")
+	_, err = build("doc->'$[*]'", "stfu", "")
+	assert.EqualError(t, err, "invalid JSON path column doc->'$[*]' for vindex (v) and table (t1): unsupported array cell in JSON path: $[*]")
+	_, err = build("doc->'$.a'", "stln", "t1")
+	assert.EqualError(t, err, "JSON path column doc->'$.a' is only supported by single column vindexes that are not owned: vindex (v) and table (t1)")
+}
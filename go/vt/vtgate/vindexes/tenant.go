@@ -0,0 +1,274 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	_ SingleColumn = (*Tenant)(nil)
+)
+
+// Tenant defines a vindex that pins tenants to key ranges. The
+// tenant to key range mapping is read from a table, and cached
+// by vtgate, which keeps it up to date through vstream. Tenants
+// that are not in the mapping table are hashed like the hash vindex.
+// Within its key range, a pinned tenant gets the keyspace id that
+// is at the same relative position as its hash in the full range,
+// which keeps it Unique.
+//
+// The supported params are:
+//   table: the mapping table, of the form keyspace.table.
+//   from: the tenant id column of the mapping table, tenant_id by default.
+//   to: the key range column of the mapping table, keyrange by default.
+//       Key ranges are written like shard names, e.g. 80-c0.
+type Tenant struct {
+	name    string
+	table   string
+	from    string
+	to      string
+	tenants *TenantMap
+}
+
+// NewTenant creates a Tenant vindex.
+func NewTenant(name string, m map[string]string) (Vindex, error) {
+	table := m["table"]
+	if len(strings.Split(table, ".")) != 2 {
+		return nil, fmt.Errorf("tenant: table should be of the form keyspace.table: %q", table)
+	}
+	from, to := m["from"], m["to"]
+	if from == "" {
+		from = "tenant_id"
+	}
+	if to == "" {
+		to = "keyrange"
+	}
+	return &Tenant{
+		name:    name,
+		table:   table,
+		from:    from,
+		to:      to,
+		tenants: TenantMapFor(table),
+	}, nil
+}
+
+// String returns the name of the vindex.
+func (vind *Tenant) String() string {
+	return vind.name
+}
+
+// Cost returns the cost of this index as 1.
+func (vind *Tenant) Cost() int {
+	return 1
+}
+
+// IsUnique returns true since the Vindex is unique.
+func (vind *Tenant) IsUnique() bool {
+	return true
+}
+
+// NeedsVCursor satisfies the Vindex interface.
+func (vind *Tenant) NeedsVCursor() bool {
+	return false
+}
+
+// MappingTable returns the qualified name of the mapping table,
+// and its tenant id and key range columns.
+func (vind *Tenant) MappingTable() (table, from, to string) {
+	return vind.table, vind.from, vind.to
+}
+
+// TenantMap returns the cached mapping of the vindex.
+func (vind *Tenant) TenantMap() *TenantMap {
+	return vind.tenants
+}
+
+// ParseMapping parses a row of the mapping table, made of the tenant
+// id and key range columns.
+func (vind *Tenant) ParseMapping(row []sqltypes.Value) (uint64, *topodatapb.KeyRange, error) {
+	if len(row) != 2 {
+		return 0, nil, fmt.Errorf("tenant: mapping row should have 2 columns: %v", row)
+	}
+	tenant, err := tenantID(row[0])
+	if err != nil {
+		return 0, nil, err
+	}
+	krs, err := key.ParseShardingSpec(row[1].ToString())
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(krs) != 1 {
+		return 0, nil, fmt.Errorf("tenant: invalid key range for tenant %d: %q", tenant, row[1].ToString())
+	}
+	return tenant, krs[0], nil
+}
+
+// TenantID converts a value of the tenant column to a tenant id.
+func (vind *Tenant) TenantID(id sqltypes.Value) (uint64, error) {
+	return tenantID(id)
+}
+
+// Map can map ids to key.Destination objects.
+func (vind *Tenant) Map(cursor VCursor, ids []sqltypes.Value) ([]key.Destination, error) {
+	if !vind.tenants.Loaded() {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "tenant: mapping from %s is not loaded yet", vind.table)
+	}
+	out := make([]key.Destination, len(ids))
+	for i, id := range ids {
+		tenant, err := tenantID(id)
+		if err != nil {
+			out[i] = key.DestinationNone{}
+			continue
+		}
+		out[i] = key.DestinationKeyspaceID(TenantKeyspaceID(tenant, vind.tenants.KeyRange(tenant)))
+	}
+	return out, nil
+}
+
+// Verify returns true if ids maps to ksids.
+func (vind *Tenant) Verify(cursor VCursor, ids []sqltypes.Value, ksids [][]byte) ([]bool, error) {
+	if !vind.tenants.Loaded() {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "tenant: mapping from %s is not loaded yet", vind.table)
+	}
+	out := make([]bool, len(ids))
+	for i := range ids {
+		tenant, err := tenantID(ids[i])
+		if err != nil {
+			return nil, err
+		}
+		out[i] = bytes.Equal(TenantKeyspaceID(tenant, vind.tenants.KeyRange(tenant)), ksids[i])
+	}
+	return out, nil
+}
+
+// tenantID converts a value to a tenant id the same way the
+// hash vindex does.
+func tenantID(id sqltypes.Value) (uint64, error) {
+	if id.IsSigned() {
+		ival, err := strconv.ParseInt(id.ToString(), 10, 64)
+		return uint64(ival), err
+	}
+	return evalengine.ToUint64(id)
+}
+
+// TenantKeyspaceID returns the keyspace id of a tenant pinned to kr.
+// If kr is nil, it returns the hash of the tenant id.
+func TenantKeyspaceID(tenant uint64, kr *topodatapb.KeyRange) []byte {
+	hashed := vhash(tenant)
+	if !key.KeyRangeIsPartial(kr) {
+		return hashed
+	}
+	// Only the first 8 bytes of the key range bounds are
+	// significant, which covers all practical shard names.
+	start := keyRangeBound(kr.Start)
+	width := keyRangeBound(kr.End) - start
+	offset, _ := bits.Mul64(binary.BigEndian.Uint64(hashed), width)
+	ksid := make([]byte, 8)
+	binary.BigEndian.PutUint64(ksid, start+offset)
+	return ksid
+}
+
+// keyRangeBound returns the first 8 bytes of a key range bound
+// as a number. The end of the full range, which is 2^64, wraps
+// around to 0, which still works for computing widths.
+func keyRangeBound(b []byte) uint64 {
+	var buf [8]byte
+	copy(buf[:], b)
+	return binary.BigEndian.Uint64(buf[:])
+}
+
+// TenantMap caches the tenant to key range mapping read from a
+// mapping table. It is shared by all Tenant vindexes that use the
+// same mapping table, so that it survives vschema reloads.
+type TenantMap struct {
+	mu        sync.RWMutex
+	loaded    bool
+	keyRanges map[uint64]*topodatapb.KeyRange
+}
+
+var tenantMaps = struct {
+	mu sync.Mutex
+	m  map[string]*TenantMap
+}{m: make(map[string]*TenantMap)}
+
+// TenantMapFor returns the TenantMap for a mapping table.
+func TenantMapFor(table string) *TenantMap {
+	tenantMaps.mu.Lock()
+	defer tenantMaps.mu.Unlock()
+	tm, ok := tenantMaps.m[table]
+	if !ok {
+		tm = &TenantMap{keyRanges: make(map[uint64]*topodatapb.KeyRange)}
+		tenantMaps.m[table] = tm
+	}
+	return tm
+}
+
+// Loaded returns true once the full mapping was loaded.
+func (tm *TenantMap) Loaded() bool {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.loaded
+}
+
+// KeyRange returns the key range a tenant is pinned to,
+// or nil if the tenant is not pinned.
+func (tm *TenantMap) KeyRange(tenant uint64) *topodatapb.KeyRange {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.keyRanges[tenant]
+}
+
+// Reset replaces the whole mapping, and marks it as loaded.
+func (tm *TenantMap) Reset(keyRanges map[uint64]*topodatapb.KeyRange) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.keyRanges = keyRanges
+	tm.loaded = true
+}
+
+// Set pins a tenant to a key range.
+func (tm *TenantMap) Set(tenant uint64, kr *topodatapb.KeyRange) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.keyRanges[tenant] = kr
+}
+
+// Delete unpins a tenant.
+func (tm *TenantMap) Delete(tenant uint64) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	delete(tm.keyRanges, tenant)
+}
+
+func init() {
+	Register("tenant", NewTenant)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func createTenant(t *testing.T, table string) *Tenant {
+	t.Helper()
+	vind, err := CreateVindex("tenant", "tenant", map[string]string{"table": table})
+	require.NoError(t, err)
+	return vind.(*Tenant)
+}
+
+func TestTenantInfo(t *testing.T) {
+	tenant := createTenant(t, "ks.tenant_info")
+	assert.Equal(t, 1, tenant.Cost())
+	assert.Equal(t, "tenant", tenant.String())
+	assert.True(t, tenant.IsUnique())
+	assert.False(t, tenant.NeedsVCursor())
+
+	table, from, to := tenant.MappingTable()
+	assert.Equal(t, "ks.tenant_info", table)
+	assert.Equal(t, "tenant_id", from)
+	assert.Equal(t, "keyrange", to)
+
+	_, err := CreateVindex("tenant", "tenant", map[string]string{"table": "tenant_info"})
+	assert.EqualError(t, err, `tenant: table should be of the form keyspace.table: "tenant_info"`)
+}
+
+func TestTenantMap(t *testing.T) {
+	tenant := createTenant(t, "ks.tenant_map")
+	ids := []sqltypes.Value{
+		sqltypes.NewInt64(1),
+		sqltypes.NewInt64(2),
+		sqltypes.NewInt64(3),
+		sqltypes.NULL,
+	}
+
+	_, err := tenant.Map(nil, ids)
+	assert.EqualError(t, err, "tenant: mapping from ks.tenant_map is not loaded yet")
+	_, err = tenant.Verify(nil, ids[:1], [][]byte{nil})
+	assert.EqualError(t, err, "tenant: mapping from ks.tenant_map is not loaded yet")
+
+	kr := &topodatapb.KeyRange{Start: []byte{0x80}, End: []byte{0xc0}}
+	tenant.TenantMap().Reset(map[uint64]*topodatapb.KeyRange{2: kr})
+
+	got, err := tenant.Map(nil, ids)
+	require.NoError(t, err)
+	hashed, err := hash.Map(nil, ids)
+	require.NoError(t, err)
+
+	// Tenants that are not pinned are hashed.
+	assert.Equal(t, hashed[0], got[0])
+	assert.Equal(t, hashed[2], got[2])
+	assert.Equal(t, key.DestinationNone{}, got[3])
+
+	// Pinned tenants end up in their key range.
+	ksid := got[1].(key.DestinationKeyspaceID)
+	assert.True(t, key.KeyRangeContains(kr, ksid), "%x not in %v", []byte(ksid), key.KeyRangeString(kr))
+
+	verified, err := tenant.Verify(nil, ids[:3], [][]byte{
+		hashed[0].(key.DestinationKeyspaceID),
+		hashed[1].(key.DestinationKeyspaceID),
+		hashed[2].(key.DestinationKeyspaceID),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, true}, verified)
+
+	// Unpinning a tenant moves it back to its hash.
+	tenant.TenantMap().Delete(2)
+	got, err = tenant.Map(nil, ids[1:2])
+	require.NoError(t, err)
+	assert.Equal(t, hashed[1], got[0])
+}
+
+func TestTenantKeyspaceID(t *testing.T) {
+	krs, err := key.ParseShardingSpec("-40-80-c0-")
+	require.NoError(t, err)
+	for _, kr := range krs {
+		for tenant := uint64(0); tenant < 100; tenant++ {
+			ksid := TenantKeyspaceID(tenant, kr)
+			assert.True(t, key.KeyRangeContains(kr, ksid), "%x not in %v", ksid, key.KeyRangeString(kr))
+		}
+	}
+	assert.Equal(t, vhash(42), TenantKeyspaceID(42, nil))
+	assert.Equal(t, vhash(42), TenantKeyspaceID(42, &topodatapb.KeyRange{}))
+}
+
+func TestTenantParseMapping(t *testing.T) {
+	tenant := createTenant(t, "ks.tenant_parse")
+
+	id, kr, err := tenant.ParseMapping([]sqltypes.Value{sqltypes.NewInt64(12), sqltypes.NewVarChar("40-80")})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(12), id)
+	assert.Equal(t, "40-80", key.KeyRangeString(kr))
+
+	_, _, err = tenant.ParseMapping([]sqltypes.Value{sqltypes.NewInt64(12), sqltypes.NewVarChar("-40-80")})
+	assert.EqualError(t, err, `tenant: invalid key range for tenant 12: "-40-80"`)
+
+	_, _, err = tenant.ParseMapping([]sqltypes.Value{sqltypes.NewInt64(12)})
+	assert.Error(t, err)
+}
@@ -109,6 +109,17 @@ type Prefixable interface {
 	PrefixVindex() SingleColumn
 }
 
+// A Ranged vindex is a MultiColumn vindex that can map a range of values
+// of each of its columns to the key ranges that contain all the matching
+// rows. It's being used to route range predicates on all of its columns,
+// like the bounding boxes of the geo vindex, to a subset of the shards.
+type Ranged interface {
+	MultiColumn
+	// MapRange returns the destination of the rows whose columns are
+	// between from and to, inclusive.
+	MapRange(vcursor VCursor, from, to []sqltypes.Value) (key.Destination, error)
+}
+
 // A Lookup vindex is one that needs to lookup
 // a previously stored map to compute the keyspace
 // id from an id. This means that the creation of
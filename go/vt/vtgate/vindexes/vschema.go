@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -54,6 +55,12 @@ const (
 	TypeReference = "reference"
 )
 
+// The following constants represent auto_increment types.
+const (
+	AutoIncrementSequence  = "sequence"
+	AutoIncrementSnowflake = "snowflake"
+)
+
 // VSchema represents the denormalized version of SrvVSchema,
 // used for building routing plans.
 type VSchema struct {
@@ -108,6 +115,33 @@ type ColumnVindex struct {
 	Name    string               `json:"name"`
 	Owned   bool                 `json:"owned,omitempty"`
 	Vindex  Vindex               `json:"vindex"`
+	// JSONPath is set if the vindex is defined over a value of
+	// the JSON document stored in its only column.
+	JSONPath *JSONPath `json:"json_path,omitempty"`
+}
+
+// MatchesJSONPath returns true if the column vindex is defined over
+// the specified JSON path. An empty path matches the column vindexes
+// that are defined over plain columns.
+func (cv *ColumnVindex) MatchesJSONPath(path string) bool {
+	if cv.JSONPath == nil {
+		return path == ""
+	}
+	return cv.JSONPath.path == path
+}
+
+// Expr returns the expression the column vindex is defined over, as
+// SQL: the column, or the unquoted extraction of the JSON path.
+func (cv *ColumnVindex) Expr() sqlparser.Expr {
+	col := &sqlparser.ColName{Name: cv.Columns[0]}
+	if cv.JSONPath == nil {
+		return col
+	}
+	return &sqlparser.BinaryExpr{
+		Left:     col,
+		Operator: sqlparser.JSONUnquoteExtractOp,
+		Right:    sqlparser.NewStrLiteral(cv.JSONPath.path),
+	}
 }
 
 // Column describes a column.
@@ -157,9 +191,11 @@ func (ks *KeyspaceSchema) MarshalJSON() ([]byte, error) {
 }
 
 // AutoIncrement contains the auto-inc information for a table.
+// Type is empty for sequences, and Sequence is nil for snowflake ids.
 type AutoIncrement struct {
 	Column   sqlparser.ColIdent `json:"column"`
-	Sequence *Table             `json:"sequence"`
+	Type     string             `json:"type,omitempty"`
+	Sequence *Table             `json:"sequence,omitempty"`
 }
 
 // BuildVSchema builds a VSchema from a SrvVSchema.
@@ -305,12 +341,24 @@ func buildTables(ks *vschemapb.Keyspace, vschema *VSchema, ksvschema *KeyspaceSc
 					columns = append(columns, sqlparser.NewColIdent(indCol))
 				}
 			}
+			var jsonPath *JSONPath
+			if len(columns) == 1 && strings.Contains(columns[0].String(), "->") {
+				expr := columns[0].String()
+				var err error
+				if columns[0], jsonPath, err = parseJSONPathColumn(expr); err != nil {
+					return fmt.Errorf("invalid JSON path column %s for vindex (%s) and table (%s): %v", expr, ind.Name, tname, err)
+				}
+				if _, ok := vindex.(SingleColumn); !ok || owned {
+					return fmt.Errorf("JSON path column %s is only supported by single column vindexes that are not owned: vindex (%s) and table (%s)", expr, ind.Name, tname)
+				}
+			}
 			columnVindex := &ColumnVindex{
-				Columns: columns,
-				Type:    vindexInfo.Type,
-				Name:    ind.Name,
-				Owned:   owned,
-				Vindex:  vindex,
+				Columns:  columns,
+				Type:     vindexInfo.Type,
+				Name:     ind.Name,
+				Owned:    owned,
+				Vindex:   vindex,
+				JSONPath: jsonPath,
 			}
 			if i == 0 {
 				// Perform Primary vindex check.
@@ -355,6 +403,26 @@ func resolveAutoIncrement(source *vschemapb.SrvVSchema, vschema *VSchema) {
 			if t == nil || table.AutoIncrement == nil {
 				continue
 			}
+			switch table.AutoIncrement.Type {
+			case "", AutoIncrementSequence:
+			case AutoIncrementSnowflake:
+				if table.AutoIncrement.Sequence != "" {
+					delete(ksvschema.Tables, tname)
+					delete(vschema.uniqueTables, tname)
+					ksvschema.Error = fmt.Errorf("auto_increment of type %s cannot have a sequence: %s", AutoIncrementSnowflake, table.AutoIncrement.Sequence)
+					continue
+				}
+				t.AutoIncrement = &AutoIncrement{
+					Column: sqlparser.NewColIdent(table.AutoIncrement.Column),
+					Type:   AutoIncrementSnowflake,
+				}
+				continue
+			default:
+				delete(ksvschema.Tables, tname)
+				delete(vschema.uniqueTables, tname)
+				ksvschema.Error = fmt.Errorf("unknown auto_increment type %s for table %s", table.AutoIncrement.Type, tname)
+				continue
+			}
 			seqks, seqtab, err := sqlparser.ParseTable(table.AutoIncrement.Sequence)
 			var seq *Table
 			if err == nil {
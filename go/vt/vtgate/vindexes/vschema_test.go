@@ -2092,6 +2092,49 @@ func TestBadSequenceName(t *testing.T) {
 	}
 }
 
+func TestSnowflakeAutoIncrement(t *testing.T) {
+	good := vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"sharded": {
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"stfu1": {
+						Type: "stfu",
+					},
+				},
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{
+							Column: "c1",
+							Name:   "stfu1",
+						}},
+						AutoIncrement: &vschemapb.AutoIncrement{
+							Column: "c1",
+							Type:   "snowflake",
+						},
+					},
+				},
+			},
+		},
+	}
+	got := BuildVSchema(&good)
+	require.NoError(t, got.Keyspaces["sharded"].Error)
+	ai := got.Keyspaces["sharded"].Tables["t1"].AutoIncrement
+	assert.Equal(t, "c1", ai.Column.String())
+	assert.Equal(t, AutoIncrementSnowflake, ai.Type)
+	assert.Nil(t, ai.Sequence)
+
+	good.Keyspaces["sharded"].Tables["t1"].AutoIncrement.Sequence = "seq"
+	got = BuildVSchema(&good)
+	assert.EqualError(t, got.Keyspaces["sharded"].Error, "auto_increment of type snowflake cannot have a sequence: seq")
+	assert.Nil(t, got.Keyspaces["sharded"].Tables["t1"])
+
+	good.Keyspaces["sharded"].Tables["t1"].AutoIncrement.Type = "uuid"
+	got = BuildVSchema(&good)
+	assert.EqualError(t, got.Keyspaces["sharded"].Error, "unknown auto_increment type uuid for table t1")
+	assert.Nil(t, got.Keyspaces["sharded"].Tables["t1"])
+}
+
 func TestBadShardedSequence(t *testing.T) {
 	bad := vschemapb.SrvVSchema{
 		Keyspaces: map[string]*vschemapb.Keyspace{
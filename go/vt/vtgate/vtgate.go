@@ -44,6 +44,7 @@ import (
 	"vitess.io/vitess/go/vt/srvtopo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/snowflake"
 	"vitess.io/vitess/go/vt/vtgate/vtgateservice"
 
 	vtschema "vitess.io/vitess/go/vt/vtgate/schema"
@@ -81,6 +82,7 @@ var (
 
 	// Put set-passthrough under a flag.
 	sysVarSetEnabled = flag.Bool("enable_system_settings", true, "This will enable the system settings to be changed per session at the database connection level")
+	useSettingsPool  = flag.Bool("enable_settings_pool", false, "If set, the system settings of a session are applied on pooled vttablet connections, instead of reserving a connection for the session. All vttablets must support settings.")
 	plannerVersion   = flag.String("planner_version", "v3", "Sets the default planner to use when the session has not changed it. Valid values are: V3, Gen4, Gen4Greedy and Gen4Fallback. Gen4Fallback tries the new gen4 planner and falls back to the V3 planner if the gen4 fails. All Gen4 versions should be considered experimental!")
 
 	// lockHeartbeatTime is used to set the next heartbeat time.
@@ -95,6 +97,10 @@ var (
 
 	enableSchemaChangeSignal = flag.Bool("schema_change_signal", false, "Enable the schema tracker")
 	schemaChangeUser         = flag.String("schema_change_signal_user", "", "User to be used to send down query to vttablet to retrieve schema changes")
+
+	// snowflake ids
+	enableSnowflakeIDs = flag.Bool("enable_snowflake_ids", false, "Enable generating snowflake ids for auto_increment columns of type snowflake. An instance id is leased through the global topo for this vtgate")
+	snowflakeLeaseTTL  = flag.Duration("snowflake_instance_id_lease_ttl", 30*time.Second, "How long the snowflake instance id lease is valid for without renewal. The lease is renewed every third of this duration")
 )
 
 func getTxMode() vtgatepb.TransactionMode {
@@ -218,6 +224,29 @@ func Init(ctx context.Context, serv srvtopo.Server, cell string, tabletTypesToWa
 
 	executor := NewExecutor(ctx, serv, cell, resolver, *normalizeQueries, *warnShardedOnly, *streamBufferSize, cacheCfg, si, *noScatter)
 
+	if *enableSnowflakeIDs {
+		ts, err := serv.GetTopoServer()
+		if err != nil {
+			log.Fatalf("Unable to get the topo server for snowflake ids: %v", err)
+		}
+		hostname, err := os.Hostname()
+		if err != nil {
+			log.Fatalf("Unable to get the hostname for snowflake ids: %v", err)
+		}
+		executor.snowflake, err = snowflake.NewKeeper(ctx, ts, fmt.Sprintf("%s-%d", hostname, os.Getpid()), *snowflakeLeaseTTL)
+		if err != nil {
+			log.Fatalf("Unable to lease a snowflake instance id: %v", err)
+		}
+	}
+
+	tw := newTenantWatcher(srvResolver)
+	executor.mu.Lock()
+	executor.tenantWatcher = tw
+	if executor.vschema != nil {
+		tw.update(executor.vschema)
+	}
+	executor.mu.Unlock()
+
 	// connect the schema tracker with the vschema manager
 	if *enableSchemaChangeSignal {
 		st.RegisterSignalReceiver(executor.vm.Rebuild)
@@ -273,6 +302,10 @@ func Init(ctx context.Context, serv srvtopo.Server, cell string, tabletTypesToWa
 		if st != nil && *enableSchemaChangeSignal {
 			st.Stop()
 		}
+		if executor.snowflake != nil {
+			executor.snowflake.Close(context.Background())
+		}
+		tw.stop()
 	})
 	rpcVTGate.registerDebugHealthHandler()
 	rpcVTGate.registerDebugEnvHandler()
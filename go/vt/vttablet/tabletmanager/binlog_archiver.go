@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+var (
+	binlogArchiveInterval = flag.Duration("binlog_archive_interval", 0, "if set, the primary uploads its closed binary logs to the backup storage at this interval, so that tablets can be restored to any point in time with RestoreFromBackup")
+
+	statsArchivedBinlogs          = stats.NewCounter("ArchivedBinlogs", "Number of binary logs archived to the backup storage")
+	statsBinlogArchiveErrors      = stats.NewCounter("BinlogArchiveErrors", "Number of failed attempts to archive binary logs")
+	statsBinlogArchiveLastSuccess = stats.NewGauge("BinlogArchiveLastSuccess", "Unix timestamp of the last successful binary log archiving run")
+)
+
+// binlogArchiver runs a poller on the primary that archives its binary logs
+// to the backup storage, once mysqld has rotated them. Like the replManager,
+// SetTabletType must be called on every tablet type change.
+type binlogArchiver struct {
+	ctx   context.Context
+	tm    *TabletManager
+	ticks *timer.Timer
+}
+
+func newBinlogArchiver(ctx context.Context, tm *TabletManager, interval time.Duration) *binlogArchiver {
+	return &binlogArchiver{
+		ctx:   ctx,
+		tm:    tm,
+		ticks: timer.NewTimer(interval),
+	}
+}
+
+func (ba *binlogArchiver) SetTabletType(tabletType topodatapb.TabletType) {
+	if *binlogArchiveInterval == 0 || ba.tm.Cnf == nil {
+		return
+	}
+	if tabletType != topodatapb.TabletType_PRIMARY {
+		ba.ticks.Stop()
+		return
+	}
+	if ba.ticks.Running() {
+		return
+	}
+	log.Info("Binlog Archiver: starting")
+	ba.ticks.Start(ba.archive)
+}
+
+func (ba *binlogArchiver) Close() {
+	ba.ticks.Stop()
+}
+
+func (ba *binlogArchiver) archive() {
+	tablet := ba.tm.Tablet()
+	count, err := mysqlctl.ArchiveBinlogs(ba.ctx, mysqlctl.ArchiveBinlogsParams{
+		Cnf:         ba.tm.Cnf,
+		Mysqld:      ba.tm.MysqlDaemon,
+		Logger:      logutil.NewConsoleLogger(),
+		Keyspace:    tablet.Keyspace,
+		Shard:       tablet.Shard,
+		TabletAlias: topoproto.TabletAliasString(tablet.Alias),
+	})
+	statsArchivedBinlogs.Add(int64(count))
+	if err != nil {
+		statsBinlogArchiveErrors.Add(1)
+		log.Errorf("Binlog Archiver: %v", err)
+		return
+	}
+	statsBinlogArchiveLastSuccess.Set(time.Now().Unix())
+}
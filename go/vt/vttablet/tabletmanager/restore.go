@@ -21,6 +21,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"vitess.io/vitess/go/mysql"
@@ -37,6 +38,7 @@ import (
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/proto/vttime"
 )
@@ -45,10 +47,12 @@ import (
 // It is only enabled if restore_from_backup is set.
 
 var (
-	restoreFromBackup      = flag.Bool("restore_from_backup", false, "(init restore parameter) will check BackupStorage for a recent backup at startup and start there")
-	restoreFromBackupTsStr = flag.String("restore_from_backup_ts", "", "(init restore parameter) if set, restore the latest backup taken at or before this timestamp. Example: '2021-04-29.133050'")
-	restoreConcurrency     = flag.Int("restore_concurrency", 4, "(init restore parameter) how many concurrent files to restore at once")
-	waitForBackupInterval  = flag.Duration("wait_for_backup_interval", 0, "(init restore parameter) if this is greater than 0, instead of starting up empty when no backups are found, keep checking at this interval for a backup to appear")
+	restoreFromBackup        = flag.Bool("restore_from_backup", false, "(init restore parameter) will check BackupStorage for a recent backup at startup and start there")
+	restoreFromBackupTsStr   = flag.String("restore_from_backup_ts", "", "(init restore parameter) if set, restore the latest backup taken at or before this timestamp. Example: '2021-04-29.133050'")
+	restoreConcurrency       = flag.Int("restore_concurrency", 4, "(init restore parameter) how many concurrent files to restore at once")
+	restoreFromKeyspaceShard = flag.String("restore_from_keyspace_shard", "", "(init restore parameter) if set, restore a logical backup of this keyspace/shard rather than of the tablet's own shard, keeping only the rows in the key range of the tablet's shard. Replication is left stopped after the restore. Used to restore a backup into a keyspace sharded differently")
+	restoreTables            = flag.String("restore_tables", "", "(init restore parameter) if set, only restore these comma separated tables of a logical backup. Only allowed in a SNAPSHOT keyspace, to restore a table to copy back with RestoreTable")
+	waitForBackupInterval    = flag.Duration("wait_for_backup_interval", 0, "(init restore parameter) if this is greater than 0, instead of starting up empty when no backups are found, keep checking at this interval for a backup to appear")
 
 	// Flags for PITR
 	binlogHost           = flag.String("binlog_host", "", "PITR restore parameter: hostname/IP of binlog server.")
@@ -120,7 +124,7 @@ func (tm *TabletManager) RestoreData(ctx context.Context, logger logutil.Logger,
 
 	startTime = time.Now()
 
-	err = tm.restoreDataLocked(ctx, logger, waitForBackupInterval, deleteBeforeRestore, backupTime)
+	err = tm.restoreDataLocked(ctx, logger, waitForBackupInterval, deleteBeforeRestore, backupTime, mysql.Position{}, time.Time{})
 	if err != nil {
 		return err
 	}
@@ -138,7 +142,11 @@ func (tm *TabletManager) RestoreData(ctx context.Context, logger logutil.Logger,
 	return nil
 }
 
-func (tm *TabletManager) restoreDataLocked(ctx context.Context, logger logutil.Logger, waitForBackupInterval time.Duration, deleteBeforeRestore bool, backupTime time.Time) error {
+// restoreDataLocked restores the latest backup, at or before backupTime if it's
+// set. If restoreToPos or restoreToTime is set, the archived binary logs are
+// replayed on top of the backup up to that point, and replication is left
+// stopped so the tablet stays there.
+func (tm *TabletManager) restoreDataLocked(ctx context.Context, logger logutil.Logger, waitForBackupInterval time.Duration, deleteBeforeRestore bool, backupTime time.Time, restoreToPos mysql.Position, restoreToTime time.Time) error {
 
 	tablet := tm.Tablet()
 	originalType := tablet.Type
@@ -164,6 +172,44 @@ func (tm *TabletManager) restoreDataLocked(ctx context.Context, logger logutil.L
 		log.Infof("Using base_keyspace %v to restore keyspace %v using a backup time of %v", keyspace, tablet.Keyspace, backupTime)
 	}
 
+	// Restore a logical backup of another shard, keeping only the rows in
+	// the key range of our shard.
+	shard := tablet.Shard
+	var keyRange *topodatapb.KeyRange
+	var vschema *vschemapb.Keyspace
+	if *restoreFromKeyspaceShard != "" {
+		keyspace, shard, err = topoproto.ParseKeyspaceShard(*restoreFromKeyspaceShard)
+		if err != nil {
+			return err
+		}
+		si, err := tm.TopoServer.GetShard(ctx, tablet.Keyspace, tablet.Shard)
+		if err != nil {
+			return err
+		}
+		if si.KeyRange != nil {
+			keyRange = si.KeyRange
+			if vschema, err = tm.TopoServer.GetVSchema(ctx, tablet.Keyspace); err != nil {
+				return vterrors.Wrapf(err, "can't get the VSchema of keyspace %v", tablet.Keyspace)
+			}
+		}
+		log.Infof("Restoring a backup of %v/%v into %v/%v", keyspace, shard, tablet.Keyspace, tablet.Shard)
+	}
+
+	// A tablet with only some of the tables can't serve its shard, nor
+	// replicate from it: only the tablets of a SNAPSHOT keyspace, which
+	// don't replicate, can restore some of the tables.
+	var tables []string
+	if *restoreTables != "" {
+		if keyspaceInfo.KeyspaceType != topodatapb.KeyspaceType_SNAPSHOT {
+			return vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, fmt.Sprintf("-restore_tables can only be used by the tablets of a SNAPSHOT keyspace, %v is not one", tablet.Keyspace))
+		}
+		for _, table := range strings.Split(*restoreTables, ",") {
+			if table = strings.TrimSpace(table); table != "" {
+				tables = append(tables, table)
+			}
+		}
+	}
+
 	params := mysqlctl.RestoreParams{
 		Cnf:                 tm.Cnf,
 		Mysqld:              tm.MysqlDaemon,
@@ -174,8 +220,13 @@ func (tm *TabletManager) restoreDataLocked(ctx context.Context, logger logutil.L
 		DeleteBeforeRestore: deleteBeforeRestore,
 		DbName:              topoproto.TabletDbName(tablet),
 		Keyspace:            keyspace,
-		Shard:               tablet.Shard,
+		Shard:               shard,
 		StartTime:           backupTime,
+		RestoreToPos:        restoreToPos,
+		RestoreToTime:       restoreToTime,
+		Tables:              tables,
+		KeyRange:            keyRange,
+		VSchema:             vschema,
 	}
 
 	// Check whether we're going to restore before changing to RESTORE type,
@@ -234,6 +285,18 @@ func (tm *TabletManager) restoreDataLocked(ctx context.Context, logger logutil.L
 	}
 	switch err {
 	case nil:
+		if !restoreToPos.IsZero() || !restoreToTime.IsZero() {
+			// Replicating would move the tablet past the point it was restored to.
+			log.Infof("Restored to %v, leaving replication stopped", pos)
+			tm.replManager.setReplicationStopped(true)
+			break
+		}
+		if *restoreFromKeyspaceShard != "" {
+			// The position of the backup is the one of another shard.
+			log.Infof("Restored a backup of %v, leaving replication stopped", *restoreFromKeyspaceShard)
+			tm.replManager.setReplicationStopped(true)
+			break
+		}
 		// Starting from here we won't be able to recover if we get stopped by a cancelled
 		// context. Thus we use the background context to get through to the finish.
 		if keyspaceInfo.KeyspaceType == topodatapb.KeyspaceType_NORMAL {
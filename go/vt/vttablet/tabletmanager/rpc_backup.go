@@ -22,12 +22,15 @@ import (
 
 	"context"
 
+	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/mysqlctl"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
 
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 const (
@@ -36,7 +39,7 @@ const (
 )
 
 // Backup takes a db backup and sends it to the BackupStorage
-func (tm *TabletManager) Backup(ctx context.Context, concurrency int, logger logutil.Logger, allowPrimary bool) error {
+func (tm *TabletManager) Backup(ctx context.Context, logger logutil.Logger, request *tabletmanagerdatapb.BackupRequest) error {
 	if tm.Cnf == nil {
 		return fmt.Errorf("cannot perform backup without my.cnf, please restart vttablet with a my.cnf file specified")
 	}
@@ -46,10 +49,11 @@ func (tm *TabletManager) Backup(ctx context.Context, concurrency int, logger log
 	// but the process didn't find out about this.
 	// It is not safe to take backups from tablet in this state
 	currentTablet := tm.Tablet()
+	allowPrimary := request.AllowPrimary
 	if !allowPrimary && currentTablet.Type == topodatapb.TabletType_PRIMARY {
 		return fmt.Errorf("type PRIMARY cannot take backup. if you really need to do this, rerun the backup command with -allow_primary")
 	}
-	engine, err := mysqlctl.GetBackupEngine()
+	engine, err := mysqlctl.GetBackupEngineByName(request.BackupEngine)
 	if err != nil {
 		return vterrors.Wrap(err, "failed to find backup engine")
 	}
@@ -107,13 +111,15 @@ func (tm *TabletManager) Backup(ctx context.Context, concurrency int, logger log
 		Cnf:          tm.Cnf,
 		Mysqld:       tm.MysqlDaemon,
 		Logger:       l,
-		Concurrency:  concurrency,
+		Concurrency:  int(request.Concurrency),
 		HookExtraEnv: tm.hookExtraEnv(),
 		TopoServer:   tm.TopoServer,
 		Keyspace:     tablet.Keyspace,
 		Shard:        tablet.Shard,
+		DbName:       topoproto.TabletDbName(tablet.Tablet),
 		TabletAlias:  topoproto.TabletAliasString(tablet.Alias),
 		BackupTime:   time.Now(),
+		BackupEngine: request.BackupEngine,
 	}
 
 	returnErr := mysqlctl.Backup(ctx, backupParams)
@@ -151,8 +157,22 @@ func (tm *TabletManager) Backup(ctx context.Context, concurrency int, logger log
 }
 
 // RestoreFromBackup deletes all local data and then restores the data from the latest backup [at
-// or before the backupTime value if specified]
-func (tm *TabletManager) RestoreFromBackup(ctx context.Context, logger logutil.Logger, backupTime time.Time) error {
+// or before the backupTime value if specified]. If a restore position or time is specified,
+// the archived binary logs are replayed on top of the backup up to that exact point.
+func (tm *TabletManager) RestoreFromBackup(ctx context.Context, logger logutil.Logger, request *tabletmanagerdatapb.RestoreFromBackupRequest) error {
+	var restoreToPos mysql.Position
+	if request.RestoreToPos != "" {
+		pos, err := mysql.DecodePosition(request.RestoreToPos)
+		if err != nil {
+			return vterrors.Wrapf(err, "invalid restore position %v", request.RestoreToPos)
+		}
+		restoreToPos = pos
+	}
+	restoreToTime := logutil.ProtoToTime(request.RestoreToTimestamp)
+	if !restoreToPos.IsZero() && !restoreToTime.IsZero() {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "only one of the restore position and the restore time can be specified")
+	}
+
 	if err := tm.lock(ctx); err != nil {
 		return err
 	}
@@ -170,7 +190,7 @@ func (tm *TabletManager) RestoreFromBackup(ctx context.Context, logger logutil.L
 	l := logutil.NewTeeLogger(logutil.NewConsoleLogger(), logger)
 
 	// now we can run restore
-	err = tm.restoreDataLocked(ctx, l, 0 /* waitForBackupInterval */, true /* deleteBeforeRestore */, backupTime)
+	err = tm.restoreDataLocked(ctx, l, 0 /* waitForBackupInterval */, true /* deleteBeforeRestore */, logutil.ProtoToTime(request.BackupTime), restoreToPos, restoreToTime)
 
 	// re-run health check to be sure to capture any replication delay
 	tm.QueryServiceControl.BroadcastHealth()
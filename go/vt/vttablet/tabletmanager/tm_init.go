@@ -140,6 +140,9 @@ type TabletManager struct {
 	// replManager manages replication.
 	replManager *replManager
 
+	// binlogArchiver archives the binary logs of the primary.
+	binlogArchiver *binlogArchiver
+
 	// tabletAlias is saved away from tablet for read-only access
 	tabletAlias *topodatapb.TabletAlias
 
@@ -245,6 +248,7 @@ func BuildTabletFromInput(alias *topodatapb.TabletAlias, port, grpcPort int32) (
 func (tm *TabletManager) Start(tablet *topodatapb.Tablet, healthCheckInterval time.Duration) error {
 	tm.DBConfigs.DBName = topoproto.TabletDbName(tablet)
 	tm.replManager = newReplManager(tm.BatchCtx, tm, healthCheckInterval)
+	tm.binlogArchiver = newBinlogArchiver(tm.BatchCtx, tm, *binlogArchiveInterval)
 	tm.tabletAlias = tablet.Alias
 	tm.tmState = newTMState(tm, tablet)
 	tm.actionSema = sync2.NewSemaphore(1, 0)
@@ -325,6 +329,7 @@ func (tm *TabletManager) Close() {
 	// running during lame duck.
 	tm.stopShardSync()
 	tm.stopRebuildKeyspace()
+	tm.binlogArchiver.Close()
 
 	// cleanup initialized fields in the tablet entry
 	f := func(tablet *topodatapb.Tablet) error {
@@ -356,6 +361,7 @@ func (tm *TabletManager) Stop() {
 	// here in addition to in Close() because tests do not call Close().
 	tm.stopShardSync()
 	tm.stopRebuildKeyspace()
+	tm.binlogArchiver.Close()
 
 	if tm.UpdateStream != nil {
 		tm.UpdateStream.Disable()
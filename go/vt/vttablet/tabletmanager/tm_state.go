@@ -250,6 +250,7 @@ func (ts *tmState) updateLocked(ctx context.Context) {
 	}
 
 	ts.tm.replManager.SetTabletType(ts.tablet.Type)
+	ts.tm.binlogArchiver.SetTabletType(ts.tablet.Type)
 
 	if ts.tm.UpdateStream != nil {
 		if topo.IsRunningUpdateStream(ts.tablet.Type) {
@@ -54,6 +54,13 @@ type DBConn struct {
 	stats   *tabletenv.Stats
 	current sync2.AtomicString
 
+	// workloadClass is the workload class the connection
+	// was handed out to, if the pool has workload classes.
+	workloadClass *workloadClass
+
+	// setting is the setting applied on the connection, if any.
+	setting *Setting
+
 	// err will be set if a query is killed through a Kill.
 	errmu sync.Mutex
 	err   error
@@ -310,6 +317,33 @@ func (dbc *DBConn) VerifyMode(strictTransTables bool) error {
 	return nil
 }
 
+// ApplySetting applies the setting on the connection, unless the
+// connection already has it. It resets the previous setting of the
+// connection first. A nil setting only resets the previous one.
+func (dbc *DBConn) ApplySetting(ctx context.Context, setting *Setting) error {
+	if dbc.setting == setting || (dbc.setting != nil && setting != nil && dbc.setting.query == setting.query) {
+		if setting != nil {
+			dbc.stats.Settings.Add("Reused", 1)
+		}
+		return nil
+	}
+	if dbc.setting != nil {
+		if _, err := dbc.Exec(ctx, dbc.setting.resetQuery, 1, false); err != nil {
+			return err
+		}
+		dbc.setting = nil
+		dbc.stats.Settings.Add("Reset", 1)
+	}
+	if setting != nil {
+		if _, err := dbc.Exec(ctx, setting.query, 1, false); err != nil {
+			return err
+		}
+		dbc.setting = setting
+		dbc.stats.Settings.Add("Applied", 1)
+	}
+	return nil
+}
+
 // Close closes the DBConn.
 func (dbc *DBConn) Close() {
 	dbc.conn.Close()
@@ -322,13 +356,17 @@ func (dbc *DBConn) IsClosed() bool {
 
 // Recycle returns the DBConn to the pool.
 func (dbc *DBConn) Recycle() {
+	class := dbc.workloadClass
+	dbc.workloadClass = nil
 	switch {
 	case dbc.pool == nil:
 		dbc.Close()
 	case dbc.conn.IsClosed():
 		dbc.pool.Put(nil)
+		dbc.pool.releaseWorkloadClass(class)
 	default:
 		dbc.pool.Put(dbc)
+		dbc.pool.releaseWorkloadClass(class)
 	}
 }
 
@@ -337,7 +375,10 @@ func (dbc *DBConn) Taint() {
 	if dbc.pool == nil {
 		return
 	}
+	class := dbc.workloadClass
+	dbc.workloadClass = nil
 	dbc.pool.Put(nil)
+	dbc.pool.releaseWorkloadClass(class)
 	dbc.pool = nil
 }
 
@@ -397,6 +438,14 @@ func (dbc *DBConn) reconnect(ctx context.Context) error {
 	dbc.err = nil
 	dbc.errmu.Unlock()
 	dbc.conn = newConn
+	// The setting is lost with the old connection.
+	if dbc.setting != nil {
+		if _, err := newConn.ExecuteFetch(dbc.setting.query, 1, false); err != nil {
+			dbc.setting = nil
+			newConn.Close()
+			return err
+		}
+	}
 	return nil
 }
 
@@ -61,6 +61,7 @@ type Pool struct {
 	waiterCount        sync2.AtomicInt64
 	dbaPool            *dbconnpool.ConnectionPool
 	appDebugParams     dbconfigs.Connector
+	scheduler          *workloadScheduler
 }
 
 // NewPool creates a new Pool. The name is used
@@ -93,6 +94,16 @@ func NewPool(env tabletenv.Env, name string, cfg tabletenv.ConnPoolConfig) *Pool
 	return cp
 }
 
+// EnableWorkloadClasses makes the pool hand out its connections to
+// the workload classes by weighted fair queuing. It must be called
+// before Open.
+func (cp *Pool) EnableWorkloadClasses(classes []tabletenv.WorkloadClassConfig) {
+	if len(classes) == 0 {
+		return
+	}
+	cp.scheduler = newWorkloadScheduler(cp.env, cp.name, cp.capacity, classes)
+}
+
 func (cp *Pool) pool() (p *pools.ResourcePool) {
 	cp.mu.Lock()
 	p = cp.connections
@@ -150,9 +161,29 @@ func (cp *Pool) Close() {
 	cp.dbaPool.Close()
 }
 
-// Get returns a connection.
+// Get returns a connection without any setting.
 // You must call Recycle on DBConn once done.
 func (cp *Pool) Get(ctx context.Context) (*DBConn, error) {
+	return cp.GetWithSetting(ctx, nil)
+}
+
+// GetWithSetting returns a connection that has the setting applied.
+// You must call Recycle on DBConn once done.
+func (cp *Pool) GetWithSetting(ctx context.Context, setting *Setting) (*DBConn, error) {
+	conn, err := cp.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.ApplySetting(ctx, setting); err != nil {
+		// The connection is left with an unknown setting.
+		conn.Close()
+		conn.Recycle()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (cp *Pool) get(ctx context.Context) (*DBConn, error) {
 	span, ctx := trace.NewSpan(ctx, "Pool.Get")
 	defer span.Finish()
 
@@ -181,11 +212,32 @@ func (cp *Pool) Get(ctx context.Context) (*DBConn, error) {
 		ctx, cancel = context.WithTimeout(ctx, cp.timeout)
 		defer cancel()
 	}
+	var class *workloadClass
+	if cp.scheduler != nil {
+		class = cp.scheduler.classFor(ctx)
+		span.Annotate("workload_class", class.name)
+		if err := cp.scheduler.acquire(ctx, class); err != nil {
+			return nil, err
+		}
+	}
 	r, err := p.Get(ctx)
 	if err != nil {
+		if class != nil {
+			cp.scheduler.release(class)
+		}
 		return nil, err
 	}
-	return r.(*DBConn), nil
+	conn := r.(*DBConn)
+	conn.workloadClass = class
+	return conn, nil
+}
+
+// releaseWorkloadClass gives back the slot of the workload class of
+// the connection, once it's back in the pool.
+func (cp *Pool) releaseWorkloadClass(class *workloadClass) {
+	if class != nil {
+		cp.scheduler.release(class)
+	}
 }
 
 // Put puts a connection into the pool.
@@ -211,6 +263,9 @@ func (cp *Pool) SetCapacity(capacity int) (err error) {
 			return err
 		}
 	}
+	if cp.scheduler != nil {
+		cp.scheduler.setCapacity(capacity)
+	}
 	cp.capacity = capacity
 	return nil
 }
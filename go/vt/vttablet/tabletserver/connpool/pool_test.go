@@ -17,6 +17,7 @@ limitations under the License.
 package connpool
 
 import (
+	"errors"
 	"runtime"
 	"sync"
 	"testing"
@@ -26,6 +27,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 
@@ -52,6 +54,49 @@ func TestConnPoolGet(t *testing.T) {
 	dbConn.Recycle()
 }
 
+func TestConnPoolGetWithSetting(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	connPool := NewPool(tabletenv.NewEnv(nil, "PoolTest"), "TestPool", tabletenv.ConnPoolConfig{
+		Size:               1,
+		IdleTimeoutSeconds: 10,
+	})
+	connPool.Open(db.ConnParams(), db.ConnParams(), db.ConnParams())
+	defer connPool.Close()
+
+	utc, err := NewSetting([]string{"set @@time_zone = 'UTC'"})
+	require.NoError(t, err)
+	db.AddQuery(utc.Query(), &sqltypes.Result{})
+	db.AddQuery(utc.ResetQuery(), &sqltypes.Result{})
+
+	// The setting is applied once, and reused by the next session with the same setting.
+	for i := 0; i < 2; i++ {
+		setting, err := NewSetting([]string{"set @@time_zone = 'UTC'"})
+		require.NoError(t, err)
+		dbConn, err := connPool.GetWithSetting(context.Background(), setting)
+		require.NoError(t, err)
+		dbConn.Recycle()
+	}
+	assert.Equal(t, 1, db.GetQueryCalledNum(utc.Query()))
+	assert.Equal(t, 0, db.GetQueryCalledNum(utc.ResetQuery()))
+
+	// A session without settings gets the setting reset.
+	dbConn, err := connPool.Get(context.Background())
+	require.NoError(t, err)
+	dbConn.Recycle()
+	assert.Equal(t, 1, db.GetQueryCalledNum(utc.ResetQuery()))
+
+	// A connection that fails to apply the setting is closed: the next
+	// session gets a new connection, without any setting to reset.
+	db.AddRejectedQuery(utc.Query(), errors.New("unknown time zone"))
+	_, err = connPool.GetWithSetting(context.Background(), utc)
+	require.Error(t, err)
+	dbConn, err = connPool.Get(context.Background())
+	require.NoError(t, err)
+	dbConn.Recycle()
+	assert.Equal(t, 1, db.GetQueryCalledNum(utc.ResetQuery()))
+}
+
 func TestConnPoolTimeout(t *testing.T) {
 	db := fakesqldb.New(t)
 	defer db.Close()
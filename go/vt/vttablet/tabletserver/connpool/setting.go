@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connpool
+
+import (
+	"strings"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Setting is a set of session system variables to apply on a pooled
+// connection. Connections remember the setting applied on them: one that
+// already has the requested setting is handed out as is. Others get their
+// previous setting reset, and the requested one applied.
+type Setting struct {
+	// query applies all the variables in a single statement.
+	// It's also the identity of the setting.
+	query string
+	// resetQuery sets the variables back to their global value.
+	resetQuery string
+}
+
+// NewSetting builds a Setting from the SET statements of a session. It
+// returns nil if there are none. Only session system variables set to
+// literal values are accepted, because the statements run on connections
+// shared with other sessions.
+func NewSetting(queries []string) (*Setting, error) {
+	if len(queries) == 0 {
+		return nil, nil
+	}
+	var exprs sqlparser.SetExprs
+	var resets []string
+	for _, query := range queries {
+		stmt, err := sqlparser.Parse(query)
+		if err != nil {
+			return nil, err
+		}
+		set, ok := stmt.(*sqlparser.Set)
+		if !ok {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid setting, not a SET statement: %s", query)
+		}
+		for _, expr := range set.Exprs {
+			name, ok := sessionVariableName(expr)
+			if !ok {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid setting, only session system variables can be set to literal values: %s", query)
+			}
+			exprs = append(exprs, expr)
+			resets = append(resets, "@@"+name+" = default")
+		}
+	}
+	return &Setting{
+		query:      sqlparser.String(&sqlparser.Set{Exprs: exprs}),
+		resetQuery: "set " + strings.Join(resets, ", "),
+	}, nil
+}
+
+// sessionVariableName returns the name of the session system variable set
+// by expr, if it's set to a literal value.
+func sessionVariableName(expr *sqlparser.SetExpr) (string, bool) {
+	if expr.Scope != sqlparser.ImplicitScope && expr.Scope != sqlparser.SessionScope {
+		return "", false
+	}
+	switch expr.Expr.(type) {
+	case *sqlparser.Literal, sqlparser.BoolVal, *sqlparser.NullVal:
+	default:
+		return "", false
+	}
+	name := strings.ToLower(expr.Name.String())
+	if !strings.HasPrefix(name, "@@") {
+		return "", false
+	}
+	name = strings.TrimPrefix(name, "@@")
+	for _, prefix := range []string{"session.", "local."} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	if name == "" || strings.Contains(name, ".") {
+		return "", false
+	}
+	return name, true
+}
+
+// Query returns the statement that applies the setting.
+func (s *Setting) Query() string {
+	return s.query
+}
+
+// ResetQuery returns the statement that resets the setting.
+func (s *Setting) ResetQuery() string {
+	return s.resetQuery
+}
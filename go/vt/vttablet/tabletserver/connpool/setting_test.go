@@ -0,0 +1,47 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connpool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSetting(t *testing.T) {
+	setting, err := NewSetting(nil)
+	require.NoError(t, err)
+	assert.Nil(t, setting)
+
+	setting, err = NewSetting([]string{"set @@sql_mode = 'STRICT_TRANS_TABLES'", "set @@session.time_zone = '+00:00', @@sql_safe_updates = 1"})
+	require.NoError(t, err)
+	assert.Equal(t, "set @@sql_mode = 'STRICT_TRANS_TABLES', @@session.time_zone = '+00:00', @@sql_safe_updates = 1", setting.Query())
+	assert.Equal(t, "set @@sql_mode = default, @@time_zone = default, @@sql_safe_updates = default", setting.ResetQuery())
+
+	for _, query := range []string{
+		"select 1",
+		"set @@global.sql_mode = ''",
+		"set sql_mode = ''",
+		"set @x = 1",
+		"set @@sql_mode = (select secret from t)",
+		"set @@sql_mode = concat('a', 'b')",
+	} {
+		_, err := NewSetting([]string{query})
+		assert.Error(t, err, query)
+	}
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connpool
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/pools"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+// workloadScheduler hands out the connections of a pool to workload
+// classes by weighted fair queuing. Each waiter gets a virtual finish
+// tag that grows by the inverse of the share of its class, and the
+// waiter with the smallest tag is served first, so that under contention
+// every class gets connections in proportion to its share. Waiters of
+// classes with a higher priority are always served first, and waiters
+// that missed the queue time SLO of their class are served before the
+// others of the same priority.
+type workloadScheduler struct {
+	mu       sync.Mutex
+	capacity int
+	inUse    int
+	// vtime is the virtual time: the tag of the last served waiter.
+	vtime float64
+
+	classes      map[string]*workloadClass
+	components   map[string]*workloadClass
+	defaultClass *workloadClass
+
+	queueTimes *servenv.TimingsWrapper
+	sloMisses  *stats.CountersWithSingleLabel
+	timeouts   *stats.CountersWithSingleLabel
+}
+
+type workloadClass struct {
+	name     string
+	weight   float64
+	priority int
+	slo      time.Duration
+
+	// finish is the tag of the last queued waiter of the class.
+	finish  float64
+	waiters []*workloadWaiter
+	inUse   int
+}
+
+type workloadWaiter struct {
+	class *workloadClass
+	tag   float64
+	start time.Time
+	ready chan struct{}
+}
+
+func newWorkloadScheduler(env tabletenv.Env, name string, capacity int, configs []tabletenv.WorkloadClassConfig) *workloadScheduler {
+	ws := &workloadScheduler{
+		capacity:   capacity,
+		classes:    make(map[string]*workloadClass),
+		components: make(map[string]*workloadClass),
+	}
+	for _, cfg := range configs {
+		class := &workloadClass{
+			name:     cfg.Name,
+			weight:   float64(cfg.Share),
+			priority: cfg.Priority,
+			slo:      cfg.QueueTimeSLOSeconds.Get(),
+		}
+		if class.weight == 0 {
+			class.weight = 1
+		}
+		ws.classes[class.name] = class
+		for _, component := range cfg.Components {
+			ws.components[component] = class
+		}
+	}
+	ws.defaultClass = ws.classes[tabletenv.DefaultWorkloadClass]
+	if ws.defaultClass == nil {
+		ws.defaultClass = &workloadClass{name: tabletenv.DefaultWorkloadClass, weight: 1}
+		ws.classes[ws.defaultClass.name] = ws.defaultClass
+	}
+
+	env.Exporter().NewGaugesFuncWithMultiLabels(name+"WorkloadClassInUse", "Tablet server conn pool connections in use by workload class", []string{"WorkloadClass"}, ws.inUseByClass)
+	env.Exporter().NewGaugesFuncWithMultiLabels(name+"WorkloadClassWaiters", "Tablet server conn pool waiters by workload class", []string{"WorkloadClass"}, ws.waitersByClass)
+	ws.queueTimes = env.Exporter().NewTimings(name+"WorkloadClassQueueTime", "Tablet server conn pool queue time by workload class", "WorkloadClass")
+	ws.sloMisses = env.Exporter().NewCountersWithSingleLabel(name+"WorkloadClassSLOMisses", "Tablet server conn pool queue time SLO misses by workload class", "WorkloadClass")
+	ws.timeouts = env.Exporter().NewCountersWithSingleLabel(name+"WorkloadClassTimeouts", "Tablet server conn pool waiters that timed out by workload class", "WorkloadClass")
+	return ws
+}
+
+// classFor returns the workload class of a request: the class set in
+// the context, or else the class of the component of the caller id,
+// or else the default class.
+func (ws *workloadScheduler) classFor(ctx context.Context) *workloadClass {
+	if class, ok := ws.classes[tabletenv.WorkloadClassFromContext(ctx)]; ok {
+		return class
+	}
+	if ef := callerid.EffectiveCallerIDFromContext(ctx); ef != nil {
+		if class, ok := ws.components[ef.Component]; ok {
+			return class
+		}
+	}
+	return ws.defaultClass
+}
+
+// acquire waits for a connection slot for the class.
+func (ws *workloadScheduler) acquire(ctx context.Context, class *workloadClass) error {
+	start := time.Now()
+	ws.mu.Lock()
+	if ws.inUse < ws.capacity && !ws.hasWaiters() {
+		ws.inUse++
+		class.inUse++
+		ws.mu.Unlock()
+		ws.queueTimes.Record(class.name, start)
+		return nil
+	}
+	w := &workloadWaiter{
+		class: class,
+		tag:   class.finish,
+		start: start,
+		ready: make(chan struct{}),
+	}
+	if w.tag < ws.vtime {
+		w.tag = ws.vtime
+	}
+	w.tag += 1 / class.weight
+	class.finish = w.tag
+	class.waiters = append(class.waiters, w)
+	ws.mu.Unlock()
+
+	select {
+	case <-w.ready:
+	case <-ctx.Done():
+		ws.mu.Lock()
+		select {
+		case <-w.ready:
+			// The slot was handed out while we were giving up.
+			ws.mu.Unlock()
+			ws.release(class)
+		default:
+			for i, other := range class.waiters {
+				if other == w {
+					class.waiters = append(class.waiters[:i], class.waiters[i+1:]...)
+					break
+				}
+			}
+			ws.mu.Unlock()
+		}
+		ws.timeouts.Add(class.name, 1)
+		return pools.ErrTimeout
+	}
+	ws.queueTimes.Record(class.name, start)
+	if class.slo != 0 && time.Since(start) > class.slo {
+		ws.sloMisses.Add(class.name, 1)
+	}
+	return nil
+}
+
+// release gives back a slot acquired for the class.
+func (ws *workloadScheduler) release(class *workloadClass) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	class.inUse--
+	ws.inUse--
+	ws.serveLocked()
+}
+
+// setCapacity changes the number of slots. If the capacity shrinks,
+// the slots in use are not handed out anymore until they fit.
+func (ws *workloadScheduler) setCapacity(capacity int) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.capacity = capacity
+	ws.serveLocked()
+}
+
+// serveLocked hands out the free slots to the next waiters.
+func (ws *workloadScheduler) serveLocked() {
+	for ws.inUse < ws.capacity {
+		w := ws.nextLocked()
+		if w == nil {
+			return
+		}
+		w.class.waiters = w.class.waiters[1:]
+		w.class.inUse++
+		ws.inUse++
+		if w.tag > ws.vtime {
+			ws.vtime = w.tag
+		}
+		close(w.ready)
+	}
+}
+
+// nextLocked returns the waiter to serve next.
+func (ws *workloadScheduler) nextLocked() *workloadWaiter {
+	now := time.Now()
+	var next *workloadWaiter
+	nextMissed := false
+	for _, class := range ws.classes {
+		if len(class.waiters) == 0 {
+			continue
+		}
+		w := class.waiters[0]
+		missed := class.slo != 0 && now.Sub(w.start) > class.slo
+		switch {
+		case next == nil:
+		case class.priority != next.class.priority:
+			if class.priority < next.class.priority {
+				continue
+			}
+		case missed != nextMissed:
+			if !missed {
+				continue
+			}
+		case missed:
+			// Serve the waiter that missed its SLO first.
+			if w.start.Add(class.slo).After(next.start.Add(next.class.slo)) {
+				continue
+			}
+		case w.tag > next.tag || (w.tag == next.tag && w.start.After(next.start)):
+			continue
+		}
+		next, nextMissed = w, missed
+	}
+	return next
+}
+
+func (ws *workloadScheduler) hasWaiters() bool {
+	for _, class := range ws.classes {
+		if len(class.waiters) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (ws *workloadScheduler) inUseByClass() map[string]int64 {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	counts := make(map[string]int64, len(ws.classes))
+	for name, class := range ws.classes {
+		counts[name] = int64(class.inUse)
+	}
+	return counts
+}
+
+func (ws *workloadScheduler) waitersByClass() map[string]int64 {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	counts := make(map[string]int64, len(ws.classes))
+	for name, class := range ws.classes {
+		counts[name] = int64(len(class.waiters))
+	}
+	return counts
+}
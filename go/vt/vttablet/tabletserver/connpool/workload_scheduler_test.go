@@ -0,0 +1,186 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/pools"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+func newTestWorkloadScheduler(capacity int, classes ...tabletenv.WorkloadClassConfig) *workloadScheduler {
+	return newWorkloadScheduler(tabletenv.NewEnv(nil, "PoolTest"), "TestPool", capacity, classes)
+}
+
+// queueWaiters queues waiters of the classes in order, and returns
+// the channel that receives the names of their classes once they
+// get a slot.
+func queueWaiters(t *testing.T, ws *workloadScheduler, classes ...string) chan string {
+	t.Helper()
+	served := make(chan string, len(classes))
+	for i, name := range classes {
+		class := ws.classes[name]
+		go func() {
+			if err := ws.acquire(context.Background(), class); err == nil {
+				served <- class.name
+			}
+		}()
+		// Wait for the waiter to be queued, so that the waiters
+		// are queued in order.
+		for waiters := 0; waiters != i+1; {
+			time.Sleep(time.Millisecond)
+			waiters = 0
+			for _, count := range ws.waitersByClass() {
+				waiters += int(count)
+			}
+		}
+	}
+	return served
+}
+
+// serveAll releases the slot of the holder, and then the slot of each
+// served waiter, and returns the order in which the classes were served.
+func serveAll(ws *workloadScheduler, holder string, served chan string, count int) []string {
+	var order []string
+	last := holder
+	for i := 0; i < count; i++ {
+		ws.release(ws.classes[last])
+		last = <-served
+		order = append(order, last)
+	}
+	ws.release(ws.classes[last])
+	return order
+}
+
+func TestWorkloadSchedulerShares(t *testing.T) {
+	ws := newTestWorkloadScheduler(1,
+		tabletenv.WorkloadClassConfig{Name: "oltp", Share: 3},
+		tabletenv.WorkloadClassConfig{Name: "batch"},
+	)
+	require.NoError(t, ws.acquire(context.Background(), ws.classes["batch"]))
+	served := queueWaiters(t, ws, "batch", "batch", "batch", "oltp", "oltp", "oltp", "oltp")
+	assert.Equal(t, map[string]int64{"oltp": 4, "batch": 3, "default": 0}, ws.waitersByClass())
+	assert.Equal(t, map[string]int64{"oltp": 0, "batch": 1, "default": 0}, ws.inUseByClass())
+
+	// oltp gets three slots for each slot of batch.
+	order := serveAll(ws, "batch", served, 7)
+	assert.Equal(t, []string{"oltp", "oltp", "batch", "oltp", "oltp", "batch", "batch"}, order)
+	assert.Equal(t, 0, ws.inUse)
+	assert.Equal(t, map[string]int64{"oltp": 0, "batch": 0, "default": 0}, ws.inUseByClass())
+}
+
+func TestWorkloadSchedulerPriority(t *testing.T) {
+	ws := newTestWorkloadScheduler(1,
+		tabletenv.WorkloadClassConfig{Name: "critical", Priority: 1},
+		tabletenv.WorkloadClassConfig{Name: "batch", Share: 10},
+	)
+	require.NoError(t, ws.acquire(context.Background(), ws.classes["batch"]))
+	served := queueWaiters(t, ws, "batch", "batch", "critical", "critical")
+	order := serveAll(ws, "batch", served, 4)
+	assert.Equal(t, []string{"critical", "critical", "batch", "batch"}, order)
+}
+
+func TestWorkloadSchedulerSLO(t *testing.T) {
+	ws := newTestWorkloadScheduler(1,
+		tabletenv.WorkloadClassConfig{Name: "interactive", QueueTimeSLOSeconds: 0.001},
+		tabletenv.WorkloadClassConfig{Name: "batch", Share: 10},
+	)
+	misses := ws.sloMisses.Counts()["interactive"]
+	require.NoError(t, ws.acquire(context.Background(), ws.classes["batch"]))
+	served := queueWaiters(t, ws, "batch", "batch", "interactive")
+	time.Sleep(2 * time.Millisecond)
+
+	// The interactive waiter missed its SLO, it's served first.
+	order := serveAll(ws, "batch", served, 3)
+	assert.Equal(t, []string{"interactive", "batch", "batch"}, order)
+	assert.Equal(t, misses+1, ws.sloMisses.Counts()["interactive"])
+}
+
+func TestWorkloadSchedulerTimeout(t *testing.T) {
+	ws := newTestWorkloadScheduler(1, tabletenv.WorkloadClassConfig{Name: "batch"})
+	batch := ws.classes["batch"]
+	timeouts := ws.timeouts.Counts()["batch"]
+	require.NoError(t, ws.acquire(context.Background(), batch))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := ws.acquire(ctx, batch)
+	assert.Equal(t, pools.ErrTimeout, err)
+	assert.Equal(t, map[string]int64{"batch": 0, "default": 0}, ws.waitersByClass())
+	assert.Equal(t, timeouts+1, ws.timeouts.Counts()["batch"])
+
+	// Growing the capacity serves the waiters.
+	served := queueWaiters(t, ws, "default")
+	ws.setCapacity(2)
+	assert.Equal(t, "default", <-served)
+	assert.Equal(t, 2, ws.inUse)
+}
+
+func TestWorkloadSchedulerClassFor(t *testing.T) {
+	ws := newTestWorkloadScheduler(1,
+		tabletenv.WorkloadClassConfig{Name: "oltp", Components: []string{"web", "api"}},
+		tabletenv.WorkloadClassConfig{Name: "batch", Components: []string{"etl"}},
+	)
+	ctx := callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("user", "etl", ""), nil)
+	assert.Equal(t, "batch", ws.classFor(ctx).name)
+	assert.Equal(t, "oltp", ws.classFor(tabletenv.NewContextWithWorkloadClass(ctx, "oltp")).name)
+	assert.Equal(t, "batch", ws.classFor(tabletenv.NewContextWithWorkloadClass(ctx, "unknown")).name)
+	assert.Equal(t, "default", ws.classFor(context.Background()).name)
+}
+
+func TestConnPoolWorkloadClasses(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	connPool := NewPool(tabletenv.NewEnv(nil, "PoolTest"), "TestPool", tabletenv.ConnPoolConfig{
+		Size:           1,
+		TimeoutSeconds: 1,
+	})
+	connPool.EnableWorkloadClasses([]tabletenv.WorkloadClassConfig{{Name: "batch"}})
+	connPool.Open(db.ConnParams(), db.ConnParams(), db.ConnParams())
+	defer connPool.Close()
+
+	ctx := tabletenv.NewContextWithWorkloadClass(context.Background(), "batch")
+	dbConn, err := connPool.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"batch": 1, "default": 0}, connPool.scheduler.inUseByClass())
+
+	// The pool is full, the waiter times out in the scheduler.
+	_, err = connPool.Get(context.Background())
+	assert.EqualError(t, err, "resource pool timed out")
+
+	dbConn.Recycle()
+	assert.Equal(t, map[string]int64{"batch": 0, "default": 0}, connPool.scheduler.inUseByClass())
+
+	// Tainted connections give back their slot too.
+	dbConn, err = connPool.Get(context.Background())
+	require.NoError(t, err)
+	dbConn.Taint()
+	assert.Equal(t, 0, connPool.scheduler.inUse)
+	dbConn.Close()
+
+	err = connPool.SetCapacity(0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, connPool.scheduler.capacity)
+}
@@ -241,6 +241,20 @@ func (hs *healthStreamer) ChangeState(tabletType topodatapb.TabletType, terTimes
 	})
 }
 
+// SetOverloaded records whether the tablet sheds load because MySQL is
+// overloaded, and broadcasts the change.
+func (hs *healthStreamer) SetOverloaded(overloaded bool) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	if hs.state.RealtimeStats.Overloaded == overloaded {
+		return
+	}
+	hs.state.RealtimeStats.Overloaded = overloaded
+	shr := proto.Clone(hs.state).(*querypb.StreamHealthResponse)
+	hs.broadCastToClients(shr)
+}
+
 func (hs *healthStreamer) broadCastToClients(shr *querypb.StreamHealthResponse) {
 	for ch := range hs.clients {
 		select {
@@ -149,6 +149,18 @@ func TestHealthStreamerBroadcast(t *testing.T) {
 		},
 	}
 	assert.Equal(t, want, shr)
+
+	// Test overload, unchanged states are not broadcast.
+	hs.SetOverloaded(true)
+	hs.SetOverloaded(true)
+	shr = <-ch
+	want.RealtimeStats.Overloaded = true
+	assert.Equal(t, want, shr)
+	hs.SetOverloaded(false)
+	shr = <-ch
+	want.RealtimeStats.Overloaded = false
+	assert.Equal(t, want, shr)
+	assert.Empty(t, ch)
 }
 
 func TestReloadSchema(t *testing.T) {
@@ -0,0 +1,333 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loadshedder provides the vttablet load shedding.
+// See the LoadShedder struct for details.
+package loadshedder
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const statusQuery = "show global status where variable_name in ('Threads_running', 'Innodb_row_lock_current_waits')"
+
+// LoadShedder delays and rejects low priority requests while MySQL is
+// overloaded, so that the remaining capacity goes to the high priority ones.
+//
+// MySQL is checked periodically, and is overloaded while any of these
+// exceeds its threshold:
+// - the average time queries waited for a query pool connection since
+//   the last check.
+// - Threads_running.
+// - Innodb_row_lock_current_waits.
+//
+// The priority of a request is the priority of its workload class. Requests
+// of classes with a priority up to ShedPriority are delayed for up to
+// MaxDelaySeconds, waiting for the overload to clear, and are rejected with
+// a RESOURCE_EXHAUSTED error after that. The error is retryable, on this
+// tablet once the overload is over, or on another tablet right away.
+type LoadShedder struct {
+	env    tabletenv.Env
+	config tabletenv.LoadSheddingConfig
+
+	// Immutable fields.
+	enabled          bool
+	dryRun           bool
+	classPriorities  map[string]int
+	componentClasses map[string]string
+	poolWaits        func() (int64, time.Duration)
+
+	conns *connpool.Pool
+	ticks *timer.Timer
+
+	rejections, rejectionsDryRun *stats.CountersWithSingleLabel
+	delays                       *servenv.TimingsWrapper
+
+	logDryRun *logutil.ThrottledLogger
+
+	mu       sync.Mutex
+	isOpen   bool
+	onChange func(overloaded bool)
+	// reason is empty if MySQL is not overloaded.
+	reason string
+	// cleared is closed when the overload clears.
+	cleared       chan struct{}
+	lastWaitCount int64
+	lastWaitTime  time.Duration
+}
+
+// New returns a LoadShedder. poolWaits returns the cumulative number of
+// waits for, and the cumulative time waited for the query pool connections.
+func New(env tabletenv.Env, poolWaits func() (int64, time.Duration)) *LoadShedder {
+	config := env.Config()
+	ls := &LoadShedder{
+		env:              env,
+		config:           config.LoadShedding,
+		enabled:          config.LoadShedding.Mode == tabletenv.Enable || config.LoadShedding.Mode == tabletenv.Dryrun,
+		dryRun:           config.LoadShedding.Mode == tabletenv.Dryrun,
+		classPriorities:  make(map[string]int),
+		componentClasses: make(map[string]string),
+		poolWaits:        poolWaits,
+		rejections: env.Exporter().NewCountersWithSingleLabel(
+			"LoadShedderRejections",
+			"Number of requests that were rejected because MySQL was overloaded",
+			"WorkloadClass"),
+		rejectionsDryRun: env.Exporter().NewCountersWithSingleLabel(
+			"LoadShedderRejectionsDryRun",
+			"Dry-run number of requests that would have been rejected because MySQL was overloaded",
+			"WorkloadClass"),
+		delays: env.Exporter().NewTimings(
+			"LoadShedderDelays",
+			"Time requests were delayed because MySQL was overloaded",
+			"WorkloadClass"),
+		logDryRun: logutil.NewThrottledLogger("LoadShedder DryRun", 5*time.Second),
+	}
+	for _, class := range config.WorkloadClasses {
+		ls.classPriorities[class.Name] = class.Priority
+		for _, component := range class.Components {
+			ls.componentClasses[component] = class.Name
+		}
+	}
+	env.Exporter().NewGaugeFunc("LoadShedderOverloaded", "Whether MySQL is overloaded (1) or not (0)", func() int64 {
+		if ls.Overloaded() {
+			return 1
+		}
+		return 0
+	})
+	if !ls.enabled {
+		return ls
+	}
+	ls.ticks = timer.NewTimer(config.LoadShedding.CheckIntervalSeconds.Get())
+	// We need one connection for the status checks.
+	ls.conns = connpool.NewPool(env, "", tabletenv.ConnPoolConfig{
+		Size:               1,
+		IdleTimeoutSeconds: config.OltpReadPool.IdleTimeoutSeconds,
+	})
+	return ls
+}
+
+// SetOnChange sets the function to call when MySQL becomes overloaded
+// or when the overload clears. It must be called before Open.
+func (ls *LoadShedder) SetOnChange(onChange func(overloaded bool)) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.onChange = onChange
+}
+
+// Open starts the periodic checks.
+func (ls *LoadShedder) Open(appParams dbconfigs.Connector) {
+	if !ls.enabled {
+		return
+	}
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if ls.isOpen {
+		return
+	}
+	ls.conns.Open(appParams, appParams, appParams)
+	ls.lastWaitCount, ls.lastWaitTime = ls.poolWaits()
+	ls.isOpen = true
+	ls.ticks.Start(func() {
+		if err := ls.check(tabletenv.LocalContext()); err != nil {
+			log.Errorf("load shedder: checking whether MySQL is overloaded failed: %v", err)
+		}
+	})
+}
+
+// Close stops the periodic checks, and clears the overload.
+func (ls *LoadShedder) Close() {
+	if !ls.enabled {
+		return
+	}
+	ls.mu.Lock()
+	if !ls.isOpen {
+		ls.mu.Unlock()
+		return
+	}
+	ls.isOpen = false
+	ls.mu.Unlock()
+
+	ls.ticks.Stop()
+	ls.conns.Close()
+	ls.setOverloaded("")
+}
+
+// Overloaded returns true if MySQL is overloaded.
+func (ls *LoadShedder) Overloaded() bool {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.reason != ""
+}
+
+// Wait returns nil if the request can go through. While MySQL is
+// overloaded, it delays the requests of low priority workload classes
+// until the overload clears, and rejects them if it doesn't clear in time.
+func (ls *LoadShedder) Wait(ctx context.Context) error {
+	if !ls.enabled {
+		return nil
+	}
+	ls.mu.Lock()
+	reason, cleared := ls.reason, ls.cleared
+	ls.mu.Unlock()
+	if reason == "" {
+		return nil
+	}
+	class := ls.classFor(ctx)
+	if ls.classPriorities[class] > ls.config.ShedPriority {
+		return nil
+	}
+	if ls.dryRun {
+		ls.rejectionsDryRun.Add(class, 1)
+		ls.logDryRun.Warningf("Would have rejected a request of workload class %s because MySQL is overloaded: %s", class, reason)
+		return nil
+	}
+
+	start := time.Now()
+	delay := time.NewTimer(ls.config.MaxDelaySeconds.Get())
+	defer delay.Stop()
+	select {
+	case <-cleared:
+		ls.delays.Record(class, start)
+		return nil
+	case <-delay.C:
+	case <-ctx.Done():
+	}
+	ls.delays.Record(class, start)
+	ls.rejections.Add(class, 1)
+	return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "load shedding: MySQL is overloaded (%s), rejected request of workload class %s", reason, class)
+}
+
+// classFor returns the workload class of a request: the class set in the
+// context, or else the class of the component of the caller id, or else
+// the default class.
+func (ls *LoadShedder) classFor(ctx context.Context) string {
+	if class := tabletenv.WorkloadClassFromContext(ctx); class != "" {
+		if _, ok := ls.classPriorities[class]; ok {
+			return class
+		}
+	}
+	if ef := callerid.EffectiveCallerIDFromContext(ctx); ef != nil {
+		if class, ok := ls.componentClasses[ef.Component]; ok {
+			return class
+		}
+	}
+	return tabletenv.DefaultWorkloadClass
+}
+
+// check checks whether MySQL is overloaded.
+func (ls *LoadShedder) check(ctx context.Context) error {
+	var reasons []string
+
+	waitCount, waitTime := ls.poolWaits()
+	ls.mu.Lock()
+	waits, waited := waitCount-ls.lastWaitCount, waitTime-ls.lastWaitTime
+	ls.lastWaitCount, ls.lastWaitTime = waitCount, waitTime
+	ls.mu.Unlock()
+	if threshold := ls.config.MaxPoolWaitSeconds.Get(); threshold > 0 && waits > 0 {
+		if avg := waited / time.Duration(waits); avg > threshold {
+			reasons = append(reasons, fmt.Sprintf("pool wait time %v > %v", avg, threshold))
+		}
+	}
+
+	if ls.config.MaxThreadsRunning > 0 || ls.config.MaxRowLockWaits > 0 {
+		status, err := ls.status(ctx)
+		if err != nil {
+			return err
+		}
+		if threshold := int64(ls.config.MaxThreadsRunning); threshold > 0 && status["Threads_running"] > threshold {
+			reasons = append(reasons, fmt.Sprintf("Threads_running %d > %d", status["Threads_running"], threshold))
+		}
+		if threshold := int64(ls.config.MaxRowLockWaits); threshold > 0 && status["Innodb_row_lock_current_waits"] > threshold {
+			reasons = append(reasons, fmt.Sprintf("Innodb_row_lock_current_waits %d > %d", status["Innodb_row_lock_current_waits"], threshold))
+		}
+	}
+
+	ls.setOverloaded(strings.Join(reasons, ", "))
+	return nil
+}
+
+// status returns the status variables of MySQL needed by the checks.
+func (ls *LoadShedder) status(ctx context.Context) (map[string]int64, error) {
+	conn, err := ls.conns.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Recycle()
+
+	qr, err := conn.Exec(ctx, statusQuery, 10, false)
+	if err != nil {
+		return nil, err
+	}
+	status := make(map[string]int64, len(qr.Rows))
+	for _, row := range qr.Rows {
+		if len(row) != 2 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected result for %s: %v", statusQuery, row)
+		}
+		v, err := strconv.ParseInt(row[1].ToString(), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		// Variable names are not case sensitive.
+		for _, name := range []string{"Threads_running", "Innodb_row_lock_current_waits"} {
+			if strings.EqualFold(row[0].ToString(), name) {
+				status[name] = v
+			}
+		}
+	}
+	return status, nil
+}
+
+// setOverloaded records whether MySQL is overloaded, for the given reason,
+// or not if the reason is empty.
+func (ls *LoadShedder) setOverloaded(reason string) {
+	ls.mu.Lock()
+	changed := (reason == "") != (ls.reason == "")
+	ls.reason = reason
+	switch {
+	case !changed:
+	case reason != "":
+		ls.cleared = make(chan struct{})
+		log.Warningf("load shedder: MySQL is overloaded: %s", reason)
+	default:
+		close(ls.cleared)
+		ls.cleared = nil
+		log.Infof("load shedder: MySQL is not overloaded anymore")
+	}
+	onChange := ls.onChange
+	ls.mu.Unlock()
+
+	if changed && onChange != nil {
+		onChange(reason != "")
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadshedder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+type fakePoolWaits struct {
+	count int64
+	time  time.Duration
+}
+
+func (f *fakePoolWaits) get() (int64, time.Duration) {
+	return f.count, f.time
+}
+
+func setStatus(db *fakesqldb.DB, threadsRunning, rowLockWaits int) {
+	db.AddQuery(statusQuery, sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("Variable_name|Value", "varchar|varchar"),
+		"Threads_running|"+sqltypes.NewInt64(int64(threadsRunning)).ToString(),
+		"Innodb_row_lock_current_waits|"+sqltypes.NewInt64(int64(rowLockWaits)).ToString(),
+	))
+}
+
+func newTestLoadShedder(t *testing.T, mode string) (*fakesqldb.DB, *LoadShedder, *fakePoolWaits) {
+	db := fakesqldb.New(t)
+	setStatus(db, 1, 0)
+
+	config := tabletenv.NewDefaultConfig()
+	config.LoadShedding = tabletenv.LoadSheddingConfig{
+		Mode:                 mode,
+		CheckIntervalSeconds: 100,
+		MaxPoolWaitSeconds:   0.1,
+		MaxThreadsRunning:    10,
+		MaxRowLockWaits:      5,
+		MaxDelaySeconds:      0.05,
+	}
+	config.WorkloadClasses = []tabletenv.WorkloadClassConfig{
+		{Name: "oltp", Priority: 1, Components: []string{"web"}},
+		{Name: "batch"},
+	}
+	waits := &fakePoolWaits{}
+	ls := New(tabletenv.NewEnv(config, "LoadShedderTest"), waits.get)
+	ls.Open(db.ConnParams())
+	return db, ls, waits
+}
+
+func TestLoadShedder(t *testing.T) {
+	db, ls, waits := newTestLoadShedder(t, tabletenv.Enable)
+	defer db.Close()
+	defer ls.Close()
+
+	var changes []bool
+	ls.SetOnChange(func(overloaded bool) {
+		changes = append(changes, overloaded)
+	})
+
+	ctx := context.Background()
+	require.NoError(t, ls.check(ctx))
+	assert.False(t, ls.Overloaded())
+	require.NoError(t, ls.Wait(ctx))
+
+	// Too many threads running.
+	setStatus(db, 20, 0)
+	require.NoError(t, ls.check(ctx))
+	assert.True(t, ls.Overloaded())
+	assert.Equal(t, "Threads_running 20 > 10", ls.reason)
+
+	rejections := ls.rejections.Counts()["batch"]
+	batchCtx := tabletenv.NewContextWithWorkloadClass(ctx, "batch")
+	err := ls.Wait(batchCtx)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.EqualError(t, err, "load shedding: MySQL is overloaded (Threads_running 20 > 10), rejected request of workload class batch")
+	assert.Equal(t, rejections+1, ls.rejections.Counts()["batch"])
+	// Queries without a class are in the default class, which is shed too.
+	assert.Error(t, ls.Wait(ctx))
+	// Higher priority classes are never shed.
+	webCtx := callerid.NewContext(ctx, callerid.NewEffectiveCallerID("user", "web", ""), nil)
+	require.NoError(t, ls.Wait(webCtx))
+
+	// Queries waiting when the overload clears go through.
+	setStatus(db, 1, 0)
+	ls.config.MaxDelaySeconds = 10
+	done := make(chan error)
+	go func() {
+		done <- ls.Wait(batchCtx)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, ls.check(ctx))
+	require.NoError(t, <-done)
+	assert.False(t, ls.Overloaded())
+
+	// Too many row lock waits and too long pool waits.
+	setStatus(db, 1, 6)
+	waits.count, waits.time = 10, 2*time.Second
+	require.NoError(t, ls.check(ctx))
+	assert.Equal(t, "pool wait time 200ms > 100ms, Innodb_row_lock_current_waits 6 > 5", ls.reason)
+	// Only the pool waits since the last check count.
+	setStatus(db, 1, 0)
+	waits.count, waits.time = 20, 2100*time.Millisecond
+	require.NoError(t, ls.check(ctx))
+	assert.False(t, ls.Overloaded())
+
+	assert.Equal(t, []bool{true, false, true, false}, changes)
+
+	// Failing checks keep the previous state.
+	db.AddRejectedQuery(statusQuery, assert.AnError)
+	assert.Error(t, ls.check(ctx))
+	assert.False(t, ls.Overloaded())
+}
+
+func TestLoadShedderDryRun(t *testing.T) {
+	db, ls, _ := newTestLoadShedder(t, tabletenv.Dryrun)
+	defer db.Close()
+	defer ls.Close()
+
+	ctx := context.Background()
+	setStatus(db, 20, 0)
+	require.NoError(t, ls.check(ctx))
+	assert.True(t, ls.Overloaded())
+
+	rejections := ls.rejectionsDryRun.Counts()["default"]
+	require.NoError(t, ls.Wait(ctx))
+	assert.Equal(t, rejections+1, ls.rejectionsDryRun.Counts()["default"])
+
+	// Closing clears the overload.
+	ls.Close()
+	assert.False(t, ls.Overloaded())
+}
+
+func TestLoadShedderDisabled(t *testing.T) {
+	db, ls, _ := newTestLoadShedder(t, tabletenv.Disable)
+	defer db.Close()
+	defer ls.Close()
+
+	require.NoError(t, ls.Wait(context.Background()))
+	assert.False(t, ls.Overloaded())
+}
@@ -38,6 +38,7 @@ type TabletService interface {
 	tabletenv.Env
 	PostponeMessages(ctx context.Context, target *querypb.Target, name string, ids []string) (count int64, err error)
 	PurgeMessages(ctx context.Context, target *querypb.Target, name string, timeCutoff int64) (count int64, err error)
+	DeadLetterMessages(ctx context.Context, target *querypb.Target, name string, ids []string) (count int64, err error)
 }
 
 // VStreamer defines  the functions of VStreamer
@@ -145,6 +146,17 @@ func (me *Engine) GeneratePostponeQuery(name string, ids []string) (string, map[
 	return query, bv, nil
 }
 
+// GenerateDeadLetterQueries returns the queries for giving up on messages.
+func (me *Engine) GenerateDeadLetterQueries(name string, ids []string) ([]*querypb.BoundQuery, error) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	mm := me.managers[name]
+	if mm == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "message table %s not found in schema", name)
+	}
+	return mm.GenerateDeadLetterQueries(ids), nil
+}
+
 // GeneratePurgeQuery returns the query and bind vars for purging messages.
 func (me *Engine) GeneratePurgeQuery(name string, timeCutoff int64) (string, map[string]*querypb.BindVariable, error) {
 	me.mu.Lock()
@@ -167,6 +167,13 @@ func TestEngineGenerate(t *testing.T) {
 	if _, _, err := engine.GeneratePurgeQuery("t2", 0); err == nil || err.Error() != want {
 		t.Errorf("engine.GeneratePurgeQuery(invalid): %v, want %s", err, want)
 	}
+
+	if _, err := engine.GenerateDeadLetterQueries("t1", []string{"1"}); err != nil {
+		t.Error(err)
+	}
+	if _, err := engine.GenerateDeadLetterQueries("t2", []string{"1"}); err == nil || err.Error() != want {
+		t.Errorf("engine.GenerateDeadLetterQueries(invalid): %v, want %s", err, want)
+	}
 }
 
 func newTestEngine(db *fakesqldb.DB) *Engine {
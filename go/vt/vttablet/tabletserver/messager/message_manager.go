@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"math/rand"
+	"strings"
 	"sync"
 	"time"
 
@@ -155,6 +156,22 @@ type receiverWithStatus struct {
 // If, for some reason, a client is closed, the load balancer resets
 // by starting with the first non-busy client.
 //
+// Message groups
+// If the table has a message_group_id column, only the oldest unacked
+// message of each group is eligible to be sent. The poller reads only
+// those, and the vstream doesn't add grouped messages to the cache.
+// Instead, it requests a poll when it sees a grouped message become due,
+// or a grouped message being acked or removed, which may make the next
+// message of its group eligible. Messages with a null group id are sent
+// as usual.
+//
+// Dead letters
+// If the table specifies a maximum number of attempts, a message that
+// was sent that many times without being acked is not sent again.
+// Instead, the send loop hands it over to be moved to the dead letter
+// table in a single transaction, or just acked if there's no dead
+// letter table.
+//
 // The Purge thread
 // This thread is mostly independent. It wakes up periodically
 // to delete old rows that were successfully acked.
@@ -169,10 +186,15 @@ type messageManager struct {
 	minBackoff   time.Duration
 	maxBackoff   time.Duration
 	batchSize    int
+	maxAttempts  int64
 	pollerTicks  *timer.Timer
 	purgeTicks   *timer.Timer
 	postponeSema *sync2.Semaphore
 
+	// groupIndex is the index of the message_group_id column in
+	// the message rows, or -1 if messages are not grouped.
+	groupIndex int
+
 	mu     sync.Mutex
 	isOpen bool
 	// cond waits on curReceiver == -1 || cache.IsEmpty():
@@ -183,6 +205,10 @@ type messageManager struct {
 	curReceiver     int
 	messagesPending bool
 
+	// pollRequested is set while a poll requested by requestPoll
+	// is pending, so that a burst of requests triggers one poll.
+	pollRequested sync2.AtomicBool
+
 	// streamMu keeps the cache and database consistent with each other.
 	// Specifically:
 	// It prevents items from being removed from cache while the poller
@@ -211,6 +237,7 @@ type messageManager struct {
 	ackQuery                  *sqlparser.ParsedQuery
 	postponeQuery             *sqlparser.ParsedQuery
 	purgeQuery                *sqlparser.ParsedQuery
+	deadLetterQueries         []*sqlparser.ParsedQuery
 }
 
 // newMessageManager creates a new message manager.
@@ -229,6 +256,8 @@ func newMessageManager(tsv TabletService, vs VStreamer, table *schema.Table, pos
 		minBackoff:      table.MessageInfo.MinBackoff,
 		maxBackoff:      table.MessageInfo.MaxBackoff,
 		batchSize:       table.MessageInfo.BatchSize,
+		maxAttempts:     int64(table.MessageInfo.MaxAttempts),
+		groupIndex:      -1,
 		cache:           newCache(table.MessageInfo.CacheSize),
 		pollerTicks:     timer.NewTimer(table.MessageInfo.PollInterval),
 		purgeTicks:      timer.NewTimer(table.MessageInfo.PollInterval),
@@ -248,6 +277,21 @@ func newMessageManager(tsv TabletService, vs VStreamer, table *schema.Table, pos
 	mm.readByPriorityAndTimeNext = sqlparser.BuildParsedQuery(
 		"select priority, time_next, epoch, time_acked, %s from %v where time_next < %a order by priority, time_next desc limit %a",
 		columnList, mm.name, ":time_next", ":max")
+	if table.MessageInfo.OrderedByGroup {
+		for i, field := range table.MessageInfo.Fields {
+			if strings.EqualFold(field.Name, "message_group_id") {
+				mm.groupIndex = i
+			}
+		}
+		// Only the messages that have no older unacked message in their
+		// group are read.
+		mm.readByPriorityAndTimeNext = sqlparser.BuildParsedQuery(
+			"select priority, time_next, epoch, time_acked, %s from %v "+
+				"where time_next < %a and (message_group_id is null or not exists ("+
+				"select 1 from %v as g where g.message_group_id = %v.message_group_id and g.time_acked is null and g.id < %v.id)) "+
+				"order by priority, time_next desc limit %a",
+			columnList, mm.name, ":time_next", mm.name, mm.name, mm.name, ":max")
+	}
 	mm.ackQuery = sqlparser.BuildParsedQuery(
 		"update %v set time_acked = %a, time_next = null where id in %a and time_acked is null",
 		mm.name, ":time_acked", "::ids")
@@ -256,6 +300,26 @@ func newMessageManager(tsv TabletService, vs VStreamer, table *schema.Table, pos
 
 	mm.postponeQuery = buildPostponeQuery(mm.name, mm.minBackoff, mm.maxBackoff)
 
+	if table.MessageInfo.DeadLetterTable == "" {
+		mm.deadLetterQueries = []*sqlparser.ParsedQuery{mm.ackQuery}
+	} else {
+		// The rows are locked first so that they can't be acked
+		// between being copied and being deleted.
+		deadLetterTable := sqlparser.NewTableIdent(table.MessageInfo.DeadLetterTable)
+		allColumns := "priority, time_next, epoch, time_acked, " + columnList
+		mm.deadLetterQueries = []*sqlparser.ParsedQuery{
+			sqlparser.BuildParsedQuery(
+				"select id from %v where id in %a and time_acked is null for update",
+				mm.name, "::ids"),
+			sqlparser.BuildParsedQuery(
+				"insert into %v(%s) select %s from %v where id in %a and time_acked is null",
+				deadLetterTable, allColumns, allColumns, mm.name, "::ids"),
+			sqlparser.BuildParsedQuery(
+				"delete from %v where id in %a and time_acked is null",
+				mm.name, "::ids"),
+		}
+	}
+
 	return mm
 }
 
@@ -487,6 +551,7 @@ func (mm *messageManager) runSend() {
 		mm.mu.Lock()
 
 		var rows [][]sqltypes.Value
+		var deadIDs []string
 		for {
 			if !mm.isOpen {
 				return
@@ -517,6 +582,10 @@ func (mm *messageManager) runSend() {
 				if mr == nil {
 					break
 				}
+				if mm.maxAttempts > 0 && mr.Epoch >= mm.maxAttempts {
+					deadIDs = append(deadIDs, mr.Row[0].ToString())
+					continue
+				}
 				if mr.Epoch >= 1 {
 					lateCount++
 				}
@@ -524,6 +593,12 @@ func (mm *messageManager) runSend() {
 			}
 			MessageStats.Add([]string{mm.name.String(), "Delayed"}, lateCount)
 
+			if deadIDs != nil {
+				mm.wg.Add(1)
+				go mm.deadLetter(deadIDs) // calls the offsetting mm.wg.Done()
+				deadIDs = nil
+			}
+
 			// If we have rows to send, break out of this loop.
 			if rows != nil {
 				break
@@ -593,10 +668,49 @@ func (mm *messageManager) postpone(tsv TabletService, name string, ackWaitTime t
 	defer mm.postponeSema.Release()
 	ctx, cancel := context.WithTimeout(tabletenv.LocalContext(), ackWaitTime)
 	defer cancel()
-	if _, err := tsv.PostponeMessages(ctx, nil, name, ids); err != nil {
+	count, err := tsv.PostponeMessages(ctx, nil, name, ids)
+	if err != nil {
 		// This can happen during spikes. Record the incident for monitoring.
 		MessageStats.Add([]string{mm.name.String(), "PostponeFailed"}, 1)
+		return
 	}
+	MessageStats.Add([]string{mm.name.String(), "Postponed"}, count)
+	MessageStats.Add([]string{mm.name.String(), "PostponeBatches"}, 1)
+}
+
+// deadLetter gives up on messages that were sent maxAttempts times.
+func (mm *messageManager) deadLetter(ids []string) {
+	defer func() {
+		mm.tsv.LogError()
+		mm.wg.Done()
+	}()
+
+	defer func() {
+		// Same as send: the ids must not be discarded while the poller
+		// is active.
+		mm.streamMu.Lock()
+		defer mm.streamMu.Unlock()
+		mm.cache.Discard(ids)
+	}()
+
+	// Dead letters share the postpone semaphore because they
+	// also occupy tx pool connections.
+	if !mm.postponeSema.Acquire() {
+		// Unreachable.
+		return
+	}
+	defer mm.postponeSema.Release()
+	ctx, cancel := context.WithTimeout(tabletenv.LocalContext(), mm.ackWaitTime)
+	defer cancel()
+	count, err := mm.tsv.DeadLetterMessages(ctx, nil, mm.name.String(), ids)
+	if err != nil {
+		// The messages remain in the table, and will be retried
+		// when they're due again.
+		MessageStats.Add([]string{mm.name.String(), "DeadLetterFailed"}, 1)
+		log.Errorf("Unable to move messages %v to the dead letter table: %v", ids, err)
+		return
+	}
+	MessageStats.Add([]string{mm.name.String(), "DeadLettered"}, count)
 }
 
 func (mm *messageManager) startVStream() {
@@ -714,6 +828,10 @@ func (mm *messageManager) processRowEvent(fields []*querypb.Field, rowEvent *bin
 	now := time.Now().UnixNano()
 	for _, rc := range rowEvent.RowChanges {
 		if rc.After == nil {
+			if mm.groupIndex != -1 {
+				// A deleted message may have been holding back its group.
+				mm.requestPoll()
+			}
 			continue
 		}
 		row := sqltypes.MakeRowTrusted(fields, rc.After)
@@ -721,6 +839,15 @@ func (mm *messageManager) processRowEvent(fields []*querypb.Field, rowEvent *bin
 		if err != nil {
 			return err
 		}
+		if mm.groupIndex != -1 && !mr.Row[mm.groupIndex].IsNull() {
+			// Only the poller knows whether the message is the next
+			// one of its group. An ack may also make the next message
+			// of the group eligible.
+			if mr.TimeAcked != 0 || mr.TimeNext <= now {
+				mm.requestPoll()
+			}
+			continue
+		}
 		if mr.TimeAcked != 0 || mr.TimeNext > now {
 			continue
 		}
@@ -729,7 +856,17 @@ func (mm *messageManager) processRowEvent(fields []*querypb.Field, rowEvent *bin
 	return nil
 }
 
+// requestPoll makes the poller run as soon as possible.
+func (mm *messageManager) requestPoll() {
+	if !mm.pollRequested.CompareAndSwap(false, true) {
+		return
+	}
+	// Trigger waits for the poller to start, which may need streamMu.
+	go mm.pollerTicks.Trigger()
+}
+
 func (mm *messageManager) runPoller() {
+	mm.pollRequested.Set(false)
 	// Fast-path. Skip all the work.
 	if mm.receiverCount() == 0 {
 		return
@@ -858,6 +995,37 @@ func (mm *messageManager) GeneratePostponeQuery(ids []string) (string, map[strin
 	return mm.postponeQuery.Query, bvs
 }
 
+// GenerateDeadLetterQueries returns the queries for giving up on messages.
+// They must be executed in the same transaction.
+func (mm *messageManager) GenerateDeadLetterQueries(ids []string) []*querypb.BoundQuery {
+	idbvs := &querypb.BindVariable{
+		Type:   querypb.Type_TUPLE,
+		Values: make([]*querypb.Value, 0, len(ids)),
+	}
+	for _, id := range ids {
+		idbvs.Values = append(idbvs.Values, &querypb.Value{
+			Type:  querypb.Type_VARBINARY,
+			Value: []byte(id),
+		})
+	}
+	bvs := map[string]*querypb.BindVariable{
+		"ids": idbvs,
+	}
+	if len(mm.deadLetterQueries) == 1 {
+		// Without a dead letter table, the messages are just acked.
+		bvs["time_acked"] = sqltypes.Int64BindVariable(time.Now().UnixNano())
+	}
+
+	queries := make([]*querypb.BoundQuery, 0, len(mm.deadLetterQueries))
+	for _, query := range mm.deadLetterQueries {
+		queries = append(queries, &querypb.BoundQuery{
+			Sql:           query.Query,
+			BindVariables: bvs,
+		})
+	}
+	return queries
+}
+
 // GeneratePurgeQuery returns the query and bind vars for purging messages.
 func (mm *messageManager) GeneratePurgeQuery(timeCutoff int64) (string, map[string]*querypb.BindVariable) {
 	return mm.purgeQuery.Query, map[string]*querypb.BindVariable{
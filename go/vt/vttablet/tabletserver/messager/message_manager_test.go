@@ -577,6 +577,72 @@ func TestMessageManagerStreamerAndPoller(t *testing.T) {
 	}
 }
 
+func TestMessageManagerGroups(t *testing.T) {
+	ti := newMMTable()
+	ti.MessageInfo.Fields = append(testFields, &querypb.Field{
+		Name: "message_group_id",
+		Type: sqltypes.VarBinary,
+	})
+	ti.MessageInfo.OrderedByGroup = true
+	mm := newMessageManager(newFakeTabletServer(), newFakeVStreamer(), ti, sync2.NewSemaphore(1, 0))
+	assert.Equal(t, 2, mm.groupIndex)
+	wantQuery := "select priority, time_next, epoch, time_acked, id, message, message_group_id from foo " +
+		"where time_next < :time_next and (message_group_id is null or not exists (" +
+		"select 1 from foo as g where g.message_group_id = foo.message_group_id and g.time_acked is null and g.id < foo.id)) " +
+		"order by priority, time_next desc limit :max"
+	assert.Equal(t, wantQuery, mm.readByPriorityAndTimeNext.Query)
+
+	fields := append(testDBFields, &querypb.Field{Type: sqltypes.VarBinary})
+	newRow := func(timeNext int64, timeAcked, group sqltypes.Value) *querypb.Row {
+		return sqltypes.RowToProto3([]sqltypes.Value{
+			sqltypes.NewInt64(1),
+			sqltypes.NewInt64(timeNext),
+			sqltypes.NewInt64(0),
+			timeAcked,
+			sqltypes.NewInt64(1),
+			sqltypes.NewVarBinary("1"),
+			group,
+		})
+	}
+	future := time.Now().Add(time.Hour).UnixNano()
+	testcases := []struct {
+		desc   string
+		change *binlogdatapb.RowChange
+		poll   bool
+	}{{
+		desc:   "due message of a group",
+		change: &binlogdatapb.RowChange{After: newRow(1, sqltypes.NULL, sqltypes.NewVarBinary("g1"))},
+		poll:   true,
+	}, {
+		desc:   "postponed message of a group",
+		change: &binlogdatapb.RowChange{After: newRow(future, sqltypes.NULL, sqltypes.NewVarBinary("g1"))},
+		poll:   false,
+	}, {
+		desc:   "acked message of a group",
+		change: &binlogdatapb.RowChange{After: newRow(future, sqltypes.NewInt64(1), sqltypes.NewVarBinary("g1"))},
+		poll:   true,
+	}, {
+		desc:   "deleted message",
+		change: &binlogdatapb.RowChange{Before: newRow(1, sqltypes.NULL, sqltypes.NewVarBinary("g1"))},
+		poll:   true,
+	}, {
+		desc:   "message without a group",
+		change: &binlogdatapb.RowChange{After: newRow(1, sqltypes.NULL, sqltypes.NULL)},
+		poll:   false,
+	}}
+	for _, tcase := range testcases {
+		mm.pollRequested.Set(false)
+		err := mm.processRowEvent(fields, &binlogdatapb.RowEvent{
+			TableName:  "foo",
+			RowChanges: []*binlogdatapb.RowChange{tcase.change},
+		})
+		assert.NoError(t, err, tcase.desc)
+		assert.Equal(t, tcase.poll, mm.pollRequested.Get(), tcase.desc)
+		// Grouped messages never go to the cache directly.
+		assert.True(t, mm.cache.IsEmpty(), tcase.desc)
+	}
+}
+
 func TestMessageManagerPoller(t *testing.T) {
 	ti := newMMTable()
 	ti.MessageInfo.BatchSize = 2
@@ -825,10 +891,76 @@ func TestMMGenerateWithBackoff(t *testing.T) {
 	}
 }
 
+func TestMessageManagerDeadLetter(t *testing.T) {
+	tsv := newFakeTabletServer()
+	ti := newMMTable()
+	ti.MessageInfo.MaxAttempts = 2
+	ti.MessageInfo.BatchSize = 2
+	mm := newMessageManager(tsv, newFakeVStreamer(), ti, sync2.NewSemaphore(1, 0))
+	mm.Open()
+	defer mm.Close()
+
+	r1 := newTestReceiver(1)
+	mm.Subscribe(context.Background(), r1.rcv)
+	<-r1.ch
+
+	ch := make(chan string, 20)
+	tsv.SetChannel(ch)
+	mm.mu.Lock()
+	mm.cache.Add(&MessageRow{Epoch: 1, Row: []sqltypes.Value{sqltypes.NewVarBinary("1"), sqltypes.NULL}})
+	mm.cache.Add(&MessageRow{Epoch: 2, Row: []sqltypes.Value{sqltypes.NewVarBinary("2"), sqltypes.NULL}})
+	mm.cond.Broadcast()
+	mm.mu.Unlock()
+
+	// Only the message that has attempts left is sent.
+	want := &sqltypes.Result{
+		Rows: [][]sqltypes.Value{{
+			sqltypes.NewVarBinary("1"),
+			sqltypes.NULL,
+		}},
+	}
+	if got := <-r1.ch; !reflect.DeepEqual(got, want) {
+		t.Errorf("Received: %v, want %v", got, want)
+	}
+	got := []string{<-ch, <-ch}
+	assert.ElementsMatch(t, []string{"postpone", "dead letter [2]"}, got)
+	assert.EqualValues(t, 1, tsv.deadLetterCount.Get())
+}
+
+func TestMMGenerateDeadLetter(t *testing.T) {
+	mm := newMessageManager(newFakeTabletServer(), newFakeVStreamer(), newMMTable(), sync2.NewSemaphore(1, 0))
+	wantids := sqltypes.TestBindVariable([]interface{}{"1", "2"})
+
+	// Without a dead letter table, messages are acked.
+	queries := mm.GenerateDeadLetterQueries([]string{"1", "2"})
+	assert.Len(t, queries, 1)
+	assert.Equal(t, "update foo set time_acked = :time_acked, time_next = null where id in ::ids and time_acked is null", queries[0].Sql)
+	assert.Equal(t, wantids, queries[0].BindVariables["ids"])
+	assert.Contains(t, queries[0].BindVariables, "time_acked")
+
+	ti := newMMTable()
+	ti.MessageInfo.MaxAttempts = 3
+	ti.MessageInfo.DeadLetterTable = "foo_dlq"
+	mm = newMessageManager(newFakeTabletServer(), newFakeVStreamer(), ti, sync2.NewSemaphore(1, 0))
+	queries = mm.GenerateDeadLetterQueries([]string{"1", "2"})
+	var got []string
+	for _, query := range queries {
+		got = append(got, query.Sql)
+		assert.Equal(t, map[string]*querypb.BindVariable{"ids": wantids}, query.BindVariables)
+	}
+	want := []string{
+		"select id from foo where id in ::ids and time_acked is null for update",
+		"insert into foo_dlq(priority, time_next, epoch, time_acked, id, message) select priority, time_next, epoch, time_acked, id, message from foo where id in ::ids and time_acked is null",
+		"delete from foo where id in ::ids and time_acked is null",
+	}
+	assert.Equal(t, want, got)
+}
+
 type fakeTabletServer struct {
 	tabletenv.Env
-	postponeCount sync2.AtomicInt64
-	purgeCount    sync2.AtomicInt64
+	postponeCount   sync2.AtomicInt64
+	purgeCount      sync2.AtomicInt64
+	deadLetterCount sync2.AtomicInt64
 
 	mu sync.Mutex
 	ch chan string
@@ -871,6 +1003,17 @@ func (fts *fakeTabletServer) PurgeMessages(ctx context.Context, target *querypb.
 	return 0, nil
 }
 
+func (fts *fakeTabletServer) DeadLetterMessages(ctx context.Context, target *querypb.Target, name string, ids []string) (count int64, err error) {
+	fts.deadLetterCount.Add(1)
+	fts.mu.Lock()
+	ch := fts.ch
+	fts.mu.Unlock()
+	if ch != nil {
+		ch <- fmt.Sprintf("dead letter %v", ids)
+	}
+	return int64(len(ids)), nil
+}
+
 type fakeVStreamer struct {
 	streamInvocations sync2.AtomicInt64
 	mu                sync.Mutex
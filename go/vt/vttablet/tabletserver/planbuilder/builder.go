@@ -19,6 +19,7 @@ package planbuilder
 import (
 	"strings"
 
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
@@ -71,6 +72,7 @@ func analyzeUpdate(upd *sqlparser.Update, tables map[string]*schema.Table) (plan
 		buf := sqlparser.NewTrackedBuffer(nil)
 		buf.Myprintf("%v", upd.Where)
 		plan.WhereClause = buf.ParsedQuery()
+		plan.PKValues = analyzePKValues(upd.Where, plan.Table)
 	}
 
 	// Situations when we pass-through:
@@ -100,6 +102,7 @@ func analyzeDelete(del *sqlparser.Delete, tables map[string]*schema.Table) (plan
 		buf := sqlparser.NewTrackedBuffer(nil)
 		buf.Myprintf("%v", del.Where)
 		plan.WhereClause = buf.ParsedQuery()
+		plan.PKValues = analyzePKValues(del.Where, plan.Table)
 	}
 
 	if PassthroughDMLs || plan.Table == nil || del.Limit != nil {
@@ -113,6 +116,54 @@ func analyzeDelete(del *sqlparser.Delete, tables map[string]*schema.Table) (plan
 	return plan, nil
 }
 
+// analyzePKValues returns the values of the primary key columns of the table
+// if the WHERE clause pins all of them with "=" or "in" conditions, or nil
+// otherwise. Other conditions ANDed to them only narrow down the rows, and
+// don't change the rows that can be updated.
+func analyzePKValues(where *sqlparser.Where, table *schema.Table) []sqltypes.PlanValue {
+	if table == nil || len(table.PKColumns) == 0 {
+		return nil
+	}
+	pkValues := make([]sqltypes.PlanValue, len(table.PKColumns))
+	found := make([]bool, len(table.PKColumns))
+	for _, filter := range sqlparser.SplitAndExpression(nil, where.Expr) {
+		comp, ok := filter.(*sqlparser.ComparisonExpr)
+		if !ok {
+			continue
+		}
+		col, valExpr := comp.Left, comp.Right
+		switch comp.Operator {
+		case sqlparser.EqualOp:
+			if _, ok := col.(*sqlparser.ColName); !ok {
+				col, valExpr = valExpr, col
+			}
+		case sqlparser.InOp:
+		default:
+			continue
+		}
+		colName, ok := col.(*sqlparser.ColName)
+		if !ok {
+			continue
+		}
+		for i, pkCol := range table.PKColumns {
+			if found[i] || !colName.Name.EqualString(table.Fields[pkCol].Name) {
+				continue
+			}
+			pv, err := sqlparser.NewPlanValue(valExpr)
+			if err != nil || pv.IsNull() || pv.IsList() != (comp.Operator == sqlparser.InOp) {
+				break
+			}
+			pkValues[i], found[i] = pv, true
+		}
+	}
+	for _, ok := range found {
+		if !ok {
+			return nil
+		}
+	}
+	return pkValues
+}
+
 func analyzeInsert(ins *sqlparser.Insert, tables map[string]*schema.Table) (plan *Plan, err error) {
 	plan = &Plan{
 		PlanID:    PlanInsert,
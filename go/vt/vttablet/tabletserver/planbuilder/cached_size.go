@@ -41,7 +41,7 @@ func (cached *Plan) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(176)
+		size += int64(216)
 	}
 	// field Table *vitess.io/vitess/go/vt/vttablet/tabletserver/schema.Table
 	size += cached.Table.CachedSize(true)
@@ -60,9 +60,18 @@ func (cached *Plan) CachedSize(alloc bool) int64 {
 	size += cached.NextCount.CachedSize(false)
 	// field WhereClause *vitess.io/vitess/go/vt/sqlparser.ParsedQuery
 	size += cached.WhereClause.CachedSize(true)
+	// field PKValues []vitess.io/vitess/go/sqltypes.PlanValue
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.PKValues)) * int64(88))
+		for _, elem := range cached.PKValues {
+			size += elem.CachedSize(false)
+		}
+	}
 	// field FullStmt vitess.io/vitess/go/vt/sqlparser.Statement
 	if cc, ok := cached.FullStmt.(cachedObject); ok {
 		size += cc.CachedSize(true)
 	}
+	// field WorkloadClass string
+	size += hack.RuntimeAllocSize(int64(len(cached.WorkloadClass)))
 	return size
 }
@@ -169,8 +169,18 @@ type Plan struct {
 	// to serialize e.g. UPDATEs going to the same row.
 	WhereClause *sqlparser.ParsedQuery
 
+	// PKValues is set for DMLs whose WHERE clause pins every primary key
+	// column to a value or a list of values, in the order of the primary
+	// key columns. It is used by the hot row protection to serialize
+	// transactions per row rather than per WHERE clause.
+	PKValues []sqltypes.PlanValue
+
 	// FullStmt can be used when the query does not operate on tables
 	FullStmt sqlparser.Statement
+
+	// WorkloadClass is the workload class set by the WORKLOAD_CLASS
+	// directive of the query, if any.
+	WorkloadClass string
 }
 
 // TableName returns the table name for the plan.
@@ -249,6 +259,7 @@ func Build(statement sqlparser.Statement, tables map[string]*schema.Table, isRes
 		return nil, err
 	}
 	plan.Permissions = BuildPermissions(statement)
+	plan.WorkloadClass = sqlparser.WorkloadClassDirective(statement)
 	return plan, nil
 }
 
@@ -267,9 +278,10 @@ func BuildStreaming(sql string, tables map[string]*schema.Table, isReservedConn
 	}
 
 	plan := &Plan{
-		PlanID:      PlanSelectStream,
-		FullQuery:   GenerateFullQuery(statement),
-		Permissions: BuildPermissions(statement),
+		PlanID:        PlanSelectStream,
+		FullQuery:     GenerateFullQuery(statement),
+		Permissions:   BuildPermissions(statement),
+		WorkloadClass: sqlparser.WorkloadClassDirective(statement),
 	}
 
 	switch stmt := statement.(type) {
@@ -30,6 +30,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/tableacl"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
@@ -46,6 +47,7 @@ func (p *Plan) MarshalJSON() ([]byte, error) {
 		FullQuery   *sqlparser.ParsedQuery `json:",omitempty"`
 		NextCount   string                 `json:",omitempty"`
 		WhereClause *sqlparser.ParsedQuery `json:",omitempty"`
+		PKValues    []sqltypes.PlanValue   `json:",omitempty"`
 	}{
 		PlanID:      p.PlanID,
 		TableName:   p.TableName(),
@@ -53,6 +55,7 @@ func (p *Plan) MarshalJSON() ([]byte, error) {
 		FieldQuery:  p.FieldQuery,
 		FullQuery:   p.FullQuery,
 		WhereClause: p.WhereClause,
+		PKValues:    p.PKValues,
 	}
 	if !p.NextCount.IsNull() {
 		b, _ := p.NextCount.MarshalJSON()
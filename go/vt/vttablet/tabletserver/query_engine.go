@@ -37,10 +37,12 @@ import (
 	"vitess.io/vitess/go/vt/dbconnpool"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/tableacl"
 	tacl "vitess.io/vitess/go/vt/tableacl/acl"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/loadshedder"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
@@ -61,6 +63,14 @@ type TabletPlan struct {
 	Rules      *rules.Rules
 	Authorized []*tableacl.ACLResult
 
+	// planInfo is the plan information for the query rules that
+	// match on it. It's sampled once, by an EXPLAIN that the first
+	// query that needs it runs in the background, and is nil until
+	// that EXPLAIN completes.
+	planInfoOnce sync.Once
+	planInfoMu   sync.Mutex
+	planInfo     *rules.PlanInfo
+
 	QueryCount   uint64
 	Time         uint64
 	MysqlTime    uint64
@@ -131,6 +141,9 @@ type QueryEngine struct {
 	// that we start more than one transaction per hot row (range).
 	// For implementation details, please see BeginExecute() in tabletserver.go.
 	txSerializer *txserializer.TxSerializer
+	// loadShedder delays and rejects low priority queries while MySQL
+	// is overloaded.
+	loadShedder *loadshedder.LoadShedder
 
 	// Vars
 	maxResultSize    sync2.AtomicInt64
@@ -151,6 +164,8 @@ type QueryEngine struct {
 
 	// stats
 	queryCounts, queryTimes, queryRowCounts, queryErrorCounts *stats.CountersWithMultiLabels
+	queryRuleThrottled                                        *stats.CountersWithSingleLabel
+	queryRuleBufferTimings                                    *servenv.TimingsWrapper
 
 	// Loggers
 	accessCheckerLogger *logutil.ThrottledLogger
@@ -177,6 +192,8 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 
 	qe.conns = connpool.NewPool(env, "ConnPool", config.OltpReadPool)
 	qe.streamConns = connpool.NewPool(env, "StreamConnPool", config.OlapReadPool)
+	qe.conns.EnableWorkloadClasses(config.WorkloadClasses)
+	qe.streamConns.EnableWorkloadClasses(config.WorkloadClasses)
 	qe.consolidatorMode.Set(config.Consolidator)
 	qe.enableQueryPlanFieldCaching = config.CacheResultFields
 	qe.consolidator = sync2.NewConsolidator()
@@ -184,6 +201,9 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 		qe.streamConsolidator = NewStreamConsolidator(config.ConsolidatorStreamTotalSize, config.ConsolidatorStreamQuerySize, returnStreamResult)
 	}
 	qe.txSerializer = txserializer.New(env)
+	qe.loadShedder = loadshedder.New(env, func() (int64, time.Duration) {
+		return qe.conns.WaitCount(), qe.conns.WaitTime()
+	})
 
 	qe.strictTableACL = config.StrictTableACL
 	qe.enableTableACLDryRun = config.EnableTableACLDryRun
@@ -226,6 +246,8 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 	qe.queryTimes = env.Exporter().NewCountersWithMultiLabels("QueryTimesNs", "query times in ns", []string{"Table", "Plan"})
 	qe.queryRowCounts = env.Exporter().NewCountersWithMultiLabels("QueryRowCounts", "query row counts", []string{"Table", "Plan"})
 	qe.queryErrorCounts = env.Exporter().NewCountersWithMultiLabels("QueryErrorCounts", "query error counts", []string{"Table", "Plan"})
+	qe.queryRuleThrottled = env.Exporter().NewCountersWithSingleLabel("QueryRuleThrottled", "Number of queries rejected by query rule throttling", "Rule")
+	qe.queryRuleBufferTimings = env.Exporter().NewTimings("QueryRuleBufferTimings", "Time queries were held by query rule buffering", "Rule")
 
 	env.Exporter().HandleFunc("/debug/hotrows", qe.txSerializer.ServeHTTP)
 	env.Exporter().HandleFunc("/debug/tablet_plans", qe.handleHTTPQueryPlans)
@@ -263,6 +285,7 @@ func (qe *QueryEngine) Open() error {
 
 	qe.streamConns.Open(qe.env.Config().DB.AppWithDB(), qe.env.Config().DB.DbaWithDB(), qe.env.Config().DB.AppDebugWithDB())
 	qe.se.RegisterNotifier("qe", qe.schemaChanged)
+	qe.loadShedder.Open(qe.env.Config().DB.AppWithDB())
 	qe.isOpen = true
 	return nil
 }
@@ -275,6 +298,7 @@ func (qe *QueryEngine) Close() {
 		return
 	}
 	// Close in reverse order of Open.
+	qe.loadShedder.Close()
 	qe.se.UnregisterNotifier("qe")
 	qe.plans.Clear()
 	qe.tables = make(map[string]*schema.Table)
@@ -455,6 +479,7 @@ func (qe *QueryEngine) handleHTTPQueryPlans(response http.ResponseWriter, reques
 	qe.plans.ForEach(func(value interface{}) bool {
 		plan := value.(*TabletPlan)
 		response.Write([]byte(fmt.Sprintf("%#v\n", sqlparser.TruncateForUI(plan.Original))))
+		response.Write([]byte(fmt.Sprintf("Fingerprint: %s\n", rules.QueryFingerprint(plan.Original))))
 		if b, err := json.MarshalIndent(plan.Plan, "", "  "); err != nil {
 			response.Write([]byte(err.Error()))
 		} else {
@@ -19,6 +19,7 @@ package tabletserver
 import (
 	"fmt"
 	"io"
+	"math"
 	"strings"
 	"sync"
 	"time"
@@ -59,10 +60,17 @@ type QueryExecutor struct {
 	logStats       *tabletenv.LogStats
 	tsv            *TabletServer
 	tabletType     topodatapb.TabletType
+	// rule is the query rule that matched the query, if any.
+	rule *rules.Rule
 }
 
 const streamRowsSize = 256
 
+// explainTimeout is the timeout of the EXPLAIN of queries for the
+// query rules that match on their plans. EXPLAIN doesn't run the
+// query, so it's much faster than the queries it's bounded by.
+const explainTimeout = 500 * time.Millisecond
+
 var streamResultPool = sync.Pool{New: func() interface{} {
 	return &sqltypes.Result{
 		Rows: make([][]sqltypes.Value, 0, streamRowsSize),
@@ -92,6 +100,10 @@ var sequenceFields = []*querypb.Field{
 }
 
 func (qre *QueryExecutor) shouldConsolidate() bool {
+	// The results of a query depend on the settings of its session.
+	if len(qre.options.GetSettings()) != 0 {
+		return false
+	}
 	cm := qre.tsv.qe.consolidatorMode.Get()
 	return cm == tabletenv.Enable || ((cm == tabletenv.NotOnMaster || cm == tabletenv.NotOnPrimary) && qre.tabletType != topodatapb.TabletType_PRIMARY)
 }
@@ -126,6 +138,14 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 	if err := qre.checkPermissions(); err != nil {
 		return nil, err
 	}
+	done, err := qre.applyRule()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	if err := qre.checkLoadShedding(); err != nil {
+		return nil, err
+	}
 
 	switch qre.plan.PlanID {
 	case p.PlanNextval:
@@ -278,8 +298,16 @@ func (qre *QueryExecutor) Stream(callback StreamCallback) error {
 	if err := qre.checkPermissions(); err != nil {
 		return err
 	}
+	done, err := qre.applyRule()
+	if err != nil {
+		return err
+	}
+	defer done()
+	if err := qre.checkLoadShedding(); err != nil {
+		return err
+	}
 
-	sql, sqlWithoutComments, err := qre.generateFinalSQL(qre.plan.FullQuery, qre.bindVars)
+	sql, sqlWithoutComments, err := qre.generateFinalSQL(qre.fullQuery(), qre.bindVars)
 	if err != nil {
 		return err
 	}
@@ -387,12 +415,13 @@ func (qre *QueryExecutor) checkPermissions() error {
 		remoteAddr = ci.RemoteAddr()
 		username = ci.Username()
 	}
-	action, desc := qre.plan.Rules.GetAction(remoteAddr, username, qre.bindVars, qre.marginComments)
-	switch action {
+	component := callerid.GetComponent(callerid.EffectiveCallerIDFromContext(qre.ctx))
+	qre.rule = qre.plan.Rules.GetRule(remoteAddr, username, component, qre.bindVars, qre.marginComments, qre.planInfo)
+	switch qre.rule.Action() {
 	case rules.QRFail:
-		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "disallowed due to rule: %s", desc)
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "disallowed due to rule: %s", qre.rule.Description)
 	case rules.QRFailRetry:
-		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "disallowed due to rule: %s", desc)
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "disallowed due to rule: %s", qre.rule.Description)
 	}
 
 	// Skip ACL check for queries against the dummy dual table
@@ -430,6 +459,135 @@ func (qre *QueryExecutor) checkPermissions() error {
 	return nil
 }
 
+// applyRule throttles or buffers the query if the query rule that matched
+// it says so. The returned function must be called once the query is done.
+// The other actions apply when the final query is generated.
+func (qre *QueryExecutor) applyRule() (done func(), err error) {
+	switch qre.rule.Action() {
+	case rules.QRThrottle:
+		done, ok := qre.rule.Throttle()
+		if !ok {
+			qre.tsv.qe.queryRuleThrottled.Add(qre.rule.Name, 1)
+			return nil, vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "throttled due to rule: %s", qre.rule.Description)
+		}
+		return done, nil
+	case rules.QRBuffer:
+		defer qre.tsv.qe.queryRuleBufferTimings.Record(qre.rule.Name, time.Now())
+		if !qre.rule.Buffer(qre.ctx) {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "buffering window expired for rule: %s", qre.rule.Description)
+		}
+	}
+	return func() {}, nil
+}
+
+// planInfo returns the plan information of the query for the query rules,
+// from EXPLAIN, or nil if it isn't known. The plan information is sampled
+// once per plan: the first execution of the query that needs it starts an
+// EXPLAIN with its bind variables in the background, and doesn't wait for
+// it. Until that EXPLAIN completes, or if the query can't be explained, the
+// rules that match on the plan information don't match the query.
+func (qre *QueryExecutor) planInfo() *rules.PlanInfo {
+	plan := qre.plan
+	plan.planInfoOnce.Do(func() {
+		query, err := qre.explainQuery()
+		if err != nil {
+			log.Warningf("Could not explain query for the query rules: %v: %q", err, sqlparser.TruncateForLog(plan.Original))
+			return
+		}
+		go func() {
+			info, err := qre.tsv.qe.explain(query)
+			if err != nil {
+				log.Warningf("Could not explain query for the query rules: %v: %q", err, sqlparser.TruncateForLog(plan.Original))
+				return
+			}
+			plan.planInfoMu.Lock()
+			defer plan.planInfoMu.Unlock()
+			plan.planInfo = info
+		}()
+	})
+	plan.planInfoMu.Lock()
+	defer plan.planInfoMu.Unlock()
+	return plan.planInfo
+}
+
+// explainQuery returns the EXPLAIN of the query with the current bind
+// variables.
+func (qre *QueryExecutor) explainQuery() (string, error) {
+	switch qre.plan.PlanID {
+	case p.PlanSelect, p.PlanSelectStream, p.PlanUpdate, p.PlanUpdateLimit, p.PlanDelete, p.PlanDeleteLimit:
+	default:
+		return "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%s queries can't be explained", qre.plan.PlanID.String())
+	}
+	bindVars := make(map[string]*querypb.BindVariable, len(qre.bindVars)+1)
+	for k, v := range qre.bindVars {
+		bindVars[k] = v
+	}
+	if _, ok := bindVars["#maxLimit"]; !ok {
+		bindVars["#maxLimit"] = sqltypes.Int64BindVariable(qre.tsv.qe.maxResultSize.Get() + 1)
+	}
+	query, err := qre.plan.FullQuery.GenerateQuery(bindVars, nil)
+	if err != nil {
+		return "", err
+	}
+	return "explain " + query, nil
+}
+
+// explain runs the EXPLAIN of a query, and returns its plan information.
+func (qe *QueryEngine) explain(query string) (*rules.PlanInfo, error) {
+	// The result is cached, so it must not depend on the context of the
+	// query that needed it.
+	ctx, cancel := context.WithTimeout(tabletenv.LocalContext(), explainTimeout)
+	defer cancel()
+	conn, err := qe.conns.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Recycle()
+	qr, err := conn.Exec(ctx, query, 1000, true)
+	if err != nil {
+		return nil, err
+	}
+	return planInfoFromExplain(qr)
+}
+
+// planInfoFromExplain returns the plan information from the result of
+// EXPLAIN. The rows examined by joins multiply.
+func planInfoFromExplain(qr *sqltypes.Result) (*rules.PlanInfo, error) {
+	info := &rules.PlanInfo{}
+	examined := false
+	for _, row := range sqltypes.ToNamedResult(qr).Rows {
+		if strings.EqualFold(row.AsString("type", ""), "ALL") {
+			info.FullScan = true
+		}
+		if row["rows"].IsNull() {
+			continue
+		}
+		rows, err := row.ToInt64("rows")
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case !examined:
+			info.RowsExamined = rows
+			examined = true
+		case rows != 0 && info.RowsExamined > math.MaxInt64/rows:
+			info.RowsExamined = math.MaxInt64
+		default:
+			info.RowsExamined *= rows
+		}
+	}
+	return info, nil
+}
+
+// fullQuery returns the query to execute: the one of the plan, unless
+// the query rule that matched the query rewrites it.
+func (qre *QueryExecutor) fullQuery() *sqlparser.ParsedQuery {
+	if rewrite := qre.rule.Rewrite(); rewrite != nil {
+		return rewrite
+	}
+	return qre.plan.FullQuery
+}
+
 func (qre *QueryExecutor) checkAccess(authorized *tableacl.ACLResult, tableName string, callerID *querypb.VTGateCallerID) error {
 	statsKey := []string{tableName, authorized.GroupName, qre.plan.PlanID.String(), callerID.Username}
 	if !authorized.IsMember(callerID) {
@@ -598,8 +756,9 @@ func (qre *QueryExecutor) execNextval() (*sqltypes.Result, error) {
 // execSelect sends a query to mysql only if another identical query is not running. Otherwise, it waits and
 // reuses the result. If the plan is missing field info, it sends the query to mysql requesting full info.
 func (qre *QueryExecutor) execSelect() (*sqltypes.Result, error) {
-	if qre.tsv.qe.enableQueryPlanFieldCaching && qre.plan.Fields != nil {
-		result, err := qre.qFetch(qre.logStats, qre.plan.FullQuery, qre.bindVars)
+	// The fields of the plan may not be the ones of a rewritten query.
+	if qre.tsv.qe.enableQueryPlanFieldCaching && qre.plan.Fields != nil && qre.rule.Rewrite() == nil {
+		result, err := qre.qFetch(qre.logStats, qre.fullQuery(), qre.bindVars)
 		if err != nil {
 			return nil, err
 		}
@@ -614,7 +773,7 @@ func (qre *QueryExecutor) execSelect() (*sqltypes.Result, error) {
 	}
 	defer conn.Recycle()
 
-	sql, _, err := qre.generateFinalSQL(qre.plan.FullQuery, qre.bindVars)
+	sql, _, err := qre.generateFinalSQL(qre.fullQuery(), qre.bindVars)
 	if err != nil {
 		return nil, err
 	}
@@ -663,8 +822,12 @@ func (qre *QueryExecutor) getConn() (*connpool.DBConn, error) {
 	span, ctx := trace.NewSpan(qre.ctx, "QueryExecutor.getConn")
 	defer span.Finish()
 
+	setting, err := connpool.NewSetting(qre.options.GetSettings())
+	if err != nil {
+		return nil, err
+	}
 	start := time.Now()
-	conn, err := qre.tsv.qe.conns.Get(ctx)
+	conn, err := qre.tsv.qe.conns.GetWithSetting(qre.withWorkloadClass(ctx), setting)
 	switch err {
 	case nil:
 		qre.logStats.WaitingForConnection += time.Since(start)
@@ -679,8 +842,12 @@ func (qre *QueryExecutor) getStreamConn() (*connpool.DBConn, error) {
 	span, ctx := trace.NewSpan(qre.ctx, "QueryExecutor.getStreamConn")
 	defer span.Finish()
 
+	setting, err := connpool.NewSetting(qre.options.GetSettings())
+	if err != nil {
+		return nil, err
+	}
 	start := time.Now()
-	conn, err := qre.tsv.qe.streamConns.Get(ctx)
+	conn, err := qre.tsv.qe.streamConns.GetWithSetting(qre.withWorkloadClass(ctx), setting)
 	switch err {
 	case nil:
 		qre.logStats.WaitingForConnection += time.Since(start)
@@ -691,6 +858,36 @@ func (qre *QueryExecutor) getStreamConn() (*connpool.DBConn, error) {
 	return nil, err
 }
 
+// withWorkloadClass returns a context for the workload class set by the
+// WORKLOAD_CLASS directive of the query, or else by the query rules.
+// Without one, the pools use the class of the caller id component.
+func (qre *QueryExecutor) withWorkloadClass(ctx context.Context) context.Context {
+	class := qre.plan.WorkloadClass
+	if class == "" {
+		remoteAddr := ""
+		username := ""
+		if ci, ok := callinfo.FromContext(qre.ctx); ok {
+			remoteAddr = ci.RemoteAddr()
+			username = ci.Username()
+		}
+		component := callerid.GetComponent(callerid.EffectiveCallerIDFromContext(qre.ctx))
+		class = qre.plan.Rules.GetWorkloadClass(remoteAddr, username, component, qre.bindVars, qre.marginComments, qre.planInfo)
+	}
+	if class == "" {
+		return ctx
+	}
+	return tabletenv.NewContextWithWorkloadClass(ctx, class)
+}
+
+// checkLoadShedding delays or rejects the query if MySQL is overloaded.
+// Queries in transactions or reserved connections always go through.
+func (qre *QueryExecutor) checkLoadShedding() error {
+	if qre.connID != 0 {
+		return nil
+	}
+	return qre.tsv.qe.loadShedder.Wait(qre.withWorkloadClass(qre.ctx))
+}
+
 func (qre *QueryExecutor) qFetch(logStats *tabletenv.LogStats, parsedQuery *sqlparser.ParsedQuery, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
 	sql, sqlWithoutComments, err := qre.generateFinalSQL(parsedQuery, bindVars)
 	if err != nil {
@@ -734,7 +931,7 @@ func (qre *QueryExecutor) qFetch(logStats *tabletenv.LogStats, parsedQuery *sqlp
 
 // txFetch fetches from a TxConnection.
 func (qre *QueryExecutor) txFetch(conn *StatefulConnection, record bool) (*sqltypes.Result, error) {
-	sql, _, err := qre.generateFinalSQL(qre.plan.FullQuery, qre.bindVars)
+	sql, _, err := qre.generateFinalSQL(qre.fullQuery(), qre.bindVars)
 	if err != nil {
 		return nil, err
 	}
@@ -754,6 +951,7 @@ func (qre *QueryExecutor) generateFinalSQL(parsedQuery *sqlparser.ParsedQuery, b
 	if err != nil {
 		return "", "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%s", err)
 	}
+	query = qre.rule.AddHints(query)
 
 	if qre.tsv.config.AnnotateQueries {
 		username := callerid.GetPrincipal(callerid.EffectiveCallerIDFromContext(qre.ctx))
@@ -801,7 +999,7 @@ func (qre *QueryExecutor) execCallProc() (*sqltypes.Result, error) {
 		return nil, err
 	}
 	defer conn.Recycle()
-	sql, _, err := qre.generateFinalSQL(qre.plan.FullQuery, qre.bindVars)
+	sql, _, err := qre.generateFinalSQL(qre.fullQuery(), qre.bindVars)
 	if err != nil {
 		return nil, err
 	}
@@ -826,7 +1024,7 @@ func (qre *QueryExecutor) execCallProc() (*sqltypes.Result, error) {
 
 func (qre *QueryExecutor) execProc(conn *StatefulConnection) (*sqltypes.Result, error) {
 	beforeInTx := conn.IsInTransaction()
-	sql, _, err := qre.generateFinalSQL(qre.plan.FullQuery, qre.bindVars)
+	sql, _, err := qre.generateFinalSQL(qre.fullQuery(), qre.bindVars)
 	if err != nil {
 		return nil, err
 	}
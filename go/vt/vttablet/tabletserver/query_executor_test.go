@@ -19,10 +19,12 @@ package tabletserver
 import (
 	"fmt"
 	"io"
+	"math"
 	"math/rand"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tx"
 
@@ -1221,6 +1223,215 @@ func TestQueryExecutorDenyListQRRetry(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorRuleActions(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	expected := &sqltypes.Result{
+		Fields: getTestTableFields(),
+	}
+	db.AddQuery("select /*+ MAX_EXECUTION_TIME(1000) */ * from test_table limit 1000", expected)
+	db.AddQuery("select pk from test_table where pk = 2 limit 10", expected)
+	db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{
+		Fields: getTestTableFields(),
+	})
+
+	hintRule := rules.NewQueryRule("hint", "hint", rules.QRHint)
+	hintRule.SetUserCond("hinted")
+	hintRule.SetMaxExecutionTime(time.Second)
+	rewriteRule := rules.NewQueryRule("rewrite", "rewrite", rules.QRRewrite)
+	rewriteRule.SetUserCond("rewritten")
+	rewriteRule.AddPlanCond(planbuilder.PlanSelect)
+	require.NoError(t, rewriteRule.SetRewrite("select pk from test_table where pk = :pk limit 10"))
+	throttleRule := rules.NewQueryRule("throttle", "throttle", rules.QRThrottle)
+	throttleRule.SetUserCond("throttled")
+	throttleRule.SetThrottle(1, 0)
+	bufferRule := rules.NewQueryRule("buffer", "buffer", rules.QRBuffer)
+	bufferRule.SetUserCond("buffered")
+	bufferRule.SetBuffer(10 * time.Millisecond)
+
+	rulesName := "actionRules"
+	qrs := rules.New()
+	qrs.Add(hintRule)
+	qrs.Add(rewriteRule)
+	qrs.Add(throttleRule)
+	qrs.Add(bufferRule)
+
+	tsv := newTestTabletServer(context.Background(), noFlags, db)
+	defer tsv.StopService()
+	tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	tsv.qe.queryRuleSources.RegisterSource(rulesName)
+	defer tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	err := tsv.qe.queryRuleSources.SetRules(rulesName, qrs)
+	require.NoError(t, err)
+
+	execute := func(user, query string, bindVars map[string]*querypb.BindVariable) error {
+		ctx := callinfo.NewContext(context.Background(), &fakecallinfo.FakeCallInfo{User: user})
+		qre := newTestQueryExecutor(ctx, tsv, query, 0)
+		for k, v := range bindVars {
+			qre.bindVars[k] = v
+		}
+		_, err := qre.Execute()
+		return err
+	}
+
+	query := "select * from test_table limit 1000"
+	require.NoError(t, execute("hinted", query, nil))
+	require.NoError(t, execute("rewritten", "select * from test_table where pk = :pk limit 1000", map[string]*querypb.BindVariable{"pk": sqltypes.Int64BindVariable(2)}))
+
+	db.AddQuery(query, expected)
+	require.NoError(t, execute("throttled", query, nil))
+	err = execute("throttled", query, nil)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.EqualError(t, err, "throttled due to rule: throttle")
+
+	err = execute("buffered", query, nil)
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	assert.EqualError(t, err, "buffering window expired for rule: buffer")
+}
+
+func TestQueryExecutorPlanRules(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table limit 1000"
+	db.AddQuery(query, &sqltypes.Result{Fields: getTestTableFields()})
+	db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{
+		Fields: getTestTableFields(),
+	})
+	db.AddQuery("explain "+query, sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("id|select_type|table|type|rows", "int64|varchar|varchar|varchar|uint64"),
+		"1|SIMPLE|test_table|ALL|5000",
+	))
+
+	fullScanRule := rules.NewQueryRule("no full scans for reporting", "full scans", rules.QRFail)
+	require.NoError(t, fullScanRule.SetComponentCond("reporting"))
+	fullScanRule.AddTableCond("test_table")
+	fullScanRule.SetFullScanCond()
+
+	rulesName := "planRules"
+	qrs := rules.New()
+	qrs.Add(fullScanRule)
+
+	tsv := newTestTabletServer(context.Background(), noFlags, db)
+	defer tsv.StopService()
+	tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	tsv.qe.queryRuleSources.RegisterSource(rulesName)
+	defer tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	err := tsv.qe.queryRuleSources.SetRules(rulesName, qrs)
+	require.NoError(t, err)
+
+	ctx := callinfo.NewContext(context.Background(), &fakecallinfo.FakeCallInfo{User: "u"})
+	webCtx := callerid.NewContext(ctx, callerid.NewEffectiveCallerID("p", "web", ""), nil)
+	_, err = newTestQueryExecutor(webCtx, tsv, query, 0).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, 0, db.GetQueryCalledNum("explain "+query))
+
+	// The first query that needs the plan information doesn't wait for
+	// the EXPLAIN.
+	reportingCtx := callerid.NewContext(ctx, callerid.NewEffectiveCallerID("p", "reporting", ""), nil)
+	qre := newTestQueryExecutor(reportingCtx, tsv, query, 0)
+	_, err = qre.Execute()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return qre.planInfo() != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = newTestQueryExecutor(reportingCtx, tsv, query, 0).Execute()
+	assert.EqualError(t, err, "disallowed due to rule: no full scans for reporting")
+	// The plan information is cached.
+	_, err = newTestQueryExecutor(reportingCtx, tsv, query, 0).Execute()
+	assert.Error(t, err)
+	assert.Equal(t, 1, db.GetQueryCalledNum("explain "+query))
+}
+
+func TestPlanInfoFromExplain(t *testing.T) {
+	fields := sqltypes.MakeTestFields("id|select_type|table|type|rows", "int64|varchar|varchar|varchar|uint64")
+	info, err := planInfoFromExplain(sqltypes.MakeTestResult(fields,
+		"1|SIMPLE|a|ref|10",
+		"1|SIMPLE|b|eq_ref|1",
+		"1|SIMPLE|c|range|20",
+	))
+	require.NoError(t, err)
+	assert.Equal(t, &rules.PlanInfo{RowsExamined: 200}, info)
+
+	info, err = planInfoFromExplain(sqltypes.MakeTestResult(fields,
+		"1|SIMPLE|a|ALL|0",
+		"1|SIMPLE|b|ALL|10",
+	))
+	require.NoError(t, err)
+	assert.Equal(t, &rules.PlanInfo{RowsExamined: 0, FullScan: true}, info)
+
+	info, err = planInfoFromExplain(sqltypes.MakeTestResult(fields,
+		"1|SIMPLE|a|ALL|5000000000",
+		"1|SIMPLE|b|ALL|5000000000",
+	))
+	require.NoError(t, err)
+	assert.Equal(t, &rules.PlanInfo{RowsExamined: math.MaxInt64, FullScan: true}, info)
+}
+
+func TestQueryExecutorWorkloadClass(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+
+	batchRule := rules.NewQueryRule("batch reports", "batch reports", rules.QRContinue)
+	batchRule.SetUserCond("reporter")
+	batchRule.SetWorkloadClass("batch")
+
+	rulesName := "workloadClassRules"
+	qrs := rules.New()
+	qrs.Add(batchRule)
+
+	ctx := callinfo.NewContext(context.Background(), &fakecallinfo.FakeCallInfo{User: "reporter"})
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	tsv.qe.queryRuleSources.RegisterSource(rulesName)
+	defer tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	err := tsv.qe.queryRuleSources.SetRules(rulesName, qrs)
+	require.NoError(t, err)
+
+	// The class of the query rule applies.
+	qre := newTestQueryExecutor(ctx, tsv, "select * from test_table limit 1000", 0)
+	assert.Equal(t, "batch", tabletenv.WorkloadClassFromContext(qre.withWorkloadClass(ctx)))
+
+	// The directive of the query takes precedence.
+	qre = newTestQueryExecutor(ctx, tsv, "select /*vt+ WORKLOAD_CLASS=\"oltp\" */ * from test_table limit 1000", 0)
+	assert.Equal(t, "oltp", tabletenv.WorkloadClassFromContext(qre.withWorkloadClass(ctx)))
+
+	// Without a matching rule, the context is left alone.
+	ctx = callinfo.NewContext(context.Background(), &fakecallinfo.FakeCallInfo{User: "web"})
+	qre = newTestQueryExecutor(ctx, tsv, "select * from test_table limit 1000", 0)
+	assert.Equal(t, "", tabletenv.WorkloadClassFromContext(qre.withWorkloadClass(ctx)))
+}
+
+func TestTabletServerBeginWorkloadClass(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+
+	batchRule := rules.NewQueryRule("batch reports", "batch reports", rules.QRContinue)
+	batchRule.SetUserCond("reporter")
+	batchRule.SetWorkloadClass("batch")
+
+	rulesName := "beginWorkloadClassRules"
+	qrs := rules.New()
+	qrs.Add(batchRule)
+
+	ctx := callinfo.NewContext(context.Background(), &fakecallinfo.FakeCallInfo{User: "reporter"})
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	tsv.qe.queryRuleSources.RegisterSource(rulesName)
+	defer tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	err := tsv.qe.queryRuleSources.SetRules(rulesName, qrs)
+	require.NoError(t, err)
+
+	// The first query of the transaction sets the class of the begin.
+	assert.Equal(t, "batch", tabletenv.WorkloadClassFromContext(tsv.withWorkloadClass(ctx, "select * from test_table limit 1000", nil, nil)))
+	assert.Equal(t, "oltp", tabletenv.WorkloadClassFromContext(tsv.withWorkloadClass(ctx, "select /*vt+ WORKLOAD_CLASS=\"oltp\" */ * from test_table limit 1000", nil, nil)))
+
+	// Without a query, the context is left alone.
+	assert.Equal(t, "", tabletenv.WorkloadClassFromContext(tsv.withWorkloadClass(ctx, "", nil, nil)))
+}
+
 type executorFlags int64
 
 const (
@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/ratelimiter"
+	"vitess.io/vitess/go/sync2"
+)
+
+// actionState is the runtime state of the QRThrottle and QRBuffer actions
+// of a rule. It's shared by all the copies of the rule, so that the limits
+// apply across all the query plans the rule is copied into.
+type actionState struct {
+	limiter     *ratelimiter.RateLimiter
+	concurrency *sync2.Semaphore
+
+	releaseOnce sync.Once
+	released    chan struct{}
+}
+
+func newThrottleState(maxQPS, maxConcurrency int) *actionState {
+	state := &actionState{}
+	if maxQPS > 0 {
+		state.limiter = ratelimiter.NewRateLimiter(maxQPS, time.Second)
+	}
+	if maxConcurrency > 0 {
+		state.concurrency = sync2.NewSemaphore(maxConcurrency, 0)
+	}
+	return state
+}
+
+func newBufferState() *actionState {
+	return &actionState{released: make(chan struct{})}
+}
+
+// release releases the queries held by the QRBuffer action.
+func (state *actionState) release() {
+	if state.released == nil {
+		return
+	}
+	state.releaseOnce.Do(func() {
+		close(state.released)
+	})
+}
+
+// Throttle returns false if the query exceeds the rate or the concurrency
+// limit of the QRThrottle action. Otherwise, it returns true, and done
+// must be called once the query is done.
+func (qr *Rule) Throttle() (done func(), ok bool) {
+	state := qr.state
+	if state.limiter != nil && !state.limiter.Allow() {
+		return nil, false
+	}
+	if state.concurrency == nil {
+		return func() {}, true
+	}
+	if !state.concurrency.TryAcquire() {
+		return nil, false
+	}
+	return state.concurrency.Release, true
+}
+
+// Buffer holds the query for the QRBuffer action, until the rule is
+// removed or the buffer window elapses. It returns false if the query
+// has to fail because the window elapsed or the context is done.
+func (qr *Rule) Buffer(ctx context.Context) bool {
+	timer := time.NewTimer(qr.bufferWindow)
+	defer timer.Stop()
+	select {
+	case <-qr.state.released:
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return false
+}
+
+// takeOverState makes the rules keep the throttling and buffering state
+// of the identical rules in old, so that pushing the same rules again
+// doesn't reset the limits. The queries buffered by the old rules that
+// are gone are released.
+func (qrs *Rules) takeOverState(old *Rules) {
+	kept := make(map[*actionState]bool)
+	for _, qr := range qrs.rules {
+		if qr.state == nil {
+			continue
+		}
+		for _, oldqr := range old.rules {
+			if oldqr.state != nil && oldqr.Equal(qr) {
+				qr.state = oldqr.state
+				kept[oldqr.state] = true
+				break
+			}
+		}
+	}
+	for _, oldqr := range old.rules {
+		if oldqr.state != nil && !kept[oldqr.state] {
+			oldqr.state.release()
+		}
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestActionsJSON(t *testing.T) {
+	input := `[{"Description":"","Name":"throttle","Action":"THROTTLE","MaxQPS":100,"MaxConcurrency":10},` +
+		`{"Description":"","Name":"hint","Action":"HINT","Hints":"NO_ICP(t)","MaxExecutionTime":1000},` +
+		`{"Description":"","Name":"rewrite","Plans":["Select"],"Action":"REWRITE","Rewrite":"select id from t where id = :id limit 10"},` +
+		`{"Description":"","Name":"buffer","Action":"BUFFER","BufferWindow":"5s"}]`
+	qrs := New()
+	require.NoError(t, qrs.UnmarshalJSON([]byte(input)))
+	b, err := json.Marshal(qrs)
+	require.NoError(t, err)
+	assert.Equal(t, input, string(b))
+
+	assert.True(t, qrs.Equal(qrs.Copy()))
+	assert.Equal(t, qrs.Find("throttle").state, qrs.Copy().Find("throttle").state)
+	assert.Equal(t, qrs.Find("buffer").state, qrs.Copy().Find("buffer").state)
+}
+
+func TestThrottle(t *testing.T) {
+	qr := NewQueryRule("", "r1", QRThrottle)
+	qr.SetThrottle(0, 2)
+	done1, ok := qr.Throttle()
+	require.True(t, ok)
+	done2, ok := qr.Throttle()
+	require.True(t, ok)
+	// Copies share the limits.
+	_, ok = qr.Copy().Throttle()
+	assert.False(t, ok)
+	done1()
+	_, ok = qr.Copy().Throttle()
+	assert.True(t, ok)
+	done2()
+
+	qr.SetThrottle(2, 0)
+	for i := 0; i < 2; i++ {
+		done, ok := qr.Throttle()
+		require.True(t, ok)
+		done()
+	}
+	_, ok = qr.Throttle()
+	assert.False(t, ok)
+}
+
+func TestBuffer(t *testing.T) {
+	qr := NewQueryRule("", "r1", QRBuffer)
+	qr.SetBuffer(10 * time.Millisecond)
+	assert.False(t, qr.Buffer(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	qr.SetBuffer(time.Hour)
+	assert.False(t, qr.Buffer(ctx))
+
+	done := make(chan bool)
+	go func() {
+		done <- qr.Buffer(context.Background())
+	}()
+	qr.state.release()
+	assert.True(t, <-done)
+	// Releasing twice is fine.
+	qr.state.release()
+}
+
+func TestRewriteAndHints(t *testing.T) {
+	var nilRule *Rule
+	assert.Equal(t, QRContinue, nilRule.Action())
+	assert.Nil(t, nilRule.Rewrite())
+	assert.Equal(t, "select 1 from dual", nilRule.AddHints("select 1 from dual"))
+
+	qr := NewQueryRule("", "r1", QRRewrite)
+	qr.AddPlanCond(planbuilder.PlanSelect)
+	require.NoError(t, qr.SetRewrite("select id from t where id = :id limit 10"))
+	query, err := qr.Rewrite().GenerateQuery(map[string]*querypb.BindVariable{"id": sqltypes.Int64BindVariable(1)}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "select id from t where id = 1 limit 10", query)
+	assert.Equal(t, "select 1 from dual", qr.AddHints("select 1 from dual"))
+
+	qr = NewQueryRule("", "r1", QRHint)
+	qr.SetHints("NO_ICP(t)")
+	assert.Nil(t, qr.Rewrite())
+	assert.Equal(t, "select /*+ NO_ICP(t) */ * from t", qr.AddHints("select * from t"))
+	qr.SetMaxExecutionTime(time.Second)
+	assert.Equal(t, "update /*+ NO_ICP(t) MAX_EXECUTION_TIME(1000) */ t set a = 1", qr.AddHints("update t set a = 1"))
+	assert.Equal(t, "set @a = 1", qr.AddHints("set @a = 1"))
+}
+
+func TestSetRewrite(t *testing.T) {
+	qr := NewQueryRule("", "r1", QRRewrite)
+	assert.EqualError(t, qr.SetRewrite("select 1 from dual"), "a rule that rewrites queries must have Plans conditions")
+
+	qr.AddPlanCond(planbuilder.PlanUpdate)
+	qr.AddPlanCond(planbuilder.PlanUpdateLimit)
+	assert.EqualError(t, qr.SetRewrite("select 1 from dual"), "the queries of plan Update can't be replaced by SELECT statements")
+	assert.EqualError(t, qr.SetRewrite("set @a = 1"), "SET statements can't be rewritten")
+	assert.Nil(t, qr.Rewrite())
+	require.NoError(t, qr.SetRewrite("update t set a = :a where id = :id limit 10"))
+	assert.NotNil(t, qr.Rewrite())
+
+	qr = NewQueryRule("", "r1", QRRewrite)
+	qr.AddPlanCond(planbuilder.PlanSelect)
+	qr.AddPlanCond(planbuilder.PlanDelete)
+	assert.EqualError(t, qr.SetRewrite("delete from t where id = :id"), "the queries of plan Select can't be replaced by DELETE statements")
+}
+
+func TestGetRule(t *testing.T) {
+	qr1 := NewQueryRule("continue", "r1", QRContinue)
+	qr1.SetWorkloadClass("batch")
+	qr2 := NewQueryRule("throttle", "r2", QRThrottle)
+	qr2.SetThrottle(1, 0)
+	require.NoError(t, qr2.SetUserCond("u1"))
+	qrs := New()
+	qrs.Add(qr1)
+	qrs.Add(qr2)
+
+	assert.Equal(t, qr2, qrs.GetRule("", "u1", "", nil, sqlparser.MarginComments{}, nil))
+	assert.Nil(t, qrs.GetRule("", "u2", "", nil, sqlparser.MarginComments{}, nil))
+}
+
+func TestMapSetRulesKeepsState(t *testing.T) {
+	qri := NewMap()
+	qri.RegisterSource("src")
+
+	newRules := func(names ...string) *Rules {
+		qrs := New()
+		for _, name := range names {
+			qr := NewQueryRule("", name, QRBuffer)
+			qr.SetBuffer(time.Hour)
+			qrs.Add(qr)
+		}
+		return qrs
+	}
+	require.NoError(t, qri.SetRules("src", newRules("r1", "r2")))
+	qrs, err := qri.Get("src")
+	require.NoError(t, err)
+	r1, r2 := qrs.Find("r1"), qrs.Find("r2")
+
+	done := make(chan bool)
+	go func() {
+		done <- r2.Buffer(context.Background())
+	}()
+	// Pushing r1 again keeps its state, and releases the queries buffered by r2.
+	require.NoError(t, qri.SetRules("src", newRules("r1")))
+	assert.True(t, <-done)
+	qrs, err = qri.Get("src")
+	require.NoError(t, err)
+	assert.True(t, r1.state == qrs.Find("r1").state)
+	select {
+	case <-r1.state.released:
+		t.Errorf("r1 was released")
+	default:
+	}
+}
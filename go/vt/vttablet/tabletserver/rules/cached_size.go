@@ -39,13 +39,23 @@ func (cached *BindVarCond) CachedSize(alloc bool) int64 {
 	}
 	return size
 }
+func (cached *PlanInfo) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(16)
+	}
+	return size
+}
 func (cached *Rule) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(240)
+		size += int64(392)
 	}
 	// field Description string
 	size += hack.RuntimeAllocSize(int64(len(cached.Description)))
@@ -55,6 +65,8 @@ func (cached *Rule) CachedSize(alloc bool) int64 {
 	size += cached.requestIP.CachedSize(false)
 	// field user vitess.io/vitess/go/vt/vttablet/tabletserver/rules.namedRegexp
 	size += cached.user.CachedSize(false)
+	// field component vitess.io/vitess/go/vt/vttablet/tabletserver/rules.namedRegexp
+	size += cached.component.CachedSize(false)
 	// field query vitess.io/vitess/go/vt/vttablet/tabletserver/rules.namedRegexp
 	size += cached.query.CachedSize(false)
 	// field leadingComment vitess.io/vitess/go/vt/vttablet/tabletserver/rules.namedRegexp
@@ -72,6 +84,13 @@ func (cached *Rule) CachedSize(alloc bool) int64 {
 			size += hack.RuntimeAllocSize(int64(len(elem)))
 		}
 	}
+	// field fingerprints []string
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.fingerprints)) * int64(16))
+		for _, elem := range cached.fingerprints {
+			size += hack.RuntimeAllocSize(int64(len(elem)))
+		}
+	}
 	// field bindVarConds []vitess.io/vitess/go/vt/vttablet/tabletserver/rules.BindVarCond
 	{
 		size += hack.RuntimeAllocSize(int64(cap(cached.bindVarConds)) * int64(48))
@@ -79,6 +98,14 @@ func (cached *Rule) CachedSize(alloc bool) int64 {
 			size += elem.CachedSize(false)
 		}
 	}
+	// field workloadClass string
+	size += hack.RuntimeAllocSize(int64(len(cached.workloadClass)))
+	// field hints string
+	size += hack.RuntimeAllocSize(int64(len(cached.hints)))
+	// field rewrite vitess.io/vitess/go/vt/vttablet/tabletserver/rules.namedQuery
+	size += cached.rewrite.CachedSize(false)
+	// field state *vitess.io/vitess/go/vt/vttablet/tabletserver/rules.actionState
+	size += cached.state.CachedSize(true)
 	return size
 }
 func (cached *Rules) CachedSize(alloc bool) int64 {
@@ -98,6 +125,24 @@ func (cached *Rules) CachedSize(alloc bool) int64 {
 	}
 	return size
 }
+func (cached *actionState) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(40)
+	}
+	// field limiter *vitess.io/vitess/go/ratelimiter.RateLimiter
+	if cached.limiter != nil {
+		size += hack.RuntimeAllocSize(int64(56))
+	}
+	// field concurrency *vitess.io/vitess/go/sync2.Semaphore
+	if cached.concurrency != nil {
+		size += hack.RuntimeAllocSize(int64(16))
+	}
+	return size
+}
 func (cached *bvcre) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -112,6 +157,20 @@ func (cached *bvcre) CachedSize(alloc bool) int64 {
 	}
 	return size
 }
+func (cached *namedQuery) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(24)
+	}
+	// field name string
+	size += hack.RuntimeAllocSize(int64(len(cached.name)))
+	// field ParsedQuery *vitess.io/vitess/go/vt/sqlparser.ParsedQuery
+	size += cached.ParsedQuery.CachedSize(true)
+	return size
+}
 func (cached *namedRegexp) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -61,14 +61,19 @@ func (qri *Map) UnRegisterSource(ruleSource string) {
 
 // SetRules takes an external Rules structure and overwrite one of the
 // internal Rules as designated by ruleSource parameter.
+// The rules that are identical to the current ones keep their throttling
+// and buffering state, and the queries buffered by the removed rules
+// are released.
 func (qri *Map) SetRules(ruleSource string, newRules *Rules) error {
 	if newRules == nil {
 		newRules = New()
 	}
 	qri.mu.Lock()
 	defer qri.mu.Unlock()
-	if _, ok := qri.queryRulesMap[ruleSource]; ok {
-		qri.queryRulesMap[ruleSource] = newRules.Copy()
+	if oldRules, ok := qri.queryRulesMap[ruleSource]; ok {
+		newRules = newRules.Copy()
+		newRules.takeOverState(oldRules)
+		qri.queryRulesMap[ruleSource] = newRules
 		return nil
 	}
 	return errors.New("Rule source identifier " + ruleSource + " is not valid")
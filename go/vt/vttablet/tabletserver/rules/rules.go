@@ -18,11 +18,15 @@ package rules
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 
@@ -167,18 +171,56 @@ func (qrs *Rules) FilterByPlan(query string, planid planbuilder.PlanType, tableN
 }
 
 // GetAction runs the input against the rules engine and returns the action to be performed.
+// planInfo is only called for the rules with conditions on the plan information,
+// and may be nil if that information is not available.
 func (qrs *Rules) GetAction(
 	ip,
-	user string,
+	user,
+	component string,
 	bindVars map[string]*querypb.BindVariable,
 	marginComments sqlparser.MarginComments,
+	planInfo func() *PlanInfo,
 ) (action Action, desc string) {
+	if qr := qrs.GetRule(ip, user, component, bindVars, marginComments, planInfo); qr != nil {
+		return qr.act, qr.Description
+	}
+	return QRContinue, ""
+}
+
+// GetRule runs the input against the rules engine and returns the first
+// rule that matches with an action other than QRContinue, or nil.
+func (qrs *Rules) GetRule(
+	ip,
+	user,
+	component string,
+	bindVars map[string]*querypb.BindVariable,
+	marginComments sqlparser.MarginComments,
+	planInfo func() *PlanInfo,
+) *Rule {
 	for _, qr := range qrs.rules {
-		if act := qr.GetAction(ip, user, bindVars, marginComments); act != QRContinue {
-			return act, qr.Description
+		if act := qr.GetAction(ip, user, component, bindVars, marginComments, planInfo); act != QRContinue {
+			return qr
 		}
 	}
-	return QRContinue, ""
+	return nil
+}
+
+// GetWorkloadClass returns the workload class set by the first matching
+// rule that sets one, or an empty string.
+func (qrs *Rules) GetWorkloadClass(
+	ip,
+	user,
+	component string,
+	bindVars map[string]*querypb.BindVariable,
+	marginComments sqlparser.MarginComments,
+	planInfo func() *PlanInfo,
+) string {
+	for _, qr := range qrs.rules {
+		if qr.workloadClass != "" && qr.matches(ip, user, component, bindVars, marginComments, planInfo) {
+			return qr.workloadClass
+		}
+	}
+	return ""
 }
 
 //-----------------------------------------------
@@ -198,7 +240,7 @@ type Rule struct {
 	// All defined conditions must match for the rule to fire (AND).
 
 	// Regexp conditions. nil conditions are ignored (TRUE).
-	requestIP, user, query, leadingComment, trailingComment namedRegexp
+	requestIP, user, component, query, leadingComment, trailingComment namedRegexp
 
 	// Any matched plan will make this condition true (OR)
 	plans []planbuilder.PlanType
@@ -206,11 +248,38 @@ type Rule struct {
 	// Any matched tableNames will make this condition true (OR)
 	tableNames []string
 
+	// Any matched query fingerprint will make this condition true (OR)
+	fingerprints []string
+
 	// All BindVar conditions have to be fulfilled to make this true (AND)
 	bindVarConds []BindVarCond
 
+	// Conditions on the plan information from EXPLAIN. Zero
+	// values are ignored (TRUE).
+	minRowsExamined int64
+	fullScan        bool
+
 	// Action to be performed on trigger
 	act Action
+
+	// workloadClass is the workload class of the matching queries.
+	workloadClass string
+
+	// Parameters of the QRThrottle action.
+	maxQPS, maxConcurrency int
+
+	// Parameters of the QRHint action.
+	hints            string
+	maxExecutionTime time.Duration
+
+	// Parameter of the QRRewrite action.
+	rewrite namedQuery
+
+	// Parameter of the QRBuffer action.
+	bufferWindow time.Duration
+
+	// state is the runtime state of the QRThrottle and QRBuffer actions.
+	state *actionState
 }
 
 type namedRegexp struct {
@@ -231,6 +300,16 @@ func (nr namedRegexp) Equal(other namedRegexp) bool {
 	return nr.name == other.name && nr.String() == other.String()
 }
 
+type namedQuery struct {
+	name string
+	*sqlparser.ParsedQuery
+}
+
+// MarshalJSON marshals to JSON.
+func (nq namedQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(nq.name)
+}
+
 // NewQueryRule creates a new Rule.
 func NewQueryRule(description, name string, act Action) (qr *Rule) {
 	// We ignore act because there's only one action right now
@@ -246,26 +325,49 @@ func (qr *Rule) Equal(other *Rule) bool {
 		qr.Name == other.Name &&
 		qr.requestIP.Equal(other.requestIP) &&
 		qr.user.Equal(other.user) &&
+		qr.component.Equal(other.component) &&
 		qr.query.Equal(other.query) &&
 		qr.leadingComment.Equal(other.leadingComment) &&
 		qr.trailingComment.Equal(other.trailingComment) &&
 		reflect.DeepEqual(qr.plans, other.plans) &&
 		reflect.DeepEqual(qr.tableNames, other.tableNames) &&
+		reflect.DeepEqual(qr.fingerprints, other.fingerprints) &&
 		reflect.DeepEqual(qr.bindVarConds, other.bindVarConds) &&
-		qr.act == other.act)
+		qr.minRowsExamined == other.minRowsExamined &&
+		qr.fullScan == other.fullScan &&
+		qr.act == other.act &&
+		qr.workloadClass == other.workloadClass &&
+		qr.maxQPS == other.maxQPS &&
+		qr.maxConcurrency == other.maxConcurrency &&
+		qr.hints == other.hints &&
+		qr.maxExecutionTime == other.maxExecutionTime &&
+		qr.rewrite.name == other.rewrite.name &&
+		qr.bufferWindow == other.bufferWindow)
 }
 
 // Copy performs a deep copy of a Rule.
+// The copy shares the throttling and buffering state of the original.
 func (qr *Rule) Copy() (newqr *Rule) {
 	newqr = &Rule{
-		Description:     qr.Description,
-		Name:            qr.Name,
-		requestIP:       qr.requestIP,
-		user:            qr.user,
-		query:           qr.query,
-		leadingComment:  qr.leadingComment,
-		trailingComment: qr.trailingComment,
-		act:             qr.act,
+		Description:      qr.Description,
+		Name:             qr.Name,
+		requestIP:        qr.requestIP,
+		user:             qr.user,
+		component:        qr.component,
+		query:            qr.query,
+		leadingComment:   qr.leadingComment,
+		trailingComment:  qr.trailingComment,
+		minRowsExamined:  qr.minRowsExamined,
+		fullScan:         qr.fullScan,
+		act:              qr.act,
+		workloadClass:    qr.workloadClass,
+		maxQPS:           qr.maxQPS,
+		maxConcurrency:   qr.maxConcurrency,
+		hints:            qr.hints,
+		maxExecutionTime: qr.maxExecutionTime,
+		rewrite:          qr.rewrite,
+		bufferWindow:     qr.bufferWindow,
+		state:            qr.state,
 	}
 	if qr.plans != nil {
 		newqr.plans = make([]planbuilder.PlanType, len(qr.plans))
@@ -275,6 +377,10 @@ func (qr *Rule) Copy() (newqr *Rule) {
 		newqr.tableNames = make([]string, len(qr.tableNames))
 		copy(newqr.tableNames, qr.tableNames)
 	}
+	if qr.fingerprints != nil {
+		newqr.fingerprints = make([]string, len(qr.fingerprints))
+		copy(newqr.fingerprints, qr.fingerprints)
+	}
 	if qr.bindVarConds != nil {
 		newqr.bindVarConds = make([]BindVarCond, len(qr.bindVarConds))
 		copy(newqr.bindVarConds, qr.bindVarConds)
@@ -293,6 +399,9 @@ func (qr *Rule) MarshalJSON() ([]byte, error) {
 	if qr.user.Regexp != nil {
 		safeEncode(b, `,"User":`, qr.user)
 	}
+	if qr.component.Regexp != nil {
+		safeEncode(b, `,"Component":`, qr.component)
+	}
 	if qr.query.Regexp != nil {
 		safeEncode(b, `,"Query":`, qr.query)
 	}
@@ -308,12 +417,42 @@ func (qr *Rule) MarshalJSON() ([]byte, error) {
 	if qr.tableNames != nil {
 		safeEncode(b, `,"TableNames":`, qr.tableNames)
 	}
+	if qr.fingerprints != nil {
+		safeEncode(b, `,"Fingerprints":`, qr.fingerprints)
+	}
 	if qr.bindVarConds != nil {
 		safeEncode(b, `,"BindVarConds":`, qr.bindVarConds)
 	}
+	if qr.minRowsExamined != 0 {
+		safeEncode(b, `,"MinRowsExamined":`, qr.minRowsExamined)
+	}
+	if qr.fullScan {
+		safeEncode(b, `,"FullScan":`, qr.fullScan)
+	}
 	if qr.act != QRContinue {
 		safeEncode(b, `,"Action":`, qr.act)
 	}
+	if qr.workloadClass != "" {
+		safeEncode(b, `,"WorkloadClass":`, qr.workloadClass)
+	}
+	if qr.maxQPS != 0 {
+		safeEncode(b, `,"MaxQPS":`, qr.maxQPS)
+	}
+	if qr.maxConcurrency != 0 {
+		safeEncode(b, `,"MaxConcurrency":`, qr.maxConcurrency)
+	}
+	if qr.hints != "" {
+		safeEncode(b, `,"Hints":`, qr.hints)
+	}
+	if qr.maxExecutionTime != 0 {
+		safeEncode(b, `,"MaxExecutionTime":`, qr.maxExecutionTime.Milliseconds())
+	}
+	if qr.rewrite.ParsedQuery != nil {
+		safeEncode(b, `,"Rewrite":`, qr.rewrite)
+	}
+	if qr.bufferWindow != 0 {
+		safeEncode(b, `,"BufferWindow":`, qr.bufferWindow.String())
+	}
 	_, _ = b.WriteString("}")
 	return b.Bytes(), nil
 }
@@ -334,6 +473,14 @@ func (qr *Rule) SetUserCond(pattern string) (err error) {
 	return
 }
 
+// SetComponentCond adds a regular expression condition for the
+// component of the effective caller id.
+func (qr *Rule) SetComponentCond(pattern string) (err error) {
+	qr.component.name = pattern
+	qr.component.Regexp, err = regexp.Compile(makeExact(pattern))
+	return
+}
+
 // AddPlanCond adds to the list of plans that can be matched for
 // the rule to fire.
 // This function acts as an OR: Any plan id match is considered a match.
@@ -348,6 +495,25 @@ func (qr *Rule) AddTableCond(tableName string) {
 	qr.tableNames = append(qr.tableNames, tableName)
 }
 
+// AddFingerprintCond adds to the list of query fingerprints that can be
+// matched for the rule to fire. See QueryFingerprint.
+// This function acts as an OR: Any fingerprint match is considered a match.
+func (qr *Rule) AddFingerprintCond(fingerprint string) {
+	qr.fingerprints = append(qr.fingerprints, fingerprint)
+}
+
+// SetMinRowsExamined adds a condition on the number of rows that
+// MySQL estimates the query examines.
+func (qr *Rule) SetMinRowsExamined(rows int64) {
+	qr.minRowsExamined = rows
+}
+
+// SetFullScanCond adds a condition for the queries that do a full
+// table scan according to MySQL.
+func (qr *Rule) SetFullScanCond() {
+	qr.fullScan = true
+}
+
 // SetQueryCond adds a regular expression condition for the query.
 func (qr *Rule) SetQueryCond(pattern string) (err error) {
 	qr.query.name = pattern
@@ -446,40 +612,218 @@ func (qr *Rule) FilterByPlan(query string, planid planbuilder.PlanType, tableNam
 	if !tableMatch(qr.tableNames, tableName) {
 		return nil
 	}
+	if qr.fingerprints != nil && !tableMatch(qr.fingerprints, QueryFingerprint(query)) {
+		return nil
+	}
 	newqr = qr.Copy()
 	newqr.query = namedRegexp{}
 	// Note we explicitly don't remove the leading/trailing comments as they
 	// must be evaluated at execution time.
 	newqr.plans = nil
 	newqr.tableNames = nil
+	newqr.fingerprints = nil
 	return newqr
 }
 
 // GetAction returns the action for a single rule.
 func (qr *Rule) GetAction(
 	ip,
-	user string,
+	user,
+	component string,
 	bindVars map[string]*querypb.BindVariable,
 	marginComments sqlparser.MarginComments,
+	planInfo func() *PlanInfo,
 ) Action {
-	if !reMatch(qr.leadingComment.Regexp, marginComments.Leading) {
+	if !qr.matches(ip, user, component, bindVars, marginComments, planInfo) {
 		return QRContinue
 	}
-	if !reMatch(qr.trailingComment.Regexp, marginComments.Trailing) {
+	return qr.act
+}
+
+// SetWorkloadClass sets the workload class of the queries that match the rule.
+func (qr *Rule) SetWorkloadClass(class string) {
+	qr.workloadClass = class
+}
+
+// Action returns the action of the rule, or QRContinue for a nil rule.
+func (qr *Rule) Action() Action {
+	if qr == nil {
 		return QRContinue
 	}
+	return qr.act
+}
+
+// SetThrottle sets the limits of the QRThrottle action: the matching
+// queries are limited to maxQPS per second, and to maxConcurrency at
+// a time. A zero value means no limit.
+func (qr *Rule) SetThrottle(maxQPS, maxConcurrency int) {
+	qr.maxQPS = maxQPS
+	qr.maxConcurrency = maxConcurrency
+	qr.state = newThrottleState(maxQPS, maxConcurrency)
+}
+
+// SetHints sets the optimizer hints that the QRHint action adds to the
+// matching queries, like "NO_INDEX_MERGE(t)" or "SET_VAR(sort_buffer_size = 16M)".
+func (qr *Rule) SetHints(hints string) {
+	qr.hints = hints
+}
+
+// SetMaxExecutionTime makes the QRHint action add a MAX_EXECUTION_TIME
+// optimizer hint to the matching queries.
+func (qr *Rule) SetMaxExecutionTime(maxExecutionTime time.Duration) {
+	qr.maxExecutionTime = maxExecutionTime
+}
+
+// SetRewrite sets the query that replaces the matching queries for the
+// QRRewrite action. It can refer to the bind variables of the matching
+// queries, and must be of the same kind: a select replaces a select.
+// The rule must only match the plans of that kind, so its plan
+// conditions have to be added first.
+func (qr *Rule) SetRewrite(query string) error {
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return err
+	}
+	plans := rewritePlans(stmt)
+	if plans == nil {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%s statements can't be rewritten", sqlparser.ASTToStatementType(stmt))
+	}
+	if qr.plans == nil {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "a rule that rewrites queries must have Plans conditions")
+	}
+	for _, plan := range qr.plans {
+		if !planMatch(plans, plan) {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the queries of plan %v can't be replaced by %s statements", plan, sqlparser.ASTToStatementType(stmt))
+		}
+	}
+	qr.rewrite = namedQuery{name: query, ParsedQuery: sqlparser.NewParsedQuery(stmt)}
+	return nil
+}
+
+// rewritePlans returns the plans whose queries a statement can replace,
+// or nil if it can't replace any.
+func rewritePlans(stmt sqlparser.Statement) []planbuilder.PlanType {
+	switch stmt.(type) {
+	case sqlparser.SelectStatement:
+		return []planbuilder.PlanType{planbuilder.PlanSelect, planbuilder.PlanSelectStream}
+	case *sqlparser.Insert:
+		return []planbuilder.PlanType{planbuilder.PlanInsert}
+	case *sqlparser.Update:
+		return []planbuilder.PlanType{planbuilder.PlanUpdate, planbuilder.PlanUpdateLimit}
+	case *sqlparser.Delete:
+		return []planbuilder.PlanType{planbuilder.PlanDelete, planbuilder.PlanDeleteLimit}
+	}
+	return nil
+}
+
+// SetBuffer sets how long the QRBuffer action holds the matching queries.
+// The queries are released when the rule is removed, and fail if it's
+// still there once the window elapses.
+func (qr *Rule) SetBuffer(window time.Duration) {
+	qr.bufferWindow = window
+	qr.state = newBufferState()
+}
+
+// Rewrite returns the query that replaces the matching queries, or nil.
+func (qr *Rule) Rewrite() *sqlparser.ParsedQuery {
+	if qr.Action() != QRRewrite {
+		return nil
+	}
+	return qr.rewrite.ParsedQuery
+}
+
+// AddHints returns the query with the optimizer hints of the rule added
+// after its leading keyword. Queries that don't support hints are
+// returned as is.
+func (qr *Rule) AddHints(query string) string {
+	if qr.Action() != QRHint {
+		return query
+	}
+	hints := qr.hints
+	if qr.maxExecutionTime != 0 {
+		if hints != "" {
+			hints += " "
+		}
+		hints += fmt.Sprintf("MAX_EXECUTION_TIME(%d)", qr.maxExecutionTime.Milliseconds())
+	}
+	keyword := query
+	if i := strings.IndexAny(query, " \t\n"); i >= 0 {
+		keyword = query[:i]
+	}
+	switch strings.ToLower(keyword) {
+	case "select", "insert", "replace", "update", "delete":
+		return keyword + " /*+ " + hints + " */" + query[len(keyword):]
+	}
+	return query
+}
+
+// matches returns true if the execution time conditions of the rule match.
+func (qr *Rule) matches(
+	ip,
+	user,
+	component string,
+	bindVars map[string]*querypb.BindVariable,
+	marginComments sqlparser.MarginComments,
+	planInfo func() *PlanInfo,
+) bool {
+	if !reMatch(qr.leadingComment.Regexp, marginComments.Leading) {
+		return false
+	}
+	if !reMatch(qr.trailingComment.Regexp, marginComments.Trailing) {
+		return false
+	}
 	if !reMatch(qr.requestIP.Regexp, ip) {
-		return QRContinue
+		return false
 	}
 	if !reMatch(qr.user.Regexp, user) {
-		return QRContinue
+		return false
+	}
+	if !reMatch(qr.component.Regexp, component) {
+		return false
 	}
 	for _, bvcond := range qr.bindVarConds {
 		if !bvMatch(bvcond, bindVars) {
-			return QRContinue
+			return false
 		}
 	}
-	return qr.act
+	// The plan information is checked last, as it may need an EXPLAIN.
+	if qr.minRowsExamined == 0 && !qr.fullScan {
+		return true
+	}
+	var info *PlanInfo
+	if planInfo != nil {
+		info = planInfo()
+	}
+	if info == nil {
+		return false
+	}
+	return info.RowsExamined >= qr.minRowsExamined && (!qr.fullScan || info.FullScan)
+}
+
+// PlanInfo is the information about the execution plan of a query
+// that the rules can match on, as estimated by MySQL's EXPLAIN.
+// The tablet server samples it once per query plan, in the background,
+// so the rules that match on it don't match the first executions of
+// a query.
+type PlanInfo struct {
+	// RowsExamined is the estimated number of rows examined.
+	RowsExamined int64
+	// FullScan is true if the query does a full table scan.
+	FullScan bool
+}
+
+// QueryFingerprint returns the fingerprint of a query: a hash of the query
+// with its literals replaced by bind variables, so that the queries that
+// only differ by their values have the same fingerprint.
+func QueryFingerprint(query string) string {
+	if stmt, reservedVars, err := sqlparser.Parse2(query); err == nil {
+		bv := make(map[string]*querypb.BindVariable)
+		if err := sqlparser.Normalize(stmt, sqlparser.NewReservedVars("fp", reservedVars), bv); err == nil {
+			query = sqlparser.String(stmt)
+		}
+	}
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:8])
 }
 
 func reMatch(re *regexp.Regexp, val string) bool {
@@ -533,18 +877,25 @@ const (
 	QRContinue = Action(iota)
 	QRFail
 	QRFailRetry
+	QRThrottle
+	QRHint
+	QRRewrite
+	QRBuffer
 )
 
+var actionNames = map[Action]string{
+	QRFail:      "FAIL",
+	QRFailRetry: "FAIL_RETRY",
+	QRThrottle:  "THROTTLE",
+	QRHint:      "HINT",
+	QRRewrite:   "REWRITE",
+	QRBuffer:    "BUFFER",
+}
+
 // MarshalJSON marshals to JSON.
 func (act Action) MarshalJSON() ([]byte, error) {
-	// If we add more actions, we'll need to use a map.
-	var str string
-	switch act {
-	case QRFail:
-		str = "FAIL"
-	case QRFailRetry:
-		str = "FAIL_RETRY"
-	default:
+	str, ok := actionNames[act]
+	if !ok {
 		str = "INVALID"
 	}
 	return json.Marshal(str)
@@ -829,21 +1180,42 @@ func MapStrOperator(strop string) (op Operator, err error) {
 // BuildQueryRule builds a query rule from a ruleInfo.
 func BuildQueryRule(ruleInfo map[string]interface{}) (qr *Rule, err error) {
 	qr = NewQueryRule("", "", QRFail)
+	hasAction := false
+	// The rewrite is set once the plan conditions it's checked against
+	// are known.
+	rewrite := ""
 	for k, v := range ruleInfo {
 		var sv string
 		var lv []interface{}
+		var iv int64
+		var bv bool
 		var ok bool
 		switch k {
-		case "Name", "Description", "RequestIP", "User", "Query", "Action", "LeadingComment", "TrailingComment":
+		case "Name", "Description", "RequestIP", "User", "Component", "Query", "Action", "LeadingComment", "TrailingComment", "WorkloadClass",
+			"Hints", "Rewrite", "BufferWindow":
 			sv, ok = v.(string)
 			if !ok {
 				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want string for %s", k)
 			}
-		case "Plans", "BindVarConds", "TableNames":
+		case "MaxQPS", "MaxConcurrency", "MaxExecutionTime", "MinRowsExamined":
+			nv, ok := v.(json.Number)
+			if !ok {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want number for %s", k)
+			}
+			iv, err = nv.Int64()
+			if err != nil || iv <= 0 {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want positive integer for %s: %v", k, nv)
+			}
+		case "Plans", "BindVarConds", "TableNames", "Fingerprints":
 			lv, ok = v.([]interface{})
 			if !ok {
 				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want list for %s", k)
 			}
+		case "FullScan":
+			bv, ok = v.(bool)
+			if !ok {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want bool for %s", k)
+			}
 		default:
 			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unrecognized tag %s", k)
 		}
@@ -862,6 +1234,11 @@ func BuildQueryRule(ruleInfo map[string]interface{}) (qr *Rule, err error) {
 			if err != nil {
 				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "could not set User condition: %v", sv)
 			}
+		case "Component":
+			err = qr.SetComponentCond(sv)
+			if err != nil {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "could not set Component condition: %v", sv)
+			}
 		case "Query":
 			err = qr.SetQueryCond(sv)
 			if err != nil {
@@ -897,6 +1274,18 @@ func BuildQueryRule(ruleInfo map[string]interface{}) (qr *Rule, err error) {
 				}
 				qr.AddTableCond(tableName)
 			}
+		case "Fingerprints":
+			for _, f := range lv {
+				fingerprint, ok := f.(string)
+				if !ok {
+					return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want string for Fingerprints")
+				}
+				qr.AddFingerprintCond(fingerprint)
+			}
+		case "MinRowsExamined":
+			qr.SetMinRowsExamined(iv)
+		case "FullScan":
+			qr.fullScan = bv
 		case "BindVarConds":
 			for _, bvc := range lv {
 				name, onAbsent, onMismatch, op, value, err := buildBindVarCondition(bvc)
@@ -908,20 +1297,80 @@ func BuildQueryRule(ruleInfo map[string]interface{}) (qr *Rule, err error) {
 					return nil, err
 				}
 			}
+		case "WorkloadClass":
+			qr.workloadClass = sv
+		case "MaxQPS":
+			qr.maxQPS = int(iv)
+		case "MaxConcurrency":
+			qr.maxConcurrency = int(iv)
+		case "Hints":
+			qr.SetHints(sv)
+		case "MaxExecutionTime":
+			qr.SetMaxExecutionTime(time.Duration(iv) * time.Millisecond)
+		case "Rewrite":
+			rewrite = sv
+		case "BufferWindow":
+			window, err := time.ParseDuration(sv)
+			if err != nil || window <= 0 {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid BufferWindow: %s", sv)
+			}
+			qr.SetBuffer(window)
 		case "Action":
-			switch sv {
-			case "FAIL":
-				qr.act = QRFail
-			case "FAIL_RETRY":
-				qr.act = QRFailRetry
-			default:
+			hasAction = true
+			qr.act = QRContinue
+			for act, name := range actionNames {
+				if name == sv {
+					qr.act = act
+				}
+			}
+			if qr.act == QRContinue {
 				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid Action %s", sv)
 			}
 		}
 	}
+	if rewrite != "" {
+		if err := qr.SetRewrite(rewrite); err != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "could not set Rewrite: %v", err.Error())
+		}
+	}
+	// A rule that sets a workload class doesn't fail the
+	// queries, unless it has an explicit action.
+	if qr.workloadClass != "" && !hasAction {
+		qr.act = QRContinue
+	}
+	if err := qr.checkActionParameters(); err != nil {
+		return nil, err
+	}
+	if qr.act == QRThrottle {
+		qr.SetThrottle(qr.maxQPS, qr.maxConcurrency)
+	}
 	return qr, nil
 }
 
+// checkActionParameters returns an error if the rule misses the parameters
+// of its action, or has parameters of another action.
+func (qr *Rule) checkActionParameters() error {
+	hasParameters := []struct {
+		act Action
+		has bool
+	}{
+		{QRThrottle, qr.maxQPS != 0 || qr.maxConcurrency != 0},
+		{QRHint, qr.hints != "" || qr.maxExecutionTime != 0},
+		{QRRewrite, qr.rewrite.ParsedQuery != nil},
+		{QRBuffer, qr.bufferWindow != 0},
+	}
+	for _, params := range hasParameters {
+		act, has := params.act, params.has
+		switch {
+		case act == qr.act && !has:
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "missing parameters for Action %s", actionNames[act])
+		case act != qr.act && has:
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "parameters of Action %s are not allowed with another action", actionNames[act])
+		}
+	}
+	return nil
+}
+
 func buildBindVarCondition(bvc interface{}) (name string, onAbsent, onMismatch bool, op Operator, value interface{}, err error) {
 	bvcinfo, ok := bvc.(map[string]interface{})
 	if !ok {
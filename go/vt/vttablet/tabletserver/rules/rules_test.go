@@ -25,6 +25,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/sqlparser"
@@ -505,19 +506,19 @@ func TestAction(t *testing.T) {
 		Trailing: "other trailing comments",
 	}
 
-	action, desc := qrs.GetAction("123", "user1", bv, mc)
+	action, desc := qrs.GetAction("123", "user1", "", bv, mc, nil)
 	assert.Equalf(t, action, QRFail, "expected fail, got %v", action)
 	assert.Equalf(t, desc, "rule 1", "want rule 1, got %s", desc)
 
-	action, desc = qrs.GetAction("1234", "user", bv, mc)
+	action, desc = qrs.GetAction("1234", "user", "", bv, mc, nil)
 	assert.Equalf(t, action, QRFailRetry, "want fail_retry, got: %s", action)
 	assert.Equalf(t, desc, "rule 2", "want rule 2, got %s", desc)
 
-	action, _ = qrs.GetAction("1234", "user1", bv, mc)
+	action, _ = qrs.GetAction("1234", "user1", "", bv, mc, nil)
 	assert.Equalf(t, action, QRContinue, "want continue, got %s", action)
 
 	bv["a"] = sqltypes.Uint64BindVariable(1)
-	action, desc = qrs.GetAction("1234", "user1", bv, mc)
+	action, desc = qrs.GetAction("1234", "user1", "", bv, mc, nil)
 	assert.Equalf(t, action, QRFail, "want fail, got %s", action)
 	assert.Equalf(t, desc, "rule 3", "want rule 3, got %s", desc)
 
@@ -530,7 +531,7 @@ func TestAction(t *testing.T) {
 	newQrs := qrs.Copy()
 	newQrs.Add(qr4)
 
-	action, desc = newQrs.GetAction("1234", "user1", bv, mc)
+	action, desc = newQrs.GetAction("1234", "user1", "", bv, mc, nil)
 	assert.Equalf(t, action, QRFail, "want fail, got %s", action)
 	assert.Equalf(t, desc, "rule 4", "want rule 4, got %s", desc)
 
@@ -539,7 +540,7 @@ func TestAction(t *testing.T) {
 
 	newQrs = qrs.Copy()
 	newQrs.Add(qr5)
-	action, desc = newQrs.GetAction("1234", "user1", bv, mc)
+	action, desc = newQrs.GetAction("1234", "user1", "", bv, mc, nil)
 	assert.Equalf(t, action, QRFail, "want fail, got %s", action)
 	assert.Equalf(t, desc, "rule 5", "want rule 5, got %s", desc)
 }
@@ -622,6 +623,104 @@ var validjsons = []ValidJSONCase{
 	{`[{"BindVarConds": [{"Name": "bvname1", "OnAbsent": true, "OnMismatch": true, "Operator": "NOMATCH", "Value": "123"}]}]`, QRNoMatch, REGEXP},
 }
 
+func TestWorkloadClass(t *testing.T) {
+	qrs := New()
+	err := qrs.UnmarshalJSON([]byte(`[{
+		"Name": "r1",
+		"User": "reporter",
+		"WorkloadClass": "reporting"
+	}, {
+		"Name": "r2",
+		"LeadingComment": ".*etl.*",
+		"WorkloadClass": "batch",
+		"Action": "FAIL_RETRY"
+	}]`))
+	require.NoError(t, err)
+
+	// A rule with a workload class only fails queries if it has an action.
+	mc := sqlparser.MarginComments{Leading: "/* etl */ "}
+	action, _ := qrs.GetAction("", "reporter", "", nil, sqlparser.MarginComments{}, nil)
+	assert.Equal(t, QRContinue, action)
+	action, _ = qrs.GetAction("", "other", "", nil, mc, nil)
+	assert.Equal(t, QRFailRetry, action)
+
+	assert.Equal(t, "reporting", qrs.GetWorkloadClass("", "reporter", "", nil, mc, nil))
+	assert.Equal(t, "batch", qrs.GetWorkloadClass("", "other", "", nil, mc, nil))
+	assert.Equal(t, "", qrs.GetWorkloadClass("", "other", "", nil, sqlparser.MarginComments{}, nil))
+
+	assert.True(t, qrs.Equal(qrs.Copy()))
+	b, err := json.Marshal(qrs.Find("r1"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"Description":"","Name":"r1","User":"reporter","WorkloadClass":"reporting"}`, string(b))
+}
+
+func TestPlanConditions(t *testing.T) {
+	qrs := New()
+	err := qrs.UnmarshalJSON([]byte(`[{
+		"Name": "r1",
+		"Component": "report.*",
+		"TableNames": ["orders"],
+		"FullScan": true,
+		"Action": "FAIL"
+	}, {
+		"Name": "r2",
+		"Fingerprints": ["` + QueryFingerprint("select * from customers where id = 1") + `"],
+		"MinRowsExamined": 1000,
+		"Action": "FAIL_RETRY"
+	}]`))
+	require.NoError(t, err)
+
+	b, err := json.Marshal(qrs.Find("r1"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"Description":"","Name":"r1","Component":"report.*","TableNames":["orders"],"FullScan":true,"Action":"FAIL"}`, string(b))
+	assert.True(t, qrs.Equal(qrs.Copy()))
+
+	explained := 0
+	planInfo := func(info *PlanInfo) func() *PlanInfo {
+		return func() *PlanInfo {
+			explained++
+			return info
+		}
+	}
+	fullScan := planInfo(&PlanInfo{RowsExamined: 10, FullScan: true})
+	mc := sqlparser.MarginComments{}
+
+	orders := qrs.FilterByPlan("select * from orders", planbuilder.PlanSelect, "orders")
+	action, _ := orders.GetAction("", "", "reporting", nil, mc, fullScan)
+	assert.Equal(t, QRFail, action)
+	action, _ = orders.GetAction("", "", "reporting", nil, mc, planInfo(&PlanInfo{RowsExamined: 10}))
+	assert.Equal(t, QRContinue, action)
+	// The plan information is only needed if the other conditions match.
+	explained = 0
+	action, _ = orders.GetAction("", "", "web", nil, mc, fullScan)
+	assert.Equal(t, QRContinue, action)
+	assert.Equal(t, 0, explained)
+	// Without plan information, the conditions on it don't match.
+	action, _ = orders.GetAction("", "", "reporting", nil, mc, nil)
+	assert.Equal(t, QRContinue, action)
+	action, _ = orders.GetAction("", "", "reporting", nil, mc, planInfo(nil))
+	assert.Equal(t, QRContinue, action)
+
+	// Queries that only differ by their values have the same fingerprint.
+	customers := qrs.FilterByPlan("select * from customers where id = 2", planbuilder.PlanSelect, "customers")
+	action, _ = customers.GetAction("", "", "", nil, mc, planInfo(&PlanInfo{RowsExamined: 1000}))
+	assert.Equal(t, QRFailRetry, action)
+	action, _ = customers.GetAction("", "", "", nil, mc, planInfo(&PlanInfo{RowsExamined: 999}))
+	assert.Equal(t, QRContinue, action)
+	customers = qrs.FilterByPlan("select id from customers where id = 2", planbuilder.PlanSelect, "customers")
+	action, _ = customers.GetAction("", "", "", nil, mc, planInfo(&PlanInfo{RowsExamined: 1000}))
+	assert.Equal(t, QRContinue, action)
+}
+
+func TestQueryFingerprint(t *testing.T) {
+	fp := QueryFingerprint("select * from t where a = 1 and b in (1, 2)")
+	assert.Len(t, fp, 16)
+	assert.Equal(t, fp, QueryFingerprint("SELECT * FROM t WHERE a = 2 AND b IN (3, 4)"))
+	assert.NotEqual(t, fp, QueryFingerprint("select * from t where a = 1"))
+	// Queries that don't parse have a fingerprint too.
+	assert.Len(t, QueryFingerprint("not a query"), 16)
+}
+
 func TestValidJSON(t *testing.T) {
 	for i, tcase := range validjsons {
 		qrs := New()
@@ -692,6 +791,22 @@ var invalidjsons = []InvalidJSONCase{
 	{`[{"BindVarConds": [{"Name": "a", "OnAbsent": true, "OnMismatch": true, "Operator": "NOMATCH", "Value": "["}]}]`, "processing [: error parsing regexp: missing closing ]: `[$`"},
 	{`[{"Action": 1 }]`, "want string for Action"},
 	{`[{"Action": "foo" }]`, "invalid Action foo"},
+	{`[{"WorkloadClass": 1 }]`, "want string for WorkloadClass"},
+	{`[{"Component": "[" }]`, "could not set Component condition: ["},
+	{`[{"Fingerprints": 1 }]`, "want list for Fingerprints"},
+	{`[{"Fingerprints": [1] }]`, "want string for Fingerprints"},
+	{`[{"MinRowsExamined": -1 }]`, "want positive integer for MinRowsExamined: -1"},
+	{`[{"FullScan": 1 }]`, "want bool for FullScan"},
+	{`[{"Action": "THROTTLE", "MaxQPS": "1"}]`, "want number for MaxQPS"},
+	{`[{"Action": "THROTTLE", "MaxConcurrency": 0}]`, "want positive integer for MaxConcurrency: 0"},
+	{`[{"Action": "THROTTLE"}]`, "missing parameters for Action THROTTLE"},
+	{`[{"Action": "HINT", "MaxExecutionTime": 1.5}]`, "want positive integer for MaxExecutionTime: 1.5"},
+	{`[{"Action": "HINT", "BufferWindow": "1s"}]`, "missing parameters for Action HINT"},
+	{`[{"Action": "REWRITE", "Rewrite": "selec 1"}]`, "could not set Rewrite: syntax error at position 6 near 'selec'"},
+	{`[{"Action": "REWRITE", "Rewrite": "select 1 from dual"}]`, "could not set Rewrite: a rule that rewrites queries must have Plans conditions"},
+	{`[{"Action": "REWRITE", "Rewrite": "select 1 from dual", "Plans": ["Delete"]}]`, "could not set Rewrite: the queries of plan Delete can't be replaced by SELECT statements"},
+	{`[{"Action": "BUFFER", "BufferWindow": "1"}]`, "invalid BufferWindow: 1"},
+	{`[{"Hints": "NO_ICP(t)"}]`, "parameters of Action HINT are not allowed with another action"},
 }
 
 func TestInvalidJSON(t *testing.T) {
@@ -72,7 +72,34 @@ func loadMessageInfo(ta *Table, comment string) error {
 		"time_acked",
 	}
 
-	ta.MessageInfo = &MessageInfo{}
+	var err error
+	if ta.MessageInfo, err = ParseMessageInfo(ta.Name.String(), comment); err != nil {
+		return err
+	}
+
+	for _, col := range requiredCols {
+		num := ta.FindColumn(sqlparser.NewColIdent(col))
+		if num == -1 {
+			return fmt.Errorf("%s missing from message table: %s", col, ta.Name.String())
+		}
+	}
+
+	ta.MessageInfo.OrderedByGroup = ta.FindColumn(sqlparser.NewColIdent("message_group_id")) != -1
+
+	// Load user-defined columns. Any "unrecognized" column is user-defined.
+	for _, field := range ta.Fields {
+		if _, ok := hiddenCols[strings.ToLower(field.Name)]; ok {
+			continue
+		}
+		ta.MessageInfo.Fields = append(ta.MessageInfo.Fields, field)
+	}
+	return nil
+}
+
+// ParseMessageInfo returns the options of a message table from its
+// comment, without the information that depends on its columns.
+func ParseMessageInfo(tableName, comment string) (*MessageInfo, error) {
+	info := &MessageInfo{}
 	// Extract keyvalues.
 	keyvals := make(map[string]string)
 	inputs := strings.Split(comment, ",")
@@ -85,46 +112,37 @@ func loadMessageInfo(ta *Table, comment string) error {
 	}
 
 	var err error
-	if ta.MessageInfo.AckWaitDuration, err = getDuration(keyvals, "vt_ack_wait"); err != nil {
-		return err
+	if info.AckWaitDuration, err = getDuration(keyvals, "vt_ack_wait"); err != nil {
+		return nil, err
 	}
-	if ta.MessageInfo.PurgeAfterDuration, err = getDuration(keyvals, "vt_purge_after"); err != nil {
-		return err
+	if info.PurgeAfterDuration, err = getDuration(keyvals, "vt_purge_after"); err != nil {
+		return nil, err
 	}
-	if ta.MessageInfo.BatchSize, err = getNum(keyvals, "vt_batch_size"); err != nil {
-		return err
+	if info.BatchSize, err = getNum(keyvals, "vt_batch_size"); err != nil {
+		return nil, err
 	}
-	if ta.MessageInfo.CacheSize, err = getNum(keyvals, "vt_cache_size"); err != nil {
-		return err
+	if info.CacheSize, err = getNum(keyvals, "vt_cache_size"); err != nil {
+		return nil, err
 	}
-	if ta.MessageInfo.PollInterval, err = getDuration(keyvals, "vt_poller_interval"); err != nil {
-		return err
+	if info.PollInterval, err = getDuration(keyvals, "vt_poller_interval"); err != nil {
+		return nil, err
 	}
 
 	// errors are ignored because these fields are optional and 0 is the default value
-	ta.MessageInfo.MinBackoff, _ = getDuration(keyvals, "vt_min_backoff")
+	info.MinBackoff, _ = getDuration(keyvals, "vt_min_backoff")
 	// the original default minimum backoff was based on ack wait timeout, so this preserves that
-	if ta.MessageInfo.MinBackoff == 0 {
-		ta.MessageInfo.MinBackoff = ta.MessageInfo.AckWaitDuration
+	if info.MinBackoff == 0 {
+		info.MinBackoff = info.AckWaitDuration
 	}
 
-	ta.MessageInfo.MaxBackoff, _ = getDuration(keyvals, "vt_max_backoff")
+	info.MaxBackoff, _ = getDuration(keyvals, "vt_max_backoff")
 
-	for _, col := range requiredCols {
-		num := ta.FindColumn(sqlparser.NewColIdent(col))
-		if num == -1 {
-			return fmt.Errorf("%s missing from message table: %s", col, ta.Name.String())
-		}
+	info.MaxAttempts, _ = getNum(keyvals, "vt_max_attempts")
+	info.DeadLetterTable = keyvals["vt_dead_letter_table"]
+	if info.DeadLetterTable != "" && info.MaxAttempts == 0 {
+		return nil, fmt.Errorf("vt_dead_letter_table requires vt_max_attempts for message table: %s", tableName)
 	}
-
-	// Load user-defined columns. Any "unrecognized" column is user-defined.
-	for _, field := range ta.Fields {
-		if _, ok := hiddenCols[strings.ToLower(field.Name)]; ok {
-			continue
-		}
-		ta.MessageInfo.Fields = append(ta.MessageInfo.Fields, field)
-	}
-	return nil
+	return info, nil
 }
 
 func getDuration(in map[string]string, key string) (time.Duration, error) {
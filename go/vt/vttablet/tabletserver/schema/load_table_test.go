@@ -141,6 +141,32 @@ func TestLoadTableMessage(t *testing.T) {
 	want.MessageInfo.MaxBackoff = 100 * time.Second
 	assert.Equal(t, want, table)
 
+	// Test loading max attempts and dead letter table
+	table, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_min_backoff=10,vt_max_backoff=100,vt_max_attempts=5,vt_dead_letter_table=test_table_dlq", db)
+	require.NoError(t, err)
+	want.MessageInfo.MaxAttempts = 5
+	want.MessageInfo.DeadLetterTable = "test_table_dlq"
+	assert.Equal(t, want, table)
+
+	// Dead letter table without max attempts
+	_, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_dead_letter_table=test_table_dlq", db)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "vt_dead_letter_table requires vt_max_attempts")
+
+	// Test loading a table ordered by message group
+	groupFields := append(want.Fields, &querypb.Field{
+		Name: "message_group_id",
+		Type: sqltypes.VarBinary,
+	})
+	db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{Fields: groupFields})
+	table, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30", db)
+	require.NoError(t, err)
+	assert.True(t, table.MessageInfo.OrderedByGroup)
+	assert.Equal(t, "message_group_id", table.MessageInfo.Fields[2].Name)
+	for query, result := range getMessageTableQueries() {
+		db.AddQuery(query, result)
+	}
+
 	// Missing property
 	_, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30", db)
 	wanterr := "not specified for message table"
@@ -103,6 +103,22 @@ type MessageInfo struct {
 	// MaxBackoff specifies the longest duration message manager
 	// should wait before rescheduling a message
 	MaxBackoff time.Duration
+
+	// MaxAttempts specifies the number of times a message is sent
+	// before it's given up on. If zero, messages are resent until
+	// they're acked.
+	MaxAttempts int
+
+	// DeadLetterTable specifies the table the messages that exceed
+	// MaxAttempts are moved to. If empty, such messages are acked
+	// and eventually purged.
+	DeadLetterTable string
+
+	// OrderedByGroup is set if the table has a message_group_id
+	// column. Messages of the same group are then sent one at
+	// a time, in the order of their ids: a message is sent only
+	// after all the previous ones of its group are acked.
+	OrderedByGroup bool
 }
 
 // NewTable creates a new Table.
@@ -64,13 +64,16 @@ type StatefulConnectionPool struct {
 func NewStatefulConnPool(env tabletenv.Env) *StatefulConnectionPool {
 	config := env.Config()
 
-	return &StatefulConnectionPool{
+	sf := &StatefulConnectionPool{
 		env:           env,
 		conns:         connpool.NewPool(env, "TransactionPool", config.TxPool),
 		foundRowsPool: connpool.NewPool(env, "FoundRowsPool", config.TxPool),
 		active:        pools.NewNumbered(),
 		lastID:        sync2.NewAtomicInt64(time.Now().UnixNano()),
 	}
+	sf.conns.EnableWorkloadClasses(config.WorkloadClasses)
+	sf.foundRowsPool.EnableWorkloadClasses(config.WorkloadClasses)
+	return sf
 }
 
 // Open makes the TxPool operational. This also starts the transaction killer
@@ -167,16 +170,18 @@ func (sf *StatefulConnectionPool) GetAndLock(id int64, reason string) (*Stateful
 }
 
 // NewConn creates a new StatefulConnection. It will be created from either the normal pool or
-// the found_rows pool, depending on the options provided
+// the found_rows pool, depending on the options provided, and has the settings of the options applied.
 func (sf *StatefulConnectionPool) NewConn(ctx context.Context, options *querypb.ExecuteOptions) (*StatefulConnection, error) {
+	setting, err := connpool.NewSetting(options.GetSettings())
+	if err != nil {
+		return nil, err
+	}
 
 	var conn *connpool.DBConn
-	var err error
-
 	if options.GetClientFoundRows() {
-		conn, err = sf.foundRowsPool.Get(ctx)
+		conn, err = sf.foundRowsPool.GetWithSetting(ctx, setting)
 	} else {
-		conn, err = sf.conns.Get(ctx)
+		conn, err = sf.conns.GetWithSetting(ctx, setting)
 	}
 	if err != nil {
 		return nil, err
@@ -73,6 +73,8 @@ var (
 	// The following vars are used for custom initialization of Tabletconfig.
 	enableHotRowProtection       bool
 	enableHotRowProtectionDryRun bool
+	enableLoadShedding           bool
+	enableLoadSheddingDryRun     bool
 	enableConsolidator           bool
 	enableConsolidatorReplicas   bool
 	enableHeartbeat              bool
@@ -141,6 +143,15 @@ func init() {
 	flag.IntVar(&currentConfig.HotRowProtection.MaxGlobalQueueSize, "hot_row_protection_max_global_queue_size", defaultConfig.HotRowProtection.MaxGlobalQueueSize, "Global queue limit across all row (ranges). Useful to prevent that the queue can grow unbounded.")
 	flag.IntVar(&currentConfig.HotRowProtection.MaxConcurrency, "hot_row_protection_concurrent_transactions", defaultConfig.HotRowProtection.MaxConcurrency, "Number of concurrent transactions let through to the txpool/MySQL for the same hot row. Should be > 1 to have enough 'ready' transactions in MySQL and benefit from a pipelining effect.")
 
+	flag.BoolVar(&enableLoadShedding, "enable_load_shedding", false, "If true, low priority queries will be delayed and rejected while MySQL is overloaded.")
+	flag.BoolVar(&enableLoadSheddingDryRun, "enable_load_shedding_dry_run", false, "If true, load shedding is not enforced but logs if queries would have been rejected.")
+	SecondsVar(&currentConfig.LoadShedding.CheckIntervalSeconds, "load_shedding_check_interval", defaultConfig.LoadShedding.CheckIntervalSeconds, "How often (in seconds) the load shedder checks whether MySQL is overloaded.")
+	SecondsVar(&currentConfig.LoadShedding.MaxPoolWaitSeconds, "load_shedding_max_pool_wait", defaultConfig.LoadShedding.MaxPoolWaitSeconds, "MySQL is overloaded if queries waited longer than this (in seconds) on average for a query pool connection since the last check. 0 disables the check.")
+	flag.IntVar(&currentConfig.LoadShedding.MaxThreadsRunning, "load_shedding_max_threads_running", defaultConfig.LoadShedding.MaxThreadsRunning, "MySQL is overloaded if Threads_running exceeds this value. 0 disables the check.")
+	flag.IntVar(&currentConfig.LoadShedding.MaxRowLockWaits, "load_shedding_max_row_lock_waits", defaultConfig.LoadShedding.MaxRowLockWaits, "MySQL is overloaded if Innodb_row_lock_current_waits exceeds this value. 0 disables the check.")
+	flag.IntVar(&currentConfig.LoadShedding.ShedPriority, "load_shedding_shed_priority", defaultConfig.LoadShedding.ShedPriority, "Queries of workload classes with a priority up to this value are shed while MySQL is overloaded.")
+	SecondsVar(&currentConfig.LoadShedding.MaxDelaySeconds, "load_shedding_max_delay", defaultConfig.LoadShedding.MaxDelaySeconds, "How long (in seconds) low priority queries wait for the overload to clear before they are rejected.")
+
 	flag.BoolVar(&currentConfig.EnableTransactionLimit, "enable_transaction_limit", defaultConfig.EnableTransactionLimit, "If true, limit on number of transactions open at the same time will be enforced for all users. User trying to open a new transaction after exhausting their limit will receive an error immediately, regardless of whether there are available slots or not.")
 	flag.BoolVar(&currentConfig.EnableTransactionLimitDryRun, "enable_transaction_limit_dry_run", defaultConfig.EnableTransactionLimitDryRun, "If true, limit on number of transactions open at the same time will be tracked for all users, but not enforced.")
 	flag.Float64Var(&currentConfig.TransactionLimitPerUser, "transaction_limit_per_user", defaultConfig.TransactionLimitPerUser, "Maximum number of transactions a single user is allowed to use at any time, represented as fraction of -transaction_cap.")
@@ -184,6 +195,16 @@ func Init() {
 		currentConfig.HotRowProtection.Mode = Disable
 	}
 
+	if enableLoadShedding {
+		if enableLoadSheddingDryRun {
+			currentConfig.LoadShedding.Mode = Dryrun
+		} else {
+			currentConfig.LoadShedding.Mode = Enable
+		}
+	} else {
+		currentConfig.LoadShedding.Mode = Disable
+	}
+
 	switch {
 	case enableConsolidatorReplicas:
 		currentConfig.Consolidator = NotOnPrimary
@@ -241,6 +262,7 @@ type TabletConfig struct {
 
 	Oltp             OltpConfig             `json:"oltp,omitempty"`
 	HotRowProtection HotRowProtectionConfig `json:"hotRowProtection,omitempty"`
+	LoadShedding     LoadSheddingConfig     `json:"loadShedding,omitempty"`
 
 	Healthcheck  HealthcheckConfig  `json:"healthcheck,omitempty"`
 	GracePeriods GracePeriodsConfig `json:"gracePeriods,omitempty"`
@@ -269,6 +291,8 @@ type TabletConfig struct {
 
 	ExternalConnections map[string]*dbconfigs.DBConfigs `json:"externalConnections,omitempty"`
 
+	WorkloadClasses []WorkloadClassConfig `json:"workloadClasses,omitempty"`
+
 	StrictTableACL          bool    `json:"-"`
 	EnableTableACLDryRun    bool    `json:"-"`
 	TableACLExemptACL       string  `json:"-"`
@@ -314,6 +338,28 @@ type HotRowProtectionConfig struct {
 	MaxConcurrency     int    `json:"maxConcurrency,omitempty"`
 }
 
+// LoadSheddingConfig contains the config for load shedding. MySQL is
+// overloaded while any of the thresholds is exceeded. A threshold of 0
+// is not checked.
+type LoadSheddingConfig struct {
+	// Mode can be disable, dryRun or enable. Default is disable.
+	Mode                 string  `json:"mode,omitempty"`
+	CheckIntervalSeconds Seconds `json:"checkIntervalSeconds,omitempty"`
+	// MaxPoolWaitSeconds is the threshold for the average time queries
+	// waited for a query pool connection since the last check.
+	MaxPoolWaitSeconds Seconds `json:"maxPoolWaitSeconds,omitempty"`
+	// MaxThreadsRunning is the threshold for Threads_running.
+	MaxThreadsRunning int `json:"maxThreadsRunning,omitempty"`
+	// MaxRowLockWaits is the threshold for Innodb_row_lock_current_waits.
+	MaxRowLockWaits int `json:"maxRowLockWaits,omitempty"`
+	// ShedPriority is the highest priority of the workload classes that
+	// are shed. Queries of classes with a higher priority always go through.
+	ShedPriority int `json:"shedPriority,omitempty"`
+	// MaxDelaySeconds is how long queries wait for the overload to clear
+	// before they are rejected.
+	MaxDelaySeconds Seconds `json:"maxDelaySeconds,omitempty"`
+}
+
 // HealthcheckConfig contains the config for healthcheck.
 type HealthcheckConfig struct {
 	IntervalSeconds           Seconds `json:"intervalSeconds,omitempty"`
@@ -328,6 +374,26 @@ type GracePeriodsConfig struct {
 	TransitionSeconds Seconds `json:"transitionSeconds,omitempty"`
 }
 
+// WorkloadClassConfig contains the config for a workload class. When
+// workload classes are configured, the connections of the query, stream
+// and transaction pools are shared by the classes in proportion to their
+// shares. Queries that don't belong to any class are in the default class.
+type WorkloadClassConfig struct {
+	Name string `json:"name,omitempty"`
+	// Share is the share of connections of the class, relative to the
+	// shares of the other classes. Default is 1.
+	Share int `json:"share,omitempty"`
+	// Priority is the priority of the class. The waiters of classes with a
+	// higher priority get connections before all the others.
+	Priority int `json:"priority,omitempty"`
+	// QueueTimeSLOSeconds is how long the queries of the class should wait
+	// for a connection at most. Waiters that miss it are served before
+	// the waiters of the classes of the same priority.
+	QueueTimeSLOSeconds Seconds `json:"queueTimeSLOSeconds,omitempty"`
+	// Components are the CallerID components whose queries belong to the class.
+	Components []string `json:"components,omitempty"`
+}
+
 // ReplicationTrackerConfig contains the config for the replication tracker.
 type ReplicationTrackerConfig struct {
 	// Mode can be disable, polling or heartbeat. Default is disable.
@@ -383,6 +449,50 @@ func (c *TabletConfig) Verify() error {
 	if v := c.HotRowProtection.MaxConcurrency; v <= 0 {
 		return fmt.Errorf("-hot_row_protection_concurrent_transactions must be > 0 (specified value: %v)", v)
 	}
+	if err := c.verifyLoadSheddingConfig(); err != nil {
+		return err
+	}
+	return c.verifyWorkloadClasses()
+}
+
+// verifyLoadSheddingConfig checks that load shedding, if enabled, has
+// a check interval and at least one threshold.
+func (c *TabletConfig) verifyLoadSheddingConfig() error {
+	if c.LoadShedding.Mode == "" || c.LoadShedding.Mode == Disable {
+		return nil
+	}
+	if v := c.LoadShedding.CheckIntervalSeconds; v <= 0 {
+		return fmt.Errorf("-load_shedding_check_interval must be > 0 (specified value: %v)", v)
+	}
+	if c.LoadShedding.MaxPoolWaitSeconds <= 0 && c.LoadShedding.MaxThreadsRunning <= 0 && c.LoadShedding.MaxRowLockWaits <= 0 {
+		return errors.New("load shedding needs at least one of -load_shedding_max_pool_wait, -load_shedding_max_threads_running or -load_shedding_max_row_lock_waits")
+	}
+	return nil
+}
+
+// verifyWorkloadClasses checks that the workload classes have distinct
+// names, and that no component belongs to more than one class.
+func (c *TabletConfig) verifyWorkloadClasses() error {
+	names := make(map[string]bool)
+	components := make(map[string]string)
+	for _, class := range c.WorkloadClasses {
+		if class.Name == "" {
+			return errors.New("workload classes must have a name")
+		}
+		if names[class.Name] {
+			return fmt.Errorf("workload class %s is defined more than once", class.Name)
+		}
+		names[class.Name] = true
+		if class.Share < 0 {
+			return fmt.Errorf("share of workload class %s must be >= 0 (specified value: %v)", class.Name, class.Share)
+		}
+		for _, component := range class.Components {
+			if other, ok := components[component]; ok {
+				return fmt.Errorf("component %s belongs to workload classes %s and %s", component, other, class.Name)
+			}
+			components[component] = class.Name
+		}
+	}
 	return nil
 }
 
@@ -457,6 +567,11 @@ var defaultConfig = TabletConfig{
 		// of them ready in MySQL and profit from a pipelining effect.
 		MaxConcurrency: 5,
 	},
+	LoadShedding: LoadSheddingConfig{
+		Mode:                 Disable,
+		CheckIntervalSeconds: 1,
+		MaxDelaySeconds:      0.1,
+	},
 	Consolidator:                Enable,
 	ConsolidatorStreamTotalSize: 128 * 1024 * 1024,
 	ConsolidatorStreamQuerySize: 2 * 1024 * 1024,
@@ -70,6 +70,7 @@ func TestConfigParse(t *testing.T) {
 gracePeriods: {}
 healthcheck: {}
 hotRowProtection: {}
+loadShedding: {}
 olapReadPool: {}
 oltp: {}
 oltpReadPool:
@@ -123,6 +124,10 @@ hotRowProtection:
   maxGlobalQueueSize: 1000
   maxQueueSize: 20
   mode: disable
+loadShedding:
+  checkIntervalSeconds: 1
+  maxDelaySeconds: 0.1
+  mode: disable
 messagePostponeParallelism: 4
 olapReadPool:
   idleTimeoutSeconds: 1800
@@ -197,6 +202,10 @@ func TestFlags(t *testing.T) {
 			MaxGlobalQueueSize: 1000,
 			MaxConcurrency:     5,
 		},
+		LoadShedding: LoadSheddingConfig{
+			CheckIntervalSeconds: 1,
+			MaxDelaySeconds:      0.1,
+		},
 		StreamBufferSize:                        32768,
 		QueryCacheSize:                          int(cache.DefaultConfig.MaxEntries),
 		QueryCacheMemory:                        cache.DefaultConfig.MaxMemoryUsage,
@@ -225,6 +234,7 @@ func TestFlags(t *testing.T) {
 	want.OlapReadPool.IdleTimeoutSeconds = 1800
 	want.TxPool.IdleTimeoutSeconds = 1800
 	want.HotRowProtection.Mode = Disable
+	want.LoadShedding.Mode = Disable
 	want.Consolidator = Enable
 	want.Healthcheck.IntervalSeconds = 20
 	want.Healthcheck.DegradedThresholdSeconds = 30
@@ -258,6 +268,24 @@ func TestFlags(t *testing.T) {
 	want.HotRowProtection.Mode = Disable
 	assert.Equal(t, want, currentConfig)
 
+	enableLoadShedding = true
+	enableLoadSheddingDryRun = true
+	Init()
+	want.LoadShedding.Mode = Dryrun
+	assert.Equal(t, want, currentConfig)
+
+	enableLoadShedding = true
+	enableLoadSheddingDryRun = false
+	Init()
+	want.LoadShedding.Mode = Enable
+	assert.Equal(t, want, currentConfig)
+
+	enableLoadShedding = false
+	enableLoadSheddingDryRun = false
+	Init()
+	want.LoadShedding.Mode = Disable
+	assert.Equal(t, want, currentConfig)
+
 	enableConsolidator = true
 	enableConsolidatorReplicas = true
 	Init()
@@ -333,3 +361,48 @@ func TestFlags(t *testing.T) {
 	want.GracePeriods.TransitionSeconds = 4
 	assert.Equal(t, want, currentConfig)
 }
+
+func TestWorkloadClassesConfig(t *testing.T) {
+	inBytes := []byte(`workloadClasses:
+- name: oltp
+  share: 3
+  priority: 1
+  queueTimeSLOSeconds: 0.05
+  components: [web, api]
+- name: batch
+  components: [etl]
+`)
+	cfg := NewDefaultConfig()
+	err := yaml2.Unmarshal(inBytes, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []WorkloadClassConfig{{
+		Name:                "oltp",
+		Share:               3,
+		Priority:            1,
+		QueueTimeSLOSeconds: 0.05,
+		Components:          []string{"web", "api"},
+	}, {
+		Name:       "batch",
+		Components: []string{"etl"},
+	}}, cfg.WorkloadClasses)
+	require.NoError(t, cfg.Verify())
+
+	cfg.WorkloadClasses[1].Components = []string{"api"}
+	assert.EqualError(t, cfg.Verify(), "component api belongs to workload classes oltp and batch")
+	cfg.WorkloadClasses[1].Name = "oltp"
+	assert.EqualError(t, cfg.Verify(), "workload class oltp is defined more than once")
+	cfg.WorkloadClasses[1].Name = ""
+	assert.EqualError(t, cfg.Verify(), "workload classes must have a name")
+}
+
+func TestVerifyLoadShedding(t *testing.T) {
+	cfg := NewDefaultConfig()
+	require.NoError(t, cfg.Verify())
+
+	cfg.LoadShedding.Mode = Enable
+	assert.EqualError(t, cfg.Verify(), "load shedding needs at least one of -load_shedding_max_pool_wait, -load_shedding_max_threads_running or -load_shedding_max_row_lock_waits")
+	cfg.LoadShedding.MaxThreadsRunning = 100
+	require.NoError(t, cfg.Verify())
+	cfg.LoadShedding.CheckIntervalSeconds = 0
+	assert.EqualError(t, cfg.Verify(), "-load_shedding_check_interval must be > 0 (specified value: 0)")
+}
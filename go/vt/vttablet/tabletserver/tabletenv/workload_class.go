@@ -0,0 +1,40 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletenv
+
+import (
+	"context"
+)
+
+// DefaultWorkloadClass is the workload class of the queries
+// that don't belong to any configured class.
+const DefaultWorkloadClass = "default"
+
+type workloadClassKey int
+
+// NewContextWithWorkloadClass returns a context for the queries of
+// the workload class. It overrides the class of the caller id.
+func NewContextWithWorkloadClass(ctx context.Context, class string) context.Context {
+	return context.WithValue(ctx, workloadClassKey(0), class)
+}
+
+// WorkloadClassFromContext returns the workload class of the context,
+// or an empty string.
+func WorkloadClassFromContext(ctx context.Context) string {
+	class, _ := ctx.Value(workloadClassKey(0)).(string)
+	return class
+}
@@ -56,6 +56,7 @@ import (
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	"vitess.io/vitess/go/vt/vttablet/onlineddl"
 	"vitess.io/vitess/go/vt/vttablet/queryservice"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/gc"
@@ -179,6 +180,7 @@ func NewTabletServer(name string, config *tabletenv.TabletConfig, topoServer *to
 	tsv.tracker = schema.NewTracker(tsv, tsv.vstreamer, tsv.se)
 	tsv.watcher = NewBinlogWatcher(tsv, tsv.vstreamer, tsv.config)
 	tsv.qe = NewQueryEngine(tsv, tsv.se)
+	tsv.qe.loadShedder.SetOnChange(tsv.hs.SetOverloaded)
 	tsv.txThrottler = txthrottler.NewTxThrottler(tsv.config, topoServer)
 	tsv.te = NewTxEngine(tsv)
 	tsv.messager = messager.NewEngine(tsv, tsv.se, tsv.vstreamer)
@@ -457,10 +459,12 @@ func (tsv *TabletServer) SchemaEngine() *schema.Engine {
 
 // Begin starts a new transaction. This is allowed only if the state is StateServing.
 func (tsv *TabletServer) Begin(ctx context.Context, target *querypb.Target, options *querypb.ExecuteOptions) (transactionID int64, tablet *topodatapb.TabletAlias, err error) {
-	return tsv.begin(ctx, target, nil, 0, options)
+	return tsv.begin(ctx, target, nil, "", nil, 0, options)
 }
 
-func (tsv *TabletServer) begin(ctx context.Context, target *querypb.Target, preQueries []string, reservedID int64, options *querypb.ExecuteOptions) (transactionID int64, tablet *topodatapb.TabletAlias, err error) {
+// begin starts a new transaction. sql is the first query of the
+// transaction, if known, for the workload class of the load shedding.
+func (tsv *TabletServer) begin(ctx context.Context, target *querypb.Target, preQueries []string, sql string, bindVariables map[string]*querypb.BindVariable, reservedID int64, options *querypb.ExecuteOptions) (transactionID int64, tablet *topodatapb.TabletAlias, err error) {
 	err = tsv.execRequest(
 		ctx, tsv.QueryTimeout.Get(),
 		"Begin", "begin", nil,
@@ -470,6 +474,11 @@ func (tsv *TabletServer) begin(ctx context.Context, target *querypb.Target, preQ
 			if tsv.txThrottler.Throttle() {
 				return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "Transaction throttled")
 			}
+			if reservedID == 0 {
+				if err := tsv.qe.loadShedder.Wait(tsv.withWorkloadClass(ctx, sql, bindVariables, options)); err != nil {
+					return err
+				}
+			}
 			var beginSQL string
 			transactionID, beginSQL, err = tsv.te.Begin(ctx, preQueries, reservedID, options)
 			logStats.TransactionID = transactionID
@@ -491,6 +500,31 @@ func (tsv *TabletServer) begin(ctx context.Context, target *querypb.Target, preQ
 	return transactionID, tsv.alias, err
 }
 
+// withWorkloadClass returns a context for the workload class of sql, set
+// by its WORKLOAD_CLASS directive or by the query rules, like the
+// QueryExecutor does for the queries it runs.
+func (tsv *TabletServer) withWorkloadClass(ctx context.Context, sql string, bindVariables map[string]*querypb.BindVariable, options *querypb.ExecuteOptions) context.Context {
+	if sql == "" {
+		return ctx
+	}
+	query, comments := sqlparser.SplitMarginComments(sql)
+	plan, err := tsv.qe.GetPlan(ctx, tabletenv.NewLogStats(ctx, "Begin"), query, skipQueryPlanCache(options), false /* isReservedConn */)
+	if err != nil {
+		// The query fails on its own once the transaction has begun.
+		return ctx
+	}
+	qre := &QueryExecutor{
+		query:          query,
+		marginComments: comments,
+		bindVars:       bindVariables,
+		options:        options,
+		plan:           plan,
+		ctx:            ctx,
+		tsv:            tsv,
+	}
+	return qre.withWorkloadClass(ctx)
+}
+
 // Commit commits the specified transaction.
 func (tsv *TabletServer) Commit(ctx context.Context, target *querypb.Target, transactionID int64) (newReservedID int64, err error) {
 	err = tsv.execRequest(
@@ -912,7 +946,7 @@ func (tsv *TabletServer) BeginExecute(ctx context.Context, target *querypb.Targe
 		}
 	}
 
-	transactionID, alias, err := tsv.begin(ctx, target, preQueries, reservedID, options)
+	transactionID, alias, err := tsv.begin(ctx, target, preQueries, sql, bindVariables, reservedID, options)
 	if err != nil {
 		return nil, 0, nil, err
 	}
@@ -942,14 +976,14 @@ func (tsv *TabletServer) beginWaitForSameRangeTransactions(ctx context.Context,
 		"", "waitForSameRangeTransactions", nil,
 		target, options, false, /* allowOnShutdown */
 		func(ctx context.Context, logStats *tabletenv.LogStats) error {
-			k, table := tsv.computeTxSerializerKey(ctx, logStats, sql, bindVariables)
-			if k == "" {
+			keys, table := tsv.computeTxSerializerKeys(ctx, logStats, sql, bindVariables)
+			if len(keys) == 0 {
 				// Query is not subject to tx serialization/hot row protection.
 				return nil
 			}
 
 			startTime := time.Now()
-			done, waited, waitErr := tsv.qe.txSerializer.Wait(ctx, k, table)
+			done, waited, waitErr := tsv.qe.txSerializer.WaitAll(ctx, keys, table)
 			txDone = done
 			if waited {
 				tsv.stats.WaitTimings.Record("TxSerializer", startTime)
@@ -960,18 +994,21 @@ func (tsv *TabletServer) beginWaitForSameRangeTransactions(ctx context.Context,
 	return txDone, err
 }
 
-// computeTxSerializerKey returns a unique string ("key") used to determine
-// whether two queries would update the same row (range).
+// computeTxSerializerKeys returns the unique strings ("keys") used to
+// determine whether two queries would update the same rows.
+// If the WHERE clause pins the primary key, there is one key per row e.g.
+// "table1 where id = 1". Otherwise, or if the primary key values target more
+// than maxTxSerializerRows rows, there is a single key for the WHERE clause.
 // Additionally, it returns the table name (needed for updating stats vars).
-// It returns an empty string as key if the row (range) cannot be parsed from
+// It returns no keys if the row (range) cannot be parsed from
 // the query and bind variables or the table name is empty.
-func (tsv *TabletServer) computeTxSerializerKey(ctx context.Context, logStats *tabletenv.LogStats, sql string, bindVariables map[string]*querypb.BindVariable) (string, string) {
+func (tsv *TabletServer) computeTxSerializerKeys(ctx context.Context, logStats *tabletenv.LogStats, sql string, bindVariables map[string]*querypb.BindVariable) ([]string, string) {
 	// Strip trailing comments so we don't pollute the query cache.
 	sql, _ = sqlparser.SplitMarginComments(sql)
 	plan, err := tsv.qe.GetPlan(ctx, logStats, sql, false /* skipQueryPlanCache */, false /* isReservedConn */)
 	if err != nil {
 		logComputeRowSerializerKey.Errorf("failed to get plan for query: %v err: %v", sql, err)
-		return "", ""
+		return nil, ""
 	}
 
 	switch plan.PlanID {
@@ -979,31 +1016,105 @@ func (tsv *TabletServer) computeTxSerializerKey(ctx context.Context, logStats *t
 	case planbuilder.PlanUpdate, planbuilder.PlanUpdateLimit,
 		planbuilder.PlanDelete, planbuilder.PlanDeleteLimit:
 	default:
-		return "", ""
+		return nil, ""
 	}
 
 	tableName := plan.TableName()
 	if tableName.IsEmpty() || plan.WhereClause == nil {
 		// Do not serialize any queries without table name or where clause
-		return "", ""
+		return nil, ""
+	}
+
+	if keys := pkSerializerKeys(plan.Plan, bindVariables); keys != nil {
+		return keys, tableName.String()
 	}
 
 	where, err := plan.WhereClause.GenerateQuery(bindVariables, nil)
 	if err != nil {
 		logComputeRowSerializerKey.Errorf("failed to substitute bind vars in where clause: %v query: %v bind vars: %v", err, sql, bindVariables)
-		return "", ""
+		return nil, ""
 	}
 
 	// Example: table1 where id = 1 and sub_id = 2
 	key := fmt.Sprintf("%s%s", tableName, where)
-	return key, tableName.String()
+	return []string{key}, tableName.String()
+}
+
+// maxTxSerializerRows is the maximum number of rows for which a transaction
+// is serialized per row. Beyond that, it's serialized by its WHERE clause.
+const maxTxSerializerRows = 100
+
+// pkSerializerKeys returns one key per row targeted by the primary key
+// values of the plan, or nil if the plan has none. The rows are the
+// cross product of the values of each primary key column, since every
+// column of a composite primary key can have its own IN list. The values
+// are cast to the type of their column, so that e.g. id = 5 and id = '5'
+// have the same key. It returns nil if a value can't be cast, or if
+// there are more than maxTxSerializerRows rows.
+func pkSerializerKeys(plan *planbuilder.Plan, bindVariables map[string]*querypb.BindVariable) []string {
+	if plan.PKValues == nil {
+		return nil
+	}
+	rows := [][]sqltypes.Value{nil}
+	for i, pv := range plan.PKValues {
+		var values []sqltypes.Value
+		if pv.IsList() {
+			var err error
+			if values, err = pv.ResolveList(bindVariables); err != nil {
+				return nil
+			}
+		} else {
+			value, err := pv.ResolveValue(bindVariables)
+			if err != nil {
+				return nil
+			}
+			values = []sqltypes.Value{value}
+		}
+		if len(rows)*len(values) > maxTxSerializerRows {
+			return nil
+		}
+		typ := plan.Table.Fields[plan.Table.PKColumns[i]].Type
+		for j, value := range values {
+			var err error
+			if values[j], err = evalengine.Cast(value, typ); err != nil {
+				return nil
+			}
+		}
+		next := make([][]sqltypes.Value, 0, len(rows)*len(values))
+		for _, row := range rows {
+			for _, value := range values {
+				next = append(next, append(row[:len(row):len(row)], value))
+			}
+		}
+		rows = next
+	}
+	keys := make([]string, 0, len(rows))
+	for _, row := range rows {
+		// Example: table1 where id = 1 and sub_id = 2
+		buf := &strings.Builder{}
+		fmt.Fprintf(buf, "%s where ", plan.TableName())
+		for i, value := range row {
+			if i > 0 {
+				buf.WriteString(" and ")
+			}
+			fmt.Fprintf(buf, "%s = ", plan.Table.Fields[plan.Table.PKColumns[i]].Name)
+			value.EncodeSQL(buf)
+		}
+		keys = append(keys, buf.String())
+	}
+	return keys
 }
 
 // BeginExecuteBatch combines Begin and ExecuteBatch.
 func (tsv *TabletServer) BeginExecuteBatch(ctx context.Context, target *querypb.Target, queries []*querypb.BoundQuery, asTransaction bool, options *querypb.ExecuteOptions) ([]sqltypes.Result, int64, *topodatapb.TabletAlias, error) {
 	// TODO(mberlin): Integrate hot row protection here as we did for BeginExecute()
 	// and ExecuteBatch(asTransaction=true).
-	transactionID, alias, err := tsv.Begin(ctx, target, options)
+	var sql string
+	var bindVariables map[string]*querypb.BindVariable
+	if len(queries) > 0 {
+		sql, bindVariables = queries[0].Sql, queries[0].BindVariables
+	}
+	transactionID, alias, err := tsv.begin(ctx, target, nil, sql, bindVariables, 0, options)
 	if err != nil {
 		return nil, 0, nil, err
 	}
@@ -1049,6 +1160,7 @@ func (tsv *TabletServer) MessageAck(ctx context.Context, target *querypb.Target,
 		return 0, err
 	}
 	messager.MessageStats.Add([]string{name, "Acked"}, count)
+	messager.MessageStats.Add([]string{name, "AckBatches"}, 1)
 	return count, nil
 }
 
@@ -1068,14 +1180,35 @@ func (tsv *TabletServer) PurgeMessages(ctx context.Context, target *querypb.Targ
 	})
 }
 
+// DeadLetterMessages moves the list of messages for a given message table
+// to its dead letter table, or acks them if it has none.
+// It returns the number of messages successfully moved.
+func (tsv *TabletServer) DeadLetterMessages(ctx context.Context, target *querypb.Target, name string, ids []string) (count int64, err error) {
+	return tsv.execDMLs(ctx, target, func() ([]*querypb.BoundQuery, error) {
+		return tsv.messager.GenerateDeadLetterQueries(name, ids)
+	})
+}
+
 func (tsv *TabletServer) execDML(ctx context.Context, target *querypb.Target, queryGenerator func() (string, map[string]*querypb.BindVariable, error)) (count int64, err error) {
+	return tsv.execDMLs(ctx, target, func() ([]*querypb.BoundQuery, error) {
+		query, bv, err := queryGenerator()
+		if err != nil {
+			return nil, err
+		}
+		return []*querypb.BoundQuery{{Sql: query, BindVariables: bv}}, nil
+	})
+}
+
+// execDMLs executes the queries in a single transaction, and returns
+// the number of rows affected by the last one.
+func (tsv *TabletServer) execDMLs(ctx context.Context, target *querypb.Target, queryGenerator func() ([]*querypb.BoundQuery, error)) (count int64, err error) {
 	if err = tsv.sm.StartRequest(ctx, target, false /* allowOnShutdown */); err != nil {
 		return 0, err
 	}
 	defer tsv.sm.EndRequest()
 	defer tsv.handlePanicAndSendLogStats("ack", nil, nil)
 
-	query, bv, err := queryGenerator()
+	queries, err := queryGenerator()
 	if err != nil {
 		return 0, err
 	}
@@ -1091,9 +1224,11 @@ func (tsv *TabletServer) execDML(ctx context.Context, target *querypb.Target, qu
 			tsv.Rollback(ctx, target, transactionID)
 		}
 	}()
-	qr, err := tsv.Execute(ctx, target, query, bv, transactionID, 0, nil)
-	if err != nil {
-		return 0, err
+	var qr *sqltypes.Result
+	for _, query := range queries {
+		if qr, err = tsv.Execute(ctx, target, query.Sql, query.BindVariables, transactionID, 0, nil); err != nil {
+			return 0, err
+		}
 	}
 	if _, err = tsv.Commit(ctx, target, transactionID); err != nil {
 		transactionID = 0
@@ -50,6 +50,8 @@ import (
 	"vitess.io/vitess/go/vt/tableacl/simpleacl"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -980,7 +982,7 @@ func TestSerializeTransactionsSameRow(t *testing.T) {
 	db.SetBeforeFunc("update test_table set name_string = 'tx1' where pk = 1 and `name` = 1 limit 10001",
 		func() {
 			close(tx1Started)
-			if err := waitForTxSerializationPendingQueries(tsv, "test_table where pk = 1 and `name` = 1", 2); err != nil {
+			if err := waitForTxSerializationPendingQueries(tsv, "test_table where pk = 1", 2); err != nil {
 				t.Fatal(err)
 			}
 		})
@@ -1119,7 +1121,7 @@ func TestSerializeTransactionsSameRow_ExecuteBatchAsTransaction(t *testing.T) {
 	db.SetBeforeFunc("update test_table set name_string = 'tx1' where pk = 1 and `name` = 1 limit 10001",
 		func() {
 			close(tx1Started)
-			if err := waitForTxSerializationPendingQueries(tsv, "test_table where pk = 1 and `name` = 1", 2); err != nil {
+			if err := waitForTxSerializationPendingQueries(tsv, "test_table where pk = 1", 2); err != nil {
 				t.Fatal(err)
 			}
 		})
@@ -1296,7 +1298,7 @@ func TestSerializeTransactionsSameRow_ConcurrentTransactions(t *testing.T) {
 	// transactions via db.SetBeforeFunc() for the same reason as mentioned
 	// in TestSerializeTransactionsSameRow: The MySQL C client does not seem
 	// to allow more than connection attempt at a time.
-	err := waitForTxSerializationPendingQueries(tsv, "test_table where pk = 1 and `name` = 1", 3)
+	err := waitForTxSerializationPendingQueries(tsv, "test_table where pk = 1", 3)
 	require.NoError(t, err)
 	close(allQueriesPending)
 
@@ -1324,6 +1326,129 @@ func waitForTxSerializationPendingQueries(tsv *TabletServer, key string, i int)
 	}
 }
 
+func TestComputeTxSerializerKeys(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.HotRowProtection.Mode = tabletenv.Enable
+	db, tsv := setupTabletServerTestCustom(t, config, "")
+	defer tsv.StopService()
+	defer db.Close()
+
+	var manyPKs []interface{}
+	var manyPKStrings []string
+	for i := 0; i <= maxTxSerializerRows; i++ {
+		manyPKs = append(manyPKs, i)
+		manyPKStrings = append(manyPKStrings, fmt.Sprint(i))
+	}
+	manyPKsSQL := "(" + strings.Join(manyPKStrings, ", ") + ")"
+
+	testcases := []struct {
+		sql      string
+		bindVars map[string]*querypb.BindVariable
+		keys     []string
+	}{{
+		sql:  "update test_table set name_string = 'a' where pk = 1",
+		keys: []string{"test_table where pk = 1"},
+	}, {
+		// Other conditions don't change the row.
+		sql:  "update test_table set name_string = 'a' where `name` = 2 and pk = 1",
+		keys: []string{"test_table where pk = 1"},
+	}, {
+		sql:      "delete from test_table where pk = :pk",
+		bindVars: map[string]*querypb.BindVariable{"pk": sqltypes.Int64BindVariable(1)},
+		keys:     []string{"test_table where pk = 1"},
+	}, {
+		// The values are normalized by the type of the column.
+		sql:      "delete from test_table where pk = :pk",
+		bindVars: map[string]*querypb.BindVariable{"pk": sqltypes.StringBindVariable("1")},
+		keys:     []string{"test_table where pk = 1"},
+	}, {
+		sql:  "update test_table set name_string = 'a' where pk in (1, 2)",
+		keys: []string{"test_table where pk = 1", "test_table where pk = 2"},
+	}, {
+		sql:      "update test_table set name_string = 'a' where pk in ::pks",
+		bindVars: map[string]*querypb.BindVariable{"pks": sqltypes.TestBindVariable([]interface{}{1, 2})},
+		keys:     []string{"test_table where pk = 1", "test_table where pk = 2"},
+	}, {
+		// Without the primary key, the WHERE clause is the key.
+		sql:  "update test_table set name_string = 'a' where `name` = 2",
+		keys: []string{"test_table where `name` = 2"},
+	}, {
+		// Beyond maxTxSerializerRows rows, the WHERE clause is the key.
+		sql:      "update test_table set name_string = 'a' where pk in ::pks",
+		bindVars: map[string]*querypb.BindVariable{"pks": sqltypes.TestBindVariable(manyPKs)},
+		keys:     []string{"test_table where pk in " + manyPKsSQL},
+	}, {
+		sql: "select * from test_table where pk = 1",
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.sql, func(t *testing.T) {
+			logStats := tabletenv.NewLogStats(ctx, "TxSerializerKeys")
+			keys, table := tsv.computeTxSerializerKeys(ctx, logStats, tcase.sql, tcase.bindVars)
+			assert.Equal(t, tcase.keys, keys)
+			if tcase.keys != nil {
+				assert.Equal(t, "test_table", table)
+			}
+		})
+	}
+}
+
+func TestPKSerializerKeys(t *testing.T) {
+	tables := map[string]*schema.Table{
+		"t": {
+			Name:      sqlparser.NewTableIdent("t"),
+			Fields:    []*querypb.Field{{Name: "a", Type: sqltypes.Int64}, {Name: "b", Type: sqltypes.VarChar}, {Name: "c", Type: sqltypes.Int64}},
+			PKColumns: []int{0, 1},
+		},
+	}
+	var manyValues []string
+	for i := 0; i < 11; i++ {
+		manyValues = append(manyValues, fmt.Sprint(i))
+	}
+
+	testcases := []struct {
+		sql      string
+		bindVars map[string]*querypb.BindVariable
+		keys     []string
+	}{{
+		sql:  "update t set c = 1 where a = 1 and b = '2'",
+		keys: []string{"t where a = 1 and b = '2'"},
+	}, {
+		// The values are cast to the type of their column.
+		sql:  "update t set c = 1 where a = '1' and b = 2",
+		keys: []string{"t where a = 1 and b = '2'"},
+	}, {
+		// A value that doesn't fit the column falls back to the WHERE clause.
+		sql: "update t set c = 1 where a = 'x' and b = 2",
+	}, {
+		// Every primary key column can have its own IN list.
+		sql: "update t set c = 1 where a in (1, 2) and b in (3, 4)",
+		keys: []string{
+			"t where a = 1 and b = '3'",
+			"t where a = 1 and b = '4'",
+			"t where a = 2 and b = '3'",
+			"t where a = 2 and b = '4'",
+		},
+	}, {
+		sql:      "delete from t where b in ::bs and a = 1",
+		bindVars: map[string]*querypb.BindVariable{"bs": sqltypes.TestBindVariable([]interface{}{3, 4})},
+		keys:     []string{"t where a = 1 and b = '3'", "t where a = 1 and b = '4'"},
+	}, {
+		// Beyond maxTxSerializerRows rows, the WHERE clause is used.
+		sql: fmt.Sprintf("update t set c = 1 where a in (%s) and b in (%s)", strings.Join(manyValues, ", "), strings.Join(manyValues[:10], ", ")),
+	}, {
+		sql: "update t set c = 1 where a in (1, 2)",
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.sql, func(t *testing.T) {
+			stmt, err := sqlparser.Parse(tcase.sql)
+			require.NoError(t, err)
+			plan, err := planbuilder.Build(stmt, tables, false, "dbName")
+			require.NoError(t, err)
+			assert.Equal(t, tcase.keys, pkSerializerKeys(plan, tcase.bindVars))
+		})
+	}
+}
+
 func TestSerializeTransactionsSameRow_TooManyPendingRequests(t *testing.T) {
 	// This test is similar to TestSerializeTransactionsSameRow, but tests only
 	// that there must not be too many pending BeginExecute() requests which are
@@ -1390,7 +1515,7 @@ func TestSerializeTransactionsSameRow_TooManyPendingRequests(t *testing.T) {
 
 		<-tx1Started
 		_, _, _, err := tsv.BeginExecute(ctx, &target, nil, q2, bvTx2, 0, nil)
-		if err == nil || vterrors.Code(err) != vtrpcpb.Code_RESOURCE_EXHAUSTED || err.Error() != "hot row protection: too many queued transactions (1 >= 1) for the same row (table + WHERE clause: 'test_table where pk = 1 and `name` = 1')" {
+		if err == nil || vterrors.Code(err) != vtrpcpb.Code_RESOURCE_EXHAUSTED || err.Error() != "hot row protection: too many queued transactions (1 >= 1) for the same row (table + WHERE clause: 'test_table where pk = 1')" {
 			t.Errorf("tx2 should have failed because there are too many pending requests: %v", err)
 		}
 		// No commit necessary because the Begin failed.
@@ -1479,7 +1604,7 @@ func TestSerializeTransactionsSameRow_TooManyPendingRequests_ExecuteBatchAsTrans
 			Sql:           q2,
 			BindVariables: bvTx2,
 		}}, true /*asTransaction*/, 0 /*connID*/, nil /*options*/)
-		if err == nil || vterrors.Code(err) != vtrpcpb.Code_RESOURCE_EXHAUSTED || err.Error() != "hot row protection: too many queued transactions (1 >= 1) for the same row (table + WHERE clause: 'test_table where pk = 1 and `name` = 1')" {
+		if err == nil || vterrors.Code(err) != vtrpcpb.Code_RESOURCE_EXHAUSTED || err.Error() != "hot row protection: too many queued transactions (1 >= 1) for the same row (table + WHERE clause: 'test_table where pk = 1')" {
 			t.Errorf("tx2 should have failed because there are too many pending requests: %v results: %+v", err, results)
 		}
 	}()
@@ -1581,7 +1706,7 @@ func TestSerializeTransactionsSameRow_RequestCanceled(t *testing.T) {
 		defer wg.Done()
 
 		// Wait until tx1 and tx2 are pending to make the test deterministic.
-		if err := waitForTxSerializationPendingQueries(tsv, "test_table where pk = 1 and `name` = 1", 2); err != nil {
+		if err := waitForTxSerializationPendingQueries(tsv, "test_table where pk = 1", 2); err != nil {
 			t.Error(err)
 		}
 
@@ -1596,7 +1721,7 @@ func TestSerializeTransactionsSameRow_RequestCanceled(t *testing.T) {
 	}()
 
 	// Wait until tx1, 2 and 3 are pending.
-	err := waitForTxSerializationPendingQueries(tsv, "test_table where pk = 1 and `name` = 1", 3)
+	err := waitForTxSerializationPendingQueries(tsv, "test_table where pk = 1", 3)
 	require.NoError(t, err)
 	// Now unblock tx2 and cancel it.
 	cancelTx2()
@@ -1708,6 +1833,24 @@ func TestPurgeMessages(t *testing.T) {
 	require.EqualValues(t, 1, count)
 }
 
+func TestDeadLetterMessages(t *testing.T) {
+	_, tsv, db := newTestTxExecutor(t)
+	defer db.Close()
+	defer tsv.StopService()
+	target := querypb.Target{TabletType: topodatapb.TabletType_PRIMARY}
+
+	_, err := tsv.DeadLetterMessages(ctx, &target, "nonmsg", []string{"1", "2"})
+	want := "message table nonmsg not found in schema"
+	require.Error(t, err)
+	require.Contains(t, err.Error(), want)
+
+	// msg has no dead letter table: the messages are acked.
+	db.AddQueryPattern("update msg set time_acked = .*", &sqltypes.Result{RowsAffected: 2})
+	count, err := tsv.DeadLetterMessages(ctx, &target, "msg", []string{"1", "2"})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, count)
+}
+
 func TestHandleExecUnknownError(t *testing.T) {
 	logStats := tabletenv.NewLogStats(ctx, "TestHandleExecError")
 	config := tabletenv.NewDefaultConfig()
@@ -106,8 +106,10 @@ type TwoPC struct {
 	updateRedoTx        *sqlparser.ParsedQuery
 	deleteRedoTx        *sqlparser.ParsedQuery
 	deleteRedoStmt      *sqlparser.ParsedQuery
+	lockRedoTx          *sqlparser.ParsedQuery
 	readAllRedo         string
 	countUnresolvedRedo *sqlparser.ParsedQuery
+	countFailedRedo     *sqlparser.ParsedQuery
 
 	insertTransaction   *sqlparser.ParsedQuery
 	insertParticipants  *sqlparser.ParsedQuery
@@ -139,10 +141,16 @@ func NewTwoPC(readPool *connpool.Pool) *TwoPC {
 	tpc.deleteRedoStmt = sqlparser.BuildParsedQuery(
 		"delete from %s.redo_statement where dtid = %a",
 		dbname, ":dtid")
+	tpc.lockRedoTx = sqlparser.BuildParsedQuery(
+		"select state from %s.redo_state where dtid = %a for update",
+		dbname, ":dtid")
 	tpc.readAllRedo = fmt.Sprintf(sqlReadAllRedo, dbname, dbname)
 	tpc.countUnresolvedRedo = sqlparser.BuildParsedQuery(
 		"select count(*) from %s.redo_state where time_created < %a",
 		dbname, ":time_created")
+	tpc.countFailedRedo = sqlparser.BuildParsedQuery(
+		"select count(*) from %s.redo_state where state = %a",
+		dbname, ":state")
 
 	tpc.insertTransaction = sqlparser.BuildParsedQuery(
 		"insert into %s.dt_state(dtid, state, time_created) values (%a, %a, %a)",
@@ -260,6 +268,26 @@ func (tpc *TwoPC) DeleteRedo(ctx context.Context, conn *StatefulConnection, dtid
 	return err
 }
 
+// LockRedo locks the redo log for the dtid using the supplied connection,
+// and returns its state. It returns false if there's no redo log for the dtid.
+func (tpc *TwoPC) LockRedo(ctx context.Context, conn *StatefulConnection, dtid string) (int, bool, error) {
+	bindVars := map[string]*querypb.BindVariable{
+		"dtid": sqltypes.StringBindVariable(dtid),
+	}
+	qr, err := tpc.exec(ctx, conn, tpc.lockRedoTx, bindVars)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(qr.Rows) < 1 {
+		return 0, false, nil
+	}
+	st, err := evalengine.ToInt64(qr.Rows[0][0])
+	if err != nil {
+		return 0, false, err
+	}
+	return int(st), true, nil
+}
+
 // ReadAllRedo returns all the prepared transactions from the redo logs.
 func (tpc *TwoPC) ReadAllRedo(ctx context.Context) (prepared, failed []*tx.PreparedTx, err error) {
 	conn, err := tpc.readPool.Get(ctx)
@@ -326,6 +354,29 @@ func (tpc *TwoPC) CountUnresolvedRedo(ctx context.Context, unresolvedTime time.T
 	return v, nil
 }
 
+// CountFailedRedo returns the number of prepared transactions that could
+// not be committed. They remain in doubt until their commit is retried.
+func (tpc *TwoPC) CountFailedRedo(ctx context.Context) (int64, error) {
+	conn, err := tpc.readPool.Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Recycle()
+
+	bindVars := map[string]*querypb.BindVariable{
+		"state": sqltypes.Int64BindVariable(RedoStateFailed),
+	}
+	qr, err := tpc.read(ctx, conn, tpc.countFailedRedo, bindVars)
+	if err != nil {
+		return 0, err
+	}
+	if len(qr.Rows) < 1 {
+		return 0, nil
+	}
+	v, _ := evalengine.ToInt64(qr.Rows[0][0])
+	return v, nil
+}
+
 // CreateTransaction saves the metadata of a 2pc transaction as Prepared.
 func (tpc *TwoPC) CreateTransaction(ctx context.Context, conn *StatefulConnection, dtid string, participants []*querypb.Target) error {
 	bindVars := map[string]*querypb.BindVariable{
@@ -395,3 +395,23 @@ func jsonStr(v interface{}) string {
 	out, _ := json.Marshal(v)
 	return string(out)
 }
+
+func TestCountFailedRedo(t *testing.T) {
+	_, tsv, db := newTestTxExecutor(t)
+	defer db.Close()
+	defer tsv.StopService()
+	tpc := tsv.te.twoPC
+	ctx := context.Background()
+
+	db.AddQuery("select count(*) from _vt.redo_state where state = 0", &sqltypes.Result{
+		Fields: []*querypb.Field{{Type: sqltypes.Int64}},
+		Rows:   [][]sqltypes.Value{{sqltypes.NewInt64(2)}},
+	})
+	count, err := tpc.CountFailedRedo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("CountFailedRedo: %d, want 2", count)
+	}
+}
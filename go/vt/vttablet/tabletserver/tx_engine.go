@@ -435,13 +435,17 @@ func (te *TxEngine) rollbackPrepared() {
 	}
 }
 
-// startWatchdog starts the watchdog goroutine, which looks for abandoned
-// transactions and calls the notifier on them.
+// startWatchdog starts the watchdog goroutine, which resolves the
+// prepared transactions of the redo log that are in doubt, and looks
+// for abandoned transactions and calls the notifier on them.
 func (te *TxEngine) startWatchdog() {
 	te.ticks.Start(func() {
 		ctx, cancel := context.WithTimeout(tabletenv.LocalContext(), te.abandonAge/4)
 		defer cancel()
 
+		abandonTime := time.Now().Add(-te.abandonAge)
+		abandoned := te.resolveRedo(ctx, abandonTime)
+
 		// Raise alerts on prepares that have been unresolved for too long.
 		// Use 5x abandonAge to give opportunity for watchdog to resolve these.
 		count, err := te.twoPC.CountUnresolvedRedo(ctx, time.Now().Add(-te.abandonAge*5))
@@ -450,15 +454,31 @@ func (te *TxEngine) startWatchdog() {
 			log.Errorf("Error reading unresolved prepares: '%v': %v", te.coordinatorAddress, err)
 		}
 		te.env.Stats().Unresolved.Set("Prepares", count)
+		count, err = te.twoPC.CountFailedRedo(ctx)
+		if err != nil {
+			te.env.Stats().InternalErrors.Add("WatchdogFail", 1)
+			log.Errorf("Error reading failed prepares: %v", err)
+		}
+		te.env.Stats().Unresolved.Set("FailedPrepares", count)
 
-		// Resolve lingering distributed transactions.
-		txs, err := te.twoPC.ReadAbandoned(ctx, time.Now().Add(-te.abandonAge))
+		// Resolve lingering distributed transactions, and the prepares
+		// that their metadata manager didn't resolve, for instance because
+		// its primary failed over.
+		txs, err := te.twoPC.ReadAbandoned(ctx, abandonTime)
 		if err != nil {
 			te.env.Stats().InternalErrors.Add("WatchdogFail", 1)
 			log.Errorf("Error reading transactions for 2pc watchdog: %v", err)
-			return
+		} else {
+			te.env.Stats().Unresolved.Set("Transactions", int64(len(txs)))
+		}
+		dtids := make(map[string]bool, len(txs)+len(abandoned))
+		for dtid := range txs {
+			dtids[dtid] = true
 		}
-		if len(txs) == 0 {
+		for _, dtid := range abandoned {
+			dtids[dtid] = true
+		}
+		if len(dtids) == 0 {
 			return
 		}
 
@@ -471,20 +491,138 @@ func (te *TxEngine) startWatchdog() {
 		defer coordConn.Close()
 
 		var wg sync.WaitGroup
-		for tx := range txs {
+		for dtid := range dtids {
 			wg.Add(1)
 			go func(dtid string) {
 				defer wg.Done()
 				if err := coordConn.ResolveTransaction(ctx, dtid); err != nil {
 					te.env.Stats().InternalErrors.Add("WatchdogFail", 1)
 					log.Errorf("Error notifying for dtid %s: %v", dtid, err)
+					return
 				}
-			}(tx)
+				te.env.Stats().ResolvedTransactions.Add(1)
+			}(dtid)
 		}
 		wg.Wait()
 	})
 }
 
+// resolveRedo re-reads the redo log, retries the commits that failed and
+// prepares again the transactions that couldn't be prepared from the redo
+// log. A commit is only marked as failed once the coordinator decided to
+// commit, so it's safe to retry it without the coordinator, which allows
+// these transactions to be resolved after a failover. It returns the
+// dtids of the prepares older than abandonTime, for the coordinator to
+// conclude or roll them back.
+func (te *TxEngine) resolveRedo(ctx context.Context, abandonTime time.Time) []string {
+	prepared, failed, err := te.twoPC.ReadAllRedo(ctx)
+	if err != nil {
+		te.env.Stats().InternalErrors.Add("WatchdogFail", 1)
+		log.Errorf("Error reading redo log for 2pc watchdog: %v", err)
+		return nil
+	}
+
+	var abandoned []string
+	for _, preparedTx := range prepared {
+		if !te.preparedPool.Contains(preparedTx.Dtid) {
+			if err := te.prepareAgain(ctx, preparedTx); err != nil {
+				te.env.Stats().InternalErrors.Add("TwopcResurrection", 1)
+				log.Errorf("Error preparing dtid %s from the redo log: %v", preparedTx.Dtid, err)
+			}
+		}
+		if preparedTx.Time.Before(abandonTime) {
+			abandoned = append(abandoned, preparedTx.Dtid)
+		}
+	}
+	for _, failedTx := range failed {
+		if err := te.retryCommit(ctx, failedTx); err != nil {
+			te.env.Stats().InternalErrors.Add("TwopcCommit", 1)
+			log.Errorf("Error retrying the commit of dtid %s: %v", failedTx.Dtid, err)
+			continue
+		}
+		te.env.Stats().ResolvedTransactions.Add(1)
+	}
+	return abandoned
+}
+
+// prepareAgain replays a prepared transaction of the redo log
+// and puts it in the prepared pool. The redo log is locked until
+// then, so that it can't be resolved in the meantime. The lock is
+// taken in a separate transaction: if the prepared transaction held
+// it, RollbackPrepared couldn't delete the redo log.
+func (te *TxEngine) prepareAgain(ctx context.Context, preparedTx *tx.PreparedTx) error {
+	lockConn, err := te.lockRedo(ctx, preparedTx.Dtid, RedoStatePrepared)
+	if lockConn == nil {
+		return err
+	}
+	defer te.txPool.RollbackAndRelease(ctx, lockConn)
+
+	conn, _, err := te.txPool.Begin(ctx, &querypb.ExecuteOptions{}, false, 0, nil)
+	if err != nil {
+		return err
+	}
+	if err := te.replayRedo(ctx, conn, preparedTx); err != nil {
+		te.txPool.RollbackAndRelease(ctx, conn)
+		return err
+	}
+	if err := te.preparedPool.Put(conn, preparedTx.Dtid); err != nil {
+		te.txPool.RollbackAndRelease(ctx, conn)
+		return err
+	}
+	return nil
+}
+
+// retryCommit replays a transaction whose commit failed, and commits it
+// along with the deletion of its redo log, which it locks first.
+func (te *TxEngine) retryCommit(ctx context.Context, failedTx *tx.PreparedTx) error {
+	conn, err := te.lockRedo(ctx, failedTx.Dtid, RedoStateFailed)
+	if conn == nil {
+		return err
+	}
+	defer te.txPool.RollbackAndRelease(ctx, conn)
+	if err := te.replayRedo(ctx, conn, failedTx); err != nil {
+		return err
+	}
+	if err := te.twoPC.DeleteRedo(ctx, conn, failedTx.Dtid); err != nil {
+		return err
+	}
+	if _, err := te.txPool.Commit(ctx, conn); err != nil {
+		return err
+	}
+	te.preparedPool.Forget(failedTx.Dtid)
+	log.Infof("TwoPC: Committed dtid %s from the redo log.", failedTx.Dtid)
+	return nil
+}
+
+// lockRedo begins a transaction that locks the redo log of the dtid, which
+// prevents a concurrent CommitPrepared from applying it twice. It returns
+// a nil connection if the redo log is no longer in the expected state,
+// because the transaction was resolved in the meantime.
+func (te *TxEngine) lockRedo(ctx context.Context, dtid string, state int) (*StatefulConnection, error) {
+	conn, _, err := te.txPool.Begin(ctx, &querypb.ExecuteOptions{}, false, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	current, ok, err := te.twoPC.LockRedo(ctx, conn, dtid)
+	if err != nil || !ok || current != state {
+		te.txPool.RollbackAndRelease(ctx, conn)
+		return nil, err
+	}
+	return conn, nil
+}
+
+// replayRedo replays the statements of the redo log in the transaction
+// of conn.
+func (te *TxEngine) replayRedo(ctx context.Context, conn *StatefulConnection, preparedTx *tx.PreparedTx) error {
+	for _, stmt := range preparedTx.Queries {
+		conn.TxProperties().RecordQuery(stmt)
+		if _, err := conn.Exec(ctx, stmt, 1, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // stopWatchdog stops the watchdog goroutine.
 func (te *TxEngine) stopWatchdog() {
 	te.ticks.Stop()
@@ -424,6 +424,7 @@ func TestExecutorResolveTransaction(t *testing.T) {
 	_, tsv, db := newShortAgeExecutor(t)
 	defer db.Close()
 	defer tsv.StopService()
+	resolved := tsv.stats.ResolvedTransactions.Get()
 	want := "aa"
 	db.AddQueryPattern(
 		"select dtid, time_created from _vt\\.dt_state where time_created.*",
@@ -441,6 +442,83 @@ func TestExecutorResolveTransaction(t *testing.T) {
 	if got != want {
 		t.Errorf("ResolveTransaction: %s, want %s", got, want)
 	}
+	require.Eventually(t, func() bool {
+		return tsv.stats.ResolvedTransactions.Get() > resolved
+	}, 5*time.Second, 10*time.Millisecond)
+	require.EqualValues(t, 1, tsv.stats.Unresolved.Counts()["Transactions"])
+}
+
+func TestExecutorRetryFailedCommit(t *testing.T) {
+	_, tsv, db := newShortAgeExecutor(t)
+	defer db.Close()
+	defer tsv.StopService()
+	resolved := tsv.stats.ResolvedTransactions.Get()
+
+	// The commit of bb failed before a failover.
+	tsv.te.preparedPool.SetFailed("bb")
+	db.AddQuery("select state from _vt.redo_state where dtid = 'bb' for update", &sqltypes.Result{
+		Fields: []*querypb.Field{{Type: sqltypes.Int64}},
+		Rows:   [][]sqltypes.Value{{sqltypes.NewInt64(RedoStateFailed)}},
+	})
+	db.AddQuery("delete from _vt.redo_state where dtid = 'bb'", &sqltypes.Result{})
+	db.AddQuery("delete from _vt.redo_statement where dtid = 'bb'", &sqltypes.Result{})
+	db.AddQuery(tsv.te.twoPC.readAllRedo, &sqltypes.Result{
+		Fields: []*querypb.Field{
+			{Type: sqltypes.VarBinary},
+			{Type: sqltypes.Uint64},
+			{Type: sqltypes.Uint64},
+			{Type: sqltypes.VarBinary},
+		},
+		Rows: [][]sqltypes.Value{{
+			sqltypes.NewVarBinary("bb"),
+			sqltypes.NewInt64(RedoStateFailed),
+			sqltypes.NewVarBinary(fmt.Sprintf("%d", time.Now().UnixNano())),
+			sqltypes.NewVarBinary("update test_table set `name` = 2 where pk = 1 limit 10001"),
+		}},
+	})
+	require.Eventually(t, func() bool {
+		return tsv.stats.ResolvedTransactions.Get() > resolved
+	}, 5*time.Second, 10*time.Millisecond)
+	require.False(t, tsv.te.preparedPool.Contains("bb"))
+	require.NotZero(t, db.GetQueryCalledNum("delete from _vt.redo_state where dtid = 'bb'"))
+}
+
+func TestExecutorRollbackPreparedAgain(t *testing.T) {
+	txe, tsv, db := newShortAgeExecutor(t)
+	defer db.Close()
+	defer tsv.StopService()
+
+	// bb was prepared before a failover, so the watchdog prepares it again.
+	db.AddQuery("select state from _vt.redo_state where dtid = 'bb' for update", &sqltypes.Result{
+		Fields: []*querypb.Field{{Type: sqltypes.Int64}},
+		Rows:   [][]sqltypes.Value{{sqltypes.NewInt64(RedoStatePrepared)}},
+	})
+	db.AddQuery("delete from _vt.redo_state where dtid = 'bb'", &sqltypes.Result{})
+	db.AddQuery("delete from _vt.redo_statement where dtid = 'bb'", &sqltypes.Result{})
+	db.AddQuery(tsv.te.twoPC.readAllRedo, &sqltypes.Result{
+		Fields: []*querypb.Field{
+			{Type: sqltypes.VarBinary},
+			{Type: sqltypes.Uint64},
+			{Type: sqltypes.Uint64},
+			{Type: sqltypes.VarBinary},
+		},
+		Rows: [][]sqltypes.Value{{
+			sqltypes.NewVarBinary("bb"),
+			sqltypes.NewInt64(RedoStatePrepared),
+			sqltypes.NewVarBinary(fmt.Sprintf("%d", time.Now().UnixNano())),
+			sqltypes.NewVarBinary("update test_table set `name` = 2 where pk = 1 limit 10001"),
+		}},
+	})
+	require.Eventually(t, func() bool {
+		return tsv.te.preparedPool.Contains("bb")
+	}, 5*time.Second, 10*time.Millisecond)
+	tsv.te.stopWatchdog()
+
+	// The prepared transaction doesn't hold the lock of its redo log,
+	// so rolling it back deletes the redo log.
+	require.NoError(t, txe.RollbackPrepared("bb", 0))
+	require.NotZero(t, db.GetQueryCalledNum("delete from _vt.redo_state where dtid = 'bb'"))
+	require.False(t, tsv.te.preparedPool.Contains("bb"))
 }
 
 func TestNoTwopc(t *testing.T) {
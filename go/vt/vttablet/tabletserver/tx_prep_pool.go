@@ -121,6 +121,18 @@ func (pp *TxPreparedPool) Forget(dtid string) {
 	delete(pp.reserved, dtid)
 }
 
+// Contains returns true if the dtid is prepared, being committed
+// or if its commit failed.
+func (pp *TxPreparedPool) Contains(dtid string) bool {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	if _, ok := pp.reserved[dtid]; ok {
+		return true
+	}
+	_, ok := pp.conns[dtid]
+	return ok
+}
+
 // FetchAll removes all connections and returns them as a list.
 // It also forgets all reserved dtids.
 func (pp *TxPreparedPool) FetchAll() []*StatefulConnection {
@@ -19,6 +19,7 @@ package tabletserver
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -116,6 +117,19 @@ func TestPrepFetchForCommit(t *testing.T) {
 	}
 }
 
+func TestPrepContains(t *testing.T) {
+	pp := NewTxPreparedPool(2)
+	pp.Put(&StatefulConnection{}, "aa")
+	assert.True(t, pp.Contains("aa"))
+	_, err := pp.FetchForCommit("aa")
+	require.NoError(t, err)
+	assert.True(t, pp.Contains("aa"))
+	pp.Forget("aa")
+	assert.False(t, pp.Contains("aa"))
+	pp.SetFailed("bb")
+	assert.True(t, pp.Contains("bb"))
+}
+
 func TestPrepFetchAll(t *testing.T) {
 	pp := NewTxPreparedPool(2)
 	conn1 := &StatefulConnection{}
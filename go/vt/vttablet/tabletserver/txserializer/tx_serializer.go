@@ -21,6 +21,7 @@ package txserializer
 import (
 	"fmt"
 	"net/http"
+	"sort"
 	"sync"
 	"time"
 
@@ -38,7 +39,7 @@ import (
 )
 
 // TxSerializer serializes incoming transactions which target the same row range
-// i.e. table name and WHERE clause are identical.
+// i.e. table name and primary key values, or else WHERE clause, are identical.
 // Additional transactions are queued and woken up in arrival order.
 //
 // This implementation has some parallels to the sync2.Consolidator class.
@@ -158,6 +159,36 @@ func (txs *TxSerializer) Wait(ctx context.Context, key, table string) (done Done
 	return func() { txs.unlock(key) }, waited, nil
 }
 
+// WaitAll is like Wait() for a transaction which targets several rows.
+// It waits for the keys one after the other in sorted order, which
+// guarantees that two transactions with overlapping keys cannot block each
+// other forever. The returned "done" releases all keys.
+func (txs *TxSerializer) WaitAll(ctx context.Context, keys []string, table string) (done DoneFunc, waited bool, err error) {
+	sorted := make([]string, len(keys))
+	copy(sorted, keys)
+	sort.Strings(sorted)
+
+	var dones []DoneFunc
+	doneAll := func() {
+		for i := len(dones) - 1; i >= 0; i-- {
+			dones[i]()
+		}
+	}
+	for i, key := range sorted {
+		if i > 0 && key == sorted[i-1] {
+			continue
+		}
+		keyDone, keyWaited, err := txs.Wait(ctx, key, table)
+		waited = waited || keyWaited
+		if err != nil {
+			doneAll()
+			return nil, waited, err
+		}
+		dones = append(dones, keyDone)
+	}
+	return doneAll, waited, nil
+}
+
 // lockLocked queues this transaction. It will unblock immediately if this
 // transaction is the first in the queue or when it acquired a slot.
 // The method has the suffix "Locked" to clarify that "txs.mu" must be locked.
@@ -510,6 +510,66 @@ func TestTxSerializerGlobalQueueOverflow(t *testing.T) {
 	done2()
 }
 
+func TestTxSerializerWaitAll(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.HotRowProtection.MaxQueueSize = 2
+	config.HotRowProtection.MaxGlobalQueueSize = 10
+	config.HotRowProtection.MaxConcurrency = 1
+	txs := New(tabletenv.NewEnv(config, "TxSerializerTest"))
+	resetVariables(txs)
+
+	// tx1 locks rows 1 and 2.
+	done1, waited1, err1 := txs.WaitAll(context.Background(), []string{"t1 where id = 2", "t1 where id = 1", "t1 where id = 2"}, "t1")
+	if err1 != nil {
+		t.Fatal(err1)
+	}
+	if waited1 {
+		t.Fatalf("tx1 must never wait: %v", waited1)
+	}
+	if got, want := txs.Pending("t1 where id = 1")+txs.Pending("t1 where id = 2"), 2; got != want {
+		t.Fatalf("wrong number of pending transactions: got = %v, want = %v", got, want)
+	}
+
+	// tx2 waits for row 2 before it locks row 3.
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		done2, waited2, err2 := txs.WaitAll(context.Background(), []string{"t1 where id = 3", "t1 where id = 2"}, "t1")
+		if err2 != nil {
+			t.Error(err2)
+			return
+		}
+		if !waited2 {
+			t.Errorf("tx2 must have waited: %v", waited2)
+		}
+		done2()
+	}()
+	if err := waitForPending(txs, "t1 where id = 2", 2); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := txs.Pending("t1 where id = 3"), 0; got != want {
+		t.Fatalf("wrong number of pending transactions: got = %v, want = %v", got, want)
+	}
+
+	// tx3 locks row 0, is rejected for row 2 and gives back row 0.
+	_, _, err3 := txs.WaitAll(context.Background(), []string{"t1 where id = 2", "t1 where id = 0"}, "t1")
+	if got, want := vterrors.Code(err3), vtrpcpb.Code_RESOURCE_EXHAUSTED; got != want {
+		t.Fatalf("tx3 must fail because the queue of row 2 is full: got = %v, want = %v", got, want)
+	}
+	if got, want := txs.Pending("t1 where id = 0"), 0; got != want {
+		t.Fatalf("tx3 must give back row 0: got = %v, want = %v", got, want)
+	}
+
+	done1()
+	wg.Wait()
+	for _, key := range []string{"t1 where id = 0", "t1 where id = 1", "t1 where id = 2", "t1 where id = 3"} {
+		if got, want := txs.Pending(key), 0; got != want {
+			t.Errorf("there should be no pending transaction for %v: got = %v, want = %v", key, got, want)
+		}
+	}
+}
+
 func TestTxSerializerPending(t *testing.T) {
 	config := tabletenv.NewDefaultConfig()
 	config.HotRowProtection.MaxQueueSize = 1
@@ -1213,24 +1213,26 @@ func tmRPCTestPromoteReplicaPanic(ctx context.Context, t *testing.T, client tmcl
 // Backup / restore related methods
 //
 
-var testBackupConcurrency = 24
-var testBackupAllowPrimary = false
+var testBackupRequest = &tabletmanagerdatapb.BackupRequest{
+	Concurrency:  24,
+	AllowPrimary: false,
+	BackupEngine: "xtrabackup",
+}
 var testBackupCalled = false
 var testRestoreFromBackupCalled = false
 
-func (fra *fakeRPCTM) Backup(ctx context.Context, concurrency int, logger logutil.Logger, allowPrimary bool) error {
+func (fra *fakeRPCTM) Backup(ctx context.Context, logger logutil.Logger, request *tabletmanagerdatapb.BackupRequest) error {
 	if fra.panics {
 		panic(fmt.Errorf("test-triggered panic"))
 	}
-	compare(fra.t, "Backup args", concurrency, testBackupConcurrency)
-	compare(fra.t, "Backup args", allowPrimary, testBackupAllowPrimary)
+	compare(fra.t, "Backup args", request, testBackupRequest)
 	logStuff(logger, 10)
 	testBackupCalled = true
 	return nil
 }
 
 func tmRPCTestBackup(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
-	stream, err := client.Backup(ctx, tablet, testBackupConcurrency, testBackupAllowPrimary)
+	stream, err := client.Backup(ctx, tablet, testBackupRequest)
 	if err != nil {
 		t.Fatalf("Backup failed: %v", err)
 	}
@@ -1239,7 +1241,7 @@ func tmRPCTestBackup(ctx context.Context, t *testing.T, client tmclient.TabletMa
 }
 
 func tmRPCTestBackupPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
-	stream, err := client.Backup(ctx, tablet, testBackupConcurrency, testBackupAllowPrimary)
+	stream, err := client.Backup(ctx, tablet, testBackupRequest)
 	if err != nil {
 		t.Fatalf("Backup failed: %v", err)
 	}
@@ -1250,7 +1252,7 @@ func tmRPCTestBackupPanic(ctx context.Context, t *testing.T, client tmclient.Tab
 	expectHandleRPCPanic(t, "Backup", true /*verbose*/, err)
 }
 
-func (fra *fakeRPCTM) RestoreFromBackup(ctx context.Context, logger logutil.Logger, backupTime time.Time) error {
+func (fra *fakeRPCTM) RestoreFromBackup(ctx context.Context, logger logutil.Logger, request *tabletmanagerdatapb.RestoreFromBackupRequest) error {
 	if fra.panics {
 		panic(fmt.Errorf("test-triggered panic"))
 	}
@@ -1259,8 +1261,8 @@ func (fra *fakeRPCTM) RestoreFromBackup(ctx context.Context, logger logutil.Logg
 	return nil
 }
 
-func tmRPCTestRestoreFromBackup(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet, backupTime time.Time) {
-	stream, err := client.RestoreFromBackup(ctx, tablet, backupTime)
+func tmRPCTestRestoreFromBackup(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.RestoreFromBackupRequest) {
+	stream, err := client.RestoreFromBackup(ctx, tablet, req)
 	if err != nil {
 		t.Fatalf("RestoreFromBackup failed: %v", err)
 	}
@@ -1268,8 +1270,8 @@ func tmRPCTestRestoreFromBackup(ctx context.Context, t *testing.T, client tmclie
 	compareError(t, "RestoreFromBackup", err, true, testRestoreFromBackupCalled)
 }
 
-func tmRPCTestRestoreFromBackupPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet, backupTime time.Time) {
-	stream, err := client.RestoreFromBackup(ctx, tablet, backupTime)
+func tmRPCTestRestoreFromBackupPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.RestoreFromBackupRequest) {
+	stream, err := client.RestoreFromBackup(ctx, tablet, req)
 	if err != nil {
 		t.Fatalf("RestoreFromBackup failed: %v", err)
 	}
@@ -1301,7 +1303,7 @@ func (fra *fakeRPCTM) HandleRPCPanic(ctx context.Context, name string, args, rep
 func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet, fakeTM tabletmanager.RPCTM) {
 	ctx := context.Background()
 
-	backupTime := time.Time{}
+	restoreFromBackupRequest := &tabletmanagerdatapb.RestoreFromBackupRequest{}
 
 	// Test RPC specific methods of the interface.
 	tmRPCTestDialExpiredContext(ctx, t, client, tablet)
@@ -1356,7 +1358,7 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 
 	// Backup / restore related methods
 	tmRPCTestBackup(ctx, t, client, tablet)
-	tmRPCTestRestoreFromBackup(ctx, t, client, tablet, backupTime)
+	tmRPCTestRestoreFromBackup(ctx, t, client, tablet, restoreFromBackupRequest)
 
 	//
 	// Tests panic handling everywhere now
@@ -1407,7 +1409,7 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestReplicaWasRestartedPanic(ctx, t, client, tablet)
 	// Backup / restore related methods
 	tmRPCTestBackupPanic(ctx, t, client, tablet)
-	tmRPCTestRestoreFromBackupPanic(ctx, t, client, tablet, backupTime)
+	tmRPCTestRestoreFromBackupPanic(ctx, t, client, tablet, restoreFromBackupRequest)
 
 	client.Close()
 }
@@ -25,12 +25,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/robfig/cron/v3"
+
 	"vitess.io/vitess/go/event"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/binlog/binlogplayer"
 	"vitess.io/vitess/go/vt/concurrency"
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/mysqlctl"
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
@@ -66,6 +69,48 @@ func (wr *Wrangler) SetKeyspaceShardingInfo(ctx context.Context, keyspace, shard
 	return err
 }
 
+// ParseBackupSchedule parses the standard cron expression of a
+// BackupSchedule. It is evaluated in UTC, like the names of the backups.
+func ParseBackupSchedule(expr string) (cron.Schedule, error) {
+	return cron.ParseStandard("CRON_TZ=UTC " + expr)
+}
+
+// SetKeyspaceBackupSchedule locks a keyspace and sets the schedule of the
+// backups vtctld takes of its shards, or removes it if schedule is nil.
+func (wr *Wrangler) SetKeyspaceBackupSchedule(ctx context.Context, keyspace string, schedule *topodatapb.BackupSchedule) (err error) {
+	if schedule != nil {
+		if _, err := ParseBackupSchedule(schedule.Cron); err != nil {
+			return fmt.Errorf("invalid cron expression %q: %v", schedule.Cron, err)
+		}
+		for _, tabletType := range schedule.TabletTypes {
+			if tabletType != topodatapb.TabletType_REPLICA && tabletType != topodatapb.TabletType_RDONLY {
+				return fmt.Errorf("backups can only be scheduled on REPLICA and RDONLY tablets, not %v", tabletType)
+			}
+		}
+		if schedule.BackupEngine != "" {
+			if _, err := mysqlctl.GetBackupEngineByName(schedule.BackupEngine); err != nil {
+				return err
+			}
+		}
+		if schedule.MinRetentionTime != nil && schedule.MinRetentionCount < 1 {
+			return fmt.Errorf("min_retention_count must be at least 1 to allow restores to succeed")
+		}
+	}
+
+	ctx, unlock, lockErr := wr.ts.LockKeyspace(ctx, keyspace, "SetKeyspaceBackupSchedule")
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock(&err)
+
+	ki, err := wr.ts.GetKeyspace(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	ki.BackupSchedule = schedule
+	return wr.ts.UpdateKeyspace(ctx, ki)
+}
+
 // validateNewWorkflow ensures that the specified workflow doesn't already exist
 // in the keyspace.
 func (wr *Wrangler) validateNewWorkflow(ctx context.Context, keyspace, workflow string) error {
@@ -0,0 +1,240 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wrangler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/grpcclient"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletconn"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+// redriveBatchSize is the number of dead lettered messages
+// moved back to the message table per transaction.
+const redriveBatchSize = 1000
+
+// redriveQueries are the queries that move messages from the dead letter
+// table back to the message table. They're executed in that order, in a
+// single transaction per batch.
+type redriveQueries struct {
+	selectIDs *sqlparser.ParsedQuery
+	insert    *sqlparser.ParsedQuery
+	delete    *sqlparser.ParsedQuery
+}
+
+// buildRedriveQueries builds the redrive queries for the message table
+// described by td. The messages are redriven as new messages: they're due
+// right away, and their attempts start over.
+func buildRedriveQueries(td *tabletmanagerdatapb.TableDefinition, filterIDs bool) (*redriveQueries, error) {
+	info, err := messageInfo(td)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil || info.DeadLetterTable == "" {
+		return nil, fmt.Errorf("table %s is not a message table with a dead letter table", td.Name)
+	}
+	messageTable := sqlparser.NewTableIdent(td.Name)
+	deadLetterTable := sqlparser.NewTableIdent(info.DeadLetterTable)
+
+	columns := sqlparser.NewTrackedBuffer(nil)
+	values := sqlparser.NewTrackedBuffer(nil)
+	for i, col := range td.Columns {
+		if i != 0 {
+			columns.WriteString(", ")
+			values.WriteString(", ")
+		}
+		colIdent := sqlparser.NewColIdent(col)
+		columns.Myprintf("%v", colIdent)
+		switch strings.ToLower(col) {
+		case "time_next":
+			values.WriteArg(":", "time_now")
+		case "epoch":
+			values.WriteString("0")
+		case "time_acked":
+			values.WriteString("null")
+		default:
+			values.Myprintf("%v", colIdent)
+		}
+	}
+
+	limit := strconv.Itoa(redriveBatchSize)
+	selectIDs := sqlparser.BuildParsedQuery("select id from %v order by id limit %s for update", deadLetterTable, limit)
+	if filterIDs {
+		selectIDs = sqlparser.BuildParsedQuery("select id from %v where id in %a order by id limit %s for update", deadLetterTable, "::ids", limit)
+	}
+	return &redriveQueries{
+		selectIDs: selectIDs,
+		insert:    sqlparser.BuildParsedQuery("insert into %v(%s) select %s from %v where id in %a", messageTable, columns.String(), values.String(), deadLetterTable, "::batch_ids"),
+		delete:    sqlparser.BuildParsedQuery("delete from %v where id in %a", deadLetterTable, "::batch_ids"),
+	}, nil
+}
+
+// messageInfo returns the options of a message table, as the tablets load
+// them from the comment of the table, or nil if it's not a message table.
+func messageInfo(td *tabletmanagerdatapb.TableDefinition) (*schema.MessageInfo, error) {
+	stmt, err := sqlparser.Parse(td.Schema)
+	if err != nil {
+		return nil, err
+	}
+	create, ok := stmt.(*sqlparser.CreateTable)
+	if !ok || create.TableSpec == nil {
+		return nil, fmt.Errorf("unexpected schema for table %s: %s", td.Name, td.Schema)
+	}
+	for _, option := range create.TableSpec.Options {
+		if !strings.EqualFold(option.Name, "comment") || option.Value == nil {
+			continue
+		}
+		if !strings.Contains(option.Value.Val, "vitess_message") {
+			return nil, nil
+		}
+		return schema.ParseMessageInfo(td.Name, option.Value.Val)
+	}
+	return nil, nil
+}
+
+// RedriveMessages moves the messages of a message table back from its
+// dead letter table, on all the shards of the keyspace. If ids is not
+// empty, only those messages are moved. It returns the number of messages
+// moved per shard.
+func (wr *Wrangler) RedriveMessages(ctx context.Context, keyspace, table string, ids []string) (map[string]int64, error) {
+	shards, err := wr.ts.GetShardNames(ctx, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(shards)
+
+	var idsBindVar *querypb.BindVariable
+	if len(ids) != 0 {
+		values := make([]interface{}, 0, len(ids))
+		for _, id := range ids {
+			values = append(values, id)
+		}
+		if idsBindVar, err = sqltypes.BuildBindVariable(values); err != nil {
+			return nil, err
+		}
+	}
+
+	counts := make(map[string]int64)
+	for _, shard := range shards {
+		count, err := wr.redriveShardMessages(ctx, keyspace, shard, table, idsBindVar)
+		counts[shard] = count
+		if err != nil {
+			return counts, fmt.Errorf("redriving messages on shard %s/%s failed: %v", keyspace, shard, err)
+		}
+		wr.Logger().Infof("Redrove %d messages of %s on shard %s/%s", count, table, keyspace, shard)
+	}
+	return counts, nil
+}
+
+func (wr *Wrangler) redriveShardMessages(ctx context.Context, keyspace, shard, table string, idsBindVar *querypb.BindVariable) (int64, error) {
+	ti, err := wr.shardPrimary(ctx, keyspace, shard)
+	if err != nil {
+		return 0, err
+	}
+	schema, err := wr.tmc.GetSchema(ctx, ti.Tablet, []string{table}, nil, false)
+	if err != nil {
+		return 0, err
+	}
+	if len(schema.TableDefinitions) != 1 {
+		return 0, fmt.Errorf("table %s not found on tablet %v", table, ti.AliasString())
+	}
+	queries, err := buildRedriveQueries(schema.TableDefinitions[0], idsBindVar != nil)
+	if err != nil {
+		return 0, err
+	}
+
+	conn, err := tabletconn.GetDialer()(ti.Tablet, grpcclient.FailFast(false))
+	if err != nil {
+		return 0, fmt.Errorf("cannot connect to tablet %v: %v", ti.AliasString(), err)
+	}
+	defer conn.Close(ctx)
+	target := &querypb.Target{
+		Keyspace:   ti.Keyspace,
+		Shard:      ti.Shard,
+		TabletType: ti.Type,
+	}
+
+	var count int64
+	for {
+		n, err := redriveBatch(ctx, conn, target, queries, idsBindVar)
+		count += n
+		if err != nil {
+			return count, err
+		}
+		if n < redriveBatchSize {
+			return count, nil
+		}
+	}
+}
+
+// redriveBatch moves one batch of messages in a transaction, and returns
+// the number of messages moved.
+func redriveBatch(ctx context.Context, conn queryservice.QueryService, target *querypb.Target, queries *redriveQueries, idsBindVar *querypb.BindVariable) (count int64, err error) {
+	transactionID, _, err := conn.Begin(ctx, target, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if transactionID != 0 {
+			conn.Rollback(ctx, target, transactionID)
+		}
+	}()
+
+	bindVars := map[string]*querypb.BindVariable{}
+	if idsBindVar != nil {
+		bindVars["ids"] = idsBindVar
+	}
+	qr, err := conn.Execute(ctx, target, queries.selectIDs.Query, bindVars, transactionID, 0, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(qr.Rows) == 0 {
+		return 0, nil
+	}
+	batchIDs := &querypb.BindVariable{Type: querypb.Type_TUPLE}
+	for _, row := range qr.Rows {
+		batchIDs.Values = append(batchIDs.Values, sqltypes.ValueToProto(row[0]))
+	}
+	bindVars = map[string]*querypb.BindVariable{
+		"time_now":  sqltypes.Int64BindVariable(time.Now().UnixNano()),
+		"batch_ids": batchIDs,
+	}
+	if _, err := conn.Execute(ctx, target, queries.insert.Query, bindVars, transactionID, 0, nil); err != nil {
+		return 0, err
+	}
+	if _, err := conn.Execute(ctx, target, queries.delete.Query, bindVars, transactionID, 0, nil); err != nil {
+		return 0, err
+	}
+	_, err = conn.Commit(ctx, target, transactionID)
+	transactionID = 0
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(qr.Rows)), nil
+}
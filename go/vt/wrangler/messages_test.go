@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wrangler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+var redriveTable = &tabletmanagerdatapb.TableDefinition{
+	Name:    "msg",
+	Schema:  "CREATE TABLE `msg` (\n  `id` bigint NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB COMMENT='vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_max_attempts=3,vt_dead_letter_table=msg_dlq'",
+	Columns: []string{"id", "priority", "time_next", "epoch", "time_acked", "message"},
+}
+
+func TestBuildRedriveQueries(t *testing.T) {
+	queries, err := buildRedriveQueries(redriveTable, false)
+	require.NoError(t, err)
+	assert.Equal(t, "select id from msg_dlq order by id limit 1000 for update", queries.selectIDs.Query)
+	assert.Equal(t, "insert into msg(id, priority, time_next, epoch, time_acked, message) select id, priority, :time_now, 0, null, message from msg_dlq where id in ::batch_ids", queries.insert.Query)
+	assert.Equal(t, "delete from msg_dlq where id in ::batch_ids", queries.delete.Query)
+
+	queries, err = buildRedriveQueries(redriveTable, true)
+	require.NoError(t, err)
+	assert.Equal(t, "select id from msg_dlq where id in ::ids order by id limit 1000 for update", queries.selectIDs.Query)
+
+	_, err = buildRedriveQueries(&tabletmanagerdatapb.TableDefinition{
+		Name:   "msg",
+		Schema: "CREATE TABLE `msg` (\n  `id` bigint NOT NULL\n) COMMENT='vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30'",
+	}, false)
+	assert.EqualError(t, err, "table msg is not a message table with a dead letter table")
+
+	_, err = buildRedriveQueries(&tabletmanagerdatapb.TableDefinition{
+		Name:   "t",
+		Schema: "CREATE TABLE `t` (\n  `id` bigint NOT NULL\n) COMMENT='vt_dead_letter_table=t_dlq'",
+	}, false)
+	assert.EqualError(t, err, "table t is not a message table with a dead letter table")
+
+	// The options are checked like the tablets do.
+	_, err = buildRedriveQueries(&tabletmanagerdatapb.TableDefinition{
+		Name:   "msg",
+		Schema: "CREATE TABLE `msg` (\n  `id` bigint NOT NULL\n) COMMENT='vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_dead_letter_table=msg_dlq'",
+	}, false)
+	assert.EqualError(t, err, "vt_dead_letter_table requires vt_max_attempts for message table: msg")
+}
+
+type redriveQueryService struct {
+	queryservice.QueryService
+	rows      [][]sqltypes.Value
+	queries   []string
+	committed bool
+}
+
+func (qs *redriveQueryService) Begin(ctx context.Context, target *querypb.Target, options *querypb.ExecuteOptions) (int64, *topodatapb.TabletAlias, error) {
+	return 1, nil, nil
+}
+
+func (qs *redriveQueryService) Execute(ctx context.Context, target *querypb.Target, sql string, bindVariables map[string]*querypb.BindVariable, transactionID, reservedID int64, options *querypb.ExecuteOptions) (*sqltypes.Result, error) {
+	qs.queries = append(qs.queries, sql)
+	return &sqltypes.Result{Rows: qs.rows}, nil
+}
+
+func (qs *redriveQueryService) Commit(ctx context.Context, target *querypb.Target, transactionID int64) (int64, error) {
+	qs.committed = true
+	return 0, nil
+}
+
+func (qs *redriveQueryService) Rollback(ctx context.Context, target *querypb.Target, transactionID int64) (int64, error) {
+	return 0, nil
+}
+
+func TestRedriveBatch(t *testing.T) {
+	queries, err := buildRedriveQueries(redriveTable, false)
+	require.NoError(t, err)
+
+	qs := &redriveQueryService{}
+	count, err := redriveBatch(context.Background(), qs, &querypb.Target{}, queries, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+	assert.Equal(t, []string{queries.selectIDs.Query}, qs.queries)
+	assert.False(t, qs.committed)
+
+	qs = &redriveQueryService{
+		rows: [][]sqltypes.Value{{sqltypes.NewInt64(1)}, {sqltypes.NewInt64(2)}},
+	}
+	count, err = redriveBatch(context.Background(), qs, &querypb.Target{}, queries, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	assert.Equal(t, []string{queries.selectIDs.Query, queries.insert.Query, queries.delete.Query}, qs.queries)
+	assert.True(t, qs.committed)
+}
@@ -0,0 +1,265 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wrangler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// restoreTablePollInterval is how often RestoreTable checks whether the
+// tablets of the SNAPSHOT keyspace restored the table.
+var restoreTablePollInterval = 10 * time.Second
+
+// RestoreTable copies a table, or the rows of it that match filter, from a
+// SNAPSHOT keyspace back into its base keyspace, as targetTable. The
+// SNAPSHOT keyspace is the temporary keyspace whose tablets restore a
+// backup of the base keyspace taken before snapshotTime. If baseKeyspace
+// is set, the SNAPSHOT keyspace is created if it doesn't exist yet. Its
+// tablets are not started by RestoreTable, which waits up to
+// waitForRestore for one of them to restore the table. The rows are then
+// copied by a Materialize workflow that stops after the copy, and that
+// should be deleted along with the SNAPSHOT keyspace. The target table is
+// created from the schema of the restored one if it doesn't exist; if it
+// does, it should be empty.
+func (wr *Wrangler) RestoreTable(ctx context.Context, workflow, snapshotKeyspace, baseKeyspace string, snapshotTime time.Time, table, targetTable, filter, cell, tabletTypes string, waitForRestore time.Duration) error {
+	ki, err := wr.ensureSnapshotKeyspace(ctx, snapshotKeyspace, baseKeyspace, snapshotTime)
+	if err != nil {
+		return err
+	}
+	targetKeyspace := ki.BaseKeyspace
+	if targetTable == "" {
+		targetTable = table
+	}
+	if workflow == "" {
+		workflow = "restore_" + targetTable
+	}
+
+	query := fmt.Sprintf("select * from %s", sqlescape.EscapeID(table))
+	if filter != "" {
+		query += " where " + filter
+	}
+	if _, err := sqlparser.Parse(query); err != nil {
+		return fmt.Errorf("invalid filter %q: %v", filter, err)
+	}
+	createDDL, err := wr.waitForRestoredTable(ctx, ki, snapshotKeyspace, table, targetTable, waitForRestore)
+	if err != nil {
+		return err
+	}
+	added, err := wr.addRestoredTableVSchema(ctx, targetKeyspace, table, targetTable)
+	if err != nil {
+		return err
+	}
+
+	err = wr.Materialize(ctx, &vtctldatapb.MaterializeSettings{
+		Workflow:       workflow,
+		SourceKeyspace: snapshotKeyspace,
+		TargetKeyspace: targetKeyspace,
+		// The snapshot doesn't change, there is nothing to replicate
+		// once the rows are copied.
+		StopAfterCopy: true,
+		Cell:          cell,
+		TabletTypes:   tabletTypes,
+		TableSettings: []*vtctldatapb.TableMaterializeSettings{{
+			TargetTable:      targetTable,
+			SourceExpression: query,
+			CreateDdl:        createDDL,
+		}},
+	})
+	if !added {
+		return err
+	}
+	if err != nil {
+		if rerr := wr.removeRestoredTableVSchema(ctx, targetKeyspace, targetTable); rerr != nil {
+			wr.Logger().Errorf("can't remove table %s from the vschema of keyspace %s: %v", targetTable, targetKeyspace, rerr)
+		}
+		return err
+	}
+	return wr.ts.RebuildSrvVSchema(ctx, nil)
+}
+
+// ensureSnapshotKeyspace returns the SNAPSHOT keyspace. If baseKeyspace
+// is set, the keyspace is created like CreateKeyspace does if it doesn't
+// exist, and it must be a snapshot of baseKeyspace otherwise.
+func (wr *Wrangler) ensureSnapshotKeyspace(ctx context.Context, snapshotKeyspace, baseKeyspace string, snapshotTime time.Time) (*topo.KeyspaceInfo, error) {
+	ki, err := wr.ts.GetKeyspace(ctx, snapshotKeyspace)
+	switch {
+	case err == nil:
+		if ki.KeyspaceType != topodatapb.KeyspaceType_SNAPSHOT {
+			return nil, fmt.Errorf("keyspace %s is not a SNAPSHOT keyspace", snapshotKeyspace)
+		}
+		if ki.BaseKeyspace == "" {
+			return nil, fmt.Errorf("snapshot keyspace %s has no base keyspace", snapshotKeyspace)
+		}
+		if baseKeyspace != "" && ki.BaseKeyspace != baseKeyspace {
+			return nil, fmt.Errorf("snapshot keyspace %s is a snapshot of keyspace %s, not %s", snapshotKeyspace, ki.BaseKeyspace, baseKeyspace)
+		}
+		return ki, nil
+	case !topo.IsErrType(err, topo.NoNode) || baseKeyspace == "":
+		return nil, err
+	}
+
+	if snapshotTime.IsZero() {
+		return nil, fmt.Errorf("the snapshot time is required to create snapshot keyspace %s", snapshotKeyspace)
+	}
+	if snapshotTime.After(time.Now()) {
+		return nil, fmt.Errorf("snapshot time %v is in the future", snapshotTime)
+	}
+	vschema, err := wr.ts.GetVSchema(ctx, baseKeyspace)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "can't get the vschema of base keyspace %s", baseKeyspace)
+	}
+	// SNAPSHOT keyspaces are excluded from global routing.
+	vschema.RequireExplicitRouting = true
+	err = wr.ts.CreateKeyspace(ctx, snapshotKeyspace, &topodatapb.Keyspace{
+		KeyspaceType: topodatapb.KeyspaceType_SNAPSHOT,
+		BaseKeyspace: baseKeyspace,
+		SnapshotTime: logutil.TimeToProto(snapshotTime),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := wr.ts.SaveVSchema(ctx, snapshotKeyspace, vschema); err != nil {
+		return nil, err
+	}
+	if err := wr.ts.RebuildSrvVSchema(ctx, nil); err != nil {
+		return nil, err
+	}
+	wr.Logger().Infof("Created snapshot keyspace %s of keyspace %s at %v", snapshotKeyspace, baseKeyspace, snapshotTime)
+	return wr.ts.GetKeyspace(ctx, snapshotKeyspace)
+}
+
+// waitForRestoredTable waits up to waitForRestore for a tablet of the
+// SNAPSHOT keyspace to restore the table, and returns its CREATE TABLE
+// statement renamed to targetTable.
+func (wr *Wrangler) waitForRestoredTable(ctx context.Context, ki *topo.KeyspaceInfo, snapshotKeyspace, table, targetTable string, waitForRestore time.Duration) (string, error) {
+	deadline := time.Now().Add(waitForRestore)
+	createDDL, err := wr.restoredTableDDL(ctx, snapshotKeyspace, table, targetTable)
+	if err == nil || waitForRestore == 0 {
+		return createDDL, err
+	}
+	backupTime := ""
+	if ki.SnapshotTime != nil {
+		backupTime = " -restore_from_backup_ts=" + logutil.ProtoToTime(ki.SnapshotTime).UTC().Format(mysqlctl.BackupTimestampFormat)
+	}
+	wr.Logger().Infof("Waiting up to %v for the tablets of snapshot keyspace %s to restore table %s (%v). Start them with -init_keyspace=%s -init_shard=<shard> -restore_from_backup%s -restore_tables=%s",
+		waitForRestore, snapshotKeyspace, table, err, snapshotKeyspace, backupTime, table)
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(restoreTablePollInterval):
+		}
+		createDDL, err = wr.restoredTableDDL(ctx, snapshotKeyspace, table, targetTable)
+		if err == nil {
+			return createDDL, nil
+		}
+		if time.Now().After(deadline) {
+			return "", vterrors.Wrapf(err, "table %s was not restored in keyspace %s after %v", table, snapshotKeyspace, waitForRestore)
+		}
+	}
+}
+
+// restoredTableDDL returns the CREATE TABLE statement of the restored
+// table, renamed to targetTable. It is read from the first tablet of the
+// SNAPSHOT keyspace that returns the schema of the table, since they are
+// usually not primaries and some of them may be down or still restoring.
+func (wr *Wrangler) restoredTableDDL(ctx context.Context, snapshotKeyspace, table, targetTable string) (string, error) {
+	shards, err := wr.ts.GetServingShards(ctx, snapshotKeyspace)
+	if err != nil {
+		return "", err
+	}
+	if len(shards) == 0 {
+		return "", fmt.Errorf("keyspace %s has no serving shard", snapshotKeyspace)
+	}
+	tablets, err := wr.ts.GetTabletMapForShard(ctx, snapshotKeyspace, shards[0].ShardName())
+	if err != nil {
+		return "", err
+	}
+	lastErr := fmt.Errorf("keyspace %s has no tablet in shard %s", snapshotKeyspace, shards[0].ShardName())
+	for _, ti := range tablets {
+		sd, err := wr.tmc.GetSchema(ctx, ti.Tablet, []string{table}, nil, false)
+		if err != nil {
+			lastErr = fmt.Errorf("can't get the schema of tablet %s: %v", ti.AliasString(), err)
+			continue
+		}
+		if len(sd.TableDefinitions) == 0 {
+			lastErr = fmt.Errorf("table %s was not restored in keyspace %s", table, snapshotKeyspace)
+			continue
+		}
+		stmt, err := sqlparser.ParseStrictDDL(sd.TableDefinitions[0].Schema)
+		if err != nil {
+			return "", err
+		}
+		create, ok := stmt.(*sqlparser.CreateTable)
+		if !ok {
+			return "", fmt.Errorf("table %s is not a base table", table)
+		}
+		create.Table = sqlparser.TableName{Name: sqlparser.NewTableIdent(targetTable)}
+		return sqlparser.String(create), nil
+	}
+	return "", lastErr
+}
+
+// addRestoredTableVSchema adds targetTable to the vschema of a sharded
+// keyspace, sharded like table, if it's not there yet. It returns whether
+// it was added. Materialize needs it in the vschema, but the SrvVSchema is
+// only rebuilt once the workflow is created, so that vtgate doesn't route
+// to a table that may never be created.
+func (wr *Wrangler) addRestoredTableVSchema(ctx context.Context, keyspace, table, targetTable string) (bool, error) {
+	vschema, err := wr.ts.GetVSchema(ctx, keyspace)
+	if err != nil {
+		return false, err
+	}
+	if !vschema.Sharded || vschema.Tables[targetTable] != nil {
+		return false, nil
+	}
+	vtable := vschema.Tables[table]
+	if vtable == nil {
+		return false, fmt.Errorf("table %s not found in vschema for keyspace %s", table, keyspace)
+	}
+	vschema.Tables[targetTable] = proto.Clone(vtable).(*vschemapb.Table)
+	if err := wr.ts.SaveVSchema(ctx, keyspace, vschema); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// removeRestoredTableVSchema removes the table added by
+// addRestoredTableVSchema when the workflow can't be created.
+func (wr *Wrangler) removeRestoredTableVSchema(ctx context.Context, keyspace, targetTable string) error {
+	vschema, err := wr.ts.GetVSchema(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	delete(vschema.Tables, targetTable)
+	return wr.ts.SaveVSchema(ctx, keyspace, vschema)
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wrangler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func TestRestoreTable(t *testing.T) {
+	ctx := context.Background()
+	ms := &vtctldatapb.MaterializeSettings{
+		Workflow:       "workflow",
+		SourceKeyspace: "snapks",
+		TargetKeyspace: "targetks",
+	}
+	env := newTestMaterializerEnv(t, ms, []string{"0"}, []string{"-80", "80-"})
+	defer env.close()
+
+	err := env.wr.RestoreTable(ctx, "workflow", "snapks", "", time.Time{}, "t1", "t1_restored", "", "", "", 0)
+	assert.EqualError(t, err, "keyspace snapks is not a SNAPSHOT keyspace")
+
+	ctx, unlock, err := env.topoServ.LockKeyspace(ctx, "snapks", "TestRestoreTable")
+	require.NoError(t, err)
+	ki, err := env.topoServ.GetKeyspace(ctx, "snapks")
+	require.NoError(t, err)
+	ki.KeyspaceType = topodatapb.KeyspaceType_SNAPSHOT
+	ki.BaseKeyspace = "targetks"
+	require.NoError(t, env.topoServ.UpdateKeyspace(ctx, ki))
+	unlock(&err)
+	require.NoError(t, err)
+
+	err = env.topoServ.SaveVSchema(ctx, "targetks", &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {Type: "hash"},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t1": {ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "c1", Name: "hash"}}},
+		},
+	})
+	require.NoError(t, err)
+	env.tmc.schema["snapks.t1"] = &tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
+			Name:   "t1",
+			Schema: "CREATE TABLE `t1` (`id` bigint, `c1` bigint, PRIMARY KEY (`id`))",
+		}},
+	}
+
+	err = env.wr.RestoreTable(ctx, "workflow", "snapks", "", time.Time{}, "t2", "", "", "", "", 0)
+	assert.EqualError(t, err, "table t2 was not restored in keyspace snapks")
+	err = env.wr.RestoreTable(ctx, "workflow", "snapks", "", time.Time{}, "t1", "", "c1 >", "", "", 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid filter")
+
+	// The restored table is only left in the vschema if the workflow is
+	// created.
+	err = env.wr.RestoreTable(ctx, "workflow", "snapks", "", time.Time{}, "t1", "t1_restored", "c1 > 10", "", "", 0)
+	require.Error(t, err)
+	vschema, err := env.topoServ.GetVSchema(ctx, "targetks")
+	require.NoError(t, err)
+	assert.Nil(t, vschema.Tables["t1_restored"])
+	env.tmc.verifyQueries(t)
+	env.expectValidation()
+
+	for _, tabletID := range []int{200, 210} {
+		env.tmc.expectVRQuery(tabletID, mzSelectFrozenQuery, &sqltypes.Result{})
+		env.tmc.expectVRQuery(tabletID, "/create table t1_restored", &sqltypes.Result{})
+	}
+	env.tmc.expectVRQuery(
+		200,
+		insertPrefix+
+			`.*shard:\\"0\\" filter:{rules:{match:\\"t1_restored\\" filter:\\"select \* from t1 where in_keyrange\(c1.*targetks\.hash.*-80.*and c1 > 10.*stop_after_copy:true`,
+		&sqltypes.Result{},
+	)
+	env.tmc.expectVRQuery(
+		210,
+		insertPrefix+
+			`.*shard:\\"0\\" filter:{rules:{match:\\"t1_restored\\" filter:\\"select \* from t1 where in_keyrange\(c1.*targetks\.hash.*80-.*and c1 > 10.*stop_after_copy:true`,
+		&sqltypes.Result{},
+	)
+	env.tmc.expectVRQuery(200, mzUpdateQuery, &sqltypes.Result{})
+	env.tmc.expectVRQuery(210, mzUpdateQuery, &sqltypes.Result{})
+	err = env.wr.RestoreTable(ctx, "workflow", "snapks", "", time.Time{}, "t1", "t1_restored", "c1 > 10", "", "", 0)
+	require.NoError(t, err)
+	env.tmc.verifyQueries(t)
+
+	// The restored table is sharded like the original one.
+	vschema, err = env.topoServ.GetVSchema(ctx, "targetks")
+	require.NoError(t, err)
+	require.NotNil(t, vschema.Tables["t1_restored"])
+	assert.Equal(t, "c1", vschema.Tables["t1_restored"].ColumnVindexes[0].Column)
+	srvVSchema, err := env.topoServ.GetSrvVSchema(ctx, env.cell)
+	require.NoError(t, err)
+	assert.NotNil(t, srvVSchema.Keyspaces["targetks"].Tables["t1_restored"])
+}
+
+func TestRestoreTableSnapshotKeyspace(t *testing.T) {
+	ctx := context.Background()
+	ms := &vtctldatapb.MaterializeSettings{
+		Workflow:       "workflow",
+		SourceKeyspace: "sourceks",
+		TargetKeyspace: "targetks",
+	}
+	env := newTestMaterializerEnv(t, ms, []string{"0"}, []string{"0"})
+	defer env.close()
+	defer func(interval time.Duration) { restoreTablePollInterval = interval }(restoreTablePollInterval)
+	restoreTablePollInterval = 10 * time.Millisecond
+	require.NoError(t, env.topoServ.SaveVSchema(ctx, "targetks", &vschemapb.Keyspace{}))
+
+	err := env.wr.RestoreTable(ctx, "", "snapks", "targetks", time.Time{}, "t1", "", "", "", "", 0)
+	assert.EqualError(t, err, "the snapshot time is required to create snapshot keyspace snapks")
+	err = env.wr.RestoreTable(ctx, "", "snapks", "targetks", time.Now().Add(time.Hour), "t1", "", "", "", "", 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is in the future")
+
+	// The SNAPSHOT keyspace is created, but it has no tablet yet.
+	snapshotTime := time.Now().Add(-time.Hour)
+	err = env.wr.RestoreTable(ctx, "", "snapks", "targetks", snapshotTime, "t1", "", "", "", "", 50*time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "table t1 was not restored in keyspace snapks after 50ms")
+	ki, err := env.topoServ.GetKeyspace(ctx, "snapks")
+	require.NoError(t, err)
+	assert.Equal(t, topodatapb.KeyspaceType_SNAPSHOT, ki.KeyspaceType)
+	assert.Equal(t, "targetks", ki.BaseKeyspace)
+	assert.Equal(t, snapshotTime.Unix(), ki.SnapshotTime.Seconds)
+	vschema, err := env.topoServ.GetVSchema(ctx, "snapks")
+	require.NoError(t, err)
+	assert.True(t, vschema.RequireExplicitRouting)
+
+	// An existing keyspace must be a snapshot of the base keyspace.
+	err = env.wr.RestoreTable(ctx, "", "snapks", "sourceks", snapshotTime, "t1", "", "", "", "", 0)
+	assert.EqualError(t, err, "snapshot keyspace snapks is a snapshot of keyspace targetks, not sourceks")
+}
+
+// schemaErrorTMClient fails to return the schema of some tablets.
+type schemaErrorTMClient struct {
+	*testMaterializerTMClient
+	failing map[uint32]bool
+}
+
+func (tmc *schemaErrorTMClient) GetSchema(ctx context.Context, tablet *topodatapb.Tablet, tables, excludeTables []string, includeViews bool) (*tabletmanagerdatapb.SchemaDefinition, error) {
+	if tmc.failing[tablet.Alias.Uid] {
+		return nil, fmt.Errorf("tablet %d is down", tablet.Alias.Uid)
+	}
+	return tmc.testMaterializerTMClient.GetSchema(ctx, tablet, tables, excludeTables, includeViews)
+}
+
+func TestRestoredTableDDL(t *testing.T) {
+	ctx := context.Background()
+	ms := &vtctldatapb.MaterializeSettings{
+		Workflow:       "workflow",
+		SourceKeyspace: "snapks",
+		TargetKeyspace: "targetks",
+	}
+	env := newTestMaterializerEnv(t, ms, []string{"0"}, []string{"0"})
+	defer env.close()
+	env.addTablet(101, "snapks", "0", topodatapb.TabletType_REPLICA)
+	env.tmc.schema["snapks.t1"] = &tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
+			Name:   "t1",
+			Schema: "CREATE TABLE `t1` (`id` bigint, PRIMARY KEY (`id`))",
+		}},
+	}
+	tmc := &schemaErrorTMClient{testMaterializerTMClient: env.tmc, failing: map[uint32]bool{100: true}}
+	env.wr.tmc = tmc
+
+	// The schema is read from another tablet if one is down.
+	ddl, err := env.wr.restoredTableDDL(ctx, "snapks", "t1", "t1_restored")
+	require.NoError(t, err)
+	assert.Contains(t, ddl, "create table t1_restored")
+
+	// The error of the last tablet is returned if all of them are down.
+	tmc.failing[101] = true
+	_, err = env.wr.restoredTableDDL(ctx, "snapks", "t1", "t1_restored")
+	require.Error(t, err)
+	assert.Regexp(t, "can't get the schema of tablet .*: tablet 10[01] is down", err.Error())
+}
@@ -0,0 +1,326 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wrangler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vreplication"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// tenantMove holds what is needed to move a tenant of a tenant vindex
+// to a new key range. The rows of the tenant are copied to the target
+// shard, the one that owns the new key range, from all the other shards.
+type tenantMove struct {
+	wr       *Wrangler
+	keyspace string
+	tenant   *vindexes.Tenant
+	tenantID uint64
+	workflow string
+
+	// tables maps the tables that are sharded by the tenant
+	// vindex to their tenant column.
+	tables map[string]string
+
+	keyRange     *topodatapb.KeyRange
+	targetShard  *topo.ShardInfo
+	sourceShards []*topo.ShardInfo
+}
+
+func (wr *Wrangler) buildTenantMove(ctx context.Context, keyspace, vindexName string, tenantID uint64, keyRange string) (*tenantMove, error) {
+	vschema, err := wr.ts.GetVSchema(ctx, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	vindexSpec, ok := vschema.Vindexes[vindexName]
+	if !ok {
+		return nil, fmt.Errorf("vindex %s not found in keyspace %s", vindexName, keyspace)
+	}
+	if vindexSpec.Type != "tenant" {
+		return nil, fmt.Errorf("vindex %s.%s is of type %s, not tenant", keyspace, vindexName, vindexSpec.Type)
+	}
+	vindex, err := vindexes.CreateVindex(vindexSpec.Type, vindexName, vindexSpec.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	tm := &tenantMove{
+		wr:       wr,
+		keyspace: keyspace,
+		tenant:   vindex.(*vindexes.Tenant),
+		tenantID: tenantID,
+		workflow: fmt.Sprintf("%s_tenant_%d", vindexName, tenantID),
+		tables:   make(map[string]string),
+	}
+	for name, table := range vschema.Tables {
+		if len(table.ColumnVindexes) == 0 || table.ColumnVindexes[0].Name != vindexName {
+			continue
+		}
+		cv := table.ColumnVindexes[0]
+		column := cv.Column
+		if column == "" && len(cv.Columns) > 0 {
+			column = cv.Columns[0]
+		}
+		tm.tables[name] = column
+	}
+	if len(tm.tables) == 0 {
+		return nil, fmt.Errorf("no table of keyspace %s is sharded by vindex %s", keyspace, vindexName)
+	}
+
+	krs, err := key.ParseShardingSpec(keyRange)
+	if err != nil {
+		return nil, err
+	}
+	if len(krs) != 1 {
+		return nil, fmt.Errorf("invalid key range: %s", keyRange)
+	}
+	tm.keyRange = krs[0]
+
+	shards, err := wr.ts.GetServingShards(ctx, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	for _, si := range shards {
+		if key.KeyRangeIncludes(si.KeyRange, tm.keyRange) {
+			tm.targetShard = si
+			continue
+		}
+		tm.sourceShards = append(tm.sourceShards, si)
+	}
+	if tm.targetShard == nil {
+		return nil, fmt.Errorf("key range %s does not fit in a single serving shard of keyspace %s", keyRange, keyspace)
+	}
+	return tm, nil
+}
+
+// MoveTenant starts copying the rows of a tenant of a tenant vindex to
+// the shard that owns keyRange, using vreplication streams that filter
+// the tables sharded by the vindex on the tenant id. The tenant keeps
+// being served from its current shard until CompleteMoveTenant is called.
+func (wr *Wrangler) MoveTenant(ctx context.Context, keyspace, vindexName string, tenantID uint64, keyRange, cell, tabletTypes string) error {
+	tm, err := wr.buildTenantMove(ctx, keyspace, vindexName, tenantID, keyRange)
+	if err != nil {
+		return err
+	}
+	targetPrimary, err := wr.ts.GetTablet(ctx, tm.targetShard.PrimaryAlias)
+	if err != nil {
+		return err
+	}
+	ids, err := tm.streamIDs(ctx, targetPrimary)
+	if err != nil {
+		return err
+	}
+	if len(ids) != 0 {
+		return fmt.Errorf("workflow %s already exists in %s/%s", tm.workflow, keyspace, tm.targetShard.ShardName())
+	}
+	if len(tm.sourceShards) == 0 {
+		return fmt.Errorf("keyspace %s has no shard to move tenant %d from", keyspace, tenantID)
+	}
+
+	tables := tm.sortedTables()
+	ig := vreplication.NewInsertGenerator(binlogplayer.BlpRunning, targetPrimary.DbName())
+	for _, sourceShard := range tm.sourceShards {
+		bls := &binlogdatapb.BinlogSource{
+			Keyspace: keyspace,
+			Shard:    sourceShard.ShardName(),
+			Filter:   &binlogdatapb.Filter{},
+		}
+		for _, table := range tables {
+			bls.Filter.Rules = append(bls.Filter.Rules, &binlogdatapb.Rule{
+				Match:  table,
+				Filter: fmt.Sprintf("select * from %s where %s = %d", sqlescape.EscapeID(table), sqlescape.EscapeID(tm.tables[table]), tenantID),
+			})
+		}
+		ig.AddRow(tm.workflow, bls, "", cell, tabletTypes)
+	}
+	_, err = wr.tmc.VReplicationExec(ctx, targetPrimary.Tablet, ig.String())
+	return err
+}
+
+// CompleteMoveTenant pins the tenant to its new key range in the mapping
+// table of the vindex, deletes the streams created by MoveTenant, and
+// deletes the rows of the tenant from the source shards. Writes to the
+// tenant should be paused while this runs, because vtgates only pick up
+// the new key range once they have streamed the change to the mapping
+// table.
+func (wr *Wrangler) CompleteMoveTenant(ctx context.Context, keyspace, vindexName string, tenantID uint64, keyRange string) error {
+	tm, err := wr.buildTenantMove(ctx, keyspace, vindexName, tenantID, keyRange)
+	if err != nil {
+		return err
+	}
+	targetPrimary, err := wr.ts.GetTablet(ctx, tm.targetShard.PrimaryAlias)
+	if err != nil {
+		return err
+	}
+	ids, err := tm.streamIDs(ctx, targetPrimary)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("workflow %s not found in %s/%s", tm.workflow, keyspace, tm.targetShard.ShardName())
+	}
+	qr, err := wr.tmc.VReplicationExec(ctx, targetPrimary.Tablet, fmt.Sprintf("select vrepl_id from _vt.copy_state where vrepl_id in (%s)", strings.Join(ids, ", ")))
+	if err != nil {
+		return err
+	}
+	if len(qr.Rows) != 0 {
+		return fmt.Errorf("workflow %s is still copying, try again later", tm.workflow)
+	}
+
+	table, from, to := tm.tenant.MappingTable()
+	mappingPrimary, mappingTable, err := tm.mappingPrimary(ctx)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("insert into %s(%s, %s) values (%d, %s) on duplicate key update %s = values(%s)",
+		sqlescape.EscapeID(mappingTable), sqlescape.EscapeID(from), sqlescape.EscapeID(to), tenantID,
+		encodeString(key.KeyRangeString(tm.keyRange)), sqlescape.EscapeID(to), sqlescape.EscapeID(to))
+	if _, err := wr.tmc.ExecuteFetchAsDba(ctx, mappingPrimary.Tablet, false, []byte(query), 0, false, false); err != nil {
+		return fmt.Errorf("failed to update tenant mapping table %s: %v", table, err)
+	}
+
+	if err := tm.deleteStreams(ctx, targetPrimary); err != nil {
+		return err
+	}
+	for _, sourceShard := range tm.sourceShards {
+		if err := tm.deleteRows(ctx, sourceShard); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CancelMoveTenant deletes the streams created by MoveTenant, and the
+// rows they copied to the target shard.
+func (wr *Wrangler) CancelMoveTenant(ctx context.Context, keyspace, vindexName string, tenantID uint64, keyRange string) error {
+	tm, err := wr.buildTenantMove(ctx, keyspace, vindexName, tenantID, keyRange)
+	if err != nil {
+		return err
+	}
+	targetPrimary, err := wr.ts.GetTablet(ctx, tm.targetShard.PrimaryAlias)
+	if err != nil {
+		return err
+	}
+	if err := tm.deleteStreams(ctx, targetPrimary); err != nil {
+		return err
+	}
+
+	// The target shard may be where the tenant currently lives,
+	// in which case its rows must be kept.
+	current, err := tm.currentKeyRange(ctx)
+	if err != nil {
+		return err
+	}
+	if key.KeyRangeContains(tm.targetShard.KeyRange, vindexes.TenantKeyspaceID(tenantID, current)) {
+		return nil
+	}
+	return tm.deleteRows(ctx, tm.targetShard)
+}
+
+func (tm *tenantMove) sortedTables() []string {
+	tables := make([]string, 0, len(tm.tables))
+	for table := range tm.tables {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+func (tm *tenantMove) streamIDs(ctx context.Context, targetPrimary *topo.TabletInfo) ([]string, error) {
+	query := fmt.Sprintf("select id from _vt.vreplication where db_name=%s and workflow=%s", encodeString(targetPrimary.DbName()), encodeString(tm.workflow))
+	p3qr, err := tm.wr.tmc.VReplicationExec(ctx, targetPrimary.Tablet, query)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, row := range sqltypes.Proto3ToResult(p3qr).Rows {
+		ids = append(ids, row[0].ToString())
+	}
+	return ids, nil
+}
+
+func (tm *tenantMove) deleteStreams(ctx context.Context, targetPrimary *topo.TabletInfo) error {
+	query := fmt.Sprintf("delete from _vt.vreplication where db_name=%s and workflow=%s", encodeString(targetPrimary.DbName()), encodeString(tm.workflow))
+	_, err := tm.wr.tmc.VReplicationExec(ctx, targetPrimary.Tablet, query)
+	return err
+}
+
+func (tm *tenantMove) deleteRows(ctx context.Context, si *topo.ShardInfo) error {
+	primary, err := tm.wr.ts.GetTablet(ctx, si.PrimaryAlias)
+	if err != nil {
+		return err
+	}
+	for _, table := range tm.sortedTables() {
+		query := fmt.Sprintf("delete from %s where %s = %d", sqlescape.EscapeID(table), sqlescape.EscapeID(tm.tables[table]), tm.tenantID)
+		if _, err := tm.wr.tmc.ExecuteFetchAsDba(ctx, primary.Tablet, false, []byte(query), 0, false, false); err != nil {
+			return fmt.Errorf("failed to delete the rows of tenant %d from %s/%s: %v", tm.tenantID, si.Keyspace(), si.ShardName(), err)
+		}
+	}
+	return nil
+}
+
+// mappingPrimary returns the primary of the keyspace of the mapping
+// table, which must be unsharded, and the unqualified table name.
+func (tm *tenantMove) mappingPrimary(ctx context.Context) (*topo.TabletInfo, string, error) {
+	table, _, _ := tm.tenant.MappingTable()
+	splits := strings.Split(table, ".")
+	shards, err := tm.wr.ts.GetServingShards(ctx, splits[0])
+	if err != nil {
+		return nil, "", err
+	}
+	if len(shards) != 1 {
+		return nil, "", fmt.Errorf("tenant mapping table %s must be in an unsharded keyspace", table)
+	}
+	primary, err := tm.wr.ts.GetTablet(ctx, shards[0].PrimaryAlias)
+	if err != nil {
+		return nil, "", err
+	}
+	return primary, splits[1], nil
+}
+
+// currentKeyRange reads the key range the tenant is pinned to from the
+// mapping table. It returns nil if the tenant is not pinned.
+func (tm *tenantMove) currentKeyRange(ctx context.Context) (*topodatapb.KeyRange, error) {
+	mappingPrimary, mappingTable, err := tm.mappingPrimary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, from, to := tm.tenant.MappingTable()
+	query := fmt.Sprintf("select %s, %s from %s where %s = %d", sqlescape.EscapeID(from), sqlescape.EscapeID(to), sqlescape.EscapeID(mappingTable), sqlescape.EscapeID(from), tm.tenantID)
+	p3qr, err := tm.wr.tmc.ExecuteFetchAsDba(ctx, mappingPrimary.Tablet, false, []byte(query), 1, false, false)
+	if err != nil {
+		return nil, err
+	}
+	qr := sqltypes.Proto3ToResult(p3qr)
+	if len(qr.Rows) == 0 {
+		return nil, nil
+	}
+	_, kr, err := tm.tenant.ParseMapping(qr.Rows[0])
+	return kr, err
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wrangler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func newTestTenantEnv(t *testing.T) *testMaterializerEnv {
+	ms := &vtctldatapb.MaterializeSettings{
+		SourceKeyspace: "tks",
+		TargetKeyspace: "tks",
+	}
+	env := newTestMaterializerEnv(t, ms, []string{"-80", "80-"}, nil)
+	env.addTablet(300, "main", "0", topodatapb.TabletType_PRIMARY)
+
+	vschema := &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"tenant": {
+				Type:   "tenant",
+				Params: map[string]string{"table": "main.tenant_map"},
+			},
+			"hash": {
+				Type: "hash",
+			},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"orders": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "tenant", Column: "tenant_id"}},
+			},
+			"users": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "tenant", Columns: []string{"tid"}}},
+			},
+			"other": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "hash", Column: "id"}},
+			},
+		},
+	}
+	err := env.topoServ.SaveVSchema(context.Background(), "tks", vschema)
+	require.NoError(t, err)
+	return env
+}
+
+func TestMoveTenant(t *testing.T) {
+	env := newTestTenantEnv(t)
+	defer env.close()
+
+	env.tmc.expectVRQuery(110, "select id from _vt.vreplication where db_name='vt_tks' and workflow='tenant_tenant_42'", &sqltypes.Result{})
+	env.tmc.expectVRQuery(
+		110,
+		insertPrefix+
+			`\(`+
+			`'tenant_tenant_42', `+
+			(`'keyspace:\\"tks\\" shard:\\"-80\\" `+
+				`filter:{`+
+				"rules:{match:\\\\\"orders\\\\\" filter:\\\\\"select \\* from `orders` where `tenant_id` = 42\\\\\"} "+
+				"rules:{match:\\\\\"users\\\\\" filter:\\\\\"select \\* from `users` where `tid` = 42\\\\\"}"+
+				`}', `)+
+			`'', [0-9]*, [0-9]*, 'zone1', 'primary', [0-9]*, 0, 'Running', 'vt_tks'`+
+			`\)`+eol,
+		&sqltypes.Result{},
+	)
+	err := env.wr.MoveTenant(context.Background(), "tks", "tenant", 42, "c0-", "zone1", "primary")
+	require.NoError(t, err)
+	env.tmc.verifyQueries(t)
+
+	// The workflow can only be created once.
+	env.tmc.expectVRQuery(110, "select id from _vt.vreplication where db_name='vt_tks' and workflow='tenant_tenant_42'", sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1"))
+	err = env.wr.MoveTenant(context.Background(), "tks", "tenant", 42, "c0-", "zone1", "primary")
+	assert.EqualError(t, err, "workflow tenant_tenant_42 already exists in tks/80-")
+	env.tmc.verifyQueries(t)
+
+	err = env.wr.MoveTenant(context.Background(), "tks", "tenant", 42, "40-c0", "", "")
+	assert.EqualError(t, err, "key range 40-c0 does not fit in a single serving shard of keyspace tks")
+	err = env.wr.MoveTenant(context.Background(), "tks", "hash", 42, "c0-", "", "")
+	assert.EqualError(t, err, "vindex tks.hash is of type hash, not tenant")
+}
+
+func TestCompleteMoveTenant(t *testing.T) {
+	env := newTestTenantEnv(t)
+	defer env.close()
+
+	streams := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1")
+	copying := sqltypes.MakeTestResult(sqltypes.MakeTestFields("vrepl_id", "int64"), "1")
+
+	env.tmc.expectVRQuery(110, "select id from _vt.vreplication where db_name='vt_tks' and workflow='tenant_tenant_42'", streams)
+	env.tmc.expectVRQuery(110, "select vrepl_id from _vt.copy_state where vrepl_id in (1)", copying)
+	err := env.wr.CompleteMoveTenant(context.Background(), "tks", "tenant", 42, "c0-")
+	assert.EqualError(t, err, "workflow tenant_tenant_42 is still copying, try again later")
+	env.tmc.verifyQueries(t)
+
+	env.tmc.expectVRQuery(110, "select id from _vt.vreplication where db_name='vt_tks' and workflow='tenant_tenant_42'", streams)
+	env.tmc.expectVRQuery(110, "select vrepl_id from _vt.copy_state where vrepl_id in (1)", &sqltypes.Result{})
+	env.tmc.expectVRQuery(300, "insert into `tenant_map`(`tenant_id`, `keyrange`) values (42, 'c0-') on duplicate key update `keyrange` = values(`keyrange`)", &sqltypes.Result{})
+	env.tmc.expectVRQuery(110, "delete from _vt.vreplication where db_name='vt_tks' and workflow='tenant_tenant_42'", &sqltypes.Result{})
+	env.tmc.expectVRQuery(100, "delete from `orders` where `tenant_id` = 42", &sqltypes.Result{})
+	env.tmc.expectVRQuery(100, "delete from `users` where `tid` = 42", &sqltypes.Result{})
+	err = env.wr.CompleteMoveTenant(context.Background(), "tks", "tenant", 42, "c0-")
+	require.NoError(t, err)
+	env.tmc.verifyQueries(t)
+}
+
+func TestCancelMoveTenant(t *testing.T) {
+	env := newTestTenantEnv(t)
+	defer env.close()
+
+	mapping := sqltypes.MakeTestFields("tenant_id|keyrange", "int64|varchar")
+
+	// The tenant lives on another shard, the copied rows are deleted.
+	env.tmc.expectVRQuery(110, "delete from _vt.vreplication where db_name='vt_tks' and workflow='tenant_tenant_42'", &sqltypes.Result{})
+	env.tmc.expectVRQuery(300, "select `tenant_id`, `keyrange` from `tenant_map` where `tenant_id` = 42", sqltypes.MakeTestResult(mapping, "42|40-80"))
+	env.tmc.expectVRQuery(110, "delete from `orders` where `tenant_id` = 42", &sqltypes.Result{})
+	env.tmc.expectVRQuery(110, "delete from `users` where `tid` = 42", &sqltypes.Result{})
+	err := env.wr.CancelMoveTenant(context.Background(), "tks", "tenant", 42, "c0-")
+	require.NoError(t, err)
+	env.tmc.verifyQueries(t)
+
+	// The tenant already lives on the target shard, its rows are kept.
+	env.tmc.expectVRQuery(110, "delete from _vt.vreplication where db_name='vt_tks' and workflow='tenant_tenant_42'", &sqltypes.Result{})
+	env.tmc.expectVRQuery(300, "select `tenant_id`, `keyrange` from `tenant_map` where `tenant_id` = 42", sqltypes.MakeTestResult(mapping, "42|80-c0"))
+	err = env.wr.CancelMoveTenant(context.Background(), "tks", "tenant", 42, "c0-")
+	require.NoError(t, err)
+	env.tmc.verifyQueries(t)
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wrangler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/dtids"
+	"vitess.io/vitess/go/vt/grpcclient"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletconn"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// This file contains the tooling to inspect and resolve the distributed
+// transactions of the TWOPC transaction mode. The metadata of a distributed
+// transaction is in the _vt.dt_state and _vt.dt_participant tables of its
+// metadata manager shard, and every participant keeps its prepared part
+// in the _vt.redo_state table until the transaction is resolved.
+
+const (
+	sqlListDistributedTransactions = `select t.dtid, t.state, t.time_created, p.keyspace, p.shard
+	from _vt.dt_state t
+	join _vt.dt_participant p on t.dtid = p.dtid
+	order by t.dtid, p.id`
+	sqlListPreparedTransactions = "select dtid, state, time_created from _vt.redo_state order by dtid"
+
+	// redoStatePrepared mirrors the redo state of the tabletserver for
+	// transactions that are prepared. Any other state is a failure.
+	redoStatePrepared = 1
+)
+
+// DistributedTransaction describes a distributed transaction, as recorded
+// by its metadata manager shard.
+type DistributedTransaction struct {
+	Dtid         string
+	State        string
+	Created      time.Time
+	Participants []string
+}
+
+// PreparedTransaction describes the part of a distributed transaction that
+// was prepared on a participant shard. A FAILED one could not be committed,
+// and the watchdog of the tablet retries its commit.
+type PreparedTransaction struct {
+	Dtid    string
+	Shard   string
+	State   string
+	Created time.Time
+}
+
+// DistributedTransactions lists the distributed transactions of a keyspace.
+type DistributedTransactions struct {
+	Transactions []*DistributedTransaction
+	Prepared     []*PreparedTransaction
+}
+
+// ListDistributedTransactions returns the unresolved distributed
+// transactions of all the shards of the keyspace, and the transactions
+// prepared on them.
+func (wr *Wrangler) ListDistributedTransactions(ctx context.Context, keyspace string) (*DistributedTransactions, error) {
+	shards, err := wr.ts.GetShardNames(ctx, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(shards)
+
+	result := &DistributedTransactions{}
+	for _, shard := range shards {
+		ti, err := wr.shardPrimary(ctx, keyspace, shard)
+		if err != nil {
+			return nil, err
+		}
+		qr, err := wr.tmc.ExecuteFetchAsDba(ctx, ti.Tablet, false, []byte(sqlListDistributedTransactions), 10000, false, false)
+		if err != nil {
+			return nil, fmt.Errorf("reading distributed transactions of shard %s/%s failed: %v", keyspace, shard, err)
+		}
+		result.Transactions = append(result.Transactions, parseDistributedTransactions(sqltypes.Proto3ToResult(qr))...)
+
+		qr, err = wr.tmc.ExecuteFetchAsDba(ctx, ti.Tablet, false, []byte(sqlListPreparedTransactions), 10000, false, false)
+		if err != nil {
+			return nil, fmt.Errorf("reading prepared transactions of shard %s/%s failed: %v", keyspace, shard, err)
+		}
+		result.Prepared = append(result.Prepared, parsePreparedTransactions(topoproto.KeyspaceShardString(keyspace, shard), sqltypes.Proto3ToResult(qr))...)
+	}
+	return result, nil
+}
+
+func parseDistributedTransactions(qr *sqltypes.Result) []*DistributedTransaction {
+	var transactions []*DistributedTransaction
+	var cur *DistributedTransaction
+	for _, row := range qr.Rows {
+		dtid := row[0].ToString()
+		if cur == nil || cur.Dtid != dtid {
+			// A failure in parsing will show up as an UNKNOWN
+			// state, or a very old time, which is harmless.
+			state, _ := row[1].ToInt64()
+			created, _ := row[2].ToInt64()
+			cur = &DistributedTransaction{
+				Dtid:    dtid,
+				State:   querypb.TransactionState(state).String(),
+				Created: time.Unix(0, created),
+			}
+			transactions = append(transactions, cur)
+		}
+		cur.Participants = append(cur.Participants, topoproto.KeyspaceShardString(row[3].ToString(), row[4].ToString()))
+	}
+	return transactions
+}
+
+func parsePreparedTransactions(shard string, qr *sqltypes.Result) []*PreparedTransaction {
+	var transactions []*PreparedTransaction
+	for _, row := range qr.Rows {
+		state := "FAILED"
+		if st, _ := row[1].ToInt64(); st == redoStatePrepared {
+			state = "PREPARED"
+		}
+		created, _ := row[2].ToInt64()
+		transactions = append(transactions, &PreparedTransaction{
+			Dtid:    row[0].ToString(),
+			Shard:   shard,
+			State:   state,
+			Created: time.Unix(0, created),
+		})
+	}
+	return transactions
+}
+
+// GetDistributedTransaction returns the metadata of a distributed
+// transaction from its metadata manager shard.
+func (wr *Wrangler) GetDistributedTransaction(ctx context.Context, dtid string) (*querypb.TransactionMetadata, error) {
+	mmShard, err := dtids.ShardSession(dtid)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := wr.dialShardPrimary(ctx, mmShard.Target.Keyspace, mmShard.Target.Shard)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close(ctx)
+	transaction, err := conn.ReadTransaction(ctx, mmShard.Target, dtid)
+	if err != nil {
+		return nil, err
+	}
+	if transaction == nil || transaction.Dtid == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "distributed transaction not found: %s", dtid)
+	}
+	return transaction, nil
+}
+
+// ResolveDistributedTransaction asks the coordinator, the vtgate at the
+// server address, to resolve an abandoned distributed transaction. It is
+// committed on all participants if the commit decision was recorded, and
+// rolled back otherwise. A transaction is abandoned once it's older than
+// abandonAge: the younger ones are refused unless force is set, because
+// their coordinator may still be working on them.
+func (wr *Wrangler) ResolveDistributedTransaction(ctx context.Context, server, dtid string, abandonAge time.Duration, force bool) error {
+	transaction, err := wr.GetDistributedTransaction(ctx, dtid)
+	if vterrors.Code(err) == vtrpcpb.Code_NOT_FOUND {
+		wr.Logger().Infof("Distributed transaction %s is already resolved", dtid)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	coordinator, err := vtgateconn.Dial(ctx, server)
+	if err != nil {
+		return fmt.Errorf("error connecting to vtgate '%v': %v", server, err)
+	}
+	defer coordinator.Close()
+	if err := resolveDistributedTransaction(ctx, coordinator, transaction, time.Now().Add(-abandonAge), force); err != nil {
+		return err
+	}
+	wr.Logger().Infof("Resolved distributed transaction %s from state %v", dtid, transaction.State)
+	return nil
+}
+
+// transactionResolver is the part of the vtgate connection that resolves
+// distributed transactions.
+type transactionResolver interface {
+	ResolveTransaction(ctx context.Context, dtid string) error
+}
+
+// resolveDistributedTransaction delegates the resolution of the transaction
+// to the coordinator, if it was created before abandonTime or if force is set.
+func resolveDistributedTransaction(ctx context.Context, coordinator transactionResolver, transaction *querypb.TransactionMetadata, abandonTime time.Time, force bool) error {
+	created := time.Unix(0, transaction.TimeCreated)
+	if !force && created.After(abandonTime) {
+		return fmt.Errorf("distributed transaction %s was created at %v and is not abandoned yet: use -force to resolve it anyway", transaction.Dtid, created)
+	}
+	return coordinator.ResolveTransaction(ctx, transaction.Dtid)
+}
+
+// shardPrimary returns the primary tablet of a shard.
+func (wr *Wrangler) shardPrimary(ctx context.Context, keyspace, shard string) (*topo.TabletInfo, error) {
+	si, err := wr.ts.GetShard(ctx, keyspace, shard)
+	if err != nil {
+		return nil, err
+	}
+	if !si.HasPrimary() {
+		return nil, fmt.Errorf("shard %v/%v has no primary", keyspace, shard)
+	}
+	return wr.ts.GetTablet(ctx, si.PrimaryAlias)
+}
+
+// dialShardPrimary returns a query service connection to the primary
+// tablet of a shard.
+func (wr *Wrangler) dialShardPrimary(ctx context.Context, keyspace, shard string) (queryservice.QueryService, error) {
+	ti, err := wr.shardPrimary(ctx, keyspace, shard)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := tabletconn.GetDialer()(ti.Tablet, grpcclient.FailFast(false))
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to tablet %v: %v", ti.AliasString(), err)
+	}
+	return conn, nil
+}
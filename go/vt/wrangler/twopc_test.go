@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wrangler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestParseDistributedTransactions(t *testing.T) {
+	qr := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("dtid|state|time_created|keyspace|shard", "varchar|int64|int64|varchar|varchar"),
+		"ks:-80:1|2|1|ks|-80",
+		"ks:-80:1|2|1|ks|80-",
+		"ks:80-:2|1|2|ks|80-",
+	)
+	assert.Equal(t, []*DistributedTransaction{{
+		Dtid:         "ks:-80:1",
+		State:        "COMMIT",
+		Created:      time.Unix(0, 1),
+		Participants: []string{"ks/-80", "ks/80-"},
+	}, {
+		Dtid:         "ks:80-:2",
+		State:        "PREPARE",
+		Created:      time.Unix(0, 2),
+		Participants: []string{"ks/80-"},
+	}}, parseDistributedTransactions(qr))
+
+	qr = sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("dtid|state|time_created", "varchar|int64|int64"),
+		"ks:-80:1|1|1",
+		"ks:-80:3|0|3",
+	)
+	assert.Equal(t, []*PreparedTransaction{{
+		Dtid:    "ks:-80:1",
+		Shard:   "ks/80-",
+		State:   "PREPARED",
+		Created: time.Unix(0, 1),
+	}, {
+		Dtid:    "ks:-80:3",
+		Shard:   "ks/80-",
+		State:   "FAILED",
+		Created: time.Unix(0, 3),
+	}}, parsePreparedTransactions("ks/80-", qr))
+}
+
+// fakeTransactionResolver records the transactions it resolves.
+type fakeTransactionResolver struct {
+	resolved []string
+	err      error
+}
+
+func (r *fakeTransactionResolver) ResolveTransaction(ctx context.Context, dtid string) error {
+	r.resolved = append(r.resolved, dtid)
+	return r.err
+}
+
+func TestResolveDistributedTransaction(t *testing.T) {
+	abandonTime := time.Now().Add(-time.Minute)
+	testcases := []struct {
+		name    string
+		created time.Time
+		force   bool
+		err     error
+		want    []string
+		wantErr string
+	}{{
+		name:    "abandoned",
+		created: abandonTime.Add(-time.Second),
+		want:    []string{"ks:-80:1234"},
+	}, {
+		name:    "not abandoned",
+		created: abandonTime.Add(time.Second),
+		wantErr: "is not abandoned yet: use -force to resolve it anyway",
+	}, {
+		name:    "forced",
+		created: abandonTime.Add(time.Second),
+		force:   true,
+		want:    []string{"ks:-80:1234"},
+	}, {
+		name:    "coordinator error",
+		created: abandonTime.Add(-time.Second),
+		err:     errors.New("resolve failed"),
+		want:    []string{"ks:-80:1234"},
+		wantErr: "resolve failed",
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			coordinator := &fakeTransactionResolver{err: tc.err}
+			transaction := &querypb.TransactionMetadata{
+				Dtid:        "ks:-80:1234",
+				State:       querypb.TransactionState_COMMIT,
+				TimeCreated: tc.created.UnixNano(),
+			}
+			err := resolveDistributedTransaction(context.Background(), coordinator, transaction, abandonTime, tc.force)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.want, coordinator.resolved)
+		})
+	}
+}